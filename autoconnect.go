@@ -0,0 +1,100 @@
+// autoconnect.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mmp/vice/pkg/log"
+	"github.com/mmp/vice/pkg/platform"
+	"github.com/mmp/vice/pkg/sim"
+)
+
+// autoConnectTimeout is how long to wait for the connection to the
+// multi-controller server when joining a sim given on the command line.
+const autoConnectTimeout = 15 * time.Second
+
+// AutoConnect starts or joins the sim specified on the command line with
+// -position, -sector, and -connect, so that vice can be launched directly
+// into a session. If that isn't possible, the connect dialog is shown with the
+// error so that the user can pick something else.
+type AutoConnect struct {
+	position  string
+	split     string
+	remoteSim string
+	password  string
+	start     time.Time
+	simConfig *sim.NewSimConfiguration
+}
+
+// checkAutoConnectFlags checks that the -position, -callsign, -sector,
+// and -connect command-line flags are consistent and returns the position
+// to sign on to.
+func checkAutoConnectFlags(position, callsign, sector, connect string) (string, error) {
+	if callsign != "" {
+		if position != "" && !strings.EqualFold(position, callsign) {
+			return "", fmt.Errorf("-position %q and -callsign %q give different positions", position, callsign)
+		}
+		position = callsign
+	}
+	if sector != "" && connect != "" {
+		return "", errors.New("-sector can't be used with -connect; the sim's split is chosen by whoever started it")
+	}
+	return position, nil
+}
+
+func MakeAutoConnect(position, split, remoteSim, password string) *AutoConnect {
+	return &AutoConnect{
+		position:  position,
+		split:     split,
+		remoteSim: remoteSim,
+		password:  password,
+		start:     time.Now(),
+	}
+}
+
+// Update should be called each time through the main loop after the
+// connection manager has been updated; it returns true once the sim has
+// been started or the connect dialog has been shown.
+func (ac *AutoConnect) Update(mgr *sim.ConnectionManager, config *Config, p platform.Platform, lg *log.Logger) bool {
+	if ac.simConfig == nil {
+		ac.simConfig = sim.MakeNewSimConfiguration(mgr, &config.LastTRACON, lg)
+	}
+	c := ac.simConfig
+
+	var err error
+	if ac.remoteSim != "" {
+		c.RemoteSimPassword = ac.password
+		var ready bool
+		if ready, err = c.SelectRemoteSim(ac.remoteSim, ac.position); !ready {
+			if time.Since(ac.start) < autoConnectTimeout {
+				return false
+			}
+			err = errors.New("unable to connect to the multi-controller vice server")
+		}
+	} else {
+		err = c.SelectPosition(ac.position, ac.split)
+	}
+
+	if err == nil {
+		err = c.Start()
+	}
+	if err != nil {
+		lg.Errorf("%v", err)
+		c.DisplayError = err
+		client := &ConnectModalClient{
+			mgr:       mgr,
+			lg:        lg,
+			simConfig: c,
+			platform:  p,
+			config:    config,
+		}
+		uiShowModalDialog(NewModalDialogBox(client, p), false)
+	}
+	return true
+}
@@ -0,0 +1,30 @@
+// autoconnect_test.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import "testing"
+
+func TestCheckAutoConnectFlags(t *testing.T) {
+	for _, test := range []struct {
+		position, callsign, sector, connect string
+		expected                            string
+		err                                 bool
+	}{
+		{position: "PHL_APP", expected: "PHL_APP"},
+		{callsign: "PHL_APP", expected: "PHL_APP"},
+		{position: "PHL_APP", callsign: "phl_app", expected: "PHL_APP"},
+		{position: "PHL_APP", callsign: "PHL_DEP", err: true},
+		{position: "PHL_APP", sector: "north", expected: "PHL_APP"},
+		{position: "PHL_APP", connect: "shared", expected: "PHL_APP"},
+		{sector: "north", connect: "shared", err: true},
+	} {
+		pos, err := checkAutoConnectFlags(test.position, test.callsign, test.sector, test.connect)
+		if (err != nil) != test.err {
+			t.Errorf("%+v: got error %v", test, err)
+		} else if pos != test.expected {
+			t.Errorf("%+v: got position %q", test, pos)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// autosave.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mmp/vice/pkg/log"
+	"github.com/mmp/vice/pkg/panes"
+	"github.com/mmp/vice/pkg/platform"
+	"github.com/mmp/vice/pkg/renderer"
+	"github.com/mmp/vice/pkg/sim"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// The configuration is otherwise only saved when vice exits, so changes
+// to the layout and settings made during a long session would be lost
+// if vice crashed. Therefore, it is periodically written to an autosave
+// file next to config.json, as well as when vice is interrupted or
+// panics. The autosave is removed after a clean exit; if there is one
+// that is newer than config.json at startup, the user is offered the
+// chance to restore from it. The sim isn't included in autosaves.
+
+func autosaveFilePath(lg *log.Logger) string {
+	return strings.TrimSuffix(configFilePath(lg), ".json") + "-autosave.json"
+}
+
+// Autosaver periodically autosaves the configuration.
+type Autosaver struct {
+	last time.Time
+	// offering is set while the user is being asked whether to restore
+	// the previous autosave, which mustn't be overwritten until they
+	// answer.
+	offering bool
+}
+
+func (a *Autosaver) Update(config *Config, p platform.Platform, lg *log.Logger) {
+	if a.offering {
+		return
+	} else if a.last.IsZero() {
+		a.last = time.Now()
+	} else if time.Since(a.last) > time.Duration(config.AutosaveMinutes)*time.Minute {
+		a.last = time.Now()
+		config.Autosave(p, lg)
+	}
+}
+
+// Autosave writes the configuration to the autosave file. The platform
+// may be nil, e.g. if vice panics during startup.
+func (c *Config) Autosave(p platform.Platform, lg *log.Logger) {
+	c.ImGuiSettings = imgui.SaveIniSettingsToMemory()
+	if p != nil {
+		c.InitialWindowSize = p.WindowSize()
+		c.InitialWindowPosition = p.WindowPosition()
+	}
+
+	// Write to a temporary file and then rename it so that a crash while
+	// writing doesn't leave a truncated autosave.
+	fn := autosaveFilePath(lg)
+	f, err := os.CreateTemp(filepath.Dir(fn), "vice-autosave-*")
+	if err != nil {
+		lg.Errorf("Unable to create autosave file: %v", err)
+		return
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "    ")
+	err = enc.Encode(&c.ConfigNoSim)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), fn)
+	}
+	if err != nil {
+		lg.Errorf("%s: unable to autosave config: %v", fn, err)
+		os.Remove(f.Name())
+	} else {
+		lg.Infof("Autosaved config to %s", fn)
+	}
+}
+
+// RemoveAutosave removes the autosave file, e.g. after the configuration
+// has been saved when vice exits normally.
+func RemoveAutosave(lg *log.Logger) {
+	if err := os.Remove(autosaveFilePath(lg)); err != nil && !os.IsNotExist(err) {
+		lg.Warnf("Unable to remove autosave: %v", err)
+	}
+}
+
+// checkAutosave returns the time the autosave was written if it is newer
+// than config.json and was written by this version of vice. Otherwise any
+// autosave is removed and the zero time is returned.
+func checkAutosave(lg *log.Logger) time.Time {
+	as, err := os.Stat(autosaveFilePath(lg))
+	if err != nil {
+		return time.Time{}
+	}
+	if cs, err := os.Stat(configFilePath(lg)); err == nil && !as.ModTime().After(cs.ModTime()) {
+		RemoveAutosave(lg)
+		return time.Time{}
+	}
+
+	var v struct{ Version int }
+	if contents, err := os.ReadFile(autosaveFilePath(lg)); err != nil || json.Unmarshal(contents, &v) != nil ||
+		v.Version != CurrentConfigVersion {
+		lg.Warnf("Ignoring unusable autosave")
+		RemoveAutosave(lg)
+		return time.Time{}
+	}
+	return as.ModTime()
+}
+
+// RestoreAutosave replaces the layout and settings with those from the
+// autosave file.
+func (c *Config) RestoreAutosave(controlClient *sim.ControlClient, r renderer.Renderer, p platform.Platform,
+	eventStream *sim.EventStream, lg *log.Logger) error {
+	return c.restoreFromFile(autosaveFilePath(lg), controlClient, r, p, eventStream, lg)
+}
+
+// restoreFromFile replaces the layout and settings with those from the
+// given saved configuration, upgrading it if it was written by an earlier
+// version of vice. The current configuration is left as is if the file
+// can't be read.
+func (c *Config) restoreFromFile(fn string, controlClient *sim.ControlClient, r renderer.Renderer,
+	p platform.Platform, eventStream *sim.EventStream, lg *log.Logger) error {
+	contents, err := os.ReadFile(fn)
+	if err != nil {
+		return err
+	}
+
+	// Make sure that the whole file decodes before touching the current
+	// configuration.
+	var check ConfigNoSim
+	if err := json.Unmarshal(contents, &check); err != nil {
+		return err
+	} else if check.Version > CurrentConfigVersion {
+		return fmt.Errorf("%s: written by a newer version of vice", filepath.Base(fn))
+	}
+
+	// Decode into the current configuration so that its sim is kept.
+	// Maps and slices are cleared first, since decoding merges into them.
+	panes.Deactivate(c.DisplayRoot)
+	c.DisplayRoot, c.Workspaces, c.LayoutSnapshots, c.ReadBulletins = nil, nil, nil, nil
+	c.Version, c.SafeModeLayout = 0, false
+	err = json.Unmarshal(contents, &c.ConfigNoSim)
+	c.upgrade()
+
+	c.Activate(r, p, eventStream, lg)
+	if controlClient != nil && controlClient.Connected() {
+		panes.LoadedSim(c.DisplayRoot, controlClient.State, p, lg)
+	}
+	imgui.LoadIniSettingsFromMemory(c.ImGuiSettings)
+
+	return err
+}
+
+// OfferRestore asks the user whether the configuration should be
+// restored from an autosave written at the given time.
+func (a *Autosaver) OfferRestore(t time.Time, config *Config, controlClient func() *sim.ControlClient,
+	r renderer.Renderer, p platform.Platform, eventStream *sim.EventStream, lg *log.Logger) {
+	a.offering = true
+	uiShowModalDialog(NewModalDialogBox(&YesOrNoModalClient{
+		title: "Restore Configuration?",
+		query: "vice didn't exit normally last time. Your layout and settings were autosaved at " +
+			t.Format("15:04 on 2 Jan") + ".\nRestore them?",
+		ok: func() {
+			if err := config.RestoreAutosave(controlClient(), r, p, eventStream, lg); err != nil {
+				ShowErrorDialog(p, lg, "Unable to restore autosave: %v", err)
+			}
+			RemoveAutosave(lg)
+			a.offering = false
+		},
+		notok: func() {
+			RemoveAutosave(lg)
+			a.offering = false
+		},
+	}, p), true)
+}
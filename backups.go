@@ -0,0 +1,157 @@
+// backups.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mmp/vice/pkg/log"
+	"github.com/mmp/vice/pkg/platform"
+	"github.com/mmp/vice/pkg/renderer"
+	"github.com/mmp/vice/pkg/sim"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// Before config.json is overwritten, the existing one is copied to the
+// backups/ directory next to it so that the user can go back to an
+// earlier configuration if a layout or settings change turns out to be a
+// mistake. Only the most recent maxConfigBackups are kept.
+
+const maxConfigBackups = 10
+
+// Backups are named with the time they were made so that sorting their
+// names sorts them by time. The time includes microseconds so that
+// backups made in quick succession don't overwrite each other; backups
+// made by earlier versions of vice only have seconds.
+const (
+	configBackupTimeFormat    = "20060102-150405.000000"
+	oldConfigBackupTimeFormat = "20060102-150405"
+)
+
+func configBackupDir(lg *log.Logger) string {
+	return filepath.Join(filepath.Dir(configFilePath(lg)), "backups")
+}
+
+// backupConfig copies config.json to the backups directory and removes
+// the oldest backups beyond maxConfigBackups.
+func backupConfig(lg *log.Logger) {
+	contents, err := os.ReadFile(configFilePath(lg))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			lg.Warnf("Unable to read config for backup: %v", err)
+		}
+		return
+	}
+
+	dir := configBackupDir(lg)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		lg.Errorf("%s: unable to make backup directory: %v", dir, err)
+		return
+	}
+	fn := filepath.Join(dir, "config-"+time.Now().Format(configBackupTimeFormat)+".json")
+	if err := os.WriteFile(fn, contents, 0o600); err != nil {
+		lg.Errorf("%s: unable to back up config: %v", fn, err)
+		return
+	}
+
+	backups := listConfigBackups(lg)
+	for len(backups) > maxConfigBackups {
+		oldest := backups[len(backups)-1]
+		if err := os.Remove(oldest.path); err != nil {
+			lg.Warnf("%s: unable to remove old backup: %v", oldest.path, err)
+		}
+		backups = backups[:len(backups)-1]
+	}
+}
+
+type configBackup struct {
+	path string
+	time time.Time
+}
+
+// listConfigBackups returns the available backups, newest first.
+func listConfigBackups(lg *log.Logger) []configBackup {
+	entries, err := os.ReadDir(configBackupDir(lg))
+	if err != nil {
+		return nil
+	}
+
+	var backups []configBackup
+	for _, e := range entries {
+		name := e.Name()
+		ts, ok := strings.CutPrefix(strings.TrimSuffix(name, ".json"), "config-")
+		if !ok || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		for _, format := range []string{configBackupTimeFormat, oldConfigBackupTimeFormat} {
+			if t, err := time.ParseInLocation(format, ts, time.Local); err == nil {
+				backups = append(backups, configBackup{path: filepath.Join(configBackupDir(lg), name), time: t})
+				break
+			}
+		}
+	}
+
+	slices.SortFunc(backups, func(a, b configBackup) int { return b.time.Compare(a.time) })
+	return backups
+}
+
+// RestoreBackupModalClient lists the configuration backups and restores
+// the layout and settings from the one the user selects.
+type RestoreBackupModalClient struct {
+	backups  []configBackup
+	selected int
+
+	config        *Config
+	controlClient *sim.ControlClient
+	renderer      renderer.Renderer
+	platform      platform.Platform
+	eventStream   *sim.EventStream
+	lg            *log.Logger
+}
+
+func (rb *RestoreBackupModalClient) Title() string { return "Restore From Backup" }
+
+func (rb *RestoreBackupModalClient) Opening() {
+	rb.backups = listConfigBackups(rb.lg)
+	rb.selected = 0
+}
+
+func (rb *RestoreBackupModalClient) Buttons() []ModalDialogButton {
+	return []ModalDialogButton{
+		{text: "Cancel", action: func() bool { return true }},
+		{text: "Restore", disabled: rb.selected >= len(rb.backups), action: func() bool {
+			fn := rb.backups[rb.selected].path
+			if err := rb.config.restoreFromFile(fn, rb.controlClient, rb.renderer, rb.platform,
+				rb.eventStream, rb.lg); err != nil {
+				ShowErrorDialog(rb.platform, rb.lg, "Unable to restore backup: %v", err)
+			}
+			return true
+		}},
+	}
+}
+
+func (rb *RestoreBackupModalClient) Draw() int {
+	if len(rb.backups) == 0 {
+		imgui.Text("There are no configuration backups.")
+		return -1
+	}
+
+	imgui.Text("Replace the current layout and settings with those saved at:")
+	if imgui.BeginChildV("backups", imgui.Vec2{300, 200}, true, 0) {
+		for i, b := range rb.backups {
+			if imgui.SelectableV(b.time.Format("Mon 2 Jan 2006 15:04:05"), i == rb.selected, 0, imgui.Vec2{}) {
+				rb.selected = i
+			}
+		}
+	}
+	imgui.EndChild()
+
+	return -1
+}
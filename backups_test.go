@@ -0,0 +1,93 @@
+// backups_test.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func useTestConfigFile(t *testing.T) string {
+	fn := filepath.Join(t.TempDir(), "config.json")
+	saved := *configFilename
+	*configFilename = fn
+	t.Cleanup(func() { *configFilename = saved })
+
+	if err := os.WriteFile(fn, []byte(`{"Version": 1}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return fn
+}
+
+func TestListConfigBackups(t *testing.T) {
+	useTestConfigFile(t)
+	dir := configBackupDir(nil)
+	if len(listConfigBackups(nil)) != 0 {
+		t.Fatalf("expected no backups before the directory exists")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{
+		"config-20240102-030405.json",        // old format
+		"config-20240102-030405.250000.json", // same second, later
+		"config-20230102-030405.000000.json",
+		"config-garbage.json",
+		"notes.txt",
+		"config-20240102-030405.txt",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups := listConfigBackups(nil)
+	var names []string
+	for _, b := range backups {
+		names = append(names, filepath.Base(b.path))
+	}
+	expected := []string{"config-20240102-030405.250000.json", "config-20240102-030405.json",
+		"config-20230102-030405.000000.json"}
+	if len(names) != len(expected) {
+		t.Fatalf("got backups %v, expected %v", names, expected)
+	}
+	for i := range names {
+		if names[i] != expected[i] {
+			t.Errorf("got backups %v, expected %v", names, expected)
+			break
+		}
+	}
+}
+
+func TestBackupConfigPrunes(t *testing.T) {
+	useTestConfigFile(t)
+
+	// Backups made in quick succession must not overwrite each other.
+	for range maxConfigBackups + 3 {
+		backupConfig(nil)
+		time.Sleep(time.Millisecond)
+	}
+
+	backups := listConfigBackups(nil)
+	if len(backups) != maxConfigBackups {
+		t.Fatalf("got %d backups, expected %d", len(backups), maxConfigBackups)
+	}
+	for i := 1; i < len(backups); i++ {
+		if !backups[i-1].time.After(backups[i].time) {
+			t.Errorf("backups not newest first: %v then %v", backups[i-1].time, backups[i].time)
+		}
+	}
+
+	entries, err := os.ReadDir(configBackupDir(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != maxConfigBackups {
+		t.Errorf("%d files left in the backup directory, expected %d", len(entries), maxConfigBackups)
+	}
+}
@@ -0,0 +1,133 @@
+// bookmarks.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mmp/vice/pkg/renderer"
+	"github.com/mmp/vice/pkg/sim"
+	"github.com/mmp/vice/pkg/util"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// Bookmark is a note attached to a moment in the session, e.g. to mark
+// something to discuss during a debrief.
+type Bookmark struct {
+	SimTime time.Time
+	Note    string
+}
+
+// BookmarksWindow lets the user mark interesting moments during a session
+// with free-text annotations and later export them.
+type BookmarksWindow struct {
+	controlClient *sim.ControlClient
+	bookmarks     []Bookmark
+	note          string
+	status        string
+}
+
+func MakeBookmarksWindow(controlClient *sim.ControlClient) *BookmarksWindow {
+	return &BookmarksWindow{controlClient: controlClient}
+}
+
+// Add records a bookmark at the current sim time.
+func (bw *BookmarksWindow) Add(note string) {
+	bw.bookmarks = append(bw.bookmarks, Bookmark{
+		SimTime: bw.controlClient.CurrentTime(),
+		Note:    strings.TrimSpace(note),
+	})
+}
+
+func (bw *BookmarksWindow) Draw(config *Config) (show bool) {
+	show = true
+	imgui.BeginV("Bookmarks", &show, imgui.WindowFlagsAlwaysAutoResize)
+
+	imgui.SetNextItemWidth(300)
+	enter := imgui.InputTextV("##note", &bw.note, imgui.InputTextFlagsEnterReturnsTrue, nil)
+	imgui.SameLine()
+	if imgui.Button("Bookmark now") || enter {
+		bw.Add(bw.note)
+		bw.note = ""
+		bw.status = ""
+	}
+
+	if len(bw.bookmarks) > 0 {
+		flags := imgui.TableFlagsBordersV | imgui.TableFlagsBordersOuterH | imgui.TableFlagsRowBg |
+			imgui.TableFlagsSizingFixedFit
+		if imgui.BeginTableV("bookmarks", 3, flags, imgui.Vec2{}, 0) {
+			imgui.TableSetupColumn("Time")
+			imgui.TableSetupColumn("Note")
+			imgui.TableSetupColumn("")
+			imgui.TableHeadersRow()
+
+			del := -1
+			for i := range bw.bookmarks {
+				b := &bw.bookmarks[i]
+				imgui.PushID(fmt.Sprintf("%d", i))
+				imgui.TableNextRow()
+				imgui.TableNextColumn()
+				imgui.Text(b.SimTime.UTC().Format("15:04:05Z"))
+				imgui.TableNextColumn()
+				imgui.SetNextItemWidth(300)
+				imgui.InputText("##note", &b.Note)
+				imgui.TableNextColumn()
+				if imgui.Button(renderer.FontAwesomeIconTrash) {
+					del = i
+				}
+				imgui.PopID()
+			}
+			imgui.EndTable()
+
+			if del != -1 {
+				bw.bookmarks = slices.Delete(bw.bookmarks, del, del+1)
+			}
+		}
+
+		if imgui.Button("Export") {
+			bw.export()
+		}
+	}
+
+	imgui.Separator()
+	imgui.Text("Session summary: statistics, alerts, bookmarks, and instructions issued")
+	if imgui.Button("Export HTML") {
+		bw.exportSummary(config, "HTML")
+	}
+	imgui.SameLine()
+	if imgui.Button("Export Markdown") {
+		bw.exportSummary(config, "Markdown")
+	}
+
+	if bw.status != "" {
+		imgui.Text(bw.status)
+	}
+
+	imgui.End()
+	return
+}
+
+// export writes the bookmarks to a text file in the user's home directory.
+func (bw *BookmarksWindow) export() {
+	fn := filepath.Join(util.ExportDir(), fmt.Sprintf("vice-bookmarks-%s.txt", time.Now().Format("20060102-150405")))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s\n", bw.controlClient.Status())
+	for _, b := range bw.bookmarks {
+		fmt.Fprintf(&sb, "%s  %s\n", b.SimTime.UTC().Format("15:04:05Z"), b.Note)
+	}
+
+	if err := os.WriteFile(fn, []byte(sb.String()), 0o644); err != nil {
+		bw.status = err.Error()
+	} else {
+		bw.status = "Saved " + fn
+	}
+}
@@ -0,0 +1,104 @@
+// bulletin.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/mmp/vice/pkg/sim"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+type bulletinResult struct {
+	text string
+	err  error
+}
+
+// BulletinWindow shows the facility bulletin specified by the scenario
+// group's "bulletin_url". Bulletins are fetched in the background when a
+// sim starts and are shown then if the user hasn't already read them.
+type BulletinWindow struct {
+	url    string
+	ch     chan bulletinResult
+	text   string
+	err    error
+	loaded bool
+}
+
+func MakeBulletinWindow(url string) *BulletinWindow {
+	bw := &BulletinWindow{
+		url: url,
+		ch:  make(chan bulletinResult, 1),
+	}
+	go func() {
+		text, err := sim.FetchBulletin(url)
+		bw.ch <- bulletinResult{text: text, err: err}
+	}()
+	return bw
+}
+
+// Update returns true in the frame when the bulletin finishes loading.
+func (bw *BulletinWindow) Update() bool {
+	if bw.loaded {
+		return false
+	}
+	select {
+	case r := <-bw.ch:
+		bw.text, bw.err, bw.loaded = r.text, r.err, true
+		return true
+	default:
+		return false
+	}
+}
+
+// hash identifies the bulletin's contents so that the user is notified
+// again when it is updated.
+func (bw *BulletinWindow) hash() string {
+	h := sha256.Sum256([]byte(bw.text))
+	return hex.EncodeToString(h[:])
+}
+
+// Unread returns true if the bulletin has loaded and the user hasn't
+// marked its current contents as read.
+func (bw *BulletinWindow) Unread(config *Config) bool {
+	return bw.loaded && bw.err == nil && bw.text != "" && config.ReadBulletins[bw.url] != bw.hash()
+}
+
+func (bw *BulletinWindow) Draw(config *Config) (show bool) {
+	show = true
+	imgui.SetNextWindowSizeConstraints(imgui.Vec2{X: 400, Y: 100}, imgui.Vec2{X: 800, Y: 600})
+	imgui.BeginV("Facility Bulletin", &show, imgui.WindowFlagsAlwaysAutoResize)
+
+	switch {
+	case !bw.loaded:
+		imgui.Text("Loading " + bw.url + "...")
+	case bw.err != nil:
+		imgui.Text("Unable to load the bulletin: " + bw.err.Error())
+	case bw.text == "":
+		imgui.Text("The facility bulletin is empty.")
+	default:
+		imgui.PushTextWrapPosV(780)
+		imgui.Text(bw.text)
+		imgui.PopTextWrapPos()
+
+		imgui.Separator()
+		if bw.Unread(config) {
+			if imgui.Button("Mark as read") {
+				if config.ReadBulletins == nil {
+					config.ReadBulletins = make(map[string]string)
+				}
+				config.ReadBulletins[bw.url] = bw.hash()
+				show = false
+			}
+		} else {
+			imgui.Text("You have read this bulletin.")
+		}
+	}
+
+	imgui.End()
+	return
+}
@@ -11,6 +11,7 @@ import (
 	"os"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/mmp/imgui-go/v4"
 	"github.com/mmp/vice/pkg/log"
@@ -44,7 +45,13 @@ import (
 // 26: make allow_long_scratchpad a single bool
 // 27: rework prefs, videomaps
 // 28: new departure flow
-const CurrentConfigVersion = 28
+// 29: STARS altimeter list
+// 30: STARS basemap
+// 31: STARS terrain brightness
+// 32: STARS runway occupancy alerts
+// 33: STARS no comm list
+// 34: STARS no comm alerts
+const CurrentConfigVersion = 34
 
 // Slightly convoluted, but the full Config definition is split into
 // the part with the Sim and the rest of it.  In this way, we can first
@@ -73,6 +80,83 @@ type ConfigNoSim struct {
 	InhibitDiscordActivity   util.AtomicBool
 	NotifiedNewCommandSyntax bool
 
+	BreakReminderMinutes  int
+	DisableBreakReminders bool
+
+	// AutosaveMinutes is how often the configuration is autosaved; see
+	// autosave.go.
+	AutosaveMinutes int
+
+	// Rendering is simplified when frames take longer than this; see
+	// degraded.go.
+	DegradedFrameTimeMS      int
+	DisableDegradedRendering bool
+
+	// Optional facility roster endpoint used to validate positions
+	// before signing on; see sim.CheckRosterPosition.
+	RosterURL string
+	RosterCID string
+
+	HideRadioWindow bool
+	MonitorGuard    bool
+
+	// ReadBulletins maps facility bulletin URLs to a hash of the
+	// contents of the bulletin the user last marked as read.
+	ReadBulletins map[string]string
+
+	// CompactMode shows a single pane at a time and collapses the menu
+	// bar, for small screens.
+	CompactMode bool
+
+	// MaximizePaneKey is the letter that, pressed with control, toggles
+	// showing a single pane using the whole window. The default, "F", is
+	// used if it is empty; "Off" disables the shortcut.
+	MaximizePaneKey string
+
+	// StartMaximizedPane is the name of a pane that is maximized when vice
+	// starts, for setups that are only used for one thing, e.g. a machine
+	// dedicated to the scope.
+	StartMaximizedPane string `json:",omitempty"`
+
+	// LockLayout prevents the panes from being resized by dragging the
+	// lines between them or moved by control-dragging them.
+	LockLayout bool
+
+	// ShowPaneTitleBars causes a title bar with buttons for common
+	// window operations to be drawn above each pane.
+	ShowPaneTitleBars bool
+
+	// LayoutSnapshots stores named snapshots of DisplayRoot that the
+	// user can switch between; see layouts.go.
+	LayoutSnapshots map[string]json.RawMessage
+	// SafeModeLayout is set when the layout is the default one that
+	// safe mode switched to; it is cleared when another layout replaces
+	// it.
+	SafeModeLayout bool `json:",omitempty"`
+
+	// AppliedPositionTemplates records the version of each facility
+	// position template the user has applied, indexed by TRACON and
+	// position; see positiontemplates.go.
+	AppliedPositionTemplates map[string]int `json:",omitempty"`
+
+	// Workspaces holds the display hierarchies that can be switched
+	// between with Alt and a number key; see workspaces.go.
+	Workspaces      []Workspace `json:",omitempty"`
+	ActiveWorkspace int
+
+	// ResourceCacheMB is the size limit, in megabytes, of the cache of
+	// downloaded map tiles, weather, TFRs, and scenarios.
+	ResourceCacheMB int
+
+	// TelemetryEnabled is set if the user has opted in to sending
+	// anonymous usage metrics; see telemetry.go.
+	TelemetryEnabled bool
+	CleanSessions    int
+	CrashedSessions  int
+	// PendingTelemetry is the previous session's report, to be sent at
+	// startup.
+	PendingTelemetry *sim.TelemetryReport `json:",omitempty"`
+
 	Callsign string
 }
 
@@ -81,13 +165,16 @@ type ConfigSim struct {
 }
 
 func configFilePath(lg *log.Logger) string {
-	dir, err := os.UserConfigDir()
+	if *configFilename != "" {
+		return *configFilename
+	}
+
+	dir, err := util.ConfigDir()
 	if err != nil {
 		lg.Errorf("Unable to find user config dir: %v", err)
 		dir = "."
 	}
 
-	dir = path.Join(dir, "Vice")
 	err = os.MkdirAll(dir, 0o700)
 	if err != nil {
 		lg.Errorf("%s: unable to make directory for config file: %v", dir, err)
@@ -103,6 +190,8 @@ func (gc *Config) Encode(w io.Writer) error {
 }
 
 func (c *Config) Save(lg *log.Logger) error {
+	backupConfig(lg)
+
 	lg.Infof("Saving config to: %s", configFilePath(lg))
 	f, err := os.Create(configFilePath(lg))
 	if err != nil {
@@ -154,6 +243,51 @@ func (gc *Config) SaveIfChanged(renderer renderer.Renderer, platform platform.Pl
 	return true
 }
 
+// safeModeLayoutName is the name of the layout snapshot that the user's
+// layout is saved to when vice is started in safe mode.
+const safeModeLayoutName = "Before safe mode"
+
+// EnterSafeMode updates the configuration for starting in safe mode: the
+// layout is replaced with the default one, after being saved as a layout
+// snapshot so that it can be switched back to, the saved sim isn't
+// resumed, and the options that are demanding of the GPU or that affect
+// window placement are disabled. If the layout is still the one from an
+// earlier launch in safe mode, the snapshot of the user's layout from
+// then is kept.
+func (c *Config) EnterSafeMode(lg *log.Logger) {
+	lg.Info("Starting in safe mode")
+
+	if _, ok := c.LayoutSnapshots[safeModeLayoutName]; ok && c.SafeModeLayout {
+		lg.Infof("Keeping existing %q layout", safeModeLayoutName)
+	} else if c.DisplayRoot != nil {
+		if err := c.SaveLayoutSnapshot(safeModeLayoutName); err != nil {
+			lg.Errorf("%s: unable to save layout: %v", safeModeLayoutName, err)
+		}
+	}
+	c.DisplayRoot = nil
+	c.SafeModeLayout = true
+	c.CompactMode = false
+	c.Sim, c.Callsign = nil, ""
+
+	c.EnableMSAA = false
+	c.SmoothLines = false
+	c.StartInFullScreen = false
+	c.SpanAllMonitors = false
+}
+
+// maximizePaneKey returns the key for the shortcut that maximizes a pane,
+// or the empty string if it is disabled.
+func (c *Config) maximizePaneKey() string {
+	switch c.MaximizePaneKey {
+	case "":
+		return "F"
+	case "Off":
+		return ""
+	default:
+		return c.MaximizePaneKey
+	}
+}
+
 func getDefaultConfig() *Config {
 	return &Config{
 		ConfigNoSim: ConfigNoSim{
@@ -184,27 +318,6 @@ func LoadOrMakeDefaultConfig(lg *log.Logger) (config *Config, configErr error) {
 			config = getDefaultConfig()
 		}
 
-		if config.Version < 1 {
-			// Force upgrade via upcoming Activate() call...
-			config.DisplayRoot = nil
-		}
-		if config.Version < 5 {
-			config.Callsign = ""
-		}
-		if config.Version < 24 {
-			config.AudioEnabled = true
-		}
-
-		if config.Version < CurrentConfigVersion {
-			if config.DisplayRoot != nil {
-				config.DisplayRoot.VisitPanes(func(p panes.Pane) {
-					if up, ok := p.(panes.PaneUpgrader); ok {
-						up.Upgrade(config.Version, CurrentConfigVersion)
-					}
-				})
-			}
-		}
-
 		if config.Version == CurrentConfigVersion {
 			// Go ahead and deserialize the Sim
 			r.Seek(0, io.SeekStart)
@@ -214,16 +327,66 @@ func LoadOrMakeDefaultConfig(lg *log.Logger) (config *Config, configErr error) {
 		}
 	}
 
-	if config.UIFontSize == 0 {
-		config.UIFontSize = 16
-	}
-	config.Version = CurrentConfigVersion
+	config.upgrade()
 
 	imgui.LoadIniSettingsFromMemory(config.ImGuiSettings)
 
 	return
 }
 
+// upgrade brings a configuration that was read from a file written by an
+// earlier version of vice up to date and fills in defaults for settings
+// that it doesn't have.
+func (c *Config) upgrade() {
+	if c.Version < 1 {
+		// Force upgrade via upcoming Activate() call...
+		c.DisplayRoot = nil
+	}
+	if c.Version < 5 {
+		c.Callsign = ""
+	}
+	if c.Version < 24 {
+		c.AudioEnabled = true
+	}
+
+	if c.Version < CurrentConfigVersion {
+		c.upgradePanes()
+	}
+
+	if c.UIFontSize == 0 {
+		c.UIFontSize = 16
+	}
+	if c.BreakReminderMinutes == 0 {
+		c.BreakReminderMinutes = 120
+	}
+	if c.AutosaveMinutes == 0 {
+		c.AutosaveMinutes = 5
+	}
+	if c.DegradedFrameTimeMS == 0 {
+		c.DegradedFrameTimeMS = int(slowFrameTime / time.Millisecond)
+	}
+	if c.ResourceCacheMB == 0 {
+		c.ResourceCacheMB = util.DefaultResourceCacheSize >> 20
+	}
+	c.Version = CurrentConfigVersion
+}
+
+// upgradePanes upgrades the panes in the layout and the workspaces from
+// the configuration's version to the current one.
+func (c *Config) upgradePanes() {
+	upgrade := func(root *panes.DisplayNode) {
+		root.VisitPanes(func(p panes.Pane) {
+			if up, ok := p.(panes.PaneUpgrader); ok {
+				up.Upgrade(c.Version, CurrentConfigVersion)
+			}
+		})
+	}
+	if c.DisplayRoot != nil {
+		upgrade(c.DisplayRoot)
+	}
+	c.visitWorkspaceRoots(upgrade)
+}
+
 func (gc *Config) Activate(r renderer.Renderer, p platform.Platform, eventStream *sim.EventStream, lg *log.Logger) {
 	if gc.DisplayRoot == nil {
 		gc.DisplayRoot = panes.NewDisplayPanes(stars.NewSTARSPane(), panes.NewMessagesPane(),
@@ -231,4 +394,40 @@ func (gc *Config) Activate(r renderer.Renderer, p platform.Platform, eventStream
 	}
 
 	panes.Activate(gc.DisplayRoot, r, p, eventStream, lg)
+
+	if len(gc.Workspaces) > 0 && (gc.ActiveWorkspace < 0 || gc.ActiveWorkspace >= len(gc.Workspaces) ||
+		gc.Workspaces[gc.ActiveWorkspace].Root != nil) {
+		lg.Warnf("Invalid active workspace %d; discarding workspaces", gc.ActiveWorkspace)
+		gc.Workspaces, gc.ActiveWorkspace = nil, 0
+	}
+}
+
+// MaximizeStartPane maximizes the pane named by StartMaximizedPane, if
+// any. It should only be called at launch, so that the pane isn't
+// maximized again when the configuration is restored. Only panes that
+// can take the keyboard focus may be maximized at startup.
+func (gc *Config) MaximizeStartPane(lg *log.Logger) {
+	if gc.StartMaximizedPane == "" {
+		return
+	}
+
+	if pane := gc.findPane(gc.StartMaximizedPane); pane == nil {
+		lg.Warnf("%s: no window with that name to maximize", gc.StartMaximizedPane)
+	} else if !pane.CanTakeKeyboardFocus() {
+		lg.Warnf("%s: window can't be maximized at startup", gc.StartMaximizedPane)
+	} else {
+		panes.ShowPane(gc.DisplayRoot, pane, true)
+	}
+}
+
+// findPane returns the first pane in the layout with the given name, or
+// nil if there isn't one.
+func (gc *Config) findPane(name string) panes.Pane {
+	var found panes.Pane
+	gc.DisplayRoot.VisitPanes(func(pane panes.Pane) {
+		if found == nil && panes.PaneName(pane) == name {
+			found = pane
+		}
+	})
+	return found
 }
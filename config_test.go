@@ -0,0 +1,100 @@
+// config_test.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mmp/vice/pkg/panes"
+)
+
+func TestEnterSafeModeKeepsLayout(t *testing.T) {
+	c := &Config{}
+	c.DisplayRoot = &panes.DisplayNode{Pane: panes.NewMessagesPane()}
+	c.EnterSafeMode(nil)
+
+	saved, ok := c.LayoutSnapshots[safeModeLayoutName]
+	if !ok {
+		t.Fatalf("layout not saved when entering safe mode")
+	}
+	if c.DisplayRoot != nil || !c.SafeModeLayout {
+		t.Errorf("expected the default layout to be used")
+	}
+
+	// Launching in safe mode again with the safe mode layout keeps the
+	// user's layout from before.
+	c.DisplayRoot = panes.NewDisplayPanes(panes.NewEmptyPane(), panes.NewMessagesPane(), panes.NewFlightStripPane())
+	c.EnterSafeMode(nil)
+	if string(c.LayoutSnapshots[safeModeLayoutName]) != string(saved) {
+		t.Errorf("snapshot of the user's layout was overwritten")
+	}
+
+	// Once the user has switched to another layout (as replaceDisplayRoot
+	// does, without activating the panes), it's saved.
+	c.DisplayRoot, c.SafeModeLayout = &panes.DisplayNode{Pane: panes.NewFlightStripPane()}, false
+	c.EnterSafeMode(nil)
+	var root panes.DisplayNode
+	if err := json.Unmarshal(c.LayoutSnapshots[safeModeLayoutName], &root); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := root.Pane.(*panes.FlightStripPane); !ok {
+		t.Errorf("expected the flight strip layout to be saved, got %T", root.Pane)
+	}
+}
+
+func TestConfigUpgradePaneTypeNames(t *testing.T) {
+	c := &Config{}
+	c.Version = CurrentConfigVersion - 1
+	c.DisplayRoot = panes.NewDisplayPanes(panes.NewEmptyPane(), panes.NewMessagesPane(), panes.NewFlightStripPane())
+	b, err := json.Marshal(&c.ConfigNoSim)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Config files from before pane types were registered by name have
+	// Go type names.
+	old := strings.NewReplacer(`"Type":"EmptyPane"`, `"Type":"*panes.EmptyPane"`,
+		`"Type":"MessagesPane"`, `"Type":"*main.MessagesPane"`,
+		`"Type":"FlightStripPane"`, `"Type":"*panes.FlightStripPane"`).Replace(string(b))
+	if old == string(b) {
+		t.Fatalf("panes not saved with their registered names: %s", b)
+	}
+
+	loaded := &Config{}
+	if err := json.Unmarshal([]byte(old), &loaded.ConfigNoSim); err != nil {
+		t.Fatal(err)
+	}
+	loaded.upgrade()
+	if loaded.Version != CurrentConfigVersion {
+		t.Errorf("got version %d after upgrade, expected %d", loaded.Version, CurrentConfigVersion)
+	}
+
+	var empty, messages, strips int
+	loaded.DisplayRoot.VisitPanes(func(p panes.Pane) {
+		switch p.(type) {
+		case *panes.EmptyPane:
+			empty++
+		case *panes.MessagesPane:
+			messages++
+		case *panes.FlightStripPane:
+			strips++
+		}
+	})
+	if empty != 1 || messages != 1 || strips != 1 {
+		t.Errorf("got %d empty, %d messages, and %d flight strip panes after loading the old config",
+			empty, messages, strips)
+	}
+
+	// Saving the upgraded config writes the registered names.
+	b, err = json.Marshal(&loaded.ConfigNoSim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), `"Type":"*`) {
+		t.Errorf("upgraded config still has Go type names: %s", b)
+	}
+}
@@ -0,0 +1,250 @@
+// debrief.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mmp/vice/pkg/panes"
+	"github.com/mmp/vice/pkg/panes/stars"
+	"github.com/mmp/vice/pkg/sim"
+	"github.com/mmp/vice/pkg/util"
+)
+
+// The session summary is a debrief document that collects statistics
+// about the session, the alerts that occurred, the user's bookmarks, and
+// the instructions they issued, so that it can be shared with an
+// instructor or reviewed afterward. Alerts are listed in order of
+// severity so that the most significant ones come first.
+
+type summarySection struct {
+	title  string
+	text   []string
+	header []string
+	rows   [][]string
+}
+
+// alertSeverity returns the weight given to an alert in the session
+// summary: conflict and runway alerts are the most severe, then MSAW,
+// then special purpose codes. Alerts that were never acknowledged count
+// double.
+func alertSeverity(r stars.AlertResponse) int {
+	w := 1
+	switch r.Kind {
+	case "CA", "RWY":
+		w = 3
+	case "MSAW":
+		w = 2
+	}
+	if r.Response == 0 {
+		w *= 2
+	}
+	return w
+}
+
+func makeSessionSummary(controlClient *sim.ControlClient, root *panes.DisplayNode, bookmarks []Bookmark) []summarySection {
+	me := controlClient.Callsign
+	now := controlClient.CurrentTime()
+
+	var alerts []stars.AlertResponse
+	root.VisitPanes(func(p panes.Pane) {
+		if sp, ok := p.(*stars.STARSPane); ok {
+			alerts = append(alerts, sp.AlertResponses()...)
+		}
+	})
+
+	// Go through the aircraft timelines for statistics and the
+	// instructions the user issued.
+	type instruction struct {
+		callsign string
+		ev       sim.TrailEvent
+	}
+	var instructions []instruction
+	var start time.Time
+	tracked := make(map[string]interface{})
+	handoffs, accepted := 0, 0
+	for callsign, t := range controlClient.Trails.Trails {
+		if len(t.Points) > 0 && (start.IsZero() || t.Points[0].Time.Before(start)) {
+			start = t.Points[0].Time
+		}
+		if slices.ContainsFunc(t.Points, func(pt sim.TrailPoint) bool { return pt.Controller == me }) {
+			tracked[callsign] = nil
+		}
+		for _, ev := range t.Events {
+			if ev.Controller != me {
+				continue
+			}
+			switch ev.Type {
+			case sim.TrailEventInstruction:
+				instructions = append(instructions, instruction{callsign: callsign, ev: ev})
+			case sim.TrailEventHandoff:
+				if strings.HasPrefix(ev.Description, "handoff offered") {
+					handoffs++
+				} else {
+					accepted++
+				}
+			}
+		}
+	}
+	slices.SortFunc(instructions, func(a, b instruction) int { return a.ev.Time.Compare(b.ev.Time) })
+
+	severity, acked := 0, 0
+	var total time.Duration
+	for _, r := range alerts {
+		severity += alertSeverity(r)
+		if r.Response > 0 {
+			acked++
+			total += r.Response
+		}
+	}
+
+	stats := summarySection{title: "Statistics", header: []string{"", ""}}
+	stat := func(name string, value any) {
+		stats.rows = append(stats.rows, []string{name, fmt.Sprintf("%v", value)})
+	}
+	stat("Position", me)
+	if !start.IsZero() {
+		stat("Session", fmt.Sprintf("%s-%s (%d minutes)", start.UTC().Format("1504Z"), now.UTC().Format("1504Z"),
+			int(now.Sub(start).Minutes())))
+	}
+	stat("Aircraft tracked", len(tracked))
+	stat("Instructions issued", len(instructions))
+	stat("Handoffs offered", handoffs)
+	stat("Handoffs accepted", accepted)
+	stat("Alerts", fmt.Sprintf("%d (%d acknowledged)", len(alerts), acked))
+	if acked > 0 {
+		stat("Average alert response", fmt.Sprintf("%.1fs", (total/time.Duration(acked)).Seconds()))
+	}
+	stat("Alert severity score", severity)
+
+	alertSection := summarySection{
+		title:  "Alerts",
+		header: []string{"Severity", "Time", "Alert", "Aircraft", "Response"},
+	}
+	slices.SortStableFunc(alerts, func(a, b stars.AlertResponse) int {
+		return cmp.Or(alertSeverity(b)-alertSeverity(a), a.Start.Compare(b.Start))
+	})
+	for _, r := range alerts {
+		alertSection.rows = append(alertSection.rows, []string{
+			fmt.Sprintf("%d", alertSeverity(r)), r.Start.Format("15:04:05"), r.Kind, r.Callsigns,
+			util.Select(r.Response > 0, fmt.Sprintf("%.1fs", r.Response.Seconds()), "Not acknowledged"),
+		})
+	}
+	if len(alerts) == 0 {
+		alertSection.text = []string{"No alerts."}
+	}
+
+	bookmarkSection := summarySection{title: "Bookmarks", header: []string{"Time", "Note"}}
+	for _, b := range bookmarks {
+		bookmarkSection.rows = append(bookmarkSection.rows, []string{b.SimTime.UTC().Format("15:04:05Z"), b.Note})
+	}
+	if len(bookmarks) == 0 {
+		bookmarkSection.text = []string{"No bookmarks."}
+	}
+
+	instructionSection := summarySection{
+		title:  "Instructions Issued",
+		text:   []string{"As read back by the pilots."},
+		header: []string{"Time", "Aircraft", "Readback"},
+	}
+	for _, in := range instructions {
+		instructionSection.rows = append(instructionSection.rows,
+			[]string{in.ev.Time.UTC().Format("15:04:05Z"), in.callsign, in.ev.Description})
+	}
+
+	return []summarySection{stats, alertSection, bookmarkSection, instructionSection}
+}
+
+func writeSummaryMarkdown(w io.Writer, title string, sections []summarySection) {
+	escape := func(s string) string { return strings.ReplaceAll(s, "|", `\|`) }
+
+	fmt.Fprintf(w, "# %s\n", title)
+	for _, s := range sections {
+		fmt.Fprintf(w, "\n## %s\n\n", s.title)
+		for _, t := range s.text {
+			fmt.Fprintf(w, "%s\n\n", t)
+		}
+		if len(s.rows) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(s.header, " | "))
+		fmt.Fprintf(w, "|%s\n", strings.Repeat(" --- |", len(s.header)))
+		for _, row := range s.rows {
+			fmt.Fprintf(w, "| %s |\n", strings.Join(util.MapSlice(row, escape), " | "))
+		}
+	}
+}
+
+func writeSummaryHTML(w io.Writer, title string, sections []summarySection) {
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%[1]s</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #999; padding: 2px 8px; text-align: left; }
+</style>
+</head>
+<body>
+<h1>%[1]s</h1>
+`, html.EscapeString(title))
+
+	for _, s := range sections {
+		fmt.Fprintf(w, "<h2>%s</h2>\n", html.EscapeString(s.title))
+		for _, t := range s.text {
+			fmt.Fprintf(w, "<p>%s</p>\n", html.EscapeString(t))
+		}
+		if len(s.rows) == 0 {
+			continue
+		}
+		fmt.Fprint(w, "<table>\n<tr>")
+		for _, h := range s.header {
+			fmt.Fprintf(w, "<th>%s</th>", html.EscapeString(h))
+		}
+		fmt.Fprint(w, "</tr>\n")
+		for _, row := range s.rows {
+			fmt.Fprint(w, "<tr>")
+			for _, c := range row {
+				fmt.Fprintf(w, "<td>%s</td>", html.EscapeString(c))
+			}
+			fmt.Fprint(w, "</tr>\n")
+		}
+		fmt.Fprint(w, "</table>\n")
+	}
+	fmt.Fprint(w, "</body>\n</html>\n")
+}
+
+// exportSummary writes the session summary in the given format, "HTML"
+// or "Markdown", to a file in the user's home directory.
+func (bw *BookmarksWindow) exportSummary(config *Config, format string) {
+	ext := util.Select(format == "HTML", "html", "md")
+	fn := filepath.Join(util.ExportDir(), fmt.Sprintf("vice-summary-%s.%s", time.Now().Format("20060102-150405"), ext))
+
+	sections := makeSessionSummary(bw.controlClient, config.DisplayRoot, bw.bookmarks)
+	title := "Session Summary: " + bw.controlClient.Status()
+
+	var sb strings.Builder
+	if format == "HTML" {
+		writeSummaryHTML(&sb, title, sections)
+	} else {
+		writeSummaryMarkdown(&sb, title, sections)
+	}
+
+	if err := os.WriteFile(fn, []byte(sb.String()), 0o644); err != nil {
+		bw.status = err.Error()
+	} else {
+		bw.status = "Saved " + fn
+	}
+}
@@ -0,0 +1,112 @@
+// degraded.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"time"
+
+	"github.com/mmp/vice/pkg/log"
+	"github.com/mmp/vice/pkg/renderer"
+)
+
+// When frames take too long to render--e.g., with 150+ aircraft on weak
+// hardware--vice switches to a degraded rendering mode so that the scope
+// stays responsive: line smoothing, which requires blending, is turned
+// off, and panes skip drawing that isn't essential (see
+// panes.Context.Degraded). Rendering goes back to normal once frames are
+// consistently fast again. An indicator is shown in the menu bar while
+// rendering is degraded.
+
+const (
+	// How long the average frame time must be above the threshold before
+	// rendering is degraded and how long it must be well below it before
+	// rendering goes back to normal; the latter is longer so that vice
+	// doesn't flip back and forth.
+	degradeDelay  = 2 * time.Second
+	recoverDelay  = 10 * time.Second
+	frameTimeGain = 0.1
+)
+
+// RenderingDegrader tracks the frame time and decides whether rendering
+// should be degraded.
+type RenderingDegrader struct {
+	// now returns the current time; it's time.Now unless replaced for
+	// testing.
+	now       func() time.Time
+	lastFrame time.Time
+	// Exponentially-weighted moving average of the frame time
+	average  time.Duration
+	degraded bool
+	// When the average frame time last crossed over to the side of the
+	// threshold that causes a change of mode; zero if it's on the other
+	// side.
+	crossed time.Time
+}
+
+// Frame should be called once each time through the main loop; it
+// returns whether rendering should be degraded for the next frame.
+func (d *RenderingDegrader) Frame(config *Config, r renderer.Renderer, lg *log.Logger) bool {
+	now := time.Now()
+	if d.now != nil {
+		now = d.now()
+	}
+	if d.lastFrame.IsZero() {
+		d.lastFrame = now
+		return false
+	}
+	ft := now.Sub(d.lastFrame)
+	d.lastFrame = now
+	d.average += time.Duration(frameTimeGain * float64(ft-d.average))
+
+	threshold := time.Duration(config.DegradedFrameTimeMS) * time.Millisecond
+	if config.DisableDegradedRendering {
+		d.setDegraded(false, config, r, lg)
+		return false
+	}
+
+	var change bool
+	var delay time.Duration
+	if d.degraded {
+		change, delay = d.average < threshold/2, recoverDelay
+	} else {
+		change, delay = d.average > threshold, degradeDelay
+	}
+
+	if !change {
+		d.crossed = time.Time{}
+	} else if d.crossed.IsZero() {
+		d.crossed = now
+	} else if now.Sub(d.crossed) > delay {
+		d.setDegraded(!d.degraded, config, r, lg)
+	}
+
+	return d.degraded
+}
+
+func (d *RenderingDegrader) setDegraded(degraded bool, config *Config, r renderer.Renderer, lg *log.Logger) {
+	if degraded == d.degraded {
+		return
+	}
+
+	d.degraded = degraded
+	d.crossed = time.Time{}
+	if degraded {
+		lg.Warnf("Average frame time %s; degrading rendering", d.average)
+		r.SetLineSmoothing(false)
+	} else {
+		lg.Infof("Average frame time %s; restoring normal rendering", d.average)
+		r.SetLineSmoothing(config.SmoothLines)
+	}
+}
+
+// Degraded returns whether rendering is currently degraded.
+func (d *RenderingDegrader) Degraded() bool {
+	return d.degraded
+}
+
+// AverageFrameTime returns the recent average frame time.
+func (d *RenderingDegrader) AverageFrameTime() time.Duration {
+	return d.average
+}
@@ -0,0 +1,123 @@
+// degraded_test.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mmp/vice/pkg/renderer"
+)
+
+// smoothingRenderer records calls to SetLineSmoothing; no other Renderer
+// methods may be called.
+type smoothingRenderer struct {
+	renderer.Renderer
+	smooth bool
+}
+
+func (r *smoothingRenderer) SetLineSmoothing(smooth bool) { r.smooth = smooth }
+
+// testDegrader returns a RenderingDegrader that uses a fake clock along
+// with a function that advances the clock by the given frame time and
+// runs a frame.
+func testDegrader(config *Config, r renderer.Renderer) (*RenderingDegrader, func(time.Duration) bool) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	d := &RenderingDegrader{now: func() time.Time { return now }}
+	d.Frame(config, r, nil)
+
+	return d, func(ft time.Duration) bool {
+		now = now.Add(ft)
+		return d.Frame(config, r, nil)
+	}
+}
+
+// runFrames runs frames of the given length for the given duration and
+// returns how long it took for rendering to change to the given mode or
+// -1 if it didn't.
+func runFrames(frame func(time.Duration) bool, ft, duration time.Duration, degraded bool) time.Duration {
+	for elapsed := time.Duration(0); elapsed < duration; {
+		elapsed += ft
+		if frame(ft) == degraded {
+			return elapsed
+		}
+	}
+	return -1
+}
+
+func TestRenderingDegraderTransitions(t *testing.T) {
+	config := &Config{}
+	config.DegradedFrameTimeMS = 50
+	config.SmoothLines = true
+	r := &smoothingRenderer{smooth: true}
+	d, frame := testDegrader(config, r)
+
+	// Fast frames never degrade.
+	if dt := runFrames(frame, 16*time.Millisecond, time.Minute, true); dt != -1 {
+		t.Fatalf("degraded after %s of fast frames", dt)
+	}
+
+	// Slow frames degrade once the average has been over the threshold
+	// for degradeDelay.
+	dt := runFrames(frame, 100*time.Millisecond, time.Minute, true)
+	if dt == -1 {
+		t.Fatalf("slow frames didn't degrade rendering")
+	} else if dt < degradeDelay || dt > degradeDelay+2*time.Second {
+		t.Errorf("degraded after %s of slow frames, expected a bit more than %s", dt, degradeDelay)
+	}
+	if !d.Degraded() || r.smooth {
+		t.Errorf("expected degraded rendering without line smoothing")
+	}
+
+	// Fast frames recover after recoverDelay.
+	dt = runFrames(frame, 10*time.Millisecond, time.Minute, false)
+	if dt == -1 {
+		t.Fatalf("fast frames didn't restore rendering")
+	} else if dt < recoverDelay || dt > recoverDelay+2*time.Second {
+		t.Errorf("recovered after %s of fast frames, expected a bit more than %s", dt, recoverDelay)
+	}
+	if d.Degraded() || !r.smooth {
+		t.Errorf("expected normal rendering with line smoothing")
+	}
+}
+
+func TestRenderingDegraderIgnoresSpikes(t *testing.T) {
+	config := &Config{}
+	config.DegradedFrameTimeMS = 50
+	r := &smoothingRenderer{}
+	_, frame := testDegrader(config, r)
+
+	runFrames(frame, 16*time.Millisecond, 10*time.Second, true)
+	// A second of slow frames isn't enough...
+	if dt := runFrames(frame, 100*time.Millisecond, time.Second, true); dt != -1 {
+		t.Fatalf("degraded after a %s spike", dt)
+	}
+	// ...and the count starts over once frames are fast again.
+	runFrames(frame, 16*time.Millisecond, 5*time.Second, true)
+	if dt := runFrames(frame, 100*time.Millisecond, time.Second, true); dt != -1 {
+		t.Fatalf("degraded after a second %s spike", dt)
+	}
+}
+
+func TestRenderingDegraderDisabled(t *testing.T) {
+	config := &Config{}
+	config.DegradedFrameTimeMS = 50
+	config.SmoothLines = true
+	r := &smoothingRenderer{smooth: true}
+	d, frame := testDegrader(config, r)
+
+	if runFrames(frame, 100*time.Millisecond, time.Minute, true) == -1 {
+		t.Fatalf("slow frames didn't degrade rendering")
+	}
+
+	// Disabling degraded rendering restores normal rendering immediately.
+	config.DisableDegradedRendering = true
+	if frame(100 * time.Millisecond) {
+		t.Errorf("still degraded after disabling degraded rendering")
+	}
+	if d.Degraded() || !r.smooth {
+		t.Errorf("expected normal rendering with line smoothing")
+	}
+}
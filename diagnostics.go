@@ -0,0 +1,142 @@
+// diagnostics.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/mmp/vice/pkg/log"
+	"github.com/mmp/vice/pkg/panes"
+	"github.com/mmp/vice/pkg/panes/stars"
+	"github.com/mmp/vice/pkg/sim"
+	"github.com/mmp/vice/pkg/util"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// reservedControlKeys gives the letters that are already used with the
+// control key, and what for.
+var reservedControlKeys = map[string]string{
+	"V": "pasting into the messages window",
+}
+
+// DiagnosticsWindow checks the user's configuration and the current
+// scenario's facility adaptation and lists the problems found. Problems
+// that involve a fix have a button that centers the STARS scope on it.
+type DiagnosticsWindow struct {
+	controlClient *sim.ControlClient
+	diags         []sim.Diagnostic
+}
+
+func MakeDiagnosticsWindow(controlClient *sim.ControlClient, config *Config, lg *log.Logger) *DiagnosticsWindow {
+	dw := &DiagnosticsWindow{controlClient: controlClient}
+	dw.check(config, lg)
+	return dw
+}
+
+func (dw *DiagnosticsWindow) check(config *Config, lg *log.Logger) {
+	dw.diags = checkConfig(config, lg)
+	if dw.controlClient != nil && dw.controlClient.Connected() {
+		dw.diags = append(dw.diags, dw.controlClient.State.CheckAdaptation()...)
+	}
+}
+
+// checkConfig checks the user's configuration and the files given on the
+// command line.
+func checkConfig(config *Config, lg *log.Logger) []sim.Diagnostic {
+	var diags []sim.Diagnostic
+
+	checkFile := func(fn, what string) {
+		if fn == "" || strings.HasPrefix(fn, "http://") || strings.HasPrefix(fn, "https://") {
+			return
+		}
+		if _, err := os.Stat(fn); err != nil {
+			diags = append(diags, sim.Diagnostic{Category: "Files", Message: what + ": " + err.Error()})
+		}
+	}
+	checkFile(*scenarioFilename, "scenario file")
+	checkFile(*videoMapFilename, "video map file")
+	checkFile(lg.LogFile, "log file")
+
+	for _, name := range util.SortedMapKeys(config.LayoutSnapshots) {
+		var root panes.DisplayNode
+		if err := json.Unmarshal(config.LayoutSnapshots[name], &root); err != nil {
+			diags = append(diags, sim.Diagnostic{
+				Category: "Layouts",
+				Message:  name + ": unable to load saved layout: " + err.Error(),
+			})
+		}
+	}
+
+	if key := config.maximizePaneKey(); key != "" {
+		if use, ok := reservedControlKeys[key]; ok {
+			diags = append(diags, sim.Diagnostic{
+				Category: "Keyboard",
+				Message:  "Maximize window shortcut Ctrl-" + key + " is also used for " + use,
+			})
+		}
+	}
+
+	return diags
+}
+
+func (dw *DiagnosticsWindow) Draw(config *Config, lg *log.Logger) (show bool) {
+	show = true
+	imgui.BeginV("Diagnostics", &show, imgui.WindowFlagsAlwaysAutoResize)
+
+	if imgui.Button("Check again") {
+		dw.check(config, lg)
+	}
+
+	if len(dw.diags) == 0 {
+		imgui.Text("No problems were found.")
+		imgui.End()
+		return
+	}
+
+	var scopes []*stars.STARSPane
+	config.DisplayRoot.VisitPanes(func(p panes.Pane) {
+		if sp, ok := p.(*stars.STARSPane); ok {
+			scopes = append(scopes, sp)
+		}
+	})
+
+	tableFlags := imgui.TableFlagsBordersV | imgui.TableFlagsBordersOuterH |
+		imgui.TableFlagsRowBg | imgui.TableFlagsSizingFixedFit
+	if imgui.BeginTableV("diagnostics", 3, tableFlags, imgui.Vec2{}, 0) {
+		imgui.TableSetupColumn("Category")
+		imgui.TableSetupColumn("Problem")
+		imgui.TableSetupColumn("")
+		imgui.TableHeadersRow()
+
+		for i, d := range dw.diags {
+			imgui.PushIDInt(i)
+			imgui.TableNextRow()
+			imgui.TableNextColumn()
+			imgui.Text(d.Category)
+			imgui.TableNextColumn()
+			if d.Warning {
+				imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{1, .8, 0, 1})
+			} else {
+				imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{1, .2, .2, 1})
+			}
+			imgui.Text(d.Message)
+			imgui.PopStyleColor()
+			imgui.TableNextColumn()
+			if d.Fix != "" && len(scopes) > 0 && imgui.Button("Show "+d.Fix) {
+				for _, sp := range scopes {
+					sp.CenterOn(d.Location)
+				}
+			}
+			imgui.PopID()
+		}
+		imgui.EndTable()
+	}
+
+	imgui.End()
+	return
+}
@@ -0,0 +1,221 @@
+// edct.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/sim"
+	"github.com/mmp/vice/pkg/util"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// edctAtRiskMargin is how close to the end of its EDCT window a departure
+// that is still on the ground is highlighted.
+const edctAtRiskMargin = 2 * time.Minute
+
+// EDCTWindow lists the user's departures in order of their expected
+// departure clearance times (EDCTs), with a countdown to each, and lets
+// the user assign EDCTs individually or import a list of them, as a
+// traffic management unit would issue.
+type EDCTWindow struct {
+	controlClient *sim.ControlClient
+
+	callsign string
+	edct     string
+	imports  string
+	result   string
+}
+
+func MakeEDCTWindow(controlClient *sim.ControlClient) *EDCTWindow {
+	return &EDCTWindow{controlClient: controlClient}
+}
+
+// parseEDCT converts a time given as HHMM in UTC to the time closest to
+// now with that hour and minute.
+func parseEDCT(s string, now time.Time) (time.Time, error) {
+	if len(s) != 4 {
+		return time.Time{}, errors.New(s + ": EDCT must be given as HHMM")
+	}
+	hhmm, err := strconv.Atoi(s)
+	if err != nil || hhmm/100 > 23 || hhmm%100 > 59 {
+		return time.Time{}, errors.New(s + ": invalid EDCT")
+	}
+
+	now = now.UTC()
+	t := time.Date(now.Year(), now.Month(), now.Day(), hhmm/100, hhmm%100, 0, 0, time.UTC)
+	if d := t.Sub(now); d < -12*time.Hour {
+		t = t.Add(24 * time.Hour)
+	} else if d > 12*time.Hour {
+		t = t.Add(-24 * time.Hour)
+	}
+	return t, nil
+}
+
+// departures returns the departures that the user is responsible for
+// that are either still on the ground or have an EDCT, sorted by EDCT.
+func (ew *EDCTWindow) departures() []*av.Aircraft {
+	c := ew.controlClient
+	var deps []*av.Aircraft
+	for _, ac := range c.Aircraft {
+		if (ac.WaitingForLaunch || !ac.EDCT.IsZero()) && c.State.DepartureController(ac, nil) == c.Callsign {
+			deps = append(deps, ac)
+		}
+	}
+	slices.SortFunc(deps, func(a, b *av.Aircraft) int {
+		// Aircraft without an EDCT go at the end.
+		if a.EDCT.IsZero() != b.EDCT.IsZero() {
+			return util.Select(a.EDCT.IsZero(), 1, -1)
+		}
+		if c := a.EDCT.Compare(b.EDCT); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Callsign, b.Callsign)
+	})
+	return deps
+}
+
+func (ew *EDCTWindow) set(callsign string, edct time.Time) {
+	ew.controlClient.SetEDCT(callsign, edct, nil, func(err error) {
+		ew.result = callsign + ": " + err.Error()
+	})
+}
+
+// importEDCTs assigns the EDCTs given in text, one "CALLSIGN HHMM" per
+// line.
+func (ew *EDCTWindow) importEDCTs(text string, now time.Time) {
+	var errs []string
+	n := 0
+	for _, line := range strings.Split(text, "\n") {
+		f := strings.Fields(line)
+		if len(f) == 0 {
+			continue
+		} else if len(f) != 2 {
+			errs = append(errs, strings.TrimSpace(line)+": expected callsign and EDCT")
+		} else if _, ok := ew.controlClient.Aircraft[f[0]]; !ok {
+			errs = append(errs, f[0]+": "+av.ErrNoAircraftForCallsign.Error())
+		} else if t, err := parseEDCT(f[1], now); err != nil {
+			errs = append(errs, f[0]+": "+err.Error())
+		} else {
+			ew.set(f[0], t)
+			n++
+		}
+	}
+	ew.result = fmt.Sprintf("Imported %d EDCTs", n)
+	if len(errs) > 0 {
+		ew.result += "\n" + strings.Join(errs, "\n")
+	}
+}
+
+func (ew *EDCTWindow) Draw() (show bool) {
+	show = true
+	imgui.BeginV("EDCTs", &show, imgui.WindowFlagsAlwaysAutoResize)
+
+	c := ew.controlClient
+	now := c.CurrentTime()
+	deps := ew.departures()
+
+	if len(deps) == 0 {
+		imgui.Text("You have no departures awaiting departure.")
+	} else {
+		tableFlags := imgui.TableFlagsBordersV | imgui.TableFlagsBordersOuterH |
+			imgui.TableFlagsRowBg | imgui.TableFlagsSizingFixedFit
+		if imgui.BeginTableV("edcts", 5, tableFlags, imgui.Vec2{}, 0) {
+			imgui.TableSetupColumn("Callsign")
+			imgui.TableSetupColumn("Airport")
+			imgui.TableSetupColumn("EDCT")
+			imgui.TableSetupColumn("Countdown")
+			imgui.TableSetupColumn("Status")
+			imgui.TableHeadersRow()
+
+			for _, ac := range deps {
+				imgui.PushID(ac.Callsign)
+				imgui.TableNextRow()
+				imgui.TableNextColumn()
+
+				atRisk := ac.EDCTAtRisk(now, edctAtRiskMargin)
+				if atRisk {
+					imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{1, .2, .2, 1})
+				}
+				if imgui.SelectableV(ac.Callsign, ac.Callsign == ew.callsign,
+					imgui.SelectableFlagsSpanAllColumns, imgui.Vec2{}) {
+					ew.callsign = ac.Callsign
+					ew.edct = util.Select(ac.EDCT.IsZero(), "", ac.EDCT.UTC().Format("1504"))
+				}
+				imgui.TableNextColumn()
+				if ac.FlightPlan != nil {
+					imgui.Text(ac.FlightPlan.DepartureAirport)
+				}
+				imgui.TableNextColumn()
+				if !ac.EDCT.IsZero() {
+					imgui.Text(ac.EDCT.UTC().Format("1504"))
+				}
+				imgui.TableNextColumn()
+				imgui.Text(ac.EDCTCountdown(now))
+				imgui.TableNextColumn()
+				switch {
+				case !ac.WaitingForLaunch:
+					imgui.Text("Departed")
+				case atRisk:
+					imgui.Text("At risk")
+				case ac.HeldForEDCT(now):
+					imgui.Text("Held")
+				default:
+					imgui.Text("Ready")
+				}
+				if atRisk {
+					imgui.PopStyleColor()
+				}
+				imgui.PopID()
+			}
+			imgui.EndTable()
+		}
+	}
+
+	imgui.Separator()
+
+	imgui.SetNextItemWidth(100)
+	imgui.InputTextV("EDCT (HHMM)", &ew.edct, imgui.InputTextFlagsCharsDecimal, nil)
+	idx := slices.IndexFunc(deps, func(ac *av.Aircraft) bool { return ac.Callsign == ew.callsign })
+	disable := idx == -1 || !deps[idx].WaitingForLaunch
+	uiStartDisable(disable)
+	imgui.SameLine()
+	if imgui.Button("Assign") {
+		if t, err := parseEDCT(ew.edct, now); err != nil {
+			ew.result = err.Error()
+		} else {
+			ew.set(ew.callsign, t)
+			ew.result = ""
+		}
+	}
+	imgui.SameLine()
+	if imgui.Button("Clear") {
+		ew.set(ew.callsign, time.Time{})
+		ew.edct, ew.result = "", ""
+	}
+	uiEndDisable(disable)
+
+	if imgui.CollapsingHeader("Import") {
+		imgui.Text("Enter one departure per line as \"CALLSIGN HHMM\".")
+		imgui.InputTextMultilineV("##import", &ew.imports, imgui.Vec2{X: 300, Y: 100}, imgui.InputTextFlagsCharsUppercase, nil)
+		if imgui.Button("Import EDCTs") {
+			ew.importEDCTs(ew.imports, now)
+		}
+	}
+
+	if ew.result != "" {
+		imgui.Text(ew.result)
+	}
+
+	imgui.End()
+	return
+}
@@ -0,0 +1,113 @@
+// intercept.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/sim"
+	"github.com/mmp/vice/pkg/util"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+const (
+	interceptApproach = iota
+	interceptFixCourse
+)
+
+// InterceptWindow is a vectoring aid: for a selected aircraft, it shows
+// the heading that will have it join its final approach course, or a
+// course through a fix, given its current position and the wind, and
+// lets the user issue that heading.
+type InterceptWindow struct {
+	controlClient *sim.ControlClient
+
+	callsign string
+	mode     int
+	fix      string
+	course   int32
+	angle    int32
+	result   string
+}
+
+func MakeInterceptWindow(controlClient *sim.ControlClient) *InterceptWindow {
+	return &InterceptWindow{
+		controlClient: controlClient,
+		angle:         30,
+	}
+}
+
+func (iw *InterceptWindow) Draw() (show bool) {
+	show = true
+	imgui.BeginV("Heading to Intercept", &show, imgui.WindowFlagsAlwaysAutoResize)
+
+	c := iw.controlClient
+	callsigns := util.FilterSlice(util.SortedMapKeys(c.Aircraft), func(callsign string) bool {
+		ac := c.Aircraft[callsign]
+		return ac.ControllingController == c.Callsign && ac.IsAirborne()
+	})
+	imgui.SetNextItemWidth(150)
+	if imgui.BeginComboV("Aircraft", iw.callsign, imgui.ComboFlagsHeightLarge) {
+		for _, callsign := range callsigns {
+			if imgui.SelectableV(callsign, callsign == iw.callsign, 0, imgui.Vec2{}) {
+				iw.callsign = callsign
+				iw.result = ""
+			}
+		}
+		imgui.EndCombo()
+	}
+
+	imgui.RadioButtonInt("Assigned approach", &iw.mode, interceptApproach)
+	imgui.SameLine()
+	imgui.RadioButtonInt("Course through fix", &iw.mode, interceptFixCourse)
+	if iw.mode == interceptFixCourse {
+		imgui.SetNextItemWidth(150)
+		imgui.InputTextV("Fix", &iw.fix, imgui.InputTextFlagsCharsUppercase, nil)
+		imgui.SetNextItemWidth(150)
+		imgui.InputIntV("Course", &iw.course, 5, 10, 0)
+		iw.course = int32(math.NormalizeHeading(float32(iw.course)))
+	}
+	imgui.SetNextItemWidth(150)
+	imgui.SliderIntV("Intercept angle", &iw.angle, 10, 45, "%d", 0)
+
+	imgui.Separator()
+
+	target := sim.InterceptTarget{Angle: float32(iw.angle)}
+	if iw.mode == interceptFixCourse {
+		target.Fix, target.Course = strings.TrimSpace(iw.fix), float32(iw.course)
+	}
+
+	if _, ok := c.Aircraft[iw.callsign]; !ok {
+		imgui.Text("Select an aircraft that you are controlling.")
+	} else if iw.mode == interceptFixCourse && target.Fix == "" {
+		imgui.Text("Enter the fix and course to intercept.")
+	} else if is, err := c.InterceptHeading(iw.callsign, target); err != nil {
+		imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{1, .2, .2, 1})
+		imgui.Text(err.Error())
+		imgui.PopStyleColor()
+	} else {
+		imgui.Text(fmt.Sprintf("Fly heading %03d for a %03d track", is.Heading, int(is.Track+0.5)))
+		imgui.Text(fmt.Sprintf("Intercept in %.1f nm", is.Distance))
+		imgui.Text("Command: " + is.Command)
+
+		if imgui.Button("Assign heading") {
+			callsign := iw.callsign
+			iw.result = "Pending"
+			c.RunAircraftCommands(callsign, is.Command, func(message string, remainingInput string) {
+				iw.result = util.Select(message != "", message, "Issued "+is.Command+" to "+callsign)
+			})
+		}
+		if iw.result != "" {
+			imgui.SameLine()
+			imgui.Text(iw.result)
+		}
+	}
+
+	imgui.End()
+	return
+}
@@ -0,0 +1,155 @@
+// landline.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mmp/vice/pkg/sim"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// landlineMessage is an entry in the landline panel's log of traffic.
+type landlineMessage struct {
+	Time     time.Time
+	Landline sim.LandlineType
+	Message  string
+}
+
+// LandlineWindow shows the landlines available at the user's position,
+// as given by the facility adaptation, and lets the user send messages
+// over them to the other positions on each line.
+type LandlineWindow struct {
+	controlClient *sim.ControlClient
+
+	selected string // name of the selected line
+	message  string
+	log      []landlineMessage
+	// ringing records when each position last called the user.
+	ringing map[string]time.Time
+	// override is set when an override line has broken in and the
+	// window should be brought to the front.
+	override bool
+}
+
+func MakeLandlineWindow(controlClient *sim.ControlClient) *LandlineWindow {
+	return &LandlineWindow{
+		controlClient: controlClient,
+		ringing:       make(map[string]time.Time),
+	}
+}
+
+// Received records an incoming landline message.
+func (lw *LandlineWindow) Received(event sim.Event, now time.Time) {
+	lw.log = append(lw.log, landlineMessage{Time: now, Landline: event.Landline, Message: event.Message})
+	lw.ringing[event.FromController] = now
+	lw.override = lw.override || event.Landline == sim.LandlineOverride
+}
+
+func (lw *LandlineWindow) send(line sim.Landline, msg string) {
+	c := lw.controlClient
+	msg = c.Callsign + ": " + msg
+	c.SendGlobalMessage(sim.GlobalMessage{
+		FromController: c.Callsign,
+		Message:        msg,
+		ToControllers:  line.Recipients(c.ConsolidatedPositions()),
+		Landline:       line.Type,
+	})
+	lw.log = append(lw.log, landlineMessage{Time: time.Now(), Landline: line.Type, Message: msg})
+}
+
+func (lw *LandlineWindow) Draw() (show bool) {
+	show = true
+	if lw.override {
+		imgui.SetNextWindowFocus()
+		lw.override = false
+	}
+	imgui.BeginV("Landlines", &show, imgui.WindowFlagsAlwaysAutoResize)
+
+	lines := lw.controlClient.Landlines()
+	if len(lines) == 0 {
+		imgui.Text("No landlines are available at this position.")
+		imgui.End()
+		return
+	}
+
+	positions := lw.controlClient.ConsolidatedPositions()
+	tableFlags := imgui.TableFlagsBordersV | imgui.TableFlagsBordersOuterH |
+		imgui.TableFlagsRowBg | imgui.TableFlagsSizingFixedFit
+	if imgui.BeginTableV("landlines", 3, tableFlags, imgui.Vec2{}, 0) {
+		imgui.TableSetupColumn("Type")
+		imgui.TableSetupColumn("Line")
+		imgui.TableSetupColumn("Positions")
+		imgui.TableHeadersRow()
+
+		for _, line := range lines {
+			imgui.PushID(line.Name)
+			imgui.TableNextRow()
+			imgui.TableNextColumn()
+
+			// Lines that have called recently are highlighted.
+			recipients := line.Recipients(positions)
+			ringing := false
+			for _, pos := range recipients {
+				if t, ok := lw.ringing[pos]; ok && time.Since(t) < radioReceiveHighlight {
+					ringing = true
+				}
+			}
+			if ringing {
+				imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{0.2, 1, 0.2, 1})
+			}
+			if imgui.SelectableV(line.Type.Abbreviation(), line.Name == lw.selected,
+				imgui.SelectableFlagsSpanAllColumns, imgui.Vec2{}) {
+				lw.selected = line.Name
+			}
+			imgui.TableNextColumn()
+			imgui.Text(line.Name)
+			imgui.TableNextColumn()
+			imgui.Text(strings.Join(recipients, ", "))
+			if ringing {
+				imgui.PopStyleColor()
+			}
+			imgui.PopID()
+		}
+		imgui.EndTable()
+	}
+
+	idx := slices.IndexFunc(lines, func(l sim.Landline) bool { return l.Name == lw.selected })
+	imgui.SetNextItemWidth(300)
+	send := imgui.InputTextV("##message", &lw.message, imgui.InputTextFlagsEnterReturnsTrue, nil)
+	imgui.SameLine()
+	disable := idx == -1 || strings.TrimSpace(lw.message) == ""
+	uiStartDisable(disable)
+	send = imgui.Button("Send") || send
+	uiEndDisable(disable)
+	if msg := strings.TrimSpace(lw.message); send && !disable {
+		lw.send(lines[idx], msg)
+		lw.message = ""
+	}
+
+	if len(lw.log) > 0 {
+		flags := imgui.TableFlagsBordersV | imgui.TableFlagsBordersOuterH | imgui.TableFlagsRowBg |
+			imgui.TableFlagsSizingFixedFit | imgui.TableFlagsScrollY
+		if imgui.BeginTableV("landlinelog", 3, flags, imgui.Vec2{X: 0, Y: 150}, 0) {
+			for i := len(lw.log) - 1; i >= 0; i-- {
+				m := lw.log[i]
+				imgui.TableNextRow()
+				imgui.TableNextColumn()
+				imgui.Text(m.Time.Format("15:04:05"))
+				imgui.TableNextColumn()
+				imgui.Text(m.Landline.Abbreviation())
+				imgui.TableNextColumn()
+				imgui.Text(m.Message)
+			}
+			imgui.EndTable()
+		}
+	}
+
+	imgui.End()
+	return
+}
@@ -0,0 +1,430 @@
+// layouts.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mmp/vice/pkg/log"
+	"github.com/mmp/vice/pkg/panes"
+	"github.com/mmp/vice/pkg/panes/stars"
+	"github.com/mmp/vice/pkg/platform"
+	"github.com/mmp/vice/pkg/renderer"
+	"github.com/mmp/vice/pkg/sim"
+	"github.com/mmp/vice/pkg/util"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// Layout snapshots are copies of the display hierarchy, saved under a
+// name, that the user can switch between, e.g. to go from a single large
+// scope to a layout with the flight strips on a second monitor. They are
+// stored in JSON form so that each switch gives a fresh set of panes.
+
+// maxLayoutShortcuts is the number of snapshots, in order of their names,
+// that can be switched to with Ctrl-Shift and a number key.
+const maxLayoutShortcuts = 9
+
+// SaveLayoutSnapshot saves the current layout under the given name,
+// replacing any existing snapshot with that name.
+func (c *Config) SaveLayoutSnapshot(name string) error {
+	b, err := json.Marshal(c.DisplayRoot)
+	if err != nil {
+		return err
+	}
+
+	if c.LayoutSnapshots == nil {
+		c.LayoutSnapshots = make(map[string]json.RawMessage)
+	}
+	c.LayoutSnapshots[name] = b
+	return nil
+}
+
+// SwitchLayoutSnapshot replaces the current layout with the named
+// snapshot; the panes in the current layout are deactivated and those in
+// the snapshot are activated and, if there is a sim, initialized for it.
+func (c *Config) SwitchLayoutSnapshot(name string, controlClient *sim.ControlClient, r renderer.Renderer,
+	p platform.Platform, eventStream *sim.EventStream, lg *log.Logger) error {
+	b, ok := c.LayoutSnapshots[name]
+	if !ok {
+		return fmt.Errorf("%s: no such layout", name)
+	}
+
+	root := &panes.DisplayNode{}
+	if err := json.Unmarshal(b, root); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	c.replaceDisplayRoot(root, controlClient, r, p, eventStream, lg)
+	lg.Infof("Switched to layout %q", name)
+	return nil
+}
+
+// layoutFile is the format of the files that layout snapshots are
+// exported to so that they can be shared with other users.
+type layoutFile struct {
+	Name string
+	// Version is the config version of the vice that wrote the file,
+	// which is used to upgrade the panes' settings when it's imported.
+	Version int
+	Layout  json.RawMessage
+}
+
+// ExportLayoutSnapshot writes the named snapshot to w.
+func (c *Config) ExportLayoutSnapshot(name string, w io.Writer) error {
+	b, ok := c.LayoutSnapshots[name]
+	if !ok {
+		return fmt.Errorf("%s: no such layout", name)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(layoutFile{Name: name, Version: CurrentConfigVersion, Layout: b})
+}
+
+// ImportLayoutSnapshot reads a layout snapshot that was written by
+// ExportLayoutSnapshot and adds it to the saved layouts. If there's
+// already one with the same name, a number is added to the name of the
+// imported one. The name it was saved under is returned.
+func (c *Config) ImportLayoutSnapshot(r io.Reader) (string, error) {
+	var lf layoutFile
+	if err := json.NewDecoder(r).Decode(&lf); err != nil {
+		return "", err
+	}
+	if lf.Version > CurrentConfigVersion {
+		return "", fmt.Errorf("%s: layout is from a newer version of vice", lf.Name)
+	}
+
+	// Make sure that it's valid and bring the panes' settings up to date.
+	root := &panes.DisplayNode{}
+	if err := json.Unmarshal(lf.Layout, root); err != nil {
+		return "", fmt.Errorf("%s: %w", lf.Name, err)
+	}
+	if lf.Version < CurrentConfigVersion {
+		root.VisitPanes(func(p panes.Pane) {
+			if up, ok := p.(panes.PaneUpgrader); ok {
+				up.Upgrade(lf.Version, CurrentConfigVersion)
+			}
+		})
+	}
+	b, err := json.Marshal(root)
+	if err != nil {
+		return "", err
+	}
+
+	name := strings.TrimSpace(lf.Name)
+	if name == "" {
+		name = "Imported"
+	}
+	if _, ok := c.LayoutSnapshots[name]; ok {
+		for i := 2; ; i++ {
+			if n := fmt.Sprintf("%s (%d)", name, i); c.LayoutSnapshots[n] == nil {
+				name = n
+				break
+			}
+		}
+	}
+
+	if c.LayoutSnapshots == nil {
+		c.LayoutSnapshots = make(map[string]json.RawMessage)
+	}
+	c.LayoutSnapshots[name] = b
+	return name, nil
+}
+
+// replaceDisplayRoot makes root the display hierarchy; the panes in the
+// current one are deactivated and those in root are activated and, if
+// there is a sim, initialized for it.
+func (c *Config) replaceDisplayRoot(root *panes.DisplayNode, controlClient *sim.ControlClient, r renderer.Renderer,
+	p platform.Platform, eventStream *sim.EventStream, lg *log.Logger) {
+	panes.Deactivate(c.DisplayRoot)
+	c.DisplayRoot, c.SafeModeLayout = root, false
+	panes.Activate(c.DisplayRoot, r, p, eventStream, lg)
+	if controlClient != nil && controlClient.Connected() {
+		panes.LoadedSim(c.DisplayRoot, controlClient.State, p, lg)
+	}
+}
+
+// layoutTemplates are predefined display hierarchies that a new layout
+// can start from. Make is passed a function that returns a pane of the
+// same type as the given one from the current layout, if there is one
+// that hasn't already been used, so that their settings carry over.
+var layoutTemplates = []struct {
+	Name string
+	Make func(reuse func(panes.Pane) panes.Pane) *panes.DisplayNode
+}{
+	{"Approach", func(reuse func(panes.Pane) panes.Pane) *panes.DisplayNode {
+		// The default: scope and messages with flight strips to the right.
+		return panes.NewDisplayPanes(reuse(stars.NewSTARSPane()), reuse(panes.NewMessagesPane()),
+			reuse(panes.NewFlightStripPane()))
+	}},
+	{"Center", func(reuse func(panes.Pane) panes.Pane) *panes.DisplayNode {
+		// No flight strips, so the scope gets the full width.
+		return (&panes.DisplayNode{Pane: reuse(panes.NewMessagesPane())}).SplitY(0.075,
+			&panes.DisplayNode{Pane: reuse(stars.NewSTARSPane())})
+	}},
+	{"Tower", func(reuse func(panes.Pane) panes.Pane) *panes.DisplayNode {
+		// A smaller scope alongside a wide strip bay.
+		scope := (&panes.DisplayNode{Pane: reuse(panes.NewMessagesPane())}).SplitY(0.1,
+			&panes.DisplayNode{Pane: reuse(stars.NewSTARSPane())})
+		return scope.SplitX(0.55, &panes.DisplayNode{Pane: reuse(panes.NewFlightStripPane())})
+	}},
+	{"Strip bay", func(reuse func(panes.Pane) panes.Pane) *panes.DisplayNode {
+		// Three even columns: flight strips, the scope, and messages.
+		return &panes.DisplayNode{
+			SplitLine:  panes.SplitLine{Axis: panes.SplitAxisX, Pos: 1. / 3},
+			MoreSplits: []panes.SplitLine{{Axis: panes.SplitAxisX, Pos: 2. / 3}},
+			Children: []*panes.DisplayNode{
+				&panes.DisplayNode{Pane: reuse(panes.NewFlightStripPane())},
+				&panes.DisplayNode{Pane: reuse(stars.NewSTARSPane())},
+				&panes.DisplayNode{Pane: reuse(panes.NewMessagesPane())},
+			},
+		}
+	}},
+}
+
+// NewLayoutFromTemplate replaces the current layout with one made from the
+// given template, reusing the current layout's panes where possible.
+func (c *Config) NewLayoutFromTemplate(template func(func(panes.Pane) panes.Pane) *panes.DisplayNode,
+	controlClient *sim.ControlClient, r renderer.Renderer, p platform.Platform, eventStream *sim.EventStream,
+	lg *log.Logger) {
+	var available []panes.Pane
+	c.DisplayRoot.VisitPanes(func(pane panes.Pane) {
+		if _, ok := pane.(*panes.SplitLine); !ok {
+			available = append(available, pane)
+		}
+	})
+	reuse := func(pane panes.Pane) panes.Pane {
+		idx := slices.IndexFunc(available, func(a panes.Pane) bool {
+			return fmt.Sprintf("%T", a) == fmt.Sprintf("%T", pane)
+		})
+		if idx == -1 {
+			return pane
+		}
+		pane = available[idx]
+		available = slices.Delete(available, idx, idx+1)
+		return pane
+	}
+
+	c.replaceDisplayRoot(template(reuse), controlClient, r, p, eventStream, lg)
+}
+
+func uiSwitchLayout(name string, config *Config, controlClient *sim.ControlClient, r renderer.Renderer,
+	p platform.Platform, eventStream *sim.EventStream, lg *log.Logger) {
+	if err := config.SwitchLayoutSnapshot(name, controlClient, r, p, eventStream, lg); err != nil {
+		lg.Errorf("%v", err)
+		uiShowModalDialog(NewModalDialogBox(&MessageModalClient{
+			title:   "Error",
+			message: "Unable to switch layouts: " + err.Error(),
+		}, p), true)
+	}
+}
+
+// uiDrawLayoutMenu draws the menu bar button and popup for saving,
+// switching between, and deleting layout snapshots.
+func uiDrawLayoutMenu(config *Config, controlClient *sim.ControlClient, r renderer.Renderer, p platform.Platform,
+	eventStream *sim.EventStream, lg *log.Logger) {
+	if imgui.Button(renderer.FontAwesomeIconThLarge) {
+		imgui.OpenPopup("layouts")
+	}
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Save and switch between window layouts")
+	}
+
+	if !imgui.BeginPopup("layouts") {
+		return
+	}
+
+	// As with the detach UI, the layout is only replaced after we're
+	// done with it.
+	var switchTo, remove string
+	names := util.SortedMapKeys(config.LayoutSnapshots)
+	if len(names) == 0 {
+		imgui.Text("No saved layouts")
+	}
+	for i, name := range names {
+		shortcut := ""
+		if i < maxLayoutShortcuts {
+			shortcut = fmt.Sprintf("Ctrl-Shift-%d", i+1)
+		}
+		if imgui.MenuItemV(name, shortcut, false, true) {
+			switchTo = name
+		}
+	}
+	if len(names) > 0 && imgui.BeginMenu("Delete") {
+		for _, name := range names {
+			if imgui.MenuItem(name) {
+				remove = name
+			}
+		}
+		imgui.EndMenu()
+	}
+	if len(names) > 0 && imgui.BeginMenu("Export") {
+		for _, name := range names {
+			if imgui.MenuItem(name) {
+				uiExportLayout(name, config, p, lg)
+			}
+		}
+		imgui.EndMenu()
+	}
+	if imgui.BeginMenu("Import") {
+		imgui.SetNextItemWidth(300)
+		imp := imgui.InputTextWithHintV("##layoutfile", "Layout file", &ui.layoutImportFile,
+			imgui.InputTextFlagsEnterReturnsTrue, nil)
+		imgui.SameLine()
+		imp = imgui.Button("Import") || imp
+		if imp && ui.layoutImportFile != "" {
+			uiImportLayout(ui.layoutImportFile, config, p, lg)
+			ui.layoutImportFile = ""
+			imgui.CloseCurrentPopup()
+		}
+		imgui.EndMenu()
+	}
+	uiDrawWorkspacesMenu(config, controlClient, r, p, eventStream, lg)
+
+	posTemplate := positionTemplate(controlClient)
+	applyPosTemplate := false
+	if posTemplate != nil {
+		applyPosTemplate = imgui.MenuItem("Apply facility template for " + controlClient.Callsign)
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip("Set up the layout, STARS lists, and monitored frequencies as recommended by the facility.\n" +
+				"The current layout is saved first.")
+		}
+	}
+
+	var template func(func(panes.Pane) panes.Pane) *panes.DisplayNode
+	if imgui.BeginMenu("New from template") {
+		for _, t := range layoutTemplates {
+			if imgui.MenuItem(t.Name) {
+				template = t.Make
+			}
+		}
+		imgui.EndMenu()
+	}
+
+	if imgui.MenuItemV("Go to window...", "Ctrl-P", false, true) {
+		ui.panePalette = &PanePalette{}
+	}
+
+	if imgui.MenuItemV("Lock layout", "", config.LockLayout, true) {
+		config.LockLayout = !config.LockLayout
+	}
+	if imgui.MenuItemV("Show window title bars", "", config.ShowPaneTitleBars, true) {
+		config.ShowPaneTitleBars = !config.ShowPaneTitleBars
+	}
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Draw a title bar above each window with buttons to show its settings,\n" +
+			"split it, maximize it, and close it.")
+	}
+
+	imgui.Separator()
+	imgui.SetNextItemWidth(200)
+	save := imgui.InputTextWithHintV("##layoutname", "Layout name", &ui.layoutName,
+		imgui.InputTextFlagsEnterReturnsTrue, nil)
+	imgui.SameLine()
+	name := strings.TrimSpace(ui.layoutName)
+	uiStartDisable(name == "")
+	save = imgui.Button("Save current layout") || save
+	uiEndDisable(name == "")
+	if save && name != "" {
+		if err := config.SaveLayoutSnapshot(name); err != nil {
+			lg.Errorf("%s: unable to save layout: %v", name, err)
+		}
+		ui.layoutName = ""
+		imgui.CloseCurrentPopup()
+	}
+
+	imgui.EndPopup()
+
+	if remove != "" {
+		delete(config.LayoutSnapshots, remove)
+	}
+	if switchTo != "" {
+		uiSwitchLayout(switchTo, config, controlClient, r, p, eventStream, lg)
+	}
+	if template != nil {
+		config.NewLayoutFromTemplate(template, controlClient, r, p, eventStream, lg)
+	}
+	if applyPosTemplate {
+		uiApplyPositionTemplate(posTemplate, config, controlClient, r, p, eventStream, lg)
+	}
+}
+
+// uiExportLayout writes the named layout snapshot to a file in the
+// export directory.
+func uiExportLayout(name string, config *Config, p platform.Platform, lg *log.Logger) {
+	fn := filepath.Join(util.ExportDir(), "vice-layout-"+sanitizeFilename(name)+".json")
+	err := func() error {
+		f, err := os.Create(fn)
+		if err != nil {
+			return err
+		}
+		if err := config.ExportLayoutSnapshot(name, f); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}()
+
+	msg := "Saved layout to " + fn
+	if err != nil {
+		lg.Errorf("%s: unable to export layout: %v", name, err)
+		msg = "Unable to export layout: " + err.Error()
+	}
+	uiShowModalDialog(NewModalDialogBox(&MessageModalClient{title: "Export Layout", message: msg}, p), false)
+}
+
+// uiImportLayout adds the layout snapshot in the given file to the saved
+// layouts.
+func uiImportLayout(fn string, config *Config, p platform.Platform, lg *log.Logger) {
+	var name string
+	f, err := os.Open(util.ResolvePortablePath(strings.TrimSpace(fn)))
+	if err == nil {
+		name, err = config.ImportLayoutSnapshot(f)
+		f.Close()
+	}
+
+	msg := fmt.Sprintf("Imported layout %q", name)
+	if err != nil {
+		lg.Errorf("%s: unable to import layout: %v", fn, err)
+		msg = "Unable to import layout: " + err.Error()
+	}
+	uiShowModalDialog(NewModalDialogBox(&MessageModalClient{title: "Import Layout", message: msg}, p), false)
+}
+
+// sanitizeFilename replaces characters that aren't allowed in filenames
+// on some systems.
+func sanitizeFilename(s string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`<>:"/\|?* `, r) {
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// uiCheckLayoutShortcuts switches to the nth layout snapshot, in order of
+// their names, when Ctrl-Shift-n is pressed.
+func uiCheckLayoutShortcuts(config *Config, controlClient *sim.ControlClient, r renderer.Renderer,
+	p platform.Platform, eventStream *sim.EventStream, lg *log.Logger) {
+	io := imgui.CurrentIO()
+	if io.WantCaptureKeyboard() || !io.KeyCtrlPressed() || !io.KeyShiftPressed() {
+		return
+	}
+
+	names := util.SortedMapKeys(config.LayoutSnapshots)
+	for i := 0; i < len(names) && i < maxLayoutShortcuts; i++ {
+		if imgui.IsKeyPressed(platform.ImguiKey1 + i) {
+			uiSwitchLayout(names[i], config, controlClient, r, p, eventStream, lg)
+			return
+		}
+	}
+}
@@ -0,0 +1,24 @@
+// layouts_test.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/mmp/vice/pkg/sim"
+)
+
+func TestPositionTemplateLayouts(t *testing.T) {
+	// Scenario validation must accept exactly the layout templates that
+	// ApplyPositionTemplate can use.
+	var names []string
+	for _, lt := range layoutTemplates {
+		names = append(names, lt.Name)
+	}
+	if !slices.Equal(names, sim.PositionTemplateLayouts) {
+		t.Errorf("layout templates %v don't match sim.PositionTemplateLayouts %v", names, sim.PositionTemplateLayouts)
+	}
+}
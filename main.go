@@ -15,9 +15,11 @@ import (
 	"log/slog"
 	_ "net/http/pprof"
 	"os"
+	"os/signal"
 	"runtime"
 	"runtime/debug"
 	"strings"
+	"syscall"
 	"time"
 
 	av "github.com/mmp/vice/pkg/aviation"
@@ -37,7 +39,9 @@ var (
 	//go:embed resources/version.txt
 	buildVersion string
 
-	// Command-line options are only used for developer features.
+	// Most command-line options are only used for developer features;
+	// -config, -position, and -connect allow launching vice directly into
+	// a particular session.
 	cpuprofile        = flag.String("cpuprofile", "", "write CPU profile to file")
 	memprofile        = flag.String("memprofile", "", "write memory profile to this file")
 	logLevel          = flag.String("loglevel", "info", "logging level: debug, info, warn, error")
@@ -45,13 +49,21 @@ var (
 	server            = flag.Bool("runserver", false, "run vice scenario server")
 	serverPort        = flag.Int("port", sim.ViceServerPort, "port to listen on when running server")
 	serverAddress     = flag.String("server", sim.ViceServerAddress+fmt.Sprintf(":%d", sim.ViceServerPort), "IP address of vice multi-controller server")
-	scenarioFilename  = flag.String("scenario", "", "filename of JSON file with a scenario definition")
+	scenarioFilename  = flag.String("scenario", "", "filename or URL of JSON file or zip package with a scenario definition")
 	videoMapFilename  = flag.String("videomap", "", "filename of JSON file with video map definitions")
 	broadcastMessage  = flag.String("broadcast", "", "message to broadcast to all active clients on the server")
 	broadcastPassword = flag.String("password", "", "password to authenticate with server for broadcast message")
 	resetSim          = flag.Bool("resetsim", false, "discard the saved simulation and do not try to resume it")
 	showRoutes        = flag.String("routes", "", "display the STARS, SIDs, and approaches known for the given airport")
 	listMaps          = flag.String("listmaps", "", "path to a video map file to list maps of (e.g., resources/videomaps/ZNY-videomaps.gob.zst)")
+	configFilename    = flag.String("config", "", "path to the configuration file to use instead of the default one")
+	position          = flag.String("position", "", "control position to sign on to; a new single-controller sim is started unless -connect is given")
+	callsign          = flag.String("callsign", "", "synonym for -position, since positions are identified by their callsigns")
+	sector            = flag.String("sector", "", "split configuration to use when starting a new sim; the -position, if given, must be its primary position")
+	connectSim        = flag.String("connect", "", "name of a running multi-controller sim to join, at the -position if given or otherwise as an observer")
+	simPassword       = flag.String("simpassword", "", "password for the multi-controller sim given with -connect")
+	portable          = flag.Bool("portable", false, "store the configuration, log, and caches in a \""+util.PortableDirName+"\" directory next to the executable")
+	safeMode          = flag.Bool("safemode", false, "start with the default layout and graphics settings, ignoring the saved sim and custom scenario and video map files")
 )
 
 func init() {
@@ -74,8 +86,28 @@ func main() {
 		fmt.Printf("FixConsole: %v\n", err)
 	}
 
+	if pos, err := checkAutoConnectFlags(*position, *callsign, *sector, *connectSim); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	} else {
+		*position = pos
+	}
+
+	// Portable mode determines where the log file goes, so it must be
+	// checked first.
+	if err := util.InitPortableMode(*portable); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to initialize portable mode: %v\n", err)
+	}
+	*scenarioFilename = util.ResolvePortablePath(*scenarioFilename)
+	*videoMapFilename = util.ResolvePortablePath(*videoMapFilename)
+
 	// Initialize the logging system first and foremost.
-	lg := log.New(*server, *logLevel)
+	logDir, err := util.ConfigDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to find user config dir: %v", err)
+		logDir = "."
+	}
+	lg := log.New(*server, *logLevel, logDir)
 
 	profiler, err := util.CreateProfiler(*cpuprofile, *memprofile)
 	if err != nil {
@@ -131,6 +163,7 @@ func main() {
 		var stats Stats
 		var render renderer.Renderer
 		var plat platform.Platform
+		var config *Config
 
 		// Catch any panics so that we can put up a dialog box and hopefully
 		// get a bug report.
@@ -138,6 +171,9 @@ func main() {
 			defer func() {
 				if err := recover(); err != nil {
 					lg.Error("Caught panic!", slog.String("stack", string(debug.Stack())))
+					if config != nil {
+						config.Autosave(plat, lg)
+					}
 					ShowFatalErrorDialog(render, plat, lg,
 						"Unfortunately an unexpected error has occurred and vice is unable to recover.\n"+
 							"Apologies! Please do file a bug and include the vice.log file for this session\nso that "+
@@ -153,6 +189,13 @@ func main() {
 		_ = imguiInit()
 
 		config, configErr := LoadOrMakeDefaultConfig(lg)
+		autosaveTime := checkAutosave(lg)
+		if *safeMode {
+			config.EnterSafeMode(lg)
+			*scenarioFilename, *videoMapFilename = "", ""
+		}
+		util.GetResourceCache().SetMaxSize(int64(config.ResourceCacheMB) << 20)
+		ui.telemetry = MakeTelemetry(config, *serverAddress, lg)
 
 		var controlClient *sim.ControlClient
 		var mgr *sim.ConnectionManager
@@ -196,12 +239,20 @@ func main() {
 		if configErr != nil {
 			ShowErrorDialog(plat, lg, "Configuration file is corrupt: %v", configErr)
 		}
+		if *safeMode {
+			uiShowModalDialog(NewModalDialogBox(&MessageModalClient{
+				title: "Safe Mode",
+				message: "vice has been started in safe mode with the default window layout and graphics settings.\n" +
+					"Your previous layout has been saved as \"" + safeModeLayoutName + "\" in the layouts menu.",
+			}, plat), true)
+		}
 		imgui.CurrentIO().SetClipboard(plat.GetClipboard())
 
 		render, err = renderer.NewOpenGL2Renderer(lg)
 		if err != nil {
 			panic(fmt.Sprintf("Unable to initialize OpenGL: %v", err))
 		}
+		render.SetLineSmoothing(config.SmoothLines)
 		renderer.FontsInit(render, plat)
 
 		eventStream := sim.NewEventStream(lg)
@@ -209,9 +260,11 @@ func main() {
 		uiInit(render, plat, config, eventStream, lg)
 
 		config.Activate(render, plat, eventStream, lg)
+		config.MaximizeStartPane(lg)
 
 		// After config.Activate(), if we have a loaded sim, get configured for it.
-		if config.Sim != nil && !*resetSim {
+		autoConnect := *position != "" || *sector != "" || *connectSim != ""
+		if config.Sim != nil && !*resetSim && !autoConnect {
 			if client, err := mgr.LoadLocalSim(config.Sim, lg); err != nil {
 				lg.Errorf("Error loading local sim: %v", err)
 			} else {
@@ -221,10 +274,26 @@ func main() {
 			}
 		}
 
+		var ac *AutoConnect
 		if !mgr.Connected() {
-			uiShowConnectDialog(mgr, false, config, plat, lg)
+			if autoConnect {
+				ac = MakeAutoConnect(strings.ToUpper(*position), *sector, *connectSim, *simPassword)
+			} else {
+				uiShowConnectDialog(mgr, false, config, plat, lg)
+			}
+		}
+
+		var autosaver Autosaver
+		if !autosaveTime.IsZero() {
+			autosaver.OfferRestore(autosaveTime, config, func() *sim.ControlClient { return controlClient },
+				render, plat, eventStream, lg)
 		}
 
+		// Autosave if we're interrupted; the user will be offered the
+		// autosave when vice is next started.
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
 		///////////////////////////////////////////////////////////////////////////
 		// Main event / rendering loop
 		lg.Info("Starting main loop")
@@ -245,18 +314,24 @@ func main() {
 			}
 
 			mgr.Update(eventStream, lg)
+			if ac != nil && ac.Update(mgr, config, plat, lg) {
+				ac = nil
+			}
 
 			// Inform imgui about input events from the user.
 			plat.ProcessEvents()
 
 			stats.redraws++
+			ui.telemetry.Frame()
+			degraded := ui.degrader.Frame(config, render, lg)
 
 			plat.NewFrame()
 			imgui.NewFrame()
 
 			// Generate and render vice draw lists
 			stats.drawPanes = panes.DrawPanes(config.DisplayRoot, plat, render, controlClient,
-				ui.menuBarHeight, &config.AudioEnabled, lg)
+				ui.menuBarHeight, &config.AudioEnabled, config.CompactMode, config.maximizePaneKey(),
+				config.LockLayout, config.ShowPaneTitleBars, degraded, lg)
 
 			// Draw the user interface
 			stats.drawUI = uiDraw(mgr, config, plat, render, controlClient, eventStream, lg)
@@ -264,6 +339,16 @@ func main() {
 			// Wait for vsync
 			plat.PostRender()
 
+			autosaver.Update(config, plat, lg)
+			select {
+			case sig := <-signals:
+				lg.Warnf("Caught %v; autosaving and exiting", sig)
+				config.Autosave(plat, lg)
+				mgr.Disconnect()
+				return
+			default:
+			}
+
 			// Periodically log current memory use, etc.
 			if stats.redraws%18000 == 0 {
 				lg.Debug("performance", slog.Any("stats", stats))
@@ -272,7 +357,12 @@ func main() {
 			if plat.ShouldStop() && len(ui.activeModalDialogs) == 0 {
 				// Do this while we're still running the event loop.
 				saveSim := mgr.ClientIsLocal()
+				ui.telemetry.Exit(config, controlClient)
 				config.SaveIfChanged(render, plat, controlClient, saveSim, lg)
+				RemoveAutosave(lg)
+				if err := util.GetResourceCache().Flush(); err != nil {
+					lg.Warnf("Download cache: %v", err)
+				}
 				mgr.Disconnect()
 				break
 			}
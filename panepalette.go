@@ -0,0 +1,114 @@
+// panepalette.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"slices"
+	"strconv"
+
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/panes"
+	"github.com/mmp/vice/pkg/platform"
+	"github.com/mmp/vice/pkg/util"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// PanePalette is opened with Ctrl-P; it lists the panes whose names match
+// what has been typed and jumps to the selected one, which is quicker
+// than finding a pane in a complex layout.
+type PanePalette struct {
+	query    string
+	selected int
+}
+
+type panePaletteMatch struct {
+	pane  panes.Pane
+	score int
+}
+
+// matches returns the panes whose names match the query, best match first
+// and otherwise in layout order.
+func (pp *PanePalette) matches(root *panes.DisplayNode) []panePaletteMatch {
+	var m []panePaletteMatch
+	root.VisitPanes(func(pane panes.Pane) {
+		if _, ok := pane.(*panes.SplitLine); ok {
+			return
+		}
+		if score, ok := util.FuzzyMatch(pp.query, panes.PaneName(pane)); ok {
+			m = append(m, panePaletteMatch{pane: pane, score: score})
+		}
+	})
+	slices.SortStableFunc(m, func(a, b panePaletteMatch) int { return b.score - a.score })
+	return m
+}
+
+// Draw draws the palette; it returns false once a pane has been chosen or
+// the palette has been dismissed.
+func (pp *PanePalette) Draw(config *Config, p platform.Platform) bool {
+	matches := pp.matches(config.DisplayRoot)
+
+	ds := p.DisplaySize()
+	imgui.SetNextWindowPosV(imgui.Vec2{X: ds[0] / 2, Y: ui.menuBarHeight + 20}, imgui.ConditionAlways,
+		imgui.Vec2{X: 0.5})
+	flags := imgui.WindowFlagsNoTitleBar | imgui.WindowFlagsAlwaysAutoResize | imgui.WindowFlagsNoMove |
+		imgui.WindowFlagsNoSavedSettings
+	imgui.BeginV("Go to window", nil, flags)
+
+	appearing := imgui.IsWindowAppearing()
+	if appearing {
+		imgui.SetKeyboardFocusHere()
+	}
+	imgui.SetNextItemWidth(float32(20 * ui.font.Size))
+	enter := imgui.InputTextWithHintV("##query", "Window name", &pp.query, imgui.InputTextFlagsEnterReturnsTrue, nil)
+	if imgui.IsItemEdited() {
+		pp.selected = 0
+	}
+
+	if imgui.IsKeyPressed(imgui.GetKeyIndex(imgui.KeyDownArrow)) {
+		pp.selected++
+	}
+	if imgui.IsKeyPressed(imgui.GetKeyIndex(imgui.KeyUpArrow)) {
+		pp.selected--
+	}
+	pp.selected = math.Clamp(pp.selected, 0, max(len(matches)-1, 0))
+
+	var chosen panes.Pane
+	for i, m := range matches {
+		if imgui.SelectableV(panes.PaneName(m.pane)+"##"+strconv.Itoa(i), i == pp.selected, 0, imgui.Vec2{}) {
+			chosen = m.pane
+		}
+	}
+	if len(matches) == 0 {
+		imgui.Text("No matching windows")
+	} else if enter {
+		chosen = matches[pp.selected].pane
+	}
+
+	imgui.Separator()
+	imgui.Text("Enter: go to window   Shift-Enter: maximize it   Escape: close")
+
+	// Clicking elsewhere dismisses the palette, as does escape.
+	done := imgui.IsKeyPressed(imgui.GetKeyIndex(imgui.KeyEscape)) ||
+		(!appearing && !imgui.IsWindowFocused())
+
+	imgui.End()
+
+	if chosen != nil {
+		panes.ShowPane(config.DisplayRoot, chosen, imgui.CurrentIO().KeyShiftPressed())
+		return false
+	}
+	return !done
+}
+
+// uiCheckPanePaletteShortcut opens the pane palette when Ctrl-P is
+// pressed.
+func uiCheckPanePaletteShortcut() {
+	io := imgui.CurrentIO()
+	if ui.panePalette == nil && !io.WantCaptureKeyboard() && io.KeyCtrlPressed() && !io.KeyShiftPressed() &&
+		imgui.IsKeyPressed(int('P')) {
+		ui.panePalette = &PanePalette{}
+	}
+}
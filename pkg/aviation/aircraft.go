@@ -30,6 +30,8 @@ type Aircraft struct {
 	Squawk              Squawk // actually squawking
 	Mode                TransponderMode
 	TempAltitude        int
+	TempHeading         int // Controller-entered; not an instruction to the pilot
+	TempSpeed           int // Ditto
 	FlightPlan          *FlightPlan
 	PointOutHistory     []string
 
@@ -44,6 +46,17 @@ type Aircraft struct {
 	HoldForRelease   bool
 	Released         bool // only used for hold for release
 	WaitingForLaunch bool // for departures
+	// If the release is only valid for a limited time, ReleaseExpiration
+	// gives the time when it lapses. ReleaseExpired is set if the aircraft
+	// didn't depart by then; it must be released again.
+	ReleaseExpiration time.Time
+	ReleaseExpired    bool
+	// EDCT is the expected departure clearance time assigned by traffic
+	// management, if any; the aircraft is expected to depart within
+	// EDCTTolerance of it. EDCTWarned records that the departure
+	// controller has been warned that it is at risk of missing it.
+	EDCT       time.Time
+	EDCTWarned bool
 
 	// The controller who gave approach clearance
 	ApproachController string
@@ -57,6 +70,8 @@ type Aircraft struct {
 	// Departure related state
 	DepartureContactAltitude   float32
 	DepartureContactController string
+	SID                        string
+	SIDIsRNAV                  bool
 
 	// Arrival-related state
 	GoAroundDistance    *float32
@@ -260,6 +275,11 @@ func (ac *Aircraft) DirectFix(fix string) []RadioTransmission {
 	return ac.transmitResponse(ac.Nav.DirectFix(strings.ToUpper(fix)))
 }
 
+func (ac *Aircraft) DirectFixRejoin(fix string, p math.Point2LL, rejoin string) []RadioTransmission {
+	wp := Waypoint{Fix: strings.ToUpper(fix), Location: p}
+	return ac.transmitResponse(ac.Nav.DirectFixRejoin(wp, strings.ToUpper(rejoin)))
+}
+
 func (ac *Aircraft) DepartFixHeading(fix string, hdg int) []RadioTransmission {
 	resp := ac.Nav.DepartFixHeading(strings.ToUpper(fix), float32(hdg))
 	return ac.transmitResponse(resp)
@@ -406,6 +426,7 @@ func (ac *Aircraft) InitializeDeparture(ap *Airport, departureAirport string, de
 	wp = append(wp, dep.RouteWaypoints...)
 	wp = util.FilterSlice(wp, func(wp Waypoint) bool { return !wp.Location.IsZero() })
 
+	ac.SID, ac.SIDIsRNAV = exitRoute.SID, exitRoute.RNAV
 	if exitRoute.SID != "" {
 		ac.FlightPlan.Route = exitRoute.SID + " " + dep.Route
 	} else {
@@ -542,6 +563,52 @@ func (ac *Aircraft) MagneticVariation() float32 {
 	return ac.Nav.FlightState.MagneticVariation
 }
 
+// ReleaseStatus summarizes the state of a held departure's release for
+// display: "EXP" if the release expired, the time left in the release
+// window as "m:ss", or an empty string otherwise.
+func (ac *Aircraft) ReleaseStatus(now time.Time) string {
+	if ac.ReleaseExpired {
+		return "EXP"
+	}
+	if !ac.Released || ac.ReleaseExpiration.IsZero() {
+		return ""
+	}
+	s := int(max(0, ac.ReleaseExpiration.Sub(now).Seconds()))
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
+
+// EDCTTolerance gives how early or late an aircraft may depart relative to
+// its EDCT.
+const EDCTTolerance = 5 * time.Minute
+
+// HeldForEDCT returns true if the aircraft has an EDCT and it is too early
+// for it to depart.
+func (ac *Aircraft) HeldForEDCT(now time.Time) bool {
+	return !ac.EDCT.IsZero() && now.Before(ac.EDCT.Add(-EDCTTolerance))
+}
+
+// EDCTAtRisk returns true if the aircraft is still waiting to depart and
+// less than the given amount of time remains before the end of its EDCT
+// window.
+func (ac *Aircraft) EDCTAtRisk(now time.Time, margin time.Duration) bool {
+	return ac.WaitingForLaunch && !ac.EDCT.IsZero() && !now.Before(ac.EDCT.Add(EDCTTolerance-margin))
+}
+
+// EDCTCountdown returns the time until the aircraft's EDCT as "m:ss", or
+// the time since it as "+m:ss" once it has passed. An empty string is
+// returned if the aircraft doesn't have an EDCT.
+func (ac *Aircraft) EDCTCountdown(now time.Time) string {
+	if ac.EDCT.IsZero() {
+		return ""
+	}
+	d, sign := ac.EDCT.Sub(now), ""
+	if d < 0 {
+		d, sign = -d, "+"
+	}
+	s := int(d.Seconds())
+	return fmt.Sprintf("%s%d:%02d", sign, s/60, s%60)
+}
+
 func (ac *Aircraft) IsAirborne() bool {
 	return ac.Nav.IsAirborne()
 }
@@ -618,18 +685,44 @@ func (ac *Aircraft) DistanceAlongRoute(fix string) (float32, error) {
 	return ac.Nav.DistanceAlongRoute(fix)
 }
 
+// CWTCategories lists the valid consolidated wake turbulence categories.
+var CWTCategories = []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "NOWGT"}
+
 func (ac *Aircraft) CWT() string {
 	perf, ok := DB.AircraftPerformance[ac.FlightPlan.BaseType()]
 	if !ok {
 		return "NOWGT"
 	}
-	cwt := []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "NOWGT"}
-	if !slices.Contains(cwt, perf.Category.CWT) {
+	if !slices.Contains(CWTCategories, perf.Category.CWT) {
 		return "NOWGT"
 	}
 	return perf.Category.CWT
 }
 
+// WeightClass returns the aircraft's legacy (pre-CWT) weight class: "J"
+// for super, "H" for heavy, "B" for the B757, "L" for large, and "S" for
+// small. It returns an empty string if the weight class isn't known,
+// including when the aircraft has no flight plan.
+func (ac *Aircraft) WeightClass() string {
+	if ac.FlightPlan == nil {
+		return ""
+	}
+	perf, ok := DB.AircraftPerformance[ac.FlightPlan.BaseType()]
+	if !ok {
+		return ""
+	}
+	switch perf.WeightClass {
+	case "J", "H", "L":
+		return perf.WeightClass
+	case "M":
+		return "B"
+	case "S", "S+":
+		return "S"
+	default:
+		return ""
+	}
+}
+
 ///////////////////////////////////////////////////////////////////////////
 // RedirectedHandoff methods
 
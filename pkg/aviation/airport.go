@@ -658,6 +658,7 @@ func (ap *Airport) PostDeserialize(icao string, loc Locator, nmPerLongitude floa
 
 type ExitRoute struct {
 	SID              string        `json:"sid"`
+	RNAV             bool          `json:"rnav"` // is the SID an RNAV procedure?
 	AssignedAltitude int           `json:"assigned_altitude"`
 	ClearedAltitude  int           `json:"cleared_altitude"`
 	SpeedRestriction int           `json:"speed_restriction"`
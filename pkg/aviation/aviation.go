@@ -164,6 +164,39 @@ func (m METAR) String() string {
 	return strings.Join([]string{m.AirportICAO, m.Time, auto, m.Wind, m.Weather, m.Altimeter, m.Rmk}, " ")
 }
 
+// ObservationTime returns the time at which the METAR was issued. METARs
+// only encode the day of the month, so the given current time is used to
+// determine the month and year. False is returned if the METAR's time
+// isn't of the expected "DDHHMMZ" form.
+func (m METAR) ObservationTime(now time.Time) (time.Time, bool) {
+	if len(m.Time) != 7 || m.Time[6] != 'Z' {
+		return time.Time{}, false
+	}
+	v, err := strconv.Atoi(m.Time[:6])
+	if err != nil {
+		return time.Time{}, false
+	}
+	day, hour, minute := v/10000, (v/100)%100, v%100
+	if day < 1 || day > 31 || hour > 23 || minute > 59 {
+		return time.Time{}, false
+	}
+
+	now = now.UTC()
+	t := time.Date(now.Year(), now.Month(), day, hour, minute, 0, 0, time.UTC)
+	if t.After(now.Add(time.Hour)) {
+		// It must be from the previous month.
+		t = time.Date(now.Year(), now.Month()-1, day, hour, minute, 0, 0, time.UTC)
+	}
+	return t, true
+}
+
+// AltimeterHundredths returns the METAR's altimeter setting in
+// hundredths of an inch of mercury (e.g., 2992).
+func (m METAR) AltimeterHundredths() (int, bool) {
+	alt, err := strconv.Atoi(strings.TrimPrefix(m.Altimeter, "A"))
+	return alt, err == nil
+}
+
 type ATIS struct {
 	Airport  string
 	AppDep   string
@@ -335,6 +368,18 @@ func (fp FlightPlan) BaseType() string {
 }
 
 func (fp FlightPlan) TypeWithoutSuffix() string {
+	if icaoType, wake, _, _, ok := icaoTypeFields(fp.AircraftType); ok {
+		// ICAO format; use the wake category to give the FAA prefix.
+		switch wake {
+		case "H":
+			return "H/" + icaoType
+		case "J":
+			return "J/" + icaoType
+		default:
+			return icaoType
+		}
+	}
+
 	// try to chop off equipment suffix
 	actypeFields := strings.Split(fp.AircraftType, "/")
 	switch len(actypeFields) {
@@ -369,6 +414,33 @@ type WindModel interface {
 	AverageWindVector() [2]float32
 }
 
+// GroundSpeedAlongCourse returns the ground speed in knots of an aircraft
+// with the given true airspeed that is tracking the given course, which
+// should be a unit vector, in the given wind, a vector in knots pointing
+// in the direction the wind is blowing. The aircraft is assumed to crab
+// into any crosswind so that its track follows the course.
+func GroundSpeedAlongCourse(course [2]float32, tas float32, wind [2]float32) float32 {
+	headwind := math.Dot(course, wind)
+	crosswind := course[0]*wind[1] - course[1]*wind[0]
+	if math.Abs(crosswind) >= tas {
+		// The aircraft can't hold the course; it's all crab.
+		return math.Max(0, headwind)
+	}
+	return math.Sqrt(math.Sqr(tas)-math.Sqr(crosswind)) + headwind
+}
+
+// HeadingForTrack returns the heading in degrees that an aircraft with
+// the given true airspeed must fly in order for its ground track to be
+// the given track in the given wind, which is specified as in
+// GroundSpeedAlongCourse. If the crosswind is stronger than the
+// airspeed, the aircraft crabs directly into it.
+func HeadingForTrack(track, tas float32, wind [2]float32) float32 {
+	course := [2]float32{math.Sin(math.Radians(track)), math.Cos(math.Radians(track))}
+	crosswind := course[0]*wind[1] - course[1]*wind[0]
+	wca := math.Degrees(math.SafeASin(crosswind / tas))
+	return math.NormalizeHeading(track + wca)
+}
+
 ///////////////////////////////////////////////////////////////////////////
 
 type RadarSite struct {
@@ -478,6 +550,31 @@ func (a *AirspaceVolume) Inside(p math.Point2LL, alt int) bool {
 	}
 }
 
+// IntersectsSegment returns true if the line segment (p0, p1) passes
+// through the lateral extent of the airspace volume; altitude isn't
+// considered.
+func (a *AirspaceVolume) IntersectsSegment(p0, p1 math.Point2LL, nmPerLongitude float32) bool {
+	switch a.Type {
+	case AirspaceVolumePolygon:
+		if math.PointInPolygon2LL(p0, a.Vertices) || math.PointInPolygon2LL(p1, a.Vertices) {
+			return true
+		}
+		for i := range a.Vertices {
+			v0, v1 := a.Vertices[i], a.Vertices[(i+1)%len(a.Vertices)]
+			if math.SegmentSegmentIntersect(p0, p1, v0, v1) {
+				return true
+			}
+		}
+		return false
+	case AirspaceVolumeCircle:
+		pc := math.LL2NM(a.Center, nmPerLongitude)
+		d := math.PointSegmentDistance(pc, math.LL2NM(p0, nmPerLongitude), math.LL2NM(p1, nmPerLongitude))
+		return d < a.Radius
+	default:
+		panic("unhandled AirspaceVolume type")
+	}
+}
+
 func (a *AirspaceVolume) GenerateDrawCommands(cb *renderer.CommandBuffer, nmPerLongitude float32) {
 	ld := renderer.GetLinesDrawBuilder()
 
@@ -891,6 +988,8 @@ type VideoMapLibrary struct {
 type videoMapToLoad struct {
 	referenced map[string]interface{}
 	filesystem fs.FS
+	// sectorFile is set for maps that come from a EuroScope sector file.
+	sectorFile *SectorFile
 }
 
 type LoadedVideoMaps struct {
@@ -910,6 +1009,13 @@ func MakeVideoMapLibrary() *VideoMapLibrary {
 // in the file are actually used; the loading code uses this information to
 // skip the work of generating CommandBuffers for unused video maps.
 func (ml *VideoMapLibrary) AddFile(filesystem fs.FS, filename string, referenced map[string]interface{}, e *util.ErrorLogger) {
+	if strings.HasSuffix(strings.ToLower(filename), ".sct") {
+		if sf := LoadSectorFile(filesystem, filename, e); sf != nil {
+			ml.AddSectorFile(filename, sf, referenced, e)
+		}
+		return
+	}
+
 	// Load the manifest and do initial error checking
 	mf, _ := strings.CutSuffix(filename, ".zst")
 	mf, _ = strings.CutSuffix(mf, "-videomaps.gob")
@@ -951,27 +1057,53 @@ func (ml *VideoMapLibrary) AddFile(filesystem fs.FS, filename string, referenced
 	}
 }
 
-// loadVideoMap handles loading the given map; it runs asynchronously and
-// returns the result via the ml.ch chan.
-func (v videoMapToLoad) load(filename string, manifest map[string]interface{}) (map[string]*VideoMap, error) {
-	f, err := v.filesystem.Open(filename)
-	if err != nil {
-		return nil, err
+// AddSectorFile adds the diagrams from a EuroScope sector file that has
+// already been loaded to the library as video maps, using the given
+// filename for them.
+func (ml *VideoMapLibrary) AddSectorFile(filename string, sf *SectorFile, referenced map[string]interface{}, e *util.ErrorLogger) {
+	manifest := make(map[string]interface{})
+	for name := range sf.Diagrams {
+		manifest[name] = nil
 	}
-	defer f.Close()
+	ml.manifests[filename] = manifest
 
-	r := io.Reader(f)
-	if strings.HasSuffix(strings.ToLower(filename), ".zst") {
-		zr, _ := zstd.NewReader(r, zstd.WithDecoderConcurrency(0))
-		defer zr.Close()
-		r = zr
+	for name := range referenced {
+		if _, ok := manifest[name]; name != "" && !ok {
+			e.Error(fmt.Errorf("%s: video map %q in \"stars_maps\" not found", filename, name))
+		}
 	}
 
-	// Initial decoding of the gob file.
+	ml.toLoad[filename] = videoMapToLoad{
+		referenced: util.DuplicateMap(referenced),
+		sectorFile: sf,
+	}
+}
+
+// loadVideoMap handles loading the given map; it runs asynchronously and
+// returns the result via the ml.ch chan.
+func (v videoMapToLoad) load(filename string, manifest map[string]interface{}) (map[string]*VideoMap, error) {
 	var maps []VideoMap
-	dec := gob.NewDecoder(r)
-	if err := dec.Decode(&maps); err != nil {
-		return nil, err
+	if v.sectorFile != nil {
+		maps = v.sectorFile.VideoMaps()
+	} else {
+		f, err := v.filesystem.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		r := io.Reader(f)
+		if strings.HasSuffix(strings.ToLower(filename), ".zst") {
+			zr, _ := zstd.NewReader(r, zstd.WithDecoderConcurrency(0))
+			defer zr.Close()
+			r = zr
+		}
+
+		// Initial decoding of the gob file.
+		dec := gob.NewDecoder(r)
+		if err := dec.Decode(&maps); err != nil {
+			return nil, err
+		}
 	}
 
 	// We'll return the maps via a map from the map name to the associated
@@ -1100,6 +1232,9 @@ type MultiUserController struct {
 	Departures       []string `json:"departures"`
 	Arrivals         []string `json:"arrivals"` // TEMPORARY for inbound flows transition
 	InboundFlows     []string `json:"inbound_flows"`
+	// Airspace gives the names of the airspace volumes that the position
+	// owns in the split.
+	Airspace []string `json:"airspace"`
 }
 
 ///////////////////////////////////////////////////////////////////////////
@@ -5,9 +5,13 @@
 package aviation
 
 import (
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/mmp/vice/pkg/math"
 	"github.com/mmp/vice/pkg/rand"
+	"github.com/mmp/vice/pkg/util"
 )
 
 func TestFrequencyFormat(t *testing.T) {
@@ -178,3 +182,463 @@ func TestDirectlyBehindCWTSeparation(t *testing.T) {
 		}
 	}
 }
+
+func TestMETARObservationTime(t *testing.T) {
+	now := time.Date(2024, time.March, 2, 3, 30, 0, 0, time.UTC)
+	type testcase struct {
+		time   string
+		expect time.Time
+		ok     bool
+	}
+	for _, tc := range []testcase{
+		testcase{time: "020251Z", expect: time.Date(2024, time.March, 2, 2, 51, 0, 0, time.UTC), ok: true},
+		testcase{time: "292351Z", expect: time.Date(2024, time.February, 29, 23, 51, 0, 0, time.UTC), ok: true},
+		testcase{time: "0202512", ok: false},
+		testcase{time: "022551Z", ok: false},
+		testcase{time: "", ok: false},
+	} {
+		obs, ok := METAR{Time: tc.time}.ObservationTime(now)
+		if ok != tc.ok {
+			t.Errorf("ObservationTime(%q) ok = %v. Expected %v", tc.time, ok, tc.ok)
+		} else if ok && !obs.Equal(tc.expect) {
+			t.Errorf("ObservationTime(%q) = %v. Expected %v", tc.time, obs, tc.expect)
+		}
+	}
+}
+
+func TestParseTFRGeoJSON(t *testing.T) {
+	geojson := `{"type": "FeatureCollection", "features": [
+  {"type": "Feature", "geometry": {"type": "Point", "coordinates": [-73, 40]}, "properties": {}},
+  {"type": "Feature",
+   "geometry": {"type": "Polygon", "coordinates": [[[-74, 40], [-73, 40], [-73, 41], [-74, 41], [-74, 40]]]},
+   "properties": {"NAME": "4/1234", "Floor": "SFC", "ceiling": "FL180",
+                  "effective": "2024-03-02T12:00:00Z", "expires": "2024-03-02T18:00:00Z"}}]}`
+
+	tfrs, err := ParseTFRGeoJSON([]byte(geojson))
+	if err != nil {
+		t.Fatalf("ParseTFRGeoJSON: %v", err)
+	}
+	if len(tfrs) != 1 {
+		t.Fatalf("Got %d TFRs. Expected 1", len(tfrs))
+	}
+
+	tfr := tfrs[0]
+	if tfr.Name != "4/1234" || tfr.Floor != 0 || tfr.Ceiling != 18000 {
+		t.Errorf("Got name %q floor %d ceiling %d. Expected \"4/1234\", 0, 18000", tfr.Name, tfr.Floor, tfr.Ceiling)
+	}
+	if len(tfr.Polygons) != 1 || len(tfr.Polygons[0]) != 4 {
+		t.Errorf("Got polygons %v. Expected a single polygon with 4 vertices", tfr.Polygons)
+	}
+	if s := tfr.AltitudeString(); s != "SFC-180" {
+		t.Errorf("AltitudeString() = %q. Expected \"SFC-180\"", s)
+	}
+
+	if tfr.Active(time.Date(2024, time.March, 2, 11, 0, 0, 0, time.UTC)) {
+		t.Errorf("TFR unexpectedly active before its effective time")
+	}
+	if !tfr.Active(time.Date(2024, time.March, 2, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("TFR unexpectedly inactive at its effective time")
+	}
+	if tfr.Active(time.Date(2024, time.March, 2, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("TFR unexpectedly active at its expiration time")
+	}
+
+	if !tfr.Inside(math.Point2LL{-73.5, 40.5}, 5000) {
+		t.Errorf("Point unexpectedly outside of TFR")
+	}
+	if tfr.Inside(math.Point2LL{-73.5, 40.5}, 19000) {
+		t.Errorf("Point above TFR ceiling unexpectedly inside")
+	}
+	if tfr.Inside(math.Point2LL{-72.5, 40.5}, 5000) {
+		t.Errorf("Point laterally outside of TFR unexpectedly inside")
+	}
+}
+
+func TestParseDOF(t *testing.T) {
+	dof := `  CURRENCY DATE = 10/06/24
+OAS#      V CO ST CITY            LATITUDE     LONGITUDE    OBSTACLE            AGL   AMSL LT H AC MAR FAA             ACTION
+------------------------------------------------------------------------------------------------------------------------------------
+01-000301 O US AL ALBERTVILLE      34 15 45.00N 086 12 40.00W TOWER              1 00249 01323 R 4 D U A 1995ASO00780OE  C 2015282
+36-001234 V US NY NEW YORK         40 44 54.36N 073 59 08.36W T-L TWR            2 00385 00412 N 2 C U   2009AEA01234OE  A 2009100
+`
+	obs := parseDOF(strings.NewReader(dof))
+	if len(obs) != 2 {
+		t.Fatalf("Got %d obstacles. Expected 2", len(obs))
+	}
+
+	o := obs[0]
+	if o.Id != "01-000301" || o.Type != "TOWER" || o.AGL != 249 || o.MSL != 1323 || !o.Lighted {
+		t.Errorf("Got %+v for first obstacle", o)
+	}
+	if math.Abs(o.Location[1]-34.2625) > 1e-4 || math.Abs(o.Location[0]+86.211111) > 1e-4 {
+		t.Errorf("Got location %v for first obstacle. Expected [-86.211111 34.2625]", o.Location)
+	}
+
+	o = obs[1]
+	if o.Type != "T-L TWR" || o.AGL != 385 || o.MSL != 412 || o.Lighted {
+		t.Errorf("Got %+v for second obstacle", o)
+	}
+}
+
+func TestFlightPlanFormats(t *testing.T) {
+	type test struct {
+		actype, remarks string
+		faa, suffix     string
+		rvsm, gnss      bool
+		base            string
+	}
+	for _, tc := range []test{
+		{actype: "B738/L", faa: "B738/L", suffix: "L", rvsm: true, gnss: true, base: "B738"},
+		{actype: "H/B744/W", faa: "H/B744/W", suffix: "W", rvsm: true, base: "B744"},
+		{actype: "C172/G", faa: "C172/G", suffix: "G", gnss: true, base: "C172"},
+		{actype: "H/B77W", faa: "H/B77W", base: "B77W"},
+		{actype: "B77W/H-SDE2E3FGHIJ3J5RWXY/LB1D1", remarks: "PBN/A1B1C1D1 DOF/241015 RMK/TCAS",
+			faa: "H/B77W/L", suffix: "L", rvsm: true, gnss: true, base: "B77W"},
+		{actype: "A320/M-SDRW/S", remarks: "PBN/D2", faa: "A320/L", suffix: "L", rvsm: true, gnss: true, base: "A320"},
+		{actype: "PA28/L-SDR/C", faa: "PA28/I", suffix: "I", base: "PA28"},
+		{actype: "C150/L-S/N", faa: "C150/X", suffix: "X", base: "C150"},
+	} {
+		fp := FlightPlan{AircraftType: tc.actype, Remarks: tc.remarks}
+		if fp.FAAAircraftType() != tc.faa {
+			t.Errorf("%s: got FAA type %q, expected %q", tc.actype, fp.FAAAircraftType(), tc.faa)
+		}
+		if fp.EquipmentSuffix() != tc.suffix {
+			t.Errorf("%s: got suffix %q, expected %q", tc.actype, fp.EquipmentSuffix(), tc.suffix)
+		}
+		if c := fp.Capabilities(); c.RVSM != tc.rvsm || c.GNSS != tc.gnss {
+			t.Errorf("%s: got capabilities %+v", tc.actype, c)
+		}
+		if fp.BaseType() != tc.base {
+			t.Errorf("%s: got base type %q, expected %q", tc.actype, fp.BaseType(), tc.base)
+		}
+
+		// Round trip through the ICAO format should preserve the suffix.
+		icao := FlightPlan{AircraftType: fp.ICAOAircraftType(), Remarks: tc.remarks}
+		if icao.EquipmentSuffix() != fp.EquipmentSuffix() && fp.EquipmentSuffix() != "" {
+			t.Errorf("%s: ICAO %q gives suffix %q", tc.actype, icao.AircraftType, icao.EquipmentSuffix())
+		}
+	}
+
+	fp := FlightPlan{Remarks: "PBN/A1B1 NAV/RNVD1E2A1 DOF/241015 RMK/TCAS EQUIPPED RMK/NON RVSM"}
+	info := fp.OtherInformation()
+	if info["PBN"] != "A1B1" || info["NAV"] != "RNVD1E2A1" || info["RMK"] != "TCAS EQUIPPED NON RVSM" {
+		t.Errorf("got item 18 fields %+v", info)
+	}
+	if dof, ok := fp.DateOfFlight(); !ok || !dof.Equal(time.Date(2024, 10, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got date of flight %v (%v)", dof, ok)
+	}
+}
+
+func TestParseSpokenTransmission(t *testing.T) {
+	telephony := map[string]string{"AAL": "AMERICAN", "JBU": "JET BLUE", "BAW": "SPEEDBIRD",
+		"SHT": "SPEEDBIRD"}
+
+	for _, test := range []struct {
+		transcript string
+		callsign   string
+		commands   []string
+	}{
+		{"American one two three, turn left heading two seven zero, descend and maintain four thousand",
+			"AAL123", []string{"L270", "D40"}},
+		{"jetblue 45 heavy climb and maintain flight level two three zero", "JBU45", []string{"C230"}},
+		{"Jet Blue four five fly heading three six zero, reduce speed to one eight zero knots",
+			"JBU45", []string{"H360", "S180"}},
+		{"speedbird niner alpha maintain 11,000 maintain two one zero knots", "BAW9A SHT9A", []string{"A110", "S210"}},
+		{"November one two three alpha bravo, turn right two zero degrees, descend and maintain eight thousand five hundred",
+			"N123AB", []string{"R20D", "D85"}},
+		{"american 12 cleared I.L.S. runway two two left approach", "AAL12", []string{"CI22L"}},
+		{"american 12 expect the RNAV yankee runway four approach", "AAL12", []string{"EY4"}},
+		{"american 12 proceed direct camrn, cleared straight in ILS runway 4 right", "AAL12", []string{"DCAMRN", "CSII4R"}},
+		{"aal12 squawk 4512 say altitude", "AAL12", []string{"SQ4512", "SA"}},
+		{"american 12 contact departure one two four point three five", "AAL12", []string{"FC"}},
+		{"american 12 contact tower", "AAL12", []string{"TO"}},
+	} {
+		callsigns, commands, err := ParseSpokenTransmission(test.transcript, telephony)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", test.transcript, err)
+		}
+		if callsign := strings.Join(callsigns, " "); callsign != test.callsign {
+			t.Errorf("%q: got callsign %q, expected %q", test.transcript, callsign, test.callsign)
+		}
+		if strings.Join(commands, " ") != strings.Join(test.commands, " ") {
+			t.Errorf("%q: got commands %v, expected %v", test.transcript, commands, test.commands)
+		}
+	}
+
+	if _, _, err := ParseSpokenTransmission("delta one two three turn left heading two seven zero", telephony); err != ErrNoSpokenCallsign {
+		t.Errorf("expected ErrNoSpokenCallsign for unknown airline, got %v", err)
+	}
+	if _, _, err := ParseSpokenTransmission("american one two three good morning", telephony); err != ErrNoSpokenInstructions {
+		t.Errorf("expected ErrNoSpokenInstructions, got %v", err)
+	}
+}
+
+func TestGroundSpeedAlongCourse(t *testing.T) {
+	north := [2]float32{0, 1}
+	for _, test := range []struct {
+		wind [2]float32
+		gs   float32
+	}{
+		{[2]float32{0, 0}, 250},   // calm
+		{[2]float32{0, -50}, 200}, // headwind
+		{[2]float32{0, 50}, 300},  // tailwind
+		{[2]float32{70, 0}, 240},  // crosswind
+		{[2]float32{300, 0}, 0},   // can't hold the course
+	} {
+		if gs := GroundSpeedAlongCourse(north, 250, test.wind); math.Abs(gs-test.gs) > 0.01 {
+			t.Errorf("wind %v: got ground speed %f, expected %f", test.wind, gs, test.gs)
+		}
+	}
+}
+
+func TestHeadingForTrack(t *testing.T) {
+	for _, test := range []struct {
+		track   float32
+		wind    [2]float32
+		heading float32
+	}{
+		{0, [2]float32{0, 0}, 0},       // calm
+		{90, [2]float32{0, -125}, 60},  // wind from the north
+		{0, [2]float32{125, 0}, 330},   // wind from the west
+		{270, [2]float32{-80, 0}, 270}, // tailwind
+		{0, [2]float32{300, 0}, 270},   // can't hold the track; crab into it
+	} {
+		if hdg := HeadingForTrack(test.track, 250, test.wind); math.HeadingDifference(hdg, test.heading) > 0.01 {
+			t.Errorf("track %f wind %v: got heading %f, expected %f", test.track, test.wind, hdg, test.heading)
+		}
+	}
+}
+
+func TestReleaseStatus(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, test := range []struct {
+		ac     Aircraft
+		status string
+	}{
+		{Aircraft{}, ""},
+		{Aircraft{Released: true}, ""},
+		{Aircraft{Released: true, ReleaseExpiration: now.Add(272 * time.Second)}, "4:32"},
+		{Aircraft{Released: true, ReleaseExpiration: now.Add(-time.Second)}, "0:00"},
+		{Aircraft{ReleaseExpired: true}, "EXP"},
+	} {
+		if s := test.ac.ReleaseStatus(now); s != test.status {
+			t.Errorf("case %d: got %q, expected %q", i, s, test.status)
+		}
+	}
+}
+
+func TestEDCT(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, test := range []struct {
+		ac        Aircraft
+		countdown string
+		held      bool
+		atRisk    bool
+	}{
+		{Aircraft{WaitingForLaunch: true}, "", false, false},
+		{Aircraft{WaitingForLaunch: true, EDCT: now.Add(12*time.Minute + 5*time.Second)}, "12:05", true, false},
+		{Aircraft{WaitingForLaunch: true, EDCT: now.Add(5 * time.Minute)}, "5:00", false, false},
+		{Aircraft{WaitingForLaunch: true, EDCT: now.Add(-4 * time.Minute)}, "+4:00", false, true},
+		{Aircraft{WaitingForLaunch: true, EDCT: now.Add(-10 * time.Minute)}, "+10:00", false, true},
+		{Aircraft{EDCT: now.Add(-4 * time.Minute)}, "+4:00", false, false},
+	} {
+		if c := test.ac.EDCTCountdown(now); c != test.countdown {
+			t.Errorf("case %d: got countdown %q, expected %q", i, c, test.countdown)
+		}
+		if h := test.ac.HeldForEDCT(now); h != test.held {
+			t.Errorf("case %d: got held %v, expected %v", i, h, test.held)
+		}
+		if r := test.ac.EDCTAtRisk(now, 2*time.Minute); r != test.atRisk {
+			t.Errorf("case %d: got at risk %v, expected %v", i, r, test.atRisk)
+		}
+	}
+}
+
+func TestPhraseologyFormatAltitude(t *testing.T) {
+	for i, test := range []struct {
+		set      string
+		ta       int
+		alt      float32
+		expected string
+	}{
+		{"faa", 0, 12000, "12,000"},
+		{"faa", 0, 18000, "FL180"},
+		{"faa", 0, 800, "800"},
+		{"icao", 0, 4000, "4000 feet"},
+		{"icao", 0, 12000, "flight level 120"},
+		{"icao", 7000, 6000, "6000 feet"},
+		{"uk", 0, 5000, "altitude 5000 feet"},
+		{"uk", 0, 8000, "flight level 80"},
+		{"unknown", 0, 12000, "12,000"},
+	} {
+		ps := GetPhraseologySet(test.set)
+		if s := ps.FormatAltitude(test.alt, test.ta); s != test.expected {
+			t.Errorf("case %d: got %q, expected %q", i, s, test.expected)
+		}
+		if test.set == "faa" {
+			if s := FormatAltitude(test.alt); s != test.expected {
+				t.Errorf("case %d: FormatAltitude gave %q, expected %q", i, s, test.expected)
+			}
+		}
+	}
+}
+
+func TestParseSectorFile(t *testing.T) {
+	sct := `; test sector
+#define COLOR_Runway 8421504
+[INFO]
+Test Sector
+TEST_CTR
+EGLL
+N051.28.39.000
+W000.27.41.000
+60
+37
+-1
+1
+
+[VOR]
+LON 113.600 N051.29.14.150 W000.27.59.990
+
+[FIXES]
+ABBOT N051.50.00.000 E001.00.00.000
+
+[AIRPORT]
+EGLL 118.500 N051.28.39.000 W000.27.41.000 D
+
+[RUNWAY]
+09L 27R 089 269 N051.28.39.000 W000.29.06.000 N051.28.39.000 W000.26.00.000 EGLL Heathrow
+
+[STAR]
+ABBOT arrival              ABBOT ABBOT LON LON COLOR_Runway
+                           LON LON N051.28.39.000 W000.27.41.000
+
+[GEO]
+EGLL Taxiways N051.00.00.000 W000.10.00.000 N051.00.00.000 W000.20.00.000 taxiway
+
+[REGIONS]
+REGIONNAME EGLL Apron
+apron N051.00.00.000 W000.00.00.000
+      N051.00.00.000 W000.01.00.000
+      N051.01.00.000 W000.01.00.000
+`
+	ese := `[POSITIONS]
+EGLL_N_APP:Heathrow Director:119.720:LN:N:EGLL:APP:-:-:0401:0407
+
+[AIRSPACE]
+SECTORLINE:south
+COORD:N051.00.00.000:W001.00.00.000
+COORD:N051.00.00.000:E001.00.00.000
+SECTORLINE:east
+COORD:N052.00.00.000:E001.00.00.000
+COORD:N051.00.00.000:E001.00.00.000
+SECTORLINE:northwest
+COORD:N052.00.00.000:E001.00.00.000
+COORD:N052.00.00.000:W001.00.00.000
+COORD:N051.00.00.000:W001.00.00.000
+SECTOR:LTMA:0:24500
+OWNER:LN
+BORDER:south:east:northwest
+`
+
+	var e util.ErrorLogger
+	sf := ParseSectorFile(strings.NewReader(sct), strings.NewReader(ese), &e)
+	if e.HaveErrors() {
+		t.Fatalf("unexpected errors: %s", e.String())
+	}
+
+	if sf.Name != "Test Sector" {
+		t.Errorf("got name %q", sf.Name)
+	}
+	for _, fix := range []string{"LON", "ABBOT", "EGLL"} {
+		if _, ok := sf.Fixes[fix]; !ok {
+			t.Errorf("%s: fix not found", fix)
+		}
+	}
+
+	for name, strips := range map[string]int{"EGLL runways": 1, "ABBOT arrival": 1, "EGLL Taxiways": 1, "EGLL Apron": 1} {
+		if len(sf.Diagrams[name]) != strips {
+			t.Errorf("%s: got %d line strips, expected %d", name, len(sf.Diagrams[name]), strips)
+		}
+	}
+	if n := len(sf.Diagrams["ABBOT arrival"][0]); n != 3 {
+		t.Errorf("expected continued STAR segments to be joined into 3 points; got %d", n)
+	}
+	if n := len(sf.Diagrams["EGLL Apron"][0]); n != 4 {
+		t.Errorf("expected closed region with 4 points; got %d", n)
+	}
+
+	if len(sf.Positions) != 1 {
+		t.Fatalf("expected 1 position, got %d", len(sf.Positions))
+	}
+	if ctrl := sf.Positions[0].Controller(); ctrl.Callsign != "EGLL_N_APP" || ctrl.Frequency != NewFrequency(119.72) ||
+		ctrl.SectorId != "LN" {
+		t.Errorf("unexpected controller %+v", *ctrl)
+	}
+
+	if len(sf.Sectors) != 1 {
+		t.Fatalf("expected 1 sector, got %d", len(sf.Sectors))
+	}
+	s := sf.Sectors[0]
+	if s.Name != "LTMA" || s.Floor != 0 || s.Ceiling != 24500 {
+		t.Errorf("unexpected sector %+v", s)
+	}
+	if len(s.Boundary) != 4 {
+		t.Errorf("expected 4 boundary vertices, got %d: %v", len(s.Boundary), s.Boundary)
+	}
+	if !math.PointInPolygon2LL(math.Point2LL{0, 51.5}, s.Boundary) {
+		t.Errorf("expected point to be inside sector boundary %v", s.Boundary)
+	}
+}
+
+func TestWeightClass(t *testing.T) {
+	db := DB
+	defer func() { DB = db }()
+	DB = &StaticDatabase{AircraftPerformance: map[string]AircraftPerformance{
+		"A388": {WeightClass: "J"},
+		"B744": {WeightClass: "H"},
+		"B752": {WeightClass: "M"},
+		"A320": {WeightClass: "L"},
+		"C172": {WeightClass: "S"},
+		"BE20": {WeightClass: "S+"},
+	}}
+
+	for _, test := range []struct {
+		aircraftType, weightClass string
+	}{
+		{"A388", "J"}, {"H/B744", "H"}, {"B752", "B"}, {"A320", "L"}, {"C172", "S"}, {"BE20", "S"}, {"ZZZZ", ""},
+	} {
+		ac := &Aircraft{Callsign: "N123", FlightPlan: &FlightPlan{AircraftType: test.aircraftType}}
+		if wc := ac.WeightClass(); wc != test.weightClass {
+			t.Errorf("%s: got weight class %q, expected %q", test.aircraftType, wc, test.weightClass)
+		}
+	}
+
+	// Unassociated tracks don't have a flight plan.
+	if wc := (&Aircraft{Callsign: "N123"}).WeightClass(); wc != "" {
+		t.Errorf("got weight class %q for an aircraft without a flight plan", wc)
+	}
+}
+
+func TestAssignSpeedOutOfRange(t *testing.T) {
+	var nav Nav
+	nav.Perf.Speed.Landing = 120
+	nav.Perf.Speed.MaxTAS = 300
+	spd := float32(250)
+	nav.Speed.Assigned = &spd
+
+	for _, s := range []float32{100, 350} {
+		if r := nav.AssignSpeed(s, false); !r.Unexpected {
+			t.Errorf("%.0f knots: expected an unexpected response, got %q", s, r.Message)
+		}
+		if nav.Speed.Assigned == nil || *nav.Speed.Assigned != 250 {
+			t.Errorf("%.0f knots: assigned speed changed", s)
+		}
+	}
+
+	if r := nav.AssignSpeed(200, false); r.Unexpected {
+		t.Errorf("200 knots: unexpected response %q", r.Message)
+	} else if nav.Speed.Assigned == nil || *nav.Speed.Assigned != 200 {
+		t.Errorf("200 knots: speed not assigned")
+	}
+}
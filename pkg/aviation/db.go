@@ -13,7 +13,9 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"regexp"
 	"slices"
 	"strconv"
 	"strings"
@@ -42,6 +44,7 @@ type StaticDatabase struct {
 	ERAMAdaptations     map[string]ERAMAdaptation
 	TRACONs             map[string]TRACON
 	MVAs                map[string][]MVA // TRACON -> MVAs
+	Obstacles           []Obstacle
 }
 
 type FAAAirport struct {
@@ -179,6 +182,8 @@ func init() {
 	go func() { db.MVAs = parseMVAs(); wg.Done() }()
 	wg.Add(1)
 	go func() { db.ERAMAdaptations = parseAdaptations(); wg.Done() }()
+	wg.Add(1)
+	go func() { db.Obstacles = parseObstacles(); wg.Done() }()
 	wg.Wait()
 
 	for icao, ap := range airports {
@@ -345,8 +350,7 @@ func parseAircraftPerformance() map[string]AircraftPerformance {
 
 		ap[ac.ICAO] = ac
 
-		cwt := []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "NOWGT"}
-		if !slices.Contains(cwt, ac.Category.CWT) {
+		if !slices.Contains(CWTCategories, ac.Category.CWT) {
 			fmt.Fprintf(os.Stderr, "%s: invalid CWT category provided\n", ac.Category.CWT)
 		}
 		if ac.Rate.Climb < 500 || ac.Rate.Climb > 5000 {
@@ -702,6 +706,109 @@ func parseMVAs() map[string][]MVA {
 	return mvas
 }
 
+// Obstacle is a charted obstacle from the FAA's Digital Obstacle File.
+type Obstacle struct {
+	Id       string
+	Type     string
+	Location math.Point2LL
+	AGL, MSL int
+	Lighted  bool
+}
+
+// Matches the fields following the longitude in a DOF record: obstacle
+// type, quantity, AGL height, MSL height, and lighting.
+var dofFieldsRe = regexp.MustCompile(`^(.*?)\s+(\d+)\s+(\d{5})\s+(\d{5})\s+(\S)`)
+
+// parseDOF parses obstacles in the FAA Digital Obstacle File format;
+// header and malformed lines are skipped.
+func parseDOF(r io.Reader) []Obstacle {
+	var obs []Obstacle
+	parseDMS := func(s string) (float32, bool) {
+		// e.g. "34 12 13.00N" or "087 10 25.00W"
+		f := strings.Fields(s[:len(s)-1])
+		if len(f) != 3 {
+			return 0, false
+		}
+		d, err0 := strconv.Atoi(f[0])
+		m, err1 := strconv.Atoi(f[1])
+		sec, err2 := strconv.ParseFloat(f[2], 64)
+		if err0 != nil || err1 != nil || err2 != nil {
+			return 0, false
+		}
+		v := float32(d) + float32(m)/60 + float32(sec)/3600
+		if h := s[len(s)-1]; h == 'S' || h == 'W' {
+			v = -v
+		}
+		return v, true
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) < 95 {
+			continue
+		}
+
+		lat, ok := parseDMS(line[35:47])
+		if !ok {
+			continue
+		}
+		long, ok := parseDMS(line[48:61])
+		if !ok {
+			continue
+		}
+		m := dofFieldsRe.FindStringSubmatch(strings.TrimSpace(line[62:]))
+		if m == nil {
+			continue
+		}
+		agl, _ := strconv.Atoi(m[3])
+		msl, _ := strconv.Atoi(m[4])
+
+		obs = append(obs, Obstacle{
+			Id:       strings.TrimSpace(line[:9]),
+			Type:     m[1],
+			Location: math.Point2LL{long, lat},
+			AGL:      agl,
+			MSL:      msl,
+			Lighted:  m[5] != "N" && m[5] != "U",
+		})
+	}
+	return obs
+}
+
+// parseObstacles loads obstacles from any DOF files (e.g., as downloaded
+// from https://www.faa.gov/air_traffic/flight_info/aeronav/digital_products/dof/)
+// found in the obstacles/ resources directory. Since the full database
+// is large, none are included by default and only obstacles of at least
+// 200' AGL are retained.
+func parseObstacles() []Obstacle {
+	var obs []Obstacle
+	_ = util.WalkResources("obstacles", func(path string, d fs.DirEntry, filesystem fs.FS, err error) error {
+		if err != nil {
+			// Most likely the directory doesn't exist.
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		for _, o := range parseDOF(bytes.NewReader(util.LoadResource(path))) {
+			if o.AGL >= 200 {
+				obs = append(obs, o)
+			}
+		}
+		return nil
+	})
+	return obs
+}
+
+// ObstaclesNear returns the obstacles within the given distance of the
+// point.
+func (d StaticDatabase) ObstaclesNear(p math.Point2LL, nm float32) []Obstacle {
+	return util.FilterSlice(d.Obstacles, func(o Obstacle) bool {
+		return math.NMDistance2LL(p, o.Location) <= nm
+	})
+}
+
 func parseARTCCsAndTRACONs() (map[string]ARTCC, map[string]TRACON) {
 	artccJSON := util.LoadResource("artccs.json")
 	var artccs map[string]ARTCC
@@ -0,0 +1,483 @@
+// pkg/aviation/euroscope.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package aviation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/util"
+)
+
+// SectorFile holds the parts of a EuroScope sector file (.sct) and its
+// extension file (.ese) that vice can use, so that facility data from
+// outside North America can be used without first converting it.
+type SectorFile struct {
+	Name   string
+	Center math.Point2LL
+	// Fixes holds the VORs, NDBs, fixes, and airports.
+	Fixes map[string]math.Point2LL
+	// Diagrams holds the line drawings from the ARTCC, airway, SID, STAR,
+	// GEO, REGIONS, and RUNWAY sections, indexed by name; they are turned
+	// into video maps.
+	Diagrams map[string][][]math.Point2LL
+	// Positions and Sectors come from the .ese file.
+	Positions []SectorFilePosition
+	Sectors   []SectorFileSector
+}
+
+// SectorFilePosition is a controller position from the POSITIONS section
+// of a .ese file.
+type SectorFilePosition struct {
+	Callsign   string
+	Name       string
+	Frequency  Frequency
+	Identifier string
+	Prefix     string
+	Suffix     string
+}
+
+// SectorFileSector is a sector from the AIRSPACE section of a .ese file;
+// its boundary is assembled from the sector lines given for its border.
+type SectorFileSector struct {
+	Name     string
+	Floor    int
+	Ceiling  int
+	Boundary []math.Point2LL
+}
+
+// LoadSectorFile loads the given .sct file as well as the .ese file with
+// the same base name, if there is one.
+func LoadSectorFile(filesystem fs.FS, filename string, e *util.ErrorLogger) *SectorFile {
+	f, err := filesystem.Open(filename)
+	if err != nil {
+		e.Error(err)
+		return nil
+	}
+	defer f.Close()
+
+	var ese io.Reader
+	base, _ := strings.CutSuffix(filename, ".sct")
+	for _, ext := range []string{".ese", ".ESE"} {
+		if ef, err := filesystem.Open(base + ext); err == nil {
+			defer ef.Close()
+			ese = ef
+			break
+		}
+	}
+
+	return ParseSectorFile(f, ese, e)
+}
+
+// ParseSectorFile parses a EuroScope sector file and, if ese is non-nil,
+// its extension file. Errors are reported for lines with coordinates that
+// can't be parsed or that refer to unknown fixes; the LABELS and FREETEXT
+// sections, colors, and the other parts of the files that vice has no
+// use for are ignored.
+func ParseSectorFile(sct io.Reader, ese io.Reader, e *util.ErrorLogger) *SectorFile {
+	sf := &SectorFile{
+		Fixes:    make(map[string]math.Point2LL),
+		Diagrams: make(map[string][][]math.Point2LL),
+	}
+
+	e.Push("sector file")
+	sf.parseSCT(sct, e)
+	e.Pop()
+
+	if ese != nil {
+		e.Push("sector extension file")
+		sf.parseESE(ese, e)
+		e.Pop()
+	}
+
+	return sf
+}
+
+// sectorFileLines calls f with the section name and fields of each line
+// in the file that isn't blank, a comment, or a #define, along with the
+// line's number.
+func sectorFileLines(r io.Reader, sep func(string) []string, f func(section string, fields []string, line int)) error {
+	scanner := bufio.NewScanner(r)
+	section := ""
+	for line := 1; scanner.Scan(); line++ {
+		s, _, _ := strings.Cut(scanner.Text(), ";")
+		s = strings.TrimRight(s, " \t\r")
+		if strings.TrimSpace(s) == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+			section = strings.ToUpper(s[1 : len(s)-1])
+			continue
+		}
+		f(section, sep(s), line)
+	}
+	return scanner.Err()
+}
+
+func (sf *SectorFile) parseSCT(r io.Reader, e *util.ErrorLogger) {
+	var info []string
+	lastSection := ""
+	diagram := "" // name of the current SID, STAR, GEO, or region drawing
+	var region []math.Point2LL
+
+	endRegion := func() {
+		if len(region) > 1 {
+			sf.Diagrams[diagram] = append(sf.Diagrams[diagram], append(region, region[0]))
+		}
+		region = nil
+	}
+
+	err := sectorFileLines(r, strings.Fields, func(section string, f []string, line int) {
+		errorf := func(s string, args ...any) {
+			e.ErrorString("line %d: %s", line, fmt.Sprintf(s, args...))
+		}
+
+		if section != lastSection {
+			endRegion()
+			diagram, lastSection = "", section
+		}
+
+		switch section {
+		case "INFO":
+			info = append(info, strings.Join(f, " "))
+			if len(info) == 5 {
+				sf.Name = info[0]
+				if p, ok := sf.coordinate(info[3], info[4]); ok {
+					sf.Center = p
+				} else {
+					errorf("%s %s: invalid center", info[3], info[4])
+				}
+			}
+
+		case "VOR", "NDB", "AIRPORT":
+			// ID frequency lat lon [class]
+			if len(f) < 4 {
+				errorf("expected identifier, frequency, and location")
+			} else if p, ok := sf.coordinate(f[2], f[3]); !ok {
+				errorf("%s %s: invalid location", f[2], f[3])
+			} else {
+				sf.Fixes[strings.ToUpper(f[0])] = p
+			}
+
+		case "FIXES":
+			if len(f) < 3 {
+				errorf("expected fix name and location")
+			} else if p, ok := sf.coordinate(f[1], f[2]); !ok {
+				errorf("%s %s: invalid location", f[1], f[2])
+			} else {
+				sf.Fixes[strings.ToUpper(f[0])] = p
+			}
+
+		case "RUNWAY":
+			// rwy1 rwy2 hdg1 hdg2 lat1 lon1 lat2 lon2 [airport [name]]
+			if len(f) < 8 {
+				errorf("expected runways, headings, and threshold locations")
+				return
+			}
+			p0, ok0 := sf.coordinate(f[4], f[5])
+			p1, ok1 := sf.coordinate(f[6], f[7])
+			if !ok0 || !ok1 {
+				errorf("invalid threshold locations")
+				return
+			}
+			name := "Runways"
+			if len(f) > 8 {
+				name = strings.ToUpper(f[8]) + " runways"
+			}
+			sf.addSegment(name, p0, p1)
+
+		case "ARTCC", "ARTCC HIGH", "ARTCC LOW", "HIGH AIRWAY", "LOW AIRWAY", "SID", "STAR", "GEO":
+			// name lat1 lon1 lat2 lon2 [color]; in SID, STAR, and GEO,
+			// the name may be omitted to continue the previous drawing.
+			name, p0, p1, ok := sf.segment(f)
+			if !ok {
+				errorf("%s: unable to parse line segment", strings.Join(f, " "))
+				return
+			}
+			if name == "" {
+				if continues := section == "SID" || section == "STAR" || section == "GEO"; !continues || diagram == "" {
+					errorf("missing name for line segment")
+					return
+				}
+				name = diagram
+			}
+			diagram = name
+			sf.addSegment(name, p0, p1)
+
+		case "REGIONS":
+			// REGIONNAME name, then color lat lon for the first vertex of
+			// each polygon and lat lon for the following ones.
+			if strings.EqualFold(f[0], "REGIONNAME") {
+				endRegion()
+				diagram = strings.Join(f[1:], " ")
+				return
+			}
+			if len(f) == 3 {
+				endRegion()
+				f = f[1:]
+			}
+			if len(f) != 2 {
+				errorf("expected region vertex")
+			} else if p, ok := sf.coordinate(f[0], f[1]); !ok {
+				errorf("%s %s: invalid location", f[0], f[1])
+			} else {
+				if diagram == "" {
+					diagram = "Regions"
+				}
+				region = append(region, p)
+			}
+		}
+	})
+	endRegion()
+	if err != nil {
+		e.Error(err)
+	}
+}
+
+// segment parses a line segment from one of the drawing sections,
+// returning its name, if given, and its endpoints. The endpoints are the
+// last four fields, or the four before a trailing color.
+func (sf *SectorFile) segment(f []string) (string, math.Point2LL, math.Point2LL, bool) {
+	for _, n := range []int{4, 5} {
+		if len(f) < n {
+			break
+		}
+		c := f[len(f)-n:]
+		p0, ok0 := sf.coordinate(c[0], c[1])
+		p1, ok1 := sf.coordinate(c[2], c[3])
+		if ok0 && ok1 {
+			return strings.Join(f[:len(f)-n], " "), p0, p1, true
+		}
+	}
+	return "", math.Point2LL{}, math.Point2LL{}, false
+}
+
+// coordinate returns the location given by a latitude and longitude
+// ("N051.28.39.000 W000.27.41.000"); EuroScope also allows the name of a
+// previously defined fix to be given for both.
+func (sf *SectorFile) coordinate(lat, lon string) (math.Point2LL, bool) {
+	if p, err := math.ParseLatLong([]byte(strings.ToUpper(lat) + "," + strings.ToUpper(lon))); err == nil {
+		return p, true
+	}
+	if !strings.EqualFold(lat, lon) {
+		return math.Point2LL{}, false
+	}
+	p, ok := sf.Fixes[strings.ToUpper(lat)]
+	return p, ok
+}
+
+// addSegment adds a line segment to the named diagram, extending the
+// previous line strip if it ends where the segment starts.
+func (sf *SectorFile) addSegment(name string, p0, p1 math.Point2LL) {
+	strips := sf.Diagrams[name]
+	if n := len(strips); n > 0 && strips[n-1][len(strips[n-1])-1] == p0 {
+		strips[n-1] = append(strips[n-1], p1)
+	} else {
+		sf.Diagrams[name] = append(strips, []math.Point2LL{p0, p1})
+	}
+}
+
+func (sf *SectorFile) parseESE(r io.Reader, e *util.ErrorLogger) {
+	sectorLines := make(map[string][]math.Point2LL)
+	borders := make(map[string][]string)
+	lastLine := ""
+	var sector *SectorFileSector
+
+	colons := func(s string) []string { return strings.Split(strings.TrimSpace(s), ":") }
+	err := sectorFileLines(r, colons, func(section string, f []string, line int) {
+		errorf := func(s string, args ...any) {
+			e.ErrorString("line %d: %s", line, fmt.Sprintf(s, args...))
+		}
+
+		switch section {
+		case "POSITIONS":
+			// callsign:name:frequency:identifier:middle letter:prefix:suffix:...
+			if len(f) < 7 {
+				errorf("expected at least 7 fields for position")
+			} else if freq, err := strconv.ParseFloat(f[2], 32); err != nil {
+				errorf("%s: invalid frequency", f[2])
+			} else {
+				sf.Positions = append(sf.Positions, SectorFilePosition{
+					Callsign:   f[0],
+					Name:       f[1],
+					Frequency:  NewFrequency(float32(freq)),
+					Identifier: f[3],
+					Prefix:     f[5],
+					Suffix:     f[6],
+				})
+			}
+
+		case "AIRSPACE":
+			switch strings.ToUpper(f[0]) {
+			case "SECTORLINE":
+				if len(f) < 2 {
+					errorf("missing sector line name")
+				} else {
+					lastLine = f[1]
+					sectorLines[lastLine] = nil
+				}
+
+			case "COORD":
+				if len(f) < 3 {
+					errorf("expected latitude and longitude")
+				} else if lastLine == "" {
+					errorf("COORD outside of SECTORLINE")
+				} else if p, ok := sf.coordinate(f[1], f[2]); !ok {
+					errorf("%s %s: invalid location", f[1], f[2])
+				} else {
+					sectorLines[lastLine] = append(sectorLines[lastLine], p)
+				}
+
+			case "CIRCLE_SECTORLINE":
+				// name:center fix:radius or name:lat:lon:radius
+				var center math.Point2LL
+				var ok bool
+				var radius string
+				if len(f) == 4 {
+					center, ok = sf.coordinate(f[2], f[2])
+					radius = f[3]
+				} else if len(f) == 5 {
+					center, ok = sf.coordinate(f[2], f[3])
+					radius = f[4]
+				}
+				r, err := strconv.ParseFloat(radius, 32)
+				if !ok || err != nil {
+					errorf("invalid circle sector line")
+				} else {
+					lastLine = f[1]
+					sectorLines[lastLine] = circle(center, float32(r))
+				}
+
+			case "SECTOR":
+				// name:floor:ceiling
+				if len(f) < 4 {
+					errorf("expected sector name, floor, and ceiling")
+					return
+				}
+				floor, err0 := strconv.Atoi(f[2])
+				ceiling, err1 := strconv.Atoi(f[3])
+				if err0 != nil || err1 != nil {
+					errorf("%s, %s: invalid floor or ceiling", f[2], f[3])
+					return
+				}
+				sf.Sectors = append(sf.Sectors, SectorFileSector{Name: f[1], Floor: floor, Ceiling: ceiling})
+				sector = &sf.Sectors[len(sf.Sectors)-1]
+
+			case "BORDER":
+				if sector == nil {
+					errorf("BORDER outside of SECTOR")
+				} else {
+					borders[sector.Name] = append(borders[sector.Name], f[1:]...)
+				}
+			}
+		}
+	})
+	if err != nil {
+		e.Error(err)
+	}
+
+	for i := range sf.Sectors {
+		s := &sf.Sectors[i]
+		var lines [][]math.Point2LL
+		for _, name := range borders[s.Name] {
+			if l, ok := sectorLines[name]; !ok {
+				e.ErrorString("sector %q: sector line %q not found", s.Name, name)
+			} else if len(l) > 0 {
+				lines = append(lines, l)
+			}
+		}
+		s.Boundary = chainLines(lines)
+	}
+}
+
+// circle returns a polygon approximating a circle with the given radius in
+// nautical miles.
+func circle(center math.Point2LL, radius float32) []math.Point2LL {
+	nmPerLongitude := 60 * math.Cos(math.Radians(center[1]))
+	pc := math.LL2NM(center, nmPerLongitude)
+	var pts []math.Point2LL
+	for i := 0; i < 360; i += 10 {
+		a := math.Radians(float32(i))
+		p := math.Add2f(pc, math.Scale2f([2]float32{math.Sin(a), math.Cos(a)}, radius))
+		pts = append(pts, math.NM2LL(p, nmPerLongitude))
+	}
+	return pts
+}
+
+// chainLines joins the sector lines that make up a sector's border into a
+// single polygon. The lines may be given in any order and either
+// direction; each one is joined to the unused line with the endpoint
+// closest to the end of the polygon so far.
+func chainLines(lines [][]math.Point2LL) []math.Point2LL {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	poly := slices.Clone(lines[0])
+	lines = lines[1:]
+	for len(lines) > 0 {
+		end := poly[len(poly)-1]
+		best, reverse, bestDist := 0, false, float32(360)
+		for i, l := range lines {
+			if d := math.Distance2f(end, l[0]); d < bestDist {
+				best, reverse, bestDist = i, false, d
+			}
+			if d := math.Distance2f(end, l[len(l)-1]); d < bestDist {
+				best, reverse, bestDist = i, true, d
+			}
+		}
+
+		l := slices.Clone(lines[best])
+		if reverse {
+			slices.Reverse(l)
+		}
+		if l[0] == end {
+			l = l[1:]
+		}
+		poly = append(poly, l...)
+		lines = slices.Delete(lines, best, best+1)
+	}
+
+	if len(poly) > 1 && poly[0] == poly[len(poly)-1] {
+		poly = poly[:len(poly)-1]
+	}
+	return poly
+}
+
+// Controller returns the vice controller for the position.
+func (p SectorFilePosition) Controller() *Controller {
+	return &Controller{
+		Callsign:           p.Callsign,
+		FullName:           p.Name,
+		Frequency:          p.Frequency,
+		SectorId:           p.Identifier,
+		FacilityIdentifier: p.Prefix,
+		Facility:           p.Prefix,
+	}
+}
+
+// VideoMaps returns a video map for each of the sector file's diagrams.
+func (sf *SectorFile) VideoMaps() []VideoMap {
+	var maps []VideoMap
+	for i, name := range util.SortedMapKeys(sf.Diagrams) {
+		label := strings.ToUpper(strings.ReplaceAll(name, " ", ""))
+		if len(label) > 8 {
+			label = label[:8]
+		}
+		maps = append(maps, VideoMap{
+			Label: label,
+			Name:  name,
+			Id:    i + 1,
+			Lines: sf.Diagrams[name],
+		})
+	}
+	return maps
+}
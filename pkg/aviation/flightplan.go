@@ -0,0 +1,347 @@
+// pkg/aviation/flightplan.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package aviation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Flight plans may be filed either in the FAA domestic format, where the
+// aircraft type field is e.g. "H/B744/L", or in the ICAO format, where it
+// holds the ICAO type, wake turbulence category, and the item 10
+// equipment and surveillance codes, e.g. "B744/H-SDE2FGHIRWY/LB1". In
+// the latter case, item 18 "other information" (PBN/, DOF/, RMK/, ...) is
+// expected to be in the flight plan's remarks.
+
+// EquipmentCapabilities summarizes the navigation and surveillance
+// equipment that a flight plan indicates an aircraft has.
+type EquipmentCapabilities struct {
+	Transponder       bool
+	AltitudeReporting bool
+	DME               bool
+	RNAV              bool
+	GNSS              bool
+	RVSM              bool
+	// ADSB is only known for ICAO flight plans; FAA equipment suffixes
+	// don't indicate ADS-B Out equipage.
+	ADSB bool
+}
+
+// FAA equipment suffixes for non-RVSM aircraft, indexed by
+// [navigation][transponder], where navigation is 0: none, 1: DME/TACAN,
+// 2: RNAV, 3: GNSS and transponder is 0: none, 1: no altitude
+// reporting, 2: altitude reporting.
+var faaEquipmentSuffixes = [4][3]string{
+	{"X", "T", "U"},
+	{"D", "B", "A"},
+	{"Y", "C", "I"},
+	{"V", "S", "G"},
+}
+
+func faaSuffixCapabilities(suffix string) (EquipmentCapabilities, bool) {
+	switch suffix {
+	case "H": // RVSM, failed transponder or altitude reporting
+		return EquipmentCapabilities{RVSM: true}, true
+	case "W":
+		return EquipmentCapabilities{RVSM: true, Transponder: true, AltitudeReporting: true}, true
+	case "Z":
+		return EquipmentCapabilities{RVSM: true, Transponder: true, AltitudeReporting: true, DME: true, RNAV: true}, true
+	case "L":
+		return EquipmentCapabilities{RVSM: true, Transponder: true, AltitudeReporting: true, DME: true, RNAV: true,
+			GNSS: true}, true
+	case "M", "N", "P": // TACAN; treat it as DME
+		return faaSuffixCapabilities(string("DBA"[strings.Index("MNP", suffix)]))
+	}
+
+	for nav, s := range faaEquipmentSuffixes {
+		for xpdr := range s {
+			if s[xpdr] == suffix {
+				return EquipmentCapabilities{
+					Transponder:       xpdr > 0,
+					AltitudeReporting: xpdr == 2,
+					DME:               nav >= 1,
+					RNAV:              nav >= 2,
+					GNSS:              nav == 3,
+				}, true
+			}
+		}
+	}
+	return EquipmentCapabilities{}, false
+}
+
+// FAASuffix returns the FAA domestic equipment suffix that best describes
+// the capabilities.
+func (e EquipmentCapabilities) FAASuffix() string {
+	if e.RVSM {
+		if !e.Transponder || !e.AltitudeReporting {
+			return "H"
+		} else if e.GNSS {
+			return "L"
+		} else if e.RNAV {
+			return "Z"
+		}
+		return "W"
+	}
+
+	nav := 0
+	if e.GNSS {
+		nav = 3
+	} else if e.RNAV {
+		nav = 2
+	} else if e.DME {
+		nav = 1
+	}
+	xpdr := 0
+	if e.Transponder {
+		xpdr = 1
+		if e.AltitudeReporting {
+			xpdr = 2
+		}
+	}
+	return faaEquipmentSuffixes[nav][xpdr]
+}
+
+// ICAOEquipment returns ICAO flight plan item 10a (equipment) and 10b
+// (surveillance) codes corresponding to the capabilities.
+func (e EquipmentCapabilities) ICAOEquipment() (equipment, surveillance string) {
+	equipment = "S"
+	if e.DME {
+		equipment += "D"
+	}
+	if e.GNSS {
+		equipment += "G"
+	}
+	if e.RNAV {
+		equipment += "R"
+	}
+	if e.RVSM {
+		equipment += "W"
+	}
+
+	if e.AltitudeReporting {
+		surveillance = "C"
+	} else if e.Transponder {
+		surveillance = "A"
+	} else {
+		surveillance = "N"
+	}
+	return
+}
+
+// PBN codes that indicate GNSS-based navigation.
+var gnssPBNCodes = []string{"B1", "B2", "C1", "C2", "D1", "D2", "L1", "O1", "O2", "S1", "S2", "T1", "T2"}
+
+func icaoEquipmentCapabilities(equipment, surveillance, pbn string) EquipmentCapabilities {
+	var e EquipmentCapabilities
+	e.DME = strings.ContainsAny(equipment, "D")
+	e.GNSS = strings.ContainsAny(equipment, "G")
+	e.RNAV = e.GNSS || strings.ContainsAny(equipment, "RI") || pbn != ""
+	e.RVSM = strings.ContainsAny(equipment, "W")
+	for _, code := range gnssPBNCodes {
+		if strings.Contains(pbn, code) {
+			e.GNSS = true
+		}
+	}
+
+	// Surveillance codes may be followed by ADS-B/ADS-C codes like
+	// "B1" or "D1", which tell us nothing about the transponder.
+	for _, code := range []string{"B1", "B2", "U1", "U2", "V1", "V2"} {
+		if strings.Contains(surveillance, code) {
+			e.ADSB = true
+		}
+	}
+	for i, ch := range surveillance {
+		if i+1 < len(surveillance) && surveillance[i+1] >= '0' && surveillance[i+1] <= '9' {
+			continue
+		}
+		switch ch {
+		case 'A', 'I', 'X':
+			e.Transponder = true
+		case 'C', 'E', 'H', 'L', 'P', 'S':
+			e.Transponder = true
+			e.AltitudeReporting = true
+		}
+	}
+	return e
+}
+
+// icaoTypeFields splits an ICAO format aircraft type field into its
+// components; ok is false if the field isn't in the ICAO format.
+func icaoTypeFields(actype string) (icaoType, wake, equipment, surveillance string, ok bool) {
+	typeWake, equip, found := strings.Cut(actype, "-")
+	if !found {
+		return
+	}
+	if icaoType, wake, found = strings.Cut(typeWake, "/"); !found || len(wake) != 1 {
+		return
+	}
+	equipment, surveillance, _ = strings.Cut(equip, "/")
+	ok = true
+	return
+}
+
+// IsICAOFormat returns true if the flight plan's aircraft type field is
+// in the ICAO format.
+func (fp FlightPlan) IsICAOFormat() bool {
+	_, _, _, _, ok := icaoTypeFields(fp.AircraftType)
+	return ok
+}
+
+// Capabilities returns the aircraft's equipment capabilities as
+// specified by the flight plan.
+func (fp FlightPlan) Capabilities() EquipmentCapabilities {
+	if _, _, equip, surv, ok := icaoTypeFields(fp.AircraftType); ok {
+		info := fp.OtherInformation()
+		e := icaoEquipmentCapabilities(equip, surv, info["PBN"])
+		// DO-260B and DO-282B ADS-B Out may alternatively be given via SUR/.
+		e.ADSB = e.ADSB || strings.Contains(info["SUR"], "260B") || strings.Contains(info["SUR"], "282B")
+		return e
+	}
+
+	if suffix := fp.EquipmentSuffix(); suffix != "" {
+		if e, ok := faaSuffixCapabilities(suffix); ok {
+			return e
+		}
+	}
+	// No suffix: assume a transponder with altitude reporting and nothing
+	// else.
+	return EquipmentCapabilities{Transponder: true, AltitudeReporting: true}
+}
+
+// EquipmentSuffix returns the FAA equipment suffix for the flight plan,
+// converting from the ICAO equipment codes if necessary.  An empty string
+// is returned if no equipment was filed.
+func (fp FlightPlan) EquipmentSuffix() string {
+	if fp.IsICAOFormat() {
+		return fp.Capabilities().FAASuffix()
+	}
+	if s, ok := strings.CutPrefix(fp.AircraftType, fp.TypeWithoutSuffix()+"/"); ok {
+		return s
+	}
+	return ""
+}
+
+// FAAAircraftType returns the aircraft type field in the FAA domestic
+// format, e.g. "H/B744/L".
+func (fp FlightPlan) FAAAircraftType() string {
+	if !fp.IsICAOFormat() {
+		return fp.AircraftType
+	}
+	return fp.TypeWithoutSuffix() + "/" + fp.EquipmentSuffix()
+}
+
+// ICAOAircraftType returns the aircraft type field in the ICAO format,
+// e.g. "B744/H-SDGRW/C".
+func (fp FlightPlan) ICAOAircraftType() string {
+	if fp.IsICAOFormat() {
+		return fp.AircraftType
+	}
+
+	var wake string
+	if strings.HasPrefix(fp.AircraftType, "H/") {
+		wake = "H"
+	} else if strings.HasPrefix(fp.AircraftType, "J/") || strings.HasPrefix(fp.AircraftType, "S/") {
+		wake = "J"
+	} else {
+		wake = "M"
+		if DB != nil {
+			if perf, ok := DB.AircraftPerformance[fp.BaseType()]; ok && perf.WeightClass == "S" {
+				wake = "L"
+			}
+		}
+	}
+
+	equip, surv := fp.Capabilities().ICAOEquipment()
+	return fp.BaseType() + "/" + wake + "-" + equip + "/" + surv
+}
+
+// icaoOtherInfoRe matches the indicators of ICAO item 18 fields, e.g.
+// "PBN/" or "RMK/".
+var icaoOtherInfoRe = regexp.MustCompile(`(?:^|\s)([A-Z]{3,4})/`)
+
+// ParseICAOOtherInformation parses ICAO flight plan item 18 ("other
+// information"), returning a map from indicators like "PBN", "DOF", and
+// "RMK" to their values.
+func ParseICAOOtherInformation(s string) map[string]string {
+	info := make(map[string]string)
+	matches := icaoOtherInfoRe.FindAllStringSubmatchIndex(s, -1)
+	for i, m := range matches {
+		end := len(s)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		key, value := s[m[2]:m[3]], strings.TrimSpace(s[m[1]:end])
+		if prev, ok := info[key]; ok {
+			// Repeated indicators are concatenated.
+			value = prev + " " + value
+		}
+		info[key] = value
+	}
+	return info
+}
+
+// OtherInformation returns the ICAO item 18 fields in the flight plan's
+// remarks.
+func (fp FlightPlan) OtherInformation() map[string]string {
+	return ParseICAOOtherInformation(fp.Remarks)
+}
+
+// DateOfFlight returns the date given by the DOF/ item 18 field, if one
+// is present.
+func (fp FlightPlan) DateOfFlight() (time.Time, bool) {
+	if dof, ok := fp.OtherInformation()["DOF"]; ok {
+		if t, err := time.Parse("060102", dof); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+const (
+	RVSMFloor   = 29000
+	RVSMCeiling = 41000
+	// ADS-B Out is required at and above 10,000' MSL.
+	ADSBAltitude = 10000
+)
+
+// CheckAssignedAltitude returns warnings about assigning the given
+// altitude to the aircraft, given the equipment in its flight plan.  No
+// warnings are issued if the flight plan doesn't specify its equipment.
+func (fp FlightPlan) CheckAssignedAltitude(alt int) []string {
+	if fp.EquipmentSuffix() == "" {
+		return nil
+	}
+
+	var w []string
+	e := fp.Capabilities()
+	if alt >= RVSMFloor && alt <= RVSMCeiling && !e.RVSM {
+		w = append(w, fmt.Sprintf("%s: non-RVSM aircraft (/%s) assigned FL%03d", fp.Callsign,
+			fp.EquipmentSuffix(), alt/100))
+	}
+	if alt >= ADSBAltitude && fp.IsICAOFormat() && !e.ADSB {
+		w = append(w, fmt.Sprintf("%s: aircraft without ADS-B Out assigned %s", fp.Callsign,
+			FormatAltitude(float32(alt))))
+	}
+	return w
+}
+
+// CheckRNAVProcedure returns warnings if the aircraft isn't equipped to
+// fly the given RNAV procedure. gnss indicates whether the procedure
+// requires GNSS, as is the case for RNAV (GPS) approaches.
+func (fp FlightPlan) CheckRNAVProcedure(procedure string, gnss bool) []string {
+	if fp.EquipmentSuffix() == "" {
+		return nil
+	}
+
+	e := fp.Capabilities()
+	if !e.RNAV || (gnss && !e.GNSS) {
+		return []string{fmt.Sprintf("%s: /%s aircraft cleared via RNAV procedure %s", fp.Callsign,
+			fp.EquipmentSuffix(), procedure)}
+	}
+	return nil
+}
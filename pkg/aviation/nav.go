@@ -37,6 +37,21 @@ type Nav struct {
 
 	FinalAltitude float32
 	Waypoints     []Waypoint
+
+	// Phraseology is the name of the PhraseologySet used for readbacks and
+	// TransitionAltitude overrides its transition altitude if non-zero.
+	Phraseology        string `json:",omitempty"`
+	TransitionAltitude int    `json:",omitempty"`
+}
+
+func (nav *Nav) phrases() *PhraseologySet {
+	return GetPhraseologySet(nav.Phraseology)
+}
+
+// formatAltitude returns the altitude as it should be read back using the
+// aircraft's phraseology.
+func (nav *Nav) formatAltitude(alt float32) string {
+	return nav.phrases().FormatAltitude(alt, nav.TransitionAltitude)
 }
 
 // DeferredHeading stores a heading assignment from the controller and the
@@ -395,7 +410,7 @@ func (nav *Nav) Summary(fp FlightPlan, lg *log.Logger) string {
 		dir := util.Select(*nav.Altitude.AfterSpeed > nav.FlightState.Altitude, "climb", "descend")
 		lines = append(lines, fmt.Sprintf("At %.0f kts, %s to %s",
 			*nav.Altitude.AfterSpeedSpeed, dir, FormatAltitude(*nav.Altitude.AfterSpeed)))
-	} else if c := nav.getWaypointAltitudeConstraint(); c != nil && !nav.flyingPT() {
+	} else if c := nav.getWaypointAltitudeConstraint(nil); c != nil && !nav.flyingPT() {
 		dir := util.Select(c.Altitude > nav.FlightState.Altitude, "Climbing", "Descending")
 		alt := c.Altitude
 		if nav.Altitude.Cleared != nil {
@@ -657,8 +672,8 @@ func (nav *Nav) updateAirspeed(lg *log.Logger) (float32, bool) {
 	}
 }
 
-func (nav *Nav) updateAltitude(lg *log.Logger, deltaKts float32, slowingTo250 bool) {
-	targetAltitude, targetRate := nav.TargetAltitude(lg)
+func (nav *Nav) updateAltitude(wind WindModel, lg *log.Logger, deltaKts float32, slowingTo250 bool) {
+	targetAltitude, targetRate := nav.TargetAltitude(wind, lg)
 
 	if nav.FinalAltitude != 0 { // allow 0 for backwards compatability with saved
 		targetAltitude = math.Min(targetAltitude, nav.FinalAltitude)
@@ -845,7 +860,7 @@ func (nav *Nav) Check(lg *log.Logger) {
 // returns passed waypoint if any
 func (nav *Nav) Update(wind WindModel, lg *log.Logger) *Waypoint {
 	deltaKts, slowingTo250 := nav.updateAirspeed(lg)
-	nav.updateAltitude(lg, deltaKts, slowingTo250)
+	nav.updateAltitude(wind, lg, deltaKts, slowingTo250)
 	nav.updateHeading(wind, lg)
 	nav.updatePositionAndGS(wind, lg)
 
@@ -1066,7 +1081,7 @@ func (nav *Nav) LocalizerHeading(wind WindModel, lg *log.Logger) (heading float3
 
 const MaximumRate = 100000
 
-func (nav *Nav) TargetAltitude(lg *log.Logger) (float32, float32) {
+func (nav *Nav) TargetAltitude(wind WindModel, lg *log.Logger) (float32, float32) {
 	// Clear out altitude restrictions from waypoints if we've made them.
 	if ar := nav.Altitude.Restriction; ar != nil {
 		if nav.Altitude.Restriction.TargetAltitude(nav.FlightState.Altitude) == nav.FlightState.Altitude {
@@ -1103,7 +1118,7 @@ func (nav *Nav) TargetAltitude(lg *log.Logger) (float32, float32) {
 		return ar.TargetAltitude(nav.FlightState.Altitude), MaximumRate
 	}
 
-	if c := nav.getWaypointAltitudeConstraint(); c != nil && !nav.flyingPT() {
+	if c := nav.getWaypointAltitudeConstraint(wind); c != nil && !nav.flyingPT() {
 		lg.Debugf("alt: altitude %.0f for waypoint %s in %.0f seconds", c.Altitude, c.Fix, c.ETA)
 		if c.ETA < 5 || nav.FlightState.Altitude < c.Altitude {
 			// Always climb as soon as we can
@@ -1146,8 +1161,9 @@ type WaypointCrossingConstraint struct {
 // higher altitudes (speed, efficiency) with the aircraft's performance and
 // subsequent altitude restrictions--e.g., sometimes it needs to be lower
 // than it would otherwise at one waypoint in order to make a restriction
-// at a subsequent waypoint.
-func (nav *Nav) getWaypointAltitudeConstraint() *WaypointCrossingConstraint {
+// at a subsequent waypoint. If a wind model is provided, the times to fly
+// the legs of the route account for the wind along each of them.
+func (nav *Nav) getWaypointAltitudeConstraint(wind WindModel) *WaypointCrossingConstraint {
 	if nav.Heading.Assigned != nil {
 		// ignore what's going on with the fixes
 		return nil
@@ -1214,15 +1230,15 @@ func (nav *Nav) getWaypointAltitudeConstraint() *WaypointCrossingConstraint {
 	// incrementally working backwards from the last altitude restriction.
 	altRange := getRestriction(lastWp).Range
 
-	// Sum of distances in nm since the last waypoint with an altitude
-	// restriction.
-	sumDist := float32(0)
+	// Sum of flying times in seconds since the last waypoint with an
+	// altitude restriction.
+	sumTime := float32(0)
 
 	// Loop over waypoints in reverse starting at the one before the last
 	// one with a waypoint restriction.
 	fix := nav.Waypoints[lastWp].Fix // first one with an alt restriction
 	for i := lastWp - 1; i >= 0; i-- {
-		sumDist += math.NMDistance2LL(nav.Waypoints[i+1].Location, nav.Waypoints[i].Location)
+		sumTime += nav.legTime(nav.Waypoints[i].Location, nav.Waypoints[i+1].Location, wind)
 
 		// Does this one have a relevant altitude restriction?
 		restr := getRestriction(i)
@@ -1239,8 +1255,7 @@ func (nav *Nav) getWaypointAltitudeConstraint() *WaypointCrossingConstraint {
 		fix = nav.Waypoints[i].Fix
 
 		// TODO: account for decreasing GS with altitude?
-		// TODO: incorporate a simple wind model in GS?
-		eta := sumDist / nav.FlightState.GS * 3600 // seconds
+		eta := sumTime
 
 		// Maximum change in altitude possible before reaching this
 		// waypoint.
@@ -1276,14 +1291,12 @@ func (nav *Nav) getWaypointAltitudeConstraint() *WaypointCrossingConstraint {
 
 		// Reset this so we compute the right eta next time we have a
 		// waypoint with an altitude restriction.
-		sumDist = 0
+		sumTime = 0
 	}
 
-	// Add the distance to the first waypoint to get the total distance
-	// (and then the ETA) between the aircraft and the first waypoint with
-	// an altitude restriction.
-	d := sumDist + math.NMDistance2LL(nav.FlightState.Position, nav.Waypoints[0].Location)
-	eta := d / nav.FlightState.GS * 3600 // seconds
+	// Add the time to the first waypoint to get the ETA between the
+	// aircraft and the first waypoint with an altitude restriction.
+	eta := sumTime + nav.legTime(nav.FlightState.Position, nav.Waypoints[0].Location, wind)
 
 	// Prefer to be higher rather than low; deal with "at or above" here as well.
 	alt := util.Select(altRange[1] != 0, altRange[1], nav.FinalAltitude)
@@ -1304,6 +1317,21 @@ func (nav *Nav) getWaypointAltitudeConstraint() *WaypointCrossingConstraint {
 	}
 }
 
+// legTime returns the time in seconds to fly from p0 to p1. Without a
+// wind model, the aircraft's current ground speed is used; otherwise the
+// ground speed along the leg is found from its true airspeed and the
+// wind.
+func (nav *Nav) legTime(p0, p1 math.Point2LL, wind WindModel) float32 {
+	d := math.NMDistance2LL(p0, p1)
+	gs := nav.FlightState.GS
+	if wind != nil && d > 0 {
+		nmPerLongitude := nav.FlightState.NmPerLongitude
+		course := math.Normalize2f(math.Sub2f(math.LL2NM(p1, nmPerLongitude), math.LL2NM(p0, nmPerLongitude)))
+		gs = GroundSpeedAlongCourse(course, nav.TAS(), wind.AverageWindVector())
+	}
+	return d / math.Max(gs, 1) * 3600
+}
+
 func (nav *Nav) TargetSpeed(lg *log.Logger) (float32, float32) {
 	maxAccel := nav.Perf.Rate.Accelerate * 30 // per minute
 
@@ -1424,7 +1452,7 @@ func (nav *Nav) TargetSpeed(lg *log.Logger) (float32, float32) {
 		return nav.FlightState.IAS, MaximumRate
 	}
 
-	target, _ := nav.TargetAltitude(lg)
+	target, _ := nav.TargetAltitude(nil, lg)
 	if nav.FlightState.Altitude >= 10000 && target < 10000 && nav.FlightState.IAS > 250 {
 		// Consider slowing to 250; estimate how long until we'll reach 10k
 		dalt := nav.FlightState.Altitude - 10000
@@ -1799,13 +1827,14 @@ func (nav *Nav) AssignAltitude(alt float32, afterSpeed bool) PilotResponse {
 		return PilotResponse{Message: "unable. That altitude is above our ceiling.", Unexpected: true}
 	}
 
+	ps := nav.phrases()
 	var response string
 	if alt > nav.FlightState.Altitude {
-		response = rand.Sample("climb and maintain ", "up to ") + FormatAltitude(alt)
+		response = ps.Phrase(ps.Climb, nav.formatAltitude(alt))
 	} else if alt == nav.FlightState.Altitude {
-		response = rand.Sample("maintain ", "we'll keep it at ") + FormatAltitude(alt)
+		response = ps.Phrase(ps.Maintain, nav.formatAltitude(alt))
 	} else {
-		response = rand.Sample("descend and maintain ", "down to ") + FormatAltitude(alt)
+		response = ps.Phrase(ps.Descend, nav.formatAltitude(alt))
 	}
 
 	if afterSpeed && nav.Speed.Assigned != nil && *nav.Speed.Assigned != nav.FlightState.IAS {
@@ -1829,9 +1858,10 @@ func (nav *Nav) AssignSpeed(speed float32, afterAltitude bool) PilotResponse {
 		nav.Speed = NavSpeed{}
 		response = "cancel speed restrictions"
 	} else if float32(speed) < nav.Perf.Speed.Landing {
-		response = fmt.Sprintf("unable. Our minimum speed is %.0f knots", nav.Perf.Speed.Landing)
+		return PilotResponse{Message: fmt.Sprintf("unable. Our minimum speed is %.0f knots", nav.Perf.Speed.Landing),
+			Unexpected: true}
 	} else if float32(speed) > maxIAS {
-		response = fmt.Sprintf("unable. Our maximum speed is %.0f knots", maxIAS)
+		return PilotResponse{Message: fmt.Sprintf("unable. Our maximum speed is %.0f knots", maxIAS), Unexpected: true}
 	} else if nav.Approach.Cleared {
 		// TODO: make sure we're not within 5 miles...
 		nav.Speed = NavSpeed{Assigned: &speed}
@@ -1842,18 +1872,20 @@ func (nav *Nav) AssignSpeed(speed float32, afterAltitude bool) PilotResponse {
 		alt := *nav.Altitude.Assigned
 		nav.Speed.AfterAltitudeAltitude = &alt
 
-		response = fmt.Sprintf("at %s feet maintain %.0f knots", FormatAltitude(alt), speed)
+		as := nav.formatAltitude(alt)
+		if nav.phrases().Feet == "" {
+			as += " feet"
+		}
+		response = fmt.Sprintf("at %s maintain %.0f knots", as, speed)
 	} else {
 		nav.Speed = NavSpeed{Assigned: &speed}
+		ps := nav.phrases()
 		if speed < nav.FlightState.IAS {
-			msg := rand.Sample("reduce speed to %.0f knots", "speed %.0f", "pulling it back to %.0f", "%.0f for the speed", "slow to %.0f")
-			response = fmt.Sprintf(msg, speed)
+			response = ps.Phrase(ps.ReduceSpeed, speed)
 		} else if speed > nav.FlightState.IAS {
-			msg := rand.Sample("increase speed to %.0f knots", "speed %.0f", "%.0f for the speed", "maintain %.0f knots")
-			response = fmt.Sprintf(msg, speed)
+			response = ps.Phrase(ps.IncreaseSpeed, speed)
 		} else {
-			msg := rand.Sample("maintain %.0f knots", "keep it at %.0f", "well stay at %.0f")
-			response = fmt.Sprintf(msg, speed)
+			response = ps.Phrase(ps.MaintainSpeed, speed)
 		}
 	}
 	return PilotResponse{Message: response}
@@ -1917,23 +1949,23 @@ func (nav *Nav) SayAltitude() PilotResponse {
 	if nav.Altitude.Assigned != nil {
 		assignedAltitude := *nav.Altitude.Assigned
 		if assignedAltitude < currentAltitude {
-			output = rand.Sample(fmt.Sprintf("at %s descending to %s", FormatAltitude(currentAltitude), FormatAltitude(assignedAltitude)),
-				fmt.Sprintf("at %s and descending", FormatAltitude(currentAltitude)))
+			output = rand.Sample(fmt.Sprintf("at %s descending to %s", nav.formatAltitude(currentAltitude), nav.formatAltitude(assignedAltitude)),
+				fmt.Sprintf("at %s and descending", nav.formatAltitude(currentAltitude)))
 
 		} else if assignedAltitude > currentAltitude {
-			output = fmt.Sprintf("at %s climbing to %s", FormatAltitude(currentAltitude), FormatAltitude(assignedAltitude))
+			output = fmt.Sprintf("at %s climbing to %s", nav.formatAltitude(currentAltitude), nav.formatAltitude(assignedAltitude))
 		} else {
-			output = rand.Sample(fmt.Sprintf("maintaining %s", FormatAltitude(currentAltitude)), fmt.Sprintf("at %s", FormatAltitude(currentAltitude)))
+			output = rand.Sample(fmt.Sprintf("maintaining %s", nav.formatAltitude(currentAltitude)), fmt.Sprintf("at %s", nav.formatAltitude(currentAltitude)))
 		}
 	} else {
-		output = rand.Sample(fmt.Sprintf("maintaining %s", FormatAltitude(currentAltitude)), fmt.Sprintf("at %s", FormatAltitude(currentAltitude)))
+		output = rand.Sample(fmt.Sprintf("maintaining %s", nav.formatAltitude(currentAltitude)), fmt.Sprintf("at %s", nav.formatAltitude(currentAltitude)))
 	}
 
 	return PilotResponse{Message: output}
 }
 
 func (nav *Nav) ExpediteDescent() PilotResponse {
-	alt, _ := nav.TargetAltitude(nil)
+	alt, _ := nav.TargetAltitude(nil, nil)
 	if alt >= nav.FlightState.Altitude {
 		return PilotResponse{Message: "unable. We're not descending", Unexpected: true}
 	}
@@ -1943,11 +1975,11 @@ func (nav *Nav) ExpediteDescent() PilotResponse {
 
 	nav.Altitude.Expedite = true
 	resp := rand.Sample("expediting down to", "expedite to")
-	return PilotResponse{Message: resp + " " + FormatAltitude(alt)}
+	return PilotResponse{Message: resp + " " + nav.formatAltitude(alt)}
 }
 
 func (nav *Nav) ExpediteClimb() PilotResponse {
-	alt, _ := nav.TargetAltitude(nil)
+	alt, _ := nav.TargetAltitude(nil, nil)
 	if alt <= nav.FlightState.Altitude {
 		return PilotResponse{Message: "unable. We're not climbing", Unexpected: true}
 	}
@@ -1958,7 +1990,7 @@ func (nav *Nav) ExpediteClimb() PilotResponse {
 
 	nav.Altitude.Expedite = true
 	resp := rand.Sample("expediting up to", "expedite to")
-	return PilotResponse{Message: resp + " " + FormatAltitude(alt)}
+	return PilotResponse{Message: resp + " " + nav.formatAltitude(alt)}
 }
 
 func (nav *Nav) AssignHeading(hdg float32, turn TurnMethod) PilotResponse {
@@ -1968,13 +2000,14 @@ func (nav *Nav) AssignHeading(hdg float32, turn TurnMethod) PilotResponse {
 
 	nav.assignHeading(hdg, turn)
 
+	ps := nav.phrases()
 	switch turn {
 	case TurnClosest:
-		return PilotResponse{Message: fmt.Sprintf("fly heading %03d", int(hdg))}
+		return PilotResponse{Message: ps.Phrase(ps.FlyHeading, int(hdg))}
 	case TurnRight:
-		return PilotResponse{Message: fmt.Sprintf("turn right heading %03d", int(hdg))}
+		return PilotResponse{Message: ps.Phrase(ps.TurnRight, int(hdg))}
 	case TurnLeft:
-		return PilotResponse{Message: fmt.Sprintf("turn left heading %03d", int(hdg))}
+		return PilotResponse{Message: ps.Phrase(ps.TurnLeft, int(hdg))}
 
 	default:
 		panic(fmt.Sprintf("%03d: unhandled turn type", turn))
@@ -1995,7 +2028,7 @@ func (nav *Nav) assignHeading(hdg float32, turn TurnMethod) {
 		// constraints, set its cleared altitude to its current altitude
 		// for now.
 		if len(nav.Waypoints) > 0 && nav.Waypoints[0].OnSTAR && nav.Altitude.Assigned == nil {
-			if c := nav.getWaypointAltitudeConstraint(); c != nil {
+			if c := nav.getWaypointAltitudeConstraint(nil); c != nil {
 				// Don't take a direct pointer to nav.FlightState.Altitude!
 				alt := nav.FlightState.Altitude
 				nav.Altitude.Cleared = &alt
@@ -2116,6 +2149,23 @@ func (nav *Nav) DirectFix(fix string) PilotResponse {
 	}
 }
 
+// DirectFixRejoin has the aircraft proceed direct to the given waypoint,
+// which need not be in its route, and then direct to the fix rejoin,
+// continuing along its route from there.
+func (nav *Nav) DirectFixRejoin(wp Waypoint, rejoin string) PilotResponse {
+	idx := slices.IndexFunc(nav.Waypoints, func(w Waypoint) bool { return w.Fix == rejoin })
+	if idx == -1 {
+		return PilotResponse{Message: "unable. " + FixReadback(rejoin) + " isn't in our route", Unexpected: true}
+	}
+
+	nav.Waypoints = append([]Waypoint{wp}, nav.Waypoints[idx:]...)
+	nav.EnqueueHeading(NavHeading{})
+	nav.Approach.NoPT = false
+	nav.Approach.InterceptState = NotIntercepting
+
+	return PilotResponse{Message: "direct " + FixReadback(wp.Fix) + ", then direct " + FixReadback(rejoin)}
+}
+
 func (nav *Nav) DepartFixDirect(fixa string, fixb string) PilotResponse {
 	fa, fb := nav.fixPairInRoute(fixa, fixb)
 	if fa == nil {
@@ -0,0 +1,457 @@
+// pkg/aviation/phraseology.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package aviation
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/util"
+)
+
+var (
+	ErrNoSpokenCallsign     = errors.New("Unable to recognize callsign")
+	ErrNoSpokenInstructions = errors.New("Unable to recognize any instructions")
+)
+
+var spokenDigits = map[string]string{
+	"zero": "0", "one": "1", "two": "2", "three": "3", "tree": "3", "four": "4", "fower": "4",
+	"five": "5", "fife": "5", "six": "6", "seven": "7", "eight": "8", "nine": "9", "niner": "9",
+}
+
+var spokenLetters = map[string]string{
+	"alpha": "A", "alfa": "A", "bravo": "B", "charlie": "C", "delta": "D", "echo": "E",
+	"foxtrot": "F", "golf": "G", "hotel": "H", "india": "I", "juliet": "J", "juliett": "J",
+	"kilo": "K", "lima": "L", "mike": "M", "november": "N", "oscar": "O", "papa": "P",
+	"quebec": "Q", "romeo": "R", "sierra": "S", "tango": "T", "uniform": "U", "victor": "V",
+	"whiskey": "W", "xray": "X", "yankee": "Y", "zulu": "Z",
+}
+
+// ParseSpokenTransmission converts the text of a spoken controller
+// transmission, as produced by a speech recognizer (e.g., "american one
+// two three turn left heading two seven zero descend and maintain four
+// thousand"), into the callsign it is addressed to and the equivalent
+// aircraft control commands (["AAL123"], ["L270", "D40"]). Numbers may be
+// given either as words or numerals. telephony maps ICAO airline codes to
+// their radio telephony designators, as in DB.Callsigns. Words that
+// aren't part of a recognized instruction are ignored.
+//
+// Since more than one airline may share a telephony designator, all of
+// the callsigns that the transmission may be addressed to are returned,
+// sorted; it is up to the caller to resolve them to a single aircraft.
+func ParseSpokenTransmission(transcript string, telephony map[string]string) ([]string, []string, error) {
+	sp := &spokenParser{words: tokenizeTranscript(transcript)}
+
+	callsigns, ok := sp.callsign(telephony)
+	if !ok {
+		return nil, nil, ErrNoSpokenCallsign
+	}
+
+	var commands []string
+	for !sp.done() {
+		if cmd, ok := sp.instruction(); ok {
+			commands = append(commands, cmd)
+		} else {
+			sp.i++
+		}
+	}
+
+	if len(commands) == 0 {
+		return callsigns, nil, ErrNoSpokenInstructions
+	}
+	return callsigns, commands, nil
+}
+
+func tokenizeTranscript(s string) []string {
+	s = strings.ToLower(s)
+	s = strings.ReplaceAll(s, ",", "") // "11,000"
+	s = strings.ReplaceAll(s, "x-ray", "xray")
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+type spokenParser struct {
+	words []string
+	i     int
+}
+
+func (sp *spokenParser) done() bool { return sp.i >= len(sp.words) }
+
+func (sp *spokenParser) peek() string {
+	if sp.done() {
+		return ""
+	}
+	return sp.words[sp.i]
+}
+
+// accept consumes the next word if it is one of the given words.
+func (sp *spokenParser) accept(words ...string) bool {
+	for _, w := range words {
+		if sp.peek() == w {
+			sp.i++
+			return true
+		}
+	}
+	return false
+}
+
+// skip consumes any filler words that may come before the next part of
+// an instruction.
+func (sp *spokenParser) skip() {
+	for sp.accept("and", "to", "the", "for", "then", "at", "a", "now", "please", "proceed") {
+	}
+}
+
+// digits consumes a run of spoken or written digits and returns them as
+// a string.
+func (sp *spokenParser) digits() string {
+	var s string
+	for !sp.done() {
+		w := sp.peek()
+		if d, ok := spokenDigits[w]; ok {
+			s += d
+		} else if util.IsAllNumbers(w) {
+			s += w
+		} else {
+			break
+		}
+		sp.i++
+	}
+	return s
+}
+
+func (sp *spokenParser) number() (int, bool) {
+	start := sp.i
+	if n, err := strconv.Atoi(sp.digits()); err == nil {
+		return n, true
+	}
+	sp.i = start
+	return 0, false
+}
+
+// callsign parses an airline callsign ("united four five six heavy"), a
+// November-registered callsign ("november one two three alpha bravo"),
+// or a written callsign ("aal123"). All of the ICAO codes that share a
+// matching telephony designator give candidate callsigns.
+func (sp *spokenParser) callsign(telephony map[string]string) ([]string, bool) {
+	if w := sp.peek(); w != "" && !util.IsAllLetters(w) && !util.IsAllNumbers(w) {
+		sp.i++
+		return []string{strings.ToUpper(w)}, true
+	}
+
+	var prefixes []string
+	if sp.accept("november") {
+		prefixes = []string{"N"}
+	} else {
+		// Try multi-word telephony designators first; they may be spoken
+		// either as separate words or run together ("jet blue", "jetblue").
+		for n := math.Min(3, len(sp.words)-sp.i); n >= 1 && len(prefixes) == 0; n-- {
+			spoken := sp.words[sp.i : sp.i+n]
+			for icao, tel := range telephony {
+				tel = strings.ToLower(tel)
+				if tel == strings.Join(spoken, " ") || tel == strings.Join(spoken, "") ||
+					strings.ReplaceAll(tel, " ", "") == strings.Join(spoken, "") {
+					prefixes = append(prefixes, icao)
+				}
+			}
+			if len(prefixes) > 0 {
+				sp.i += n
+			}
+		}
+		if len(prefixes) == 0 {
+			return nil, false
+		}
+		slices.Sort(prefixes)
+	}
+
+	id := sp.digits()
+	for !sp.done() {
+		l, ok := spokenLetters[sp.peek()]
+		if !ok {
+			break
+		}
+		id += l
+		sp.i++
+	}
+	if id == "" {
+		return nil, false
+	}
+
+	sp.accept("heavy", "super")
+	return util.MapSlice(prefixes, func(p string) string { return p + id }), true
+}
+
+// altitude parses an altitude ("flight level two three zero", "one one
+// thousand", "eight thousand five hundred", "5000") and returns it in
+// hundreds of feet.
+func (sp *spokenParser) altitude() (int, bool) {
+	start := sp.i
+	if sp.accept("flight") {
+		if sp.accept("level") {
+			if fl, ok := sp.number(); ok {
+				return fl, true
+			}
+		}
+		sp.i = start
+		return 0, false
+	}
+	if w := sp.peek(); strings.HasPrefix(w, "fl") && util.IsAllNumbers(w[2:]) && len(w) > 2 {
+		sp.i++
+		fl, _ := strconv.Atoi(w[2:])
+		return fl, true
+	}
+
+	n, ok := sp.number()
+	if !ok {
+		return 0, false
+	}
+	if sp.accept("thousand") {
+		alt := n * 10
+		hstart := sp.i
+		if h, ok := sp.number(); ok && sp.accept("hundred") {
+			alt += h
+		} else {
+			// It wasn't the hundreds; put it back.
+			sp.i = hstart
+		}
+		return alt, true
+	} else if sp.accept("hundred") {
+		return n, true
+	} else if n >= 1000 && n%100 == 0 {
+		return n / 100, true
+	}
+
+	sp.i = start
+	return 0, false
+}
+
+// approach parses an approach ("i l s runway two two left", "rnav
+// yankee runway four") and returns its identifier in the form used in
+// scenario files ("I22L", "Y4").
+func (sp *spokenParser) approach() (string, bool) {
+	start := sp.i
+	sp.skip()
+
+	var id string
+	switch {
+	case sp.accept("ils"):
+		id = "I"
+	case sp.peek() == "i" && sp.i+2 < len(sp.words) && sp.words[sp.i+1] == "l" && sp.words[sp.i+2] == "s":
+		sp.i += 3
+		id = "I"
+	case sp.accept("rnav", "gps"):
+		id = "R"
+	case sp.accept("localizer"):
+		id = "L"
+	case sp.accept("visual", "vor"):
+		id = "V"
+	default:
+		sp.i = start
+		return "", false
+	}
+	if l, ok := spokenLetters[sp.peek()]; ok {
+		// e.g., "rnav yankee"
+		id = l
+		sp.i++
+	}
+
+	sp.accept("approach")
+	if !sp.accept("runway") {
+		sp.i = start
+		return "", false
+	}
+	rwy, ok := sp.number()
+	if !ok {
+		sp.i = start
+		return "", false
+	}
+	id += strconv.Itoa(rwy)
+	switch {
+	case sp.accept("left"):
+		id += "L"
+	case sp.accept("right"):
+		id += "R"
+	case sp.accept("center"):
+		id += "C"
+	}
+	sp.accept("approach")
+	return id, true
+}
+
+// instruction tries to parse a single instruction starting at the current
+// word, returning the corresponding command if successful.
+func (sp *spokenParser) instruction() (string, bool) {
+	start := sp.i
+	fail := func() (string, bool) {
+		sp.i = start
+		return "", false
+	}
+	heading := func() (int, bool) {
+		sp.accept("heading")
+		return sp.number()
+	}
+
+	switch {
+	case sp.accept("turn"), sp.peek() == "left" || sp.peek() == "right":
+		dir := ""
+		if sp.accept("left") {
+			dir = "L"
+		} else if sp.accept("right") {
+			dir = "R"
+		} else {
+			return fail()
+		}
+		if sp.peek() == "heading" {
+			if hdg, ok := heading(); ok {
+				return dir + strconv.Itoa(hdg), true
+			}
+		} else if deg, ok := sp.number(); ok && sp.accept("degrees") {
+			return dir + strconv.Itoa(deg) + "D", true
+		}
+
+	case sp.accept("fly"), sp.peek() == "heading":
+		if sp.accept("present") && sp.accept("heading") {
+			return "H", true
+		}
+		if hdg, ok := heading(); ok {
+			return "H" + strconv.Itoa(hdg), true
+		}
+
+	case sp.accept("climb"):
+		sp.skip()
+		if sp.accept("via") {
+			return "CVS", true
+		}
+		sp.accept("maintain")
+		if alt, ok := sp.altitude(); ok {
+			return "C" + strconv.Itoa(alt), true
+		}
+
+	case sp.accept("descend"):
+		sp.skip()
+		if sp.accept("via") {
+			return "DVS", true
+		}
+		sp.accept("maintain")
+		if alt, ok := sp.altitude(); ok {
+			return "D" + strconv.Itoa(alt), true
+		}
+
+	case sp.accept("maintain"):
+		switch {
+		case sp.accept("present"):
+			if sp.accept("heading") {
+				return "H", true
+			}
+		case sp.accept("slowest"):
+			sp.accept("practical")
+			sp.accept("speed")
+			return "SMIN", true
+		case sp.accept("maximum"):
+			sp.accept("forward")
+			sp.accept("speed")
+			return "SMAX", true
+		default:
+			if alt, ok := sp.altitude(); ok {
+				return "A" + strconv.Itoa(alt), true
+			} else if kts, ok := sp.number(); ok && sp.accept("knots") {
+				return "S" + strconv.Itoa(kts), true
+			}
+		}
+
+	case sp.accept("reduce", "increase", "speed"):
+		sp.accept("speed")
+		sp.skip()
+		if kts, ok := sp.number(); ok {
+			sp.accept("knots")
+			return "S" + strconv.Itoa(kts), true
+		}
+
+	case sp.accept("resume"):
+		if sp.accept("normal") && sp.accept("speed") {
+			return "S", true
+		}
+
+	case sp.accept("expedite"):
+		sp.skip()
+		if sp.accept("climb") {
+			return "EC", true
+		} else if sp.accept("descent") {
+			return "ED", true
+		}
+
+	case sp.accept("direct"):
+		if fix := sp.peek(); util.IsAllLetters(fix) && len(fix) >= 3 && len(fix) <= 5 {
+			sp.i++
+			return "D" + strings.ToUpper(fix), true
+		}
+
+	case sp.accept("cleared"):
+		sp.skip()
+		if sp.accept("straight") {
+			sp.accept("in")
+			if ap, ok := sp.approach(); ok {
+				return "CSI" + ap, true
+			}
+		} else if ap, ok := sp.approach(); ok {
+			return "C" + ap, true
+		}
+
+	case sp.accept("expect"):
+		if ap, ok := sp.approach(); ok {
+			return "E" + ap, true
+		}
+
+	case sp.accept("cancel"):
+		if sp.accept("approach") && sp.accept("clearance") {
+			return "CAC", true
+		}
+
+	case sp.accept("intercept"):
+		sp.skip()
+		if sp.accept("localizer") {
+			return "I", true
+		}
+
+	case sp.accept("contact"):
+		if sp.accept("tower") {
+			return "TO", true
+		}
+		if sp.accept("departure", "approach", "center") {
+			// Skip the frequency, if given.
+			for sp.digits() != "" && sp.accept("point", "decimal") {
+			}
+			return "FC", true
+		}
+
+	case sp.accept("squawk"):
+		if sp.accept("ident") {
+			return "ID", true
+		}
+		if code := sp.digits(); len(code) == 4 {
+			return "SQ" + code, true
+		}
+
+	case sp.accept("ident"):
+		return "ID", true
+
+	case sp.accept("say"):
+		switch {
+		case sp.accept("altitude"):
+			return "SA", true
+		case sp.accept("heading"):
+			return "SH", true
+		case sp.accept("speed", "airspeed"):
+			return "SS", true
+		}
+	}
+
+	return fail()
+}
@@ -0,0 +1,136 @@
+// pkg/aviation/phrasesets.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package aviation
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mmp/vice/pkg/rand"
+	"github.com/mmp/vice/pkg/util"
+)
+
+// PhraseologySet gives the wording that simulated pilots use when reading
+// back altitude, speed, and heading instructions, so that scenarios
+// outside the US can use ICAO or regional phraseology. Each phrase is a
+// list of alternatives, one of which is chosen at random; altitudes are
+// substituted with %s, speeds with %.0f, and headings with %03d.
+type PhraseologySet struct {
+	// TransitionAltitude is the altitude at and above which altitudes are
+	// given as flight levels, unless the scenario gives another one.
+	TransitionAltitude int
+	// FlightLevel precedes flight levels; Altitude and Feet come before
+	// and after altitudes below the transition altitude.
+	FlightLevel, Altitude, Feet string
+	// ThousandsSeparator causes a comma to be included in altitudes
+	// ("5,000").
+	ThousandsSeparator bool
+
+	Climb, Descend, Maintain                  []string
+	ReduceSpeed, IncreaseSpeed, MaintainSpeed []string
+	FlyHeading, TurnLeft, TurnRight           []string
+}
+
+// PhraseologySets holds the available phraseology sets, indexed by the
+// names used for them in scenario files.
+var PhraseologySets = map[string]*PhraseologySet{
+	"faa": {
+		TransitionAltitude: 18000,
+		FlightLevel:        "FL",
+		ThousandsSeparator: true,
+		Climb:              []string{"climb and maintain %s", "up to %s"},
+		Descend:            []string{"descend and maintain %s", "down to %s"},
+		Maintain:           []string{"maintain %s", "we'll keep it at %s"},
+		ReduceSpeed:        []string{"reduce speed to %.0f knots", "speed %.0f", "pulling it back to %.0f", "%.0f for the speed", "slow to %.0f"},
+		IncreaseSpeed:      []string{"increase speed to %.0f knots", "speed %.0f", "%.0f for the speed", "maintain %.0f knots"},
+		MaintainSpeed:      []string{"maintain %.0f knots", "keep it at %.0f", "well stay at %.0f"},
+		FlyHeading:         []string{"fly heading %03d"},
+		TurnLeft:           []string{"turn left heading %03d"},
+		TurnRight:          []string{"turn right heading %03d"},
+	},
+	// ICAO Doc 4444; transition altitudes vary from country to country,
+	// so scenarios should generally specify theirs.
+	"icao": {
+		TransitionAltitude: 5000,
+		FlightLevel:        "flight level ",
+		Feet:               " feet",
+		Climb:              []string{"climb to %s"},
+		Descend:            []string{"descend to %s"},
+		Maintain:           []string{"maintain %s"},
+		ReduceSpeed:        []string{"reduce speed to %.0f knots"},
+		IncreaseSpeed:      []string{"increase speed to %.0f knots"},
+		MaintainSpeed:      []string{"maintain %.0f knots"},
+		FlyHeading:         []string{"fly heading %03d"},
+		TurnLeft:           []string{"turn left heading %03d"},
+		TurnRight:          []string{"turn right heading %03d"},
+	},
+	// UK CAP 413, which omits "to" and says "altitude" before altitudes.
+	"uk": {
+		TransitionAltitude: 6000,
+		FlightLevel:        "flight level ",
+		Altitude:           "altitude ",
+		Feet:               " feet",
+		Climb:              []string{"climb %s"},
+		Descend:            []string{"descend %s"},
+		Maintain:           []string{"maintain %s"},
+		ReduceSpeed:        []string{"speed %.0f knots"},
+		IncreaseSpeed:      []string{"speed %.0f knots"},
+		MaintainSpeed:      []string{"maintain %.0f knots"},
+		FlyHeading:         []string{"fly heading %03d"},
+		TurnLeft:           []string{"turn left heading %03d"},
+		TurnRight:          []string{"turn right heading %03d"},
+	},
+}
+
+// DefaultPhraseologySet is used when a scenario doesn't specify one.
+const DefaultPhraseologySet = "faa"
+
+// GetPhraseologySet returns the named phraseology set, or the default one
+// if there is no such set.
+func GetPhraseologySet(name string) *PhraseologySet {
+	if ps, ok := PhraseologySets[name]; ok {
+		return ps
+	}
+	return PhraseologySets[DefaultPhraseologySet]
+}
+
+// CheckPhraseologySet reports an error if there's no phraseology set with
+// the given name; the empty string is allowed and gives the default.
+func CheckPhraseologySet(name string, e *util.ErrorLogger) {
+	if _, ok := PhraseologySets[name]; name != "" && !ok {
+		e.ErrorString("%q: unknown phraseology; options are %v", name, util.SortedMapKeys(PhraseologySets))
+	}
+}
+
+// FormatAltitude returns the altitude as it should be read back, as a
+// flight level if it is at or above the transition altitude, which is
+// the set's default if transitionAltitude is zero.
+func (ps *PhraseologySet) FormatAltitude(falt float32, transitionAltitude int) string {
+	if transitionAltitude == 0 {
+		transitionAltitude = ps.TransitionAltitude
+	}
+	alt := int(falt)
+	if alt >= transitionAltitude {
+		return ps.FlightLevel + strconv.Itoa(alt/100)
+	}
+
+	s := strconv.Itoa(alt)
+	if alt >= 1000 {
+		// Round to hundreds of feet.
+		alt = alt / 100 * 100
+		if ps.ThousandsSeparator {
+			s = fmt.Sprintf("%d,%03d", alt/1000, alt%1000)
+		} else {
+			s = strconv.Itoa(alt)
+		}
+	}
+	return ps.Altitude + s + ps.Feet
+}
+
+// Phrase returns one of the given alternatives, chosen at random, with the
+// arguments substituted.
+func (ps *PhraseologySet) Phrase(alternatives []string, args ...any) string {
+	return fmt.Sprintf(rand.Sample(alternatives...), args...)
+}
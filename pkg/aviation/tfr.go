@@ -0,0 +1,249 @@
+// pkg/aviation/tfr.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package aviation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/util"
+)
+
+// TFR represents a temporary flight restriction.
+type TFR struct {
+	Name        string
+	Description string
+	// Altitude bounds, in feet MSL.
+	Floor, Ceiling int
+	// Activation schedule; zero times indicate that the TFR is in effect
+	// indefinitely in the corresponding direction.
+	Effective, Expires time.Time
+	Polygons           [][]math.Point2LL
+}
+
+// Active returns true if the TFR is in effect at the given time.
+func (t TFR) Active(now time.Time) bool {
+	return (t.Effective.IsZero() || !now.Before(t.Effective)) &&
+		(t.Expires.IsZero() || now.Before(t.Expires))
+}
+
+// InsideLateral returns true if the given point is inside one of the
+// TFR's polygons, independent of altitude.
+func (t TFR) InsideLateral(p math.Point2LL) bool {
+	for _, poly := range t.Polygons {
+		if math.PointInPolygon2LL(p, poly) {
+			return true
+		}
+	}
+	return false
+}
+
+// Inside returns true if the given point and altitude are inside the TFR.
+func (t TFR) Inside(p math.Point2LL, alt int) bool {
+	return alt >= t.Floor && alt <= t.Ceiling && t.InsideLateral(p)
+}
+
+// AltitudeString returns the TFR's altitude bounds in hundreds of feet,
+// e.g. "SFC-180".
+func (t TFR) AltitudeString() string {
+	floor := "SFC"
+	if t.Floor > 0 {
+		floor = fmt.Sprintf("%03d", t.Floor/100)
+	}
+	ceiling := "UNL"
+	if t.Ceiling < tfrUnlimited {
+		ceiling = fmt.Sprintf("%03d", t.Ceiling/100)
+	}
+	return floor + "-" + ceiling
+}
+
+// ScheduleString returns a summary of the TFR's activation schedule.
+func (t TFR) ScheduleString() string {
+	format := func(t time.Time, unset string) string {
+		if t.IsZero() {
+			return unset
+		}
+		return t.UTC().Format("01/02 1504Z")
+	}
+	return format(t.Effective, "NOW") + " - " + format(t.Expires, "UFN")
+}
+
+const tfrUnlimited = 99999
+
+// FAATFRURL gives the location of the FAA's GeoJSON feed of the currently
+// published TFRs.
+const FAATFRURL = "https://tfr.faa.gov/geoserver/TFR/ows?service=WFS&version=1.1.0&request=GetFeature" +
+	"&typeName=TFR:V_TFR_LOC&maxFeatures=1000&outputFormat=application/json&srsname=EPSG:4326"
+
+// FetchFAATFRs fetches the current TFRs from the FAA. If they can't be
+// fetched, the ones that were fetched most recently are returned, if
+// available, with stale set to true.
+func FetchFAATFRs() (tfrs []TFR, stale bool, err error) {
+	b, stale, err := util.GetResourceCache().Get(FAATFRURL, 0, func() ([]byte, error) {
+		client := http.Client{Timeout: 30 * time.Second}
+		resp, err := client.Get(FAATFRURL)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%s: %s", FAATFRURL, resp.Status)
+		}
+		return io.ReadAll(resp.Body)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	tfrs, err = ParseTFRGeoJSON(b)
+	return tfrs, stale, err
+}
+
+// ParseTFRGeoJSON parses TFRs from a GeoJSON FeatureCollection; each
+// feature with Polygon or MultiPolygon geometry gives a TFR. Feature
+// properties are used (if present) for the name, altitude bounds, and
+// activation schedule; a variety of property names are accepted so that
+// both the FAA's feed and hand-written files can be used.
+func ParseTFRGeoJSON(b []byte) ([]TFR, error) {
+	var fc struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Geometry struct {
+				Type        string          `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(b, &fc); err != nil {
+		return nil, err
+	}
+	if fc.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("%q: expected GeoJSON \"FeatureCollection\"", fc.Type)
+	}
+
+	ring := func(coords [][]float64) []math.Point2LL {
+		var r []math.Point2LL
+		for _, c := range coords {
+			if len(c) >= 2 {
+				r = append(r, math.Point2LL{float32(c[0]), float32(c[1])})
+			}
+		}
+		// GeoJSON repeats the first vertex at the end of the ring.
+		if len(r) > 1 && r[0] == r[len(r)-1] {
+			r = r[:len(r)-1]
+		}
+		return r
+	}
+
+	var tfrs []TFR
+	for i, f := range fc.Features {
+		tfr := TFR{Ceiling: tfrUnlimited}
+
+		switch f.Geometry.Type {
+		case "Polygon":
+			var coords [][][]float64
+			if err := json.Unmarshal(f.Geometry.Coordinates, &coords); err != nil {
+				return nil, fmt.Errorf("feature %d: %w", i, err)
+			}
+			if len(coords) > 0 { // ignore holes
+				tfr.Polygons = append(tfr.Polygons, ring(coords[0]))
+			}
+		case "MultiPolygon":
+			var coords [][][][]float64
+			if err := json.Unmarshal(f.Geometry.Coordinates, &coords); err != nil {
+				return nil, fmt.Errorf("feature %d: %w", i, err)
+			}
+			for _, poly := range coords {
+				if len(poly) > 0 {
+					tfr.Polygons = append(tfr.Polygons, ring(poly[0]))
+				}
+			}
+		default:
+			// Points, lines, etc., aren't meaningful as TFRs.
+			continue
+		}
+
+		props := make(map[string]interface{})
+		for k, v := range f.Properties {
+			props[strings.ToLower(k)] = v
+		}
+		lookup := func(keys ...string) (interface{}, bool) {
+			for _, k := range keys {
+				if v, ok := props[k]; ok && v != nil {
+					return v, true
+				}
+			}
+			return nil, false
+		}
+		lookupString := func(keys ...string) string {
+			if v, ok := lookup(keys...); ok {
+				return strings.TrimSpace(fmt.Sprint(v))
+			}
+			return ""
+		}
+		lookupAltitude := func(keys ...string) (int, bool) {
+			v, ok := lookup(keys...)
+			if !ok {
+				return 0, false
+			}
+			switch a := v.(type) {
+			case float64:
+				return int(a), true
+			case string:
+				if a = strings.ToUpper(strings.TrimSpace(a)); a == "SFC" || a == "GND" {
+					return 0, true
+				} else if a == "UNL" || a == "UNLTD" {
+					return tfrUnlimited, true
+				} else if alt, err := strconv.Atoi(strings.TrimPrefix(a, "FL")); err == nil {
+					return util.Select(strings.HasPrefix(a, "FL"), 100*alt, alt), true
+				}
+			}
+			return 0, false
+		}
+		lookupTime := func(keys ...string) (time.Time, error) {
+			s := lookupString(keys...)
+			if s == "" {
+				return time.Time{}, nil
+			}
+			for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "200601021504"} {
+				if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+					return t, nil
+				}
+			}
+			return time.Time{}, fmt.Errorf("%s: unable to parse time", s)
+		}
+
+		tfr.Name = lookupString("name", "notam", "notam_key", "notam_id")
+		if tfr.Name == "" {
+			tfr.Name = fmt.Sprintf("TFR %d", i+1)
+		}
+		tfr.Description = lookupString("description", "title", "legal")
+		if alt, ok := lookupAltitude("floor", "lower", "lower_val", "floor_ft"); ok {
+			tfr.Floor = alt
+		}
+		if alt, ok := lookupAltitude("ceiling", "upper", "upper_val", "ceiling_ft"); ok {
+			tfr.Ceiling = alt
+		}
+
+		var err error
+		if tfr.Effective, err = lookupTime("effective", "start", "date_effective", "effective_date"); err != nil {
+			return nil, fmt.Errorf("feature %d: %w", i, err)
+		}
+		if tfr.Expires, err = lookupTime("expires", "end", "date_expire", "expire_date"); err != nil {
+			return nil, fmt.Errorf("feature %d: %w", i, err)
+		}
+
+		tfrs = append(tfrs, tfr)
+	}
+
+	return tfrs, nil
+}
@@ -22,7 +22,7 @@ type Logger struct {
 	Start   time.Time
 }
 
-func New(server bool, level string) *Logger {
+func New(server bool, level string, dir string) *Logger {
 	var w *lumberjack.Logger
 
 	if server {
@@ -33,12 +33,7 @@ func New(server bool, level string) *Logger {
 			Compress: true,
 		}
 	} else {
-		dir, err := os.UserConfigDir()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Unable to find user config dir: %v", err)
-			dir = "."
-		}
-		fn := path.Join(dir, "Vice", "vice.slog")
+		fn := path.Join(dir, "vice.slog")
 
 		w = &lumberjack.Logger{
 			Filename:   fn,
@@ -146,6 +146,22 @@ func LineLineIntersect(p1f, p2f, p3f, p4f [2]float32) ([2]float32, bool) {
 	return [2]float32{float32(numx / denom), float32(numy / denom)}, true
 }
 
+// SegmentSegmentIntersect returns true if the line segments (p0, p1) and
+// (q0, q1) intersect; segments that only touch at an endpoint are
+// considered to intersect, while collinear overlapping segments are not.
+func SegmentSegmentIntersect(p0, p1, q0, q1 [2]float32) bool {
+	cross := func(o, a, b [2]float32) float32 {
+		return (a[0]-o[0])*(b[1]-o[1]) - (a[1]-o[1])*(b[0]-o[0])
+	}
+	d0, d1 := cross(q0, q1, p0), cross(q0, q1, p1)
+	d2, d3 := cross(p0, p1, q0), cross(p0, p1, q1)
+	if (d0 == 0 && d1 == 0) || (d2 == 0 && d3 == 0) {
+		return false
+	}
+	return ((d0 <= 0 && d1 >= 0) || (d0 >= 0 && d1 <= 0)) &&
+		((d2 <= 0 && d3 >= 0) || (d2 >= 0 && d3 <= 0))
+}
+
 // RayRayMinimumDistance takes two rays p0+d0*t and p1+d1*t and returns the
 // value of t where their distance is minimized.
 func RayRayMinimumDistance(p0, d0, p1, d1 [2]float32) float32 {
@@ -176,6 +176,28 @@ func TestPointInPolygon(t *testing.T) {
 	}
 }
 
+func TestSegmentSegmentIntersect(t *testing.T) {
+	cases := []struct {
+		p0, p1, q0, q1 [2]float32
+		expected       bool
+	}{
+		{p0: [2]float32{0, 0}, p1: [2]float32{2, 2}, q0: [2]float32{0, 2}, q1: [2]float32{2, 0}, expected: true},
+		{p0: [2]float32{0, 0}, p1: [2]float32{1, 1}, q0: [2]float32{0, 2}, q1: [2]float32{0.9, 1.1}, expected: false},
+		{p0: [2]float32{0, 0}, p1: [2]float32{2, 0}, q0: [2]float32{1, 0}, q1: [2]float32{1, 3}, expected: true},
+		{p0: [2]float32{0, 0}, p1: [2]float32{2, 0}, q0: [2]float32{0, 1}, q1: [2]float32{2, 1}, expected: false},
+		{p0: [2]float32{0, 0}, p1: [2]float32{2, 0}, q0: [2]float32{1, 0}, q1: [2]float32{3, 0}, expected: false},
+	}
+
+	for _, c := range cases {
+		if r := SegmentSegmentIntersect(c.p0, c.p1, c.q0, c.q1); r != c.expected {
+			t.Errorf("(%v, %v) (%v, %v): expected %v, got %v", c.p0, c.p1, c.q0, c.q1, c.expected, r)
+		}
+		if r := SegmentSegmentIntersect(c.q0, c.q1, c.p0, c.p1); r != c.expected {
+			t.Errorf("(%v, %v) (%v, %v): expected %v, got %v", c.q0, c.q1, c.p0, c.p1, c.expected, r)
+		}
+	}
+}
+
 func TestPointSegmentDistance(t *testing.T) {
 	refSampled := func(p, v, w [2]float32) float32 {
 		const n = 16384
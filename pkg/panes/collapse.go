@@ -0,0 +1,187 @@
+// pkg/panes/collapse.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package panes
+
+import (
+	"slices"
+
+	"github.com/mmp/imgui-go/v4"
+	"github.com/mmp/vice/pkg/log"
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/platform"
+	"github.com/mmp/vice/pkg/renderer"
+	"github.com/mmp/vice/pkg/sim"
+)
+
+// The pane of a leaf node that is one of the children of a split may be
+// collapsed to a thin labeled bar at one of its edges, which gives its
+// space to the neighboring child until it is restored. All but the last
+// child of a split collapse toward their start, moving the split line
+// after them; the last one collapses toward its end. The node records the
+// split line's position from before it was collapsed in RestorePos.
+
+// CollapsedBar is drawn in place of a collapsed pane; clicking it restores
+// the pane. Like TabStrip, it implements the Pane interface so that it is
+// handled along with the other panes.
+type CollapsedBar struct {
+	node *DisplayNode
+}
+
+func (*CollapsedBar) Activate(renderer.Renderer, platform.Platform, *sim.EventStream, *log.Logger) {}
+func (*CollapsedBar) Deactivate()                                                                  {}
+func (*CollapsedBar) LoadedSim(sim.State, platform.Platform, *log.Logger)                          {}
+func (*CollapsedBar) ResetSim(sim.State, platform.Platform, *log.Logger)                           {}
+func (*CollapsedBar) CanTakeKeyboardFocus() bool                                                   { return false }
+func (*CollapsedBar) Hide() bool                                                                   { return false }
+
+func collapsedBarSize() float32 {
+	return tabStripHeight()
+}
+
+func (b *CollapsedBar) Draw(ctx *Context, cb *renderer.CommandBuffer) {
+	if ctx.Mouse != nil {
+		ctx.Mouse.SetCursor(imgui.MouseCursorHand)
+		if ctx.Mouse.Clicked[platform.MouseButtonPrimary] {
+			wm.restoreNode = b.node
+		}
+	}
+
+	font := renderer.GetDefaultFont()
+	style := renderer.TextStyle{Font: font, Color: UITextColor}
+	w, h := ctx.PaneExtent.Width(), ctx.PaneExtent.Height()
+
+	td := renderer.GetTextDrawBuilder()
+	defer renderer.ReturnTextDrawBuilder(td)
+
+	name := PaneName(b.node.Pane)
+	if w < h {
+		// Tall and narrow: write the name vertically, one character per
+		// line.
+		y := h - 3
+		for _, ch := range name {
+			if y < float32(font.Size) {
+				break
+			}
+			td.AddText(string(ch), [2]float32{3, y}, style)
+			y -= float32(font.Size)
+		}
+	} else {
+		td.AddText(name, [2]float32{tabPadding, h - 3}, style)
+	}
+
+	cb.ClearRGB(UIControlColor.Scale(0.5))
+	ctx.SetWindowCoordinateMatrices(cb)
+	td.GenerateCommands(cb)
+}
+
+func (d *DisplayNode) collapsedBar() *CollapsedBar {
+	if d.bar == nil {
+		d.bar = &CollapsedBar{node: d}
+	}
+	return d.bar
+}
+
+// collapseLine returns the index of the split line that is moved when the
+// i-th child of an interior node is collapsed.
+func (d *DisplayNode) collapseLine(i int) int {
+	return min(i, len(d.Children)-2)
+}
+
+// collapsedSplitPos returns the position that the i-th split line of an
+// interior node must have to leave room for just the bar of a collapsed
+// child next to it, given the size of the node along the split axis. If
+// neither child uses the line, false is returned.
+func (d *DisplayNode) collapsedSplitPos(i int, size float32) (float32, bool) {
+	bar := math.Min(collapsedBarSize()/size, 0.5)
+	if d.Children[i].Collapsed {
+		start := float32(0)
+		if i > 0 {
+			start = d.splitLine(i - 1).Pos
+		}
+		return start + bar, true
+	} else if i == len(d.Children)-2 && d.Children[i+1].Collapsed {
+		return 1 - bar, true
+	}
+	return 0, false
+}
+
+// CollapsePane collapses the given pane to a bar. It returns false if the
+// pane can't be collapsed: if it isn't part of a split, if all of its
+// siblings are collapsed, if the split line it would move is already
+// being used by a collapsed sibling, or if it is the last visible pane
+// that can take the keyboard focus.
+func (d *DisplayNode) CollapsePane(pane Pane) bool {
+	parent, idx := d.ParentNodeForPane(pane)
+	if parent == nil || parent.Children[idx].Collapsed {
+		return false
+	}
+
+	line := parent.collapseLine(idx)
+	expanded := 0
+	for i, child := range parent.Children {
+		if child.Collapsed {
+			if parent.collapseLine(i) == line {
+				return false
+			}
+		} else {
+			expanded++
+		}
+	}
+	if expanded < 2 {
+		return false
+	}
+
+	if pane.CanTakeKeyboardFocus() && !slices.ContainsFunc(wmKeyboardPanes(d, d, true, true),
+		func(p Pane) bool { return p != pane }) {
+		return false
+	}
+
+	wm.history.push(d)
+	node := parent.Children[idx]
+	node.Collapsed = true
+	node.RestorePos = parent.splitLine(line).Pos
+	return true
+}
+
+// RestorePane restores a collapsed pane to the size it had before it was
+// collapsed, as far as the neighboring split lines allow.
+func (d *DisplayNode) RestorePane(pane Pane) {
+	parent, idx := d.ParentNodeForPane(pane)
+	if parent == nil || !parent.Children[idx].Collapsed {
+		return
+	}
+
+	wm.history.push(d)
+	node := parent.Children[idx]
+	line := parent.collapseLine(idx)
+
+	// The neighboring split lines may have been moved in the meantime.
+	lo, hi := float32(.01), float32(.99)
+	if line > 0 {
+		lo = parent.splitLine(line-1).Pos + .01
+	}
+	if line+1 < len(parent.Children)-1 {
+		hi = parent.splitLine(line+1).Pos - .01
+	}
+	parent.splitLine(line).Pos = math.Clamp(node.RestorePos, lo, hi)
+	node.Collapsed, node.RestorePos = false, 0
+}
+
+// IsCollapsed returns true if the given pane has been collapsed.
+func (d *DisplayNode) IsCollapsed(pane Pane) bool {
+	parent, idx := d.ParentNodeForPane(pane)
+	return parent != nil && parent.Children[idx].Collapsed
+}
+
+// wmRestoreClickedBar restores the pane whose bar was clicked, if any.
+func wmRestoreClickedBar(root *DisplayNode) {
+	if wm.restoreNode == nil {
+		return
+	}
+	if n := wm.restoreNode; n.Collapsed {
+		root.RestorePane(n.Pane)
+	}
+	wm.restoreNode = nil
+}
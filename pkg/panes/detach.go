@@ -0,0 +1,223 @@
+// pkg/panes/detach.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package panes
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/mmp/vice/pkg/log"
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/platform"
+	"github.com/mmp/vice/pkg/renderer"
+	"github.com/mmp/vice/pkg/sim"
+	"github.com/mmp/vice/pkg/util"
+)
+
+// Panes can be detached from the main window's display hierarchy into
+// their own top-level windows, e.g. to put them on another monitor. The
+// detached panes are stored in the root DisplayNode so that they are
+// saved and restored along with the rest of the layout.
+
+// DetachedPane is a pane that is shown in its own window.
+type DetachedPane struct {
+	// Node is a leaf node that holds the pane; using a DisplayNode lets us
+	// reuse its JSON marshaling of Panes.
+	Node *DisplayNode
+	// Position and Size record the window's most recent position and
+	// size on the screen so that it can be restored in the same place.
+	Position, Size [2]int
+	// Geometries records the window's geometry for each display
+	// configuration, keyed by Platform.DisplayConfiguration(), so that a
+	// layout that spans multiple monitors is restored correctly when
+	// monitors are connected or disconnected.
+	Geometries map[string]platform.WindowGeometry `json:",omitempty"`
+
+	window               platform.SecondaryWindow
+	displayConfiguration string
+}
+
+// geometry returns the window geometry to use for the given display
+// configuration.
+func (dp *DetachedPane) geometry(config string) platform.WindowGeometry {
+	if g, ok := dp.Geometries[config]; ok {
+		return g
+	}
+	return platform.WindowGeometry{Size: dp.Size, Position: dp.Position}
+}
+
+// DetachedPanes returns the panes that are currently shown in their own
+// windows.
+func (d *DisplayNode) DetachedPanes() []Pane {
+	return util.MapSlice(d.Detached, func(dp *DetachedPane) Pane { return dp.Node.Pane })
+}
+
+// DetachPane removes the given pane from the main window and shows it in
+// its own window. The last pane in the main window can't be detached;
+// false is returned if the pane wasn't detached.
+func (d *DisplayNode) DetachPane(pane Pane) bool {
+	if _, ok := pane.(*SplitLine); ok {
+		return false
+	}
+	prev := d.clone()
+	if !d.removePane(pane) {
+		return false
+	}
+	wm.history.record(d, prev)
+
+	dp := &DetachedPane{Node: &DisplayNode{Pane: pane}}
+	if e, ok := wm.paneExtents[pane]; ok {
+		dp.Size = [2]int{int(e.Width()), int(e.Height())}
+	}
+	d.Detached = append(d.Detached, dp)
+	return true
+}
+
+// RedockPane closes the window of a detached pane and returns the pane to
+// the main window, along its right side.
+func (d *DisplayNode) RedockPane(pane Pane) {
+	idx := slices.IndexFunc(d.Detached, func(dp *DetachedPane) bool { return dp.Node.Pane == pane })
+	if idx == -1 {
+		return
+	}
+	wm.history.push(d)
+	if w := d.Detached[idx].window; w != nil {
+		w.Destroy()
+		d.Detached[idx].window = nil
+	}
+	d.Detached = slices.Delete(d.Detached, idx, idx+1)
+
+	old := *d
+	old.Detached, old.Overlays, old.strip, old.title = nil, nil, nil, nil
+	d.replace(&DisplayNode{
+		SplitLine: SplitLine{Axis: SplitAxisX, Pos: 0.8},
+		Children:  []*DisplayNode{&old, &DisplayNode{Pane: pane}},
+	})
+}
+
+// removePane removes the given pane from the display hierarchy, either
+// from its tab group or by replacing its parent node with its sibling. It
+// returns false if the pane isn't present or is the only one.
+func (d *DisplayNode) removePane(pane Pane) bool {
+	if node := d.NodeForPane(pane); node != nil && len(node.Tabs) > 0 {
+		node.RemoveTab(pane)
+		return true
+	} else if parent, idx := d.ParentNodeForPane(pane); parent != nil {
+		parent.removeChild(idx)
+		return true
+	}
+	return false
+}
+
+// replace sets the node to be n; the detached and overlay panes, which
+// are only stored at the root, are preserved.
+func (d *DisplayNode) replace(n *DisplayNode) {
+	detached, overlays := d.Detached, d.Overlays
+	*d = *n
+	d.strip, d.bar, d.title = nil, nil, nil
+	d.Detached, d.Overlays = detached, overlays
+}
+
+// drawDetachedPanes draws each of the detached panes in its own window,
+// creating the windows as needed. Panes whose windows have been closed
+// are returned to the main window.
+func drawDetachedPanes(root *DisplayNode, p platform.Platform, r renderer.Renderer, controlClient *sim.ControlClient,
+	keyboard *platform.KeyboardState, audioEnabled *bool, degraded bool, lg *log.Logger) {
+	config := p.DisplayConfiguration()
+	for _, dp := range slices.Clone(root.Detached) {
+		if dp.window == nil {
+			g := dp.geometry(config)
+			w, err := p.NewSecondaryWindow(fmt.Sprintf("vice: %s", PaneName(dp.Node.Pane)), g.Size, g.Position)
+			if err != nil {
+				lg.Errorf("%s: unable to create window: %v", PaneName(dp.Node.Pane), err)
+				root.RedockPane(dp.Node.Pane)
+				continue
+			}
+			dp.window = w
+			dp.displayConfiguration = config
+		}
+
+		w := dp.window
+		if w.ShouldClose() {
+			root.RedockPane(dp.Node.Pane)
+			continue
+		}
+		if dp.displayConfiguration != config {
+			// The monitors have changed; put the window back where it was
+			// the last time they were used, or make sure it's still
+			// visible.
+			w.SetGeometry(dp.geometry(config))
+			dp.displayConfiguration = config
+		}
+		dp.Position, dp.Size = w.WindowPosition(), w.WindowSize()
+		if dp.Geometries == nil {
+			dp.Geometries = make(map[string]platform.WindowGeometry)
+		}
+		dp.Geometries[config] = platform.WindowGeometry{Size: dp.Size, Position: dp.Position}
+
+		displaySize, fbSize := w.DisplaySize(), w.FramebufferSize()
+		if fbSize[0] == 0 || fbSize[1] == 0 {
+			// Minimized
+			continue
+		}
+		windowExtent := math.Extent2D{P1: displaySize}
+
+		// Clicking in a window gives its pane the keyboard focus.
+		if w.Clicked() && dp.Node.Pane.CanTakeKeyboardFocus() {
+			wm.focus.Take(dp.Node.Pane)
+		}
+		mouse := w.GetMouse()
+
+		cb := renderer.GetCommandBuffer()
+		cb.ClearRGB(renderer.RGB{})
+		dp.Node.visitPanesWithBounds(windowExtent, windowExtent, p, false,
+			func(paneExtent math.Extent2D, parentExtent math.Extent2D, pane Pane) {
+				scale := paneScale(pane)
+				ctx := Context{
+					PaneExtent:       scaledExtent(paneExtent, scale),
+					ParentPaneExtent: parentExtent,
+					Platform:         p,
+					DrawPixelScale:   util.Select(runtime.GOOS == "windows", p.DPIScale(), float32(1)),
+					PixelsPerInch:    util.Select(runtime.GOOS == "windows", 96*p.DPIScale(), 72),
+					DPIScale:         p.DPIScale(),
+					Scale:            scale,
+					Renderer:         r,
+					Keyboard:         keyboard,
+					HaveFocus:        pane == wm.focus.Current() && keyboard != nil,
+					Now:              time.Now(),
+					Lg:               lg,
+					AudioEnabled:     audioEnabled,
+					Degraded:         degraded,
+					KeyboardFocus:    &wm.focus,
+					ControlClient:    controlClient,
+				}
+
+				if mouse != nil {
+					// As in Context.InitializeMouse, but w.r.t. the
+					// detached window.
+					m := *mouse
+					m.Pos[0] -= paneExtent.P0[0]
+					m.Pos[1] = displaySize[1] - 1 - paneExtent.P0[1] - m.Pos[1]
+					m.Wheel[1] *= -1
+					m.DragDelta[1] *= -1
+					if paneExtent.Inside([2]float32{mouse.Pos[0], displaySize[1] - 1 - mouse.Pos[1]}) {
+						ctx.Mouse = &m
+						ctx.scaleMouse()
+					}
+				}
+
+				cb.SetDrawBounds(paneExtent, fbSize[1]/displaySize[1])
+				pane.Draw(&ctx, cb)
+				cb.ResetState()
+			})
+
+		w.BeginRender()
+		r.RenderCommandBuffer(cb)
+		w.EndRender()
+		renderer.ReturnCommandBuffer(cb)
+	}
+}
@@ -6,8 +6,8 @@
 // which is more or less how we're describing the handling of the tiled
 // window layout.  Other than the main menu bar, which is handled
 // via imgui calls in ui.go, all of the rest of the window is managed here.
-// At the top is the status bar and then the rest of the window is
-// a kd-tree of Panes, separated by SplitLines.
+// Below the menu bar, the window is a kd-tree of Panes, separated by
+// SplitLines.
 
 package panes
 
@@ -16,6 +16,7 @@ import (
 	"fmt"
 	"runtime"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/mmp/imgui-go/v4"
@@ -39,10 +40,100 @@ var (
 
 		focus WMKeyboardFocus
 
+		// In compact mode, only a single pane is shown; compactPane
+		// records which one.
+		compactPane Pane
+
+		// Window-coordinate extents of the panes drawn in the most
+		// recent frame.
+		paneExtents map[Pane]math.Extent2D
+		// Size in pixels of the nodes that the split lines drawn in the
+		// most recent frame split, along the split axis.
+		splitSizes map[*SplitLine]float32
+
+		// Non-nil while the user is ctrl-dragging a pane to move it.
+		paneDrag *paneDrag
+
+		// maximizedPane is shown using the entire window when set;
+		// lastMaximizedPane is the one that was most recently maximized.
+		maximizedPane, lastMaximizedPane Pane
+
+		history layoutHistory
+
+		// restoreNode is set when the bar of a collapsed pane has been
+		// clicked.
+		restoreNode *DisplayNode
+
+		// titleBars is set when panes in the main window are drawn with
+		// title bars; titleBarRequest records a click on one of their
+		// buttons and settingsPane is the pane whose settings the user
+		// has asked to see. See titlebar.go.
+		titleBars       bool
+		titleBarRequest *titleBarRequest
+		settingsPane    Pane
+
+		// dockOverlay is set when the button to return an overlay pane
+		// to the layout has been clicked. See overlay.go.
+		dockOverlay Pane
+
 		lastAircraftResponse string
 	}
 )
 
+// CompactPane returns the pane that is shown in compact mode.
+func CompactPane() Pane {
+	return wm.compactPane
+}
+
+// PaneExtent returns the extent in window coordinates that the given pane
+// was drawn with in the most recent frame; false is returned if it wasn't
+// drawn, e.g. because it is hidden.
+func PaneExtent(pane Pane) (math.Extent2D, bool) {
+	e, ok := wm.paneExtents[pane]
+	return e, ok
+}
+
+// SetCompactPane sets the pane to be shown in compact mode and gives it
+// the keyboard focus, if it can take it.
+func SetCompactPane(pane Pane) {
+	wm.compactPane = pane
+	if pane.CanTakeKeyboardFocus() {
+		wm.focus.Take(pane)
+	}
+}
+
+// ShowPane makes the given pane visible and gives it the keyboard focus,
+// if it can take it: it is made the active tab of its tab group, restored
+// if it has been collapsed, and, in compact mode, made the pane that is
+// shown. If maximize is true, it is also shown using the entire window
+// unless it is detached.
+func ShowPane(root *DisplayNode, pane Pane, maximize bool) {
+	node := root.leafForPane(pane)
+	if node == nil {
+		return
+	}
+	if idx := slices.Index(node.Tabs, pane); idx != -1 {
+		node.SelectTab(idx, nil)
+	}
+	if root.IsCollapsed(pane) {
+		root.RestorePane(pane)
+	}
+
+	wm.compactPane = pane
+	detached := slices.ContainsFunc(root.Detached, func(dp *DetachedPane) bool {
+		return dp.Node.leafForPane(pane) != nil
+	})
+	if maximize && !detached {
+		wm.maximizedPane = pane
+	} else if wm.maximizedPane != nil && wm.maximizedPane != pane {
+		wm.lastMaximizedPane, wm.maximizedPane = wm.maximizedPane, nil
+	}
+
+	if pane.CanTakeKeyboardFocus() {
+		wm.focus.Take(pane)
+	}
+}
+
 type WMKeyboardFocus struct {
 	initial Pane
 
@@ -79,6 +170,18 @@ func (f *WMKeyboardFocus) Current() Pane {
 	return f.current
 }
 
+// Update makes sure that one of the given panes, which are those that can
+// currently take the focus, has the focus, giving it to the first if not.
+// If there are none, e.g. when only flight strips are visible, nothing
+// has the focus.
+func (f *WMKeyboardFocus) Update(kp []Pane) {
+	if len(kp) == 0 {
+		*f = WMKeyboardFocus{}
+	} else if f.current == nil || !slices.Contains(kp, f.current) {
+		*f = WMKeyboardFocus{initial: kp[0], current: kp[0]}
+	}
+}
+
 ///////////////////////////////////////////////////////////////////////////
 // SplitLine
 
@@ -97,8 +200,26 @@ type SplitLine struct {
 	// Offset in [0,1] with respect to the parent Pane's bounds.
 	Pos  float32
 	Axis SplitType
+
+	// Range that Pos may be dragged over; with n-way splits, it's limited
+	// by the neighboring split lines.
+	minPos, maxPos float32
+
+	// While the line is being dragged, dragPos tracks where it would be
+	// without snapping.
+	dragPos float32
 }
 
+// splitSnapRatios are the positions that split lines snap to when they
+// are dragged within splitSnapDistance pixels of them.
+var splitSnapRatios = []float32{0.25, 1. / 3, 0.5, 2. / 3, 0.75}
+
+const splitSnapDistance = 8
+
+// splitWheelStep is the amount that a split line moves for each click of
+// the mouse wheel; it moves a tenth as much if shift is held.
+const splitWheelStep = 0.01
+
 func (s *SplitLine) Activate(renderer.Renderer, platform.Platform, *sim.EventStream, *log.Logger) {}
 func (s *SplitLine) Deactivate()                                                                  {}
 func (s *SplitLine) LoadedSim(sim.State, platform.Platform, *log.Logger)                          {}
@@ -107,23 +228,48 @@ func (s *SplitLine) CanTakeKeyboardFocus() bool
 func (s *SplitLine) Hide() bool                                                                   { return false }
 
 func (s *SplitLine) Draw(ctx *Context, cb *renderer.CommandBuffer) {
-	if ctx.Mouse != nil {
+	if ctx.Mouse != nil && !ctx.LayoutLocked {
 		if s.Axis == SplitAxisX {
 			ctx.Mouse.SetCursor(imgui.MouseCursorResizeEW)
 		} else {
 			ctx.Mouse.SetCursor(imgui.MouseCursorResizeNS)
 		}
 
+		if ctx.Mouse.Clicked[platform.MouseButtonSecondary] {
+			s.dragPos = s.Pos
+		}
 		if ctx.Mouse.Dragging[platform.MouseButtonSecondary] {
 			delta := ctx.Mouse.DragDelta
-
-			if s.Axis == SplitAxisX {
-				s.Pos += delta[0] / ctx.ParentPaneExtent.Width()
-			} else {
-				s.Pos += delta[1] / ctx.ParentPaneExtent.Height()
+			size := util.Select(s.Axis == SplitAxisX, ctx.ParentPaneExtent.Width(), ctx.ParentPaneExtent.Height())
+			s.dragPos += util.Select(s.Axis == SplitAxisX, delta[0], delta[1]) / size
+
+			// Snap to the nearby common ratio, if any, unless shift is
+			// held.
+			s.Pos = s.dragPos
+			if !imgui.CurrentIO().KeyShiftPressed() {
+				for _, r := range splitSnapRatios {
+					if math.Abs(s.dragPos-r)*size < splitSnapDistance {
+						s.Pos = r
+						break
+					}
+				}
 			}
 			// Just in case
-			s.Pos = math.Clamp(s.Pos, .01, .99)
+			s.Pos = math.Clamp(s.Pos, s.minPos, s.maxPos)
+
+			imgui.SetTooltip(fmt.Sprintf("%.0f%%", 100*s.Pos))
+		}
+
+		// The mouse wheel nudges the line, which allows finer
+		// adjustments than dragging, and a double-click centers it.
+		if wheel := ctx.Mouse.Wheel[1]; wheel != 0 {
+			step := util.Select(imgui.CurrentIO().KeyShiftPressed(), float32(splitWheelStep/10), splitWheelStep)
+			s.Pos = math.Clamp(s.Pos+wheel*step, s.minPos, s.maxPos)
+			imgui.SetTooltip(fmt.Sprintf("%.1f%%", 100*s.Pos))
+		}
+		if ctx.Mouse.DoubleClicked[platform.MouseButtonPrimary] ||
+			ctx.Mouse.DoubleClicked[platform.MouseButtonSecondary] {
+			s.Pos = math.Clamp(0.5, s.minPos, s.maxPos)
 		}
 	}
 
@@ -133,6 +279,79 @@ func (s *SplitLine) Draw(ctx *Context, cb *renderer.CommandBuffer) {
 	cb.ClearRGB(UIControlColor)
 }
 
+// Split describes one of the split lines in a display hierarchy so that
+// its position can be set directly.
+type Split struct {
+	Line *SplitLine
+	// Before and After are the names of the panes on either side of the
+	// line: to its left and right or below and above it.
+	Before, After string
+	// Start and End are the positions of the neighboring split lines, or
+	// 0 and 1 if there are none.
+	Start, End float32
+	// Size is the size in pixels of the node along the split axis, or
+	// zero if it wasn't drawn in the most recent frame.
+	Size float32
+}
+
+// Splits returns all of the split lines in the display hierarchy other
+// than those in detached windows.
+func (d *DisplayNode) Splits() []Split {
+	var splits []Split
+	var visit func(d *DisplayNode)
+	visit = func(d *DisplayNode) {
+		n := len(d.Children)
+		for i, child := range d.Children {
+			if i < n-1 {
+				s := Split{
+					Line:   d.splitLine(i),
+					Before: child.paneNames(),
+					After:  d.Children[i+1].paneNames(),
+					Start:  0,
+					End:    1,
+					Size:   wm.splitSizes[d.splitLine(i)],
+				}
+				if i > 0 {
+					s.Start = d.splitLine(i - 1).Pos
+				}
+				if i+1 < n-1 {
+					s.End = d.splitLine(i + 1).Pos
+				}
+				splits = append(splits, s)
+			}
+			visit(child)
+		}
+	}
+	visit(d)
+	return splits
+}
+
+// paneNames returns the names of the panes under the node, separated by
+// commas.
+func (d *DisplayNode) paneNames() string {
+	var names []string
+	d.visitVisiblePanes(func(pane Pane) {
+		if _, ok := pane.(*SplitLine); !ok {
+			names = append(names, PaneName(pane))
+		}
+	})
+	return strings.Join(names, ", ")
+}
+
+// SetSplitPos moves the given split line in the hierarchy to pos, within
+// the range that it can be dragged over. The change can be undone.
+func (d *DisplayNode) SetSplitPos(s *SplitLine, pos float32) {
+	lo, hi := s.minPos, s.maxPos
+	if lo == 0 && hi == 0 {
+		// It hasn't been drawn yet.
+		lo, hi = .01, .99
+	}
+	if pos = math.Clamp(pos, lo, hi); pos != s.Pos {
+		wm.history.push(d)
+		s.Pos = pos
+	}
+}
+
 func splitLineWidth(p platform.Platform) int {
 	return int(util.Select(runtime.GOOS == "windows", p.DPIScale(), float32(1))*2 + 0.5)
 }
@@ -141,13 +360,41 @@ func splitLineWidth(p platform.Platform) int {
 // DisplayNode
 
 // DisplayNode represents a node in the Pane display hierarchy, which is a
-// kd-tree.
+// kd-tree, generalized so that interior nodes may be split into more than
+// two children along their axis.
 type DisplayNode struct {
 	// non-nil only for leaf nodes: iff splitAxis == SplitAxisNone
-	Pane      Pane
-	SplitLine SplitLine
+	Pane Pane
+	// SplitLine separates the first two children; MoreSplits holds the
+	// split lines between the subsequent ones for nodes with more than
+	// two children. All positions are with respect to the node's bounds
+	// and are increasing.
+	SplitLine  SplitLine
+	MoreSplits []SplitLine `json:",omitempty"`
 	// non-nil only for interior notes: iff splitAxis != SplitAxisNone
-	Children [2]*DisplayNode
+	Children []*DisplayNode
+
+	// Leaf nodes may hold a tab group, in which case Tabs holds all of
+	// its panes and Pane is Tabs[ActiveTab].
+	Tabs      []Pane `json:",omitempty"`
+	ActiveTab int    `json:",omitempty"`
+	strip     *TabStrip
+
+	// Collapsed is set if a leaf node's pane has been collapsed to a bar;
+	// RestorePos then holds the position of the split line that was
+	// moved, for when it is restored. See collapse.go.
+	Collapsed  bool    `json:",omitempty"`
+	RestorePos float32 `json:",omitempty"`
+	bar        *CollapsedBar
+
+	title *TitleBar
+
+	// Panes that have been detached into their own windows; only used
+	// at the root of the hierarchy.
+	Detached []*DetachedPane `json:",omitempty"`
+	// Panes that are drawn on top of the layout; also only used at the
+	// root.
+	Overlays []*OverlayPane `json:",omitempty"`
 }
 
 // NodeForPane searches a display node hierarchy for a given Pane,
@@ -156,15 +403,37 @@ func (d *DisplayNode) NodeForPane(pane Pane) *DisplayNode {
 	if d.Pane == pane {
 		return d
 	}
-	if d.Children[0] == nil {
-		// We've reached a leaf node without finding it.
-		return nil
+	for _, child := range d.Children {
+		if n := child.NodeForPane(pane); n != nil {
+			return n
+		}
+	}
+	// We've reached a leaf node without finding it.
+	return nil
+}
+
+// leafForPane returns the leaf node that holds the given pane, either as
+// its pane or as one of its tabs, including the nodes of detached panes.
+func (d *DisplayNode) leafForPane(pane Pane) *DisplayNode {
+	if d.Pane == pane || slices.Contains(d.Tabs, pane) {
+		return d
+	}
+	for _, child := range d.Children {
+		if n := child.leafForPane(pane); n != nil {
+			return n
+		}
 	}
-	d0 := d.Children[0].NodeForPane(pane)
-	if d0 != nil {
-		return d0
+	for _, dp := range d.Detached {
+		if n := dp.Node.leafForPane(pane); n != nil {
+			return n
+		}
+	}
+	for _, op := range d.Overlays {
+		if n := op.Node.leafForPane(pane); n != nil {
+			return n
+		}
 	}
-	return d.Children[1].NodeForPane(pane)
+	return nil
 }
 
 // ParentNodeForPane returns both the DisplayNode one level up the
@@ -175,16 +444,63 @@ func (d *DisplayNode) ParentNodeForPane(pane Pane) (*DisplayNode, int) {
 		return nil, -1
 	}
 
-	if d.Children[0] != nil && d.Children[0].Pane == pane {
-		return d, 0
-	} else if d.Children[1] != nil && d.Children[1].Pane == pane {
-		return d, 1
+	for i, child := range d.Children {
+		if child.Pane == pane {
+			return d, i
+		}
 	}
+	for _, child := range d.Children {
+		if c, idx := child.ParentNodeForPane(pane); c != nil {
+			return c, idx
+		}
+	}
+	return nil, -1
+}
 
-	if c0, idx := d.Children[0].ParentNodeForPane(pane); c0 != nil {
-		return c0, idx
+// splitLine returns the i-th split line of an interior node, the one
+// between its i-th and i+1-th children.
+func (d *DisplayNode) splitLine(i int) *SplitLine {
+	if i == 0 {
+		return &d.SplitLine
 	}
-	return d.Children[1].ParentNodeForPane(pane)
+	return &d.MoreSplits[i-1]
+}
+
+// splitExtents returns the extents of an interior node's children and of
+// the split lines between them. It also updates the range that each split
+// line can be dragged over.
+func (d *DisplayNode) splitExtents(e math.Extent2D, lineWidth int) (children, lines []math.Extent2D) {
+	size := util.Select(d.SplitLine.Axis == SplitAxisX, e.Width(), e.Height())
+	rest := e
+	for i := range len(d.Children) - 1 {
+		s := d.splitLine(i)
+		s.minPos, s.maxPos = .01, .99
+		if i > 0 {
+			s.minPos = d.splitLine(i-1).Pos + .01
+		}
+		if i+1 < len(d.Children)-1 {
+			s.maxPos = d.splitLine(i+1).Pos - .01
+		}
+		// The lines next to collapsed children are fixed so that they
+		// have room for just their bars.
+		if pos, ok := d.collapsedSplitPos(i, size); ok {
+			s.Pos, s.minPos, s.maxPos = pos, pos, pos
+		}
+
+		// Each child starts where the previous split line ends.
+		var c, l, r math.Extent2D
+		if d.SplitLine.Axis == SplitAxisX {
+			c, l, r = splitX(e, s.Pos, lineWidth)
+			c.P0[0] = rest.P0[0]
+		} else {
+			c, l, r = splitY(e, s.Pos, lineWidth)
+			c.P0[1] = rest.P0[1]
+		}
+		children = append(children, c)
+		lines = append(lines, l)
+		rest = r
+	}
+	return append(children, rest), lines
 }
 
 // TypedDisplayNodePane helps with marshaling to and unmarshaling from
@@ -194,12 +510,14 @@ func (d *DisplayNode) ParentNodeForPane(pane Pane) (*DisplayNode, int) {
 // The one messy bit is that when we save the DisplayNode hierarchy,
 // although the public member variables of Panes are automatically
 // serialized, the types of the Panes are not.  Therefore, we instead
-// marshal/unmarshal TypedDisplayNodePane instances, which carry along a
-// string representation of the associated Pane type stored at a
-// DisplayNode.
+// marshal/unmarshal TypedDisplayNodePane instances, which carry along
+// the name that the associated Pane's type was registered with using
+// RegisterPaneType.
 type TypedDisplayNodePane struct {
 	DisplayNode
 	Type string
+	// TabTypes similarly gives the types of the Panes in a tab group.
+	TabTypes []string `json:",omitempty"`
 }
 
 // MarshalJSON is called when a DisplayNode is to be marshaled into JSON.
@@ -207,8 +525,14 @@ type TypedDisplayNodePane struct {
 // the Pane's type.
 func (d *DisplayNode) MarshalJSON() ([]byte, error) {
 	td := TypedDisplayNodePane{DisplayNode: *d}
-	if d.Pane != nil {
-		td.Type = fmt.Sprintf("%T", d.Pane)
+	if len(d.Tabs) > 0 {
+		// The active pane is stored in Tabs.
+		td.Pane = nil
+		for _, pane := range d.Tabs {
+			td.TabTypes = append(td.TabTypes, paneTypeName(pane))
+		}
+	} else if d.Pane != nil {
+		td.Type = paneTypeName(d.Pane)
 	}
 	return json.Marshal(td)
 }
@@ -233,6 +557,69 @@ func (d *DisplayNode) UnmarshalJSON(s []byte) error {
 	if err := json.Unmarshal(*m["Children"], &d.Children); err != nil {
 		return err
 	}
+	if c, ok := m["Collapsed"]; ok && c != nil {
+		if err := json.Unmarshal(*c, &d.Collapsed); err != nil {
+			return err
+		}
+	}
+	if rp, ok := m["RestorePos"]; ok && rp != nil {
+		if err := json.Unmarshal(*rp, &d.RestorePos); err != nil {
+			return err
+		}
+	}
+	if !slices.ContainsFunc(d.Children, func(c *DisplayNode) bool { return c != nil }) {
+		// Leaf nodes used to be saved with two nil children.
+		d.Children = nil
+	}
+	if ms, ok := m["MoreSplits"]; ok && ms != nil {
+		if err := json.Unmarshal(*ms, &d.MoreSplits); err != nil {
+			return err
+		}
+	}
+	if d.SplitLine.Axis != SplitAxisNone && len(d.MoreSplits) != len(d.Children)-2 {
+		return fmt.Errorf("mismatched split lines and children in config file")
+	}
+	if det, ok := m["Detached"]; ok && det != nil {
+		if err := json.Unmarshal(*det, &d.Detached); err != nil {
+			return err
+		}
+	}
+	if ov, ok := m["Overlays"]; ok && ov != nil {
+		if err := json.Unmarshal(*ov, &d.Overlays); err != nil {
+			return err
+		}
+	}
+
+	if tabs, ok := m["Tabs"]; ok && tabs != nil {
+		var panes []json.RawMessage
+		var types []string
+		if err := json.Unmarshal(*tabs, &panes); err != nil {
+			return err
+		}
+		if t, ok := m["TabTypes"]; ok && t != nil {
+			if err := json.Unmarshal(*t, &types); err != nil {
+				return err
+			}
+		}
+		if len(types) != len(panes) || len(panes) == 0 {
+			return fmt.Errorf("mismatched tab group types in config file")
+		}
+		if at, ok := m["ActiveTab"]; ok && at != nil {
+			if err := json.Unmarshal(*at, &d.ActiveTab); err != nil {
+				return err
+			}
+		}
+
+		for i := range panes {
+			pane, err := UnmarshalPane(types[i], panes[i])
+			if err != nil {
+				return err
+			}
+			d.Tabs = append(d.Tabs, pane)
+		}
+		d.SelectTab(math.Clamp(d.ActiveTab, 0, len(d.Tabs)-1), nil)
+		return nil
+	}
 
 	// Now create the appropriate Pane type based on the type string.
 	if paneType == "" {
@@ -251,11 +638,26 @@ func (d *DisplayNode) UnmarshalJSON(s []byte) error {
 func (d *DisplayNode) VisitPanes(visit func(Pane)) {
 	switch d.SplitLine.Axis {
 	case SplitAxisNone:
-		visit(d.Pane)
+		if len(d.Tabs) > 0 {
+			for _, pane := range d.Tabs {
+				visit(pane)
+			}
+		} else {
+			visit(d.Pane)
+		}
 	default:
-		d.Children[0].VisitPanes(visit)
-		visit(&d.SplitLine)
-		d.Children[1].VisitPanes(visit)
+		for i, child := range d.Children {
+			if i > 0 {
+				visit(d.splitLine(i - 1))
+			}
+			child.VisitPanes(visit)
+		}
+	}
+	for _, dp := range d.Detached {
+		dp.Node.VisitPanes(visit)
+	}
+	for _, op := range d.Overlays {
+		op.Node.VisitPanes(visit)
 	}
 }
 
@@ -264,19 +666,37 @@ func (d *DisplayNode) VisitPanes(visit func(Pane)) {
 // the bounding box of its parent node in the DisplayNodeTree.
 func (d *DisplayNode) VisitPanesWithBounds(displayExtent math.Extent2D, parentDisplayExtent math.Extent2D, p platform.Platform,
 	visit func(math.Extent2D, math.Extent2D, Pane)) {
+	d.visitPanesWithBounds(displayExtent, parentDisplayExtent, p, wm.titleBars, visit)
+}
+
+func (d *DisplayNode) visitPanesWithBounds(displayExtent math.Extent2D, parentDisplayExtent math.Extent2D, p platform.Platform,
+	titleBars bool, visit func(math.Extent2D, math.Extent2D, Pane)) {
 	switch d.SplitLine.Axis {
 	case SplitAxisNone:
-		visit(displayExtent, parentDisplayExtent, d.Pane)
-	case SplitAxisX:
-		d0, ds, d1 := splitX(displayExtent, d.SplitLine.Pos, splitLineWidth(p))
-		d.Children[0].VisitPanesWithBounds(d0, displayExtent, p, visit)
-		visit(ds, displayExtent, &d.SplitLine)
-		d.Children[1].VisitPanesWithBounds(d1, displayExtent, p, visit)
-	case SplitAxisY:
-		d0, ds, d1 := splitY(displayExtent, d.SplitLine.Pos, splitLineWidth(p))
-		d.Children[0].VisitPanesWithBounds(d0, displayExtent, p, visit)
-		visit(ds, displayExtent, &d.SplitLine)
-		d.Children[1].VisitPanesWithBounds(d1, displayExtent, p, visit)
+		if d.Collapsed {
+			visit(displayExtent, parentDisplayExtent, d.collapsedBar())
+			return
+		}
+		if titleBars {
+			et, rest := splitTitleBar(displayExtent)
+			visit(et, displayExtent, d.titleBar())
+			parentDisplayExtent, displayExtent = displayExtent, rest
+		}
+		if len(d.Tabs) > 0 {
+			es, ep := splitTabStrip(displayExtent)
+			visit(es, displayExtent, d.tabStrip())
+			visit(ep, displayExtent, d.Pane)
+		} else {
+			visit(displayExtent, parentDisplayExtent, d.Pane)
+		}
+	default:
+		ce, le := d.splitExtents(displayExtent, splitLineWidth(p))
+		for i, child := range d.Children {
+			if i > 0 {
+				visit(le[i-1], displayExtent, d.splitLine(i-1))
+			}
+			child.visitPanesWithBounds(ce[i], displayExtent, p, titleBars, visit)
+		}
 	}
 }
 
@@ -289,7 +709,7 @@ func (d *DisplayNode) SplitX(x float32, newChild *DisplayNode) *DisplayNode {
 		panic(fmt.Sprintf("DisplayNode splitting a non-leaf node: %v", d))
 	}
 	return &DisplayNode{SplitLine: SplitLine{Axis: SplitAxisX, Pos: x},
-		Children: [2]*DisplayNode{d, newChild}}
+		Children: []*DisplayNode{d, newChild}}
 }
 
 // SplitY returns a new DisplayNode from splitting the provided node
@@ -298,8 +718,35 @@ func (d *DisplayNode) SplitY(y float32, newChild *DisplayNode) *DisplayNode {
 	if d.SplitLine.Axis != SplitAxisNone {
 		panic(fmt.Sprintf("DisplayNode splitting a non-leaf node: %v", d))
 	}
-	return &DisplayNode{SplitLine: SplitLine{Axis: SplitAxisX, Pos: y},
-		Children: [2]*DisplayNode{d, newChild}}
+	return &DisplayNode{SplitLine: SplitLine{Axis: SplitAxisY, Pos: y},
+		Children: []*DisplayNode{d, newChild}}
+}
+
+// insertChild adds a child to an interior node at index i, giving it
+// room by spacing all of the node's children evenly.
+func (d *DisplayNode) insertChild(i int, child *DisplayNode) {
+	d.Children = slices.Insert(d.Children, i, child)
+	d.MoreSplits = append(d.MoreSplits, SplitLine{Axis: d.SplitLine.Axis})
+	for j := range len(d.Children) - 1 {
+		d.splitLine(j).Pos = float32(j+1) / float32(len(d.Children))
+	}
+}
+
+// removeChild removes the i-th child of an interior node, along with the
+// split line on one side of it so that a neighbor takes over its space.
+// If only one child remains, the node is replaced with it.
+func (d *DisplayNode) removeChild(i int) {
+	if len(d.Children) == 2 {
+		d.replace(d.Children[1-i])
+		// It's no longer part of a split, so it can't stay collapsed.
+		d.Collapsed, d.RestorePos = false, 0
+		return
+	}
+
+	lines := append([]SplitLine{d.SplitLine}, d.MoreSplits...)
+	lines = slices.Delete(lines, max(i-1, 0), max(i-1, 0)+1)
+	d.SplitLine, d.MoreSplits = lines[0], lines[1:]
+	d.Children = slices.Delete(d.Children, i, i+1)
 }
 
 func splitX(e math.Extent2D, x float32, lineWidth int) (math.Extent2D, math.Extent2D, math.Extent2D) {
@@ -340,44 +787,41 @@ func (d *DisplayNode) FindPaneForMouse(displayExtent math.Extent2D, p [2]float32
 	}
 	if d.SplitLine.Axis == SplitAxisNone {
 		// We've reached a leaf node and found the pane.
+		if d.Collapsed {
+			return d.collapsedBar()
+		}
+		if wm.titleBars {
+			et, rest := splitTitleBar(displayExtent)
+			if et.Inside(p) {
+				return d.titleBar()
+			}
+			displayExtent = rest
+		}
+		if len(d.Tabs) > 0 {
+			if es, _ := splitTabStrip(displayExtent); es.Inside(p) {
+				return d.tabStrip()
+			}
+		}
 		return d.Pane
 	}
 
-	// Compute the extents of the two nodes and the split line.
-	var d0, ds, d1 math.Extent2D
-	if d.SplitLine.Axis == SplitAxisX {
-		d0, ds, d1 = splitX(displayExtent, d.SplitLine.Pos, splitLineWidth(plat))
-
-		// Round the X extents to integer coordinates, to benefit the split
-		// line--since it's relatively small, it's helpful to make it a
-		// larger target.
-		d0.P1[0] = math.Floor(d0.P1[0])
-		ds.P0[0] = math.Floor(ds.P0[0])
-		ds.P1[0] = math.Ceil(ds.P1[0])
-		d1.P0[0] = math.Ceil(d1.P0[0])
-
-	} else {
-		d0, ds, d1 = splitY(displayExtent, d.SplitLine.Pos, splitLineWidth(plat))
-
-		// For a y split, similarly round y bounds up/down to integer
-		// coordinates to give the split line a better chance.
-		d0.P1[1] = math.Floor(d0.P1[1])
-		ds.P0[1] = math.Floor(ds.P0[1])
-		ds.P1[1] = math.Ceil(ds.P1[1])
-		d1.P0[1] = math.Ceil(d1.P0[1])
-	}
+	// Compute the extents of the children and the split lines. splitX()
+	// and splitY() round the split lines' edges outward to integer
+	// coordinates, which makes these relatively small lines larger
+	// targets for the mouse.
+	ce, le := d.splitExtents(displayExtent, splitLineWidth(plat))
 
 	// Now figure out which it is inside.
-	if d0.Inside(p) {
-		return d.Children[0].FindPaneForMouse(d0, p, plat)
-	} else if ds.Inside(p) {
-		return &d.SplitLine
-	} else if d1.Inside(p) {
-		return d.Children[1].FindPaneForMouse(d1, p, plat)
-	} else {
-		panic("Mouse not overlapping anything?")
-		return nil
+	for i, child := range d.Children {
+		if ce[i].Inside(p) {
+			return child.FindPaneForMouse(ce[i], p, plat)
+		}
+		if i < len(le) && le[i].Inside(p) {
+			return d.splitLine(i)
+		}
 	}
+	panic("Mouse not overlapping anything?")
+	return nil
 }
 
 func (d *DisplayNode) String() string {
@@ -389,8 +833,9 @@ func (d *DisplayNode) getString(indent string) string {
 		return ""
 	}
 	s := fmt.Sprintf(indent+"%p split %d pane %p (%T)\n", d, d.SplitLine.Axis, d.Pane, d.Pane)
-	s += d.Children[0].getString(indent + "     ")
-	s += d.Children[1].getString(indent + "     ")
+	for _, child := range d.Children {
+		s += child.getString(indent + "     ")
+	}
 	return s
 }
 
@@ -408,13 +853,53 @@ func wmPaneIsPresent(pane Pane, root *DisplayNode) bool {
 	return found
 }
 
+// visitVisiblePanes is like VisitPanes, though only the active pane of
+// tab groups is visited.
+func (d *DisplayNode) visitVisiblePanes(visit func(Pane)) {
+	if d.SplitLine.Axis == SplitAxisNone {
+		visit(util.Select[Pane](d.Collapsed, d.collapsedBar(), d.Pane))
+	} else {
+		for i, child := range d.Children {
+			if i > 0 {
+				visit(d.splitLine(i - 1))
+			}
+			child.visitVisiblePanes(visit)
+		}
+	}
+}
+
+// wmKeyboardPanes returns the visible panes that can take the keyboard
+// focus: those in the layout rooted at root and, if requested, in the
+// detached windows and overlays stored at fullRoot.
+func wmKeyboardPanes(root, fullRoot *DisplayNode, detached, overlays bool) []Pane {
+	var kp []Pane
+	visit := func(p Pane) {
+		if p.CanTakeKeyboardFocus() {
+			kp = append(kp, p)
+		}
+	}
+	root.visitVisiblePanes(visit)
+	if detached {
+		for _, dp := range fullRoot.Detached {
+			dp.Node.visitVisiblePanes(visit)
+		}
+	}
+	if overlays {
+		for _, op := range fullRoot.Overlays {
+			op.Node.visitVisiblePanes(visit)
+		}
+	}
+	return kp
+}
+
 // DrawPanes is called each time through the main rendering loop; it
 // handles all of the details of drawing the Panes in the display
 // hierarchy, making sure they don't inadvertently draw over other panes,
 // and providing mouse and keyboard events only to the Pane that should
 // respectively be receiving them.
 func DrawPanes(root *DisplayNode, p platform.Platform, r renderer.Renderer, controlClient *sim.ControlClient,
-	menuBarHeight float32, audioEnabled *bool, lg *log.Logger) renderer.RendererStats {
+	menuBarHeight float32, audioEnabled *bool, compact bool, maximizeKey string, lockLayout bool,
+	titleBars bool, degraded bool, lg *log.Logger) renderer.RendererStats {
 	if controlClient == nil {
 		commandBuffer := renderer.GetCommandBuffer()
 		defer renderer.ReturnCommandBuffer(commandBuffer)
@@ -424,7 +909,9 @@ func DrawPanes(root *DisplayNode, p platform.Platform, r renderer.Renderer, cont
 
 	var filter func(d *DisplayNode) *DisplayNode
 	filter = func(d *DisplayNode) *DisplayNode {
-		if d.Children[0].Pane != nil && d.Children[0].Pane.Hide() {
+		if d.SplitLine.Axis == SplitAxisNone || len(d.Children) > 2 {
+			return d
+		} else if d.Children[0].Pane != nil && d.Children[0].Pane.Hide() {
 			return filter(d.Children[1])
 		} else if d.Children[1].Pane != nil && d.Children[1].Pane.Hide() {
 			return filter(d.Children[0])
@@ -432,23 +919,45 @@ func DrawPanes(root *DisplayNode, p platform.Platform, r renderer.Renderer, cont
 			return d
 		}
 	}
+	// Detached panes are stored at the root.
+	fullRoot := root
 	root = filter(root)
-
-	getKeyboardPanes := func() []Pane {
-		var kp []Pane
-		root.VisitPanes(func(p Pane) {
-			if p.CanTakeKeyboardFocus() {
-				kp = append(kp, p)
+	wm.titleBars = titleBars && !compact
+
+	if compact {
+		// Show just the one selected pane, using the full window.
+		if wm.compactPane == nil || !wmPaneIsPresent(wm.compactPane, root) {
+			wm.compactPane = wm.focus.Current()
+			if wm.compactPane == nil || !wmPaneIsPresent(wm.compactPane, root) {
+				wm.compactPane = nil
+				root.VisitPanes(func(p Pane) {
+					if wm.compactPane == nil && p.CanTakeKeyboardFocus() {
+						wm.compactPane = p
+					}
+				})
 			}
-		})
-		return kp
+			if wm.compactPane == nil {
+				// None of them can take the focus; show the first one.
+				root.VisitPanes(func(p Pane) {
+					if wm.compactPane == nil {
+						wm.compactPane = p
+					}
+				})
+			}
+		}
+		root = &DisplayNode{Pane: wm.compactPane}
+	} else {
+		wmCheckMaximizeShortcut(root, p, menuBarHeight, maximizeKey)
+		if wm.maximizedPane != nil {
+			root = &DisplayNode{Pane: wm.maximizedPane}
+		}
 	}
+	showOverlays := !compact && wm.maximizedPane == nil
 
-	if wm.focus.Current() == nil || !wmPaneIsPresent(wm.focus.Current(), root) {
-		kp := getKeyboardPanes()
-		focus := kp[0]
-		wm.focus = WMKeyboardFocus{initial: focus, current: focus}
+	getKeyboardPanes := func() []Pane {
+		return wmKeyboardPanes(root, fullRoot, !compact, showOverlays)
 	}
+	wm.focus.Update(getKeyboardPanes())
 
 	// Useful values related to the display size.
 	fbSize := p.FramebufferSize()
@@ -461,11 +970,24 @@ func DrawPanes(root *DisplayNode, p platform.Platform, r renderer.Renderer, cont
 	// our window coordinates.
 	mousePos := [2]float32{imgui.MousePos().X, displaySize[1] - 1 - imgui.MousePos().Y}
 
-	// Figure out which Pane the mouse is in.
-	mousePane := root.FindPaneForMouse(paneDisplayExtent, mousePos, p)
+	// Figure out which Pane the mouse is in; overlays are drawn on top of
+	// the layout, so they're checked first.
+	var mouseOverlay Pane
+	if showOverlays {
+		mouseOverlay = wmFindOverlayForMouse(fullRoot, paneDisplayExtent, mousePos)
+	}
+	mousePane := mouseOverlay
+	if mousePane == nil {
+		mousePane = root.FindPaneForMouse(paneDisplayExtent, mousePos, p)
+	}
 
 	io := imgui.CurrentIO()
 
+	if !compact && !lockLayout && wm.maximizedPane == nil && wm.paneDrag == nil && !io.WantCaptureMouse() &&
+		wmStartPaneDrag(mousePane, mousePos) {
+		wm.mouseConsumerOverride = nil
+	}
+
 	// If the user has clicked or is dragging in a Pane, record it in
 	// mouseConsumerOverride so that we can continue to dispatch mouse
 	// events to that Pane until the mouse button is released, even if the
@@ -476,8 +998,12 @@ func DrawPanes(root *DisplayNode, p platform.Platform, r renderer.Renderer, cont
 	isClicked := imgui.IsMouseClicked(platform.MouseButtonPrimary) ||
 		imgui.IsMouseClicked(platform.MouseButtonSecondary) ||
 		imgui.IsMouseClicked(platform.MouseButtonTertiary)
-	if !io.WantCaptureMouse() && (isDragging || isClicked) && wm.mouseConsumerOverride == nil {
+	if !io.WantCaptureMouse() && (isDragging || isClicked) && wm.mouseConsumerOverride == nil && wm.paneDrag == nil {
 		wm.mouseConsumerOverride = mousePane
+		if _, ok := mousePane.(*SplitLine); ok && !lockLayout && imgui.IsMouseClicked(platform.MouseButtonSecondary) {
+			// Resizing can be undone.
+			wm.history.push(fullRoot)
+		}
 	} else if io.WantCaptureMouse() {
 		// However, clear the mouse override if imgui wants mouse events
 		wm.mouseConsumerOverride = nil
@@ -502,65 +1028,126 @@ func DrawPanes(root *DisplayNode, p platform.Platform, r renderer.Renderer, cont
 	}
 
 	if keyboard != nil && keyboard.WasPressed(platform.KeyTab) {
+		// Tab and control-tab cycle forward through the panes;
+		// control-shift-tab cycles backward.
+		step := 1
+		if keyboard.WasPressed(platform.KeyControl) && keyboard.WasPressed(platform.KeyShift) {
+			step = -1
+		}
 		cur := wm.focus.Current()
 		kp := getKeyboardPanes()
-		if idx := slices.Index(kp, cur); idx == -1 {
+		if len(kp) == 0 {
+			// Nothing can take the focus.
+		} else if idx := slices.Index(kp, cur); idx == -1 {
 			panic("Current focus pane not found in keyboard panes?")
 		} else {
-			next := kp[(idx+1)%len(kp)]
+			next := kp[(idx+step+len(kp))%len(kp)]
 			wm.focus.Take(next)
 		}
 	}
+	if keyboard != nil && keyboard.WasPressed(platform.KeyControl) {
+		// Control-arrow moves the focus to the adjacent pane in the
+		// arrow's direction.
+		for key, dir := range map[platform.Key][2]float32{
+			platform.KeyLeftArrow:  {-1, 0},
+			platform.KeyRightArrow: {1, 0},
+			platform.KeyUpArrow:    {0, 1},
+			platform.KeyDownArrow:  {0, -1},
+		} {
+			if keyboard.WasPressed(key) {
+				if next := wmFindPaneInDirection(root, paneDisplayExtent, p, getKeyboardPanes(), dir); next != nil {
+					wm.focus.Take(next)
+				}
+				// Don't pass the arrow on to the panes.
+				delete(keyboard.Pressed, key)
+			}
+		}
+	}
 
 	// Actually visit the panes.
-	root.VisitPanesWithBounds(paneDisplayExtent, paneDisplayExtent, p,
-		func(paneExtent math.Extent2D, parentExtent math.Extent2D, pane Pane) {
-			haveFocus := pane == wm.focus.Current() && !imgui.CurrentIO().WantCaptureKeyboard()
-			ctx := Context{
-				PaneExtent:       paneExtent,
-				ParentPaneExtent: parentExtent,
-				Platform:         p,
-				DrawPixelScale:   util.Select(runtime.GOOS == "windows", p.DPIScale(), float32(1)),
-				PixelsPerInch:    util.Select(runtime.GOOS == "windows", 96*p.DPIScale(), 72),
-				DPIScale:         p.DPIScale(),
-				Renderer:         r,
-				Keyboard:         keyboard,
-				HaveFocus:        haveFocus,
-				Now:              time.Now(),
-				Lg:               lg,
-				MenuBarHeight:    menuBarHeight,
-				AudioEnabled:     audioEnabled,
-				KeyboardFocus:    &wm.focus,
-				ControlClient:    controlClient,
-			}
+	clear(wm.paneExtents)
+	if wm.paneExtents == nil {
+		wm.paneExtents = make(map[Pane]math.Extent2D)
+	}
+	clear(wm.splitSizes)
+	if wm.splitSizes == nil {
+		wm.splitSizes = make(map[*SplitLine]float32)
+	}
+	drawPane := func(paneExtent math.Extent2D, parentExtent math.Extent2D, pane Pane) {
+		wm.paneExtents[pane] = paneExtent
+		if s, ok := pane.(*SplitLine); ok {
+			wm.splitSizes[s] = util.Select(s.Axis == SplitAxisX, parentExtent.Width(), parentExtent.Height())
+		}
+		haveFocus := pane == wm.focus.Current() && !imgui.CurrentIO().WantCaptureKeyboard()
+		scale := paneScale(pane)
+		ctx := Context{
+			PaneExtent:       scaledExtent(paneExtent, scale),
+			ParentPaneExtent: parentExtent,
+			Platform:         p,
+			DrawPixelScale:   util.Select(runtime.GOOS == "windows", p.DPIScale(), float32(1)),
+			PixelsPerInch:    util.Select(runtime.GOOS == "windows", 96*p.DPIScale(), 72),
+			DPIScale:         p.DPIScale(),
+			Scale:            scale,
+			Renderer:         r,
+			Keyboard:         keyboard,
+			HaveFocus:        haveFocus,
+			Now:              time.Now(),
+			Lg:               lg,
+			MenuBarHeight:    menuBarHeight,
+			AudioEnabled:     audioEnabled,
+			Compact:          compact,
+			LayoutLocked:     lockLayout,
+			Degraded:         degraded,
+			KeyboardFocus:    &wm.focus,
+			ControlClient:    controlClient,
+		}
 
-			// Similarly make the mouse events available only to the
-			// one Pane that should see them.
-			ownsMouse := wm.mouseConsumerOverride == pane ||
-				(wm.mouseConsumerOverride == nil &&
-					!io.WantCaptureMouse() &&
-					paneExtent.Inside(mousePos))
-			if ownsMouse {
-				// Full display size, including the menu and status bar.
-				displayTrueFull := math.Extent2D{P0: [2]float32{0, 0}, P1: [2]float32{displaySize[0], displaySize[1]}}
-				ctx.InitializeMouse(displayTrueFull, p)
-			}
+		// Similarly make the mouse events available only to the
+		// one Pane that should see them; panes underneath an
+		// overlay don't get them.
+		ownsMouse := wm.paneDrag == nil && (wm.mouseConsumerOverride == pane ||
+			(wm.mouseConsumerOverride == nil &&
+				!io.WantCaptureMouse() &&
+				paneExtent.Inside(mousePos) &&
+				(mouseOverlay == nil || mouseOverlay == pane)))
+		if ownsMouse {
+			// Full display size, including the menu bar.
+			displayTrueFull := math.Extent2D{P0: [2]float32{0, 0}, P1: [2]float32{displaySize[0], displaySize[1]}}
+			ctx.InitializeMouse(displayTrueFull, p)
+		}
 
-			// Specify the scissor rectangle and viewport that
-			// correspond to the pixels that the Pane covers. In this
-			// way, not only can the Pane be implemented in terms of
-			// Pane coordinates, independent of where it is actually
-			// placed in the overall window, but this also ensures that
-			// the Pane can't inadvertently draw over other Panes.
-			commandBuffer.SetDrawBounds(paneExtent, p.FramebufferSize()[1]/p.DisplaySize()[1])
+		// Specify the scissor rectangle and viewport that
+		// correspond to the pixels that the Pane covers. In this
+		// way, not only can the Pane be implemented in terms of
+		// Pane coordinates, independent of where it is actually
+		// placed in the overall window, but this also ensures that
+		// the Pane can't inadvertently draw over other Panes.
+		commandBuffer.SetDrawBounds(paneExtent, p.FramebufferSize()[1]/p.DisplaySize()[1])
 
-			// Let the Pane do its thing
-			pane.Draw(&ctx, commandBuffer)
+		// Let the Pane do its thing
+		pane.Draw(&ctx, commandBuffer)
 
-			// And reset the graphics state to the standard baseline,
-			// so no state changes leak and affect subsequent drawing.
-			commandBuffer.ResetState()
-		})
+		// And reset the graphics state to the standard baseline,
+		// so no state changes leak and affect subsequent drawing.
+		commandBuffer.ResetState()
+	}
+	root.VisitPanesWithBounds(paneDisplayExtent, paneDisplayExtent, p, drawPane)
+	if showOverlays {
+		// Draw the overlays after the layout so that they're on top.
+		for _, op := range fullRoot.Overlays {
+			eb, ep := splitTitleBar(op.extent(paneDisplayExtent))
+			drawPane(eb, paneDisplayExtent, op.overlayBar())
+			drawPane(ep, paneDisplayExtent, op.Node.Pane)
+		}
+	}
+
+	if wm.paneDrag != nil {
+		ctx := Context{PaneExtent: paneDisplayExtent, Platform: p, DPIScale: p.DPIScale()}
+		wmUpdatePaneDrag(fullRoot, mousePane, mousePos, &ctx, commandBuffer)
+	}
+	wmRestoreClickedBar(fullRoot)
+	wmApplyTitleBarRequest(fullRoot)
+	wmApplyOverlayRequest(fullRoot)
 
 	// Clear mouseConsumerOverride if the user has stopped dragging;
 	// only do this after visiting the Panes so that the override Pane
@@ -569,6 +1156,10 @@ func DrawPanes(root *DisplayNode, p platform.Platform, r renderer.Renderer, cont
 		wm.mouseConsumerOverride = nil
 	}
 
+	if !compact {
+		drawDetachedPanes(fullRoot, p, r, controlClient, keyboard, audioEnabled, degraded, lg)
+	}
+
 	// fbSize will be (0,0) if the window is minimized, in which case we
 	// can skip rendering. It's still important to do all of the pane
 	// traversal, etc., though, so that events are still consumed and
@@ -579,19 +1170,117 @@ func DrawPanes(root *DisplayNode, p platform.Platform, r renderer.Renderer, cont
 	return renderer.RendererStats{}
 }
 
+// wmCheckMaximizeShortcut handles control and the given key, which toggles
+// showing a single pane using the entire window. The pane under the mouse
+// is maximized or, if the mouse isn't over one, the most recently
+// maximized pane; with shift, the pane with the keyboard focus is
+// maximized.
+func wmCheckMaximizeShortcut(root *DisplayNode, p platform.Platform, menuBarHeight float32, key string) {
+	if wm.maximizedPane != nil && !wmPaneIsPresent(wm.maximizedPane, root) {
+		wm.maximizedPane = nil
+	}
+
+	io := imgui.CurrentIO()
+	if key == "" || io.WantCaptureKeyboard() || !io.KeyCtrlPressed() || !imgui.IsKeyPressed(int(key[0])) {
+		return
+	}
+
+	if wm.maximizedPane != nil {
+		wm.lastMaximizedPane, wm.maximizedPane = wm.maximizedPane, nil
+		return
+	}
+
+	var pane Pane
+	if io.KeyShiftPressed() {
+		pane = wm.focus.Current()
+	} else {
+		displaySize := p.DisplaySize()
+		extent := math.Extent2D{P1: [2]float32{displaySize[0], displaySize[1] - menuBarHeight}}
+		mousePos := [2]float32{imgui.MousePos().X, displaySize[1] - 1 - imgui.MousePos().Y}
+		if mp := root.FindPaneForMouse(extent, mousePos, p); mp != nil {
+			if tb, ok := mp.(*TitleBar); ok {
+				pane = tb.node.Pane
+			} else if _, ok := mp.(*SplitLine); !ok {
+				pane = mp
+			}
+		}
+		if pane == nil && wm.lastMaximizedPane != nil && wmPaneIsPresent(wm.lastMaximizedPane, root) {
+			pane = wm.lastMaximizedPane
+		}
+		if pane == nil {
+			pane = wm.focus.Current()
+		}
+	}
+
+	wmMaximizePane(pane, root)
+}
+
+// wmMaximizePane shows the given pane using the entire window, giving it
+// the keyboard focus if it can take it. Panes that can't, such as the
+// flight strips, may be maximized as well; the focus then stays with a
+// detached pane, if there is one, or is cleared.
+func wmMaximizePane(pane Pane, root *DisplayNode) {
+	if pane != nil && wmPaneIsPresent(pane, root) {
+		wm.maximizedPane = pane
+		if pane.CanTakeKeyboardFocus() {
+			wm.focus.Take(pane)
+		}
+	}
+}
+
+// wmFindPaneInDirection returns the pane among candidates that is nearest
+// to the pane with the keyboard focus in the given direction, or nil if
+// there is none.
+func wmFindPaneInDirection(root *DisplayNode, displayExtent math.Extent2D, p platform.Platform,
+	candidates []Pane, dir [2]float32) Pane {
+	centers := make(map[Pane][2]float32)
+	root.VisitPanesWithBounds(displayExtent, displayExtent, p,
+		func(paneExtent math.Extent2D, parentExtent math.Extent2D, pane Pane) {
+			centers[pane] = paneExtent.Center()
+		})
+
+	c, ok := centers[wm.focus.Current()]
+	if !ok {
+		// The focused pane is in a detached window.
+		return nil
+	}
+
+	var best Pane
+	bestScore := float32(0)
+	perp := [2]float32{-dir[1], dir[0]}
+	for _, pane := range candidates {
+		pc, ok := centers[pane]
+		if !ok || pane == wm.focus.Current() {
+			continue
+		}
+		v := math.Sub2f(pc, c)
+		along := math.Dot(v, dir)
+		if along <= 0 {
+			continue
+		}
+		// Prefer panes that are more directly in line with the current
+		// one over ones that are closer but off to the side.
+		score := along + 2*math.Abs(math.Dot(v, perp))
+		if best == nil || score < bestScore {
+			best, bestScore = pane, score
+		}
+	}
+	return best
+}
+
 func NewDisplayPanes(stars, messages, fsp Pane) *DisplayNode {
 	return &DisplayNode{
 		SplitLine: SplitLine{
 			Pos:  0.8,
 			Axis: SplitAxisX,
 		},
-		Children: [2]*DisplayNode{
+		Children: []*DisplayNode{
 			&DisplayNode{
 				SplitLine: SplitLine{
 					Pos:  0.075,
 					Axis: SplitAxisY,
 				},
-				Children: [2]*DisplayNode{
+				Children: []*DisplayNode{
 					&DisplayNode{Pane: messages},
 					&DisplayNode{Pane: stars},
 				},
@@ -611,14 +1300,14 @@ func Activate(root *DisplayNode, r renderer.Renderer, p platform.Platform, event
 	})
 	if !haveMessages {
 		root := root
-		if root.SplitLine.Axis == SplitAxisX && root.Children[0] != nil {
+		if root.SplitLine.Axis == SplitAxisX && len(root.Children) > 0 {
 			messages := NewMessagesPane()
 			root.Children[0] = &DisplayNode{
 				SplitLine: SplitLine{
 					Pos:  0.075,
 					Axis: SplitAxisY,
 				},
-				Children: [2]*DisplayNode{
+				Children: []*DisplayNode{
 					&DisplayNode{Pane: messages},
 					&DisplayNode{Pane: root.Children[0].Pane},
 				},
@@ -633,6 +1322,21 @@ func Activate(root *DisplayNode, r renderer.Renderer, p platform.Platform, event
 	})
 }
 
+// Deactivate deactivates all of the panes in the display hierarchy and
+// closes the windows of any detached panes; it is called when the
+// hierarchy is being replaced with another.
+func Deactivate(root *DisplayNode) {
+	root.VisitPanes(func(pane Pane) {
+		pane.Deactivate()
+	})
+	for _, dp := range root.Detached {
+		if dp.window != nil {
+			dp.window.Destroy()
+			dp.window = nil
+		}
+	}
+}
+
 func LoadedSim(root *DisplayNode, state sim.State, pl platform.Platform, lg *log.Logger) {
 	root.VisitPanes(func(p Pane) {
 		p.LoadedSim(state, pl, lg)
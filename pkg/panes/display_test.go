@@ -0,0 +1,170 @@
+// pkg/panes/display_test.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package panes
+
+import (
+	"testing"
+
+	"github.com/mmp/vice/pkg/sim"
+)
+
+func TestKeyboardFocusUpdate(t *testing.T) {
+	a, b := NewMessagesPane(), NewMessagesPane()
+
+	// Focus held by a pane that is no longer present is cleared if
+	// nothing else can take it.
+	f := WMKeyboardFocus{initial: a, current: a}
+	f.Update(nil)
+	if f.Current() != nil {
+		t.Errorf("expected no focus, got %v", f.Current())
+	}
+	f.Release()
+	if f.Current() != nil {
+		t.Errorf("expected no focus after release, got %v", f.Current())
+	}
+
+	// Once there is a pane that can take it, the first one gets the
+	// focus, and then keeps it.
+	f.Update([]Pane{b, a})
+	if f.Current() != b {
+		t.Errorf("expected the first pane to have the focus, got %v", f.Current())
+	}
+	f.Update([]Pane{a, b})
+	if f.Current() != b {
+		t.Errorf("focus moved to %v", f.Current())
+	}
+}
+
+// unfocusableLayout sets up a layout with the flight strips, which can't
+// take the keyboard focus, next to a messages pane that has it. wm is
+// restored when the test finishes.
+func unfocusableLayout(t *testing.T) (root *DisplayNode, strips, messages Pane) {
+	saved := wm
+	t.Cleanup(func() { wm = saved })
+
+	mp := NewMessagesPane()
+	mp.events = sim.NewEventStream(nil).Subscribe()
+	strips, messages = NewFlightStripPane(), mp
+	root = &DisplayNode{
+		SplitLine: SplitLine{Pos: 0.5, Axis: SplitAxisY},
+		Children:  []*DisplayNode{{Pane: strips}, {Pane: messages}},
+	}
+	wm.focus = WMKeyboardFocus{initial: messages, current: messages}
+	return
+}
+
+// TestUnfocusablePaneOperations checks that window management operations
+// that can leave only panes that can't take the keyboard focus visible
+// leave the focus in a consistent state.
+func TestUnfocusablePaneOperations(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		op   func(t *testing.T, root *DisplayNode, strips, messages Pane)
+		// keepsFocus is true if the messages pane should still have the
+		// focus afterward; otherwise nothing should have it.
+		keepsFocus bool
+	}{
+		{
+			name: "maximize",
+			op: func(t *testing.T, root *DisplayNode, strips, messages Pane) {
+				wmMaximizePane(strips, root)
+				if wm.maximizedPane != strips {
+					t.Errorf("flight strips weren't maximized")
+				}
+			},
+		},
+		{
+			name: "maximize with a detached pane",
+			op: func(t *testing.T, root *DisplayNode, strips, messages Pane) {
+				root.Children[1] = &DisplayNode{Pane: NewEmptyPane()}
+				root.Detached = []*DetachedPane{{Node: &DisplayNode{Pane: messages}}}
+				wmMaximizePane(strips, root)
+			},
+			keepsFocus: true,
+		},
+		{
+			name: "close",
+			op: func(t *testing.T, root *DisplayNode, strips, messages Pane) {
+				wm.titleBarRequest = &titleBarRequest{pane: messages, action: titleBarClose}
+				wmApplyTitleBarRequest(root)
+				if root.Pane != strips {
+					t.Errorf("expected only the flight strips to remain")
+				}
+			},
+		},
+		{
+			name: "collapse",
+			op: func(t *testing.T, root *DisplayNode, strips, messages Pane) {
+				if root.CollapsePane(messages) {
+					t.Errorf("collapsed the only pane that can take the keyboard focus")
+				}
+				if !root.CollapsePane(strips) {
+					t.Errorf("unable to collapse the flight strips")
+				}
+			},
+			keepsFocus: true,
+		},
+		{
+			name: "ShowPane",
+			op: func(t *testing.T, root *DisplayNode, strips, messages Pane) {
+				ShowPane(root, strips, true)
+				if wm.maximizedPane != strips {
+					t.Errorf("flight strips weren't maximized")
+				}
+				if wm.focus.Current() != messages {
+					t.Errorf("focus changed to a pane that can't take it: %v", wm.focus.Current())
+				}
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			root, strips, messages := unfocusableLayout(t)
+			test.op(t, root, strips, messages)
+
+			// As in DrawPanes: a maximized pane is shown by itself along
+			// with the detached panes; overlays are only shown otherwise.
+			visible := root
+			if wm.maximizedPane != nil {
+				visible = &DisplayNode{Pane: wm.maximizedPane}
+			}
+			wm.focus.Update(wmKeyboardPanes(visible, root, true, wm.maximizedPane == nil))
+
+			if test.keepsFocus && wm.focus.Current() != messages {
+				t.Errorf("expected the messages pane to have the focus, got %v", wm.focus.Current())
+			} else if !test.keepsFocus && wm.focus.Current() != nil {
+				t.Errorf("expected no focus, got %v", wm.focus.Current())
+			}
+		})
+	}
+}
+
+func TestMovePaneClearsNodeState(t *testing.T) {
+	a, b, c := NewEmptyPane(), NewEmptyPane(), NewFlightStripPane()
+	root := &DisplayNode{
+		SplitLine:  SplitLine{Pos: 0.5, Axis: SplitAxisX},
+		Children:   []*DisplayNode{{Pane: a}, {Pane: b}, {Pane: c}},
+		MoreSplits: []SplitLine{{Pos: 0.75, Axis: SplitAxisX}},
+	}
+	// Give the target's node title bar and collapsed bar state.
+	target := root.NodeForPane(b)
+	target.titleBar()
+	target.collapsedBar()
+
+	root.movePane(c, b, paneDropTop)
+
+	node := root.NodeForPane(b)
+	if node == nil {
+		t.Fatalf("target pane not found after move")
+	}
+	if node.title != nil && node.title.node != node {
+		t.Errorf("title bar refers to a different node")
+	}
+	if node.bar != nil && node.bar.node != node {
+		t.Errorf("collapsed bar refers to a different node")
+	}
+	if root.NodeForPane(c) == nil {
+		t.Errorf("moved pane not found")
+	}
+}
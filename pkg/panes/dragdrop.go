@@ -0,0 +1,267 @@
+// pkg/panes/dragdrop.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package panes
+
+import (
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/platform"
+	"github.com/mmp/vice/pkg/renderer"
+	"github.com/mmp/vice/pkg/util"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// Panes can be rearranged by ctrl-dragging from near one of a pane's
+// edges; the pane is then swapped with the pane it's dropped on, moved
+// next to it if dropped near one of its edges, or added to its tab group
+// if dropped on a tab strip. Alt-dragging from anywhere in a pane is a
+// shortcut for swapping it with another pane.
+
+// paneDragEdgeWidth is the width in pixels of the region along a pane's
+// edges where a ctrl-drag starts moving the pane.
+const paneDragEdgeWidth = 12
+
+type paneDropType int
+
+const (
+	paneDropNone paneDropType = iota
+	paneDropSwap
+	paneDropTab
+	paneDropLeft
+	paneDropRight
+	paneDropBottom
+	paneDropTop
+)
+
+type paneDrag struct {
+	pane Pane
+	// swap is set for alt-drags, which only swap panes.
+	swap bool
+}
+
+// paneGhostScale is the size of the preview of a pane being alt-dragged,
+// relative to the pane's size.
+const paneGhostScale = 0.25
+
+// paneDropTarget returns the pane under the mouse that a dragged pane
+// would be dropped on, how it would be dropped, and the extent to
+// highlight.
+func paneDropTarget(mousePane Pane, mousePos [2]float32) (Pane, paneDropType, math.Extent2D) {
+	e, ok := wm.paneExtents[mousePane]
+	if !ok || mousePane == wm.paneDrag.pane {
+		return nil, paneDropNone, e
+	}
+
+	switch p := mousePane.(type) {
+	case *SplitLine:
+		return nil, paneDropNone, e
+	case *TabStrip:
+		return p.node.Pane, util.Select(wm.paneDrag.swap, paneDropSwap, paneDropTab), e
+	case *TitleBar:
+		if p.node.Pane == wm.paneDrag.pane {
+			return nil, paneDropNone, e
+		}
+		return p.node.Pane, util.Select(wm.paneDrag.swap, paneDropSwap, paneDropTab), e
+	}
+
+	if wm.paneDrag.swap {
+		return mousePane, paneDropSwap, e
+	}
+
+	// Position of the mouse in [0,1]^2 within the pane
+	u := (mousePos[0] - e.P0[0]) / e.Width()
+	v := (mousePos[1] - e.P0[1]) / e.Height()
+	const edge = 0.25
+	switch {
+	case u < edge:
+		e.P1[0] = (e.P0[0] + e.P1[0]) / 2
+		return mousePane, paneDropLeft, e
+	case u > 1-edge:
+		e.P0[0] = (e.P0[0] + e.P1[0]) / 2
+		return mousePane, paneDropRight, e
+	case v < edge:
+		e.P1[1] = (e.P0[1] + e.P1[1]) / 2
+		return mousePane, paneDropBottom, e
+	case v > 1-edge:
+		e.P0[1] = (e.P0[1] + e.P1[1]) / 2
+		return mousePane, paneDropTop, e
+	default:
+		return mousePane, paneDropSwap, e
+	}
+}
+
+// wmStartPaneDrag starts dragging a pane if the user has ctrl-clicked near
+// the edge of one or alt-clicked anywhere in one.
+func wmStartPaneDrag(mousePane Pane, mousePos [2]float32) bool {
+	io := imgui.CurrentIO()
+	if mousePane == nil || !(io.KeyCtrlPressed() || io.KeyAltPressed()) ||
+		!imgui.IsMouseClicked(platform.MouseButtonPrimary) {
+		return false
+	}
+	swap := !io.KeyCtrlPressed()
+	switch p := mousePane.(type) {
+	case *SplitLine, *TabStrip, *CollapsedBar, *overlayBar:
+		return false
+	case *TitleBar:
+		// Panes can be dragged by their title bars.
+		wm.paneDrag = &paneDrag{pane: p.node.Pane, swap: swap}
+		return true
+	}
+
+	if swap {
+		wm.paneDrag = &paneDrag{pane: mousePane, swap: true}
+		return true
+	}
+
+	e, ok := wm.paneExtents[mousePane]
+	if !ok {
+		return false
+	}
+	inner := e.Expand(-paneDragEdgeWidth)
+	if inner.Inside(mousePos) {
+		return false
+	}
+
+	wm.paneDrag = &paneDrag{pane: mousePane}
+	return true
+}
+
+// wmUpdatePaneDrag highlights the drop target for a pane that is being
+// dragged and rearranges the display hierarchy when it is dropped.
+func wmUpdatePaneDrag(root *DisplayNode, mousePane Pane, mousePos [2]float32, ctx *Context,
+	cb *renderer.CommandBuffer) {
+	imgui.SetMouseCursor(imgui.MouseCursorHand)
+	target, drop, e := paneDropTarget(mousePane, mousePos)
+
+	if !imgui.IsMouseDown(platform.MouseButtonPrimary) {
+		if drop != paneDropNone {
+			wm.history.push(root)
+			root.movePane(wm.paneDrag.pane, target, drop)
+		}
+		wm.paneDrag = nil
+		return
+	}
+
+	ld := renderer.GetColoredLinesDrawBuilder()
+	defer renderer.ReturnColoredLinesDrawBuilder(ld)
+
+	outline := func(e math.Extent2D, color renderer.RGB) {
+		e = e.Expand(-2)
+		ld.AddLineLoop(color, [][2]float32{e.P0, {e.P1[0], e.P0[1]}, e.P1, {e.P0[0], e.P1[1]}})
+	}
+	if se, ok := wm.paneExtents[wm.paneDrag.pane]; ok {
+		outline(se, UICautionColor)
+	}
+	if drop != paneDropNone {
+		outline(e, UITextHighlightColor)
+	}
+
+	cb.SetDrawBounds(ctx.PaneExtent, ctx.Platform.FramebufferSize()[1]/ctx.Platform.DisplaySize()[1])
+	ctx.SetWindowCoordinateMatrices(cb)
+	cb.LineWidth(3, ctx.DPIScale)
+	ld.GenerateCommands(cb)
+	if wm.paneDrag.swap {
+		drawPaneGhost(wm.paneDrag.pane, mousePos, cb)
+	}
+	cb.ResetState()
+}
+
+// drawPaneGhost draws a translucent, scaled-down stand-in for the pane
+// being alt-dragged, centered at the mouse position.
+func drawPaneGhost(pane Pane, mousePos [2]float32, cb *renderer.CommandBuffer) {
+	se, ok := wm.paneExtents[pane]
+	if !ok {
+		return
+	}
+	font := renderer.GetDefaultFont()
+	name := PaneName(pane)
+	bx, by := font.BoundText(name, 0)
+	w := math.Max(paneGhostScale*se.Width(), float32(bx+2*tabPadding))
+	h := math.Max(paneGhostScale*se.Height(), float32(2*by))
+	p0 := [2]float32{mousePos[0] - w/2, mousePos[1] - h/2}
+	p1 := [2]float32{mousePos[0] + w/2, mousePos[1] + h/2}
+
+	trid := renderer.GetTrianglesDrawBuilder()
+	defer renderer.ReturnTrianglesDrawBuilder(trid)
+	trid.AddQuad(p0, [2]float32{p1[0], p0[1]}, p1, [2]float32{p0[0], p1[1]})
+
+	td := renderer.GetTextDrawBuilder()
+	defer renderer.ReturnTextDrawBuilder(td)
+	td.AddText(name, [2]float32{mousePos[0] - float32(bx)/2, mousePos[1] + float32(by)/2},
+		renderer.TextStyle{Font: font, Color: UITextHighlightColor})
+
+	c := UIControlColor
+	cb.SetRGBA(renderer.RGBA{R: c.R, G: c.G, B: c.B, A: 0.6})
+	cb.Blend()
+	trid.GenerateCommands(cb)
+	cb.DisableBlend()
+	td.GenerateCommands(cb)
+}
+
+// movePane moves pane to the location described by drop with respect to
+// target.
+func (d *DisplayNode) movePane(pane Pane, target Pane, drop paneDropType) {
+	src, dst := d.NodeForPane(pane), d.NodeForPane(target)
+	if src == nil || dst == nil || src == dst {
+		return
+	}
+
+	if drop == paneDropSwap {
+		src.setActivePane(target)
+		dst.setActivePane(pane)
+		return
+	}
+
+	// Otherwise the pane is first removed from where it is now.
+	if !d.removePane(pane) {
+		return
+	}
+
+	// Removing the pane may have moved the target's node.
+	if dst = d.NodeForPane(target); dst == nil {
+		return
+	}
+
+	if drop == paneDropTab {
+		dst.AddTab(pane)
+		return
+	}
+
+	moved := &DisplayNode{Pane: pane}
+
+	// If the target's node is already split along the same axis, the pane
+	// is added to it as another child, with the children spaced evenly.
+	horizontal := drop == paneDropLeft || drop == paneDropRight
+	axis := SplitType(util.Select(horizontal, SplitAxisX, SplitAxisY))
+	if parent, idx := d.ParentNodeForPane(target); parent != nil && parent.SplitLine.Axis == axis {
+		before := drop == paneDropLeft || drop == paneDropBottom
+		parent.insertChild(util.Select(before, idx, idx+1), moved)
+		return
+	}
+
+	old := *dst
+	old.strip, old.bar, old.title, old.Detached, old.Overlays = nil, nil, nil, nil, nil
+	var split *DisplayNode
+	switch drop {
+	case paneDropLeft:
+		split = moved.SplitX(0.5, &old)
+	case paneDropRight:
+		split = old.SplitX(0.5, moved)
+	case paneDropBottom:
+		split = moved.SplitY(0.5, &old)
+	case paneDropTop:
+		split = old.SplitY(0.5, moved)
+	}
+	dst.replace(split)
+}
+
+// setActivePane replaces the node's pane, or the active one in its tab
+// group, with the given one.
+func (d *DisplayNode) setActivePane(pane Pane) {
+	if len(d.Tabs) > 0 {
+		d.Tabs[d.ActiveTab] = pane
+	}
+	d.Pane = pane
+}
@@ -5,7 +5,6 @@
 package panes
 
 import (
-	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -24,6 +23,8 @@ import (
 )
 
 type FlightStripPane struct {
+	PaneScale
+
 	FontSize int
 	font     *renderer.Font
 
@@ -61,11 +62,7 @@ type FlightStripPane struct {
 }
 
 func init() {
-	RegisterUnmarshalPane("FlightStripPane", func(d []byte) (Pane, error) {
-		var p FlightStripPane
-		err := json.Unmarshal(d, &p)
-		return &p, err
-	})
+	RegisterPaneType("FlightStripPane", func() Pane { return &FlightStripPane{} })
 }
 
 func NewFlightStripPane() *FlightStripPane {
@@ -112,6 +109,11 @@ func (fsp *FlightStripPane) Activate(r renderer.Renderer, p platform.Platform, e
 	fsp.events = eventStream.Subscribe()
 }
 
+func (fsp *FlightStripPane) Deactivate() {
+	fsp.events.Unsubscribe()
+	fsp.events = nil
+}
+
 func (fsp *FlightStripPane) getCID(callsign string) int {
 	if id, ok := fsp.CIDs[callsign]; ok {
 		return id
@@ -433,7 +435,7 @@ func (fsp *FlightStripPane) Draw(ctx *Context, cb *renderer.CommandBuffer) {
 
 		// First column; 3 entries: callsign, aircraft type, 3-digit id number
 		cid := fmt.Sprintf("%03d", fsp.getCID(callsign))
-		drawColumn(callsign, ac.CWT()+"/"+fp.BaseType(), cid, width0, false)
+		drawColumn(callsign, ctx.ControlClient.WakeCategory(ac)+"/"+fp.BaseType(), cid, width0, false)
 
 		x += width0
 		if ctx.ControlClient.State.IsDeparture(ac) {
@@ -442,10 +444,11 @@ func (fsp *FlightStripPane) Draw(ctx *Context, cb *renderer.CommandBuffer) {
 			drawColumn(fp.AssignedSquawk.String(), proposedTime, strconv.Itoa(fp.Altitude/100),
 				width1, true)
 
-			// Third column: departure airport, (empty), (empty)
+			// Third column: departure airport, release status, (empty)
 			x += width1
 			// Departures
-			drawColumn(fp.DepartureAirport, "", "", width2, false)
+			rel := ac.ReleaseStatus(ctx.ControlClient.CurrentTime())
+			drawColumn(fp.DepartureAirport, util.Select(rel != "", "REL "+rel, ""), "", width2, false)
 
 			x += width2
 			// Fourth column: route and destination airport
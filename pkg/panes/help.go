@@ -0,0 +1,133 @@
+// pkg/panes/help.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package panes
+
+import (
+	_ "embed"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// HelpTopic describes a command or setting; it is shown in a popup when
+// the user presses F1. Text may include the markup understood by the
+// keyboard command reference: _italic_ and *fixed-width*.
+type HelpTopic struct {
+	Title       string
+	Syntax      string
+	Description string
+	// CLI gives the equivalent command as entered with a callsign in the
+	// messages pane.
+	CLI string
+	// Adaptation describes scenario and facility adaptation options
+	// related to the command.
+	Adaptation string
+}
+
+type helpCommand struct {
+	// The command is a candidate if the input starts with Prefix; if
+	// Match is given, it must also match the input.
+	Prefix      string `json:"prefix"`
+	Match       string `json:"match"`
+	Syntax      string `json:"syntax"`
+	Description string `json:"description"`
+	CLI         string `json:"cli"`
+	Adaptation  string `json:"adaptation"`
+
+	re *regexp.Regexp
+}
+
+type helpSetting struct {
+	Description string `json:"description"`
+	CLI         string `json:"cli"`
+}
+
+//go:embed help.json
+var helpJSON []byte
+
+var help struct {
+	once     sync.Once
+	Commands []helpCommand          `json:"commands"`
+	Settings map[string]helpSetting `json:"settings"`
+
+	// Topic that has been requested but not yet shown.
+	pending *HelpTopic
+}
+
+func loadHelp() {
+	help.once.Do(func() {
+		if err := json.Unmarshal(helpJSON, &help); err != nil {
+			panic("help.json: " + err.Error())
+		}
+		for i := range help.Commands {
+			if help.Commands[i].Match != "" {
+				help.Commands[i].re = regexp.MustCompile(help.Commands[i].Match)
+			}
+		}
+	})
+}
+
+// CommandHelp returns help for the aircraft control command being entered
+// in input, which may be preceded by a callsign and other commands; the
+// last one is the one described.
+func CommandHelp(input string) (HelpTopic, bool) {
+	loadHelp()
+
+	fields := strings.Fields(strings.ToUpper(input))
+	if len(fields) == 0 {
+		return HelpTopic{}, false
+	}
+	cmd := fields[len(fields)-1]
+
+	topic := func(c helpCommand) HelpTopic {
+		return HelpTopic{
+			Title:       c.Syntax,
+			Syntax:      c.Syntax,
+			Description: c.Description,
+			CLI:         c.CLI,
+			Adaptation:  c.Adaptation,
+		}
+	}
+
+	for _, c := range help.Commands {
+		if strings.HasPrefix(cmd, c.Prefix) && (c.re == nil || c.re.MatchString(cmd)) {
+			return topic(c), true
+		}
+	}
+	// Fall back to just the prefix for partially-entered commands.
+	for _, c := range help.Commands {
+		if strings.HasPrefix(cmd, c.Prefix) {
+			return topic(c), true
+		}
+	}
+	return HelpTopic{}, false
+}
+
+// SettingHelp returns help for the setting with the given label.
+func SettingHelp(name string) (HelpTopic, bool) {
+	loadHelp()
+
+	if s, ok := help.Settings[name]; ok {
+		return HelpTopic{Title: name, Description: s.Description, CLI: s.CLI}, true
+	}
+	return HelpTopic{}, false
+}
+
+// RequestHelp asks for the given topic to be shown to the user.
+func RequestHelp(t HelpTopic) {
+	help.pending = &t
+}
+
+// PendingHelp returns the most recently requested help topic, if any,
+// and clears the request.
+func PendingHelp() (HelpTopic, bool) {
+	if help.pending == nil {
+		return HelpTopic{}, false
+	}
+	t := *help.pending
+	help.pending = nil
+	return t, true
+}
@@ -5,7 +5,7 @@
 package panes
 
 import (
-	"encoding/json"
+	"fmt"
 	"log/slog"
 	"slices"
 	"strings"
@@ -16,6 +16,7 @@ import (
 	"github.com/mmp/vice/pkg/platform"
 	"github.com/mmp/vice/pkg/renderer"
 	"github.com/mmp/vice/pkg/sim"
+	"github.com/mmp/vice/pkg/util"
 
 	"github.com/mmp/imgui-go/v4"
 )
@@ -25,6 +26,8 @@ type Message struct {
 	system   bool
 	error    bool
 	global   bool
+	guard    bool
+	landline bool
 }
 
 type CLIInput struct {
@@ -33,8 +36,20 @@ type CLIInput struct {
 }
 
 type MessagesPane struct {
+	PaneScale
+
 	KeepFocusAfterTrackSlew bool
 
+	// While the push-to-talk key (F1-F16 for values 1-16, or disabled
+	// if 0) is held, audio from the microphone is recorded. When it is
+	// released, the audio is transcribed by the speech recognition
+	// server at SpeechRecognitionURL and the transcript is handled as a
+	// spoken transmission.
+	PushToTalkKey        int
+	SpeechRecognitionURL string
+	transmitting         bool
+	transcripts          chan speechTranscript
+
 	FontIdentifier renderer.FontIdentifier
 	font           *renderer.Font
 	scrollbar      *ScrollBar
@@ -49,11 +64,7 @@ type MessagesPane struct {
 }
 
 func init() {
-	RegisterUnmarshalPane("MessagesPane", func(d []byte) (Pane, error) {
-		var p MessagesPane
-		err := json.Unmarshal(d, &p)
-		return &p, err
-	})
+	RegisterPaneType("MessagesPane", func() Pane { return &MessagesPane{} })
 }
 
 func NewMessagesPane() *MessagesPane {
@@ -75,6 +86,12 @@ func (mp *MessagesPane) Activate(r renderer.Renderer, p platform.Platform, event
 		mp.scrollbar = NewVerticalScrollBar(4, true)
 	}
 	mp.events = eventStream.Subscribe()
+	mp.transcripts = make(chan speechTranscript, 4)
+}
+
+func (mp *MessagesPane) Deactivate() {
+	mp.events.Unsubscribe()
+	mp.events = nil
 }
 
 func (mp *MessagesPane) LoadedSim(ss sim.State, pl platform.Platform, lg *log.Logger) {}
@@ -90,10 +107,26 @@ func (mp *MessagesPane) DrawUI(p platform.Platform, config *platform.Config) {
 		mp.font = newFont
 	}
 	imgui.Checkbox("Keep focus after slewing track for control command", &mp.KeepFocusAfterTrackSlew)
+
+	imgui.SetNextItemWidth(100)
+	if imgui.BeginCombo("Push-to-talk key", util.Select(mp.PushToTalkKey == 0, "Off",
+		fmt.Sprintf("F%d", mp.PushToTalkKey))) {
+		for k := 0; k <= 16; k++ {
+			if imgui.SelectableV(util.Select(k == 0, "Off", fmt.Sprintf("F%d", k)), mp.PushToTalkKey == k,
+				0, imgui.Vec2{}) {
+				mp.PushToTalkKey = k
+			}
+		}
+		imgui.EndCombo()
+	}
+	if mp.PushToTalkKey != 0 {
+		imgui.InputTextV("Speech recognition server URL", &mp.SpeechRecognitionURL, 0, nil)
+	}
 }
 
 func (mp *MessagesPane) Draw(ctx *Context, cb *renderer.CommandBuffer) {
 	mp.processEvents(ctx)
+	mp.processPushToTalk(ctx)
 
 	if ctx.Mouse != nil && ctx.Mouse.Clicked[platform.MouseButtonPrimary] {
 		ctx.KeyboardFocus.Take(mp)
@@ -124,7 +157,7 @@ func (mp *MessagesPane) Draw(ctx *Context, cb *renderer.CommandBuffer) {
 		Color: renderer.RGB{1, 1, .2}, DrawBackground: true, BackgroundColor: renderer.RGB{1, 1, 1}}
 	ci := mp.input
 
-	prompt := "> "
+	prompt := util.Select(mp.transmitting, "TX> ", "> ")
 	if !ctx.HaveFocus {
 		// Don't draw the cursor if we don't have keyboard focus
 		td.AddText(prompt+ci.cmd, [2]float32{indent, y}, cliStyle)
@@ -171,8 +204,8 @@ func (mp *MessagesPane) processKeyboard(ctx *Context) {
 		return
 	}
 
-	// Grab keyboard input
-	if len(mp.input.cmd) > 0 && mp.input.cmd[0] == '/' {
+	// Grab keyboard input; chat and guard messages aren't upper-cased.
+	if len(mp.input.cmd) > 0 && (mp.input.cmd[0] == '/' || mp.input.cmd[0] == '!') {
 		mp.input.InsertAtCursor(ctx.Keyboard.Input)
 	} else {
 		mp.input.InsertAtCursor(strings.ToUpper(ctx.Keyboard.Input))
@@ -236,6 +269,12 @@ func (mp *MessagesPane) processKeyboard(ctx *Context) {
 		}
 	}
 
+	if ctx.Keyboard.WasPressed(platform.KeyF1) {
+		if t, ok := CommandHelp(mp.input.cmd); ok {
+			RequestHelp(t)
+		}
+	}
+
 	if ctx.Keyboard.WasPressed(platform.KeyEnter) && strings.TrimSpace(mp.input.cmd) != "" {
 		mp.runCommands(ctx)
 	}
@@ -245,6 +284,10 @@ func (msg *Message) Color() renderer.RGB {
 	switch {
 	case msg.error:
 		return renderer.RGB{.9, .1, .1}
+	case msg.guard:
+		return renderer.RGB{1, .5, .1}
+	case msg.landline:
+		return renderer.RGB{.3, .75, 1}
 	case msg.global, msg.system:
 		return renderer.RGB{0.012, 0.78, 0.016}
 	default:
@@ -255,6 +298,19 @@ func (msg *Message) Color() renderer.RGB {
 func (mp *MessagesPane) runCommands(ctx *Context) {
 	mp.input.cmd = strings.TrimSpace(mp.input.cmd)
 
+	if msg, ok := strings.CutPrefix(mp.input.cmd, "!"); ok {
+		// Transmit on guard; the message is shown when it comes back
+		// as an event, if guard is being monitored.
+		ctx.ControlClient.SendGlobalMessage(sim.GlobalMessage{
+			FromController: ctx.ControlClient.Callsign,
+			Message:        ctx.ControlClient.Callsign + ": " + strings.TrimSpace(msg),
+			Guard:          true,
+		})
+		mp.history = append(mp.history, mp.input)
+		mp.input = CLIInput{}
+		return
+	}
+
 	if mp.input.cmd[0] == '/' {
 		ctx.ControlClient.SendGlobalMessage(sim.GlobalMessage{
 			FromController: ctx.ControlClient.Callsign,
@@ -266,6 +322,16 @@ func (mp *MessagesPane) runCommands(ctx *Context) {
 		return
 	}
 
+	if transcript, ok := strings.CutPrefix(mp.input.cmd, "\""); ok {
+		// A spoken transmission, as entered by a speech recognizer or
+		// dictation software: convert the phraseology to commands.
+		mp.messages = append(mp.messages, Message{contents: "> " + mp.input.cmd})
+		mp.history = append(mp.history, mp.input)
+		mp.input = CLIInput{}
+		mp.runSpokenTransmission(ctx, transcript)
+		return
+	}
+
 	if mp.input.cmd == "P" {
 		ctx.ControlClient.ToggleSimPause()
 		mp.history = append(mp.history, mp.input)
@@ -298,6 +364,65 @@ func (mp *MessagesPane) runCommands(ctx *Context) {
 	}
 }
 
+func (mp *MessagesPane) processPushToTalk(ctx *Context) {
+	select {
+	case t := <-mp.transcripts:
+		if t.err != nil {
+			mp.messages = append(mp.messages, Message{contents: "Speech recognition: " + t.err.Error(), error: true})
+		} else if t.text != "" {
+			mp.messages = append(mp.messages, Message{contents: "> \"" + t.text})
+			mp.runSpokenTransmission(ctx, t.text)
+		}
+	default:
+	}
+
+	held := mp.PushToTalkKey != 0 && ctx.Keyboard != nil &&
+		ctx.Keyboard.IsFKeyHeld(platform.Key(int(platform.KeyF1)+mp.PushToTalkKey-1))
+	if held && !mp.transmitting {
+		mp.transmitting = true
+		if err := ctx.Platform.StartAudioCapture(); err != nil {
+			mp.messages = append(mp.messages, Message{contents: "Microphone: " + err.Error(), error: true})
+		}
+	} else if !held && mp.transmitting {
+		mp.transmitting = false
+		pcm := ctx.Platform.StopAudioCapture()
+		if len(pcm) < platform.AudioSampleRate/5 {
+			// Less than a tenth of a second; the key was just tapped.
+			return
+		}
+
+		url, ch := mp.SpeechRecognitionURL, mp.transcripts
+		go func() {
+			text, err := transcribeSpeech(speechClient, url, encodeWAV(pcm, platform.AudioSampleRate))
+			ch <- speechTranscript{text: text, err: err}
+		}()
+	}
+}
+
+func (mp *MessagesPane) runSpokenTransmission(ctx *Context, transcript string) {
+	callsigns, commands, err := av.ParseSpokenTransmission(transcript, av.DB.Callsigns)
+	if err != nil {
+		mp.messages = append(mp.messages, Message{contents: err.Error(), error: true})
+		return
+	}
+
+	ac, err := ctx.ControlClient.AircraftFromSpokenCallsign(callsigns)
+	if err != nil {
+		mp.messages = append(mp.messages,
+			Message{contents: strings.Join(callsigns, "/") + ": " + err.Error(), error: true})
+		return
+	}
+
+	cmd := strings.Join(commands, " ")
+	mp.messages = append(mp.messages, Message{contents: ac.Callsign + " " + cmd, system: true})
+	ctx.ControlClient.RunAircraftCommands(ac.Callsign, cmd,
+		func(errorString string, remainingCommands string) {
+			if errorString != "" {
+				mp.messages = append(mp.messages, Message{contents: errorString, error: true})
+			}
+		})
+}
+
 func (ci *CLIInput) InsertAtCursor(s string) {
 	if len(s) == 0 {
 		return
@@ -323,7 +448,7 @@ func (ci *CLIInput) DeleteAfterCursor() {
 }
 
 func (mp *MessagesPane) processEvents(ctx *Context) {
-	lastRadioCallsign := ""
+	lastRadioCallsign, lastRadioController := "", ""
 	var lastRadioType av.RadioTransmissionType
 	var unexpectedTransmission bool
 	var transmissions []string
@@ -341,9 +466,9 @@ func (mp *MessagesPane) processEvents(ctx *Context) {
 				radioCallsign = telephony + " " + flight
 				if ac := ctx.ControlClient.Aircraft[callsign]; ac != nil {
 					if fp := ac.FlightPlan; fp != nil {
-						if strings.HasPrefix(fp.AircraftType, "H/") {
+						if actype := fp.TypeWithoutSuffix(); strings.HasPrefix(actype, "H/") {
 							radioCallsign += " heavy"
-						} else if strings.HasPrefix(fp.AircraftType, "J/") || strings.HasPrefix(fp.AircraftType, "S/") {
+						} else if strings.HasPrefix(actype, "J/") || strings.HasPrefix(actype, "S/") {
 							radioCallsign += " super"
 						}
 					}
@@ -353,8 +478,8 @@ func (mp *MessagesPane) processEvents(ctx *Context) {
 
 		response := strings.Join(transmissions, ", ")
 		var msg Message
-		if lastRadioType == av.RadioTransmissionContact {
-			ctrl := ctx.ControlClient.Controllers[ctx.ControlClient.Callsign]
+		ctrl, _ := ctx.ControlClient.ControlPosition(lastRadioController)
+		if lastRadioType == av.RadioTransmissionContact && ctrl != nil {
 			fullName := ctrl.FullName
 			if ac := ctx.ControlClient.Aircraft[callsign]; ac != nil && ctx.ControlClient.State.IsDeparture(ac) {
 				// Always refer to the controller as "departure" for departing aircraft.
@@ -367,6 +492,15 @@ func (mp *MessagesPane) processEvents(ctx *Context) {
 			}
 			msg = Message{contents: response + ". " + radioCallsign, error: unexpectedTransmission}
 		}
+		if radio := &ctx.ControlClient.Radio; ctrl != nil && ctrl.Frequency != radio.Primed {
+			// Note which frequency it came in on if it wasn't the primed
+			// one, and whether it was retransmitted via cross-coupling.
+			if radio.CoupledWithPrimed(lastRadioController) {
+				msg.contents = "[" + ctrl.Frequency.String() + " XC] " + msg.contents
+			} else {
+				msg.contents = "[" + ctrl.Frequency.String() + "] " + msg.contents
+			}
+		}
 		ctx.Lg.Debug("radio_transmission", slog.String("callsign", callsign), slog.Any("message", msg))
 		mp.messages = append(mp.messages, msg)
 	}
@@ -374,14 +508,16 @@ func (mp *MessagesPane) processEvents(ctx *Context) {
 	for _, event := range mp.events.Get() {
 		switch event.Type {
 		case sim.RadioTransmissionEvent:
-			if event.ToController == ctx.ControlClient.Callsign {
-				if event.Callsign != lastRadioCallsign || event.RadioTransmissionType != lastRadioType {
+			if ctx.ControlClient.Radio.Receives(event.ToController) {
+				if event.Callsign != lastRadioCallsign || event.RadioTransmissionType != lastRadioType ||
+					event.ToController != lastRadioController {
 					if len(transmissions) > 0 {
 						addTransmissions()
 						transmissions = nil
 						unexpectedTransmission = false
 					}
 					lastRadioCallsign = event.Callsign
+					lastRadioController = event.ToController
 					lastRadioType = event.RadioTransmissionType
 				}
 				transmissions = append(transmissions, event.Message)
@@ -391,7 +527,25 @@ func (mp *MessagesPane) processEvents(ctx *Context) {
 			if event.FromController != ctx.ControlClient.Callsign {
 				mp.messages = append(mp.messages, Message{contents: event.Message, global: true})
 			}
+		case sim.GuardMessageEvent:
+			if ctx.ControlClient.Radio.MonitorGuard {
+				mp.messages = append(mp.messages, Message{
+					contents: "[" + sim.GuardFrequency.String() + " GUARD] " + event.Message,
+					guard:    true,
+				})
+			}
+		case sim.LandlineMessageEvent:
+			if slices.Contains(ctx.ControlClient.ConsolidatedPositions(), event.ToController) {
+				mp.messages = append(mp.messages, Message{
+					contents: "[" + event.Landline.Abbreviation() + "] " + event.Message,
+					landline: true,
+				})
+			}
 		case sim.StatusMessageEvent:
+			if event.ToController != "" && event.ToController != ctx.ControlClient.Callsign {
+				// Directed to another controller.
+				break
+			}
 			// Don't spam the same message repeatedly; look in the most recent 5.
 			n := len(mp.messages)
 			start := math.Max(0, n-5)
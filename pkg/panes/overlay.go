@@ -0,0 +1,229 @@
+// pkg/panes/overlay.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package panes
+
+import (
+	"slices"
+
+	"github.com/mmp/imgui-go/v4"
+	"github.com/mmp/vice/pkg/log"
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/platform"
+	"github.com/mmp/vice/pkg/renderer"
+	"github.com/mmp/vice/pkg/sim"
+	"github.com/mmp/vice/pkg/util"
+)
+
+// Panes can also be shown as overlays: small panes, e.g. for timers or
+// information about the selected aircraft, that are drawn on top of the
+// main window's layout rather than taking up a slot in it. Like detached
+// panes, overlays are stored in the root DisplayNode. Each one has a bar
+// along its top that is used to move it, resize it, and return it to the
+// layout.
+
+const (
+	overlayMinW = 100
+	overlayMinH = 60
+)
+
+// OverlayPane is a pane that is drawn above the main window's layout.
+type OverlayPane struct {
+	// Node is a leaf node that holds the pane, as with DetachedPane.
+	Node *DisplayNode
+	// Position is the overlay's lower-left corner, with the window's
+	// pane area mapped to [0,1]^2 so that the overlay stays in the same
+	// corner when the window is resized.
+	Position [2]float32
+	// Size is the overlay's size in pixels.
+	Size [2]float32
+
+	bar *overlayBar
+}
+
+// extent returns the overlay's extent in window coordinates, given the
+// extent of the window's pane area; it is kept inside that area.
+func (op *OverlayPane) extent(display math.Extent2D) math.Extent2D {
+	w := math.Clamp(op.Size[0], overlayMinW, math.Max(overlayMinW, display.Width()))
+	h := math.Clamp(op.Size[1], overlayMinH, math.Max(overlayMinH, display.Height()))
+	x := display.P0[0] + math.Clamp(op.Position[0]*display.Width(), 0, math.Max(0, display.Width()-w))
+	y := display.P0[1] + math.Clamp(op.Position[1]*display.Height(), 0, math.Max(0, display.Height()-h))
+	p0 := [2]float32{math.Floor(x), math.Floor(y)}
+	return math.Extent2D{P0: p0, P1: math.Add2f(p0, [2]float32{math.Floor(w), math.Floor(h)})}
+}
+
+func (op *OverlayPane) overlayBar() *overlayBar {
+	if op.bar == nil {
+		op.bar = &overlayBar{overlay: op}
+	}
+	return op.bar
+}
+
+// OverlayPanes returns the panes that are currently shown as overlays.
+func (d *DisplayNode) OverlayPanes() []Pane {
+	return util.MapSlice(d.Overlays, func(op *OverlayPane) Pane { return op.Node.Pane })
+}
+
+// OverlayPane removes the given pane from the layout and shows it as an
+// overlay in the upper right corner of the window. The last pane in the
+// layout can't be made an overlay; false is returned if the pane wasn't
+// moved.
+func (d *DisplayNode) OverlayPane(pane Pane) bool {
+	if _, ok := pane.(*SplitLine); ok {
+		return false
+	}
+	prev := d.clone()
+	if !d.removePane(pane) {
+		return false
+	}
+	wm.history.record(d, prev)
+
+	op := &OverlayPane{Node: &DisplayNode{Pane: pane}, Size: [2]float32{300, 200}, Position: [2]float32{1, 1}}
+	if e, ok := wm.paneExtents[pane]; ok {
+		op.Size = [2]float32{math.Min(e.Width(), op.Size[0]), math.Min(e.Height(), op.Size[1])}
+	}
+	d.Overlays = append(d.Overlays, op)
+	return true
+}
+
+// DockOverlay returns an overlay pane to the layout, along its right
+// side.
+func (d *DisplayNode) DockOverlay(pane Pane) {
+	idx := slices.IndexFunc(d.Overlays, func(op *OverlayPane) bool { return op.Node.Pane == pane })
+	if idx == -1 {
+		return
+	}
+	wm.history.push(d)
+	d.Overlays = slices.Delete(d.Overlays, idx, idx+1)
+
+	old := *d
+	old.Detached, old.Overlays, old.strip, old.title = nil, nil, nil, nil
+	d.replace(&DisplayNode{
+		SplitLine: SplitLine{Axis: SplitAxisX, Pos: 0.8},
+		Children:  []*DisplayNode{&old, &DisplayNode{Pane: pane}},
+	})
+}
+
+// wmFindOverlayForMouse returns the overlay pane or overlay bar that the
+// mouse is over, if any. Overlays are drawn in order, so the last one is
+// on top and is checked first.
+func wmFindOverlayForMouse(root *DisplayNode, displayExtent math.Extent2D, p [2]float32) Pane {
+	for _, op := range slices.Backward(root.Overlays) {
+		e := op.extent(displayExtent)
+		if !e.Inside(p) {
+			continue
+		}
+		if eb, _ := splitTitleBar(e); eb.Inside(p) {
+			return op.overlayBar()
+		}
+		return op.Node.Pane
+	}
+	return nil
+}
+
+// overlayBar is drawn along the top of an overlay; dragging it moves the
+// overlay and it has buttons to resize the overlay and to return it to
+// the layout. Like TitleBar, it implements the Pane interface so that it
+// is handled along with the other panes.
+type overlayBar struct {
+	overlay  *OverlayPane
+	resizing bool
+}
+
+func (*overlayBar) Activate(renderer.Renderer, platform.Platform, *sim.EventStream, *log.Logger) {}
+func (*overlayBar) Deactivate()                                                                  {}
+func (*overlayBar) LoadedSim(sim.State, platform.Platform, *log.Logger)                          {}
+func (*overlayBar) ResetSim(sim.State, platform.Platform, *log.Logger)                           {}
+func (*overlayBar) CanTakeKeyboardFocus() bool                                                   { return false }
+func (*overlayBar) Hide() bool                                                                   { return false }
+
+func (ob *overlayBar) Draw(ctx *Context, cb *renderer.CommandBuffer) {
+	font := renderer.GetDefaultFont()
+	w, h := ctx.PaneExtent.Width(), ctx.PaneExtent.Height()
+	buttonWidth := h
+	op := ob.overlay
+
+	qb := renderer.GetColoredTrianglesDrawBuilder()
+	defer renderer.ReturnColoredTrianglesDrawBuilder(qb)
+	td := renderer.GetTextDrawBuilder()
+	defer renderer.ReturnTextDrawBuilder(td)
+
+	// The buttons, from right to left: the resize grip and, if the
+	// layout isn't locked, the button that docks the overlay.
+	type button struct {
+		icon, tooltip string
+		resize        bool
+	}
+	buttons := []button{{renderer.FontAwesomeIconExpandAlt, "Drag to resize", true}}
+	if !ctx.LayoutLocked {
+		buttons = append(buttons, button{renderer.FontAwesomeIconTimes, "Return to layout", false})
+	}
+
+	m := ctx.Mouse
+	x := w
+	for _, b := range buttons {
+		x -= buttonWidth
+		style := renderer.TextStyle{Font: font, Color: UITextColor}
+		if m != nil && (m.Pos[0] >= x && m.Pos[0] < x+buttonWidth || (b.resize && ob.resizing)) {
+			qb.AddQuad([2]float32{x, 0}, [2]float32{x + buttonWidth, 0}, [2]float32{x + buttonWidth, h},
+				[2]float32{x, h}, UIControlColor)
+			style.Color = UITextHighlightColor
+			if b.resize {
+				m.SetCursor(imgui.MouseCursorResizeNESW)
+			} else {
+				m.SetCursor(imgui.MouseCursorHand)
+			}
+			imgui.SetTooltip(b.tooltip)
+			if m.Clicked[platform.MouseButtonPrimary] {
+				if b.resize {
+					ob.resizing = true
+				} else {
+					wm.dockOverlay = op.Node.Pane
+				}
+			}
+		}
+		bx, _ := font.BoundText(b.icon, 0)
+		td.AddText(b.icon, [2]float32{x + (buttonWidth-float32(bx))/2, h - 3}, style)
+	}
+
+	if m != nil && m.Dragging[platform.MouseButtonPrimary] {
+		if ob.resizing {
+			// The lower-left corner stays put; the grip follows the
+			// mouse.
+			op.Size[0] = math.Max(overlayMinW, op.Size[0]+m.DragDelta[0])
+			op.Size[1] = math.Max(overlayMinH, op.Size[1]+m.DragDelta[1])
+		} else {
+			m.SetCursor(imgui.MouseCursorResizeAll)
+			// Start from the overlay's current extent, which may have
+			// been clamped to the window.
+			pe := ctx.ParentPaneExtent
+			p0 := math.Add2f(math.Sub2f(op.extent(pe).P0, pe.P0), m.DragDelta)
+			op.Position[0] = math.Clamp(p0[0]/pe.Width(), 0, 1)
+			op.Position[1] = math.Clamp(p0[1]/pe.Height(), 0, 1)
+		}
+	}
+	if m == nil || !m.Down[platform.MouseButtonPrimary] {
+		ob.resizing = false
+	}
+
+	style := renderer.TextStyle{Font: font, Color: UITextColor}
+	if op.Node.Pane == ctx.KeyboardFocus.Current() {
+		style.Color = UITextHighlightColor
+	}
+	td.AddText(PaneName(op.Node.Pane), [2]float32{tabPadding, h - 3}, style)
+
+	cb.ClearRGB(UIControlColor.Scale(0.5))
+	ctx.SetWindowCoordinateMatrices(cb)
+	qb.GenerateCommands(cb)
+	td.GenerateCommands(cb)
+}
+
+// wmApplyOverlayRequest returns the overlay whose dock button was
+// clicked, if any, to the layout.
+func wmApplyOverlayRequest(root *DisplayNode) {
+	if pane := wm.dockOverlay; pane != nil {
+		wm.dockOverlay = nil
+		root.DockOverlay(pane)
+	}
+}
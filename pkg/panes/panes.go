@@ -7,6 +7,7 @@ package panes
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
@@ -26,6 +27,11 @@ type Pane interface {
 	// Sim-independent initialization.
 	Activate(r renderer.Renderer, p platform.Platform, eventStream *sim.EventStream, lg *log.Logger)
 
+	// Deactivate is called when the pane is removed from the display,
+	// e.g. when switching to another layout; it should release anything
+	// acquired in Activate.
+	Deactivate()
+
 	// LoadedSim is called when vice is restarted and a Sim is loaded from disk.
 	LoadedSim(ss sim.State, pl platform.Platform, lg *log.Logger)
 
@@ -42,6 +48,55 @@ type UIDrawer interface {
 	DrawUI(p platform.Platform, config *platform.Config)
 }
 
+// PaneName returns the name of the given pane to show to the user.
+func PaneName(pane Pane) string {
+	if d, ok := pane.(UIDrawer); ok {
+		return d.DisplayName()
+	}
+	return fmt.Sprintf("%T", pane)
+}
+
+// Scaler is implemented by panes whose contents can be drawn larger or
+// smaller than the rest of the user interface, e.g. so that flight strips
+// can be easier to read than the scope's lists. The pane is given a
+// PaneExtent whose size is in scaled units; since the viewport still
+// covers the pane's full extent, the projection matrix set by
+// SetWindowCoordinateMatrices then scales everything the pane draws.
+type Scaler interface {
+	UIScale() float32
+	SetUIScale(s float32)
+}
+
+// PaneScale can be embedded in a pane to implement Scaler; the scale is
+// then saved along with the pane's other settings.
+type PaneScale struct {
+	// Scale multiplies the size of the pane's text and graphics; zero is
+	// taken to be one.
+	Scale float32 `json:",omitempty"`
+}
+
+func (s *PaneScale) UIScale() float32 {
+	return util.Select(s.Scale == 0, float32(1), s.Scale)
+}
+
+func (s *PaneScale) SetUIScale(scale float32) {
+	s.Scale = scale
+}
+
+// paneScale returns the scale at which the given pane should be drawn.
+func paneScale(pane Pane) float32 {
+	if s, ok := pane.(Scaler); ok && s.UIScale() > 0 {
+		return s.UIScale()
+	}
+	return 1
+}
+
+// scaledExtent returns the extent to give a pane drawn at the given
+// scale: it has the same origin as e but its size is in scaled units.
+func scaledExtent(e math.Extent2D, scale float32) math.Extent2D {
+	return math.Extent2D{P0: e.P0, P1: math.Add2f(e.P0, math.Scale2f(math.Sub2f(e.P1, e.P0), 1/scale))}
+}
+
 type KeyboardFocus interface {
 	Take(p Pane)
 	TakeTemporary(p Pane)
@@ -74,6 +129,10 @@ type Context struct {
 	// "retina" factor; this is mostly useful for drawing "chunky" 1
 	// pixel-wide lines and the like.
 	DPIScale float32
+	// Scale is the pane's own scale factor if it is a Scaler and one
+	// otherwise; PaneExtent's size and the mouse position are in scaled
+	// units.
+	Scale float32
 
 	Renderer  renderer.Renderer
 	Mouse     *platform.MouseState
@@ -84,6 +143,16 @@ type Context struct {
 
 	MenuBarHeight float32
 	AudioEnabled  *bool
+	// Compact is set when the user has enabled the compact layout for
+	// small screens; panes may use smaller fonts for secondary
+	// information.
+	Compact bool
+	// LayoutLocked is set when the user has locked the layout so that
+	// the panes can't be resized or moved.
+	LayoutLocked bool
+	// Degraded is set when frames are taking too long to render; panes
+	// should skip drawing that isn't essential.
+	Degraded bool
 
 	KeyboardFocus KeyboardFocus
 
@@ -104,6 +173,17 @@ func (ctx *Context) InitializeMouse(fullDisplayExtent math.Extent2D, p platform.
 	// Negate y to go to pane coordinates
 	ctx.Mouse.Wheel[1] *= -1
 	ctx.Mouse.DragDelta[1] *= -1
+
+	ctx.scaleMouse()
+}
+
+// scaleMouse converts the mouse position from window pixels to the
+// pane's scaled units.
+func (ctx *Context) scaleMouse() {
+	if ctx.Scale != 0 && ctx.Scale != 1 {
+		ctx.Mouse.Pos = math.Scale2f(ctx.Mouse.Pos, 1/ctx.Scale)
+		ctx.Mouse.DragDelta = math.Scale2f(ctx.Mouse.DragDelta, 1/ctx.Scale)
+	}
 }
 
 func (ctx *Context) SetWindowCoordinateMatrices(cb *renderer.CommandBuffer) {
@@ -113,25 +193,59 @@ func (ctx *Context) SetWindowCoordinateMatrices(cb *renderer.CommandBuffer) {
 	cb.LoadModelViewMatrix(math.Identity3x3())
 }
 
-var paneUnmarshalRegistry map[string]func([]byte) (Pane, error) = make(map[string]func([]byte) (Pane, error))
+// Pane types must be registered with RegisterPaneType in order to be
+// saved in and restored from the config file; the registered name is
+// stored along with each pane's member variables. paneTypes maps from
+// those names to functions that return new panes of the types, and
+// paneTypeNames maps the other way.
+var (
+	paneTypes     = make(map[string]func() Pane)
+	paneTypeNames = make(map[reflect.Type]string)
+)
 
-func RegisterUnmarshalPane(name string, fn func([]byte) (Pane, error)) {
-	if _, ok := paneUnmarshalRegistry[name]; ok {
+// RegisterPaneType registers a Pane type under the given name; factory
+// should return a new pane of the type that the pane's JSON can be
+// unmarshaled into. It is generally called from an init function in the
+// file that defines the pane.
+func RegisterPaneType(name string, factory func() Pane) {
+	if _, ok := paneTypes[name]; ok {
 		panic(name + " registered multiple times")
 	}
-	paneUnmarshalRegistry[name] = fn
+	paneTypes[name] = factory
+	paneTypeNames[reflect.TypeOf(factory())] = name
+}
+
+// paneTypeName returns the name that the pane's type was registered with.
+// Unregistered types get their Go type name, which can't be unmarshaled
+// but is at least informative in error messages.
+func paneTypeName(pane Pane) string {
+	if name, ok := paneTypeNames[reflect.TypeOf(pane)]; ok {
+		return name
+	}
+	return fmt.Sprintf("%T", pane)
 }
 
 func UnmarshalPane(paneType string, data []byte) (Pane, error) {
 	if paneType == "" {
 		return nil, nil
-	} else if _, name, ok := strings.Cut(paneType, "."); ok { // e.g. "*panes.MessagesPane"
-		if fn, ok := paneUnmarshalRegistry[name]; ok {
-			return fn(data)
+	}
+
+	factory, ok := paneTypes[paneType]
+	if !ok {
+		// Older config files have Go type names, e.g. "*main.MessagesPane"
+		// or "*panes.MessagesPane"; the part after the package name is the
+		// registered name.
+		if idx := strings.LastIndex(paneType, "."); idx != -1 {
+			factory, ok = paneTypes[paneType[idx+1:]]
 		}
 	}
-	fmt.Printf("reg %+v\n\n", paneUnmarshalRegistry)
-	return NewEmptyPane(), fmt.Errorf("%s: Unhandled type in config file", paneType)
+	if !ok {
+		return NewEmptyPane(), fmt.Errorf("%s: Unhandled type in config file", paneType)
+	}
+
+	pane := factory()
+	err := json.Unmarshal(data, pane)
+	return pane, err
 }
 
 ///////////////////////////////////////////////////////////////////////////
@@ -146,14 +260,11 @@ type EmptyPane struct {
 func NewEmptyPane() *EmptyPane { return &EmptyPane{} }
 
 func init() {
-	RegisterUnmarshalPane("EmptyPane", func(d []byte) (Pane, error) {
-		var p EmptyPane
-		err := json.Unmarshal(d, &p)
-		return &p, err
-	})
+	RegisterPaneType("EmptyPane", func() Pane { return &EmptyPane{} })
 }
 
 func (ep *EmptyPane) Activate(renderer.Renderer, platform.Platform, *sim.EventStream, *log.Logger) {}
+func (ep *EmptyPane) Deactivate()                                                                  {}
 func (ep *EmptyPane) LoadedSim(ss sim.State, pl platform.Platform, lg *log.Logger)                 {}
 func (ep *EmptyPane) ResetSim(ss sim.State, pl platform.Platform, lg *log.Logger)                  {}
 func (ep *EmptyPane) CanTakeKeyboardFocus() bool                                                   { return false }
@@ -0,0 +1,106 @@
+// pkg/panes/speech.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package panes
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrNoSpeechRecognitionURL = errors.New("No speech recognition server URL has been specified")
+
+var speechClient = &http.Client{Timeout: 30 * time.Second}
+
+// speechPrompt is given to the speech recognizer as an example of the
+// sort of speech to expect; it helps it transcribe phraseology and
+// numbers the way that av.ParseSpokenTransmission expects.
+const speechPrompt = "American one two three, turn left heading two seven zero, descend and maintain " +
+	"four thousand, reduce speed to one eight zero, cleared ILS runway two two left approach."
+
+type speechTranscript struct {
+	text string
+	err  error
+}
+
+// encodeWAV returns a WAV file holding the given one channel, 16-bit
+// little-endian PCM audio.
+func encodeWAV(pcm []byte, rate int) []byte {
+	var b bytes.Buffer
+	w := func(v any) { binary.Write(&b, binary.LittleEndian, v) }
+
+	b.WriteString("RIFF")
+	w(uint32(36 + len(pcm)))
+	b.WriteString("WAVE")
+
+	b.WriteString("fmt ")
+	w(uint32(16))       // chunk size
+	w(uint16(1))        // PCM
+	w(uint16(1))        // channels
+	w(uint32(rate))     // sample rate
+	w(uint32(2 * rate)) // bytes per second
+	w(uint16(2))        // bytes per sample
+	w(uint16(16))       // bits per sample
+
+	b.WriteString("data")
+	w(uint32(len(pcm)))
+	b.Write(pcm)
+
+	return b.Bytes()
+}
+
+// transcribeSpeech sends the WAV file to a speech recognition server that
+// implements the OpenAI audio transcription API (as whisper.cpp's server
+// and faster-whisper-server do) and returns the transcript.
+func transcribeSpeech(client *http.Client, url string, wav []byte) (string, error) {
+	if url == "" {
+		return "", ErrNoSpeechRecognitionURL
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", "transmission.wav")
+	if err != nil {
+		return "", err
+	}
+	fw.Write(wav)
+	mw.WriteField("model", "whisper-1")
+	mw.WriteField("language", "en")
+	mw.WriteField("prompt", speechPrompt)
+	mw.WriteField("response_format", "json")
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: %s", url, resp.Status)
+	}
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(result.Text), nil
+}
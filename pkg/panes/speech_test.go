@@ -0,0 +1,68 @@
+// pkg/panes/speech_test.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package panes
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEncodeWAV(t *testing.T) {
+	pcm := []byte{1, 2, 3, 4, 5, 6}
+	wav := encodeWAV(pcm, 44100)
+
+	if len(wav) != 44+len(pcm) {
+		t.Fatalf("got %d bytes, expected %d", len(wav), 44+len(pcm))
+	}
+	if string(wav[0:4]) != "RIFF" || string(wav[8:16]) != "WAVEfmt " || string(wav[36:40]) != "data" {
+		t.Errorf("malformed header %q", wav[:44])
+	}
+	if n := binary.LittleEndian.Uint32(wav[4:]); n != uint32(len(wav)-8) {
+		t.Errorf("RIFF size %d, expected %d", n, len(wav)-8)
+	}
+	if r := binary.LittleEndian.Uint32(wav[24:]); r != 44100 {
+		t.Errorf("sample rate %d, expected 44100", r)
+	}
+	if n := binary.LittleEndian.Uint32(wav[40:]); n != uint32(len(pcm)) || !bytes.Equal(wav[44:], pcm) {
+		t.Errorf("data chunk doesn't match the PCM")
+	}
+}
+
+func TestTranscribeSpeech(t *testing.T) {
+	wav := encodeWAV([]byte{1, 2, 3, 4}, 44100)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if b, _ := io.ReadAll(f); !bytes.Equal(b, wav) {
+			http.Error(w, "wrong audio", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"text": " American one two three, turn left heading two seven zero. "}`))
+	}))
+	defer srv.Close()
+
+	text, err := transcribeSpeech(srv.Client(), srv.URL, wav)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if text != "American one two three, turn left heading two seven zero." {
+		t.Errorf("got transcript %q", text)
+	}
+
+	if _, err := transcribeSpeech(srv.Client(), srv.URL, encodeWAV(nil, 44100)); err == nil {
+		t.Errorf("expected an error from a failed request")
+	}
+	if _, err := transcribeSpeech(srv.Client(), "", wav); err != ErrNoSpeechRecognitionURL {
+		t.Errorf("expected ErrNoSpeechRecognitionURL, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+// pkg/panes/stars/alerts.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package stars
+
+import (
+	"fmt"
+	"time"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/util"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// AlertResponse records how long the controller took to acknowledge an
+// alert, for training analytics.
+type AlertResponse struct {
+	Kind      string // "CA", "MSAW", "RWY", or the SPC code
+	Callsigns string
+	Start     time.Time
+	// Response is zero if the alert ended without being acknowledged.
+	Response time.Duration
+}
+
+// logAlertResponse records the end of an alert that started at start.
+func (sp *STARSPane) logAlertResponse(kind, callsigns string, start, now time.Time, acknowledged bool) {
+	r := AlertResponse{Kind: kind, Callsigns: callsigns, Start: start}
+	if acknowledged {
+		r.Response = now.Sub(start)
+	}
+	sp.alertResponses = append(sp.alertResponses, r)
+}
+
+// AlertResponses returns the alerts that have ended this session, in the
+// order they ended.
+func (sp *STARSPane) AlertResponses() []AlertResponse {
+	return sp.alertResponses
+}
+
+// alertSounding returns true if audio should be playing for an
+// unacknowledged alert whose initial audio ends at soundEnd; after that,
+// the audio resumes if the alert is ignored for AlertEscalationSeconds.
+func (sp *STARSPane) alertSounding(soundEnd, now time.Time) bool {
+	if now.Before(soundEnd) {
+		return true
+	}
+	if sp.AlertEscalationSeconds == 0 {
+		return false
+	}
+	start := soundEnd.Add(-AlertAudioDuration)
+	return now.Sub(start) > time.Duration(sp.AlertEscalationSeconds)*time.Second
+}
+
+// unacknowledgedAlert returns true if the aircraft is involved in an
+// alert that the controller hasn't acknowledged; its alerts flash until
+// it is.
+func (sp *STARSPane) unacknowledgedAlert(ac *av.Aircraft) bool {
+	state := sp.Aircraft[ac.Callsign]
+	if state.MSAW && !state.MSAWAcknowledged {
+		return true
+	}
+	if ok, _ := av.SquawkIsSPC(ac.Squawk); ok && !state.SPCAcknowledged {
+		return true
+	}
+	for _, ca := range sp.CAAircraft {
+		if (ca.Callsigns[0] == ac.Callsign || ca.Callsigns[1] == ac.Callsign) && !ca.Acknowledged {
+			return true
+		}
+	}
+	if alert, acknowledged := sp.runwayAlertAircraft(ac.Callsign); alert && !acknowledged {
+		return true
+	}
+	return false
+}
+
+func (sp *STARSPane) drawAlertResponsesUI() {
+	esc := int32(sp.AlertEscalationSeconds)
+	imgui.SliderIntV("Repeat unacknowledged alert audio after (seconds)", &esc, 0, 120,
+		util.Select(esc == 0, "Never", "%d"), 0)
+	sp.AlertEscalationSeconds = int(esc)
+
+	if len(sp.alertResponses) == 0 {
+		imgui.Text("No alerts this session.")
+		return
+	}
+
+	var n int
+	var total, longest time.Duration
+	for _, r := range sp.alertResponses {
+		if r.Response > 0 {
+			n++
+			total += r.Response
+			longest = max(longest, r.Response)
+		}
+	}
+	imgui.Text(fmt.Sprintf("%d alerts, %d acknowledged", len(sp.alertResponses), n))
+	if n > 0 {
+		imgui.Text(fmt.Sprintf("Average response %.1fs, longest %.1fs", (total / time.Duration(n)).Seconds(),
+			longest.Seconds()))
+	}
+
+	flags := imgui.TableFlagsBordersV | imgui.TableFlagsBordersOuterH | imgui.TableFlagsRowBg |
+		imgui.TableFlagsSizingFixedFit | imgui.TableFlagsScrollY
+	if imgui.BeginTableV("alertresponses", 4, flags, imgui.Vec2{X: 0, Y: 200}, 0) {
+		imgui.TableSetupColumn("Time")
+		imgui.TableSetupColumn("Alert")
+		imgui.TableSetupColumn("Aircraft")
+		imgui.TableSetupColumn("Response")
+		imgui.TableHeadersRow()
+
+		for i := len(sp.alertResponses) - 1; i >= 0; i-- {
+			r := sp.alertResponses[i]
+			imgui.TableNextRow()
+			imgui.TableNextColumn()
+			imgui.Text(r.Start.Format("15:04:05"))
+			imgui.TableNextColumn()
+			imgui.Text(r.Kind)
+			imgui.TableNextColumn()
+			imgui.Text(r.Callsigns)
+			imgui.TableNextColumn()
+			imgui.Text(util.Select(r.Response > 0, fmt.Sprintf("%.1fs", r.Response.Seconds()), "Not acknowledged"))
+		}
+		imgui.EndTable()
+	}
+}
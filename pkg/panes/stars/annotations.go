@@ -0,0 +1,279 @@
+// pkg/panes/stars/annotations.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package stars
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/panes"
+	"github.com/mmp/vice/pkg/platform"
+	"github.com/mmp/vice/pkg/renderer"
+	"github.com/mmp/vice/pkg/sim"
+	"github.com/mmp/vice/pkg/util"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// Annotations are lines, circles, and text labels that the user draws on
+// the scope, e.g., to mark a temporary flow or a weather deviation route,
+// or when teaching. They're positioned in lat-long so that they stay put
+// as the scope is panned and zoomed and either expire after a while or
+// are kept until they're cleared. If ShareAnnotations is set, they and
+// highlighted locations are also sent to the other controllers in the
+// sim, who see them on their scopes.
+
+var STARSAnnotationColor = renderer.RGB{1, .6, .2}
+
+// annotationTool holds the state of the annotation drawing tool in the
+// settings window.
+type annotationTool struct {
+	tool       sim.AnnotationType
+	text       string
+	ttlMinutes int // zero -> until cleared
+
+	// The annotation being drawn, if any.
+	wip *sim.ScopeAnnotation
+	// Removals of the user's shared annotations made in the settings
+	// window; the other controllers are told at the next draw.
+	pendingRemovals []sim.ScopeAnnotation
+}
+
+func (sp *STARSPane) drawAnnotationsUI() {
+	at := &sp.annotationTool
+	if imgui.BeginCombo("Drawing tool", at.tool.String()) {
+		for _, t := range []sim.AnnotationType{sim.AnnotationNone, sim.AnnotationFreehand, sim.AnnotationLine, sim.AnnotationCircle, sim.AnnotationText} {
+			if imgui.SelectableV(t.String(), t == at.tool, 0, imgui.Vec2{}) {
+				at.tool = t
+				at.wip = nil
+			}
+		}
+		imgui.EndCombo()
+	}
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Freehand: drag with the left mouse button\n" +
+			"Line: click at each end\n" +
+			"Circle: click at the center and then on the circle\n" +
+			"Text: click where the text should go")
+	}
+	if at.tool == sim.AnnotationText {
+		imgui.InputText("Text", &at.text)
+	}
+
+	ttl := int32(at.ttlMinutes)
+	imgui.SliderIntV("Remove after (minutes)", &ttl, 0, 120, util.Select(ttl == 0, "Never", "%d"), 0)
+	at.ttlMinutes = int(ttl)
+
+	imgui.Checkbox("Share with other controllers", &sp.ShareAnnotations)
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("New annotations and highlighted locations are also shown on the other controllers' scopes")
+	}
+
+	if len(sp.Annotations) > 0 {
+		if imgui.Button("Remove last") {
+			sp.removeLastAnnotation()
+		}
+		imgui.SameLine()
+		if imgui.Button("Clear all") {
+			sp.clearAnnotations()
+		}
+		imgui.SameLine()
+		imgui.Text(fmt.Sprintf("%d annotation(s)", len(sp.Annotations)))
+	}
+}
+
+// addAnnotation finishes the annotation being drawn.
+func (sp *STARSPane) addAnnotation(ctx *panes.Context) {
+	at := &sp.annotationTool
+	if at.ttlMinutes > 0 {
+		at.wip.Expires = ctx.Now.Add(time.Duration(at.ttlMinutes) * time.Minute)
+	}
+	if sp.ShareAnnotations {
+		ctx.ControlClient.ShareAnnotation(*at.wip)
+		at.wip.Shared = true
+	}
+	sp.Annotations = append(sp.Annotations, *at.wip)
+	at.wip = nil
+}
+
+// removeLastAnnotation removes the user's most recent annotation, from the
+// other controllers' scopes as well if it was shared.
+func (sp *STARSPane) removeLastAnnotation() {
+	last := sp.Annotations[len(sp.Annotations)-1]
+	sp.Annotations = sp.Annotations[:len(sp.Annotations)-1]
+	if last.Shared {
+		sp.annotationTool.pendingRemovals = append(sp.annotationTool.pendingRemovals,
+			sim.ScopeAnnotation{Type: sim.AnnotationRemoveLast})
+	}
+}
+
+// clearAnnotations removes all of the user's annotations, from the other
+// controllers' scopes as well if any were shared.
+func (sp *STARSPane) clearAnnotations() {
+	if slices.ContainsFunc(sp.Annotations, func(a sim.ScopeAnnotation) bool { return a.Shared }) {
+		sp.annotationTool.pendingRemovals = append(sp.annotationTool.pendingRemovals,
+			sim.ScopeAnnotation{Type: sim.AnnotationNone})
+	}
+	sp.Annotations = nil
+}
+
+// highlightLocation shows a blinking square at the given location for a
+// few seconds.
+func (sp *STARSPane) highlightLocation(ctx *panes.Context, p math.Point2LL) {
+	sp.highlightedLocation = p
+	sp.highlightedLocationEndTime = time.Now().Add(5 * time.Second)
+	if sp.ShareAnnotations {
+		ctx.ControlClient.ShareAnnotation(sim.ScopeAnnotation{
+			Type:    sim.AnnotationHighlight,
+			Points:  []math.Point2LL{p},
+			Expires: sp.highlightedLocationEndTime,
+		})
+	}
+}
+
+// receiveAnnotation handles an annotation shared by another controller.
+func (sp *STARSPane) receiveAnnotation(a sim.ScopeAnnotation) {
+	switch a.Type {
+	case sim.AnnotationNone:
+		sp.receivedAnnotations = util.FilterSlice(sp.receivedAnnotations, func(ra sim.ScopeAnnotation) bool {
+			return ra.From != a.From
+		})
+	case sim.AnnotationRemoveLast:
+		for i := len(sp.receivedAnnotations) - 1; i >= 0; i-- {
+			if sp.receivedAnnotations[i].From == a.From {
+				sp.receivedAnnotations = slices.Delete(sp.receivedAnnotations, i, i+1)
+				break
+			}
+		}
+	case sim.AnnotationHighlight:
+		if len(a.Points) > 0 {
+			sp.highlightedLocation = a.Points[0]
+			sp.highlightedLocationEndTime = time.Now().Add(5 * time.Second)
+		}
+	default:
+		if len(a.Points) > 0 {
+			sp.receivedAnnotations = append(sp.receivedAnnotations, a)
+		}
+	}
+}
+
+// consumeAnnotationMouseEvents handles drawing annotations with the mouse
+// when a drawing tool is selected; the mouse events it uses are not passed
+// on to the scope.
+func (sp *STARSPane) consumeAnnotationMouseEvents(ctx *panes.Context, transforms ScopeTransformations) {
+	at := &sp.annotationTool
+	mouse := ctx.Mouse
+	if at.tool == sim.AnnotationNone || mouse == nil {
+		return
+	}
+	if ctx.Keyboard != nil && ctx.Keyboard.WasPressed(platform.KeyEscape) {
+		at.wip = nil
+	}
+
+	p := transforms.LatLongFromWindowP(mouse.Pos)
+	primary := platform.MouseButtonPrimary
+	switch at.tool {
+	case sim.AnnotationFreehand:
+		if mouse.Clicked[primary] {
+			at.wip = &sim.ScopeAnnotation{Type: sim.AnnotationFreehand, Points: []math.Point2LL{p}}
+		} else if at.wip != nil && mouse.Down[primary] {
+			// Only add a point once the mouse has moved a few pixels.
+			last := transforms.WindowFromLatLongP(at.wip.Points[len(at.wip.Points)-1])
+			if math.Distance2f(last, mouse.Pos) > 3 {
+				at.wip.Points = append(at.wip.Points, p)
+			}
+		} else if at.wip != nil {
+			if len(at.wip.Points) > 1 {
+				sp.addAnnotation(ctx)
+			}
+			at.wip = nil
+		}
+
+	case sim.AnnotationLine, sim.AnnotationCircle:
+		if mouse.Clicked[primary] {
+			if at.wip == nil {
+				at.wip = &sim.ScopeAnnotation{Type: at.tool, Points: []math.Point2LL{p}}
+			} else {
+				if at.tool == sim.AnnotationLine {
+					at.wip.Points = append(at.wip.Points, p)
+				}
+				sp.addAnnotation(ctx)
+			}
+		}
+		if at.wip != nil && at.tool == sim.AnnotationCircle {
+			at.wip.Radius = math.NMDistance2LL(at.wip.Points[0], p)
+		}
+
+	case sim.AnnotationText:
+		if mouse.Clicked[primary] && at.text != "" {
+			at.wip = &sim.ScopeAnnotation{Type: sim.AnnotationText, Points: []math.Point2LL{p}, Text: at.text}
+			sp.addAnnotation(ctx)
+		}
+	}
+
+	mouse.Clicked[primary] = false
+	mouse.Dragging[primary] = false
+}
+
+func (sp *STARSPane) drawAnnotations(ctx *panes.Context, transforms ScopeTransformations, cb *renderer.CommandBuffer) {
+	for _, a := range sp.annotationTool.pendingRemovals {
+		ctx.ControlClient.ShareAnnotation(a)
+	}
+	sp.annotationTool.pendingRemovals = nil
+
+	// Remove expired annotations.
+	unexpired := func(a sim.ScopeAnnotation) bool { return a.Expires.IsZero() || ctx.Now.Before(a.Expires) }
+	sp.Annotations = util.FilterSlice(sp.Annotations, unexpired)
+	sp.receivedAnnotations = util.FilterSlice(sp.receivedAnnotations, unexpired)
+
+	ps := sp.currentPrefs()
+	color := ps.Brightness.Lines.ScaleRGB(STARSAnnotationColor)
+
+	ld := renderer.GetLinesDrawBuilder()
+	defer renderer.ReturnLinesDrawBuilder(ld)
+	td := renderer.GetTextDrawBuilder()
+	defer renderer.ReturnTextDrawBuilder(td)
+	style := renderer.TextStyle{Font: sp.systemFont[ps.CharSize.Tools], Color: color}
+
+	draw := func(a sim.ScopeAnnotation, p1 math.Point2LL) {
+		switch a.Type {
+		case sim.AnnotationFreehand:
+			pts := make([][2]float32, len(a.Points))
+			for i, p := range a.Points {
+				pts[i] = p
+			}
+			ld.AddLineStrip(pts)
+		case sim.AnnotationLine:
+			if len(a.Points) > 1 {
+				p1 = a.Points[1]
+			}
+			ld.AddLine(a.Points[0], p1)
+		case sim.AnnotationCircle:
+			ld.AddLatLongCircle(a.Points[0], ctx.ControlClient.NmPerLongitude, a.Radius, 90)
+		case sim.AnnotationText:
+			td.AddText(a.Text, transforms.WindowFromLatLongP(a.Points[0]), style)
+		}
+	}
+
+	for _, a := range sp.Annotations {
+		draw(a, a.Points[0])
+	}
+	for _, a := range sp.receivedAnnotations {
+		draw(a, a.Points[0])
+	}
+	if wip := sp.annotationTool.wip; wip != nil && ctx.Mouse != nil {
+		// Rubber-band the one being drawn to the mouse position.
+		draw(*wip, transforms.LatLongFromWindowP(ctx.Mouse.Pos))
+	}
+
+	transforms.LoadLatLongViewingMatrices(cb)
+	cb.SetRGB(color)
+	cb.LineWidth(2, ctx.DPIScale)
+	ld.GenerateCommands(cb)
+	transforms.LoadWindowViewingMatrices(cb)
+	td.GenerateCommands(cb)
+}
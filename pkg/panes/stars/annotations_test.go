@@ -0,0 +1,78 @@
+// pkg/panes/stars/annotations_test.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package stars
+
+import (
+	"testing"
+
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/sim"
+)
+
+func TestRemoveAnnotations(t *testing.T) {
+	p := []math.Point2LL{{-75, 40}}
+	sp := &STARSPane{Annotations: []sim.ScopeAnnotation{
+		{Type: sim.AnnotationText, Points: p, Text: "shared", Shared: true},
+		{Type: sim.AnnotationText, Points: p, Text: "local"},
+	}}
+
+	// Removing an annotation that wasn't shared only affects the user's
+	// scope.
+	sp.removeLastAnnotation()
+	if len(sp.Annotations) != 1 || len(sp.annotationTool.pendingRemovals) != 0 {
+		t.Fatalf("removing a local annotation: %d left, %d removals shared", len(sp.Annotations),
+			len(sp.annotationTool.pendingRemovals))
+	}
+
+	// Removing a shared one is shared too.
+	sp.removeLastAnnotation()
+	if len(sp.Annotations) != 0 || len(sp.annotationTool.pendingRemovals) != 1 ||
+		sp.annotationTool.pendingRemovals[0].Type != sim.AnnotationRemoveLast {
+		t.Errorf("expected the removal of a shared annotation to be shared, got %+v",
+			sp.annotationTool.pendingRemovals)
+	}
+
+	sp.annotationTool.pendingRemovals = nil
+	sp.Annotations = []sim.ScopeAnnotation{{Type: sim.AnnotationText, Points: p, Text: "local"}}
+	sp.clearAnnotations()
+	if len(sp.Annotations) != 0 || len(sp.annotationTool.pendingRemovals) != 0 {
+		t.Errorf("clearing local annotations shouldn't be shared")
+	}
+}
+
+func TestReceiveAnnotation(t *testing.T) {
+	p := []math.Point2LL{{-75, 40}}
+	sp := &STARSPane{Annotations: []sim.ScopeAnnotation{{Type: sim.AnnotationText, Points: p, Text: "mine"}}}
+
+	for _, a := range []sim.ScopeAnnotation{
+		{Type: sim.AnnotationText, Points: p, Text: "a1", From: "PHL_A"},
+		{Type: sim.AnnotationText, Points: p, Text: "b1", From: "PHL_B"},
+		{Type: sim.AnnotationText, Points: p, Text: "a2", From: "PHL_A"},
+		{Type: sim.AnnotationText, Points: p, Text: "b2", From: "PHL_B"},
+		{Type: sim.AnnotationRemoveLast, From: "PHL_A"},
+	} {
+		sp.receiveAnnotation(a)
+	}
+
+	// Received annotations are kept separately so that they aren't saved
+	// with the user's.
+	if len(sp.Annotations) != 1 || sp.Annotations[0].Text != "mine" {
+		t.Errorf("user's annotations changed: %+v", sp.Annotations)
+	}
+	texts := func() (s []string) {
+		for _, a := range sp.receivedAnnotations {
+			s = append(s, a.Text)
+		}
+		return
+	}
+	if got := texts(); len(got) != 3 || got[0] != "a1" || got[1] != "b1" || got[2] != "b2" {
+		t.Errorf("after PHL_A removed its last annotation, got %v", got)
+	}
+
+	sp.receiveAnnotation(sim.ScopeAnnotation{Type: sim.AnnotationNone, From: "PHL_B"})
+	if got := texts(); len(got) != 1 || got[0] != "a1" {
+		t.Errorf("after PHL_B cleared its annotations, got %v", got)
+	}
+}
@@ -0,0 +1,375 @@
+// pkg/panes/stars/basemap.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package stars
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	gomath "math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmp/vice/pkg/log"
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/panes"
+	"github.com/mmp/vice/pkg/renderer"
+	"github.com/mmp/vice/pkg/util"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// basemapSource describes a provider of web map tiles in the standard
+// z/x/y "slippy map" layout.
+type basemapSource struct {
+	Name string
+	// URL has {z}, {x}, and {y} replaced with the tile coordinates.
+	URL     string
+	MaxZoom int
+	// Process, if non-nil, is applied to each tile's image after it is
+	// decoded.
+	Process func(img image.Image, key basemapTileKey) image.Image
+}
+
+var basemapSources = []basemapSource{
+	basemapSource{
+		Name:    "Satellite",
+		URL:     "https://server.arcgisonline.com/ArcGIS/rest/services/World_Imagery/MapServer/tile/{z}/{y}/{x}",
+		MaxZoom: 17,
+	},
+	basemapSource{
+		Name:    "Terrain",
+		URL:     "https://tile.opentopomap.org/{z}/{x}/{y}.png",
+		MaxZoom: 15,
+	},
+}
+
+func getBasemapSource(name string) basemapSource {
+	if name == hillshadeSource.Name {
+		return hillshadeSource
+	}
+	for _, src := range basemapSources {
+		if src.Name == name {
+			return src
+		}
+	}
+	return basemapSources[0]
+}
+
+const (
+	// Upper bound on the number of tiles that are drawn at once; the zoom
+	// level is reduced until the visible region is covered by at most
+	// this many.
+	maxVisibleBasemapTiles = 64
+	// Number of tile textures we hold on to before freeing ones that
+	// aren't visible.
+	maxBasemapTextures = 256
+)
+
+type basemapTileKey struct {
+	Source  string
+	Z, X, Y int
+}
+
+// Extent returns the lat-long bounds of the tile.
+func (k basemapTileKey) Extent() math.Extent2D {
+	n := float64(int(1) << k.Z)
+	lon := func(x int) float32 { return float32(float64(x)/n*360 - 180) }
+	lat := func(y int) float32 {
+		return float32(gomath.Atan(gomath.Sinh(gomath.Pi*(1-2*float64(y)/n))) * 180 / gomath.Pi)
+	}
+	return math.Extent2D{P0: [2]float32{lon(k.X), lat(k.Y + 1)}, P1: [2]float32{lon(k.X + 1), lat(k.Y)}}
+}
+
+// tileCoordinates returns the (possibly fractional) tile coordinates of
+// the given point at zoom level z.
+func tileCoordinates(p math.Point2LL, z int) (float64, float64) {
+	n := float64(int(1) << z)
+	lat := float64(math.Clamp(p[1], -85, 85)) * gomath.Pi / 180
+	x := (float64(p[0]) + 180) / 360 * n
+	y := (1 - gomath.Log(gomath.Tan(lat)+1/gomath.Cos(lat))/gomath.Pi) / 2 * n
+	return x, y
+}
+
+type basemapTileResult struct {
+	key basemapTileKey
+	img image.Image
+	err error
+}
+
+type basemapTile struct {
+	texId   uint32 // zero if the fetch failed
+	pending bool
+}
+
+// Basemap manages fetching, caching, and drawing raster map tiles.  As
+// with WeatherRadar, the network requests happen in a separate goroutine;
+// tile requests are sent via reqChan and decoded images are returned via
+// imgChan so that textures can be created on the main thread. Closing
+// done stops the goroutine.
+type Basemap struct {
+	active  bool
+	reqChan chan basemapTileKey
+	imgChan chan basemapTileResult
+	done    chan struct{}
+	tiles   map[basemapTileKey]*basemapTile
+}
+
+func (b *Basemap) Activate(lg *log.Logger) {
+	if b.active {
+		return
+	}
+
+	b.active = true
+	b.reqChan = make(chan basemapTileKey, maxVisibleBasemapTiles)
+	b.imgChan = make(chan basemapTileResult, maxVisibleBasemapTiles)
+	b.done = make(chan struct{})
+	if b.tiles == nil {
+		b.tiles = make(map[basemapTileKey]*basemapTile)
+	} else {
+		// Keep the textures from before we were deactivated, but
+		// requests that were in flight were lost along with the old
+		// channels, so they need to be made again.
+		for key, t := range b.tiles {
+			if t.pending {
+				delete(b.tiles, key)
+			}
+		}
+	}
+
+	go fetchBasemapTiles(b.reqChan, b.imgChan, b.done, lg)
+}
+
+// Deactivate stops the goroutine that fetches tiles.
+func (b *Basemap) Deactivate() {
+	if !b.active {
+		return
+	}
+
+	b.active = false
+	close(b.done)
+	b.reqChan, b.imgChan, b.done = nil, nil, nil
+}
+
+// visibleTiles returns the keys of the tiles that cover the given
+// lat-long extent at a resolution that roughly matches the given number
+// of pixels across it.
+func visibleTiles(src basemapSource, e math.Extent2D, widthPixels float32) []basemapTileKey {
+	if e.Width() <= 0 || widthPixels <= 0 {
+		return nil
+	}
+
+	// Standard tiles are 256 pixels wide.
+	z := int(gomath.Ceil(gomath.Log2(float64(360 * widthPixels / (256 * e.Width())))))
+	z = math.Clamp(z, 1, src.MaxZoom)
+
+	for ; z >= 1; z-- {
+		x0, y0 := tileCoordinates(math.Point2LL{e.P0[0], e.P1[1]}, z)
+		x1, y1 := tileCoordinates(math.Point2LL{e.P1[0], e.P0[1]}, z)
+		n := int(1) << z
+		ix0, iy0 := math.Clamp(int(x0), 0, n-1), math.Clamp(int(y0), 0, n-1)
+		ix1, iy1 := math.Clamp(int(x1), 0, n-1), math.Clamp(int(y1), 0, n-1)
+
+		if (ix1-ix0+1)*(iy1-iy0+1) > maxVisibleBasemapTiles {
+			continue
+		}
+
+		var keys []basemapTileKey
+		for y := iy0; y <= iy1; y++ {
+			for x := ix0; x <= ix1; x++ {
+				keys = append(keys, basemapTileKey{Source: src.Name, Z: z, X: x, Y: y})
+			}
+		}
+		return keys
+	}
+	return nil
+}
+
+func (b *Basemap) Draw(ctx *panes.Context, src basemapSource, brightness STARSBrightness, transforms ScopeTransformations,
+	cb *renderer.CommandBuffer) {
+	if !b.active {
+		return
+	}
+
+	// Collect any tiles that have arrived.
+	for done := false; !done; {
+		select {
+		case r := <-b.imgChan:
+			if t, ok := b.tiles[r.key]; ok {
+				t.pending = false
+				if r.err != nil {
+					ctx.Lg.Warnf("%+v: basemap tile: %v", r.key, r.err)
+				} else {
+					t.texId = ctx.Renderer.CreateTextureFromImage(r.img, false)
+				}
+			}
+		default:
+			done = true
+		}
+	}
+
+	// The scope is rotated for magnetic variation, so all four corners
+	// are needed to bound the visible region.
+	pe := ctx.PaneExtent
+	e := math.Extent2DFromPoints([][2]float32{
+		transforms.LatLongFromWindowP([2]float32{0, 0}),
+		transforms.LatLongFromWindowP([2]float32{pe.Width(), 0}),
+		transforms.LatLongFromWindowP([2]float32{0, pe.Height()}),
+		transforms.LatLongFromWindowP([2]float32{pe.Width(), pe.Height()}),
+	})
+	visible := visibleTiles(src, e, pe.Width()*ctx.DPIScale)
+
+	isVisible := make(map[basemapTileKey]interface{})
+	for _, key := range visible {
+		isVisible[key] = nil
+		if _, ok := b.tiles[key]; !ok {
+			select {
+			case b.reqChan <- key:
+				b.tiles[key] = &basemapTile{pending: true}
+			default:
+				// The fetcher is busy; we'll try again next frame.
+			}
+		}
+	}
+
+	// Free up textures for tiles that are no longer visible if we have
+	// too many of them.
+	if len(b.tiles) > maxBasemapTextures {
+		for key, t := range b.tiles {
+			if _, ok := isVisible[key]; !ok && !t.pending {
+				if t.texId != 0 {
+					ctx.Renderer.DestroyTexture(t.texId)
+				}
+				delete(b.tiles, key)
+			}
+		}
+	}
+
+	transforms.LoadLatLongViewingMatrices(cb)
+	cb.SetRGB(brightness.RGB())
+	for _, key := range visible {
+		t := b.tiles[key]
+		if t == nil || t.texId == 0 {
+			continue
+		}
+
+		// Tiles are small enough that treating them as axis-aligned in
+		// lat-long is a fine approximation of the Mercator projection.
+		te := key.Extent()
+		td := renderer.GetTexturedTrianglesDrawBuilder()
+		td.AddQuad(te.P0, [2]float32{te.P1[0], te.P0[1]}, te.P1, [2]float32{te.P0[0], te.P1[1]},
+			[2]float32{0, 1}, [2]float32{1, 1}, [2]float32{1, 0}, [2]float32{0, 0})
+		cb.EnableTexture(t.texId)
+		td.GenerateCommands(cb)
+		cb.DisableTexture()
+		renderer.ReturnTexturedTrianglesDrawBuilder(td)
+	}
+}
+
+func fetchBasemapTiles(reqChan chan basemapTileKey, imgChan chan basemapTileResult, done chan struct{},
+	lg *log.Logger) {
+	// Tiles used to be cached in their own directory; they are now kept
+	// in the resource cache.
+	if dir, err := util.CacheDir(); err == nil {
+		os.RemoveAll(filepath.Join(dir, "basemap"))
+	}
+
+	client := http.Client{Timeout: 30 * time.Second}
+	cache := util.GetResourceCache()
+
+	// send returns false if the Basemap has been deactivated, in which
+	// case nothing is reading imgChan any more.
+	send := func(r basemapTileResult) bool {
+		select {
+		case imgChan <- r:
+			return true
+		case <-done:
+			return false
+		}
+	}
+
+	for {
+		var key basemapTileKey
+		select {
+		case key = <-reqChan:
+		case <-done:
+			return
+		}
+
+		// Tiles don't change, so cached ones never expire.
+		cacheKey := fmt.Sprintf("basemap/%s/%d/%d/%d", key.Source, key.Z, key.X, key.Y)
+		b, _, err := cache.Get(cacheKey, -1, func() ([]byte, error) {
+			return fetchBasemapTile(&client, getBasemapSource(key.Source), key)
+		})
+		if err != nil {
+			if !send(basemapTileResult{key: key, err: err}) {
+				return
+			}
+			continue
+		}
+
+		img, _, err := image.Decode(bytes.NewReader(b))
+		if src := getBasemapSource(key.Source); err == nil && src.Process != nil {
+			img = src.Process(img, key)
+		}
+		if !send(basemapTileResult{key: key, img: img, err: err}) {
+			return
+		}
+	}
+}
+
+func fetchBasemapTile(client *http.Client, src basemapSource, key basemapTileKey) ([]byte, error) {
+	url := strings.NewReplacer("{z}", strconv.Itoa(key.Z), "{x}", strconv.Itoa(key.X),
+		"{y}", strconv.Itoa(key.Y)).Replace(src.URL)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Tile servers' usage policies require an identifying user agent.
+	req.Header.Set("User-Agent", "vice-atc")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (sp *STARSPane) drawBasemapUI() {
+	ps := sp.currentPrefs()
+
+	imgui.Checkbox("Draw satellite/terrain basemap", &ps.Basemap.Visible)
+	if imgui.BeginComboV("Basemap source", ps.Basemap.Source, imgui.ComboFlagsHeightLarge) {
+		for _, src := range basemapSources {
+			if imgui.SelectableV(src.Name, src.Name == ps.Basemap.Source, 0, imgui.Vec2{}) {
+				ps.Basemap.Source = src.Name
+			}
+		}
+		imgui.EndCombo()
+	}
+	brightness := int32(ps.Brightness.Basemap)
+	imgui.SliderInt("Basemap brightness", &brightness, 0, 100)
+	ps.Brightness.Basemap = STARSBrightness(brightness)
+
+	imgui.Checkbox("Draw terrain hillshade", &ps.DisplayHillshade)
+	terrain := int32(ps.Brightness.Terrain)
+	imgui.SliderInt("Terrain brightness (hillshade, MVA/MIA maps)", &terrain, 0, 100)
+	ps.Brightness.Terrain = STARSBrightness(terrain)
+
+	imgui.Checkbox("Draw obstacles when zoomed in", &ps.DisplayObstacles)
+}
@@ -0,0 +1,59 @@
+// pkg/panes/stars/basemap_test.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package stars
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestBasemapDeactivateStopsFetcher(t *testing.T) {
+	// Keep the fetcher away from the user's cache directory.
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	before := runtime.NumGoroutine()
+
+	// As happens when switching back and forth between workspaces.
+	var b Basemap
+	for range 10 {
+		b.Activate(nil)
+		b.Deactivate()
+	}
+	if b.active || b.reqChan != nil || b.done != nil {
+		t.Errorf("basemap still active after Deactivate")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("%d goroutines still running after Deactivate", n-before)
+	}
+}
+
+func TestBasemapReactivateKeepsTextures(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	var b Basemap
+	b.Activate(nil)
+	b.Deactivate()
+
+	fetched, pending := basemapTileKey{Z: 5, X: 1, Y: 2}, basemapTileKey{Z: 5, X: 2, Y: 2}
+	b.tiles[fetched] = &basemapTile{texId: 1}
+	b.tiles[pending] = &basemapTile{pending: true}
+
+	b.Activate(nil)
+	defer b.Deactivate()
+	if _, ok := b.tiles[fetched]; !ok {
+		t.Errorf("fetched tile was discarded")
+	}
+	if _, ok := b.tiles[pending]; ok {
+		t.Errorf("pending tile should have been discarded since its request was lost")
+	}
+}
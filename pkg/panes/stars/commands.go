@@ -96,6 +96,12 @@ func (sp *STARSPane) processKeyboardInput(ctx *panes.Context) {
 			sp.disableMenuSpinner(ctx)
 			sp.wipRBL = nil
 			sp.wipSignificantPoint = nil
+		case platform.KeyPlus, platform.KeyMinus:
+			// Control-+/- steps through the standard ranges; without
+			// control they're regular input characters.
+			if ctx.Keyboard.WasPressed(platform.KeyControl) && !sp.LockDisplay {
+				ps.Range = stepRadarRange(ps.Range, key == platform.KeyMinus)
+			}
 		case platform.KeyF1:
 			if ctx.Keyboard.WasPressed(platform.KeyControl) {
 				// Recenter
@@ -107,6 +113,13 @@ func (sp *STARSPane) processKeyboardInput(ctx *panes.Context) {
 				sp.resetInputState()
 				sp.commandMode = CommandModeReleaseDeparture
 			}
+			if !ctx.Keyboard.WasPressed(platform.KeyControl) && !ctx.Keyboard.WasPressed(platform.KeyShift) &&
+				sp.commandMode == CommandModeNone {
+				// Show help for the command being entered, if any.
+				if t, ok := panes.CommandHelp(sp.previewAreaInput); ok {
+					panes.RequestHelp(t)
+				}
+			}
 		case platform.KeyF2:
 			if ctx.Keyboard.WasPressed(platform.KeyControl) {
 				if ps.DisplayDCB {
@@ -190,6 +203,8 @@ func (sp *STARSPane) processKeyboardInput(ctx *panes.Context) {
 				sp.resetInputState()
 				sp.commandMode = CommandModeCollisionAlert
 			}
+		case platform.KeyF12:
+			sp.findCursorStart = time.Now()
 		case platform.KeyF13:
 			sp.resetInputState()
 			sp.commandMode = CommandModeReleaseDeparture
@@ -455,8 +470,7 @@ func (sp *STARSPane) executeSTARSCommand(cmd string, ctx *panes.Context) (status
 
 		if len(cmd) > 3 && cmd[:3] == "*F " && sp.wipSignificantPoint != nil {
 			if sig, ok := sp.significantPoints[cmd[3:]]; ok {
-				status = sp.displaySignificantPointInfo(*sp.wipSignificantPoint, sig.Location,
-					ctx.ControlClient.NmPerLongitude, ctx.ControlClient.MagneticVariation)
+				status = sp.displaySignificantPointInfo(ctx, *sp.wipSignificantPoint, sig.Location)
 			} else {
 				status.err = ErrSTARSCommandFormat
 			}
@@ -1314,6 +1328,34 @@ func (sp *STARSPane) executeSTARSCommand(cmd string, ctx *panes.Context) (status
 				case 'N':
 					updateList(cmd[1:], &ps.CRDAStatusList.Visible, nil)
 					return
+				case 'F':
+					updateList(cmd[1:], &ps.NoCommList.Visible, &ps.NoCommList.Lines)
+					return
+				case 'A':
+					if len(cmd) > 1 && cmd[1] == ' ' {
+						// Specify the airports to include in the list; "ALL"
+						// reverts to the airports from the SSA list.
+						var airports []string
+						for _, ap := range strings.Fields(cmd[2:]) {
+							if ap == "ALL" {
+								airports = nil
+								break
+							} else if _, ok := ctx.ControlClient.METAR[ap]; ok {
+								airports = append(airports, ap)
+							} else if _, ok := ctx.ControlClient.METAR["K"+ap]; ok {
+								airports = append(airports, "K"+ap)
+							} else {
+								status.err = ErrSTARSIllegalAirport
+								return
+							}
+						}
+						ps.AltimeterListAirports = airports
+						ps.AltimeterList.Visible = true
+						status.clear = true
+					} else {
+						updateList(cmd[1:], &ps.AltimeterList.Visible, &ps.AltimeterList.Lines)
+					}
+					return
 				default:
 					status.err = ErrSTARSIllegalFunction
 					return
@@ -1909,6 +1951,16 @@ func (sp *STARSPane) setTemporaryAltitude(ctx *panes.Context, callsign string, a
 		func(err error) { sp.displayError(err, ctx) })
 }
 
+func (sp *STARSPane) setTemporaryHeading(ctx *panes.Context, callsign string, heading int) {
+	ctx.ControlClient.SetTemporaryHeading(callsign, heading, nil,
+		func(err error) { sp.displayError(err, ctx) })
+}
+
+func (sp *STARSPane) setTemporarySpeed(ctx *panes.Context, callsign string, speed int) {
+	ctx.ControlClient.SetTemporarySpeed(callsign, speed, nil,
+		func(err error) { sp.displayError(err, ctx) })
+}
+
 func (sp *STARSPane) setGlobalLeaderLine(ctx *panes.Context, callsign string, dir *math.CardinalOrdinalDirection) {
 	state := sp.Aircraft[callsign]
 	state.GlobalLeaderLineDirection = dir // hack for instant update
@@ -2098,15 +2150,32 @@ func (sp *STARSPane) executeSTARSClickedCommand(ctx *panes.Context, cmd string,
 						if ca.Callsigns[0] == ac.Callsign || ca.Callsigns[1] == ac.Callsign {
 							status.clear = true
 							sp.CAAircraft[i].Acknowledged = true
+							sp.logAlertResponse("CA", ca.Callsigns[0]+"/"+ca.Callsigns[1],
+								ca.SoundEnd.Add(-AlertAudioDuration), ctx.Now, true)
 							return
 						}
 					}
+				} else if alert, acknowledged := sp.runwayAlertAircraft(ac.Callsign); alert && !acknowledged {
+					// Acknowledged a runway occupancy alert
+					status.clear = true
+					for i, ra := range sp.RunwayAlerts {
+						if (ra.Arrival == ac.Callsign || ra.Occupant == ac.Callsign) && !ra.Acknowledged {
+							sp.RunwayAlerts[i].Acknowledged = true
+							sp.logAlertResponse("RWY", ra.Arrival+"/"+ra.Occupant,
+								ra.SoundEnd.Add(-AlertAudioDuration), ctx.Now, true)
+						}
+					}
+					return
 				} else if state.MSAW && !state.MSAWAcknowledged {
 					// Acknowledged a MSAW
 					state.MSAWAcknowledged = true
+					sp.logAlertResponse("MSAW", ac.Callsign, state.MSAWSoundEnd.Add(-AlertAudioDuration),
+						ctx.Now, true)
 				} else if state.SPCAlert && !state.SPCAcknowledged {
 					// Acknowledged SPC alert
 					state.SPCAcknowledged = true
+					sp.logAlertResponse(ac.Squawk.String(), ac.Callsign, state.SPCSoundEnd.Add(-AlertAudioDuration),
+						ctx.Now, true)
 				} else if trk != nil && trk.HandoffController != "" && trk.HandoffController != ctx.ControlClient.Callsign &&
 					trk.TrackOwner == ctx.ControlClient.Callsign {
 					// cancel offered handoff offered
@@ -2204,6 +2273,12 @@ func (sp *STARSPane) executeSTARSClickedCommand(ctx *panes.Context, cmd string,
 				sp.scopeClickHandler = toSignificantPointClickHandler(ctx, sp)
 				sp.previewAreaInput += " " // sort of a hack: if the fix is entered via keyboard, it appears on the next line
 				return
+			} else if cmd == "*C" {
+				// toggle whether the aircraft is communicating on our frequency
+				state.OnFrequency = !state.OnFrequency
+				state.HandoffAcceptedTime, state.HandoffAcceptedFrom = time.Time{}, ""
+				status.clear = true
+				return
 			} else if cmd == "*J" {
 				// remove j-ring for aircraft
 				state.JRingRadius = 0
@@ -2345,6 +2420,32 @@ func (sp *STARSPane) executeSTARSClickedCommand(ctx *panes.Context, cmd string,
 					}
 				}
 				return
+			} else if len(cmd) >= 2 && (cmd[:2] == "*H" || cmd[:2] == "*S") {
+				// Assigned heading or speed; with no value, the field is
+				// cleared.
+				value := 0
+				if len(cmd) > 2 {
+					var err error
+					if value, err = strconv.Atoi(cmd[2:]); err != nil || len(cmd) > 5 {
+						status.err = ErrSTARSCommandFormat
+						return
+					}
+				}
+				if cmd[1] == 'H' {
+					if len(cmd) > 2 && (len(cmd) != 5 || value < 1 || value > 360) {
+						status.err = ErrSTARSIllegalValue
+						return
+					}
+					sp.setTemporaryHeading(ctx, ac.Callsign, value)
+				} else {
+					if len(cmd) > 2 && (value < 40 || value > 999) {
+						status.err = ErrSTARSIllegalValue
+						return
+					}
+					sp.setTemporarySpeed(ctx, ac.Callsign, value)
+				}
+				status.clear = true
+				return
 			} else if cmd == ".ROUTE" {
 				sp.drawRouteAircraft = ac.Callsign
 				status.clear = true
@@ -2821,11 +2922,21 @@ func (sp *STARSPane) executeSTARSClickedCommand(ctx *panes.Context, cmd string,
 			ps.VideoMapsList.Visible = true
 			status.clear = true
 			return
+		} else if cmd == "TF" {
+			ps.NoCommList.Position = transforms.NormalizedFromWindowP(mousePosition)
+			ps.NoCommList.Visible = true
+			status.clear = true
+			return
 		} else if cmd == "TN" {
 			ps.CRDAStatusList.Position = transforms.NormalizedFromWindowP(mousePosition)
 			ps.CRDAStatusList.Visible = true
 			status.clear = true
 			return
+		} else if cmd == "TA" {
+			ps.AltimeterList.Position = transforms.NormalizedFromWindowP(mousePosition)
+			ps.AltimeterList.Visible = true
+			status.clear = true
+			return
 		} else if len(cmd) >= 2 && cmd[0] == 'P' {
 			list, _ := sp.getTowerOrCoordinationList(cmd[1:])
 			if list == nil {
@@ -2880,6 +2991,20 @@ func (sp *STARSPane) numpadToDirection(key byte) (*math.CardinalOrdinalDirection
 	}
 }
 
+// zoomAbout sets the range to r, adjusting the scope center so that the
+// point p stays fixed on the screen.
+func (ps *Preferences) zoomAbout(r float32, p math.Point2LL) {
+	r = math.Clamp(r, 6, 256) // 4-33
+	scale := r / ps.Range
+	centerTransform := math.Identity3x3().
+		Translate(p[0], p[1]).
+		Scale(scale, scale).
+		Translate(-p[0], -p[1])
+
+	ps.CurrentCenter = centerTransform.TransformPoint(ps.CurrentCenter)
+	ps.Range = r
+}
+
 func (sp *STARSPane) consumeMouseEvents(ctx *panes.Context, ghosts []*av.GhostAircraft,
 	transforms ScopeTransformations, cb *renderer.CommandBuffer) {
 	if ctx.Mouse == nil {
@@ -2913,24 +3038,19 @@ func (sp *STARSPane) consumeMouseEvents(ctx *panes.Context, ghosts []*av.GhostAi
 		// Consume mouse wheel
 		if mouse.Wheel[1] != 0 {
 			r := ps.Range
-			if _, ok := ctx.Keyboard.Pressed[platform.KeyControl]; ok {
-				ps.Range += 3 * mouse.Wheel[1]
+			if ctx.Keyboard != nil && ctx.Keyboard.WasPressed(platform.KeyControl) {
+				r += 3 * mouse.Wheel[1]
+			} else if ctx.Keyboard != nil && ctx.Keyboard.WasPressed(platform.KeyShift) {
+				r += 0.25 * mouse.Wheel[1]
 			} else {
-				ps.Range += mouse.Wheel[1]
+				r += mouse.Wheel[1]
 			}
-			ps.Range = math.Clamp(ps.Range, 6, 256) // 4-33
-
-			// We want to zoom in centered at the mouse position; this affects
-			// the scope center after the zoom, so we'll find the
-			// transformation that gives the new center position.
-			mouseLL := transforms.LatLongFromWindowP(mouse.Pos)
-			scale := ps.Range / r
-			centerTransform := math.Identity3x3().
-				Translate(mouseLL[0], mouseLL[1]).
-				Scale(scale, scale).
-				Translate(-mouseLL[0], -mouseLL[1])
 
-			ps.CurrentCenter = centerTransform.TransformPoint(ps.CurrentCenter)
+			if sp.WheelZoomAtCenter {
+				ps.Range = math.Clamp(r, 6, 256) // 4-33
+			} else {
+				ps.zoomAbout(r, transforms.LatLongFromWindowP(mouse.Pos))
+			}
 		}
 	}
 
@@ -2946,6 +3066,9 @@ func (sp *STARSPane) consumeMouseEvents(ctx *panes.Context, ghosts []*av.GhostAi
 			if ac, _ := sp.tryGetClosestAircraft(ctx, ctx.Mouse.Pos, transforms); ac != nil {
 				if state := sp.Aircraft[ac.Callsign]; state != nil {
 					state.IsSelected = !state.IsSelected
+					if state.IsSelected {
+						sp.showMiniStrip(ac.Callsign, platform.MouseButtonPrimary)
+					}
 					return
 				}
 			}
@@ -2972,6 +3095,9 @@ func (sp *STARSPane) consumeMouseEvents(ctx *panes.Context, ghosts []*av.GhostAi
 		if ac, _ := sp.tryGetClosestAircraft(ctx, ctx.Mouse.Pos, transforms); ac != nil {
 			if state := sp.Aircraft[ac.Callsign]; state != nil {
 				state.IsSelected = !state.IsSelected
+				if state.IsSelected {
+					sp.showMiniStrip(ac.Callsign, platform.MouseButtonTertiary)
+				}
 			}
 		}
 	} else if !ctx.ControlClient.SimIsPaused {
@@ -3026,7 +3152,9 @@ func (sp *STARSPane) consumeMouseEvents(ctx *panes.Context, ghosts []*av.GhostAi
 			// Get it all into the command buffer
 			transforms.LoadWindowViewingMatrices(cb)
 			cb.SetRGBA(renderer.RGBA{R: 0.25, G: 0.25, B: 0.25, A: 0.75})
-			cb.Blend()
+			if !ctx.Degraded {
+				cb.Blend()
+			}
 			trid.GenerateCommands(cb)
 			cb.DisableBlend()
 			td.GenerateCommands(cb)
@@ -3043,6 +3171,10 @@ func amendFlightPlan(ctx *panes.Context, callsign string, amend func(fp *av.Flig
 	} else {
 		fp := util.Select(ac.FlightPlan != nil, ac.FlightPlan, &av.FlightPlan{})
 		amend(fp)
+		// STARS works with domestic flight plans; ICAO equipment codes are
+		// converted to the corresponding FAA equipment suffix. (Item 18
+		// information remains in the remarks.)
+		fp.AircraftType = fp.FAAAircraftType()
 		return ctx.ControlClient.AmendFlightPlan(callsign, *fp)
 	}
 }
@@ -3262,6 +3394,10 @@ func (sp *STARSPane) lookupControllerForId(ctx *panes.Context, id, callsign stri
 		return nil
 	}
 
+	if id == "NEXT" && !haveTrianglePrefix {
+		return sp.nextController(ctx, callsign)
+	}
+
 	if haveTrianglePrefix {
 		if lc == 1 {
 			// Facility id where there's only one controller at that facility.
@@ -3316,6 +3452,40 @@ func (sp *STARSPane) lookupControllerForId(ctx *panes.Context, id, callsign stri
 				return control
 			}
 		}
+
+		// The sector may not be staffed, in which case it goes to
+		// whoever it is consolidated into.
+		for _, pos := range util.SortedMapKeys(ctx.ControlClient.ControlPositions) {
+			control := ctx.ControlClient.ControlPositions[pos]
+			if control.FacilityIdentifier == "" && control.SectorId == id {
+				if ctrl := resolvedController(ctx, pos); ctrl != nil {
+					return ctrl
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// nextController returns the controller that the aircraft should be
+// handed off to next according to the facility's airspace awareness
+// rules, allowing for consolidated positions.
+func (sp *STARSPane) nextController(ctx *panes.Context, callsign string) *av.Controller {
+	if callsign == "" {
+		return nil
+	}
+	pos, err := calculateAirspace(ctx, callsign)
+	if err != nil {
+		return nil
+	}
+	return resolvedController(ctx, pos)
+}
+
+// resolvedController returns the controller currently working the given
+// position, if any.
+func resolvedController(ctx *panes.Context, pos string) *av.Controller {
+	if callsign, ok := ctx.ControlClient.ResolveController(pos); ok {
+		return ctx.ControlClient.Controllers[callsign]
 	}
 	return nil
 }
@@ -55,7 +55,7 @@ type fullDatablock struct {
 	field5  [3][7]dbChar
 	// line 3
 	field6 [2][5]dbChar
-	field7 [2][4]dbChar
+	field7 [4][4]dbChar
 }
 
 func (db fullDatablock) draw(td *renderer.TextDrawBuilder, pt [2]float32, font *renderer.Font,
@@ -76,7 +76,7 @@ func (db fullDatablock) draw(td *renderer.TextDrawBuilder, pt [2]float32, font *
 	nc := math.Max(numVariants([][]dbChar{db.field34[0][:], db.field34[1][:], db.field34[2][:]}),
 		numVariants([][]dbChar{db.field5[0][:], db.field5[1][:], db.field5[2][:]}))
 	nc = math.Max(nc, numVariants([][]dbChar{db.field6[0][:], db.field6[1][:]}))
-	nc = math.Max(nc, numVariants([][]dbChar{db.field7[0][:], db.field7[1][:]}))
+	nc = math.Max(nc, numVariants([][]dbChar{db.field7[0][:], db.field7[1][:], db.field7[2][:], db.field7[3][:]}))
 
 	// Cycle 1 is 2s, others are 1.5s. Then get that in half seconds.
 	fullCycleHalfSeconds := 4 + 3*(nc-1)
@@ -100,7 +100,7 @@ func (db fullDatablock) draw(td *renderer.TextDrawBuilder, pt [2]float32, font *
 		dbMakeLine(dbChopTrailing(selectMultiplexed([][]dbChar{db.field34[0][:], db.field34[1][:], db.field34[2][:]})),
 			selectMultiplexed([][]dbChar{db.field5[0][:], db.field5[1][:], db.field5[2][:]})),
 		dbMakeLine(selectMultiplexed([][]dbChar{db.field6[0][:], db.field6[1][:]}),
-			selectMultiplexed([][]dbChar{db.field7[0][:], db.field7[1][:]})),
+			selectMultiplexed([][]dbChar{db.field7[0][:], db.field7[1][:], db.field7[2][:], db.field7[3][:]})),
 	}
 	pt[1] += float32(font.Size) // align leader with line 1
 	dbDrawLines(lines, td, pt, font, brightness, leaderLineDirection, halfSeconds)
@@ -425,8 +425,9 @@ func (sp *STARSPane) getDatablock(ctx *panes.Context, ac *av.Aircraft) datablock
 
 	// Alerts are common to all datablock types
 	var alerts [16]dbChar
+	// Alerts flash until they are acknowledged.
 	formatDBText(alerts[:], strings.Join(sp.getWarnings(ctx, ac), "/"), STARSTextAlertColor,
-		false /* do these ever flash? */)
+		sp.unacknowledgedAlert(ac))
 
 	trk := sp.getTrack(ctx, ac)
 
@@ -773,14 +774,30 @@ func (sp *STARSPane) getDatablock(ctx *panes.Context, ac *av.Aircraft) datablock
 			formatDBText(db.field6[idx][:], ac.Squawk.String(), color, false)
 		}
 
-		// Field 7: assigned altitude, assigned beacon if mismatch
+		// Field 7: assigned altitude, heading, and speed, assigned beacon
+		// if mismatch; these time-share in that order.
+		f7 := 0
 		if ac.TempAltitude != 0 {
 			ta := (ac.TempAltitude + 50) / 100
-			formatDBText(db.field7[0][:], fmt.Sprintf("A%03d", ta), color, false)
+			formatDBText(db.field7[f7][:], fmt.Sprintf("A%03d", ta), color, false)
+			f7++
+		}
+		if ac.TempHeading != 0 {
+			formatDBText(db.field7[f7][:], fmt.Sprintf("H%03d", ac.TempHeading), color, false)
+			f7++
+		}
+		if ac.TempSpeed != 0 {
+			formatDBText(db.field7[f7][:], fmt.Sprintf("S%03d", ac.TempSpeed), color, false)
+			f7++
 		}
 		if beaconMismatch {
-			idx := util.Select(fieldEmpty(db.field7[0][:]), 0, 1)
-			formatDBText(db.field7[idx][:], trk.FlightPlan.AssignedSquawk.String(), color, true)
+			formatDBText(db.field7[f7][:], trk.FlightPlan.AssignedSquawk.String(), color, true)
+			f7++
+		}
+		if f7 < len(db.field7) && trk.TrackOwner == ctx.ControlClient.Callsign && !state.OnFrequency {
+			// Not yet communicating on our frequency; it flashes if it's
+			// been a while since we accepted the handoff.
+			formatDBText(db.field7[f7][:], "NC", color, sp.noCommAlert(ctx, state))
 		}
 
 		return db
@@ -1019,6 +1036,9 @@ func (sp *STARSPane) haveActiveWarnings(ctx *panes.Context, ac *av.Aircraft) boo
 			}) {
 		return true
 	}
+	if alert, _ := sp.runwayAlertAircraft(ac.Callsign); alert && !ps.DisableRunwayAlerts {
+		return true
+	}
 	if _, outside := sp.WarnOutsideAirspace(ctx, ac); outside {
 		return true
 	}
@@ -1040,6 +1060,9 @@ func (sp *STARSPane) getWarnings(ctx *panes.Context, ac *av.Aircraft) []string {
 	if state.MSAW && !state.InhibitMSAW && !state.DisableMSAW && !ps.DisableMSAW {
 		addWarning("LA")
 	}
+	if state.TFR != "" {
+		addWarning("TFR")
+	}
 	if ok, code := av.SquawkIsSPC(ac.Squawk); ok {
 		addWarning(code)
 	}
@@ -1053,6 +1076,9 @@ func (sp *STARSPane) getWarnings(ctx *panes.Context, ac *av.Aircraft) []string {
 			}) {
 		addWarning("CA")
 	}
+	if alert, _ := sp.runwayAlertAircraft(ac.Callsign); alert && !ps.DisableRunwayAlerts {
+		addWarning("RWY")
+	}
 	if alts, outside := sp.WarnOutsideAirspace(ctx, ac); outside {
 		altStrs := ""
 		for _, a := range alts {
@@ -916,6 +916,27 @@ func (s *dcbRadarRangeSpinner) KeyboardInput(text string) error {
 
 func (s *dcbRadarRangeSpinner) Disabled() {}
 
+// radarRanges are the ranges stepped through when the range is changed
+// from the keyboard.
+var radarRanges = []float32{6, 8, 10, 12, 16, 20, 24, 32, 40, 48, 64, 80, 96, 128, 160, 192, 256}
+
+// stepRadarRange returns the next standard range above r or, if zoomOut is
+// false, the next one below it.
+func stepRadarRange(r float32, zoomOut bool) float32 {
+	if zoomOut {
+		if idx := slices.IndexFunc(radarRanges, func(v float32) bool { return v > r }); idx != -1 {
+			return radarRanges[idx]
+		}
+		return radarRanges[len(radarRanges)-1]
+	}
+	for i := len(radarRanges) - 1; i >= 0; i-- {
+		if radarRanges[i] < r {
+			return radarRanges[i]
+		}
+	}
+	return radarRanges[0]
+}
+
 // dcbIntegerRangeSpinner is a generic implementation of dcbSpinner for
 // managing integers in steps of 1 within a given range.
 type dcbIntegerRangeSpinner struct {
@@ -0,0 +1,204 @@
+// pkg/panes/stars/euroscope.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package stars
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/util"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// Controllers moving from EuroScope can import the tag families, lists,
+// and symbology from their EuroScope settings files. STARS datablocks
+// have a fixed format, so tag items are mapped onto the preferences that
+// control the optional datablock fields; items that STARS always shows
+// or can't show are reported so the user knows what didn't carry over.
+//
+// The following lines are used; everything else is ignored:
+//
+//	TAGFAMILY:name
+//	TAGTYPE:index:name                 e.g. "Untagged", "Tagged", "Detailed"
+//	TAGITEM:TAG_ITEM_TYPE_xxx:...
+//	LIST:name:visible:lines            e.g. "LIST:Departure List:1:8"
+//	group:item:color:size:...          symbology, e.g. "Datablock:tagged:65280:3.5:0:0:0"
+
+// esTagItemsAlwaysShown are the EuroScope tag items that have an
+// equivalent in STARS full datablocks that is always shown.
+var esTagItemsAlwaysShown = map[string]string{
+	"TAG_ITEM_TYPE_CALLSIGN":          "callsign",
+	"TAG_ITEM_TYPE_ALTITUDE":          "altitude",
+	"TAG_ITEM_TYPE_GROUND_SPEED":      "ground speed",
+	"TAG_ITEM_TYPE_PLANE_TYPE":        "aircraft type",
+	"TAG_ITEM_TYPE_AIRCRAFT_CATEGORY": "wake category",
+	"TAG_ITEM_TYPE_SCRATCH_PAD":       "scratchpad",
+	"TAG_ITEM_TYPE_TEMP_ALTITUDE":     "temporary altitude",
+	"TAG_ITEM_TYPE_ASSIGNED_HEADING":  "assigned heading (recorded with *H)",
+	"TAG_ITEM_TYPE_ASSIGNED_SPEED":    "assigned speed (recorded with *S)",
+	"TAG_ITEM_TYPE_VERTICAL_SPEED":    "climb/descent arrow",
+	"TAG_ITEM_TYPE_SECTOR_INDICATOR":  "owning position",
+}
+
+// esLists maps from EuroScope list names to the corresponding STARS
+// lists.
+var esLists = map[string]func(ps *Preferences) *BasicSTARSList{
+	"DEPARTURE LIST":    func(ps *Preferences) *BasicSTARSList { return &ps.TABList },
+	"ARRIVAL LIST":      func(ps *Preferences) *BasicSTARSList { return &ps.TowerLists[0] },
+	"VFR LIST":          func(ps *Preferences) *BasicSTARSList { return &ps.VFRList },
+	"CONFLICT LIST":     func(ps *Preferences) *BasicSTARSList { return &ps.AlertList },
+	"CONFLICT AND RISK": func(ps *Preferences) *BasicSTARSList { return &ps.AlertList },
+	"CONTROLLER LIST":   func(ps *Preferences) *BasicSTARSList { return &ps.SignOnList },
+}
+
+// importEuroScopeSettings applies the EuroScope settings in r to ps,
+// returning descriptions of the settings that were applied and of those
+// that couldn't be.
+func importEuroScopeSettings(r io.Reader, ps *Preferences) (applied, unsupported []string, err error) {
+	tagType := ""
+	note := func(s *[]string, msg string) {
+		if !slices.Contains(*s, msg) {
+			*s = append(*s, msg)
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		f := strings.Split(line, ":")
+
+		switch strings.ToUpper(f[0]) {
+		case "TAGFAMILY":
+			tagType = ""
+
+		case "TAGTYPE":
+			if len(f) >= 3 {
+				tagType = strings.ToUpper(f[2])
+			}
+
+		case "TAGITEM":
+			if len(f) < 2 {
+				continue
+			}
+			item := strings.ToUpper(f[1])
+			// Untagged aircraft get limited datablocks in STARS; the rest
+			// get full datablocks.
+			limited := tagType == "UNTAGGED" || tagType == "UNCORRELATED"
+
+			switch {
+			case item == "TAG_ITEM_TYPE_SQUAWK" && limited:
+				ps.DisplayLDBBeaconCodes = true
+				note(&applied, "Beacon codes shown in limited datablocks")
+			case item == "TAG_ITEM_TYPE_FINAL_ALTITUDE" && !limited:
+				ps.DisplayRequestedAltitude = true
+				note(&applied, "Requested altitude shown in full datablocks")
+			case esTagItemsAlwaysShown[item] != "" && !limited:
+				note(&applied, "Always shown in full datablocks: "+esTagItemsAlwaysShown[item])
+			case limited && (item == "TAG_ITEM_TYPE_ALTITUDE" || item == "TAG_ITEM_TYPE_CALLSIGN"):
+				// Limited datablocks always include these.
+			default:
+				note(&unsupported, "Tag item "+f[1]+util.Select(limited, " (untagged)", ""))
+			}
+
+		case "LIST":
+			// LIST:name:visible:lines
+			if len(f) < 3 {
+				continue
+			}
+			get, ok := esLists[strings.ToUpper(f[1])]
+			if !ok {
+				note(&unsupported, "List "+f[1])
+				continue
+			}
+			l := get(ps)
+			l.Visible = f[2] == "1"
+			if len(f) > 3 {
+				if n, err := strconv.Atoi(f[3]); err == nil {
+					l.Lines = math.Clamp(n, 1, 100)
+				}
+			}
+			note(&applied, "List "+f[1])
+
+		case "DATABLOCK", "LIST TEXT":
+			// Symbology: group:item:color:size:... STARS text sizes are
+			// 0-5; EuroScope's are in points, with 3.5 typical.
+			if len(f) < 4 {
+				continue
+			}
+			size, err := strconv.ParseFloat(f[3], 32)
+			if err != nil {
+				continue
+			}
+			cs := math.Clamp(int((size-3)*2+0.5), 0, 5)
+			if strings.EqualFold(f[0], "DATABLOCK") {
+				ps.CharSize.Datablocks = cs
+				note(&applied, fmt.Sprintf("Datablock text size %d", cs))
+			} else {
+				ps.CharSize.Lists = cs
+				note(&applied, fmt.Sprintf("List text size %d", cs))
+			}
+			// Colors are fixed in STARS.
+			note(&unsupported, "Symbology colors")
+		}
+	}
+	return applied, unsupported, scanner.Err()
+}
+
+// esImporter holds the state for importing EuroScope settings from the
+// settings window.
+type esImporter struct {
+	filename    string
+	applied     []string
+	unsupported []string
+	err         string
+}
+
+func (sp *STARSPane) drawEuroScopeImportUI() {
+	esi := &sp.esImport
+	imgui.InputText("EuroScope settings file", &esi.filename)
+	imgui.SameLine()
+	if imgui.Button("Import##euroscope") {
+		esi.applied, esi.unsupported, esi.err = nil, nil, ""
+		if f, err := os.Open(esi.filename); err != nil {
+			esi.err = err.Error()
+		} else {
+			defer f.Close()
+			ps := sp.currentPrefs()
+			if esi.applied, esi.unsupported, err = importEuroScopeSettings(f, ps); err != nil {
+				esi.err = err.Error()
+			}
+		}
+	}
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Imports tag families, lists, and symbology sizes; the ones without STARS\n" +
+			"equivalents are listed after importing.")
+	}
+
+	if esi.err != "" {
+		imgui.Text("Import error: " + esi.err)
+	}
+	if len(esi.applied) > 0 {
+		imgui.Text("Imported:")
+		for _, s := range esi.applied {
+			imgui.Text("  " + s)
+		}
+	}
+	if len(esi.unsupported) > 0 {
+		imgui.Text("Not available in STARS:")
+		for _, s := range esi.unsupported {
+			imgui.Text("  " + s)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// pkg/panes/stars/finals.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package stars
+
+import (
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/panes"
+	"github.com/mmp/vice/pkg/renderer"
+	"github.com/mmp/vice/pkg/util"
+)
+
+// drawFinals draws the extended centerlines of the active arrival
+// runways, with distance marks and the adapted gates.
+func (sp *STARSPane) drawFinals(ctx *panes.Context, transforms ScopeTransformations, cb *renderer.CommandBuffer) {
+	ps := sp.currentPrefs()
+	if !ps.DisplayFinals || ps.Brightness.Lines == 0 {
+		return
+	}
+
+	ld := renderer.GetLinesDrawBuilder()
+	defer renderer.ReturnLinesDrawBuilder(ld)
+	td := renderer.GetTextDrawBuilder()
+	defer renderer.ReturnTextDrawBuilder(td)
+
+	color := ps.Brightness.Lines.ScaleRGB(STARSMapColor)
+	style := renderer.TextStyle{Font: sp.systemFont[ps.CharSize.Tools], Color: color}
+	nmPerLongitude := ctx.ControlClient.NmPerLongitude
+
+	for _, f := range ctx.ControlClient.ActiveFinals() {
+		rwy, ok := av.LookupRunway(f.Airport, f.Runway)
+		if !ok {
+			continue
+		}
+		opp, ok := av.LookupOppositeRunway(f.Airport, f.Runway)
+		if !ok {
+			continue
+		}
+
+		// The centerline extends from the threshold away from the
+		// opposite end of the runway.
+		thr := math.LL2NM(rwy.Threshold, nmPerLongitude)
+		dir := math.Normalize2f(math.Sub2f(thr, math.LL2NM(opp.Threshold, nmPerLongitude)))
+		pw := func(d float32) [2]float32 {
+			return transforms.WindowFromLatLongP(math.NM2LL(math.Add2f(thr, math.Scale2f(dir, d)), nmPerLongitude))
+		}
+
+		p0, p1 := pw(0), pw(f.Length)
+		ld.AddLine(p0, p1)
+
+		// Marks are drawn perpendicular to the centerline with a fixed
+		// size in pixels, so that they're legible at any range.
+		wdir := math.Normalize2f(math.Sub2f(p1, p0))
+		perp := [2]float32{-wdir[1], wdir[0]}
+		mark := func(d, size float32) [2]float32 {
+			p := pw(d)
+			ld.AddLine(math.Add2f(p, math.Scale2f(perp, size)), math.Sub2f(p, math.Scale2f(perp, size)))
+			return math.Add2f(p, math.Scale2f(perp, size+4))
+		}
+
+		if f.TickSpacing > 0 {
+			for i := 1; float32(i)*f.TickSpacing <= f.Length; i++ {
+				// Every fifth mark is longer.
+				mark(float32(i)*f.TickSpacing, util.Select(i%5 == 0, float32(6), float32(3)))
+			}
+		}
+		for _, g := range f.Gates {
+			td.AddText(g.Name, mark(g.Distance, 10), style)
+		}
+	}
+
+	cb.LineWidth(1, ctx.DPIScale)
+	transforms.LoadWindowViewingMatrices(cb)
+	cb.SetRGB(color)
+	ld.GenerateCommands(cb)
+	td.GenerateCommands(cb)
+}
@@ -10,6 +10,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	av "github.com/mmp/vice/pkg/aviation"
 	"github.com/mmp/vice/pkg/math"
@@ -24,7 +25,7 @@ func (sp *STARSPane) drawSystemLists(aircraft []*av.Aircraft, ctx *panes.Context
 
 	transforms.LoadWindowViewingMatrices(cb)
 
-	font := sp.systemFont[ps.CharSize.Lists]
+	font := sp.listFont(ctx)
 	listStyle := renderer.TextStyle{
 		Font:  font,
 		Color: ps.Brightness.Lists.ScaleRGB(STARSListColor),
@@ -44,8 +45,10 @@ func (sp *STARSPane) drawSystemLists(aircraft []*av.Aircraft, ctx *panes.Context
 	sp.drawTABList(ctx, normalizedToWindow(ps.TABList.Position), aircraft, listStyle, td)
 	sp.drawAlertList(ctx, normalizedToWindow(ps.AlertList.Position), aircraft, listStyle, td)
 	sp.drawCoastList(ctx, normalizedToWindow(ps.CoastList.Position), listStyle, td)
+	sp.drawNoCommList(ctx, normalizedToWindow(ps.NoCommList.Position), aircraft, listStyle, td)
 	sp.drawMapsList(ctx, normalizedToWindow(ps.VideoMapsList.Position), listStyle, td)
 	sp.drawCRDAStatusList(ctx, normalizedToWindow(ps.CRDAStatusList.Position), aircraft, listStyle, td)
+	sp.drawAltimeterList(ctx, normalizedToWindow(ps.AltimeterList.Position), font, td)
 
 	towerListAirports := ctx.ControlClient.TowerListAirports()
 	for i, tl := range ps.TowerLists {
@@ -142,7 +145,7 @@ func (sp *STARSPane) drawSSAList(ctx *panes.Context, pw [2]float32, aircraft []*
 	transforms ScopeTransformations, cb *renderer.CommandBuffer) {
 	ps := sp.currentPrefs()
 
-	font := sp.systemFont[ps.CharSize.Lists]
+	font := sp.listFont(ctx)
 	listStyle := renderer.TextStyle{
 		Font:  font,
 		Color: ps.Brightness.Lists.ScaleRGB(STARSListColor),
@@ -305,23 +308,7 @@ func (sp *STARSPane) drawSSAList(ctx *panes.Context, pw [2]float32, aircraft []*
 	}
 
 	if filter.All || filter.AirportWeather {
-		airports := util.SortedMapKeys(ctx.ControlClient.Airports)
-		// Sort via 1. primary? 2. tower list index, 3. alphabetic
-		sort.Slice(airports, func(i, j int) bool {
-			if airports[i] == ctx.ControlClient.PrimaryAirport {
-				return true
-			} else if airports[j] == ctx.ControlClient.PrimaryAirport {
-				return false
-			} else {
-				a, b := ctx.ControlClient.Airports[airports[i]], ctx.ControlClient.Airports[airports[j]]
-				ai := util.Select(a.TowerListIndex != 0, a.TowerListIndex, 1000)
-				bi := util.Select(b.TowerListIndex != 0, b.TowerListIndex, 1000)
-				if ai != bi {
-					return ai < bi
-				}
-			}
-			return airports[i] < airports[j]
-		})
+		airports := weatherAirports(ctx)
 
 		// 2-78: apparently it's limited to 6 airports; there are also
 		// some nuances about automatically-entered versus manually
@@ -386,6 +373,26 @@ func (sp *STARSPane) drawSSAList(ctx *panes.Context, pw [2]float32, aircraft []*
 			pw = td.AddText(text, pw, listStyle)
 			newline()
 		}
+
+		// Aircraft with inhibited alerts, so they aren't forgotten.
+		var caInhibited, msawInhibited []string
+		for _, callsign := range util.SortedMapKeys(sp.Aircraft) {
+			state := sp.Aircraft[callsign]
+			if state.DisableCAWarnings {
+				caInhibited = append(caInhibited, callsign)
+			}
+			if state.DisableMSAW || state.InhibitMSAW {
+				msawInhibited = append(msawInhibited, callsign)
+			}
+		}
+		if len(caInhibited) > 0 {
+			pw = td.AddText("CA INH: "+strings.Join(caInhibited, " "), pw, listStyle)
+			newline()
+		}
+		if len(msawInhibited) > 0 {
+			pw = td.AddText("LA INH: "+strings.Join(msawInhibited, " "), pw, listStyle)
+			newline()
+		}
 	}
 
 	if (filter.All || filter.ActiveCRDAPairs) && !ps.CRDA.Disabled {
@@ -405,6 +412,28 @@ func (sp *STARSPane) drawSSAList(ctx *panes.Context, pw [2]float32, aircraft []*
 	}
 }
 
+// weatherAirports returns the airports to report weather for, sorted by
+// 1. primary airport, 2. tower list index, 3. alphabetically.
+func weatherAirports(ctx *panes.Context) []string {
+	airports := util.SortedMapKeys(ctx.ControlClient.Airports)
+	sort.Slice(airports, func(i, j int) bool {
+		if airports[i] == ctx.ControlClient.PrimaryAirport {
+			return true
+		} else if airports[j] == ctx.ControlClient.PrimaryAirport {
+			return false
+		} else {
+			a, b := ctx.ControlClient.Airports[airports[i]], ctx.ControlClient.Airports[airports[j]]
+			ai := util.Select(a.TowerListIndex != 0, a.TowerListIndex, 1000)
+			bi := util.Select(b.TowerListIndex != 0, b.TowerListIndex, 1000)
+			if ai != bi {
+				return ai < bi
+			}
+		}
+		return airports[i] < airports[j]
+	})
+	return airports
+}
+
 func (sp *STARSPane) drawVFRList(ctx *panes.Context, pw [2]float32, aircraft []*av.Aircraft, style renderer.TextStyle,
 	td *renderer.TextDrawBuilder) {
 	ps := sp.currentPrefs()
@@ -439,6 +468,38 @@ func (sp *STARSPane) drawVFRList(ctx *panes.Context, pw [2]float32, aircraft []*
 	}
 }
 
+func (sp *STARSPane) drawNoCommList(ctx *panes.Context, pw [2]float32, aircraft []*av.Aircraft, style renderer.TextStyle,
+	td *renderer.TextDrawBuilder) {
+	ps := sp.currentPrefs()
+	if !ps.NoCommList.Visible {
+		return
+	}
+
+	var text strings.Builder
+	var nc []*av.Aircraft
+	// Aircraft we're tracking that haven't checked in on our frequency.
+	for _, ac := range aircraft {
+		if state, ok := sp.Aircraft[ac.Callsign]; ok && !state.OnFrequency {
+			if trk := sp.getTrack(ctx, ac); trk != nil && trk.TrackOwner == ctx.ControlClient.Callsign {
+				nc = append(nc, ac)
+			}
+		}
+	}
+
+	slices.SortFunc(nc, func(a, b *av.Aircraft) int { return strings.Compare(a.Callsign, b.Callsign) })
+
+	text.WriteString("NO COMM\n")
+	if len(nc) > ps.NoCommList.Lines {
+		text.WriteString(fmt.Sprintf("MORE: %d/%d\n", ps.NoCommList.Lines, len(nc)))
+	}
+	for i := range math.Min(len(nc), ps.NoCommList.Lines) {
+		ac := nc[i]
+		text.WriteString(fmt.Sprintf("%s %-7s %s\n", sp.getTabListIndex(ac), ac.Callsign, ac.Squawk.String()))
+	}
+
+	td.AddText(text.String(), pw, style)
+}
+
 func (sp *STARSPane) drawTABList(ctx *panes.Context, pw [2]float32, aircraft []*av.Aircraft, style renderer.TextStyle,
 	td *renderer.TextDrawBuilder) {
 	ps := sp.currentPrefs()
@@ -496,6 +557,30 @@ func (sp *STARSPane) drawAlertList(ctx *panes.Context, pw [2]float32, aircraft [
 		lists = append(lists, "CA")
 		n += len(sp.CAAircraft)
 	}
+	if !ps.DisableRunwayAlerts {
+		lists = append(lists, "RWY")
+		n += len(sp.RunwayAlerts)
+	}
+	var noComm []*av.Aircraft
+	if !ps.DisableNoCommAlerts {
+		lists = append(lists, "NC")
+		for _, ac := range aircraft {
+			if state := sp.Aircraft[ac.Callsign]; sp.noCommAlert(ctx, state) {
+				if trk := sp.getTrack(ctx, ac); trk != nil && trk.TrackOwner == ctx.ControlClient.Callsign {
+					noComm = append(noComm, ac)
+				}
+			}
+		}
+		n += len(noComm)
+	}
+	if len(sp.TFRs.mapActive) > 0 {
+		lists = append(lists, "TFR")
+		for _, ac := range aircraft {
+			if sp.Aircraft[ac.Callsign].TFR != "" {
+				n++
+			}
+		}
+	}
 
 	if len(lists) > 0 {
 		text.WriteString(strings.Join(lists, "/") + "\n")
@@ -510,8 +595,12 @@ func (sp *STARSPane) drawAlertList(ctx *panes.Context, pw [2]float32, aircraft [
 				if n == 0 {
 					break
 				}
-				if sp.Aircraft[ac.Callsign].MSAW {
-					text.WriteString(fmt.Sprintf("%-14s%03d LA\n", ac.Callsign, int((ac.Altitude()+50)/100)))
+				if state := sp.Aircraft[ac.Callsign]; state.MSAW {
+					text.WriteString(fmt.Sprintf("%-14s%03d LA", ac.Callsign, int((ac.Altitude()+50)/100)))
+					if o := state.MSAWObstacle; o != nil {
+						text.WriteString(fmt.Sprintf(" %s %d", o.Type, o.MSL))
+					}
+					text.WriteString("\n")
 					n--
 				}
 			}
@@ -529,6 +618,44 @@ func (sp *STARSPane) drawAlertList(ctx *panes.Context, pw [2]float32, aircraft [
 			}
 		}
 
+		// RWY
+		if !ps.DisableRunwayAlerts {
+			for _, ra := range sp.RunwayAlerts {
+				if n == 0 {
+					break
+				}
+
+				text.WriteString(fmt.Sprintf("%-17s RWY %s\n", ra.Arrival+"*"+ra.Occupant, ra.Runway))
+				n--
+			}
+		}
+
+		// NC: list the previous sector so the controller knows who to call.
+		for _, ac := range noComm {
+			if n == 0 {
+				break
+			}
+			from := sp.Aircraft[ac.Callsign].HandoffAcceptedFrom
+			if ctrl, ok := ctx.ControlClient.Controllers[from]; ok && ctrl != nil {
+				from = ctrl.SectorId
+			}
+			text.WriteString(fmt.Sprintf("%-17s NC %s\n", ac.Callsign, from))
+			n--
+		}
+
+		// TFR
+		if len(sp.TFRs.mapActive) > 0 {
+			for _, ac := range aircraft {
+				if n == 0 {
+					break
+				}
+				if tfr := sp.Aircraft[ac.Callsign].TFR; tfr != "" {
+					text.WriteString(fmt.Sprintf("%-14s%03d TFR %s\n", ac.Callsign, int((ac.Altitude()+50)/100), tfr))
+					n--
+				}
+			}
+		}
+
 		if text.Len() > 0 {
 			td.AddText(text.String(), pw, style)
 		}
@@ -636,6 +763,102 @@ func (sp *STARSPane) drawCRDAStatusList(ctx *panes.Context, pw [2]float32, aircr
 	}
 }
 
+// altimeterStatus records the most recent altimeter setting reported for
+// an airport so that the altimeter list can flag significant changes.
+type altimeterStatus struct {
+	altimeter int // hundredths of an inch of mercury
+	delta     int // change from the previous report
+	changed   time.Time
+}
+
+// How long an altimeter change remains flagged in the altimeter list.
+const altimeterChangeAlertDuration = 15 * time.Minute
+
+// updateAltimeters records changes in the reported altimeter settings;
+// it's called for each frame since METARs may be updated by the server at
+// any time.
+func (sp *STARSPane) updateAltimeters(ctx *panes.Context) {
+	if sp.altimeters == nil {
+		sp.altimeters = make(map[string]*altimeterStatus)
+	}
+
+	for icao, metar := range ctx.ControlClient.METAR {
+		alt, ok := metar.AltimeterHundredths()
+		if !ok {
+			continue
+		}
+		if st, ok := sp.altimeters[icao]; !ok {
+			sp.altimeters[icao] = &altimeterStatus{altimeter: alt}
+		} else if st.altimeter != alt {
+			st.delta = alt - st.altimeter
+			st.altimeter = alt
+			st.changed = ctx.ControlClient.CurrentTime()
+		}
+	}
+}
+
+func (sp *STARSPane) drawAltimeterList(ctx *panes.Context, pw [2]float32, font *renderer.Font,
+	td *renderer.TextDrawBuilder) {
+	sp.updateAltimeters(ctx)
+
+	ps := sp.currentPrefs()
+	if !ps.AltimeterList.Visible {
+		return
+	}
+
+	listStyle := renderer.TextStyle{
+		Font:  font,
+		Color: ps.Brightness.Lists.ScaleRGB(STARSListColor),
+	}
+	alertStyle := renderer.TextStyle{
+		Font:  font,
+		Color: ps.Brightness.Lists.ScaleRGB(STARSTextAlertColor),
+	}
+
+	airports := ps.AltimeterListAirports
+	if len(airports) == 0 {
+		airports = weatherAirports(ctx)
+	}
+	airports = util.FilterSlice(airports, func(ap string) bool { return ctx.ControlClient.METAR[ap] != nil })
+
+	now := ctx.ControlClient.CurrentTime()
+	staleAge := time.Duration(ps.AltimeterList.StaleMinutes) * time.Minute
+
+	pw = td.AddText("ALTIMETER\n", pw, listStyle)
+	if len(airports) > ps.AltimeterList.Lines {
+		pw = td.AddText(fmt.Sprintf("MORE: %d/%d\n", ps.AltimeterList.Lines, len(airports)), pw, listStyle)
+		airports = airports[:ps.AltimeterList.Lines]
+	}
+
+	for _, icao := range airports {
+		metar := ctx.ControlClient.METAR[icao]
+		line := fmt.Sprintf("%-4s ", strings.TrimPrefix(icao, "K"))
+		alert := false
+
+		if alt, ok := metar.AltimeterHundredths(); ok {
+			line += fmt.Sprintf("%02d.%02d", alt/100, alt%100)
+		} else {
+			line += "-----"
+		}
+
+		if obs, ok := metar.ObservationTime(now); ok {
+			line += " " + obs.Format("1504")
+			if staleAge > 0 && now.Sub(obs) > staleAge {
+				line += " STALE"
+				alert = true
+			}
+		}
+
+		if st, ok := sp.altimeters[icao]; ok && !st.changed.IsZero() &&
+			now.Sub(st.changed) < altimeterChangeAlertDuration {
+			line += fmt.Sprintf(" %+03d", st.delta)
+			alert = alert || math.Abs(st.delta) >= ps.AltimeterList.ChangeThreshold
+		}
+
+		pw = td.AddText(line+"\n", pw, util.Select(alert, alertStyle, listStyle))
+	}
+}
+
 func (sp *STARSPane) drawTowerList(ctx *panes.Context, pw [2]float32, airport string, lines int, aircraft []*av.Aircraft,
 	style renderer.TextStyle, td *renderer.TextDrawBuilder) {
 	stripK := func(airport string) string {
@@ -658,7 +881,7 @@ func (sp *STARSPane) drawTowerList(ctx *panes.Context, pw [2]float32, airport st
 			actype = strings.TrimPrefix(actype, "S/")
 			// We'll punt on the chance that two aircraft have the
 			// exact same distance to the airport...
-			m[dist] = fmt.Sprintf("%-7s %s", ac.Callsign, actype)
+			m[dist] = fmt.Sprintf("%-7s %-4s %s", ac.Callsign, actype, ctx.ControlClient.WakeCategory(ac))
 		}
 	}
 
@@ -691,7 +914,7 @@ func (sp *STARSPane) drawSignOnList(ctx *panes.Context, pw [2]float32, style ren
 
 func (sp *STARSPane) drawCoordinationLists(ctx *panes.Context, paneExtent math.Extent2D, transforms ScopeTransformations, cb *renderer.CommandBuffer) {
 	ps := sp.currentPrefs()
-	font := sp.systemFont[ps.CharSize.Lists]
+	font := sp.listFont(ctx)
 	listStyle := renderer.TextStyle{
 		Font:  font,
 		Color: ps.Brightness.Lists.ScaleRGB(STARSListColor),
@@ -760,8 +983,13 @@ func (sp *STARSPane) drawCoordinationLists(ctx *panes.Context, paneExtent math.E
 			// TODO: NO FP if no flight plan
 			text.WriteString("     " + sp.getTabListIndex(ac))
 			text.WriteString(util.Select(ac.Released, "+", " "))
-			text.WriteString(fmt.Sprintf(" %-10s %5s %s %5s %03d\n", ac.Callsign, ac.FlightPlan.BaseType(),
+			text.WriteString(fmt.Sprintf(" %-10s %5s %s %5s %03d", ac.Callsign, ac.FlightPlan.BaseType(),
 				ac.Squawk, trk.SP1, ac.FlightPlan.Altitude/100))
+			// Time remaining in the release window, or EXP if it lapsed.
+			if rel := ac.ReleaseStatus(ctx.ControlClient.CurrentTime()); rel != "" {
+				text.WriteString(" " + rel)
+			}
+			text.WriteString("\n")
 			if !ac.Released && blinkDim {
 				pw = td.AddText(text.String(), pw, dimStyle)
 			} else {
@@ -772,3 +1000,13 @@ func (sp *STARSPane) drawCoordinationLists(ctx *panes.Context, paneExtent math.E
 
 	td.GenerateCommands(cb)
 }
+
+// listFont returns the font to use for the lists; it is one size smaller
+// than the LISTS character size in compact mode.
+func (sp *STARSPane) listFont(ctx *panes.Context) *renderer.Font {
+	size := sp.currentPrefs().CharSize.Lists
+	if ctx.Compact {
+		size = math.Max(0, size-1)
+	}
+	return sp.systemFont[size]
+}
@@ -0,0 +1,229 @@
+// pkg/panes/stars/ministrip.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package stars
+
+import (
+	"fmt"
+	"strings"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/panes"
+	"github.com/mmp/vice/pkg/platform"
+	"github.com/mmp/vice/pkg/renderer"
+	"github.com/mmp/vice/pkg/util"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// The mini strip is a small flight strip drawn next to a selected track
+// with the key flight plan information and buttons for the most common
+// track operations, so that the controller doesn't need to look over at
+// the flight strip bay.
+
+type MiniStripMode int
+
+const (
+	MiniStripOff         MiniStripMode = iota
+	MiniStripSelected                  // shown for the most recently selected track
+	MiniStripMiddleClick               // only shown for tracks selected by middle-clicking
+)
+
+func (m MiniStripMode) String() string {
+	return []string{"Off", "On selection", "On middle-click"}[m]
+}
+
+// showMiniStrip is called when the user selects a track; click gives the
+// mouse button that was used.
+func (sp *STARSPane) showMiniStrip(callsign string, click int) {
+	if sp.MiniStripMode == MiniStripSelected ||
+		(sp.MiniStripMode == MiniStripMiddleClick && click == platform.MouseButtonTertiary) {
+		sp.miniStripCallsign = callsign
+	}
+}
+
+type miniStripButton struct {
+	label   string
+	tooltip string
+	action  func()
+}
+
+func (sp *STARSPane) miniStripButtons(ctx *panes.Context, ac *av.Aircraft) []miniStripButton {
+	var buttons []miniStripButton
+	callsign := ac.Callsign
+	trk := sp.getTrack(ctx, ac)
+	if trk == nil {
+		return nil
+	}
+
+	if trk.HandoffController == ctx.ControlClient.Callsign {
+		buttons = append(buttons, miniStripButton{
+			label:   "ACPT",
+			tooltip: "Accept the handoff",
+			action:  func() { sp.acceptHandoff(ctx, callsign) },
+		})
+	}
+	if trk.TrackOwner == ctx.ControlClient.Callsign {
+		if trk.HandoffController == "" {
+			if ctrl := sp.nextController(ctx, callsign); ctrl != nil && ctrl.Callsign != ctx.ControlClient.Callsign {
+				buttons = append(buttons, miniStripButton{
+					label:   "HO " + ctrl.SectorId,
+					tooltip: "Hand off the track to " + ctrl.Callsign,
+					action: func() {
+						ctx.ControlClient.HandoffTrack(callsign, ctrl.Callsign, nil,
+							func(err error) { sp.displayError(err, ctx) })
+					},
+				})
+			}
+		} else {
+			buttons = append(buttons, miniStripButton{
+				label:   "CNCL",
+				tooltip: "Cancel the handoff",
+				action:  func() { sp.cancelHandoff(ctx, callsign) },
+			})
+		}
+		buttons = append(buttons, miniStripButton{
+			label:   "SP",
+			tooltip: "Set the scratchpad to the text in the preview area",
+			action: func() {
+				if err := sp.setScratchpad(ctx, callsign, strings.TrimSpace(sp.previewAreaInput), false, false); err != nil {
+					sp.displayError(err, ctx)
+				} else {
+					sp.resetInputState()
+				}
+			},
+		})
+		buttons = append(buttons, miniStripButton{
+			label:   "DROP",
+			tooltip: "Drop the track",
+			action:  func() { sp.dropTrack(ctx, callsign) },
+		})
+	} else if trk.TrackOwner == "" {
+		buttons = append(buttons, miniStripButton{
+			label:   "TRK",
+			tooltip: "Initiate control of the track",
+			action:  func() { sp.initiateTrack(ctx, callsign) },
+		})
+	}
+
+	return buttons
+}
+
+// drawMiniStrip draws the mini strip, if there is one, and handles clicks
+// on its buttons; clicks inside the strip aren't passed along to the
+// scope.
+func (sp *STARSPane) drawMiniStrip(ctx *panes.Context, transforms ScopeTransformations, cb *renderer.CommandBuffer) {
+	if sp.miniStripCallsign == "" {
+		return
+	}
+	ac, ok := ctx.ControlClient.Aircraft[sp.miniStripCallsign]
+	state, sok := sp.Aircraft[sp.miniStripCallsign]
+	if !ok || !sok || !state.IsSelected || sp.MiniStripMode == MiniStripOff {
+		sp.miniStripCallsign = ""
+		return
+	}
+
+	ps := sp.currentPrefs()
+	font := sp.systemFont[ps.CharSize.Lists]
+	color := ps.Brightness.Lists.ScaleRGB(STARSListColor)
+
+	// The strip's text: callsign, type, and CWT category; beacon code,
+	// requested altitude, and route; scratchpads.
+	var lines []string
+	fp := ac.FlightPlan
+	line := ac.Callsign
+	if fp != nil {
+		line += " " + fp.TypeWithoutSuffix()
+	}
+	lines = append(lines, line+" "+state.CWTCategory)
+	line = ac.Squawk.String()
+	if fp != nil {
+		line += fmt.Sprintf(" R%03d %s-%s", fp.Altitude/100, fp.DepartureAirport, fp.ArrivalAirport)
+	}
+	lines = append(lines, line)
+	if ac.Scratchpad != "" || ac.SecondaryScratchpad != "" {
+		lines = append(lines, strings.TrimSpace(ac.Scratchpad+" "+ac.SecondaryScratchpad))
+	}
+	text := strings.Join(lines, "\n")
+
+	buttons := sp.miniStripButtons(ctx, ac)
+
+	const pad = 4
+	tw, th := font.BoundText(text, 0)
+	bh := font.Size + 2*pad
+	w := float32(tw) + 2*pad
+	bw := make([]float32, len(buttons))
+	var bwSum float32
+	for i, b := range buttons {
+		x, _ := font.BoundText(b.label, 0)
+		bw[i] = float32(x) + 2*pad
+		bwSum += bw[i]
+	}
+	w = math.Max(w, bwSum+float32(len(buttons)+1)*pad)
+	h := float32(th) + 2*pad + util.Select(len(buttons) > 0, float32(bh)+pad, 0)
+
+	// Offset the strip up and to the right of the track.
+	pac := transforms.WindowFromLatLongP(state.TrackPosition())
+	p0 := math.Add2f(pac, [2]float32{24, 24 + h}) // upper-left
+	// Keep it on the scope
+	p0[0] = math.Min(p0[0], ctx.PaneExtent.Width()-w)
+	p0[1] = math.Min(p0[1], ctx.PaneExtent.Height())
+
+	trid := renderer.GetColoredTrianglesDrawBuilder()
+	defer renderer.ReturnColoredTrianglesDrawBuilder(trid)
+	ld := renderer.GetColoredLinesDrawBuilder()
+	defer renderer.ReturnColoredLinesDrawBuilder(ld)
+	td := renderer.GetTextDrawBuilder()
+	defer renderer.ReturnTextDrawBuilder(td)
+
+	box := func(p0 [2]float32, w, h float32, fill, outline renderer.RGB) {
+		p1 := [2]float32{p0[0] + w, p0[1]}
+		p2 := [2]float32{p0[0] + w, p0[1] - h}
+		p3 := [2]float32{p0[0], p0[1] - h}
+		trid.AddQuad(p0, p1, p2, p3, fill)
+		ld.AddLineLoop(outline, [][2]float32{p0, p1, p2, p3})
+	}
+	inside := func(p0 [2]float32, w, h float32) bool {
+		m := ctx.Mouse
+		return m != nil && m.Pos[0] >= p0[0] && m.Pos[0] < p0[0]+w && m.Pos[1] <= p0[1] && m.Pos[1] > p0[1]-h
+	}
+
+	bg := ps.Brightness.BackgroundContrast.ScaleRGB(STARSBackgroundColor)
+	box(p0, w, h, bg, color)
+	td.AddText(text, math.Add2f(p0, [2]float32{pad, -pad}), renderer.TextStyle{Font: font, Color: color})
+
+	x := p0[0] + pad
+	y := p0[1] - float32(th) - 2*pad
+	for i, b := range buttons {
+		bp := [2]float32{x, y}
+		style := renderer.TextStyle{Font: font, Color: color}
+		fill := bg
+		if inside(bp, bw[i], float32(bh)) {
+			fill = color.Scale(0.4)
+			style.Color = STARSTrackedAircraftColor
+			ctx.Mouse.SetCursor(imgui.MouseCursorHand)
+			imgui.SetTooltip(b.tooltip)
+			if ctx.Mouse.Clicked[platform.MouseButtonPrimary] {
+				b.action()
+			}
+		}
+		box(bp, bw[i], float32(bh), fill, color)
+		td.AddText(b.label, [2]float32{x + pad, y - pad}, style)
+		x += bw[i] + pad
+	}
+
+	if inside(p0, w, h) {
+		// Don't let the scope see clicks on the strip.
+		for i := range ctx.Mouse.Clicked {
+			ctx.Mouse.Clicked[i] = false
+		}
+	}
+
+	transforms.LoadWindowViewingMatrices(cb)
+	trid.GenerateCommands(cb)
+	cb.LineWidth(1, ctx.DPIScale)
+	ld.GenerateCommands(cb)
+	td.GenerateCommands(cb)
+}
@@ -0,0 +1,88 @@
+// pkg/panes/stars/obstacles.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package stars
+
+import (
+	"fmt"
+	"time"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/panes"
+	"github.com/mmp/vice/pkg/renderer"
+)
+
+const (
+	// Obstacles within this distance of the scope center are considered
+	// for display and alerts.
+	obstacleRadius = 100
+	// Obstacles are only drawn when the range is at most this.
+	obstacleDisplayRange = 20
+	// An obstacle alert is issued for aircraft within obstacleAlertRadius
+	// nm of an obstacle, either now or along their track projected
+	// obstacleAlertLookahead into the future, whose current or projected
+	// altitude is less than obstacleAlertClearance feet above it.
+	obstacleAlertRadius    = 1
+	obstacleAlertLookahead = 30 * time.Second
+	obstacleAlertClearance = 500
+)
+
+// obstacleAlert returns the first obstacle that the aircraft's track
+// conflicts with, if any.
+func (sp *STARSPane) obstacleAlert(state *AircraftState, nmPerLongitude float32) (av.Obstacle, bool) {
+	cur, prev := state.track, state.previousTrack
+	dt := cur.Time.Sub(prev.Time).Seconds()
+	if prev.Time.IsZero() || dt <= 0 {
+		return av.Obstacle{}, false
+	}
+
+	// Linearly extrapolate the track.
+	s := float32(obstacleAlertLookahead.Seconds() / dt)
+	p0 := math.LL2NM(cur.Position, nmPerLongitude)
+	p1 := math.Add2f(p0, math.Scale2f(math.Sub2f(p0, math.LL2NM(prev.Position, nmPerLongitude)), s))
+	alt := min(cur.Altitude, cur.Altitude+int(s*float32(cur.Altitude-prev.Altitude)))
+
+	for _, o := range sp.obstacles {
+		if alt >= o.MSL+obstacleAlertClearance {
+			continue
+		}
+		if math.PointSegmentDistance(math.LL2NM(o.Location, nmPerLongitude), p0, p1) < obstacleAlertRadius {
+			return o, true
+		}
+	}
+	return av.Obstacle{}, false
+}
+
+func (sp *STARSPane) drawObstacles(ctx *panes.Context, transforms ScopeTransformations, cb *renderer.CommandBuffer) {
+	ps := sp.currentPrefs()
+	if !ps.DisplayObstacles || ps.Range > obstacleDisplayRange || len(sp.obstacles) == 0 {
+		return
+	}
+
+	ld := renderer.GetLinesDrawBuilder()
+	defer renderer.ReturnLinesDrawBuilder(ld)
+	td := renderer.GetTextDrawBuilder()
+	defer renderer.ReturnTextDrawBuilder(td)
+
+	color := ps.Brightness.Terrain.ScaleRGB(STARSMapColor)
+	style := renderer.TextStyle{Font: sp.systemFont[ps.CharSize.Tools], Color: color}
+
+	// Draw each obstacle as a caret with its MSL height underneath.
+	const sz = 4
+	for _, o := range sp.obstacles {
+		pw := transforms.WindowFromLatLongP(o.Location)
+		if pw[0] < 0 || pw[0] > ctx.PaneExtent.Width() || pw[1] < 0 || pw[1] > ctx.PaneExtent.Height() {
+			continue
+		}
+		ld.AddLine(math.Add2f(pw, [2]float32{-sz, -sz}), math.Add2f(pw, [2]float32{0, sz}))
+		ld.AddLine(math.Add2f(pw, [2]float32{0, sz}), math.Add2f(pw, [2]float32{sz, -sz}))
+		td.AddTextCentered(fmt.Sprintf("%d", o.MSL), math.Add2f(pw, [2]float32{0, -2 * sz}), style)
+	}
+
+	transforms.LoadWindowViewingMatrices(cb)
+	cb.SetRGB(color)
+	ld.GenerateCommands(cb)
+	td.GenerateCommands(cb)
+}
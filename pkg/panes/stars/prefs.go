@@ -5,7 +5,11 @@
 package stars
 
 import (
+	"fmt"
+	"reflect"
 	"slices"
+	"strings"
+	"time"
 
 	av "github.com/mmp/vice/pkg/aviation"
 	"github.com/mmp/vice/pkg/math"
@@ -24,6 +28,23 @@ type PreferenceSet struct {
 	Current  Preferences
 	Selected *int // if non-nil, an index into Saved
 	Saved    [numSavedPreferenceSets]*Preferences
+
+	// Snapshots are quick copies of the current preferences, separate
+	// from the STARS saved preference sets, that make it easy to
+	// experiment with settings and then revert or compare.
+	Snapshots []PreferencesSnapshot
+
+	// Decluttered holds the preferences to restore when presentation
+	// declutter mode is turned off; it is nil when the mode is off.
+	Decluttered *Preferences
+}
+
+const maxPreferencesSnapshots = 10
+
+type PreferencesSnapshot struct {
+	Name  string
+	Time  time.Time
+	Prefs Preferences
 }
 
 func (p *PreferenceSet) Upgrade(from, to int) {
@@ -36,6 +57,12 @@ func (p *PreferenceSet) Upgrade(from, to int) {
 			p.Upgrade(from, to)
 		}
 	}
+	for i := range p.Snapshots {
+		p.Snapshots[i].Prefs.Upgrade(from, to)
+	}
+	if p.Decluttered != nil {
+		p.Decluttered.Upgrade(from, to)
+	}
 }
 
 func (p *PreferenceSet) SetCurrent(cur Preferences, pl platform.Platform, sp *STARSPane) {
@@ -47,6 +74,57 @@ func (p *PreferenceSet) SetCurrent(cur Preferences, pl platform.Platform, sp *ST
 	p.Current.Activate(pl, sp)
 }
 
+// TakeSnapshot saves a copy of the current preferences, discarding the
+// oldest snapshot if there are already too many.
+func (p *PreferenceSet) TakeSnapshot(name string) {
+	if len(p.Snapshots) == maxPreferencesSnapshots {
+		p.Snapshots = p.Snapshots[1:]
+	}
+	p.Snapshots = append(p.Snapshots, PreferencesSnapshot{
+		Name:  name,
+		Time:  time.Now(),
+		Prefs: deep.MustCopy(p.Current),
+	})
+}
+
+// newSnapshotName returns the default name for a new snapshot: "Snapshot
+// n" with the smallest n that isn't already used.
+func (p *PreferenceSet) newSnapshotName() string {
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("Snapshot %d", i)
+		if !slices.ContainsFunc(p.Snapshots, func(s PreferencesSnapshot) bool { return s.Name == name }) {
+			return name
+		}
+	}
+}
+
+// RestoreSnapshot makes the i'th snapshot's preferences current.
+func (p *PreferenceSet) RestoreSnapshot(i int, pl platform.Platform, sp *STARSPane) {
+	p.SetCurrent(p.Snapshots[i].Prefs, pl, sp)
+}
+
+// SwapSnapshot exchanges the current preferences with the i'th snapshot,
+// so that calling it repeatedly toggles between the two for A/B
+// comparisons.
+func (p *PreferenceSet) SwapSnapshot(i int, pl platform.Platform, sp *STARSPane) {
+	cur := p.Current
+	p.SetCurrent(p.Snapshots[i].Prefs, pl, sp)
+	p.Snapshots[i].Prefs = cur
+}
+
+// ToggleDeclutter switches presentation declutter mode on or off. When it
+// is turned on, the current preferences are saved and then modified to
+// hide non-essential symbology; turning it off restores them.
+func (p *PreferenceSet) ToggleDeclutter(pl platform.Platform, sp *STARSPane) {
+	if p.Decluttered != nil {
+		p.SetCurrent(*p.Decluttered, pl, sp)
+		p.Decluttered = nil
+	} else {
+		p.Decluttered = p.Current.Duplicate()
+		p.Current.Declutter()
+	}
+}
+
 // Reset ends up being called when a new Sim is started. It is responsible
 // for resetting all of the preference values in the PreferenceSet that we
 // don't expect to persist on a restart (e.g. quick look positions.)
@@ -130,9 +208,24 @@ type Preferences struct {
 	DisableCAWarnings bool
 	DisableMSAW       bool
 
+	// Runway occupancy alerts are issued when an arrival is within
+	// RunwayAlertDistance nm of the threshold of a runway that another
+	// aircraft is on.
+	DisableRunwayAlerts bool
+	RunwayAlertDistance float32
+
+	// No-comm alerts are issued when an aircraft hasn't checked in
+	// NoCommAlertSeconds after we accepted its handoff.
+	DisableNoCommAlerts bool
+	NoCommAlertSeconds  int
+
 	VideoMapVisible map[int]interface{}
 
 	DisplayRequestedAltitude bool
+
+	// Airports shown in the altimeter list; if empty, the airports from
+	// the SSA list's airport weather are used.
+	AltimeterListAirports []string
 }
 
 // CommonPreferences stores the STARS preference settings that are
@@ -191,6 +284,22 @@ type CommonPreferences struct {
 		History            STARSBrightness
 		Weather            STARSBrightness
 		WxContrast         STARSBrightness
+		Basemap            STARSBrightness
+		Terrain            STARSBrightness // MVA/MIA maps and hillshade
+	}
+
+	DisplayHillshade bool
+	DisplayObstacles bool
+	// Draw extended centerlines for the active arrival runways.
+	DisplayFinals bool
+	// Show and hide the adapted runway flow maps according to the active
+	// runways when a sim starts.
+	AutoRunwayFlowMaps bool
+
+	// Satellite/terrain raster map drawn underneath the video maps.
+	Basemap struct {
+		Visible bool
+		Source  string
 	}
 
 	CharSize struct {
@@ -234,6 +343,7 @@ type CommonPreferences struct {
 	AlertList     BasicSTARSList
 	CoastList     BasicSTARSList
 	SignOnList    BasicSTARSList
+	NoCommList    BasicSTARSList
 	VideoMapsList struct {
 		Position  [2]float32
 		Visible   bool
@@ -242,6 +352,14 @@ type CommonPreferences struct {
 	CRDAStatusList    BasicSTARSList
 	TowerLists        [3]BasicSTARSList
 	CoordinationLists map[string]*CoordinationList
+	AltimeterList     struct {
+		BasicSTARSList
+		// METARs older than this are flagged as stale.
+		StaleMinutes int
+		// Altimeter changes of at least this many hundredths of an
+		// inch of mercury are flagged.
+		ChangeThreshold int
+	}
 }
 
 type BasicSTARSList struct {
@@ -280,7 +398,12 @@ func (p *Preferences) Reset(ss sim.State, sp *STARSPane) {
 		p.CRDA.RunwayPairState = append(p.CRDA.RunwayPairState, state)
 	}
 
-	// Make the scenario's default video maps visible
+	sp.showDefaultVideoMaps(p, ss)
+}
+
+// showDefaultVideoMaps makes the scenario's default video maps the
+// visible ones.
+func (sp *STARSPane) showDefaultVideoMaps(p *Preferences, ss sim.State) {
 	p.VideoMapVisible = make(map[int]interface{})
 	_, defaultVideoMaps := ss.GetVideoMaps()
 	for _, dm := range defaultVideoMaps {
@@ -291,12 +414,66 @@ func (p *Preferences) Reset(ss sim.State, sp *STARSPane) {
 			// lg.Errorf("%s: \"default_map\" not found in \"stars_maps\"", dm)
 		}
 	}
+
+	if p.AutoRunwayFlowMaps {
+		sp.applyRunwayFlowMaps(p, ss)
+	}
+}
+
+// ApplyPositionTemplate shows the position's default video maps and the
+// lists given by the template. Unknown list names are returned.
+func (sp *STARSPane) ApplyPositionTemplate(ss sim.State, t *sim.PositionTemplate) []string {
+	ps := sp.currentPrefs()
+	sp.showDefaultVideoMaps(ps, ss)
+
+	lists := map[string]*bool{
+		"VFR":     &ps.VFRList.Visible,
+		"TAB":     &ps.TABList.Visible,
+		"ALERT":   &ps.AlertList.Visible,
+		"COAST":   &ps.CoastList.Visible,
+		"SIGN ON": &ps.SignOnList.Visible,
+		"NO COMM": &ps.NoCommList.Visible,
+		"MAPS":    &ps.VideoMapsList.Visible,
+		"CRDA":    &ps.CRDAStatusList.Visible,
+		"ALTIM":   &ps.AltimeterList.Visible,
+		"TOWER 1": &ps.TowerLists[0].Visible,
+		"TOWER 2": &ps.TowerLists[1].Visible,
+		"TOWER 3": &ps.TowerLists[2].Visible,
+	}
+	for _, v := range lists {
+		*v = false
+	}
+	var unknown []string
+	for _, name := range t.Lists {
+		if v, ok := lists[strings.ToUpper(name)]; ok {
+			*v = true
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
+
+// applyRunwayFlowMaps shows the adapted video maps for the active runway
+// flow and hides the ones for the other flows.
+func (sp *STARSPane) applyRunwayFlowMaps(p *Preferences, ss sim.State) {
+	show, hide := ss.RunwayFlowMaps()
+	for _, m := range sp.videoMaps {
+		if slices.Contains(show, m.Name) {
+			p.VideoMapVisible[m.Id] = nil
+		} else if slices.Contains(hide, m.Name) {
+			delete(p.VideoMapVisible, m.Id)
+		}
+	}
 }
 
 func makeDefaultPreferences() *Preferences {
 	var prefs Preferences
 
 	prefs.DisplayDCB = true
+	prefs.AutoRunwayFlowMaps = true
+	prefs.RunwayAlertDistance = 2
+	prefs.NoCommAlertSeconds = 60
 	prefs.DCBPosition = dcbPositionTop
 
 	prefs.RangeRingRadius = 5
@@ -343,6 +520,8 @@ func makeDefaultPreferences() *Preferences {
 	prefs.Brightness.History = 60
 	prefs.Brightness.Weather = 30
 	prefs.Brightness.WxContrast = 30
+	prefs.Brightness.Basemap = 30
+	prefs.Brightness.Terrain = 50
 
 	for i := range prefs.DisplayWeatherLevel {
 		prefs.DisplayWeatherLevel[i] = true
@@ -383,6 +562,10 @@ func makeDefaultPreferences() *Preferences {
 	prefs.SignOnList.Position = [2]float32{.9, .9}
 	prefs.SignOnList.Visible = true
 
+	prefs.NoCommList.Position = [2]float32{.8, .45}
+	prefs.NoCommList.Lines = 5
+	prefs.NoCommList.Visible = false
+
 	prefs.VideoMapsList.Position = [2]float32{.85, .5}
 	prefs.VideoMapsList.Visible = false
 
@@ -399,14 +582,61 @@ func makeDefaultPreferences() *Preferences {
 
 	prefs.CoordinationLists = make(map[string]*CoordinationList)
 
+	prefs.AltimeterList.Position = [2]float32{.8, .8}
+	prefs.AltimeterList.Lines = 6
+	prefs.AltimeterList.StaleMinutes = 75
+	prefs.AltimeterList.ChangeThreshold = 5
+
+	prefs.Basemap.Source = basemapSources[0].Name
+
 	return &prefs
 }
 
+// Differences returns the names of the preferences that differ between p
+// and other.
+func (p *Preferences) Differences(other *Preferences) []string {
+	var diffs []string
+	var visit func(a, b reflect.Value)
+	visit = func(a, b reflect.Value) {
+		for i := 0; i < a.NumField(); i++ {
+			f := a.Type().Field(i)
+			if f.Anonymous && f.Type.Kind() == reflect.Struct {
+				visit(a.Field(i), b.Field(i))
+			} else if f.IsExported() && !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+				diffs = append(diffs, f.Name)
+			}
+		}
+	}
+	visit(reflect.ValueOf(p).Elem(), reflect.ValueOf(other).Elem())
+	return diffs
+}
+
 func (p *Preferences) Duplicate() *Preferences {
 	c := deep.MustCopy(*p)
 	return &c
 }
 
+// Declutter modifies the preferences for screenshots, streaming, and
+// projection: history, range rings, the compass, the DCB, and the lists
+// are hidden and datablocks and position symbols are drawn larger.
+func (p *Preferences) Declutter() {
+	p.RadarTrackHistory = 0
+	p.Brightness.RangeRings = 0
+	p.Brightness.Compass = 0
+	p.DisplayDCB = false
+
+	p.SSAList.Filter = Preferences{}.SSAList.Filter
+	for _, l := range []*BasicSTARSList{&p.VFRList, &p.TABList, &p.AlertList, &p.CoastList, &p.SignOnList,
+		&p.NoCommList, &p.CRDAStatusList, &p.TowerLists[0], &p.TowerLists[1], &p.TowerLists[2], &p.AltimeterList.BasicSTARSList} {
+		l.Visible = false
+	}
+	p.VideoMapsList.Visible = false
+	p.DisplayEmptyCoordinationLists = false
+
+	p.CharSize.Datablocks = math.Min(p.CharSize.Datablocks+2, 5)
+	p.CharSize.PositionSymbols = math.Min(p.CharSize.PositionSymbols+1, 5)
+}
+
 func (p *Preferences) Activate(pl platform.Platform, sp *STARSPane) {
 	pl.SetAudioVolume(p.AudioVolume)
 
@@ -533,6 +763,32 @@ func (ps *Preferences) Upgrade(from, to int) {
 
 		ps.RangeRingsUserCenter = ps.RangeRingsCenter != ps.Center
 	}
+	if from < 29 {
+		ps.AltimeterList.Position = [2]float32{.8, .8}
+		ps.AltimeterList.Lines = 6
+		ps.AltimeterList.StaleMinutes = 75
+		ps.AltimeterList.ChangeThreshold = 5
+	}
+	if from < 30 {
+		ps.Brightness.Basemap = 30
+		ps.Basemap.Source = basemapSources[0].Name
+	}
+	if from < 31 {
+		ps.Brightness.Terrain = 50
+	}
+	if from < 32 {
+		ps.RunwayAlertDistance = 2
+		for len(ps.AudioEffectEnabled) < AudioNumTypes {
+			ps.AudioEffectEnabled = append(ps.AudioEffectEnabled, true)
+		}
+	}
+	if from < 33 {
+		ps.NoCommList.Position = [2]float32{.8, .45}
+		ps.NoCommList.Lines = 5
+	}
+	if from < 34 {
+		ps.NoCommAlertSeconds = 60
+	}
 }
 
 func (sp *STARSPane) initPrefsForLoadedSim(ss sim.State, pl platform.Platform) {
@@ -0,0 +1,97 @@
+// pkg/panes/stars/prefs_test.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package stars
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/mmp/vice/pkg/platform"
+	"github.com/mmp/vice/pkg/sim"
+)
+
+func TestApplyPositionTemplateLists(t *testing.T) {
+	sp := &STARSPane{prefSet: &PreferenceSet{Current: *makeDefaultPreferences()}}
+
+	// Every list that scenario validation accepts must be known here.
+	all := &sim.PositionTemplate{Lists: sim.PositionTemplateLists}
+	if unknown := sp.ApplyPositionTemplate(sim.State{}, all); len(unknown) > 0 {
+		t.Errorf("lists allowed in position templates aren't handled: %v", unknown)
+	}
+
+	ps := sp.currentPrefs()
+	if unknown := sp.ApplyPositionTemplate(sim.State{}, &sim.PositionTemplate{Lists: []string{"coast"}}); len(unknown) > 0 {
+		t.Errorf("unexpected unknown lists %v", unknown)
+	}
+	if !ps.CoastList.Visible || ps.VFRList.Visible || ps.TowerLists[0].Visible {
+		t.Errorf("expected only the coast list to be visible")
+	}
+}
+
+// testPlatform records the audio volume that preferences set.
+type testPlatform struct {
+	platform.Platform
+	volume int
+}
+
+func (p *testPlatform) SetAudioVolume(vol int) { p.volume = vol }
+
+func TestPreferenceSnapshots(t *testing.T) {
+	ps := &PreferenceSet{Current: *makeDefaultPreferences()}
+	pl := &testPlatform{}
+
+	ps.Current.Range, ps.Current.AudioVolume = 40, 3
+	ps.TakeSnapshot(ps.newSnapshotName())
+	ps.Current.Range, ps.Current.AudioVolume = 60, 7
+	ps.Current.VideoMapVisible[100] = nil
+	if snap := ps.Snapshots[0]; snap.Name != "Snapshot 1" || snap.Prefs.Range != 40 {
+		t.Errorf("unexpected snapshot %q with range %f", snap.Name, snap.Prefs.Range)
+	}
+	if _, ok := ps.Snapshots[0].Prefs.VideoMapVisible[100]; ok {
+		t.Errorf("snapshot shares maps with the current preferences")
+	}
+
+	// Swapping repeatedly toggles between the two.
+	ps.SwapSnapshot(0, pl, nil)
+	if ps.Current.Range != 40 || ps.Snapshots[0].Prefs.Range != 60 || pl.volume != 3 {
+		t.Errorf("swap: current range %f, snapshot range %f, volume %d", ps.Current.Range,
+			ps.Snapshots[0].Prefs.Range, pl.volume)
+	}
+	ps.SwapSnapshot(0, pl, nil)
+	if ps.Current.Range != 60 || ps.Snapshots[0].Prefs.Range != 40 || pl.volume != 7 {
+		t.Errorf("second swap: current range %f, snapshot range %f, volume %d", ps.Current.Range,
+			ps.Snapshots[0].Prefs.Range, pl.volume)
+	}
+
+	ps.RestoreSnapshot(0, pl, nil)
+	if ps.Current.Range != 40 || pl.volume != 3 {
+		t.Errorf("restore: current range %f, volume %d", ps.Current.Range, pl.volume)
+	}
+	ps.Current.VideoMapVisible[200] = nil
+	if _, ok := ps.Snapshots[0].Prefs.VideoMapVisible[200]; ok {
+		t.Errorf("restored preferences share maps with the snapshot")
+	}
+
+	// New snapshots get the first unused name.
+	ps.TakeSnapshot(ps.newSnapshotName())
+	ps.TakeSnapshot(ps.newSnapshotName())
+	ps.Snapshots = slices.Delete(ps.Snapshots, 1, 2)
+	if name := ps.newSnapshotName(); name != "Snapshot 2" {
+		t.Errorf("expected \"Snapshot 2\" after deleting it, got %q", name)
+	}
+	ps.TakeSnapshot(ps.newSnapshotName())
+	if name := ps.newSnapshotName(); name != "Snapshot 4" {
+		t.Errorf("expected \"Snapshot 4\", got %q", name)
+	}
+
+	// The oldest snapshots are discarded when there are too many.
+	for range maxPreferencesSnapshots {
+		ps.TakeSnapshot(ps.newSnapshotName())
+	}
+	if len(ps.Snapshots) != maxPreferencesSnapshots || ps.Snapshots[0].Name == "Snapshot 1" {
+		t.Errorf("expected %d snapshots without the first, got %d starting with %q", maxPreferencesSnapshots,
+			len(ps.Snapshots), ps.Snapshots[0].Name)
+	}
+}
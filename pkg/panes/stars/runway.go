@@ -0,0 +1,136 @@
+// pkg/panes/stars/runway.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package stars
+
+import (
+	"slices"
+	"time"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/panes"
+)
+
+const (
+	// Aircraft within this distance of a runway's centerline and below
+	// runwayOccupancyAGL are considered to be on the runway.
+	runwayHalfWidth    = 0.05 // nm, ~300'
+	runwayOccupancyAGL = 100  // feet
+)
+
+// RunwayOccupancyAlert records an arrival that is inside the alert
+// distance from an active arrival runway's threshold while another
+// aircraft is on that runway.
+type RunwayOccupancyAlert struct {
+	Arrival, Occupant string
+	Airport, Runway   string
+	Acknowledged      bool
+	SoundEnd          time.Time
+}
+
+func (sp *STARSPane) updateRunwayAlerts(ctx *panes.Context, aircraft []*av.Aircraft) {
+	ps := sp.currentPrefs()
+
+	var current []RunwayOccupancyAlert
+	if !ps.DisableRunwayAlerts {
+		current = sp.findRunwayOccupancyConflicts(ctx, aircraft, ps.RunwayAlertDistance)
+	}
+
+	same := func(a, b RunwayOccupancyAlert) bool {
+		return a.Arrival == b.Arrival && a.Occupant == b.Occupant && a.Airport == b.Airport && a.Runway == b.Runway
+	}
+
+	// Remove ones that no longer apply
+	var alerts []RunwayOccupancyAlert
+	for _, ra := range sp.RunwayAlerts {
+		if slices.ContainsFunc(current, func(c RunwayOccupancyAlert) bool { return same(c, ra) }) {
+			alerts = append(alerts, ra)
+		} else if !ra.Acknowledged {
+			sp.logAlertResponse("RWY", ra.Arrival+"/"+ra.Occupant, ra.SoundEnd.Add(-AlertAudioDuration),
+				ctx.Now, false)
+		}
+	}
+
+	// And add new ones, keeping them in the order they were detected.
+	for _, c := range current {
+		if !slices.ContainsFunc(alerts, func(ra RunwayOccupancyAlert) bool { return same(c, ra) }) {
+			c.SoundEnd = ctx.Now.Add(AlertAudioDuration)
+			alerts = append(alerts, c)
+		}
+	}
+	sp.RunwayAlerts = alerts
+}
+
+// findRunwayOccupancyConflicts returns an alert for each arrival that is
+// within dist nm of an active arrival runway's threshold while another
+// aircraft is on the runway.
+func (sp *STARSPane) findRunwayOccupancyConflicts(ctx *panes.Context, aircraft []*av.Aircraft,
+	dist float32) []RunwayOccupancyAlert {
+	var conflicts []RunwayOccupancyAlert
+	nmPerLongitude := ctx.ControlClient.NmPerLongitude
+
+	for _, ar := range ctx.ControlClient.ArrivalRunways {
+		rwy, ok := av.LookupRunway(ar.Airport, ar.Runway)
+		if !ok {
+			continue
+		}
+		opp, ok := av.LookupOppositeRunway(ar.Airport, ar.Runway)
+		if !ok {
+			continue
+		}
+		ap, ok := av.DB.Airports[ar.Airport]
+		if !ok {
+			continue
+		}
+
+		thr := math.LL2NM(rwy.Threshold, nmPerLongitude)
+		end := math.LL2NM(opp.Threshold, nmPerLongitude)
+
+		onRunway := func(ac *av.Aircraft) bool {
+			return ac.Altitude() < float32(ap.Elevation+runwayOccupancyAGL) &&
+				math.PointSegmentDistance(math.LL2NM(ac.Position(), nmPerLongitude), thr, end) < runwayHalfWidth
+		}
+		approaching := func(ac *av.Aircraft) bool {
+			appr := ac.Nav.Approach.Assigned
+			if appr == nil || appr.Runway != ar.Runway || ac.FlightPlan == nil ||
+				ac.FlightPlan.ArrivalAirport != ar.Airport || !ac.IsAirborne() || onRunway(ac) {
+				return false
+			}
+			p := math.LL2NM(ac.Position(), nmPerLongitude)
+			// Only consider aircraft short of the threshold.
+			return math.Distance2f(p, thr) < dist && math.Dot(math.Sub2f(p, thr), math.Sub2f(thr, end)) > 0
+		}
+
+		for _, arr := range aircraft {
+			if !approaching(arr) {
+				continue
+			}
+			for _, occ := range aircraft {
+				if occ != arr && onRunway(occ) {
+					conflicts = append(conflicts, RunwayOccupancyAlert{
+						Arrival:  arr.Callsign,
+						Occupant: occ.Callsign,
+						Airport:  ar.Airport,
+						Runway:   ar.Runway,
+					})
+				}
+			}
+		}
+	}
+	return conflicts
+}
+
+// runwayAlertAircraft returns true if the aircraft is involved in a runway
+// occupancy alert and, if so, whether the alert has been acknowledged.
+func (sp *STARSPane) runwayAlertAircraft(callsign string) (alert bool, acknowledged bool) {
+	acknowledged = true
+	for _, ra := range sp.RunwayAlerts {
+		if ra.Arrival == callsign || ra.Occupant == callsign {
+			alert = true
+			acknowledged = acknowledged && ra.Acknowledged
+		}
+	}
+	return
+}
@@ -5,13 +5,14 @@
 package stars
 
 import (
-	"encoding/json"
 	"fmt"
 	"image"
 	"image/color"
 	"image/gif"
 	"image/png"
 	"os"
+	"path/filepath"
+	"reflect"
 	"slices"
 	"sort"
 	"strconv"
@@ -27,6 +28,7 @@ import (
 	"github.com/mmp/vice/pkg/sim"
 	"github.com/mmp/vice/pkg/util"
 
+	"github.com/brunoga/deep"
 	"github.com/mmp/imgui-go/v4"
 	"github.com/tosone/minimp3"
 )
@@ -86,6 +88,12 @@ type STARSPane struct {
 	systemMaps map[int]av.VideoMap
 
 	weatherRadar WeatherRadar
+	basemap      Basemap
+	hillshade    Basemap
+
+	TFRs TFRs
+
+	esImport esImporter
 
 	// Which weather history snapshot to draw: this is always 0 unless the
 	// 'display weather history' command was entered.
@@ -106,6 +114,12 @@ type STARSPane struct {
 	RestorePreferences       *Preferences
 	RestorePreferencesNumber *int
 
+	// Differences between each of the preference snapshots and
+	// snapshotDiffsPrefs, cached for the snapshots UI; nil if they need
+	// to be recomputed.
+	snapshotDiffs      [][]string
+	snapshotDiffsPrefs Preferences
+
 	// All of the aircraft in the world, each with additional information
 	// carried along in an STARSAircraftState.
 	Aircraft map[string]*AircraftState
@@ -121,6 +135,11 @@ type STARSPane struct {
 	AutoTrackDepartures bool `json:"autotrack_departures"`
 	LockDisplay         bool
 
+	// ModelTrackSources enables the modeling of radar, ADS-B, and
+	// multilateration tracks; see tracksource.go.
+	ModelTrackSources bool
+	adsbOutage        bool
+
 	// callsign -> controller id
 	InboundPointOuts  map[string]string
 	OutboundPointOuts map[string]string
@@ -139,11 +158,45 @@ type STARSPane struct {
 
 	CAAircraft []CAAircraft
 
+	RunwayAlerts []RunwayOccupancyAlert
+
+	Annotations      []sim.ScopeAnnotation
+	ShareAnnotations bool
+	annotationTool   annotationTool
+	// Annotations shared by other controllers; they only last for the
+	// session and so aren't saved with the user's.
+	receivedAnnotations []sim.ScopeAnnotation
+
 	// For CRDA
 	ConvergingRunways []STARSConvergingRunways
 
 	// Various UI state
 	FlipNumericKeypad bool
+	// If set, the mouse wheel zooms about the scope center rather than
+	// the cursor position.
+	WheelZoomAtCenter bool
+	ScopeCursor       ScopeCursor
+	// If non-zero, the cursor is hidden when the mouse hasn't moved over
+	// the scope for this many seconds.
+	CursorHideSeconds int
+	// When the mini strip is shown next to selected tracks and the track
+	// it is currently shown for, if any.
+	MiniStripMode     MiniStripMode
+	miniStripCallsign string
+	// If non-zero, CA and MSAW alerts that have been inhibited, either
+	// globally or for individual aircraft, are automatically re-enabled
+	// after this many minutes.
+	AlertInhibitMinutes int
+
+	// When each currently-inhibited alert was inhibited; keys are "CA"
+	// or "MSAW", optionally followed by a space and a callsign.
+	alertInhibitTimes map[string]time.Time
+	// If non-zero, the audio for an unacknowledged alert is repeated
+	// once it has been ignored for this many seconds.
+	AlertEscalationSeconds int
+
+	// Acknowledged and expired alerts during the current session.
+	alertResponses []AlertResponse
 
 	scopeClickHandler   func(pw [2]float32, transforms ScopeTransformations) CommandStatus
 	activeDCBMenu       int
@@ -171,11 +224,24 @@ type STARSPane struct {
 	wipSignificantPoint *math.Point2LL
 
 	audioEffects     map[AudioType]int // to handle from Platform.AddPCM()
+	audioPlatform    platform.Platform // for stopping audio in Deactivate()
 	testAudioEndTime time.Time
 
 	highlightedLocation        math.Point2LL
 	highlightedLocationEndTime time.Time
 
+	// For hiding the cursor when the mouse is idle and for briefly
+	// highlighting its location on request.
+	lastMousePos    [2]float32
+	lastMouseMove   time.Time
+	findCursorStart time.Time
+
+	// Charted obstacles near the facility.
+	obstacles []av.Obstacle
+
+	// Most recently reported altimeter settings, indexed by airport.
+	altimeters map[string]*altimeterStatus
+
 	// Built-in screenshots / video captures
 	capture struct {
 		enabled          bool
@@ -199,11 +265,7 @@ type STARSPane struct {
 }
 
 func init() {
-	panes.RegisterUnmarshalPane("STARSPane", func(d []byte) (panes.Pane, error) {
-		var p STARSPane
-		err := json.Unmarshal(d, &p)
-		return &p, err
-	})
+	panes.RegisterPaneType("STARSPane", func() panes.Pane { return &STARSPane{} })
 }
 
 type AudioType int
@@ -218,6 +280,7 @@ const (
 	AudioInboundHandoff
 	AudioCommandError
 	AudioHandoffAccepted
+	AudioRunwayOccupancy
 	AudioNumTypes
 )
 
@@ -231,6 +294,7 @@ func (ae AudioType) String() string {
 		"Inbound Handoff",
 		"Command Error",
 		"Handoff Accepted",
+		"Runway Occupancy",
 	}[ae]
 }
 
@@ -328,6 +392,12 @@ func (sp *STARSPane) DisplayName() string { return "STARS" }
 
 func (sp *STARSPane) Hide() bool { return false }
 
+// CenterOn moves the scope's current center to the given point; the
+// default center is unchanged.
+func (sp *STARSPane) CenterOn(p math.Point2LL) {
+	sp.currentPrefs().CurrentCenter = p
+}
+
 func (sp *STARSPane) Activate(r renderer.Renderer, p platform.Platform, eventStream *sim.EventStream, lg *log.Logger) {
 	if sp.InboundPointOuts == nil {
 		sp.InboundPointOuts = make(map[string]string)
@@ -347,6 +417,7 @@ func (sp *STARSPane) Activate(r renderer.Renderer, p platform.Platform, eventStr
 
 	sp.initializeFonts(r, p)
 	sp.initializeAudio(p, lg)
+	sp.audioPlatform = p
 
 	if sp.Aircraft == nil {
 		sp.Aircraft = make(map[string]*AircraftState)
@@ -358,6 +429,8 @@ func (sp *STARSPane) Activate(r renderer.Renderer, p platform.Platform, eventStr
 	sp.events = eventStream.Subscribe()
 
 	sp.weatherRadar.Activate(r, lg)
+	sp.basemap.Activate(lg)
+	sp.hillshade.Activate(lg)
 
 	sp.lastTrackUpdate = time.Time{} // force immediate update at start
 	sp.lastHistoryTrackUpdate = time.Time{}
@@ -365,6 +438,24 @@ func (sp *STARSPane) Activate(r renderer.Renderer, p platform.Platform, eventStr
 	sp.capture.enabled = os.Getenv("VICE_CAPTURE") != ""
 }
 
+func (sp *STARSPane) Deactivate() {
+	sp.events.Unsubscribe()
+	sp.events = nil
+
+	sp.weatherRadar.Deactivate()
+	sp.basemap.Deactivate()
+	sp.hillshade.Deactivate()
+
+	// Don't leave alerts sounding after the pane is gone.
+	if sp.audioPlatform != nil {
+		for _, effect := range sp.audioEffects {
+			sp.audioPlatform.StopPlayAudio(effect)
+		}
+		sp.testAudioEndTime = time.Time{}
+		sp.audioPlatform = nil
+	}
+}
+
 func (sp *STARSPane) LoadedSim(ss sim.State, pl platform.Platform, lg *log.Logger) {
 	sp.initPrefsForLoadedSim(ss, pl)
 
@@ -372,6 +463,7 @@ func (sp *STARSPane) LoadedSim(ss sim.State, pl platform.Platform, lg *log.Logge
 
 	sp.makeMaps(ss, lg)
 	sp.makeSignificantPoints(ss)
+	sp.obstacles = av.DB.ObstaclesNear(ss.Center, obstacleRadius)
 }
 
 func (sp *STARSPane) ResetSim(ss sim.State, pl platform.Platform, lg *log.Logger) {
@@ -393,12 +485,14 @@ func (sp *STARSPane) ResetSim(ss sim.State, pl platform.Platform, lg *log.Logger
 		sp.TabListAircraft[i] = ""
 	}
 	sp.TabListSearchStart = 0
+	sp.alertResponses = nil
 
 	// Update maps before resetting the prefs since we may rewrite some map
 	// ids and we want to use the right ones when we're enabling the
 	// default maps.
 	sp.makeMaps(ss, lg)
 	sp.makeSignificantPoints(ss)
+	sp.obstacles = av.DB.ObstaclesNear(ss.Center, obstacleRadius)
 
 	sp.resetPrefsForNewSim(ss, pl)
 
@@ -406,6 +500,7 @@ func (sp *STARSPane) ResetSim(ss sim.State, pl platform.Platform, lg *log.Logger
 
 	sp.lastTrackUpdate = time.Time{} // force update
 	sp.lastHistoryTrackUpdate = time.Time{}
+	sp.altimeters = nil
 }
 
 func (sp *STARSPane) makeMaps(ss sim.State, lg *log.Logger) {
@@ -464,7 +559,7 @@ func (sp *STARSPane) makeMaps(ss sim.State, lg *log.Logger) {
 	mvas := av.VideoMap{
 		Label: ss.TRACON + " MVA",
 		Name:  "ALL MINIMUM VECTORING ALTITUDES",
-		Id:    701,
+		Id:    mvaMapId,
 	}
 	ld := renderer.GetLinesDrawBuilder()
 	for _, mva := range av.DB.MVAs[ss.TRACON] {
@@ -476,6 +571,33 @@ func (sp *STARSPane) makeMaps(ss sim.State, lg *log.Logger) {
 	renderer.ReturnLinesDrawBuilder(ld)
 	sp.systemMaps[mvas.Id] = fixupId(mvas)
 
+	// MIAs
+	mias := av.VideoMap{
+		Label: ss.TRACON + " MIA",
+		Name:  "ALL MINIMUM IFR ALTITUDES",
+		Id:    miaMapId,
+	}
+	ld = renderer.GetLinesDrawBuilder()
+	for _, vol := range ss.MIAs() {
+		var center math.Point2LL
+		switch vol.Type {
+		case av.AirspaceVolumePolygon:
+			ld.AddLineLoop(util.MapSlice(vol.Vertices, func(p math.Point2LL) [2]float32 { return p }))
+			center = math.Extent2DFromPoints(util.MapSlice(vol.Vertices, func(p math.Point2LL) [2]float32 { return p })).Center()
+		case av.AirspaceVolumeCircle:
+			ld.AddLatLongCircle(vol.Center, ss.NmPerLongitude, vol.Radius, 360)
+			center = vol.Center
+		}
+		ld.AddNumber(center, 0.005, fmt.Sprintf("%d", vol.Floor/100))
+	}
+	ld.GenerateCommands(&mias.CommandBuffer)
+	renderer.ReturnLinesDrawBuilder(ld)
+	sp.systemMaps[mias.Id] = fixupId(mias)
+
+	// TFRs
+	sp.TFRs.mapActive = sp.TFRs.activeNames(ss.SimTime)
+	sp.systemMaps[tfrMapId] = fixupId(makeTFRMap(sp.TFRs.active(ss.SimTime)))
+
 	// Radar maps
 	radarIndex := 801
 	for _, name := range util.SortedMapKeys(ss.RadarSites) {
@@ -530,8 +652,117 @@ func (sp *STARSPane) DrawUI(p platform.Platform, config *platform.Config) {
 
 	imgui.Checkbox("Lock display", &sp.LockDisplay)
 
+	imgui.Checkbox("Model track sources", &sp.ModelTrackSources)
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Radar-only tracks are drawn as boxes and updated every five seconds; ADS-B tracks\n" +
+			"are drawn as filled symbols and multilateration tracks near airports as outlines,\n" +
+			"both updated every second. Only applies in FUSED mode.")
+	}
+	if sp.ModelTrackSources {
+		imgui.SameLine()
+		imgui.Checkbox("Simulate ADS-B outage", &sp.adsbOutage)
+	}
+
 	imgui.Checkbox("Invert numeric keypad", &sp.FlipNumericKeypad)
 
+	if imgui.BeginCombo("Scope cursor", sp.ScopeCursor.String()) {
+		for _, c := range []ScopeCursor{ScopeCursorSTARS, ScopeCursorCrosshair, ScopeCursorSystem} {
+			if imgui.SelectableV(c.String(), c == sp.ScopeCursor, 0, imgui.Vec2{}) {
+				sp.ScopeCursor = c
+			}
+		}
+		imgui.EndCombo()
+	}
+	hide := int32(sp.CursorHideSeconds)
+	imgui.SliderIntV("Hide idle cursor after (seconds)", &hide, 0, 60, util.Select(hide == 0, "Never", "%d"), 0)
+	sp.CursorHideSeconds = int(hide)
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Press F12 with the scope focused to briefly highlight the cursor's location.")
+	}
+
+	if imgui.BeginCombo("Mini strip", sp.MiniStripMode.String()) {
+		for _, m := range []MiniStripMode{MiniStripOff, MiniStripSelected, MiniStripMiddleClick} {
+			if imgui.SelectableV(m.String(), m == sp.MiniStripMode, 0, imgui.Vec2{}) {
+				sp.MiniStripMode = m
+			}
+		}
+		imgui.EndCombo()
+	}
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Shows flight plan information and buttons for common track operations next to\n" +
+			"the selected track.")
+	}
+	imgui.Checkbox("Mouse wheel zooms about scope center", &sp.WheelZoomAtCenter)
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Otherwise the scope zooms about the cursor position. Hold shift for fine zoom " +
+			"and control for coarse zoom; control-+ and control-- step through the standard ranges.")
+	}
+
+	inhibit := int32(sp.AlertInhibitMinutes)
+	imgui.SliderIntV("Re-enable inhibited CA/MSAW after (minutes)", &inhibit, 0, 60,
+		util.Select(inhibit == 0, "Never", "%d"), 0)
+	sp.AlertInhibitMinutes = int(inhibit)
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Inhibited alerts are listed in the SSA so that they aren't forgotten.")
+	}
+
+	stale := int32(ps.AltimeterList.StaleMinutes)
+	imgui.SliderInt("Altimeter list stale METAR age (minutes)", &stale, 30, 180)
+	ps.AltimeterList.StaleMinutes = int(stale)
+	change := int32(ps.AltimeterList.ChangeThreshold)
+	imgui.SliderInt("Altimeter list change alert (hundredths inHg)", &change, 1, 20)
+	ps.AltimeterList.ChangeThreshold = int(change)
+
+	sp.drawBasemapUI()
+
+	imgui.Checkbox("Draw extended centerlines for active arrival runways", &ps.DisplayFinals)
+	imgui.Checkbox("Select video maps for the runway configuration", &ps.AutoRunwayFlowMaps)
+
+	enableRunwayAlerts := !ps.DisableRunwayAlerts
+	if imgui.Checkbox("Runway occupancy alerts", &enableRunwayAlerts) {
+		ps.DisableRunwayAlerts = !enableRunwayAlerts
+	}
+	if enableRunwayAlerts {
+		imgui.SliderFloatV("Runway occupancy alert distance (nm)", &ps.RunwayAlertDistance, 0.5, 5, "%.1f", 0)
+	}
+
+	enableNoCommAlerts := !ps.DisableNoCommAlerts
+	if imgui.Checkbox("Alert when a handed-off aircraft doesn't check in", &enableNoCommAlerts) {
+		ps.DisableNoCommAlerts = !enableNoCommAlerts
+	}
+	if enableNoCommAlerts {
+		interval := int32(ps.NoCommAlertSeconds)
+		imgui.SliderIntV("No-comm alert interval (seconds)", &interval, 15, 300, "%d", 0)
+		ps.NoCommAlertSeconds = int(interval)
+	}
+
+	declutter := sp.prefSet.Decluttered != nil
+	if imgui.Checkbox("Presentation declutter", &declutter) {
+		sp.prefSet.ToggleDeclutter(p, sp)
+	}
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Hide history, range rings, the DCB, and lists and enlarge datablocks for screenshots,\n" +
+			"streaming, and projection. The previous settings are restored when this is turned off.")
+	}
+
+	if imgui.CollapsingHeader("Preference Snapshots") {
+		sp.drawSnapshotsUI(p)
+	}
+
+	sp.TFRs.DrawUI()
+
+	if imgui.CollapsingHeader("Scope Annotations") {
+		sp.drawAnnotationsUI()
+	}
+
+	if imgui.CollapsingHeader("Import EuroScope Settings") {
+		sp.drawEuroScopeImportUI()
+	}
+
+	if imgui.CollapsingHeader("Alert Response Times") {
+		sp.drawAlertResponsesUI()
+	}
+
 	imgui.Checkbox("Enable additional sound effects", &config.AudioEnabled)
 
 	if !config.AudioEnabled {
@@ -573,7 +804,9 @@ func (sp *STARSPane) Upgrade(from, to int) {
 
 func (sp *STARSPane) Draw(ctx *panes.Context, cb *renderer.CommandBuffer) {
 	sp.processEvents(ctx)
+	sp.updateTFRs(ctx)
 	sp.updateRadarTracks(ctx)
+	sp.updateAlertInhibits(ctx)
 	sp.autoReleaseDepartures(ctx)
 
 	ps := sp.currentPrefs()
@@ -596,11 +829,21 @@ func (sp *STARSPane) Draw(ctx *panes.Context, cb *renderer.CommandBuffer) {
 		cb.SetScissorBounds(scopeExtent, ctx.Platform.FramebufferSize()[1]/ctx.Platform.DisplaySize()[1])
 	}
 
+	// Map imagery is skipped when rendering is degraded.
+	if ps.Basemap.Visible && !ctx.Degraded {
+		sp.basemap.Draw(ctx, getBasemapSource(ps.Basemap.Source), ps.Brightness.Basemap, transforms, cb)
+	}
+	if ps.DisplayHillshade && !ctx.Degraded {
+		sp.hillshade.Draw(ctx, hillshadeSource, ps.Brightness.Terrain, transforms, cb)
+	}
+
 	sp.drawWX(ctx, transforms, cb)
 
 	sp.drawRangeRings(ctx, transforms, cb)
 
 	sp.drawVideoMaps(ctx, transforms, cb)
+	sp.drawObstacles(ctx, transforms, cb)
+	sp.drawFinals(ctx, transforms, cb)
 
 	sp.drawScenarioRoutes(ctx, transforms, sp.systemFont[ps.CharSize.Tools],
 		ps.Brightness.Lists.ScaleRGB(STARSListColor), cb)
@@ -630,6 +873,8 @@ func (sp *STARSPane) Draw(ctx *panes.Context, cb *renderer.CommandBuffer) {
 	sp.drawRBLs(aircraft, ctx, transforms, cb)
 	sp.drawMinSep(ctx, transforms, cb)
 	sp.drawAirspace(ctx, transforms, cb)
+	sp.drawTFRInfo(ctx, transforms, cb)
+	sp.drawAnnotations(ctx, transforms, cb)
 
 	sp.drawHighlighted(ctx, transforms, cb)
 
@@ -639,6 +884,8 @@ func (sp *STARSPane) Draw(ctx *panes.Context, cb *renderer.CommandBuffer) {
 
 	ghosts := sp.getGhostAircraft(aircraft, ctx)
 	sp.drawGhosts(ghosts, ctx, transforms, cb)
+	sp.drawMiniStrip(ctx, transforms, cb)
+	sp.consumeAnnotationMouseEvents(ctx, transforms)
 	sp.consumeMouseEvents(ctx, ghosts, transforms, cb)
 	if ctx.Mouse != nil {
 		sp.drawMouseCursor(ctx, scopeExtent, transforms, cb)
@@ -703,6 +950,9 @@ func (sp *STARSPane) drawVideoMaps(ctx *panes.Context, transforms ScopeTransform
 		if vm.Group == 1 {
 			color = ps.Brightness.VideoGroupB.ScaleRGB(STARSMapColor)
 		}
+		if isTerrainMap(vm) {
+			color = ps.Brightness.Terrain.ScaleRGB(STARSMapColor)
+		}
 		cb.SetRGB(color)
 		cb.Call(vm.CommandBuffer)
 	}
@@ -741,10 +991,103 @@ func (sp *STARSPane) drawCRDARegions(ctx *panes.Context, transforms ScopeTransfo
 	}
 }
 
+func (sp *STARSPane) drawSnapshotsUI(p platform.Platform) {
+	ps := sp.prefSet
+	if imgui.Button("Take snapshot") {
+		ps.TakeSnapshot(ps.newSnapshotName())
+		sp.snapshotDiffs = nil
+	}
+	if len(ps.Snapshots) == 0 {
+		return
+	}
+
+	// Differences walks all of the preferences, so only call it when
+	// the snapshots or the current preferences have changed.
+	if sp.snapshotDiffs == nil || !reflect.DeepEqual(sp.snapshotDiffsPrefs, ps.Current) {
+		sp.snapshotDiffsPrefs = deep.MustCopy(ps.Current)
+		sp.snapshotDiffs = util.MapSlice(ps.Snapshots, func(s PreferencesSnapshot) []string {
+			return s.Prefs.Differences(&ps.Current)
+		})
+	}
+
+	flags := imgui.TableFlagsBordersV | imgui.TableFlagsBordersOuterH | imgui.TableFlagsRowBg | imgui.TableFlagsSizingFixedFit
+	if imgui.BeginTableV("snapshots", 5, flags, imgui.Vec2{}, 0) {
+		imgui.TableSetupColumn("Name")
+		imgui.TableSetupColumn("Taken")
+		imgui.TableSetupColumn("Changes")
+		imgui.TableSetupColumn("##restore")
+		imgui.TableSetupColumn("##delete")
+		imgui.TableHeadersRow()
+
+		for i := 0; i < len(ps.Snapshots); i++ {
+			snap := &ps.Snapshots[i]
+			imgui.PushID(strconv.Itoa(i))
+			imgui.TableNextRow()
+
+			imgui.TableNextColumn()
+			imgui.SetNextItemWidth(150)
+			imgui.InputText("##name", &snap.Name)
+
+			imgui.TableNextColumn()
+			imgui.Text(snap.Time.Format("15:04:05"))
+
+			imgui.TableNextColumn()
+			if diffs := sp.snapshotDiffs[i]; len(diffs) == 0 {
+				imgui.Text("(same as current)")
+			} else {
+				imgui.Text(fmt.Sprintf("%d differences", len(diffs)))
+				if imgui.IsItemHovered() {
+					imgui.SetTooltip(strings.Join(diffs, "\n"))
+				}
+			}
+
+			imgui.TableNextColumn()
+			if imgui.Button("Restore") {
+				ps.RestoreSnapshot(i, p, sp)
+			}
+			imgui.SameLine()
+			if imgui.Button("A/B") {
+				ps.SwapSnapshot(i, p, sp)
+			}
+			if imgui.IsItemHovered() {
+				imgui.SetTooltip("Swap the current preferences with this snapshot")
+			}
+
+			imgui.TableNextColumn()
+			if imgui.Button(renderer.FontAwesomeIconTrash) {
+				ps.Snapshots = slices.Delete(ps.Snapshots, i, i+1)
+				sp.snapshotDiffs = slices.Delete(sp.snapshotDiffs, i, i+1)
+				i--
+			}
+
+			imgui.PopID()
+		}
+		imgui.EndTable()
+	}
+}
+
+// ScopeCursor specifies the cursor that is shown over the radar scope.
+type ScopeCursor int
+
+const (
+	ScopeCursorSTARS     ScopeCursor = iota // STARS "+" cursor
+	ScopeCursorCrosshair                    // lines spanning the scope
+	ScopeCursorSystem                       // the regular system cursor
+)
+
+func (c ScopeCursor) String() string {
+	return []string{"STARS", "Crosshair", "System"}[c]
+}
+
+// How long the cursor location is highlighted after F12 is pressed.
+const findCursorDuration = 750 * time.Millisecond
+
 func (sp *STARSPane) drawMouseCursor(ctx *panes.Context, scopeExtent math.Extent2D, transforms ScopeTransformations,
 	cb *renderer.CommandBuffer) {
 	td := renderer.GetTextDrawBuilder()
 	defer renderer.ReturnTextDrawBuilder(td)
+	ld := renderer.GetLinesDrawBuilder()
+	defer renderer.ReturnLinesDrawBuilder(ld)
 
 	// Is the mouse over the DCB or over the regular STARS scope? Note that
 	// we need to offset the mouse position to be w.r.t. window coordinates
@@ -754,11 +1097,61 @@ func (sp *STARSPane) drawMouseCursor(ctx *panes.Context, scopeExtent math.Extent
 		return
 	}
 
-	ctx.Mouse.SetCursor(imgui.MouseCursorNone)
+	now := time.Now()
+	if ctx.Mouse.Pos != sp.lastMousePos || sp.lastMouseMove.IsZero() {
+		sp.lastMousePos = ctx.Mouse.Pos
+		sp.lastMouseMove = now
+	}
+	finding := now.Sub(sp.findCursorStart) < findCursorDuration
+	idle := sp.CursorHideSeconds > 0 &&
+		now.Sub(sp.lastMouseMove) > time.Duration(sp.CursorHideSeconds)*time.Second
+
+	if idle && !finding {
+		ctx.Mouse.SetCursor(imgui.MouseCursorNone)
+		return
+	}
 
 	// STARS Operators Manual 4-74: FDB brightness is used for the cursor
 	ps := sp.currentPrefs()
-	cursorStyle := renderer.TextStyle{Font: sp.cursorsFont, Color: ps.Brightness.FullDatablocks.RGB()}
+	cursorColor := ps.Brightness.FullDatablocks.RGB()
+
+	cb.SetDrawBounds(ctx.PaneExtent, ctx.Platform.FramebufferSize()[1]/ctx.Platform.DisplaySize()[1])
+	transforms.LoadWindowViewingMatrices(cb)
+
+	if finding {
+		// Draw shrinking circles around the cursor so that it's easy to
+		// find on large or multiple displays.
+		t := float32(now.Sub(sp.findCursorStart)) / float32(findCursorDuration)
+		r := math.Lerp(t, 200, 20)
+		ld.AddCircle(ctx.Mouse.Pos, r, 64)
+		ld.AddCircle(ctx.Mouse.Pos, r+4, 64)
+		cb.LineWidth(2, ctx.DPIScale)
+		cb.SetRGB(cursorColor)
+		ld.GenerateCommands(cb)
+		ld.Reset()
+	}
+
+	switch sp.ScopeCursor {
+	case ScopeCursorSystem:
+		return
+
+	case ScopeCursorCrosshair:
+		ctx.Mouse.SetCursor(imgui.MouseCursorNone)
+		// scopeExtent is in window coordinates; the lines are drawn in
+		// pane coordinates.
+		ext := scopeExtent.Offset(math.Scale2f(ctx.PaneExtent.P0, -1))
+		p := ctx.Mouse.Pos
+		ld.AddLine([2]float32{ext.P0[0], p[1]}, [2]float32{ext.P1[0], p[1]})
+		ld.AddLine([2]float32{p[0], ext.P0[1]}, [2]float32{p[0], ext.P1[1]})
+		cb.LineWidth(1, ctx.DPIScale)
+		cb.SetRGB(cursorColor)
+		ld.GenerateCommands(cb)
+		return
+	}
+
+	ctx.Mouse.SetCursor(imgui.MouseCursorNone)
+
+	cursorStyle := renderer.TextStyle{Font: sp.cursorsFont, Color: cursorColor}
 	background := ps.Brightness.BackgroundContrast.ScaleRGB(STARSBackgroundColor)
 	bgStyle := renderer.TextStyle{Font: sp.cursorsFont, Color: background}
 
@@ -776,8 +1169,6 @@ func (sp *STARSPane) drawMouseCursor(ctx *panes.Context, scopeExtent math.Extent
 	draw(idx+1, bgStyle)
 	draw(idx, cursorStyle)
 
-	cb.SetDrawBounds(ctx.PaneExtent, ctx.Platform.FramebufferSize()[1]/ctx.Platform.DisplaySize()[1])
-	transforms.LoadWindowViewingMatrices(cb)
 	td.GenerateCommands(cb)
 }
 
@@ -937,11 +1328,21 @@ func (sp *STARSPane) visibleAircraft(ctx *panes.Context) []*av.Aircraft {
 			aircraft = append(aircraft, ac)
 
 			// Is this the first we've seen it?
-			if state.FirstRadarTrack.IsZero() {
+			first := state.FirstRadarTrack.IsZero()
+			if first {
 				state.FirstRadarTrack = now
+			}
+
+			// Departures are acquired when they are first seen unless the
+			// facility has an auto-acquisition area for their airport, in
+			// which case they are acquired once they're airborne in it.
+			area := ctx.ControlClient.STARSFacilityAdaptation.AutoAcquisitionArea(ac)
+			if sp.AutoTrackDepartures && !state.AutoAcquired &&
+				((area == nil && first) || (area != nil && area.Inside(ac))) {
+				state.AutoAcquired = true
 
 				trk := sp.getTrack(ctx, ac)
-				if sp.AutoTrackDepartures && trk != nil && trk.TrackOwner == "" &&
+				if trk != nil && trk.TrackOwner == "" &&
 					ctx.ControlClient.DepartureController(ac, ctx.Lg) == ctx.ControlClient.Callsign {
 					starsFP := sim.MakeSTARSFlightPlan(ac.FlightPlan)
 					ctx.ControlClient.InitiateTrack(callsign, starsFP, nil, nil) // ignore error...
@@ -994,6 +1395,7 @@ func (sp *STARSPane) initializeAudio(p platform.Platform, lg *log.Logger) {
 		sp.audioEffects[AudioInboundHandoff] = loadMP3("263124__pan14__sine-octaves-up-beep.mp3")
 		sp.audioEffects[AudioCommandError] = loadMP3("ERROR.mp3")
 		sp.audioEffects[AudioHandoffAccepted] = loadMP3("321104__nsstudios__blip2.mp3")
+		sp.audioEffects[AudioRunwayOccupancy] = loadMP3("CA_1000ms.mp3")
 	}
 }
 
@@ -1025,15 +1427,21 @@ func (sp *STARSPane) updateAudio(ctx *panes.Context, aircraft []*av.Aircraft) {
 	playCASound := !ps.DisableCAWarnings && slices.ContainsFunc(sp.CAAircraft,
 		func(ca CAAircraft) bool {
 			return !ca.Acknowledged && !sp.Aircraft[ca.Callsigns[0]].DisableCAWarnings &&
-				!sp.Aircraft[ca.Callsigns[1]].DisableCAWarnings && ctx.Now.Before(ca.SoundEnd)
+				!sp.Aircraft[ca.Callsigns[1]].DisableCAWarnings && sp.alertSounding(ca.SoundEnd, ctx.Now)
 		})
 	updateContinuous(playCASound, AudioConflictAlert)
 
+	playRunwaySound := !ps.DisableRunwayAlerts && slices.ContainsFunc(sp.RunwayAlerts,
+		func(ra RunwayOccupancyAlert) bool {
+			return !ra.Acknowledged && sp.alertSounding(ra.SoundEnd, ctx.Now)
+		})
+	updateContinuous(playRunwaySound, AudioRunwayOccupancy)
+
 	playMSAWSound := !ps.DisableMSAW && func() bool {
 		for _, ac := range aircraft {
 			state := sp.Aircraft[ac.Callsign]
 			if state.MSAW && !state.MSAWAcknowledged && !state.InhibitMSAW && !state.DisableMSAW &&
-				ctx.Now.Before(state.MSAWSoundEnd) {
+				sp.alertSounding(state.MSAWSoundEnd, ctx.Now) {
 				return true
 			}
 		}
@@ -1049,7 +1457,7 @@ func (sp *STARSPane) updateAudio(ctx *panes.Context, aircraft []*av.Aircraft) {
 		for _, ac := range aircraft {
 			state := sp.Aircraft[ac.Callsign]
 			ok, _ := av.SquawkIsSPC(ac.Squawk)
-			if ok && !state.SPCAcknowledged && ctx.Now.Before(state.SPCSoundEnd) {
+			if ok && !state.SPCAcknowledged && sp.alertSounding(state.SPCSoundEnd, ctx.Now) {
 				return true
 			}
 		}
@@ -1100,10 +1508,7 @@ func (sp *STARSPane) handleCapture(ctx *panes.Context, transforms ScopeTransform
 	}
 
 	if sp.capture.doStill && sp.capture.haveRegion {
-		fn := "capture.png"
-		if d, err := os.UserHomeDir(); err == nil {
-			fn = d + "/" + fn
-		}
+		fn := filepath.Join(util.ExportDir(), "capture.png")
 		w, err := os.Create(fn)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "%v\n", err)
@@ -1233,10 +1638,7 @@ func captureEncodeFrames(ch chan *image.RGBA) {
 		} else {
 			// No more images; save the animated GIFs.
 			for i := range 2 {
-				fn := [2]string{"capture.gif", "capture-2x.gif"}[i]
-				if d, err := os.UserHomeDir(); err == nil {
-					fn = d + "/" + fn
-				}
+				fn := filepath.Join(util.ExportDir(), [2]string{"capture.gif", "capture-2x.gif"}[i])
 				w, err := os.Create(fn)
 				if err != nil {
 					fmt.Fprintf(os.Stderr, "%v\n", err)
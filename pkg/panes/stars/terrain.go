@@ -0,0 +1,81 @@
+// pkg/panes/stars/terrain.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package stars
+
+import (
+	"image"
+	"image/color"
+	gomath "math"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/math"
+)
+
+// System map ids for the MVA and MIA maps; these are drawn using the
+// terrain brightness rather than the A/B video map groups.
+const (
+	mvaMapId = 701
+	miaMapId = 703
+)
+
+func isTerrainMap(vm av.VideoMap) bool {
+	return vm.Name == "ALL MINIMUM VECTORING ALTITUDES" || vm.Name == "ALL MINIMUM IFR ALTITUDES"
+}
+
+// hillshadeSource provides elevation tiles in the "terrarium" encoding,
+// which are converted to hillshaded images after they are fetched.
+var hillshadeSource = basemapSource{
+	Name:    "Hillshade",
+	URL:     "https://s3.amazonaws.com/elevation-tiles-prod/terrarium/{z}/{x}/{y}.png",
+	MaxZoom: 15,
+	Process: hillshade,
+}
+
+// terrariumElevation returns the elevation in meters encoded in a
+// terrarium tile pixel.
+func terrariumElevation(c color.Color) float64 {
+	r, g, b, _ := c.RGBA()
+	return float64(r>>8)*256 + float64(g>>8) + float64(b>>8)/256 - 32768
+}
+
+// hillshade converts a terrarium elevation tile to a grayscale image
+// shaded as if lit from the northwest, 45 degrees above the horizon.
+func hillshade(img image.Image, key basemapTileKey) image.Image {
+	b := img.Bounds()
+	nx, ny := b.Dx(), b.Dy()
+	elev := make([]float64, nx*ny)
+	for y := range ny {
+		for x := range nx {
+			elev[x+y*nx] = terrariumElevation(img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	at := func(x, y int) float64 {
+		return elev[math.Clamp(x, 0, nx-1)+math.Clamp(y, 0, ny-1)*nx]
+	}
+
+	// Size of a pixel in meters at the tile's latitude.
+	lat := float64(key.Extent().Center()[1]) * gomath.Pi / 180
+	cellSize := 40075016.686 * gomath.Cos(lat) / float64(nx*(1<<key.Z))
+
+	// The standard hillshade formulation uses a mathematical angle for
+	// the sun's azimuth; compass 315 is 135 degrees.
+	const azimuth, altitude = (360 - 315 + 90) * gomath.Pi / 180, 45 * gomath.Pi / 180
+	zenith := gomath.Pi/2 - altitude
+
+	shaded := image.NewGray(image.Rect(0, 0, nx, ny))
+	for y := range ny {
+		for x := range nx {
+			dzdx := (at(x+1, y) - at(x-1, y)) / (2 * cellSize)
+			dzdy := (at(x, y+1) - at(x, y-1)) / (2 * cellSize)
+			slope := gomath.Atan(gomath.Hypot(dzdx, dzdy))
+			aspect := gomath.Atan2(dzdy, -dzdx)
+
+			v := gomath.Cos(zenith)*gomath.Cos(slope) +
+				gomath.Sin(zenith)*gomath.Sin(slope)*gomath.Cos(azimuth-aspect)
+			shaded.SetGray(x, y, color.Gray{Y: uint8(255 * math.Clamp(v, 0, 1))})
+		}
+	}
+	return shaded
+}
@@ -0,0 +1,250 @@
+// pkg/panes/stars/tfr.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package stars
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/log"
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/panes"
+	"github.com/mmp/vice/pkg/renderer"
+	"github.com/mmp/vice/pkg/util"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// System map id for the temporary flight restriction map.
+const tfrMapId = 702
+
+// How often TFRs are re-fetched from the FAA when automatic fetching is
+// enabled.
+const tfrFetchInterval = 30 * time.Minute
+
+type tfrFetchResult struct {
+	tfrs  []av.TFR
+	stale bool
+	err   error
+}
+
+// TFRs holds the temporary flight restrictions known to the STARSPane,
+// both those fetched from the FAA and those imported by the user.
+type TFRs struct {
+	// Fetch TFRs from the FAA periodically.
+	Fetch bool
+	// TFRs imported from GeoJSON files.
+	Imported []av.TFR
+
+	fetched   []av.TFR
+	fetchCh   chan tfrFetchResult
+	lastFetch time.Time
+	fetchErr  string
+
+	// Names of the TFRs that are currently active and have been drawn
+	// into the system map; used to detect when it must be regenerated.
+	mapActive []string
+
+	importFilename string
+	importErr      string
+}
+
+func (t *TFRs) all() []av.TFR {
+	return append(slices.Clone(t.fetched), t.Imported...)
+}
+
+func (t *TFRs) active(now time.Time) []av.TFR {
+	return util.FilterSlice(t.all(), func(tfr av.TFR) bool { return tfr.Active(now) })
+}
+
+func (t *TFRs) activeNames(now time.Time) []string {
+	return util.MapSlice(t.active(now), func(tfr av.TFR) string { return tfr.Name })
+}
+
+// update kicks off a fetch of TFRs from the FAA if it's time for one and
+// collects the results of earlier fetches.
+func (t *TFRs) update(lg *log.Logger) {
+	if t.fetchCh != nil {
+		select {
+		case r := <-t.fetchCh:
+			t.fetchCh = nil
+			if r.err != nil {
+				lg.Warnf("TFR fetch: %v", r.err)
+				t.fetchErr = r.err.Error()
+			} else {
+				t.fetched, t.fetchErr = r.tfrs, ""
+				if r.stale {
+					t.fetchErr = "unable to reach the FAA; using the most recently fetched TFRs"
+				}
+			}
+		default:
+		}
+	}
+
+	if !t.Fetch {
+		t.fetched = nil
+	} else if t.fetchCh == nil && time.Since(t.lastFetch) > tfrFetchInterval {
+		t.lastFetch = time.Now()
+		ch := make(chan tfrFetchResult, 1)
+		t.fetchCh = ch
+		go func() {
+			tfrs, stale, err := av.FetchFAATFRs()
+			ch <- tfrFetchResult{tfrs: tfrs, stale: stale, err: err}
+		}()
+	}
+}
+
+func (t *TFRs) importFile(filename string) error {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	tfrs, err := av.ParseTFRGeoJSON(b)
+	if err != nil {
+		return err
+	}
+
+	// Replace any previously-imported TFRs with the same name.
+	for _, tfr := range tfrs {
+		t.Imported = slices.DeleteFunc(t.Imported, func(im av.TFR) bool { return im.Name == tfr.Name })
+	}
+	t.Imported = append(t.Imported, tfrs...)
+	return nil
+}
+
+func (t *TFRs) DrawUI() {
+	imgui.Checkbox("Fetch temporary flight restrictions from the FAA", &t.Fetch)
+	if t.Fetch && t.fetchErr != "" {
+		imgui.Text("TFR fetch error: " + t.fetchErr)
+	}
+
+	imgui.InputText("TFR GeoJSON file", &t.importFilename)
+	imgui.SameLine()
+	if imgui.Button("Import") {
+		if err := t.importFile(t.importFilename); err != nil {
+			t.importErr = err.Error()
+		} else {
+			t.importErr, t.importFilename = "", ""
+		}
+	}
+	if t.importErr != "" {
+		imgui.Text("TFR import error: " + t.importErr)
+	}
+	if len(t.Imported) > 0 {
+		imgui.Text(strings.Join(util.MapSlice(t.Imported, func(tfr av.TFR) string { return tfr.Name }), ", "))
+		imgui.SameLine()
+		if imgui.Button("Clear imported TFRs") {
+			t.Imported = nil
+		}
+	}
+}
+
+// makeTFRMap returns a system video map that draws the outlines of the
+// given TFRs.
+func makeTFRMap(tfrs []av.TFR) av.VideoMap {
+	vm := av.VideoMap{
+		Label: "TFR",
+		Name:  "ALL TEMPORARY FLIGHT RESTRICTIONS",
+		Id:    tfrMapId,
+	}
+
+	ld := renderer.GetLinesDrawBuilder()
+	for _, tfr := range tfrs {
+		for _, poly := range tfr.Polygons {
+			ld.AddLineLoop(util.MapSlice(poly, func(p math.Point2LL) [2]float32 { return p }))
+		}
+	}
+	ld.GenerateCommands(&vm.CommandBuffer)
+	renderer.ReturnLinesDrawBuilder(ld)
+
+	return vm
+}
+
+// updateTFRs fetches TFRs as needed, regenerates the TFR system map if the
+// set of active TFRs has changed, and updates the penetration alerts for
+// the aircraft we are tracking.
+func (sp *STARSPane) updateTFRs(ctx *panes.Context) {
+	sp.TFRs.update(ctx.Lg)
+
+	now := ctx.ControlClient.SimTime
+	if names := sp.TFRs.activeNames(now); !slices.Equal(names, sp.TFRs.mapActive) {
+		sp.TFRs.mapActive = names
+		// The id may have been remapped if it collided with a video map.
+		for id, vm := range sp.systemMaps {
+			if vm.Label == "TFR" {
+				vm = makeTFRMap(sp.TFRs.active(now))
+				vm.Id = id
+				sp.systemMaps[id] = vm
+			}
+		}
+	}
+}
+
+func (sp *STARSPane) updateTFRAlerts(ctx *panes.Context) {
+	active := sp.TFRs.active(ctx.ControlClient.SimTime)
+	for callsign, ac := range ctx.ControlClient.Aircraft {
+		state := sp.Aircraft[callsign]
+		if ac.TrackingController != ctx.ControlClient.Callsign {
+			state.TFR = ""
+			continue
+		}
+
+		idx := slices.IndexFunc(active, func(tfr av.TFR) bool {
+			return tfr.Inside(state.track.Position, state.track.Altitude)
+		})
+		if idx == -1 {
+			state.TFR = ""
+		} else {
+			state.TFR = active[idx].Name
+		}
+	}
+}
+
+// drawTFRInfo shows the name, altitude bounds, and schedule of the TFRs
+// under the mouse cursor when the TFR map is displayed.
+func (sp *STARSPane) drawTFRInfo(ctx *panes.Context, transforms ScopeTransformations, cb *renderer.CommandBuffer) {
+	if ctx.Mouse == nil {
+		return
+	}
+	ps := sp.currentPrefs()
+	visible := false
+	for id := range ps.VideoMapVisible {
+		if vm, ok := sp.systemMaps[id]; ok && vm.Label == "TFR" {
+			visible = true
+		}
+	}
+	if !visible {
+		return
+	}
+
+	p := transforms.LatLongFromWindowP(ctx.Mouse.Pos)
+	var lines []string
+	for _, tfr := range sp.TFRs.active(ctx.ControlClient.SimTime) {
+		if tfr.InsideLateral(p) {
+			lines = append(lines, tfr.Name+" "+tfr.AltitudeString(), tfr.ScheduleString())
+			if tfr.Description != "" {
+				lines = append(lines, tfr.Description)
+			}
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	td := renderer.GetTextDrawBuilder()
+	defer renderer.ReturnTextDrawBuilder(td)
+	style := renderer.TextStyle{
+		Font:           sp.systemFont[ps.CharSize.Tools],
+		Color:          ps.Brightness.Lists.ScaleRGB(STARSListColor),
+		DrawBackground: true,
+	}
+	td.AddText(strings.ToUpper(strings.Join(lines, "\n")), math.Add2f(ctx.Mouse.Pos, [2]float32{16, -16}), style)
+
+	transforms.LoadWindowViewingMatrices(cb)
+	td.GenerateCommands(cb)
+}
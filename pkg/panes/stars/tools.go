@@ -5,11 +5,13 @@
 package stars
 
 import (
+	"bytes"
 	_ "embed"
 	"fmt"
 	"image"
 	"image/draw"
 	"image/png"
+	"io"
 	"log/slog"
 	gomath "math"
 	"math/bits"
@@ -76,6 +78,17 @@ func (w *WeatherRadar) Activate(r renderer.Renderer, lg *log.Logger) {
 	go fetchWeather(w.reqChan, w.cbChan, lg)
 }
 
+// Deactivate stops fetching weather radar images.
+func (w *WeatherRadar) Deactivate() {
+	if !w.active {
+		return
+	}
+
+	w.active = false
+	close(w.reqChan)
+	w.reqChan = nil
+}
+
 func (w *WeatherRadar) HaveWeather() [numWxLevels]bool {
 	var r [numWxLevels]bool
 	for i := range numWxLevels {
@@ -301,16 +314,28 @@ func fetchWeather(reqChan chan math.Point2LL, cbChan chan [numWxLevels]*renderer
 
 		url := "https://opengeo.ncep.noaa.gov/geoserver/conus/conus_bref_qcd/ows?" + params.Encode()
 
-		// Request the image
+		// Request the image; if that fails, the most recent image for the
+		// region is used, if there is one.
 		lg.Info("Fetching weather", slog.String("url", url))
-		resp, err := http.Get(url)
+		b, stale, err := util.GetResourceCache().Get(url, 0, func() ([]byte, error) {
+			resp, err := http.Get(url)
+			if err != nil {
+				return nil, err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return nil, fmt.Errorf("%s: %s", url, resp.Status)
+			}
+			return io.ReadAll(resp.Body)
+		})
 		if err != nil {
 			lg.Infof("Weather error: %s", err)
 			continue
+		} else if stale {
+			lg.Infof("Weather unavailable; using the most recently fetched image")
 		}
-		defer resp.Body.Close()
 
-		img, err := png.Decode(resp.Body)
+		img, err := png.Decode(bytes.NewReader(b))
 		if err != nil {
 			lg.Infof("Weather error: %s", err)
 			continue
@@ -1747,7 +1772,9 @@ func rblSecondClickHandler(ctx *panes.Context, sp *STARSPane) func([2]float32, S
 	}
 }
 
-func (sp *STARSPane) displaySignificantPointInfo(p0, p1 math.Point2LL, nmPerLongitude, magneticVariation float32) (status CommandStatus) {
+func (sp *STARSPane) displaySignificantPointInfo(ctx *panes.Context, p0, p1 math.Point2LL) (status CommandStatus) {
+	nmPerLongitude, magneticVariation := ctx.ControlClient.NmPerLongitude, ctx.ControlClient.MagneticVariation
+
 	// Find the closest significant point to p1.
 	minDist := float32(1000000)
 	var closest *sim.SignificantPoint
@@ -1768,8 +1795,7 @@ func (sp *STARSPane) displaySignificantPointInfo(p0, p1 math.Point2LL, nmPerLong
 	}
 
 	// Display a blinking square at the point
-	sp.highlightedLocation = closest.Location
-	sp.highlightedLocationEndTime = time.Now().Add(5 * time.Second)
+	sp.highlightLocation(ctx, closest.Location)
 
 	// 6-148
 	format := func(sig sim.SignificantPoint) string {
@@ -1816,8 +1842,7 @@ func toSignificantPointClickHandler(ctx *panes.Context, sp *STARSPane) func([2]f
 			return
 		} else {
 			p1 := transforms.LatLongFromWindowP(pw)
-			return sp.displaySignificantPointInfo(*sp.wipSignificantPoint, p1,
-				ctx.ControlClient.NmPerLongitude, ctx.ControlClient.MagneticVariation)
+			return sp.displaySignificantPointInfo(ctx, *sp.wipSignificantPoint, p1)
 		}
 	}
 }
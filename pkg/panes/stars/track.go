@@ -50,6 +50,8 @@ type AircraftState struct {
 	// rates of altitude change, etc.
 	track         av.RadarTrack
 	previousTrack av.RadarTrack
+	// source is only set if STARSPane.ModelTrackSources is.
+	source TrackSource
 
 	// Radar track history is maintained with a ring buffer where
 	// historyTracksIndex is the index of the next track to be written.
@@ -111,6 +113,10 @@ type AircraftState struct {
 	InhibitMSAW      bool // only applies if in an alert. clear when alert is over?
 	MSAWAcknowledged bool
 	MSAWSoundEnd     time.Time
+	// Set if the MSAW is due to an obstacle rather than an MVA.
+	MSAWObstacle *av.Obstacle
+
+	TFR string // name of the TFR the aircraft has penetrated, if any
 
 	SPCAlert        bool
 	SPCAcknowledged bool
@@ -119,6 +125,9 @@ type AircraftState struct {
 	FirstSeen           time.Time
 	FirstRadarTrack     time.Time
 	HaveEnteredAirspace bool
+	// AutoAcquired is set once it's been considered for auto-acquisition
+	// as a departure.
+	AutoAcquired bool
 
 	CWTCategory string // cache this for performance
 
@@ -128,6 +137,17 @@ type AircraftState struct {
 
 	RDIndicatorEnd time.Time
 
+	// OnFrequency records whether the aircraft has checked in on the
+	// user's frequency. It is set when the pilot calls in, cleared when
+	// the aircraft is switched to another controller, and can be toggled
+	// manually with the *C command.
+	OnFrequency bool
+	// When we accept a handoff, we note the time and the controller it
+	// came from so that the controller can be alerted if the aircraft
+	// doesn't check in; both are cleared once it does.
+	HandoffAcceptedTime time.Time
+	HandoffAcceptedFrom string
+
 	// Set when the user enters a command to clear the primary scratchpad,
 	// but it is already empty. (In turn, this causes the exit
 	// fix/destination airport and the like to no longer be displayed, when
@@ -231,8 +251,9 @@ func (sp *STARSPane) processEvents(ctx *panes.Context) {
 			sa.GlobalLeaderLineDirection = ac.GlobalLeaderLineDirection
 			sa.UseGlobalLeaderLine = sa.GlobalLeaderLineDirection != nil
 			sa.FirstSeen = ctx.ControlClient.SimTime
-			sa.CWTCategory = ac.CWT()
+			sa.CWTCategory = ctx.ControlClient.WakeCategory(ac)
 			sa.TabListIndex = TabListUnassignedIndex
+			sa.OnFrequency = ac.ControllingController == ctx.ControlClient.Callsign
 
 			sp.Aircraft[callsign] = sa
 		}
@@ -337,6 +358,12 @@ func (sp *STARSPane) processEvents(ctx *panes.Context) {
 			}
 
 		case sim.AcceptedHandoffEvent:
+			if event.ToController == ctx.ControlClient.Callsign && event.FromController != ctx.ControlClient.Callsign {
+				if state, ok := sp.Aircraft[event.Callsign]; ok && !state.OnFrequency {
+					state.HandoffAcceptedTime = ctx.ControlClient.SimTime
+					state.HandoffAcceptedFrom = event.FromController
+				}
+			}
 			if event.FromController == ctx.ControlClient.Callsign && event.ToController != ctx.ControlClient.Callsign {
 				if state, ok := sp.Aircraft[event.Callsign]; ok {
 					sp.playOnce(ctx.Platform, AudioHandoffAccepted)
@@ -356,6 +383,23 @@ func (sp *STARSPane) processEvents(ctx *panes.Context) {
 				}
 			}
 
+		case sim.RadioTransmissionEvent:
+			// Any transmission from the pilot to us means they're on our
+			// frequency.
+			if event.ToController == ctx.ControlClient.Callsign {
+				if state, ok := sp.Aircraft[event.Callsign]; ok {
+					state.OnFrequency = true
+					state.HandoffAcceptedTime, state.HandoffAcceptedFrom = time.Time{}, ""
+				}
+			}
+
+		case sim.HandoffControllEvent:
+			if event.FromController == ctx.ControlClient.Callsign {
+				if state, ok := sp.Aircraft[event.Callsign]; ok {
+					state.OnFrequency = false
+				}
+			}
+
 		case sim.IdentEvent:
 			if state, ok := sp.Aircraft[event.Callsign]; ok {
 				state.IdentStart = time.Now().Add(time.Duration(2+rand.Intn(3)) * time.Second)
@@ -368,6 +412,18 @@ func (sp *STARSPane) processEvents(ctx *panes.Context) {
 				state.UseGlobalLeaderLine = state.GlobalLeaderLineDirection != nil
 			}
 
+		case sim.SharedAnnotationEvent:
+			if event.FromController != ctx.ControlClient.Callsign && event.Annotation != nil {
+				sp.receiveAnnotation(*event.Annotation)
+			}
+
+		case sim.SplitChangedEvent:
+			// The airspace we own may have changed; start over with
+			// the outside-airspace warnings.
+			for _, state := range sp.Aircraft {
+				state.HaveEnteredAirspace = false
+			}
+
 		case sim.ForceQLEvent:
 			if sp.ForceQLCallsigns == nil {
 				sp.ForceQLCallsigns = make(map[string]interface{})
@@ -388,6 +444,16 @@ func (sp *STARSPane) processEvents(ctx *panes.Context) {
 	}
 }
 
+// noCommAlert returns true if we accepted a handoff for the aircraft long
+// enough ago that it should have checked in but it hasn't.
+func (sp *STARSPane) noCommAlert(ctx *panes.Context, state *AircraftState) bool {
+	ps := sp.currentPrefs()
+	if ps.DisableNoCommAlerts || state.OnFrequency || state.HandoffAcceptedTime.IsZero() {
+		return false
+	}
+	return ctx.ControlClient.SimTime.Sub(state.HandoffAcceptedTime) > time.Duration(ps.NoCommAlertSeconds)*time.Second
+}
+
 func (sp *STARSPane) isQuicklooked(ctx *panes.Context, ac *av.Aircraft) bool {
 	if sp.currentPrefs().QuickLookAll {
 		return true
@@ -421,6 +487,13 @@ func (sp *STARSPane) updateMSAWs(ctx *panes.Context) {
 		warn := slices.ContainsFunc(mvas, func(mva av.MVA) bool {
 			return state.track.Altitude < mva.MinimumLimit && mva.Inside(state.track.Position)
 		})
+		state.MSAWObstacle = nil
+		if !warn {
+			if o, ok := sp.obstacleAlert(state, ctx.ControlClient.NmPerLongitude); ok {
+				warn = true
+				state.MSAWObstacle = &o
+			}
+		}
 
 		if !warn && state.InhibitMSAW {
 			// The warning has cleared, so the inhibit is disabled (p.7-25)
@@ -430,6 +503,8 @@ func (sp *STARSPane) updateMSAWs(ctx *panes.Context) {
 			// It's a new alert
 			state.MSAWAcknowledged = false
 			state.MSAWSoundEnd = time.Now().Add(AlertAudioDuration)
+		} else if !warn && state.MSAW && !state.MSAWAcknowledged {
+			sp.logAlertResponse("MSAW", callsign, state.MSAWSoundEnd.Add(-AlertAudioDuration), ctx.Now, false)
 		}
 		state.MSAW = warn
 	}
@@ -456,6 +531,14 @@ func (sp *STARSPane) updateRadarTracks(ctx *panes.Context) {
 			continue
 		}
 
+		if sp.ModelTrackSources {
+			// Each track is updated at its source's rate.
+			state.source = sp.trackSource(ctx, ac)
+			if now.Sub(state.track.Time) < state.source.updateInterval() {
+				continue
+			}
+		}
+
 		state.previousTrack = state.track
 		state.track = av.RadarTrack{
 			Position:    ac.Position(),
@@ -467,6 +550,7 @@ func (sp *STARSPane) updateRadarTracks(ctx *panes.Context) {
 
 	// Update low altitude alerts now that we have updated tracks
 	sp.updateMSAWs(ctx)
+	sp.updateTFRAlerts(ctx)
 
 	aircraft := sp.visibleAircraft(ctx)
 	sort.Slice(aircraft, func(i, j int) bool {
@@ -487,11 +571,13 @@ func (sp *STARSPane) updateRadarTracks(ctx *panes.Context) {
 	}
 
 	sp.updateCAAircraft(ctx, aircraft)
+	sp.updateRunwayAlerts(ctx, aircraft)
 	sp.updateInTrailDistance(ctx, aircraft)
 
 	// FIXME(mtrokel): should this be happening in the STARSComputer Update method?
 	if !ctx.ControlClient.STARSFacilityAdaptation.KeepLDB {
-		ctx.ControlClient.STARSComputer().UpdateAssociatedFlightPlans(aircraft)
+		ctx.ControlClient.STARSComputer().UpdateAssociatedFlightPlans(aircraft,
+			&ctx.ControlClient.STARSFacilityAdaptation)
 	}
 }
 
@@ -738,7 +824,26 @@ func (sp *STARSPane) drawRadarTrack(ac *av.Aircraft, state *AircraftState, headi
 		case RadarModeFused:
 			if ps.Brightness.PrimarySymbols > 0 {
 				color := primaryTargetBrightness.ScaleRGB(STARSTrackBlockColor)
-				drawTrack(trackBuilder, pw, sp.fusedTrackVertices, color)
+				if !sp.ModelTrackSources || state.source == TrackSourceADSB {
+					drawTrack(trackBuilder, pw, sp.fusedTrackVertices, color)
+				} else if state.source == TrackSourceMLAT {
+					// Multilateration tracks are outlined.
+					pts := util.MapSlice(sp.fusedTrackVertices, func(v [2]float32) [2]float32 {
+						return transforms.LatLongFromWindowP(math.Add2f(pw, v))
+					})
+					ld.AddLineLoop(color, pts)
+				} else {
+					// Radar-only tracks are drawn as a box, as in multi-radar
+					// mode.
+					rot := math.Rotator2f(heading)
+					box := [4][2]float32{[2]float32{-9, -3}, [2]float32{9, -3}, [2]float32{9, 3}, [2]float32{-9, 3}}
+					for i := range box {
+						box[i] = math.Scale2f(box[i], ctx.DrawPixelScale)
+						box[i] = math.Add2f(rot(box[i]), pw)
+						box[i] = transforms.LatLongFromWindowP(box[i])
+					}
+					trid.AddQuad(box[0], box[1], box[2], box[3], color)
+				}
 			}
 		}
 	}
@@ -822,8 +927,15 @@ func (sp *STARSPane) drawHistoryTrails(aircraft []*av.Aircraft, ctx *panes.Conte
 	defer renderer.ReturnColoredTrianglesDrawBuilder(historyBuilder)
 
 	const historyTrackDiameter = 8
+	// Number of history tracks drawn when rendering is degraded.
+	const degradedTrackHistory = 2
 	historyTrackVertices := getTrackVertices(ctx, historyTrackDiameter)
 
+	history := ps.RadarTrackHistory
+	if ctx.Degraded {
+		history = math.Min(history, degradedTrackHistory)
+	}
+
 	now := ctx.ControlClient.CurrentTime()
 	for _, ac := range aircraft {
 		state := sp.Aircraft[ac.Callsign]
@@ -833,7 +945,7 @@ func (sp *STARSPane) drawHistoryTrails(aircraft []*av.Aircraft, ctx *panes.Conte
 		}
 
 		// Draw history from new to old
-		for i := range ps.RadarTrackHistory {
+		for i := range history {
 			trackColorNum := math.Min(i, len(STARSTrackHistoryColors)-1)
 			trackColor := ps.Brightness.History.ScaleRGB(STARSTrackHistoryColors[trackColorNum])
 
@@ -862,7 +974,17 @@ func (sp *STARSPane) WarnOutsideAirspace(ctx *panes.Context, ac *av.Aircraft) (a
 	}
 
 	state := sp.Aircraft[ac.Callsign]
-	if ctx.ControlClient.IsDeparture(ac) {
+	if own := ctx.ControlClient.ControllerAirspace(); len(own) > 0 {
+		// The split assigns airspace to positions, so use ours for
+		// both arrivals and departures.
+		inAirspace, ownAlts := sim.InAirspace(ac.Position(), ac.Altitude(), own)
+		if !state.HaveEnteredAirspace {
+			state.HaveEnteredAirspace = inAirspace
+		} else {
+			alts = ownAlts
+			outside = !inAirspace
+		}
+	} else if ctx.ControlClient.IsDeparture(ac) {
 		if len(ctx.ControlClient.DepartureAirspace) > 0 {
 			inDepartureAirspace, depAlts := sim.InAirspace(ac.Position(), ac.Altitude(), ctx.ControlClient.DepartureAirspace)
 			if !state.HaveEnteredAirspace {
@@ -912,7 +1034,14 @@ func (sp *STARSPane) updateCAAircraft(ctx *panes.Context, aircraft []*av.Aircraf
 
 	// Remove ones that are no longer conflicting
 	sp.CAAircraft = util.FilterSlice(sp.CAAircraft, func(ca CAAircraft) bool {
-		return conflicting(ca.Callsigns[0], ca.Callsigns[1])
+		if conflicting(ca.Callsigns[0], ca.Callsigns[1]) {
+			return true
+		}
+		if !ca.Acknowledged {
+			sp.logAlertResponse("CA", ca.Callsigns[0]+"/"+ca.Callsigns[1],
+				ca.SoundEnd.Add(-AlertAudioDuration), ctx.Now, false)
+		}
+		return false
 	})
 
 	// Remove ones that are no longer visible
@@ -1068,7 +1197,7 @@ func (ma *ModeledAircraft) NextPosition(p [2]float32) [2]float32 {
 }
 
 func (sp *STARSPane) checkInTrailCwtSeparation(ctx *panes.Context, back, front *av.Aircraft) {
-	cwtSeparation := av.CWTApproachSeparation(front.CWT(), back.CWT())
+	cwtSeparation := av.CWTApproachSeparation(ctx.ControlClient.CWT(front), ctx.ControlClient.CWT(back))
 
 	state := sp.Aircraft[back.Callsign]
 	vol := back.ATPAVolume()
@@ -1245,3 +1374,37 @@ func (sp *STARSPane) radarVisibility(radarSites map[string]*av.RadarSite, pos ma
 
 	return
 }
+
+// updateAlertInhibits tracks how long CA and MSAW alerts have been
+// inhibited and re-enables them after STARSPane.AlertInhibitMinutes.
+func (sp *STARSPane) updateAlertInhibits(ctx *panes.Context) {
+	ps := sp.currentPrefs()
+	inhibits := map[string]*bool{"CA": &ps.DisableCAWarnings, "MSAW": &ps.DisableMSAW}
+	for callsign, state := range sp.Aircraft {
+		inhibits["CA "+callsign] = &state.DisableCAWarnings
+		inhibits["MSAW "+callsign] = &state.DisableMSAW
+	}
+
+	if sp.alertInhibitTimes == nil {
+		sp.alertInhibitTimes = make(map[string]time.Time)
+	}
+	for key := range sp.alertInhibitTimes {
+		if b, ok := inhibits[key]; !ok || !*b {
+			delete(sp.alertInhibitTimes, key)
+		}
+	}
+
+	for key, b := range inhibits {
+		if !*b {
+			continue
+		}
+		t, ok := sp.alertInhibitTimes[key]
+		if !ok {
+			sp.alertInhibitTimes[key] = ctx.Now
+		} else if sp.AlertInhibitMinutes > 0 &&
+			ctx.Now.Sub(t) > time.Duration(sp.AlertInhibitMinutes)*time.Minute {
+			*b = false
+			delete(sp.alertInhibitTimes, key)
+		}
+	}
+}
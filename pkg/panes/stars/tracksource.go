@@ -0,0 +1,81 @@
+// pkg/panes/stars/tracksource.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package stars
+
+import (
+	"hash/fnv"
+	"slices"
+	"time"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/panes"
+)
+
+// When STARSPane.ModelTrackSources is set, each track is derived from a
+// particular surveillance source: ADS-B for equipped aircraft, where it
+// is available, multilateration close to the airports, and otherwise
+// radar. Tracks are drawn with a different symbol and are updated at a
+// different rate depending on their source. Scenarios can specify areas
+// where ADS-B isn't available and ADS-B can be taken out of service
+// entirely to simulate an outage.
+
+type TrackSource int
+
+const (
+	TrackSourceRadar TrackSource = iota
+	TrackSourceADSB
+	TrackSourceMLAT
+)
+
+func (s TrackSource) String() string {
+	return []string{"Radar", "ADS-B", "MLAT"}[s]
+}
+
+// updateInterval returns how often tracks from the source are updated.
+func (s TrackSource) updateInterval() time.Duration {
+	if s == TrackSourceRadar {
+		return 5 * time.Second // roughly one sweep of a terminal radar
+	}
+	return time.Second
+}
+
+const (
+	// Multilateration coverage extends this many nm from the airports
+	// and up to this many feet above them.
+	mlatRadius  = 5
+	mlatCeiling = 2000
+)
+
+// adsbEquipped returns whether the aircraft has ADS-B Out. All IFR
+// aircraft do; a quarter of the VFR aircraft, chosen consistently by
+// callsign, don't.
+func adsbEquipped(ac *av.Aircraft) bool {
+	if ac.FlightPlan == nil || ac.FlightPlan.Rules != av.VFR {
+		return true
+	}
+	h := fnv.New32a()
+	h.Write([]byte(ac.Callsign))
+	return h.Sum32()%4 != 0
+}
+
+// trackSource returns the surveillance source of the aircraft's track.
+func (sp *STARSPane) trackSource(ctx *panes.Context, ac *av.Aircraft) TrackSource {
+	pos, alt := ac.Position(), int(ac.Altitude())
+
+	if adsbEquipped(ac) && !sp.adsbOutage &&
+		!slices.ContainsFunc(ctx.ControlClient.ADSBOutages, func(v av.AirspaceVolume) bool { return v.Inside(pos, alt) }) {
+		return TrackSourceADSB
+	}
+
+	for icao := range ctx.ControlClient.Airports {
+		if ap, ok := av.DB.Airports[icao]; ok && alt < ap.Elevation+mlatCeiling &&
+			math.NMDistance2LL(pos, ap.Location) < mlatRadius {
+			return TrackSourceMLAT
+		}
+	}
+
+	return TrackSourceRadar
+}
@@ -0,0 +1,85 @@
+// pkg/panes/stars/tracksource_test.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package stars
+
+import (
+	"fmt"
+	"testing"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/panes"
+	"github.com/mmp/vice/pkg/sim"
+)
+
+func TestADSBEquipped(t *testing.T) {
+	ifr := &av.Aircraft{Callsign: "AAL1", FlightPlan: &av.FlightPlan{Rules: av.IFR}}
+	if !adsbEquipped(ifr) || !adsbEquipped(&av.Aircraft{Callsign: "AAL2"}) {
+		t.Errorf("IFR aircraft and aircraft without flight plans should be equipped")
+	}
+
+	unequipped := 0
+	for i := range 1000 {
+		ac := &av.Aircraft{Callsign: fmt.Sprintf("N%d", i), FlightPlan: &av.FlightPlan{Rules: av.VFR}}
+		if !adsbEquipped(ac) {
+			unequipped++
+		}
+	}
+	if unequipped < 200 || unequipped > 300 {
+		t.Errorf("%d of 1000 VFR aircraft unequipped, expected about a quarter", unequipped)
+	}
+}
+
+func TestTrackSource(t *testing.T) {
+	db := av.DB
+	defer func() { av.DB = db }()
+	av.DB = &av.StaticDatabase{Airports: map[string]av.FAAAirport{
+		"KPHL": {Id: "KPHL", Elevation: 36, Location: math.Point2LL{-75, 40}},
+	}}
+
+	ctx := &panes.Context{ControlClient: &sim.ControlClient{State: sim.State{
+		Airports: map[string]*av.Airport{"KPHL": {}},
+		ADSBOutages: []av.AirspaceVolume{{Type: av.AirspaceVolumeCircle, Center: math.Point2LL{-74, 40},
+			Radius: 10, Floor: 0, Ceiling: 10000}},
+	}}}
+
+	var vfrCallsign string
+	for i := 0; vfrCallsign == ""; i++ {
+		ac := &av.Aircraft{Callsign: fmt.Sprintf("N%d", i), FlightPlan: &av.FlightPlan{Rules: av.VFR}}
+		if !adsbEquipped(ac) {
+			vfrCallsign = ac.Callsign
+		}
+	}
+
+	aircraft := func(callsign string, rules av.FlightRules, p math.Point2LL, alt float32) *av.Aircraft {
+		ac := &av.Aircraft{Callsign: callsign, FlightPlan: &av.FlightPlan{Rules: rules}}
+		ac.Nav.FlightState.Position = p
+		ac.Nav.FlightState.Altitude = alt
+		return ac
+	}
+	nearAirport, farAway, inOutage := math.Point2LL{-75.02, 40.01}, math.Point2LL{-76, 41}, math.Point2LL{-74, 40}
+
+	for _, test := range []struct {
+		name       string
+		ac         *av.Aircraft
+		adsbOutage bool
+		expected   TrackSource
+	}{
+		{"equipped", aircraft("AAL1", av.IFR, farAway, 5000), false, TrackSourceADSB},
+		{"equipped near airport", aircraft("AAL1", av.IFR, nearAirport, 1000), false, TrackSourceADSB},
+		{"in outage area", aircraft("AAL1", av.IFR, inOutage, 5000), false, TrackSourceRadar},
+		{"above outage area", aircraft("AAL1", av.IFR, inOutage, 12000), false, TrackSourceADSB},
+		{"ADS-B out of service", aircraft("AAL1", av.IFR, farAway, 5000), true, TrackSourceRadar},
+		{"ADS-B out of service near airport", aircraft("AAL1", av.IFR, nearAirport, 1000), true, TrackSourceMLAT},
+		{"unequipped", aircraft(vfrCallsign, av.VFR, farAway, 3000), false, TrackSourceRadar},
+		{"unequipped near airport", aircraft(vfrCallsign, av.VFR, nearAirport, 1500), false, TrackSourceMLAT},
+		{"unequipped above MLAT coverage", aircraft(vfrCallsign, av.VFR, nearAirport, 2500), false, TrackSourceRadar},
+	} {
+		sp := &STARSPane{adsbOutage: test.adsbOutage}
+		if src := sp.trackSource(ctx, test.ac); src != test.expected {
+			t.Errorf("%s: got %s, expected %s", test.name, src, test.expected)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// pkg/panes/tabs.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package panes
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/mmp/vice/pkg/log"
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/platform"
+	"github.com/mmp/vice/pkg/renderer"
+	"github.com/mmp/vice/pkg/sim"
+	"github.com/mmp/vice/pkg/util"
+)
+
+// A leaf DisplayNode may hold a tab group of multiple Panes rather than a
+// single one. In that case, DisplayNode.Tabs holds all of them and
+// DisplayNode.Pane is the active one, which is the only one drawn; a
+// TabStrip is drawn above it to switch between them.
+
+// TabStrip is the clickable strip of tabs drawn at the top of a tab
+// group. Like SplitLine, it implements the Pane interface so that it is
+// handled along with the other panes when drawing and dispatching mouse
+// events.
+type TabStrip struct {
+	node *DisplayNode
+}
+
+func (ts *TabStrip) Activate(renderer.Renderer, platform.Platform, *sim.EventStream, *log.Logger) {}
+func (ts *TabStrip) Deactivate()                                                                  {}
+func (ts *TabStrip) LoadedSim(sim.State, platform.Platform, *log.Logger)                          {}
+func (ts *TabStrip) ResetSim(sim.State, platform.Platform, *log.Logger)                           {}
+func (ts *TabStrip) CanTakeKeyboardFocus() bool                                                   { return false }
+func (ts *TabStrip) Hide() bool                                                                   { return false }
+
+const tabPadding = 8
+
+func tabStripHeight() float32 {
+	return float32(renderer.GetDefaultFont().Size) + 6
+}
+
+// splitTabStrip returns the extents of the tab strip and the active pane
+// of a tab group that covers the given extent.
+func splitTabStrip(e math.Extent2D) (math.Extent2D, math.Extent2D) {
+	strip, pane := e, e
+	strip.P0[1] = math.Floor(e.P1[1] - tabStripHeight())
+	pane.P1[1] = strip.P0[1]
+	return strip, pane
+}
+
+func (ts *TabStrip) Draw(ctx *Context, cb *renderer.CommandBuffer) {
+	d := ts.node
+	font := renderer.GetDefaultFont()
+	h := ctx.PaneExtent.Height()
+
+	widths := util.MapSlice(d.Tabs, func(pane Pane) float32 {
+		bx, _ := font.BoundText(PaneName(pane), 0)
+		return float32(bx) + 2*tabPadding
+	})
+
+	if ctx.Mouse != nil && ctx.Mouse.Clicked[platform.MouseButtonPrimary] {
+		x := float32(0)
+		for i, w := range widths {
+			if ctx.Mouse.Pos[0] >= x && ctx.Mouse.Pos[0] < x+w {
+				d.SelectTab(i, ctx.KeyboardFocus)
+				break
+			}
+			x += w
+		}
+	}
+
+	qb := renderer.GetColoredTrianglesDrawBuilder()
+	defer renderer.ReturnColoredTrianglesDrawBuilder(qb)
+	td := renderer.GetTextDrawBuilder()
+	defer renderer.ReturnTextDrawBuilder(td)
+	ld := renderer.GetColoredLinesDrawBuilder()
+	defer renderer.ReturnColoredLinesDrawBuilder(ld)
+
+	x := float32(0)
+	for i, pane := range d.Tabs {
+		w := widths[i]
+		style := renderer.TextStyle{Font: font, Color: UITextColor}
+		if i == d.ActiveTab {
+			qb.AddQuad([2]float32{x, 0}, [2]float32{x + w, 0}, [2]float32{x + w, h}, [2]float32{x, h}, UIControlColor)
+			style.Color = UITextHighlightColor
+		}
+		td.AddText(PaneName(pane), [2]float32{x + tabPadding, h - 3}, style)
+		x += w
+		ld.AddLine([2]float32{x, 0}, [2]float32{x, h}, UIControlColor)
+	}
+	ld.AddLine([2]float32{0, 0.5}, [2]float32{ctx.PaneExtent.Width(), 0.5}, UIControlColor)
+
+	ctx.SetWindowCoordinateMatrices(cb)
+	qb.GenerateCommands(cb)
+	cb.LineWidth(1, ctx.DPIScale)
+	ld.GenerateCommands(cb)
+	td.GenerateCommands(cb)
+}
+
+// AddTab adds the given pane to the tab group of the leaf node d,
+// converting it into a tab group if it isn't one already. The new pane
+// becomes the active one.
+func (d *DisplayNode) AddTab(pane Pane) {
+	if d.SplitLine.Axis != SplitAxisNone {
+		panic(fmt.Sprintf("DisplayNode adding a tab to a non-leaf node: %v", d))
+	}
+	if len(d.Tabs) == 0 && d.Pane != nil {
+		d.Tabs = []Pane{d.Pane}
+	}
+	d.Tabs = append(d.Tabs, pane)
+	d.SelectTab(len(d.Tabs)-1, nil)
+}
+
+// SelectTab makes the i-th pane of the node's tab group the active one.
+// If focus is non-nil and the pane can take the keyboard focus, it is
+// given it.
+func (d *DisplayNode) SelectTab(i int, focus KeyboardFocus) {
+	d.ActiveTab = i
+	d.Pane = d.Tabs[i]
+	if focus != nil && d.Pane.CanTakeKeyboardFocus() {
+		focus.Take(d.Pane)
+	}
+}
+
+func (d *DisplayNode) tabStrip() *TabStrip {
+	if d.strip == nil {
+		d.strip = &TabStrip{node: d}
+	}
+	return d.strip
+}
+
+// RemoveTab removes the given pane from the node's tab group; if just one
+// pane is left, the node reverts to being a regular leaf node.
+func (d *DisplayNode) RemoveTab(pane Pane) {
+	idx := slices.Index(d.Tabs, pane)
+	if idx == -1 {
+		return
+	}
+	d.Tabs = slices.Delete(d.Tabs, idx, idx+1)
+	if len(d.Tabs) == 1 {
+		d.Pane, d.Tabs, d.ActiveTab = d.Tabs[0], nil, 0
+	} else {
+		d.SelectTab(math.Clamp(d.ActiveTab, 0, len(d.Tabs)-1), nil)
+	}
+}
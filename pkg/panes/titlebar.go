@@ -0,0 +1,180 @@
+// pkg/panes/titlebar.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package panes
+
+import (
+	"github.com/mmp/imgui-go/v4"
+	"github.com/mmp/vice/pkg/log"
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/platform"
+	"github.com/mmp/vice/pkg/renderer"
+	"github.com/mmp/vice/pkg/sim"
+)
+
+// When title bars are enabled, each pane in the main window has a title
+// bar above it (and above its tab strip, for tab groups) that shows its
+// name and has buttons for the most common window operations. Title bars
+// aren't drawn for detached panes, since their windows already have one.
+
+// TitleBar is drawn above a pane when title bars are enabled. Like
+// TabStrip, it implements the Pane interface so that it is handled along
+// with the other panes.
+type TitleBar struct {
+	node *DisplayNode
+}
+
+func (*TitleBar) Activate(renderer.Renderer, platform.Platform, *sim.EventStream, *log.Logger) {}
+func (*TitleBar) Deactivate()                                                                  {}
+func (*TitleBar) LoadedSim(sim.State, platform.Platform, *log.Logger)                          {}
+func (*TitleBar) ResetSim(sim.State, platform.Platform, *log.Logger)                           {}
+func (*TitleBar) CanTakeKeyboardFocus() bool                                                   { return false }
+func (*TitleBar) Hide() bool                                                                   { return false }
+
+type titleBarAction int
+
+const (
+	titleBarSettings titleBarAction = iota
+	titleBarSplit
+	titleBarMaximize
+	titleBarClose
+)
+
+// titleBarRequest records a title bar button that was clicked; the
+// display hierarchy is updated after it has been traversed.
+type titleBarRequest struct {
+	pane   Pane
+	action titleBarAction
+}
+
+type titleBarButton struct {
+	icon    string
+	tooltip string
+	action  titleBarAction
+}
+
+// splitTitleBar returns the extents of the title bar and of the rest of a
+// leaf node that covers the given extent.
+func splitTitleBar(e math.Extent2D) (math.Extent2D, math.Extent2D) {
+	return splitTabStrip(e)
+}
+
+// buttons returns the title bar's buttons, ordered from right to left.
+func (tb *TitleBar) buttons(ctx *Context) []titleBarButton {
+	pane := tb.node.Pane
+	var b []titleBarButton
+	if !ctx.LayoutLocked {
+		b = append(b, titleBarButton{renderer.FontAwesomeIconTimes, "Close", titleBarClose})
+	}
+	if wm.maximizedPane == pane {
+		b = append(b, titleBarButton{renderer.FontAwesomeIconCompressAlt, "Restore", titleBarMaximize})
+	} else {
+		b = append(b, titleBarButton{renderer.FontAwesomeIconExpandAlt, "Maximize", titleBarMaximize})
+	}
+	if !ctx.LayoutLocked && wm.maximizedPane == nil {
+		b = append(b, titleBarButton{renderer.FontAwesomeIconColumns, "Split", titleBarSplit})
+	}
+	if _, ok := pane.(UIDrawer); ok {
+		b = append(b, titleBarButton{renderer.FontAwesomeIconCog, "Settings", titleBarSettings})
+	}
+	return b
+}
+
+func (tb *TitleBar) Draw(ctx *Context, cb *renderer.CommandBuffer) {
+	font := renderer.GetDefaultFont()
+	w, h := ctx.PaneExtent.Width(), ctx.PaneExtent.Height()
+	buttonWidth := h
+
+	qb := renderer.GetColoredTrianglesDrawBuilder()
+	defer renderer.ReturnColoredTrianglesDrawBuilder(qb)
+	td := renderer.GetTextDrawBuilder()
+	defer renderer.ReturnTextDrawBuilder(td)
+
+	x := w
+	for _, b := range tb.buttons(ctx) {
+		x -= buttonWidth
+		style := renderer.TextStyle{Font: font, Color: UITextColor}
+		if m := ctx.Mouse; m != nil && m.Pos[0] >= x && m.Pos[0] < x+buttonWidth {
+			qb.AddQuad([2]float32{x, 0}, [2]float32{x + buttonWidth, 0}, [2]float32{x + buttonWidth, h},
+				[2]float32{x, h}, UIControlColor)
+			style.Color = UITextHighlightColor
+			m.SetCursor(imgui.MouseCursorHand)
+			imgui.SetTooltip(b.tooltip)
+			if m.Clicked[platform.MouseButtonPrimary] {
+				wm.titleBarRequest = &titleBarRequest{pane: tb.node.Pane, action: b.action}
+			}
+		}
+		bx, _ := font.BoundText(b.icon, 0)
+		td.AddText(b.icon, [2]float32{x + (buttonWidth-float32(bx))/2, h - 3}, style)
+	}
+
+	style := renderer.TextStyle{Font: font, Color: UITextColor}
+	if tb.node.Pane == ctx.KeyboardFocus.Current() {
+		style.Color = UITextHighlightColor
+	}
+	td.AddText(PaneName(tb.node.Pane), [2]float32{tabPadding, h - 3}, style)
+
+	cb.ClearRGB(UIControlColor.Scale(0.5))
+	ctx.SetWindowCoordinateMatrices(cb)
+	qb.GenerateCommands(cb)
+	td.GenerateCommands(cb)
+}
+
+func (d *DisplayNode) titleBar() *TitleBar {
+	if d.title == nil {
+		d.title = &TitleBar{node: d}
+	}
+	return d.title
+}
+
+// wmApplyTitleBarRequest carries out the action of the title bar button
+// that was clicked, if any.
+func wmApplyTitleBarRequest(root *DisplayNode) {
+	req := wm.titleBarRequest
+	if req == nil {
+		return
+	}
+	wm.titleBarRequest = nil
+
+	switch req.action {
+	case titleBarSettings:
+		wm.settingsPane = req.pane
+
+	case titleBarSplit:
+		// Split the pane's node, adding an empty pane to its right that
+		// other panes can be dragged to.
+		node := root.NodeForPane(req.pane)
+		if node == nil {
+			return
+		}
+		wm.history.push(root)
+		leaf := node.clone()
+		leaf.Detached, leaf.Overlays = nil, nil
+		node.replace(leaf.SplitX(0.5, &DisplayNode{Pane: NewEmptyPane()}))
+
+	case titleBarMaximize:
+		if wm.maximizedPane == req.pane {
+			wm.lastMaximizedPane, wm.maximizedPane = req.pane, nil
+		} else {
+			wmMaximizePane(req.pane, root)
+		}
+
+	case titleBarClose:
+		// The last pane in the main window can't be closed.
+		if root.removePane(req.pane) {
+			req.pane.Deactivate()
+			// The pane can't come back, so the earlier layouts can't be
+			// restored.
+			wm.history = layoutHistory{}
+		}
+	}
+}
+
+// PendingSettings returns the pane whose title bar's settings button was
+// most recently clicked, if any, and clears the request.
+func PendingSettings() (Pane, bool) {
+	pane := wm.settingsPane
+	wm.settingsPane = nil
+	return pane, pane != nil
+}
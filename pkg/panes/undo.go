@@ -0,0 +1,105 @@
+// pkg/panes/undo.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package panes
+
+import (
+	"slices"
+)
+
+// Edits to the display hierarchy--moving panes, detaching and re-docking
+// them, and resizing splits--can be undone and redone. Before each edit, a
+// copy of the hierarchy is pushed on the undo stack. The copies share the
+// panes themselves, since these edits only rearrange them.
+
+// maxLayoutUndo is the maximum number of edits that can be undone.
+const maxLayoutUndo = 50
+
+type layoutHistory struct {
+	// root is the hierarchy that the stacks apply to; they are cleared
+	// if it is replaced, e.g. by switching layouts.
+	root       *DisplayNode
+	undo, redo []*DisplayNode
+}
+
+// clone returns a copy of the hierarchy that shares its panes.
+func (d *DisplayNode) clone() *DisplayNode {
+	c := *d
+	c.strip, c.bar, c.title = nil, nil, nil
+	c.Tabs = slices.Clone(d.Tabs)
+	c.Detached = slices.Clone(d.Detached)
+	c.Overlays = slices.Clone(d.Overlays)
+	c.MoreSplits = slices.Clone(d.MoreSplits)
+	c.Children = slices.Clone(d.Children)
+	for i, child := range d.Children {
+		c.Children[i] = child.clone()
+	}
+	return &c
+}
+
+// record adds the state of the hierarchy before an edit to the undo
+// stack.
+func (h *layoutHistory) record(root *DisplayNode, prev *DisplayNode) {
+	if h.root != root {
+		*h = layoutHistory{root: root}
+	}
+	h.undo = append(h.undo, prev)
+	if len(h.undo) > maxLayoutUndo {
+		h.undo = h.undo[1:]
+	}
+	h.redo = nil
+}
+
+func (h *layoutHistory) push(root *DisplayNode) {
+	h.record(root, root.clone())
+}
+
+// restoreLayout replaces the hierarchy with n, closing the windows of any
+// panes that are no longer detached.
+func restoreLayout(root *DisplayNode, n *DisplayNode) {
+	for _, dp := range root.Detached {
+		if !slices.Contains(n.Detached, dp) && dp.window != nil {
+			dp.window.Destroy()
+			dp.window = nil
+		}
+	}
+	*root = *n
+}
+
+// CanUndoLayout returns true if there is an edit to the given display
+// hierarchy that can be undone.
+func CanUndoLayout(root *DisplayNode) bool {
+	return wm.history.root == root && len(wm.history.undo) > 0
+}
+
+// CanRedoLayout returns true if there is an undone edit to the given
+// display hierarchy that can be redone.
+func CanRedoLayout(root *DisplayNode) bool {
+	return wm.history.root == root && len(wm.history.redo) > 0
+}
+
+// UndoLayout reverts the most recent edit to the display hierarchy.
+func UndoLayout(root *DisplayNode) {
+	h := &wm.history
+	if !CanUndoLayout(root) {
+		return
+	}
+	n := len(h.undo)
+	h.redo = append(h.redo, root.clone())
+	restoreLayout(root, h.undo[n-1])
+	h.undo = h.undo[:n-1]
+}
+
+// RedoLayout reapplies the most recently undone edit to the display
+// hierarchy.
+func RedoLayout(root *DisplayNode) {
+	h := &wm.history
+	if !CanRedoLayout(root) {
+		return
+	}
+	n := len(h.redo)
+	h.undo = append(h.undo, root.clone())
+	restoreLayout(root, h.redo[n-1])
+	h.redo = h.redo[:n-1]
+}
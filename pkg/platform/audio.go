@@ -28,6 +28,7 @@ type audioEngine struct {
 	mu      sync.Mutex
 	config  *Config
 	volume  int
+	capture sdl.AudioDeviceID
 }
 
 type audioEffect struct {
@@ -116,6 +117,44 @@ func (a *audioEngine) StopPlayAudio(index int) {
 	a.mu.Unlock()
 }
 
+func (a *audioEngine) StartAudioCapture() error {
+	if a.capture != 0 {
+		return nil
+	}
+
+	// No callback is given, so captured audio is queued for
+	// StopAudioCapture to dequeue.
+	spec := sdl.AudioSpec{
+		Freq:     AudioSampleRate,
+		Format:   sdl.AUDIO_S16SYS,
+		Channels: 1,
+		Samples:  2048,
+	}
+	dev, err := sdl.OpenAudioDevice("", true, &spec, nil, 0)
+	if err != nil {
+		return err
+	}
+	a.capture = dev
+	sdl.PauseAudioDevice(dev, false)
+	return nil
+}
+
+func (a *audioEngine) StopAudioCapture() []byte {
+	if a.capture == 0 {
+		return nil
+	}
+
+	sdl.PauseAudioDevice(a.capture, true)
+	pcm := make([]byte, sdl.GetQueuedAudioSize(a.capture))
+	// go-sdl2 reports SDL_DequeueAudio's nonzero byte count as a
+	// failure, so its error return isn't meaningful here.
+	_ = sdl.DequeueAudio(a.capture, pcm)
+	sdl.CloseAudioDevice(a.capture)
+	a.capture = 0
+
+	return pcm
+}
+
 //export audioCallback
 func audioCallback(user unsafe.Pointer, ptr *C.uint8, size C.int) {
 	n := int(size)
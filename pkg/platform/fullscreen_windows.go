@@ -33,5 +33,8 @@ func (g *glfwPlatform) EnableFullScreen(fullscreen bool) {
 
 		g.window.SetMonitor(nil, g.config.InitialWindowPosition[0], g.config.InitialWindowPosition[1],
 			windowSize[0], windowSize[1], glfw.DontCare)
+		if g.config.SpanAllMonitors {
+			g.applyWindowGeometry()
+		}
 	}
 }
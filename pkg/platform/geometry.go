@@ -0,0 +1,173 @@
+// pkg/platform/geometry.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package platform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mmp/vice/pkg/math"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// WindowGeometry records the size and position of the (non-fullscreen)
+// application window.
+type WindowGeometry struct {
+	Size     [2]int
+	Position [2]int
+}
+
+// displayConfiguration returns a string that identifies the current set
+// of monitors and their arrangement; it is used as the key for
+// remembering the window geometry, so that e.g. docking or undocking a
+// laptop restores the window to where it was the last time that set of
+// displays was in use.
+func displayConfiguration() string {
+	var s []string
+	for _, m := range glfw.GetMonitors() {
+		x, y := m.GetPos()
+		vm := m.GetVideoMode()
+		s = append(s, fmt.Sprintf("%s@%d,%d:%dx%d", m.GetName(), x, y, vm.Width, vm.Height))
+	}
+	return strings.Join(s, "|")
+}
+
+// windowIsVisible returns true if enough of the window's top edge,
+// where the title bar is, is on one of the monitors for the user to be
+// able to grab it and move the window.
+func windowIsVisible(pos, size [2]int) bool {
+	const minVisible = 64
+	for _, m := range glfw.GetMonitors() {
+		mx, my, mw, mh := m.GetWorkarea()
+		x0, x1 := math.Max(pos[0], mx), math.Min(pos[0]+size[0], mx+mw)
+		if x1-x0 >= minVisible && pos[1] >= my && pos[1] < my+mh {
+			return true
+		}
+	}
+	return false
+}
+
+// restoreWindowGeometry sets config.InitialWindowSize and
+// config.InitialWindowPosition to the geometry saved for the current
+// display configuration, if there is one.
+func restoreWindowGeometry(config *Config) {
+	if g, ok := config.WindowGeometries[displayConfiguration()]; ok {
+		config.InitialWindowSize = g.Size
+		config.InitialWindowPosition = g.Position
+	}
+}
+
+// ensureWindowVisible moves the window onto the primary monitor,
+// shrinking it if necessary to fit, if it would otherwise be off-screen
+// (e.g., because the monitor it was on has been disconnected).
+func ensureWindowVisible(config *Config) {
+	g := onScreen(WindowGeometry{Size: config.InitialWindowSize, Position: config.InitialWindowPosition})
+	config.InitialWindowSize, config.InitialWindowPosition = g.Size, g.Position
+}
+
+// onScreen returns the given geometry if the window would be visible and
+// otherwise returns geometry that puts it on the primary monitor.
+func onScreen(g WindowGeometry) WindowGeometry {
+	if windowIsVisible(g.Position, g.Size) {
+		return g
+	}
+
+	mx, my, mw, mh := glfw.GetPrimaryMonitor().GetWorkarea()
+	return WindowGeometry{
+		Position: [2]int{mx + 100, my + 100},
+		Size:     [2]int{math.Min(g.Size[0], mw-150), math.Min(g.Size[1], mh-150)},
+	}
+}
+
+// spanningGeometry returns the geometry of a window that covers the work
+// areas of all of the monitors.
+func spanningGeometry() WindowGeometry {
+	var p0, p1 [2]int
+	for i, m := range glfw.GetMonitors() {
+		mx, my, mw, mh := m.GetWorkarea()
+		if i == 0 {
+			p0, p1 = [2]int{mx, my}, [2]int{mx + mw, my + mh}
+		} else {
+			p0 = [2]int{math.Min(p0[0], mx), math.Min(p0[1], my)}
+			p1 = [2]int{math.Max(p1[0], mx+mw), math.Max(p1[1], my+mh)}
+		}
+	}
+	return WindowGeometry{Position: p0, Size: [2]int{p1[0] - p0[0], p1[1] - p0[1]}}
+}
+
+func (g *glfwPlatform) DisplayConfiguration() string {
+	return g.displayConfiguration
+}
+
+func (g *glfwPlatform) SetSpanAllMonitors(span bool) {
+	g.config.SpanAllMonitors = span
+	if g.window.GetMonitor() != nil {
+		// It will take effect when leaving fullscreen.
+		return
+	}
+	g.applyWindowGeometry()
+}
+
+// applyWindowGeometry sets the window's geometry to span all of the
+// monitors if that's enabled and otherwise to the geometry saved for the
+// current display configuration.
+func (g *glfwPlatform) applyWindowGeometry() {
+	if g.config.SpanAllMonitors {
+		sg := spanningGeometry()
+		g.window.SetAttrib(glfw.Decorated, glfw.False)
+		g.window.SetSize(sg.Size[0], sg.Size[1])
+		g.window.SetPos(sg.Position[0], sg.Position[1])
+		return
+	}
+
+	g.window.SetAttrib(glfw.Decorated, glfw.True)
+	restoreWindowGeometry(g.config)
+	ensureWindowVisible(g.config)
+	g.window.SetSize(g.config.InitialWindowSize[0], g.config.InitialWindowSize[1])
+	g.window.SetPos(g.config.InitialWindowPosition[0], g.config.InitialWindowPosition[1])
+}
+
+// saveWindowGeometry records the window's current geometry for the
+// current display configuration.
+func (g *glfwPlatform) saveWindowGeometry() {
+	if g.window.GetMonitor() != nil || g.window.GetAttrib(glfw.Iconified) == glfw.True ||
+		g.config.SpanAllMonitors {
+		// Don't remember fullscreen, minimized, or spanning geometry.
+		return
+	}
+
+	if g.config.WindowGeometries == nil {
+		g.config.WindowGeometries = make(map[string]WindowGeometry)
+	}
+	g.config.WindowGeometries[g.displayConfiguration] = WindowGeometry{
+		Size:     g.WindowSize(),
+		Position: g.WindowPosition(),
+	}
+}
+
+func (g *glfwPlatform) windowPosChange(window *glfw.Window, x, y int) {
+	g.saveWindowGeometry()
+}
+
+func (g *glfwPlatform) windowSizeChange(window *glfw.Window, width, height int) {
+	g.saveWindowGeometry()
+}
+
+// displayConfigurationChanged is called when monitors are connected or
+// disconnected; it moves the window to where it was when the new
+// configuration was last used, or onto a visible monitor if it's been
+// stranded. If the window spans all of the monitors, it is resized to
+// span the new ones.
+func (g *glfwPlatform) displayConfigurationChanged() {
+	g.displayConfiguration = displayConfiguration()
+	if g.window.GetMonitor() != nil {
+		return
+	}
+
+	g.config.InitialWindowSize = g.WindowSize()
+	g.config.InitialWindowPosition = g.WindowPosition()
+	g.applyWindowGeometry()
+}
@@ -45,6 +45,8 @@ type glfwPlatform struct {
 	// These are the keys that are actively held down; for now just the
 	// function keys, since all we currently need is F1 for beaconator.
 	heldFKeys map[Key]interface{}
+	// Identifies the current set of monitors; see displayConfiguration().
+	displayConfiguration string
 }
 
 type Config struct {
@@ -54,9 +56,24 @@ type Config struct {
 	InitialWindowPosition [2]int
 
 	EnableMSAA bool
+	// Number of samples per pixel when MSAA is enabled; zero is treated
+	// as 4, for configs saved before this was selectable.
+	MSAASamples int
+	// SmoothLines enables OpenGL's line anti-aliasing, which is
+	// independent of MSAA.
+	SmoothLines bool
 
 	StartInFullScreen bool
 	FullScreenMonitor int
+
+	// WindowGeometries records the window's size and position for each
+	// display configuration vice has been run with, keyed by
+	// displayConfiguration().
+	WindowGeometries map[string]WindowGeometry
+
+	// SpanAllMonitors makes the (non-fullscreen) window cover all of the
+	// monitors.
+	SpanAllMonitors bool
 }
 
 // New returns a new instance of a Platform implemented with a window
@@ -75,6 +92,8 @@ func New(config *Config, lg *log.Logger) (Platform, error) {
 	glfw.WindowHint(glfw.ContextVersionMajor, 2)
 	glfw.WindowHint(glfw.ContextVersionMinor, 1)
 
+	restoreWindowGeometry(config)
+
 	vm := glfw.GetPrimaryMonitor().GetVideoMode()
 	if config.InitialWindowSize[0] == 0 || config.InitialWindowSize[1] == 0 {
 		if runtime.GOOS == "windows" {
@@ -86,18 +105,25 @@ func New(config *Config, lg *log.Logger) (Platform, error) {
 		}
 	}
 
-	// If window position is out of bounds, create the window at (100, 100)
-	if config.InitialWindowPosition[0] < 0 || config.InitialWindowPosition[1] < 0 ||
-		config.InitialWindowPosition[0] > vm.Width || config.InitialWindowPosition[1] > vm.Height {
-		config.InitialWindowPosition = [2]int{100, 100}
+	// If the window would be off-screen, put it on the primary monitor.
+	ensureWindowVisible(config)
+
+	spanning := config.SpanAllMonitors && !config.StartInFullScreen
+	if spanning {
+		sg := spanningGeometry()
+		config.InitialWindowSize, config.InitialWindowPosition = sg.Size, sg.Position
 	}
+
 	// Start with an invisible window so that we can position it first
 	glfw.WindowHint(glfw.Visible, 0)
 	// Disable GLFW_AUTO_ICONIFY to stop the window from automatically minimizing in fullscreen
 	glfw.WindowHint(glfw.AutoIconify, 0)
 	// Maybe enable multisampling
 	if config.EnableMSAA {
-		glfw.WindowHint(glfw.Samples, 4)
+		if config.MSAASamples == 0 {
+			config.MSAASamples = 4
+		}
+		glfw.WindowHint(glfw.Samples, config.MSAASamples)
 	}
 	var window *glfw.Window
 	monitors := glfw.GetMonitors()
@@ -116,6 +142,9 @@ func New(config *Config, lg *log.Logger) (Platform, error) {
 		return nil, fmt.Errorf("failed to create window: %w", err)
 	}
 	window.SetPos(config.InitialWindowPosition[0], config.InitialWindowPosition[1])
+	if spanning {
+		window.SetAttrib(glfw.Decorated, glfw.False)
+	}
 	window.Show()
 	window.MakeContextCurrent()
 
@@ -125,6 +154,8 @@ func New(config *Config, lg *log.Logger) (Platform, error) {
 		window:      window,
 		multisample: config.EnableMSAA,
 		heldFKeys:   make(map[Key]interface{}),
+
+		displayConfiguration: displayConfiguration(),
 	}
 	platform.setKeyMapping()
 	platform.installCallbacks()
@@ -189,6 +220,7 @@ func (g *glfwPlatform) MonitorCallback(monitor *glfw.Monitor, event glfw.Periphe
 		g.config.FullScreenMonitor = 0
 		g.config.StartInFullScreen = false
 	}
+	g.displayConfigurationChanged()
 }
 
 func (g *glfwPlatform) Dispose() {
@@ -353,6 +385,8 @@ func (g *glfwPlatform) installCallbacks() {
 	g.window.SetScrollCallback(g.mouseScrollChange)
 	g.window.SetKeyCallback(g.keyChange)
 	g.window.SetCharCallback(g.charChange)
+	g.window.SetPosCallback(g.windowPosChange)
+	g.window.SetSizeCallback(g.windowSizeChange)
 }
 
 var glfwButtonIndexByID = map[glfw.MouseButton]int{
@@ -7,6 +7,7 @@ package platform
 import (
 	"strings"
 
+	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/mmp/imgui-go/v4"
 )
 
@@ -96,8 +97,18 @@ const (
 	KeyF16
 	KeyV
 	KeyInsert
+	KeyPlus  // either =/+ or keypad +
+	KeyMinus // either -/_ or keypad -
 )
 
+// ImguiKeyF1 is imgui's index for the F1 key, for use with
+// imgui.IsKeyPressed; F2 and on follow sequentially.
+const ImguiKeyF1 = 290
+
+// ImguiKey1 is imgui's index for the 1 key on the main keyboard; 2
+// through 9 follow sequentially.
+const ImguiKey1 = 49
+
 type KeyboardState struct {
 	Input string
 	// A key shows up here once each time it is pressed (though repeatedly
@@ -162,9 +173,8 @@ func (g *glfwPlatform) GetKeyboard() *KeyboardState {
 	if imgui.IsKeyPressed(imgui.GetKeyIndex(imgui.KeyV)) {
 		keyboard.Pressed[KeyV] = nil
 	}
-	const ImguiF1 = 290
 	for i := 0; i < 16; i++ { // 16 f-keys on the STARS keyboard
-		if imgui.IsKeyPressed(ImguiF1 + i) {
+		if imgui.IsKeyPressed(ImguiKeyF1 + i) {
 			keyboard.Pressed[Key(int(KeyF1)+i)] = nil
 		}
 	}
@@ -184,6 +194,14 @@ func (g *glfwPlatform) GetKeyboard() *KeyboardState {
 	if imgui.IsKeyPressed(imgui.GetKeyIndex(imgui.KeyInsert)) {
 		keyboard.Pressed[KeyInsert] = nil
 	}
+	// imgui doesn't have indices for these, but its key state is indexed
+	// by GLFW key codes.
+	if imgui.IsKeyPressed(int(glfw.KeyEqual)) || imgui.IsKeyPressed(int(glfw.KeyKPAdd)) {
+		keyboard.Pressed[KeyPlus] = nil
+	}
+	if imgui.IsKeyPressed(int(glfw.KeyMinus)) || imgui.IsKeyPressed(int(glfw.KeyKPSubtract)) {
+		keyboard.Pressed[KeyMinus] = nil
+	}
 
 	return keyboard
 }
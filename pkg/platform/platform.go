@@ -53,6 +53,14 @@ type Platform interface {
 	// GetAllMonitorNames() returns an array of all available monitors' names.
 	GetAllMonitorNames() []string
 
+	// DisplayConfiguration returns a string that identifies the current
+	// set of monitors and their arrangement.
+	DisplayConfiguration() string
+
+	// SetSpanAllMonitors specifies whether the window should cover all
+	// of the monitors when it isn't fullscreen.
+	SetSpanAllMonitors(span bool)
+
 	// DisplaySize returns the dimension of the display.
 	DisplaySize() [2]float32
 
@@ -76,6 +84,10 @@ type Platform interface {
 	// Disable mouse capture.
 	EndCaptureMouse()
 
+	// NewSecondaryWindow creates an additional top-level window with the
+	// given title, size, and position.
+	NewSecondaryWindow(title string, size [2]int, pos [2]int) (SecondaryWindow, error)
+
 	// Scaling factor to account for Retina-style displays
 	DPIScale() float32
 
@@ -108,4 +120,13 @@ type Platform interface {
 	// StopPlayAudio stops playback of the audio effect specified
 	// by the given identifier.
 	StopPlayAudio(id int)
+
+	// StartAudioCapture starts recording audio from the default
+	// microphone.
+	StartAudioCapture() error
+
+	// StopAudioCapture stops recording and returns the audio captured
+	// since StartAudioCapture was called as one channel 16-bit PCM
+	// sampled at AudioSampleRate.
+	StopAudioCapture() []byte
 }
@@ -0,0 +1,201 @@
+// pkg/platform/window.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package platform
+
+import (
+	"fmt"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// SecondaryWindow is an additional top-level window, e.g. for a pane that
+// has been detached from the main window. It shares OpenGL resources
+// like textures with the main window, so the same Renderer can be used
+// to draw into it between calls to BeginRender and EndRender.
+type SecondaryWindow interface {
+	// BeginRender makes the window's OpenGL context current.
+	BeginRender()
+
+	// EndRender swaps the window's buffers and makes the main window's
+	// context current again.
+	EndRender()
+
+	// ShouldClose returns true if the user has asked to close the window.
+	ShouldClose() bool
+
+	// Destroy closes the window.
+	Destroy()
+
+	DisplaySize() [2]float32
+	FramebufferSize() [2]float32
+	WindowSize() [2]int
+	WindowPosition() [2]int
+
+	// SetGeometry moves and resizes the window; if it would be
+	// off-screen, it is put on the primary monitor instead.
+	SetGeometry(g WindowGeometry)
+
+	// GetMouse returns the state of the mouse, in the same form as
+	// Platform.GetMouse, if it is over the window and nil otherwise.
+	GetMouse() *MouseState
+
+	// Clicked returns true if a mouse button was pressed in the window
+	// since the last call to GetMouse.
+	Clicked() bool
+}
+
+type glfwSecondaryWindow struct {
+	platform *glfwPlatform
+	window   *glfw.Window
+
+	down       [MouseButtonCount]bool
+	clicked    [MouseButtonCount]bool
+	dragging   [MouseButtonCount]bool
+	clickTime  [MouseButtonCount]float64
+	doubleTime [MouseButtonCount]bool
+	lastPos    [2]float32
+	pressPos   [2]float32
+	wheel      [2]float32
+}
+
+// NewSecondaryWindow creates a new top-level window with the given title,
+// size, and position; if the size is zero, a default size is used.
+func (g *glfwPlatform) NewSecondaryWindow(title string, size [2]int, pos [2]int) (SecondaryWindow, error) {
+	if size[0] == 0 || size[1] == 0 {
+		size = [2]int{600, 400}
+	}
+
+	glfw.WindowHint(glfw.Visible, 0)
+	window, err := glfw.CreateWindow(size[0], size[1], title, nil, g.window)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create window: %w", err)
+	}
+	if pos != [2]int{} {
+		geom := onScreen(WindowGeometry{Size: size, Position: pos})
+		window.SetSize(geom.Size[0], geom.Size[1])
+		window.SetPos(geom.Position[0], geom.Position[1])
+	}
+	window.Show()
+
+	// Only the main window waits for vsync.
+	window.MakeContextCurrent()
+	glfw.SwapInterval(0)
+	g.window.MakeContextCurrent()
+
+	w := &glfwSecondaryWindow{platform: g, window: window}
+
+	// Keyboard input goes to the main window's handlers so that it is
+	// delivered to the pane with the keyboard focus as usual.
+	window.SetKeyCallback(g.keyChange)
+	window.SetCharCallback(g.charChange)
+	window.SetScrollCallback(func(_ *glfw.Window, x, y float64) {
+		g.anyEvents = true
+		w.wheel[0] += float32(x)
+		w.wheel[1] += float32(y)
+	})
+	window.SetMouseButtonCallback(func(_ *glfw.Window, button glfw.MouseButton, action glfw.Action, _ glfw.ModifierKey) {
+		g.anyEvents = true
+		if b, ok := glfwButtonIndexByID[button]; ok && action == glfw.Press {
+			w.clicked[b] = true
+		}
+	})
+
+	return w, nil
+}
+
+func (w *glfwSecondaryWindow) BeginRender() {
+	w.window.MakeContextCurrent()
+}
+
+func (w *glfwSecondaryWindow) EndRender() {
+	w.window.SwapBuffers()
+	w.platform.window.MakeContextCurrent()
+}
+
+func (w *glfwSecondaryWindow) ShouldClose() bool {
+	return w.window.ShouldClose()
+}
+
+func (w *glfwSecondaryWindow) Destroy() {
+	w.window.Destroy()
+}
+
+func (w *glfwSecondaryWindow) DisplaySize() [2]float32 {
+	x, y := w.window.GetSize()
+	return [2]float32{float32(x), float32(y)}
+}
+
+func (w *glfwSecondaryWindow) FramebufferSize() [2]float32 {
+	x, y := w.window.GetFramebufferSize()
+	return [2]float32{float32(x), float32(y)}
+}
+
+func (w *glfwSecondaryWindow) WindowSize() [2]int {
+	x, y := w.window.GetSize()
+	return [2]int{x, y}
+}
+
+func (w *glfwSecondaryWindow) WindowPosition() [2]int {
+	x, y := w.window.GetPos()
+	return [2]int{x, y}
+}
+
+func (w *glfwSecondaryWindow) SetGeometry(g WindowGeometry) {
+	g = onScreen(g)
+	w.window.SetSize(g.Size[0], g.Size[1])
+	w.window.SetPos(g.Position[0], g.Position[1])
+}
+
+func (w *glfwSecondaryWindow) Clicked() bool {
+	for _, c := range w.clicked {
+		if c {
+			return true
+		}
+	}
+	return false
+}
+
+// GetMouse tracks the mouse buttons itself since imgui only follows the
+// main window.
+func (w *glfwSecondaryWindow) GetMouse() *MouseState {
+	x, y := w.window.GetCursorPos()
+	pos := [2]float32{float32(x), float32(y)}
+	size := w.DisplaySize()
+	inside := pos[0] >= 0 && pos[1] >= 0 && pos[0] < size[0] && pos[1] < size[1]
+
+	m := &MouseState{Pos: pos, Wheel: w.wheel}
+	now := glfw.GetTime()
+	for b := 0; b < MouseButtonCount; b++ {
+		down := w.clicked[b] || w.window.GetMouseButton(glfwButtonIDByIndex[b]) == glfw.Press
+
+		m.Down[b] = down
+		m.Clicked[b] = w.clicked[b]
+		m.Released[b] = w.down[b] && !down
+		if m.Clicked[b] {
+			m.DoubleClicked[b] = now-w.clickTime[b] < 0.3 && !w.doubleTime[b]
+			w.doubleTime[b] = m.DoubleClicked[b]
+			w.clickTime[b] = now
+			w.pressPos = pos
+		}
+		if down && !m.Clicked[b] && (w.dragging[b] || pos != w.pressPos) {
+			w.dragging[b] = true
+			m.Dragging[b] = true
+			m.DragDelta = [2]float32{pos[0] - w.lastPos[0], pos[1] - w.lastPos[1]}
+		} else if !down {
+			w.dragging[b] = false
+		}
+
+		w.down[b] = down
+		w.clicked[b] = false
+	}
+	w.lastPos = pos
+	w.wheel = [2]float32{}
+
+	if !inside && !w.dragging[MouseButtonPrimary] && !w.dragging[MouseButtonSecondary] &&
+		!w.dragging[MouseButtonTertiary] {
+		return nil
+	}
+	return m
+}
@@ -15,6 +15,7 @@ import (
 	"unicode/utf8"
 	"unsafe"
 
+	"github.com/mmp/vice/pkg/math"
 	"github.com/mmp/vice/pkg/platform"
 	"github.com/mmp/vice/pkg/util"
 
@@ -150,33 +151,51 @@ var (
 	FontAwesomeIconArrowRight          = faUsedIcons["ArrowRight"]
 	FontAwesomeIconArrowUp             = faUsedIcons["ArrowUp"]
 	FontAwesomeIconBook                = faUsedIcons["Book"]
+	FontAwesomeIconBookmark            = faUsedIcons["Bookmark"]
+	FontAwesomeIconBullhorn            = faUsedIcons["Bullhorn"]
 	FontAwesomeIconBug                 = faUsedIcons["Bug"]
 	FontAwesomeIconCaretDown           = faUsedIcons["CaretDown"]
 	FontAwesomeIconCaretRight          = faUsedIcons["CaretRight"]
 	FontAwesomeIconCheckSquare         = faUsedIcons["CheckSquare"]
+	FontAwesomeIconClipboardList       = faUsedIcons["ClipboardList"]
 	FontAwesomeIconCog                 = faUsedIcons["Cog"]
+	FontAwesomeIconColumns             = faUsedIcons["Columns"]
+	FontAwesomeIconCompass             = faUsedIcons["Compass"]
 	FontAwesomeIconCompressAlt         = faUsedIcons["CompressAlt"]
 	FontAwesomeIconCopyright           = faUsedIcons["Copyright"]
 	FontAwesomeIconDiscord             = faBrandsUsedIcons["Discord"]
+	FontAwesomeIconEllipsisH           = faUsedIcons["EllipsisH"]
 	FontAwesomeIconExclamationTriangle = faUsedIcons["ExclamationTriangle"]
 	FontAwesomeIconExpandAlt           = faUsedIcons["ExpandAlt"]
 	FontAwesomeIconFile                = faUsedIcons["File"]
+	FontAwesomeIconFileExport          = faUsedIcons["FileExport"]
 	FontAwesomeIconFolder              = faUsedIcons["Folder"]
 	FontAwesomeIconGithub              = faBrandsUsedIcons["Github"]
+	FontAwesomeIconGraduationCap       = faUsedIcons["GraduationCap"]
 	FontAwesomeIconHandPointLeft       = faUsedIcons["HandPointLeft"]
+	FontAwesomeIconHeadset             = faUsedIcons["Headset"]
 	FontAwesomeIconHome                = faUsedIcons["Home"]
+	FontAwesomeIconHourglassHalf       = faUsedIcons["HourglassHalf"]
 	FontAwesomeIconInfoCircle          = faUsedIcons["InfoCircle"]
 	FontAwesomeIconKeyboard            = faUsedIcons["Keyboard"]
 	FontAwesomeIconLevelUpAlt          = faUsedIcons["LevelUpAlt"]
+	FontAwesomeIconLink                = faUsedIcons["Link"]
 	FontAwesomeIconLock                = faUsedIcons["Lock"]
 	FontAwesomeIconMouse               = faUsedIcons["Mouse"]
 	FontAwesomeIconPauseCircle         = faUsedIcons["PauseCircle"]
+	FontAwesomeIconPhone               = faUsedIcons["Phone"]
 	FontAwesomeIconPlayCircle          = faUsedIcons["PlayCircle"]
 	FontAwesomeIconQuestionCircle      = faUsedIcons["QuestionCircle"]
 	FontAwesomeIconPlaneDeparture      = faUsedIcons["PlaneDeparture"]
 	FontAwesomeIconRedo                = faUsedIcons["Redo"]
+	FontAwesomeIconRoute               = faUsedIcons["Route"]
+	FontAwesomeIconSignOutAlt          = faUsedIcons["SignOutAlt"]
 	FontAwesomeIconSquare              = faUsedIcons["Square"]
+	FontAwesomeIconStethoscope         = faUsedIcons["Stethoscope"]
+	FontAwesomeIconThLarge             = faUsedIcons["ThLarge"]
+	FontAwesomeIconTimes               = faUsedIcons["Times"]
 	FontAwesomeIconTrash               = faUsedIcons["Trash"]
+	FontAwesomeIconUsers               = faUsedIcons["Users"]
 )
 
 var (
@@ -192,31 +211,49 @@ var (
 		"ArrowRight":          FontAwesomeString("ArrowRight"),
 		"ArrowUp":             FontAwesomeString("ArrowUp"),
 		"Book":                FontAwesomeString("Book"),
+		"Bookmark":            FontAwesomeString("Bookmark"),
+		"Bullhorn":            FontAwesomeString("Bullhorn"),
 		"Bug":                 FontAwesomeString("Bug"),
 		"CaretDown":           FontAwesomeString("CaretDown"),
 		"CaretRight":          FontAwesomeString("CaretRight"),
 		"CheckSquare":         FontAwesomeString("CheckSquare"),
+		"ClipboardList":       FontAwesomeString("ClipboardList"),
 		"CompressAlt":         FontAwesomeString("CompressAlt"),
 		"Cog":                 FontAwesomeString("Cog"),
+		"Columns":             FontAwesomeString("Columns"),
+		"Compass":             FontAwesomeString("Compass"),
 		"Copyright":           FontAwesomeString("Copyright"),
+		"EllipsisH":           FontAwesomeString("EllipsisH"),
 		"ExclamationTriangle": FontAwesomeString("ExclamationTriangle"),
 		"ExpandAlt":           FontAwesomeString("ExpandAlt"),
 		"File":                FontAwesomeString("File"),
+		"FileExport":          FontAwesomeString("FileExport"),
 		"Folder":              FontAwesomeString("Folder"),
+		"GraduationCap":       FontAwesomeString("GraduationCap"),
 		"HandPointLeft":       FontAwesomeString("HandPointLeft"),
+		"Headset":             FontAwesomeString("Headset"),
 		"Home":                FontAwesomeString("Home"),
+		"HourglassHalf":       FontAwesomeString("HourglassHalf"),
 		"InfoCircle":          FontAwesomeString("InfoCircle"),
 		"Keyboard":            FontAwesomeString("Keyboard"),
 		"LevelUpAlt":          FontAwesomeString("LevelUpAlt"),
+		"Link":                FontAwesomeString("Link"),
 		"Lock":                FontAwesomeString("Lock"),
 		"Mouse":               FontAwesomeString("Mouse"),
 		"PauseCircle":         FontAwesomeString("PauseCircle"),
+		"Phone":               FontAwesomeString("Phone"),
 		"PlayCircle":          FontAwesomeString("PlayCircle"),
 		"QuestionCircle":      FontAwesomeString("QuestionCircle"),
 		"PlaneDeparture":      FontAwesomeString("PlaneDeparture"),
 		"Redo":                FontAwesomeString("Redo"),
+		"Route":               FontAwesomeString("Route"),
+		"SignOutAlt":          FontAwesomeString("SignOutAlt"),
 		"Square":              FontAwesomeString("Square"),
+		"Stethoscope":         FontAwesomeString("Stethoscope"),
+		"ThLarge":             FontAwesomeString("ThLarge"),
+		"Times":               FontAwesomeString("Times"),
 		"Trash":               FontAwesomeString("Trash"),
+		"Users":               FontAwesomeString("Users"),
 	}
 	faBrandsUsedIcons map[string]string = map[string]string{
 		"Discord": FontAwesomeBrandsString("Discord"),
@@ -234,9 +271,22 @@ func ptrToUint16Slice(p unsafe.Pointer) []uint16 {
 	return (*[unrealisticLargePointer / 2]uint16)(p)[:]
 }
 
+// fontsDPIScale records the DPI scale that the fonts were rasterized at.
+var fontsDPIScale float32 = 1
+
+// FontsDPIScale returns the DPI scale that the font atlas was built for;
+// when the window moves to a display with a different scale, the fonts
+// must be scaled accordingly.
+func FontsDPIScale() float32 {
+	return fontsDPIScale
+}
+
 func FontsInit(r Renderer, p platform.Platform) {
 	lg.Info("Starting to initialize fonts")
 	fonts = make(map[FontIdentifier]*Font)
+	if runtime.GOOS == "windows" {
+		fontsDPIScale = math.Max(1, p.DPIScale())
+	}
 	io := imgui.CurrentIO()
 
 	// Given a map that specifies the icons used in an icon font, returns
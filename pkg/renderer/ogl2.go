@@ -24,6 +24,7 @@ var lg *log.Logger
 type OpenGL2Renderer struct {
 	lg              *log.Logger
 	createdTextures map[uint32]int
+	smoothLines     bool
 }
 
 // NewOpenGL2Renderer creates an OpenGL context and creates a texture for the imgui fonts.
@@ -46,6 +47,13 @@ func NewOpenGL2Renderer(l *log.Logger) (Renderer, error) {
 	}, nil
 }
 
+func (ogl2 *OpenGL2Renderer) SetLineSmoothing(smooth bool) {
+	ogl2.smoothLines = smooth
+	if smooth {
+		gl.Hint(gl.LINE_SMOOTH_HINT, gl.NICEST)
+	}
+}
+
 func (ogl2 *OpenGL2Renderer) Dispose() {
 	for texid := range ogl2.createdTextures {
 		gl.DeleteTextures(1, &texid)
@@ -270,7 +278,23 @@ func (ogl2 *OpenGL2Renderer) RenderCommandBuffer(cb *CommandBuffer) RendererStat
 			offset := ui32()
 			ptr := offsetPtr(offset)
 			count := i32()
-			gl.DrawElements(gl.LINES, count, gl.UNSIGNED_INT, ptr)
+			if ogl2.smoothLines {
+				// Line smoothing works by modulating alpha, so blending
+				// must be enabled for it to have any effect.
+				blend := gl.IsEnabled(gl.BLEND)
+				if !blend {
+					gl.Enable(gl.BLEND)
+					gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
+				}
+				gl.Enable(gl.LINE_SMOOTH)
+				gl.DrawElements(gl.LINES, count, gl.UNSIGNED_INT, ptr)
+				gl.Disable(gl.LINE_SMOOTH)
+				if !blend {
+					gl.Disable(gl.BLEND)
+				}
+			} else {
+				gl.DrawElements(gl.LINES, count, gl.UNSIGNED_INT, ptr)
+			}
 
 			stats.nDrawCalls++
 			stats.nLines += int(count / 2)
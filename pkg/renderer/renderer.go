@@ -44,6 +44,9 @@ type Renderer interface {
 	// width and height.
 	ReadPixelRGBAs(x, y, width, height int) []uint8
 
+	// SetLineSmoothing enables or disables anti-aliasing of lines.
+	SetLineSmoothing(smooth bool)
+
 	// Dispose releases resources allocated by the renderer.
 	Dispose()
 }
@@ -0,0 +1,70 @@
+// pkg/sim/annotation.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"time"
+
+	"github.com/mmp/vice/pkg/math"
+)
+
+// Controllers can draw annotations on their scopes and optionally share
+// them with the other controllers in the sim, who see them on their own
+// scopes. The sim doesn't keep the annotations; it just passes them along
+// as events.
+
+type AnnotationType int
+
+const (
+	// AnnotationNone, when shared, clears the annotations previously
+	// shared by the controller.
+	AnnotationNone AnnotationType = iota
+	AnnotationFreehand
+	AnnotationLine
+	AnnotationCircle
+	AnnotationText
+	// AnnotationHighlight briefly highlights a location.
+	AnnotationHighlight
+	// AnnotationRemoveLast, when shared, removes the last annotation
+	// shared by the controller.
+	AnnotationRemoveLast
+)
+
+func (a AnnotationType) String() string {
+	return []string{"None", "Freehand", "Line", "Circle", "Text", "Highlight", "Remove last"}[a]
+}
+
+type ScopeAnnotation struct {
+	Type   AnnotationType
+	Points []math.Point2LL // freehand path, line endpoints, circle center, text position
+	Radius float32         // circles, in nm
+	Text   string
+	// Expires is zero if the annotation persists until it is cleared.
+	Expires time.Time
+	// From is the callsign of the controller who shared the annotation;
+	// it is empty for the user's own annotations.
+	From string `json:",omitempty"`
+	// Shared records whether the user's own annotation was shared, so
+	// that removing it can be shared as well.
+	Shared bool `json:",omitempty"`
+}
+
+func (s *Sim) ShareAnnotation(token string, a ScopeAnnotation) error {
+	s.mu.Lock(s.lg)
+	defer s.mu.Unlock(s.lg)
+
+	ctrl, ok := s.controllers[token]
+	if !ok {
+		return ErrInvalidControllerToken
+	}
+
+	a.From = ctrl.Callsign
+	s.eventStream.Post(Event{
+		Type:           SharedAnnotationEvent,
+		FromController: ctrl.Callsign,
+		Annotation:     &a,
+	})
+	return nil
+}
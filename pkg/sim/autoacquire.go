@@ -0,0 +1,137 @@
+// pkg/sim/autoacquire.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"fmt"
+	"slices"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/util"
+)
+
+// AutoAcquisitionArea specifies where departures from the given airports
+// are automatically acquired: once a departure is airborne inside one of
+// the volumes, a track is started for the departure controller, who then
+// sees it with a full datablock. Departures from airports without an
+// auto-acquisition area are acquired close to the airport.
+type AutoAcquisitionArea struct {
+	Name     string              `json:"name"`
+	Airports []string            `json:"airports"`
+	Volumes  []av.AirspaceVolume `json:"volumes"`
+}
+
+// Inside returns whether the aircraft is airborne inside the area.
+func (a *AutoAcquisitionArea) Inside(ac *av.Aircraft) bool {
+	if ac.Altitude() <= ac.DepartureAirportElevation()+50 {
+		// Still on the ground
+		return false
+	}
+	pos, alt := ac.Position(), int(ac.Altitude())
+	return slices.ContainsFunc(a.Volumes, func(v av.AirspaceVolume) bool { return v.Inside(pos, alt) })
+}
+
+// Approaching returns whether a departure from one of the area's airports
+// may still be on its way into the area: it's within 2nm of its
+// departure airport or it's below the ceiling of one of the volumes and
+// either laterally inside it or no farther from the airport than the
+// volume's far edge, as it is while climbing out toward an area that
+// doesn't start at the airport.
+func (a *AutoAcquisitionArea) Approaching(ac *av.Aircraft) bool {
+	pos, alt := ac.Position(), int(ac.Altitude())
+	ap, ok := av.DB.Airports[ac.FlightPlan.DepartureAirport]
+	if ok && math.NMDistance2LL(ap.Location, pos) <= 2 {
+		return true
+	}
+	return slices.ContainsFunc(a.Volumes, func(v av.AirspaceVolume) bool {
+		if alt > v.Ceiling {
+			return false
+		}
+		return v.Inside(pos, max(alt, v.Floor+1)) ||
+			(ok && math.NMDistance2LL(ap.Location, pos) <= volumeFarthestDistance(v, ap.Location))
+	})
+}
+
+// volumeFarthestDistance returns the distance in nm from p to the point
+// of the volume that is farthest from it.
+func volumeFarthestDistance(v av.AirspaceVolume, p math.Point2LL) float32 {
+	if v.Type == av.AirspaceVolumeCircle {
+		return math.NMDistance2LL(p, v.Center) + v.Radius
+	}
+	var d float32
+	for _, vtx := range v.Vertices {
+		d = max(d, math.NMDistance2LL(p, vtx))
+	}
+	return d
+}
+
+// AutoAcquisitionArea returns the auto-acquisition area for the
+// aircraft's departure airport or nil if there isn't one.
+func (fa *STARSFacilityAdaptation) AutoAcquisitionArea(ac *av.Aircraft) *AutoAcquisitionArea {
+	if ac.FlightPlan == nil {
+		return nil
+	}
+	for i, area := range fa.AutoAcquisitionAreas {
+		if slices.Contains(area.Airports, ac.FlightPlan.DepartureAirport) {
+			return &fa.AutoAcquisitionAreas[i]
+		}
+	}
+	return nil
+}
+
+func (fa *STARSFacilityAdaptation) checkAutoAcquisitionAreas(e *util.ErrorLogger, sg *ScenarioGroup) {
+	seen := make(map[string]string)
+	for _, area := range fa.AutoAcquisitionAreas {
+		e.Push("\"auto_acquisition_areas\" " + area.Name)
+
+		if len(area.Airports) == 0 {
+			e.ErrorString("must specify \"airports\"")
+		}
+		for _, ap := range area.Airports {
+			if _, ok := sg.Airports[ap]; !ok {
+				e.ErrorString("airport %q not defined in scenario group", ap)
+			} else if other, ok := seen[ap]; ok {
+				e.ErrorString("airport %q is also in area %q", ap, other)
+			} else {
+				seen[ap] = area.Name
+			}
+		}
+		if len(area.Volumes) == 0 {
+			e.ErrorString("must specify \"volumes\"")
+		}
+		checkAirspaceVolumes(e, area.Volumes)
+
+		e.Pop()
+	}
+}
+
+// checkAirspaceVolumes reports errors for volumes that can't contain
+// anything.
+func checkAirspaceVolumes(e *util.ErrorLogger, volumes []av.AirspaceVolume) {
+	for i, v := range volumes {
+		name := v.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		e.Push("volume " + name)
+
+		if v.Floor >= v.Ceiling {
+			e.ErrorString("\"floor\" %d must be below \"ceiling\" %d", v.Floor, v.Ceiling)
+		}
+		switch v.Type {
+		case av.AirspaceVolumePolygon:
+			if len(v.Vertices) < 3 {
+				e.ErrorString("must specify at least three \"vertices\"")
+			}
+		case av.AirspaceVolumeCircle:
+			if v.Radius <= 0 {
+				e.ErrorString("must specify a positive \"radius\"")
+			}
+		}
+
+		e.Pop()
+	}
+}
@@ -0,0 +1,196 @@
+// pkg/sim/autoacquire_test.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"testing"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/util"
+)
+
+// withTestAirports sets up a minimal database with KJFK at the origin,
+// KEWR to its west, and KBOS far away to the northeast.
+func withTestAirports(t *testing.T) {
+	db := av.DB
+	t.Cleanup(func() { av.DB = db })
+	av.DB = &av.StaticDatabase{
+		Airports: map[string]av.FAAAirport{
+			"KJFK": {Id: "KJFK", Elevation: 10, Location: math.Point2LL{0, 0}},
+			"KEWR": {Id: "KEWR", Elevation: 10, Location: math.Point2LL{-0.5, 0}},
+			"KBOS": {Id: "KBOS", Elevation: 10, Location: math.Point2LL{3, 3}},
+		},
+	}
+}
+
+// testAcquisitionAdaptation has an auto-acquisition area for KJFK that
+// starts about 6nm east of the airport.
+func testAcquisitionAdaptation() *STARSFacilityAdaptation {
+	return &STARSFacilityAdaptation{
+		AutoAcquisitionAreas: []AutoAcquisitionArea{{
+			Name:     "JFK",
+			Airports: []string{"KJFK"},
+			Volumes: []av.AirspaceVolume{{
+				Type:     av.AirspaceVolumePolygon,
+				Floor:    500,
+				Ceiling:  5000,
+				Vertices: []math.Point2LL{{0.1, -0.1}, {0.3, -0.1}, {0.3, 0.1}, {0.1, 0.1}},
+			}},
+		}},
+	}
+}
+
+func makeTestDeparture(airport string, pos math.Point2LL, alt float32) *av.Aircraft {
+	ac := &av.Aircraft{
+		Callsign:   "AAL1",
+		FlightPlan: &av.FlightPlan{DepartureAirport: airport, ArrivalAirport: "KBOS"},
+	}
+	ac.Nav.FlightState.Position = pos
+	ac.Nav.FlightState.Altitude = alt
+	ac.Nav.FlightState.DepartureAirportElevation = 10
+	return ac
+}
+
+func TestAutoAcquisitionArea(t *testing.T) {
+	fa := testAcquisitionAdaptation()
+
+	if area := fa.AutoAcquisitionArea(makeTestDeparture("KJFK", math.Point2LL{}, 10)); area == nil || area.Name != "JFK" {
+		t.Errorf("expected the JFK area for a KJFK departure, got %v", area)
+	}
+	if area := fa.AutoAcquisitionArea(makeTestDeparture("KEWR", math.Point2LL{}, 10)); area != nil {
+		t.Errorf("expected no area for a KEWR departure, got %v", area)
+	}
+	if area := fa.AutoAcquisitionArea(&av.Aircraft{Callsign: "N123"}); area != nil {
+		t.Errorf("expected no area without a flight plan, got %v", area)
+	}
+
+	area := &fa.AutoAcquisitionAreas[0]
+	if !area.Inside(makeTestDeparture("KJFK", math.Point2LL{0.2, 0}, 3000)) {
+		t.Errorf("expected to be inside the area")
+	}
+	if area.Inside(makeTestDeparture("KJFK", math.Point2LL{0.2, 0}, 300)) {
+		t.Errorf("expected to be below the area")
+	}
+	if area.Inside(makeTestDeparture("KJFK", math.Point2LL{0.5, 0}, 3000)) {
+		t.Errorf("expected to be outside the area")
+	}
+}
+
+func TestInAcquisitionArea(t *testing.T) {
+	withTestAirports(t)
+	fa := testAcquisitionAdaptation()
+
+	for _, test := range []struct {
+		name        string
+		airport     string
+		pos         math.Point2LL
+		alt         float32
+		acquire     bool // inAcquisitionArea
+		approaching bool // approachingAcquisitionArea
+	}{
+		{name: "on the ground at KJFK", airport: "KJFK", pos: math.Point2LL{0, 0}, alt: 10,
+			approaching: true},
+		{name: "airborne near KJFK, short of the area", airport: "KJFK", pos: math.Point2LL{0.025, 0}, alt: 1000,
+			approaching: true},
+		{name: "between KJFK and its area", airport: "KJFK", pos: math.Point2LL{0.06, 0}, alt: 1500,
+			approaching: true},
+		{name: "away from KJFK, opposite the area", airport: "KJFK", pos: math.Point2LL{-0.4, 0}, alt: 4000},
+		{name: "below the KJFK area", airport: "KJFK", pos: math.Point2LL{0.2, 0}, alt: 300,
+			approaching: true},
+		{name: "inside the KJFK area", airport: "KJFK", pos: math.Point2LL{0.2, 0}, alt: 3000,
+			acquire: true, approaching: true},
+		{name: "past the KJFK area", airport: "KJFK", pos: math.Point2LL{0.5, 0}, alt: 6000},
+		{name: "airborne near KEWR", airport: "KEWR", pos: math.Point2LL{-0.475, 0}, alt: 1000,
+			acquire: true},
+		{name: "away from KEWR", airport: "KEWR", pos: math.Point2LL{-0.3, 0}, alt: 4000},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ac := makeTestDeparture(test.airport, test.pos, test.alt)
+			if got := inAcquisitionArea(ac, fa); got != test.acquire {
+				t.Errorf("inAcquisitionArea: got %v, expected %v", got, test.acquire)
+			}
+			if got := approachingAcquisitionArea(ac, fa); got != test.approaching {
+				t.Errorf("approachingAcquisitionArea: got %v, expected %v", got, test.approaching)
+			}
+		})
+	}
+}
+
+func TestUpdateAssociatedFlightPlansWaitsForArea(t *testing.T) {
+	withTestAirports(t)
+	fa := testAcquisitionAdaptation()
+
+	ac := makeTestDeparture("KJFK", math.Point2LL{0.025, 0}, 1000)
+	ac.Squawk = av.Squawk(0o1234)
+	comp := &STARSComputer{
+		ContainedPlans:   map[av.Squawk]*STARSFlightPlan{ac.Squawk: {}},
+		TrackInformation: make(map[string]*TrackInformation),
+	}
+
+	comp.UpdateAssociatedFlightPlans([]*av.Aircraft{ac}, fa)
+	if comp.TrackInformation[ac.Callsign] != nil {
+		t.Errorf("flight plan associated before the departure reached the acquisition area")
+	}
+
+	// More than 2nm from the airport but not yet in the area.
+	ac.Nav.FlightState.Position, ac.Nav.FlightState.Altitude = math.Point2LL{0.06, 0}, 1500
+	comp.UpdateAssociatedFlightPlans([]*av.Aircraft{ac}, fa)
+	if comp.TrackInformation[ac.Callsign] != nil {
+		t.Errorf("flight plan associated while the departure was on its way to the acquisition area")
+	}
+
+	ac.Nav.FlightState.Position, ac.Nav.FlightState.Altitude = math.Point2LL{0.5, 0}, 6000
+	comp.UpdateAssociatedFlightPlans([]*av.Aircraft{ac}, fa)
+	if comp.TrackInformation[ac.Callsign] == nil {
+		t.Errorf("flight plan not associated after the departure left the acquisition area")
+	}
+}
+
+func TestCheckAutoAcquisitionAreas(t *testing.T) {
+	sg := &ScenarioGroup{Airports: map[string]*av.Airport{"KJFK": {}, "KEWR": {}}}
+	square := []math.Point2LL{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+
+	for _, test := range []struct {
+		name   string
+		area   AutoAcquisitionArea
+		errors bool
+	}{
+		{name: "valid", area: AutoAcquisitionArea{Name: "A", Airports: []string{"KJFK"},
+			Volumes: []av.AirspaceVolume{{Type: av.AirspaceVolumePolygon, Floor: 0, Ceiling: 3000, Vertices: square}}}},
+		{name: "no airports", errors: true, area: AutoAcquisitionArea{Name: "A",
+			Volumes: []av.AirspaceVolume{{Type: av.AirspaceVolumePolygon, Floor: 0, Ceiling: 3000, Vertices: square}}}},
+		{name: "unknown airport", errors: true, area: AutoAcquisitionArea{Name: "A", Airports: []string{"KLGA"},
+			Volumes: []av.AirspaceVolume{{Type: av.AirspaceVolumePolygon, Floor: 0, Ceiling: 3000, Vertices: square}}}},
+		{name: "no volumes", errors: true, area: AutoAcquisitionArea{Name: "A", Airports: []string{"KJFK"}}},
+		{name: "too few vertices", errors: true, area: AutoAcquisitionArea{Name: "A", Airports: []string{"KJFK"},
+			Volumes: []av.AirspaceVolume{{Type: av.AirspaceVolumePolygon, Floor: 0, Ceiling: 3000, Vertices: square[:2]}}}},
+		{name: "floor above ceiling", errors: true, area: AutoAcquisitionArea{Name: "A", Airports: []string{"KJFK"},
+			Volumes: []av.AirspaceVolume{{Type: av.AirspaceVolumePolygon, Floor: 5000, Ceiling: 3000, Vertices: square}}}},
+		{name: "zero radius", errors: true, area: AutoAcquisitionArea{Name: "A", Airports: []string{"KJFK"},
+			Volumes: []av.AirspaceVolume{{Type: av.AirspaceVolumeCircle, Floor: 0, Ceiling: 3000}}}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fa := &STARSFacilityAdaptation{AutoAcquisitionAreas: []AutoAcquisitionArea{test.area}}
+			var e util.ErrorLogger
+			fa.checkAutoAcquisitionAreas(&e, sg)
+			if e.HaveErrors() != test.errors {
+				t.Errorf("errors: got %v, expected %v: %s", e.HaveErrors(), test.errors, e.String())
+			}
+		})
+	}
+
+	// An airport may only be in one area.
+	valid := AutoAcquisitionArea{Name: "A", Airports: []string{"KJFK"},
+		Volumes: []av.AirspaceVolume{{Type: av.AirspaceVolumePolygon, Floor: 0, Ceiling: 3000, Vertices: square}}}
+	other := valid
+	other.Name = "B"
+	fa := &STARSFacilityAdaptation{AutoAcquisitionAreas: []AutoAcquisitionArea{valid, other}}
+	var e util.ErrorLogger
+	fa.checkAutoAcquisitionAreas(&e, sg)
+	if !e.HaveErrors() {
+		t.Errorf("expected an error for an airport in two areas")
+	}
+}
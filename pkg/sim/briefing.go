@@ -0,0 +1,129 @@
+// pkg/sim/briefing.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/util"
+)
+
+// ReliefBriefing returns a position relief briefing for the current
+// controller that summarizes the weather, runway configuration, open
+// positions, and traffic situation. The provided notes (e.g., holds or
+// non-standard operations the controller wants to pass along) are
+// included at the end.
+func (ss *State) ReliefBriefing(notes string) string {
+	var b strings.Builder
+	section := func(title string, lines []string) {
+		b.WriteString(title + ":\n")
+		if len(lines) == 0 {
+			b.WriteString("  NONE\n")
+		}
+		for _, l := range lines {
+			b.WriteString("  " + l + "\n")
+		}
+	}
+
+	b.WriteString(fmt.Sprintf("RELIEF BRIEFING %s %s\n", ss.Callsign, ss.SimTime.UTC().Format("1504Z")))
+
+	// Weather
+	var wx []string
+	for _, ap := range util.SortedMapKeys(ss.METAR) {
+		m := ss.METAR[ap]
+		s := ap + " " + m.Wind + " " + m.Altimeter
+		if m.Weather != "" {
+			s += " " + m.Weather
+		}
+		wx = append(wx, s)
+	}
+	section("WEATHER", wx)
+
+	// Runway configuration
+	runways := func(airport, runway string, m map[string][]string) {
+		if !slices.Contains(m[airport], runway) {
+			m[airport] = append(m[airport], runway)
+		}
+	}
+	arr, dep := make(map[string][]string), make(map[string][]string)
+	for _, rwy := range ss.ArrivalRunways {
+		runways(rwy.Airport, rwy.Runway, arr)
+	}
+	for _, rwy := range ss.DepartureRunways {
+		runways(rwy.Airport, rwy.Runway, dep)
+	}
+	var rwys []string
+	for _, ap := range util.SortedMapKeys(ss.Airports) {
+		if len(arr[ap]) > 0 || len(dep[ap]) > 0 {
+			rwys = append(rwys, fmt.Sprintf("%s LANDING %s DEPARTING %s", ap,
+				util.Select(len(arr[ap]) > 0, strings.Join(arr[ap], " "), "-"),
+				util.Select(len(dep[ap]) > 0, strings.Join(dep[ap], " "), "-")))
+		}
+	}
+	section("RUNWAYS", rwys)
+
+	// Open positions
+	var positions []string
+	for _, callsign := range util.SortedMapKeys(ss.Controllers) {
+		if ctrl := ss.Controllers[callsign]; ctrl.IsHuman {
+			s := ctrl.SectorId + " " + callsign
+			if !ctrl.SignOnTime.IsZero() {
+				s += " SINCE " + ctrl.SignOnTime.UTC().Format("1504Z")
+			}
+			positions = append(positions, s)
+		}
+	}
+	section("OPEN POSITIONS", positions)
+
+	// Traffic
+	var tracked, handoffs, pointOuts, nonStandard []string
+	for _, callsign := range util.SortedMapKeys(ss.Aircraft) {
+		ac := ss.Aircraft[callsign]
+		if ac.HandoffTrackController == ss.Callsign {
+			handoffs = append(handoffs, callsign+" FROM "+ac.TrackingController)
+		}
+		if ac.TrackingController != ss.Callsign {
+			continue
+		}
+
+		tracked = append(tracked, callsign)
+		if ac.HandoffTrackController != "" {
+			handoffs = append(handoffs, callsign+" TO "+ac.HandoffTrackController)
+		}
+		if len(ac.PointOutHistory) > 0 {
+			pointOuts = append(pointOuts, callsign+" "+strings.Join(ac.PointOutHistory, " "))
+		}
+		if ok, code := av.SquawkIsSPC(ac.Squawk); ok {
+			nonStandard = append(nonStandard, callsign+" "+code)
+		} else if ac.SPCOverride != "" {
+			nonStandard = append(nonStandard, callsign+" "+ac.SPCOverride)
+		}
+	}
+	for _, ac := range ss.GetReleaseDepartures() {
+		if !ac.Released {
+			nonStandard = append(nonStandard, ac.Callsign+" AWAITING RELEASE")
+		}
+	}
+	if len(tracked) > 0 {
+		tracked = []string{fmt.Sprintf("%d TRACKED: %s", len(tracked), strings.Join(tracked, " "))}
+	}
+	section("TRAFFIC", tracked)
+	section("HANDOFFS", handoffs)
+	section("POINT OUTS", pointOuts)
+	section("NON-STANDARD", nonStandard)
+
+	var noteLines []string
+	for _, l := range strings.Split(notes, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			noteLines = append(noteLines, strings.ToUpper(l))
+		}
+	}
+	section("NOTES", noteLines)
+
+	return b.String()
+}
@@ -0,0 +1,38 @@
+// pkg/sim/bulletin.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Facility bulletins are plain text, served from the scenario group's
+// "bulletin_url"; any longer than this are truncated.
+const maxBulletinSize = 64 * 1024
+
+// FetchBulletin returns the text of the facility bulletin at the given
+// URL.
+func FetchBulletin(bulletinURL string) (string, error) {
+	client := http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(bulletinURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: %s", bulletinURL, resp.Status)
+	}
+
+	text, err := io.ReadAll(io.LimitReader(resp.Body, maxBulletinSize))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", bulletinURL, err)
+	}
+	return strings.TrimSpace(strings.ReplaceAll(string(text), "\r\n", "\n")), nil
+}
@@ -39,6 +39,11 @@ type ControlClient struct {
 		overflights map[string]map[int]bool               // group->index
 	}
 
+	// Client-side radio state; not shared with the server.
+	Radio Radio
+	// Recorded aircraft track histories, for exporting.
+	Trails TrailRecorder
+
 	// This is all read-only data that we expect other parts of the system
 	// to access directly.
 	State
@@ -49,7 +54,7 @@ func (c *ControlClient) RPCClient() *util.RPCClient {
 }
 
 func NewControlClient(ss State, controllerToken string, client *util.RPCClient, lg *log.Logger) *ControlClient {
-	return &ControlClient{
+	c := &ControlClient{
 		State: ss,
 		lg:    lg,
 		proxy: &proxy{
@@ -58,6 +63,8 @@ func NewControlClient(ss State, controllerToken string, client *util.RPCClient,
 		},
 		lastUpdateRequest: time.Now(),
 	}
+	c.Radio.Update(&c.State)
+	return c
 }
 
 func (c *ControlClient) Status() string {
@@ -66,6 +73,10 @@ func (c *ControlClient) Status() string {
 	} else {
 		deparr := fmt.Sprintf(" [ %d departures %d arrivals %d overflights ]",
 			c.TotalDepartures, c.TotalArrivals, c.TotalOverflights)
+		if n := c.ReadbackErrorsCaught + c.ReadbackErrorsMissed; n > 0 {
+			deparr += fmt.Sprintf(" [ readback errors caught %d/%d (%d%%) ]", c.ReadbackErrorsCaught, n,
+				100*c.ReadbackErrorsCaught/n)
+		}
 		if c.SimName == "" {
 			return c.State.Callsign + ": " + c.SimDescription + deparr
 		} else {
@@ -112,6 +123,25 @@ func (c *ControlClient) SendGlobalMessage(global GlobalMessage) {
 		})
 }
 
+// ShareAnnotation sends a scope annotation to the other controllers.
+func (c *ControlClient) ShareAnnotation(a ScopeAnnotation) {
+	c.pendingCalls = append(c.pendingCalls,
+		&util.PendingCall{
+			Call:      c.proxy.ShareAnnotation(a),
+			IssueTime: time.Now(),
+		})
+}
+
+// ChangeSplit switches the sim to the given split configuration.
+func (c *ControlClient) ChangeSplit(split string, err func(error)) {
+	c.pendingCalls = append(c.pendingCalls,
+		&util.PendingCall{
+			Call:      c.proxy.ChangeSplit(split),
+			IssueTime: time.Now(),
+			OnErr:     err,
+		})
+}
+
 func (c *ControlClient) SetScratchpad(callsign string, scratchpad string, success func(any), err func(error)) {
 	if ac := c.State.Aircraft[callsign]; ac != nil && ac.TrackingController == c.State.Callsign {
 		ac.Scratchpad = scratchpad
@@ -154,6 +184,34 @@ func (c *ControlClient) SetTemporaryAltitude(callsign string, alt int, success f
 		})
 }
 
+func (c *ControlClient) SetTemporaryHeading(callsign string, heading int, success func(any), err func(error)) {
+	if ac := c.State.Aircraft[callsign]; ac != nil && ac.TrackingController == c.State.Callsign {
+		ac.TempHeading = heading
+	}
+
+	c.pendingCalls = append(c.pendingCalls,
+		&util.PendingCall{
+			Call:      c.proxy.SetTemporaryHeading(callsign, heading),
+			IssueTime: time.Now(),
+			OnSuccess: success,
+			OnErr:     err,
+		})
+}
+
+func (c *ControlClient) SetTemporarySpeed(callsign string, speed int, success func(any), err func(error)) {
+	if ac := c.State.Aircraft[callsign]; ac != nil && ac.TrackingController == c.State.Callsign {
+		ac.TempSpeed = speed
+	}
+
+	c.pendingCalls = append(c.pendingCalls,
+		&util.PendingCall{
+			Call:      c.proxy.SetTemporarySpeed(callsign, speed),
+			IssueTime: time.Now(),
+			OnSuccess: success,
+			OnErr:     err,
+		})
+}
+
 func (c *ControlClient) AmendFlightPlan(callsign string, fp av.FlightPlan) error {
 	return nil // UNIMPLEMENTED
 }
@@ -356,6 +414,16 @@ func (c *ControlClient) ReleaseDeparture(callsign string, success func(any), err
 		})
 }
 
+func (c *ControlClient) SetEDCT(callsign string, edct time.Time, success func(any), err func(error)) {
+	c.pendingCalls = append(c.pendingCalls,
+		&util.PendingCall{
+			Call:      c.proxy.SetEDCT(callsign, edct),
+			IssueTime: time.Now(),
+			OnSuccess: success,
+			OnErr:     err,
+		})
+}
+
 func (c *ControlClient) ChangeControlPosition(callsign string, keepTracks bool) error {
 	err := c.proxy.ChangeControlPosition(callsign, keepTracks)
 	if err == nil {
@@ -459,6 +527,19 @@ func (c *ControlClient) UpdateWorld(wu *WorldUpdate, eventStream *EventStream) {
 	c.State.TotalDepartures = wu.TotalDepartures
 	c.State.TotalArrivals = wu.TotalArrivals
 	c.State.TotalOverflights = wu.TotalOverflights
+	c.State.ReadbackErrorsCaught = wu.ReadbackErrorsCaught
+	c.State.ReadbackErrorsMissed = wu.ReadbackErrorsMissed
+
+	for _, e := range wu.Events {
+		if e.Type == SplitChangedEvent {
+			if err := c.State.changeSplit(e.Message); err != nil {
+				c.lg.Errorf("%s: unable to change split: %v", e.Message, err)
+			}
+		}
+	}
+
+	c.Radio.Update(&c.State)
+	c.Trails.Update(&c.State, wu.Events)
 
 	// Important: do this after updating aircraft, controllers, etc.,
 	// so that they reflect any changes the events are flagging.
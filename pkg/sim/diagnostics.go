@@ -0,0 +1,119 @@
+// pkg/sim/diagnostics.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"fmt"
+	"slices"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/util"
+)
+
+// Diagnostic describes a problem found when checking the configuration
+// of vice or the facility adaptation of the current scenario.
+type Diagnostic struct {
+	Category string
+	Message  string
+	// Warning is set for problems that may be intentional.
+	Warning bool
+	// If the problem concerns a fix that can be located, Fix gives its
+	// name and Location its position.
+	Fix      string
+	Location math.Point2LL
+}
+
+// diagnosticFixRange is how far, as a multiple of the scope's range, a
+// fix referenced by the adaptation can be from the scope's center before
+// it is reported as suspicious.
+const diagnosticFixRange = 3
+
+// CheckAdaptation checks the facility adaptation for problems that aren't
+// caught when the scenario is loaded, such as references to video maps
+// that aren't in the video map file and to fixes that can't be found or
+// are far from the facility.
+func (ss *State) CheckAdaptation() []Diagnostic {
+	var diags []Diagnostic
+	fa := &ss.STARSFacilityAdaptation
+
+	// Video maps
+	mapNames := fa.VideoMapNames
+	if config, ok := fa.ControllerConfigs[ss.Callsign]; ok && len(config.VideoMapNames) > 0 {
+		mapNames = config.VideoMapNames
+	}
+	haveMap := func(name string) bool {
+		return slices.ContainsFunc(ss.ControllerVideoMaps, func(vm av.VideoMap) bool { return vm.Name == name })
+	}
+	for _, name := range mapNames {
+		if name != "" && !haveMap(name) {
+			diags = append(diags, Diagnostic{
+				Category: "Video maps",
+				Message:  name + ": video map not found in \"" + fa.VideoMapFile + "\"",
+			})
+		}
+	}
+	for _, name := range ss.ControllerDefaultVideoMaps {
+		if !haveMap(name) {
+			diags = append(diags, Diagnostic{
+				Category: "Video maps",
+				Message:  name + ": default video map is not one of the position's maps",
+			})
+		}
+	}
+	for _, f := range fa.RunwayFlowMaps {
+		for _, name := range f.Maps {
+			if !haveMap(name) {
+				diags = append(diags, Diagnostic{
+					Category: "Video maps",
+					Message:  f.Name + ": runway flow video map " + name + " is not one of the position's maps",
+				})
+			}
+		}
+	}
+
+	// Fixes referenced by rules
+	center, rng := ss.GetInitialCenter(), ss.GetInitialRange()
+	checkFix := func(category, fix, context string) {
+		if p, ok := ss.Locate(fix); !ok {
+			diags = append(diags, Diagnostic{
+				Category: category,
+				Message:  fmt.Sprintf("%s: %s references unknown fix", fix, context),
+			})
+		} else if d := math.NMDistance2LL(center, p); d > diagnosticFixRange*rng {
+			diags = append(diags, Diagnostic{
+				Category: category,
+				Message:  fmt.Sprintf("%s: %s references fix %.0f nm from the scope center", fix, context, d),
+				Warning:  true,
+				Fix:      fix,
+				Location: p,
+			})
+		}
+	}
+	for _, aa := range fa.AirspaceAwareness {
+		for _, fix := range aa.Fix {
+			if fix != "ALL" {
+				checkFix("Airspace awareness", fix, "rule for "+aa.ReceivingController)
+			}
+		}
+	}
+	for _, fix := range util.SortedMapKeys(fa.CoordinationFixes) {
+		checkFix("Coordination fixes", fix, "coordination fix")
+	}
+	for _, name := range util.SortedMapKeys(fa.SignificantPoints) {
+		sp := fa.SignificantPoints[name]
+		if d := math.NMDistance2LL(center, sp.Location); d > diagnosticFixRange*rng {
+			diags = append(diags, Diagnostic{
+				Category: "Significant points",
+				Message:  fmt.Sprintf("%s: significant point %.0f nm from the scope center", name, d),
+				Warning:  true,
+				Fix:      name,
+				Location: sp.Location,
+			})
+		}
+	}
+
+	return diags
+}
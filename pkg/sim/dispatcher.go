@@ -7,6 +7,7 @@ package sim
 import (
 	"strconv"
 	"strings"
+	"time"
 
 	av "github.com/mmp/vice/pkg/aviation"
 	"github.com/mmp/vice/pkg/math"
@@ -263,6 +264,9 @@ type GlobalMessageArgs struct {
 	ControllerToken string
 	FromController  string
 	Message         string
+	Guard           bool
+	ToControllers   []string
+	Landline        LandlineType
 }
 
 func (sd *Dispatcher) GlobalMessage(po *GlobalMessageArgs, _ *struct{}) error {
@@ -273,6 +277,32 @@ func (sd *Dispatcher) GlobalMessage(po *GlobalMessageArgs, _ *struct{}) error {
 	}
 }
 
+type ShareAnnotationArgs struct {
+	ControllerToken string
+	Annotation      ScopeAnnotation
+}
+
+func (sd *Dispatcher) ShareAnnotation(sa *ShareAnnotationArgs, _ *struct{}) error {
+	if sim, ok := sd.sm.controllerTokenToSim[sa.ControllerToken]; !ok {
+		return ErrNoSimForControllerToken
+	} else {
+		return sim.ShareAnnotation(sa.ControllerToken, sa.Annotation)
+	}
+}
+
+type ChangeSplitArgs struct {
+	ControllerToken string
+	Split           string
+}
+
+func (sd *Dispatcher) ChangeSplit(cs *ChangeSplitArgs, _ *struct{}) error {
+	if sim, ok := sd.sm.controllerTokenToSim[cs.ControllerToken]; !ok {
+		return ErrNoSimForControllerToken
+	} else {
+		return sim.ChangeSplit(cs.ControllerToken, cs.Split)
+	}
+}
+
 func (sd *Dispatcher) PointOut(po *PointOutArgs, _ *struct{}) error {
 	if sim, ok := sd.sm.controllerTokenToSim[po.ControllerToken]; !ok {
 		return ErrNoSimForControllerToken
@@ -321,6 +351,20 @@ func (sd *Dispatcher) ReleaseDeparture(hd *HeldDepartureArgs, _ *struct{}) error
 	}
 }
 
+type SetEDCTArgs struct {
+	ControllerToken string
+	Callsign        string
+	EDCT            time.Time
+}
+
+func (sd *Dispatcher) SetEDCT(se *SetEDCTArgs, _ *struct{}) error {
+	if sim, ok := sd.sm.controllerTokenToSim[se.ControllerToken]; !ok {
+		return ErrNoSimForControllerToken
+	} else {
+		return sim.SetEDCT(se.ControllerToken, se.Callsign, se.EDCT)
+	}
+}
+
 type AssignAltitudeArgs struct {
 	ControllerToken string
 	Callsign        string
@@ -335,6 +379,28 @@ func (sd *Dispatcher) SetTemporaryAltitude(alt *AssignAltitudeArgs, _ *struct{})
 	}
 }
 
+type TemporaryAssignmentArgs struct {
+	ControllerToken string
+	Callsign        string
+	Value           int
+}
+
+func (sd *Dispatcher) SetTemporaryHeading(a *TemporaryAssignmentArgs, _ *struct{}) error {
+	if sim, ok := sd.sm.controllerTokenToSim[a.ControllerToken]; !ok {
+		return ErrNoSimForControllerToken
+	} else {
+		return sim.SetTemporaryHeading(a.ControllerToken, a.Callsign, a.Value)
+	}
+}
+
+func (sd *Dispatcher) SetTemporarySpeed(a *TemporaryAssignmentArgs, _ *struct{}) error {
+	if sim, ok := sd.sm.controllerTokenToSim[a.ControllerToken]; !ok {
+		return ErrNoSimForControllerToken
+	} else {
+		return sim.SetTemporarySpeed(a.ControllerToken, a.Callsign, a.Value)
+	}
+}
+
 type DeleteAircraftArgs AircraftSpecifier
 
 func (sd *Dispatcher) DeleteAllAircraft(da *DeleteAircraftArgs, _ *struct{}) error {
@@ -485,6 +551,12 @@ func (sd *Dispatcher) RunAircraftCommands(cmds *AircraftCommandsArgs, result *Ai
 						rewriteError(err)
 						return nil
 					}
+				case 'R':
+					// Direct <fix1>, then rejoin the route at <fix2>
+					if err := sim.DirectFixRejoin(token, callsign, fix, components[1][1:]); err != nil {
+						rewriteError(err)
+						return nil
+					}
 				case 'H':
 					// Depart <fix> at heading <hdg>
 					if hdg, err := strconv.Atoi(components[1][1:]); err != nil {
@@ -11,8 +11,10 @@ import (
 )
 
 var (
+	ErrAircraftAlreadyDeparted    = errors.New("Aircraft has already departed")
 	ErrAircraftAlreadyReleased    = errors.New("Aircraft already released")
 	ErrAircraftNotReleased        = errors.New("Aircraft not released")
+	ErrAmbiguousCallsign          = errors.New("Callsign matches more than one aircraft")
 	ErrBeaconMismatch             = errors.New("Beacon code mismatch")
 	ErrControllerAlreadySignedIn  = errors.New("Controller with that callsign already signed in")
 	ErrDuplicateSimName           = errors.New("A sim with that name already exists")
@@ -23,7 +25,9 @@ var (
 	ErrInvalidCommandSyntax       = errors.New("Invalid command syntax")
 	ErrInvalidControllerToken     = errors.New("Invalid controller token")
 	ErrInvalidDepartureController = errors.New("Invalid departure controller")
+	ErrInterceptPastRunway        = errors.New("Aircraft can't intercept the final approach course before the runway")
 	ErrInvalidPassword            = errors.New("Invalid password")
+	ErrNoAssignedApproach         = errors.New("Aircraft has not been assigned an approach")
 	ErrNoCoordinationFix          = errors.New("No coordination fix found")
 	ErrNoMatchingFlight           = errors.New("No matching flight")
 	ErrNoNamedSim                 = errors.New("No Sim with that name")
@@ -33,8 +37,10 @@ var (
 	ErrRPCVersionMismatch         = errors.New("Client and server RPC versions don't match")
 	ErrRestoringSavedState        = errors.New("Errors during state restoration")
 	ErrServerDisconnected         = errors.New("Server disconnected")
+	ErrSignedInPositionNotInSplit = errors.New("A signed-in position isn't in that split")
 	ErrUnknownFacility            = errors.New("Unknown facility (ARTCC/TRACON)")
 	ErrUnknownControllerFacility  = errors.New("Unknown controller facility")
+	ErrUnknownSplit               = errors.New("Unknown split")
 )
 
 var errorStringToError = map[string]error{
@@ -62,8 +68,10 @@ var errorStringToError = map[string]error{
 	av.ErrUnknownApproach.Error():              av.ErrUnknownApproach,
 	av.ErrUnknownRunway.Error():                av.ErrUnknownRunway,
 
+	ErrAircraftAlreadyDeparted.Error():    ErrAircraftAlreadyDeparted,
 	ErrAircraftAlreadyReleased.Error():    ErrAircraftAlreadyReleased,
 	ErrAircraftNotReleased.Error():        ErrAircraftNotReleased,
+	ErrAmbiguousCallsign.Error():          ErrAmbiguousCallsign,
 	ErrBeaconMismatch.Error():             ErrBeaconMismatch,
 	ErrControllerAlreadySignedIn.Error():  ErrControllerAlreadySignedIn,
 	ErrDuplicateSimName.Error():           ErrDuplicateSimName,
@@ -83,8 +91,10 @@ var errorStringToError = map[string]error{
 	ErrRPCVersionMismatch.Error():         ErrRPCVersionMismatch,
 	ErrRestoringSavedState.Error():        ErrRestoringSavedState,
 	ErrServerDisconnected.Error():         ErrServerDisconnected,
+	ErrSignedInPositionNotInSplit.Error(): ErrSignedInPositionNotInSplit,
 	ErrUnknownFacility.Error():            ErrUnknownFacility,
 	ErrUnknownControllerFacility.Error():  ErrUnknownControllerFacility,
+	ErrUnknownSplit.Error():               ErrUnknownSplit,
 }
 
 func TryDecodeError(e error) error {
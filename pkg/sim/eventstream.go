@@ -210,6 +210,10 @@ const (
 	ForceQLEvent
 	TransferAcceptedEvent
 	TransferRejectedEvent
+	GuardMessageEvent
+	LandlineMessageEvent
+	SharedAnnotationEvent
+	SplitChangedEvent
 	NumEventTypes
 )
 
@@ -218,7 +222,8 @@ func (t EventType) String() string {
 		"OfferedHandoff", "AcceptedHandoff", "AcceptedRedirectedHandoffEvent", "CanceledHandoff",
 		"RejectedHandoff", "RadioTransmission", "StatusMessage", "ServerBroadcastMessage",
 		"GlobalMessage", "AcknowledgedPointOut", "RejectedPointOut", "Ident", "HandoffControl",
-		"SetGlobalLeaderLine", "TrackClicked", "ForceQL", "TransferAccepted", "TransferRejected"}[t]
+		"SetGlobalLeaderLine", "TrackClicked", "ForceQL", "TransferAccepted", "TransferRejected", "GuardMessage",
+		"LandlineMessage", "SharedAnnotation", "SplitChanged"}[t]
 }
 
 type Event struct {
@@ -229,6 +234,8 @@ type Event struct {
 	Message               string
 	RadioTransmissionType av.RadioTransmissionType       // For radio transmissions only
 	LeaderLineDirection   *math.CardinalOrdinalDirection // SetGlobalLeaderLineEvent
+	Landline              LandlineType                   // LandlineMessageEvent
+	Annotation            *ScopeAnnotation               // SharedAnnotationEvent
 }
 
 func (e *Event) String() string {
@@ -0,0 +1,92 @@
+// pkg/sim/finals.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"slices"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/util"
+)
+
+const (
+	defaultFinalLength      = 15 // nm
+	defaultFinalTickSpacing = 1  // nm
+)
+
+// FinalApproachCourse specifies the depiction of a runway's extended
+// centerline on the scope.
+type FinalApproachCourse struct {
+	Airport string `json:"airport"`
+	Runway  string `json:"runway"`
+	// Length is the length of the centerline from the threshold in nm.
+	Length float32 `json:"length"`
+	// TickSpacing gives the distance in nm between the distance marks
+	// along the centerline; if zero, only the gates are marked.
+	TickSpacing float32 `json:"tick_spacing"`
+	// Gates are named marks at given distances from the threshold, e.g.
+	// for the final approach fix or a turn-on gate.
+	Gates []FinalApproachGate `json:"gates"`
+}
+
+type FinalApproachGate struct {
+	Name     string  `json:"name"`
+	Distance float32 `json:"distance"`
+}
+
+// ActiveFinals returns the final approach courses for the active arrival
+// runways, using the ones from the facility adaptation where available
+// and otherwise a default centerline with a mark every mile.
+func (ss *State) ActiveFinals() []FinalApproachCourse {
+	var finals []FinalApproachCourse
+	for _, rwy := range ss.ArrivalRunways {
+		idx := slices.IndexFunc(ss.STARSFacilityAdaptation.Finals, func(f FinalApproachCourse) bool {
+			return f.Airport == rwy.Airport && f.Runway == rwy.Runway
+		})
+		if idx != -1 {
+			finals = append(finals, ss.STARSFacilityAdaptation.Finals[idx])
+		} else {
+			finals = append(finals, FinalApproachCourse{
+				Airport:     rwy.Airport,
+				Runway:      rwy.Runway,
+				Length:      defaultFinalLength,
+				TickSpacing: defaultFinalTickSpacing,
+			})
+		}
+	}
+	return finals
+}
+
+func (s *STARSFacilityAdaptation) checkFinals(e *util.ErrorLogger) {
+	for i := range s.Finals {
+		f := &s.Finals[i]
+		e.Push("\"finals\" " + f.Airport + " " + f.Runway)
+
+		if _, ok := av.LookupRunway(f.Airport, f.Runway); !ok {
+			e.ErrorString("unknown runway")
+		} else if _, ok := av.LookupOppositeRunway(f.Airport, f.Runway); !ok {
+			e.ErrorString("unable to find opposite runway")
+		}
+
+		if f.Length == 0 {
+			f.Length = defaultFinalLength
+		} else if f.Length < 0 {
+			e.ErrorString("\"length\" must be positive")
+		}
+		if f.TickSpacing < 0 {
+			e.ErrorString("\"tick_spacing\" must be positive")
+		}
+		for _, g := range f.Gates {
+			if g.Name == "" {
+				e.ErrorString("\"name\" must be specified for gate")
+			}
+			if g.Distance <= 0 || g.Distance > f.Length {
+				e.ErrorString("%s: gate \"distance\" must be between 0 and the centerline length", g.Name)
+			}
+		}
+
+		e.Pop()
+	}
+}
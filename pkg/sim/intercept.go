@@ -0,0 +1,105 @@
+// pkg/sim/intercept.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"fmt"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/math"
+)
+
+// InterceptTarget specifies the course that the user would like an
+// aircraft to intercept: either the final approach course of the approach
+// the aircraft has been assigned or the given magnetic course through a
+// fix.
+type InterceptTarget struct {
+	Fix    string // if empty, the assigned approach is used
+	Course float32
+	// Angle is the angle in degrees between the aircraft's track and the
+	// course at the intercept.
+	Angle float32
+}
+
+// InterceptSolution describes the vector that brings an aircraft onto
+// the course given by an InterceptTarget.
+type InterceptSolution struct {
+	// Heading is the magnetic heading to assign, rounded to 5 degrees; it
+	// accounts for the wind.
+	Heading int
+	// Track is the magnetic ground track that the aircraft will follow.
+	Track float32
+	// Intercept is the point where the aircraft will join the course and
+	// Distance is the distance in nm to it.
+	Intercept math.Point2LL
+	Distance  float32
+	// Command is the aircraft control command that assigns the heading.
+	Command string
+}
+
+// InterceptHeading returns the heading that the given aircraft should be
+// assigned to join the target course at the target's intercept angle,
+// given its current position and true airspeed and the wind.
+func (ss *State) InterceptHeading(callsign string, target InterceptTarget) (InterceptSolution, error) {
+	ac, ok := ss.Aircraft[callsign]
+	if !ok {
+		return InterceptSolution{}, av.ErrNoAircraftForCallsign
+	}
+	nmPerLongitude, magneticVariation := ss.NmPerLongitude, ss.MagneticVariation
+
+	// Find a point on the course and the course's direction, in nm
+	// coordinates.
+	var p0, dir [2]float32
+	var threshold *[2]float32
+	if target.Fix == "" {
+		ap := ac.Nav.Approach.Assigned
+		if ap == nil {
+			return InterceptSolution{}, ErrNoAssignedApproach
+		}
+		line := ap.Line()
+		p0 = math.LL2NM(line[0], nmPerLongitude)
+		p1 := math.LL2NM(line[1], nmPerLongitude)
+		dir = math.Normalize2f(math.Sub2f(p1, p0))
+		threshold = &p1
+		target.Course = ap.Heading(nmPerLongitude, magneticVariation)
+	} else if p, ok := ss.Locate(target.Fix); !ok {
+		return InterceptSolution{}, fmt.Errorf("%s: unknown fix", target.Fix)
+	} else {
+		p0 = math.LL2NM(p, nmPerLongitude)
+		course := math.Radians(target.Course - magneticVariation)
+		dir = [2]float32{math.Sin(course), math.Cos(course)}
+	}
+
+	// Turn toward the course: to the right if the aircraft is to the
+	// left of it and vice versa. The offset is negative when the aircraft
+	// is to the left.
+	pac := math.LL2NM(ac.Position(), nmPerLongitude)
+	offset := math.SignedPointLineDistance(pac, p0, math.Add2f(p0, dir))
+	angle := math.Clamp(target.Angle, 5, 90)
+	track := target.Course - math.Sign(offset)*angle
+
+	// The aircraft is offset from the course by |offset| and will close
+	// on it at the intercept angle.
+	dist := math.Abs(offset) / math.Sin(math.Radians(angle))
+	t := math.Radians(track - magneticVariation)
+	pi := math.Add2f(pac, math.Scale2f([2]float32{math.Sin(t), math.Cos(t)}, dist))
+	if threshold != nil && math.Dot(dir, math.Sub2f(pi, *threshold)) > 0 {
+		return InterceptSolution{}, ErrInterceptPastRunway
+	}
+
+	hdg := av.HeadingForTrack(track-magneticVariation, ac.TAS(), ss.AverageWindVector()) + magneticVariation
+	heading := 5 * int(math.NormalizeHeading(hdg+2.5)/5)
+	if heading == 0 {
+		heading = 360
+	}
+
+	return InterceptSolution{
+		Heading:   heading,
+		Track:     math.NormalizeHeading(track),
+		Intercept: math.NM2LL(pi, nmPerLongitude),
+		Distance:  dist,
+		Command:   fmt.Sprintf("H%03d", heading),
+	}, nil
+}
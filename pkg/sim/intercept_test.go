@@ -0,0 +1,65 @@
+// pkg/sim/intercept_test.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"testing"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/math"
+)
+
+func TestInterceptHeading(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		course  float32
+		pos     math.Point2LL
+		track   float32
+		heading int
+	}{
+		{name: "left of northbound course", course: 360, pos: math.Point2LL{-0.1, -0.2}, track: 30, heading: 30},
+		{name: "right of northbound course", course: 360, pos: math.Point2LL{0.1, -0.2}, track: 330, heading: 330},
+		{name: "left of southbound course", course: 180, pos: math.Point2LL{0.1, 0.2}, track: 150, heading: 150},
+		{name: "right of southbound course", course: 180, pos: math.Point2LL{-0.1, 0.2}, track: 210, heading: 210},
+		{name: "left of eastbound course", course: 90, pos: math.Point2LL{-0.2, 0.1}, track: 120, heading: 120},
+		{name: "right of eastbound course", course: 90, pos: math.Point2LL{-0.2, -0.1}, track: 60, heading: 60},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ac := &av.Aircraft{Callsign: "AAL1"}
+			ac.Nav.FlightState.Position = test.pos
+			ac.Nav.FlightState.IAS = 210
+			ac.Nav.FlightState.Altitude = 3000
+			ac.Nav.Perf.Speed.CruiseTAS = 450
+
+			ss := &State{
+				Aircraft:       map[string]*av.Aircraft{"AAL1": ac},
+				Fixes:          map[string]math.Point2LL{"FIXXX": {0, 0}},
+				NmPerLongitude: 60,
+			}
+
+			sol, err := ss.InterceptHeading("AAL1", InterceptTarget{Fix: "FIXXX", Course: test.course, Angle: 30})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(sol.Track-test.track) > 0.01 {
+				t.Errorf("track %f, expected %f", sol.Track, test.track)
+			}
+			if sol.Heading != test.heading {
+				t.Errorf("heading %d, expected %d", sol.Heading, test.heading)
+			}
+
+			// The intercept point should be on the course, ahead of the
+			// aircraft.
+			p := math.LL2NM(sol.Intercept, 60)
+			c := math.Radians(test.course)
+			if d := math.PointLineDistance(p, [2]float32{}, [2]float32{math.Sin(c), math.Cos(c)}); d > 0.01 {
+				t.Errorf("intercept %v is %f nm off the course", sol.Intercept, d)
+			}
+			if sol.Distance <= 0 {
+				t.Errorf("expected a positive distance to the intercept, got %f", sol.Distance)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+// pkg/sim/landline.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"slices"
+
+	"github.com/mmp/vice/pkg/util"
+)
+
+// LandlineType identifies the kind of a landline between controller
+// positions.
+type LandlineType string
+
+const (
+	// Intercom lines ring the other position, which answers when ready.
+	LandlineIntercom LandlineType = "intercom"
+	// Override lines break in on the other position immediately.
+	LandlineOverride LandlineType = "override"
+	// Shout lines go to all of the positions on the line at once.
+	LandlineShout LandlineType = "shout"
+)
+
+// Abbreviation returns the abbreviation for the line type that is shown
+// in the landline panel and in messages.
+func (t LandlineType) Abbreviation() string {
+	switch t {
+	case LandlineOverride:
+		return "OV"
+	case LandlineShout:
+		return "SH"
+	default:
+		return "IC"
+	}
+}
+
+// Landline is a line between controller positions, as specified in the
+// facility adaptation.
+type Landline struct {
+	Name      string       `json:"name"`
+	Type      LandlineType `json:"type"`
+	Positions []string     `json:"positions"`
+}
+
+// Recipients returns the positions on the line that a message from any
+// of the given positions goes to.
+func (l Landline) Recipients(from []string) []string {
+	return util.FilterSlice(l.Positions, func(p string) bool { return !slices.Contains(from, p) })
+}
+
+// Landlines returns the lines that are available at the user's position
+// or any position consolidated into it. If the facility adaptation
+// doesn't specify any, there is an intercom line to each of the other
+// signed-in controllers and a shout line to all of them.
+func (ss *State) Landlines() []Landline {
+	positions := ss.ConsolidatedPositions()
+
+	if len(ss.STARSFacilityAdaptation.Landlines) > 0 {
+		return util.FilterSlice(ss.STARSFacilityAdaptation.Landlines, func(l Landline) bool {
+			return slices.ContainsFunc(l.Positions, func(p string) bool { return slices.Contains(positions, p) })
+		})
+	}
+
+	var lines []Landline
+	var all []string
+	for _, callsign := range util.SortedMapKeys(ss.Controllers) {
+		if slices.Contains(positions, callsign) {
+			continue
+		}
+		lines = append(lines, Landline{
+			Name:      callsign,
+			Type:      LandlineIntercom,
+			Positions: []string{ss.Callsign, callsign},
+		})
+		all = append(all, callsign)
+	}
+	if len(all) > 1 {
+		lines = append(lines, Landline{
+			Name:      "All",
+			Type:      LandlineShout,
+			Positions: append([]string{ss.Callsign}, all...),
+		})
+	}
+	return lines
+}
+
+func (s *STARSFacilityAdaptation) checkLandlines(e *util.ErrorLogger, sg *ScenarioGroup) {
+	for _, l := range s.Landlines {
+		e.Push("\"landlines\" " + l.Name)
+
+		if l.Name == "" {
+			e.ErrorString("\"name\" must be specified for landline.")
+		}
+		switch l.Type {
+		case LandlineIntercom, LandlineOverride:
+			if len(l.Positions) != 2 {
+				e.ErrorString("%q lines must have exactly two \"positions\".", l.Type)
+			}
+		case LandlineShout:
+			if len(l.Positions) < 2 {
+				e.ErrorString("shout lines must have at least two \"positions\".")
+			}
+		default:
+			e.ErrorString("%q: invalid \"type\". Expected \"intercom\", \"override\", or \"shout\".", l.Type)
+		}
+		for _, pos := range l.Positions {
+			if _, ok := sg.ControlPositions[pos]; !ok {
+				e.ErrorString(pos + ": controller unknown")
+			}
+		}
+
+		e.Pop()
+	}
+}
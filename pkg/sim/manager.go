@@ -180,6 +180,7 @@ func (sm *SimManager) GetRunningSims(_ int, result *map[string]*RemoteSim) error
 			RequirePassword:    s.RequirePassword,
 			AvailablePositions: make(map[string]struct{}),
 			CoveredPositions:   make(map[string]struct{}),
+			Positions:          make(map[string]*av.Controller),
 		}
 
 		// Figure out which positions are available; start with all of the possible ones,
@@ -194,6 +195,11 @@ func (sm *SimManager) GetRunningSims(_ int, result *map[string]*RemoteSim) error
 				rs.CoveredPositions[ctrl.Callsign] = struct{}{}
 			}
 		}
+		for callsign := range rs.AvailablePositions {
+			if ctrl, ok := s.State.ControlPositions[callsign]; ok {
+				rs.Positions[callsign] = ctrl
+			}
+		}
 		s.mu.Unlock(s.lg)
 
 		running[name] = rs
@@ -345,3 +351,48 @@ func BroadcastMessage(hostname, msg, password string, lg *log.Logger) {
 		lg.Errorf("broadcast error: %v", err)
 	}
 }
+
+// TelemetryReport holds the anonymous usage metrics that vice sends to
+// the server at the start of the following session when the user has
+// opted in to sending them. It contains nothing that identifies the user:
+// just the platform, performance measurements, and which video map file
+// was in use.
+type TelemetryReport struct {
+	OS            string
+	Arch          string
+	CPUs          int
+	ConfigVersion int
+
+	SessionMinutes float32
+	Frames         int
+	MeanFrameMs    float32
+	P95FrameMs     float32
+	MaxFrameMs     float32
+	// SlowFrames is the number of frames that took longer than 50ms.
+	SlowFrames int
+	MemoryMB   int
+
+	VideoMapFile string
+	VideoMaps    int
+
+	// CleanSessions and CrashedSessions count the sessions since the
+	// user opted in that exited normally and that didn't.
+	CleanSessions   int
+	CrashedSessions int
+}
+
+func (sm *SimManager) ReportTelemetry(r *TelemetryReport, _ *struct{}) error {
+	sm.lg.Info("telemetry", slog.Any("report", *r))
+	return nil
+}
+
+// SendTelemetry sends the given report to the server.
+func SendTelemetry(hostname string, r *TelemetryReport, lg *log.Logger) error {
+	client, err := getClient(hostname, lg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	return client.CallWithTimeout("SimManager.ReportTelemetry", r, nil)
+}
@@ -526,23 +526,42 @@ func (comp *STARSComputer) AutoAssociateFP(ac *av.Aircraft, fp *STARSFlightPlan)
 	})
 }
 
-func (comp *STARSComputer) UpdateAssociatedFlightPlans(aircraft []*av.Aircraft) {
+func (comp *STARSComputer) UpdateAssociatedFlightPlans(aircraft []*av.Aircraft, fa *STARSFacilityAdaptation) {
 	for _, ac := range aircraft {
 		fp, ok := comp.ContainedPlans[ac.Squawk]
-		if ok && (!inAcquisitionArea(ac) && !inDropArea(ac)) && comp.TrackInformation[ac.Callsign] == nil { // Prevent departures
+		if ok && (!inAcquisitionArea(ac, fa) && !inDropArea(ac) && !approachingAcquisitionArea(ac, fa)) &&
+			comp.TrackInformation[ac.Callsign] == nil { // Prevent departures
 			comp.AutoAssociateFP(ac, fp)
 		}
 	}
 }
 
-// This should be facility-defined in the json file, but for now it's 30nm
-// near their departure airport.
-func inAcquisitionArea(ac *av.Aircraft) bool {
+// approachingAcquisitionArea returns whether the aircraft departed from
+// an airport with an auto-acquisition area and may not have reached it
+// yet; its flight plan shouldn't be associated before it's acquired.
+func approachingAcquisitionArea(ac *av.Aircraft, fa *STARSFacilityAdaptation) bool {
+	area := fa.AutoAcquisitionArea(ac)
+	return area != nil && area.Approaching(ac)
+}
+
+// inAcquisitionArea returns whether the aircraft's track may be acquired
+// automatically: it's inside the facility's auto-acquisition area for its
+// departure airport, if there is one, and otherwise within 2nm of it, or
+// it's within 2nm of its arrival airport.
+func inAcquisitionArea(ac *av.Aircraft, fa *STARSFacilityAdaptation) bool {
 	if inDropArea(ac) {
 		return false
 	}
 
-	for _, icao := range []string{ac.FlightPlan.DepartureAirport, ac.FlightPlan.ArrivalAirport} {
+	airports := []string{ac.FlightPlan.DepartureAirport, ac.FlightPlan.ArrivalAirport}
+	if area := fa.AutoAcquisitionArea(ac); area != nil {
+		if area.Inside(ac) {
+			return true
+		}
+		airports = airports[1:]
+	}
+
+	for _, icao := range airports {
 		ap := av.DB.Airports[icao]
 		if math.NMDistance2LL(ap.Location, ac.Position()) <= 2 {
 			return true
@@ -820,19 +839,32 @@ func (comp *STARSComputer) RejectPointOut(callsign, controller string) error {
 	return nil
 }
 
-func (comp *STARSComputer) ReleaseDeparture(callsign string) error {
+func (comp *STARSComputer) ReleaseDeparture(callsign string, expiration time.Time) error {
 	idx := slices.IndexFunc(comp.HoldForRelease, func(ac *av.Aircraft) bool { return ac.Callsign == callsign })
 	if idx == -1 {
 		return av.ErrNoAircraftForCallsign
 	}
-	if comp.HoldForRelease[idx].Released {
+	if ac := comp.HoldForRelease[idx]; ac.Released {
 		return ErrAircraftAlreadyReleased
 	} else {
-		comp.HoldForRelease[idx].Released = true
+		ac.Released = true
+		ac.ReleaseExpiration = expiration
+		ac.ReleaseExpired = false
 		return nil
 	}
 }
 
+// ExpireRelease cancels the release of a departure that didn't depart
+// within its release window.
+func (comp *STARSComputer) ExpireRelease(callsign string) {
+	if idx := slices.IndexFunc(comp.HoldForRelease, func(ac *av.Aircraft) bool { return ac.Callsign == callsign }); idx != -1 {
+		ac := comp.HoldForRelease[idx]
+		ac.Released = false
+		ac.ReleaseExpiration = time.Time{}
+		ac.ReleaseExpired = true
+	}
+}
+
 func (comp *STARSComputer) GetReleaseDepartures() []*av.Aircraft {
 	return comp.HoldForRelease
 }
@@ -963,7 +995,7 @@ func (comp *STARSComputer) AssociateFlightPlans(s *Sim) {
 			// ERROR Unable to resolve departure controller for aircraft
 			// that are initially controlled by a virtual controller
 			// (e.g. LGA water gate departures when controlling JFK.)
-			if inAcquisitionArea(ac) && s.State.DepartureController(ac, s.lg) == ctrl {
+			if inAcquisitionArea(ac, &s.State.STARSFacilityAdaptation) && s.State.DepartureController(ac, s.lg) == ctrl {
 				// If they have already contacted departure, then initiating
 				// track gives control as well; otherwise ControllingController
 				// is left unset until contact.
@@ -1053,7 +1085,7 @@ func (fp STARSFlightPlan) Message() FlightPlanMessage {
 			NumberOfAircraft:  1,
 			AircraftType:      fp.TypeWithoutSuffix(),
 			AircraftCategory:  fp.AircraftType, // TODO: Use a method to turn this into an aircraft category
-			Equipment:         strings.TrimPrefix(fp.AircraftType, fp.TypeWithoutSuffix()),
+			Equipment:         util.Select(fp.EquipmentSuffix() != "", "/"+fp.EquipmentSuffix(), ""),
 		},
 		FlightID:         fp.ECID + fp.Callsign,
 		CoordinationFix:  fp.CoordinationFix,
@@ -1441,7 +1473,7 @@ func FlightPlanDepartureMessage(fp av.FlightPlan, sendingFacility string, simTim
 			NumberOfAircraft:  1, // One for now.
 			AircraftType:      fp.TypeWithoutSuffix(),
 			AircraftCategory:  fp.AircraftType, // TODO: Use a method to turn this into an aircraft category
-			Equipment:         strings.TrimPrefix(fp.AircraftType, fp.TypeWithoutSuffix()),
+			Equipment:         util.Select(fp.EquipmentSuffix() != "", "/"+fp.EquipmentSuffix(), ""),
 		},
 		BCN:             fp.AssignedSquawk,
 		CoordinationFix: fp.Exit,
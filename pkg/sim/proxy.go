@@ -6,6 +6,7 @@ package sim
 
 import (
 	"net/rpc"
+	"time"
 
 	av "github.com/mmp/vice/pkg/aviation"
 	"github.com/mmp/vice/pkg/math"
@@ -177,6 +178,23 @@ func (s *proxy) GlobalMessage(global GlobalMessage) *rpc.Call {
 		ControllerToken: s.ControllerToken,
 		Message:         global.Message,
 		FromController:  global.FromController,
+		Guard:           global.Guard,
+		ToControllers:   global.ToControllers,
+		Landline:        global.Landline,
+	}, nil, nil)
+}
+
+func (s *proxy) ShareAnnotation(a ScopeAnnotation) *rpc.Call {
+	return s.Client.Go("Sim.ShareAnnotation", &ShareAnnotationArgs{
+		ControllerToken: s.ControllerToken,
+		Annotation:      a,
+	}, nil, nil)
+}
+
+func (s *proxy) ChangeSplit(split string) *rpc.Call {
+	return s.Client.Go("Sim.ChangeSplit", &ChangeSplitArgs{
+		ControllerToken: s.ControllerToken,
+		Split:           split,
 	}, nil, nil)
 }
 
@@ -240,6 +258,14 @@ func (s *proxy) ReleaseDeparture(callsign string) *rpc.Call {
 	}, nil, nil)
 }
 
+func (s *proxy) SetEDCT(callsign string, edct time.Time) *rpc.Call {
+	return s.Client.Go("Sim.SetEDCT", &SetEDCTArgs{
+		ControllerToken: s.ControllerToken,
+		Callsign:        callsign,
+		EDCT:            edct,
+	}, nil, nil)
+}
+
 func (s *proxy) SetTemporaryAltitude(callsign string, alt int) *rpc.Call {
 	return s.Client.Go("Sim.SetTemporaryAltitude", &AssignAltitudeArgs{
 		ControllerToken: s.ControllerToken,
@@ -248,6 +274,22 @@ func (s *proxy) SetTemporaryAltitude(callsign string, alt int) *rpc.Call {
 	}, nil, nil)
 }
 
+func (s *proxy) SetTemporaryHeading(callsign string, heading int) *rpc.Call {
+	return s.Client.Go("Sim.SetTemporaryHeading", &TemporaryAssignmentArgs{
+		ControllerToken: s.ControllerToken,
+		Callsign:        callsign,
+		Value:           heading,
+	}, nil, nil)
+}
+
+func (s *proxy) SetTemporarySpeed(callsign string, speed int) *rpc.Call {
+	return s.Client.Go("Sim.SetTemporarySpeed", &TemporaryAssignmentArgs{
+		ControllerToken: s.ControllerToken,
+		Callsign:        callsign,
+		Value:           speed,
+	}, nil, nil)
+}
+
 func (s *proxy) DeleteAllAircraft() *rpc.Call {
 	return s.Client.Go("Sim.DeleteAllAircraft", &DeleteAircraftArgs{
 		ControllerToken: s.ControllerToken,
@@ -0,0 +1,228 @@
+// pkg/sim/radio.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"slices"
+	"time"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/util"
+)
+
+// Radio holds the client-side state of the controller's radio: which
+// frequencies they are monitoring and which one is primed for
+// transmitting. The frequency of the controller's own position and the
+// frequencies of any positions that are consolidated into it are always
+// available; frequencies of other positions may be added for
+// monitoring. When covering combined sectors, the controller's
+// frequencies may be cross-coupled, in which case traffic received on
+// any of the coupled frequencies is retransmitted on all of them.
+type Radio struct {
+	Frequencies []*RadioFrequency
+	Primed      av.Frequency
+
+	// Which frequency traffic was last received on and when.
+	LastReceived     av.Frequency
+	LastReceivedTime time.Time
+
+	// MonitorGuard indicates whether the guard frequency is being
+	// received.
+	MonitorGuard bool
+}
+
+// GuardFrequency is the international VHF emergency frequency, 121.5.
+const GuardFrequency = av.Frequency(121500)
+
+type RadioFrequency struct {
+	Frequency av.Frequency
+	// Controller is the position that the frequency belongs to.
+	Controller string
+	Receive    bool
+	// Owned is true if the frequency belongs to a position the user is
+	// working (either their own or one consolidated into it) and thus
+	// may be transmitted on.
+	Owned bool
+	// Coupled is true if the frequency is cross-coupled with the other
+	// coupled frequencies; only owned frequencies may be coupled.
+	Coupled bool
+}
+
+// Update synchronizes the radio's frequencies with the current set of
+// controllers and the user's position.
+func (r *Radio) Update(ss *State) {
+	owned := ss.ConsolidatedPositions()
+
+	// Drop frequencies for positions that have gone away and positions
+	// that are no longer consolidated into ours, unless they've been
+	// explicitly added for monitoring.
+	r.Frequencies = util.FilterSlice(r.Frequencies, func(rf *RadioFrequency) bool {
+		if _, ok := ss.ControlPosition(rf.Controller); !ok {
+			return false
+		}
+		wasOwned := rf.Owned
+		rf.Owned = slices.Contains(owned, rf.Controller)
+		rf.Coupled = rf.Coupled && rf.Owned
+		return rf.Owned || !wasOwned
+	})
+
+	for _, callsign := range owned {
+		if r.frequency(callsign) == nil {
+			if ctrl, ok := ss.ControlPosition(callsign); ok {
+				r.Frequencies = append(r.Frequencies, &RadioFrequency{
+					Frequency:  ctrl.Frequency,
+					Controller: callsign,
+					Receive:    true,
+					Owned:      true,
+				})
+			}
+		}
+	}
+
+	// Make sure we're primed on one of our own frequencies.
+	if rf := r.primedFrequency(); rf == nil || !rf.Owned {
+		if ctrl, ok := ss.Controllers[ss.Callsign]; ok {
+			r.Primed = ctrl.Frequency
+		}
+	}
+}
+
+func (r *Radio) frequency(controller string) *RadioFrequency {
+	for _, rf := range r.Frequencies {
+		if rf.Controller == controller {
+			return rf
+		}
+	}
+	return nil
+}
+
+func (r *Radio) primedFrequency() *RadioFrequency {
+	for _, rf := range r.Frequencies {
+		if rf.Frequency == r.Primed {
+			return rf
+		}
+	}
+	return nil
+}
+
+// Prime selects the given frequency for transmitting; it returns false
+// if the frequency isn't one of the user's.
+func (r *Radio) Prime(f av.Frequency) bool {
+	for _, rf := range r.Frequencies {
+		if rf.Frequency == f && rf.Owned {
+			r.Primed = f
+			rf.Receive = true
+			return true
+		}
+	}
+	return false
+}
+
+// Monitor adds the frequency of the given controller's position to the
+// radio.
+func (r *Radio) Monitor(ss *State, controller string) {
+	if ctrl, ok := ss.ControlPosition(controller); ok && r.frequency(controller) == nil {
+		r.Frequencies = append(r.Frequencies, &RadioFrequency{
+			Frequency:  ctrl.Frequency,
+			Controller: controller,
+			Receive:    true,
+		})
+	}
+}
+
+// Remove removes a monitored frequency; the user's own frequencies can't
+// be removed.
+func (r *Radio) Remove(f av.Frequency) {
+	r.Frequencies = util.FilterSlice(r.Frequencies, func(rf *RadioFrequency) bool {
+		return rf.Owned || rf.Frequency != f
+	})
+}
+
+// Tuned returns true if the given controller's frequency is on the
+// radio, whether or not it is being received.
+func (r *Radio) Tuned(controller string) bool {
+	return r.frequency(controller) != nil
+}
+
+// Receives returns true if transmissions to the given controller are
+// heard on the radio, either directly or via retransmission on a
+// frequency that it is cross-coupled with.
+func (r *Radio) Receives(controller string) bool {
+	rf := r.frequency(controller)
+	if rf == nil {
+		return false
+	}
+	return rf.Receive || (rf.Coupled && slices.ContainsFunc(r.Frequencies,
+		func(rf *RadioFrequency) bool { return rf.Coupled && rf.Receive }))
+}
+
+// Couple cross-couples the given frequency with the other coupled
+// frequencies or, if coupled is false, removes it from the coupling.
+func (r *Radio) Couple(f av.Frequency, coupled bool) {
+	for _, rf := range r.Frequencies {
+		if rf.Frequency == f && rf.Owned {
+			rf.Coupled = coupled
+		}
+	}
+}
+
+// CoupledWithPrimed returns true if the given controller's frequency is
+// cross-coupled with the primed frequency, so that transmissions on
+// it are effectively on the primed frequency.
+func (r *Radio) CoupledWithPrimed(controller string) bool {
+	rf, primed := r.frequency(controller), r.primedFrequency()
+	return rf != nil && primed != nil && rf.Coupled && primed.Coupled
+}
+
+// NumCoupled returns the number of frequencies that are cross-coupled.
+func (r *Radio) NumCoupled() int {
+	n := 0
+	for _, rf := range r.Frequencies {
+		if rf.Coupled {
+			n++
+		}
+	}
+	return n
+}
+
+// Received records that traffic was received for the given controller.
+func (r *Radio) Received(controller string, t time.Time) {
+	if rf := r.frequency(controller); rf != nil {
+		r.LastReceived = rf.Frequency
+		r.LastReceivedTime = t
+	}
+}
+
+// ReceivedGuard records that traffic was received on the guard frequency.
+func (r *Radio) ReceivedGuard(t time.Time) {
+	if r.MonitorGuard {
+		r.LastReceived = GuardFrequency
+		r.LastReceivedTime = t
+	}
+}
+
+// ConsolidatedPositions returns the user's position along with all of
+// the positions that are currently consolidated into it.
+func (ss *State) ConsolidatedPositions() []string {
+	pos := []string{ss.Callsign}
+	if ss.MultiControllers == nil {
+		return pos
+	}
+
+	for callsign := range ss.MultiControllers {
+		if callsign == ss.Callsign {
+			continue
+		}
+		resolved, err := ss.MultiControllers.ResolveController(callsign, func(callsign string) bool {
+			ctrl, ok := ss.Controllers[callsign]
+			return ok && ctrl.IsHuman
+		})
+		if err == nil && resolved == ss.Callsign {
+			pos = append(pos, callsign)
+		}
+	}
+	slices.Sort(pos[1:])
+	return pos
+}
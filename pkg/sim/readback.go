@@ -0,0 +1,125 @@
+// pkg/sim/readback.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"log/slog"
+	"slices"
+	"time"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/rand"
+)
+
+// ReadbackError records an incorrect readback that a simulated pilot has
+// given; the controller has until Deadline to catch it by reissuing the
+// instruction with the correct value, Value.
+type ReadbackError struct {
+	Kind       string // "altitude", "heading", or "speed"
+	Value      int
+	Controller string
+	Deadline   time.Time
+}
+
+// How much sim time the controller has to correct an incorrect readback.
+const readbackErrorCorrectionTime = 30 * time.Second
+
+// assignWithReadback issues an altitude, heading, or speed assignment
+// using assign. If the aircraft had given an incorrect readback of the
+// same kind, reissuing the instruction with the correct value counts as
+// catching it. Otherwise, with probability LaunchConfig.ReadbackErrorRate,
+// the pilot mishears the instruction and is given a wrong value instead;
+// if the pilot accepts it, they read back and fly that value until
+// corrected.
+func (s *Sim) assignWithReadback(ctrl *av.Controller, ac *av.Aircraft, kind string, value int,
+	assign func(int) []av.RadioTransmission) []av.RadioTransmission {
+	re, pending := s.ReadbackErrors[ac.Callsign]
+	if pending && re.Kind == kind && re.Value == value {
+		rt := assign(value)
+		if !rejected(rt) {
+			s.ReadbackErrorsCaught++
+			delete(s.ReadbackErrors, ac.Callsign)
+			s.lg.Info("readback error caught", slog.String("callsign", ac.Callsign), slog.String("kind", kind))
+		}
+		return rt
+	}
+
+	// Only one incorrect readback is outstanding for an aircraft at a
+	// time so that an uncorrected one isn't lost.
+	if pending || rand.Float32() >= s.LaunchConfig.ReadbackErrorRate {
+		return assign(value)
+	}
+
+	wrong := incorrectReadbackValue(kind, value)
+	rt := assign(wrong)
+	if rejected(rt) {
+		// The pilot was unable to comply with what they heard, so there's
+		// no incorrect assignment to catch.
+		return rt
+	}
+
+	if s.ReadbackErrors == nil {
+		s.ReadbackErrors = make(map[string]ReadbackError)
+	}
+	s.ReadbackErrors[ac.Callsign] = ReadbackError{
+		Kind:       kind,
+		Value:      value,
+		Controller: ctrl.Callsign,
+		Deadline:   s.SimTime.Add(readbackErrorCorrectionTime),
+	}
+	s.lg.Info("readback error injected", slog.String("callsign", ac.Callsign), slog.String("kind", kind),
+		slog.Int("value", wrong))
+
+	return rt
+}
+
+// incorrectReadbackValue returns a plausible wrong value for an assigned
+// altitude, heading, or speed.
+func incorrectReadbackValue(kind string, value int) int {
+	sign := 1 - 2*rand.Intn(2)
+	switch kind {
+	case "altitude":
+		if value <= 2000 {
+			sign = 1
+		}
+		value += sign * 1000
+	case "heading":
+		value += sign * 20
+		if value > 360 {
+			value -= 360
+		} else if value <= 0 {
+			value += 360
+		}
+	case "speed":
+		value += sign * 20
+	}
+	return value
+}
+
+// rejected returns true if the pilot responded to an instruction with
+// "unable".
+func rejected(rt []av.RadioTransmission) bool {
+	return slices.ContainsFunc(rt, func(rt av.RadioTransmission) bool {
+		return rt.Type == av.RadioTransmissionUnexpected
+	})
+}
+
+// updateReadbackErrors counts incorrect readbacks that weren't corrected
+// in time as missed and lets the controller know about them.
+func (s *Sim) updateReadbackErrors() {
+	for callsign, re := range s.ReadbackErrors {
+		if s.SimTime.Before(re.Deadline) {
+			continue
+		}
+
+		s.ReadbackErrorsMissed++
+		delete(s.ReadbackErrors, callsign)
+		s.eventStream.Post(Event{
+			Type:         StatusMessageEvent,
+			ToController: re.Controller,
+			Message:      callsign + " read back an incorrect " + re.Kind + " that was not corrected",
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// pkg/sim/readback_test.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"testing"
+	"time"
+
+	av "github.com/mmp/vice/pkg/aviation"
+)
+
+// testAssign returns an assignment function that records the values it
+// was called with and rejects the ones that accept returns false for.
+func testAssign(accept func(int) bool, values *[]int) func(int) []av.RadioTransmission {
+	return func(v int) []av.RadioTransmission {
+		*values = append(*values, v)
+		if !accept(v) {
+			return []av.RadioTransmission{{Message: "unable", Type: av.RadioTransmissionUnexpected}}
+		}
+		return []av.RadioTransmission{{Message: "roger", Type: av.RadioTransmissionReadback}}
+	}
+}
+
+func readbackTestSim(rate float32) *Sim {
+	return &Sim{
+		State:        &State{SimTime: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)},
+		LaunchConfig: LaunchConfig{ReadbackErrorRate: rate},
+	}
+}
+
+func TestReadbackErrorRate(t *testing.T) {
+	ctrl := &av.Controller{Callsign: "PHL_APP"}
+	ac := &av.Aircraft{Callsign: "AAL1"}
+
+	for _, rate := range []float32{0, 0.25, 1} {
+		s := readbackTestSim(rate)
+		const n = 4000
+		injected := 0
+		for range n {
+			var values []int
+			s.assignWithReadback(ctrl, ac, "altitude", 8000, testAssign(func(int) bool { return true }, &values))
+			if len(values) != 1 {
+				t.Fatalf("expected a single assignment, got %v", values)
+			}
+			if re, ok := s.ReadbackErrors[ac.Callsign]; ok {
+				injected++
+				if values[0] != 7000 && values[0] != 9000 {
+					t.Errorf("expected the altitude to be misheard 1000' off, got %v", values)
+				}
+				if re.Value != 8000 {
+					t.Errorf("expected the correct altitude to be recorded, got %d", re.Value)
+				}
+				delete(s.ReadbackErrors, ac.Callsign)
+			} else if values[0] != 8000 {
+				t.Errorf("expected the correct altitude to be assigned, got %v", values)
+			}
+		}
+
+		frac := float32(injected) / n
+		if frac < rate-0.03 || frac > rate+0.03 {
+			t.Errorf("rate %f: injected readback errors %f of the time", rate, frac)
+		}
+	}
+}
+
+func TestReadbackErrorRejected(t *testing.T) {
+	ctrl := &av.Controller{Callsign: "PHL_APP"}
+	ac := &av.Aircraft{Callsign: "AAL1"}
+	s := readbackTestSim(1)
+
+	// If the pilot is unable to fly the misheard value, there's no
+	// readback error to catch.
+	var values []int
+	rt := s.assignWithReadback(ctrl, ac, "speed", 250, testAssign(func(spd int) bool { return spd == 250 }, &values))
+	if len(values) != 1 || values[0] == 250 || !rejected(rt) {
+		t.Errorf("expected a single rejected assignment of a misheard speed, got %v", values)
+	}
+	if len(s.ReadbackErrors) != 0 {
+		t.Errorf("readback error recorded for a rejected instruction")
+	}
+}
+
+func TestReadbackErrorCaught(t *testing.T) {
+	ctrl := &av.Controller{Callsign: "PHL_APP"}
+	ac := &av.Aircraft{Callsign: "AAL1"}
+	s := readbackTestSim(1)
+	s.ReadbackErrors = map[string]ReadbackError{ac.Callsign: {Kind: "altitude", Value: 8000, Controller: ctrl.Callsign}}
+	accept := func(int) bool { return true }
+
+	for _, test := range []struct {
+		kind   string
+		value  int
+		accept func(int) bool
+		caught bool
+	}{
+		{"heading", 270, accept, false},                            // a different kind of instruction
+		{"altitude", 9000, accept, false},                          // a different altitude
+		{"altitude", 8000, func(int) bool { return false }, false}, // rejected
+		{"altitude", 8000, accept, true},
+	} {
+		var values []int
+		s.assignWithReadback(ctrl, ac, test.kind, test.value, testAssign(test.accept, &values))
+		// Another readback error isn't injected while one is pending.
+		if len(values) != 1 || values[0] != test.value {
+			t.Errorf("%s %d: expected a single correct assignment, got %v", test.kind, test.value, values)
+		}
+		_, pending := s.ReadbackErrors[ac.Callsign]
+		if caught := s.ReadbackErrorsCaught == 1; caught != test.caught || pending == test.caught {
+			t.Errorf("%s %d: expected caught %v, got %d caught and pending %v", test.kind, test.value,
+				test.caught, s.ReadbackErrorsCaught, pending)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// pkg/sim/reroute.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"slices"
+	"strings"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/util"
+)
+
+// RerouteSuggestion describes an amended route for an aircraft whose
+// route penetrates closed airspace.
+type RerouteSuggestion struct {
+	Callsign string
+	// Penetrated is the name of the first closed airspace volume that the
+	// aircraft's current route passes through.
+	Penetrated string
+	// Fix is the fix to proceed direct to in order to avoid the closed
+	// airspace; it's empty if the aircraft can go direct to Rejoin.
+	Fix string
+	// Rejoin is the fix at which the aircraft rejoins its route.
+	Rejoin string
+	// Command is the aircraft control command that applies the reroute.
+	Command string
+	// Unresolved is set if no reroute could be found (e.g., the aircraft
+	// is already inside the closed airspace or its route doesn't exit
+	// it).
+	Unresolved bool
+}
+
+// Route returns a human-readable summary of the suggested route.
+func (r RerouteSuggestion) Route() string {
+	if r.Unresolved {
+		return "(no reroute found)"
+	} else if r.Fix == "" {
+		return "direct " + r.Rejoin
+	}
+	return "direct " + r.Fix + ", direct " + r.Rejoin
+}
+
+// SuggestReroutes returns reroute suggestions for the aircraft that the
+// current controller is controlling whose remaining routes pass through
+// any of the given closed airspace volumes at an altitude the volume
+// covers.
+func (ss *State) SuggestReroutes(closed []av.AirspaceVolume) []RerouteSuggestion {
+	var suggestions []RerouteSuggestion
+
+	for _, callsign := range util.SortedMapKeys(ss.Aircraft) {
+		ac := ss.Aircraft[callsign]
+		if ac.ControllingController != ss.Callsign || !ac.IsAirborne() || len(ac.Nav.Waypoints) == 0 {
+			continue
+		}
+
+		// Consider volumes that cover the aircraft's current altitude or
+		// any altitude between there and its filed altitude.
+		lo, hi := int(ac.Altitude()), int(ac.Altitude())
+		if fp := ac.FlightPlan; fp != nil && fp.Altitude != 0 {
+			lo, hi = math.Min(lo, fp.Altitude), math.Max(hi, fp.Altitude)
+		}
+		volumes := util.FilterSlice(closed, func(v av.AirspaceVolume) bool {
+			return hi > v.Floor && lo <= v.Ceiling
+		})
+		if len(volumes) == 0 {
+			continue
+		}
+
+		if s, ok := ss.suggestReroute(ac, volumes); ok {
+			suggestions = append(suggestions, s)
+		}
+	}
+
+	return suggestions
+}
+
+func (ss *State) suggestReroute(ac *av.Aircraft, volumes []av.AirspaceVolume) (RerouteSuggestion, bool) {
+	nmPerLongitude := ss.NmPerLongitude
+	// Returns the name of the first volume that the segment penetrates.
+	penetrates := func(p0, p1 math.Point2LL) (string, bool) {
+		for _, v := range volumes {
+			if v.IntersectsSegment(p0, p1, nmPerLongitude) {
+				return v.Name, true
+			}
+		}
+		return "", false
+	}
+	inside := func(p math.Point2LL) bool {
+		return slices.ContainsFunc(volumes, func(v av.AirspaceVolume) bool {
+			return v.IntersectsSegment(p, p, nmPerLongitude)
+		})
+	}
+
+	pos := ac.Position()
+	wps := ac.Nav.Waypoints
+	route := append([]math.Point2LL{pos}, util.MapSlice(wps, func(wp av.Waypoint) math.Point2LL { return wp.Location })...)
+
+	// Find the first route segment that penetrates closed airspace.
+	first := -1
+	s := RerouteSuggestion{Callsign: ac.Callsign}
+	for i := range len(route) - 1 {
+		if name, ok := penetrates(route[i], route[i+1]); ok {
+			first = i
+			s.Penetrated = name
+			break
+		}
+	}
+	if first == -1 {
+		return RerouteSuggestion{}, false
+	}
+
+	s.Unresolved = true
+	if inside(pos) {
+		return s, true
+	}
+
+	// The rejoin fix is the first named fix after the penetrating segment
+	// from which the rest of the route is clear.
+	rejoin := -1
+	for j := first; j < len(wps); j++ {
+		if strings.HasPrefix(wps[j].Fix, "_") || inside(wps[j].Location) {
+			continue
+		}
+		clear := true
+		for k := j + 1; k < len(route)-1; k++ {
+			if _, ok := penetrates(route[k], route[k+1]); ok {
+				clear = false
+				break
+			}
+		}
+		if clear {
+			rejoin = j
+			break
+		}
+	}
+	if rejoin == -1 {
+		return s, true
+	}
+	s.Rejoin = wps[rejoin].Fix
+	rp := wps[rejoin].Location
+
+	if _, ok := penetrates(pos, rp); !ok {
+		s.Unresolved = false
+		s.Command = "D" + s.Rejoin
+		return s, true
+	}
+
+	// Otherwise find the fix that gives the shortest clear dogleg, as
+	// long as it's less than twice the direct distance. The fixes that
+	// give such doglegs are inside an ellipse around the direct route
+	// that is in turn inside a circle of radius direct around its
+	// midpoint, so fixes outside of the bounds of that circle (padded a
+	// bit since it's computed using nmPerLongitude) are skipped without
+	// doing the more expensive tests.
+	direct := math.NMDistance2LL(pos, rp)
+	bestDist := 2 * direct
+	mid := math.LL2NM(math.Mid2LL(pos, rp), nmPerLongitude)
+	bounds := math.Extent2D{P0: mid, P1: mid}.Expand(1.1 * direct)
+	consider := func(fix string, p math.Point2LL) {
+		if !bounds.Inside(math.LL2NM(p, nmPerLongitude)) {
+			return
+		}
+		d := math.NMDistance2LL(pos, p) + math.NMDistance2LL(p, rp)
+		if d >= bestDist || inside(p) {
+			return
+		}
+		if _, ok := penetrates(pos, p); ok {
+			return
+		}
+		if _, ok := penetrates(p, rp); ok {
+			return
+		}
+		bestDist = d
+		s.Fix = fix
+	}
+
+	for fix, p := range ss.Fixes {
+		consider(fix, p)
+	}
+	for _, fix := range av.DB.Fixes {
+		consider(fix.Id, fix.Location)
+	}
+	for _, aid := range av.DB.Navaids {
+		consider(aid.Id, aid.Location)
+	}
+
+	if s.Fix != "" {
+		s.Unresolved = false
+		s.Command = "D" + s.Fix + "/R" + s.Rejoin
+	}
+	return s, true
+}
@@ -0,0 +1,48 @@
+// pkg/sim/reroute_test.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"testing"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/math"
+)
+
+func TestSuggestRerouteDogleg(t *testing.T) {
+	db := av.DB
+	defer func() { av.DB = db }()
+	av.DB = &av.StaticDatabase{Navaids: map[string]av.Navaid{
+		// A clear dogleg, but a longer one than via NORTH.
+		"SOUTH": {Id: "SOUTH", Location: math.Point2LL{-74, 39.2}},
+		// Far outside of the area worth considering.
+		"FAR": {Id: "FAR", Location: math.Point2LL{-74, 43}},
+	}}
+
+	ss := &State{
+		NmPerLongitude: 46,
+		Fixes: map[string]math.Point2LL{
+			"NORTH": {-74, 40.3},
+			// Inside the closed airspace.
+			"INSID": {-74, 40.05},
+		},
+	}
+	ac := &av.Aircraft{Callsign: "AAL1"}
+	ac.Nav.FlightState.Position = math.Point2LL{-75, 40}
+	ac.Nav.Waypoints = []av.Waypoint{
+		{Fix: "MIDPT", Location: math.Point2LL{-74, 40}},
+		{Fix: "REJN", Location: math.Point2LL{-73, 40}},
+	}
+	closed := []av.AirspaceVolume{{Name: "CLOSED", Type: av.AirspaceVolumeCircle,
+		Center: math.Point2LL{-74, 40}, Radius: 10, Ceiling: 18000}}
+
+	s, ok := ss.suggestReroute(ac, closed)
+	if !ok {
+		t.Fatalf("route through closed airspace not found")
+	}
+	if s.Unresolved || s.Fix != "NORTH" || s.Rejoin != "REJN" || s.Command != "DNORTH/RREJN" {
+		t.Errorf("unexpected reroute %+v", s)
+	}
+}
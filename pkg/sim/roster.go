@@ -0,0 +1,102 @@
+// pkg/sim/roster.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Facilities may provide a roster endpoint that vice queries before
+// signing on to a position.  The endpoint is sent an HTTP GET request
+// with "cid", "facility", and "position" query parameters and should
+// respond with JSON corresponding to RosterCheckResult.
+
+// RosterConflict describes another controller's scheduled ownership of
+// the position.
+type RosterConflict struct {
+	Controller string    `json:"controller"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+}
+
+type RosterCheckResult struct {
+	// Valid indicates whether the controller is allowed to work the
+	// position given their rating and certifications.
+	Valid bool `json:"valid"`
+	// Message is optional explanatory text from the facility.
+	Message   string           `json:"message"`
+	Conflicts []RosterConflict `json:"conflicts"`
+}
+
+// Warnings returns human-readable descriptions of any issues with
+// signing on to the position; an empty slice means all is well.
+func (r RosterCheckResult) Warnings() []string {
+	var w []string
+	if !r.Valid {
+		w = append(w, "The position is not valid for your rating.")
+	}
+	for _, c := range r.Conflicts {
+		w = append(w, fmt.Sprintf("%s is scheduled on the position %s-%s.", c.Controller,
+			c.Start.UTC().Format("1504Z"), c.End.UTC().Format("1504Z")))
+	}
+	if r.Message != "" && len(w) > 0 {
+		w = append(w, r.Message)
+	}
+	return w
+}
+
+// CheckRosterPosition queries the facility roster endpoint at rosterURL
+// to determine whether the controller with the given id may sign on to
+// the given position.
+func CheckRosterPosition(rosterURL, cid, facility, position string) (RosterCheckResult, error) {
+	var result RosterCheckResult
+
+	u, err := url.Parse(rosterURL)
+	if err != nil {
+		return result, err
+	}
+	q := u.Query()
+	q.Set("cid", cid)
+	q.Set("facility", facility)
+	q.Set("position", strings.TrimPrefix(position, "_"))
+	u.RawQuery = q.Encode()
+
+	client := http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return result, fmt.Errorf("%s: %s", rosterURL, resp.Status)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	return result, err
+}
+
+// SignOnPosition returns the facility and position the user will sign on
+// to with the current configuration.  The position is empty if it isn't
+// one that should be checked against a facility roster.
+func (c *NewSimConfiguration) SignOnPosition() (facility, position string) {
+	switch c.NewSimType {
+	case NewSimCreateRemote:
+		return c.TRACONName, c.Scenario.SelectedController
+	case NewSimJoinRemote:
+		if c.mgr.remoteServer == nil || c.SelectedRemoteSimPosition == "Observer" {
+			break
+		}
+		if rs, ok := c.mgr.remoteServer.runningSims[c.SelectedRemoteSim]; ok {
+			return rs.GroupName, c.SelectedRemoteSimPosition
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,97 @@
+// pkg/sim/runwayflow.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"slices"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/util"
+)
+
+// RunwayFlowMaps associates video maps--final approach and departure area
+// maps, for example--with a runway flow. The maps are displayed when all
+// of the flow's runways are active and hidden otherwise.
+type RunwayFlowMaps struct {
+	Name             string          `json:"name"`
+	ArrivalRunways   []RunwayFlowRwy `json:"arrival_runways"`
+	DepartureRunways []RunwayFlowRwy `json:"departure_runways"`
+	Maps             []string        `json:"maps"`
+}
+
+type RunwayFlowRwy struct {
+	Airport string `json:"airport"`
+	Runway  string `json:"runway"`
+}
+
+// Active returns true if all of the flow's runways are in use.
+func (f RunwayFlowMaps) Active(ss *State) bool {
+	for _, r := range f.ArrivalRunways {
+		if !slices.ContainsFunc(ss.ArrivalRunways, func(a ScenarioGroupArrivalRunway) bool {
+			return a.Airport == r.Airport && a.Runway == r.Runway
+		}) {
+			return false
+		}
+	}
+	for _, r := range f.DepartureRunways {
+		if !slices.ContainsFunc(ss.DepartureRunways, func(d ScenarioGroupDepartureRunway) bool {
+			return d.Airport == r.Airport && d.Runway == r.Runway
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// RunwayFlowMaps returns the names of the video maps that should be shown
+// and hidden for the current runway configuration. A map that is
+// associated with both an active and an inactive flow is shown.
+func (ss *State) RunwayFlowMaps() (show, hide []string) {
+	for _, f := range ss.STARSFacilityAdaptation.RunwayFlowMaps {
+		if f.Active(ss) {
+			show = append(show, f.Maps...)
+		}
+	}
+	for _, f := range ss.STARSFacilityAdaptation.RunwayFlowMaps {
+		if !f.Active(ss) {
+			for _, m := range f.Maps {
+				if !slices.Contains(show, m) && !slices.Contains(hide, m) {
+					hide = append(hide, m)
+				}
+			}
+		}
+	}
+	return
+}
+
+func (s *STARSFacilityAdaptation) checkRunwayFlowMaps(e *util.ErrorLogger) {
+	// Video map names for the controller configs aren't validated until
+	// the maps are loaded, so only check them against "stars_maps".
+	for _, f := range s.RunwayFlowMaps {
+		e.Push("\"runway_flow_maps\" " + f.Name)
+
+		if len(f.ArrivalRunways) == 0 && len(f.DepartureRunways) == 0 {
+			e.ErrorString("must specify at least one of \"arrival_runways\" and \"departure_runways\"")
+		}
+		for _, r := range append(slices.Clone(f.ArrivalRunways), f.DepartureRunways...) {
+			if _, ok := av.LookupRunway(r.Airport, r.Runway); !ok {
+				e.ErrorString("%s %s: unknown runway", r.Airport, r.Runway)
+			}
+		}
+
+		if len(f.Maps) == 0 {
+			e.ErrorString("must specify \"maps\"")
+		}
+		if len(s.VideoMapNames) > 0 {
+			for _, m := range f.Maps {
+				if !slices.Contains(s.VideoMapNames, m) {
+					e.ErrorString("video map %q not found in \"stars_maps\"", m)
+				}
+			}
+		}
+
+		e.Pop()
+	}
+}
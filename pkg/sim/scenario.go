@@ -23,6 +23,7 @@ import (
 )
 
 type ScenarioGroup struct {
+	FormatVersion    int                       `json:"format_version"`
 	TRACON           string                    `json:"tracon"`
 	Name             string                    `json:"name"`
 	Airports         map[string]*av.Airport    `json:"airports"`
@@ -39,9 +40,26 @@ type ScenarioGroup struct {
 
 	PrimaryAirport string `json:"primary_airport"`
 
+	// BulletinURL gives the location of a facility bulletin (new
+	// procedures, upcoming events, etc.) that is shown to controllers.
+	BulletinURL string `json:"bulletin_url"`
+
+	// Phraseology names the av.PhraseologySet that pilots use for
+	// readbacks; TransitionAltitude, if given, overrides its transition
+	// altitude.
+	Phraseology        string `json:"phraseology"`
+	TransitionAltitude int    `json:"transition_altitude"`
+
 	ReportingPointStrings []string            `json:"reporting_points"`
 	ReportingPoints       []av.ReportingPoint // not in JSON
 
+	// SectorFile optionally gives a EuroScope sector file (.sct); its
+	// fixes, controller positions, sectors, and diagrams are used in
+	// addition to what is specified in the scenario group. A .ese file
+	// with the same base name is used if present.
+	SectorFile string         `json:"sector_file"`
+	sectorFile *av.SectorFile // not in JSON
+
 	NmPerLatitude           float32 // Always 60
 	NmPerLongitude          float32 // Derived from Center
 	MagneticVariation       float32
@@ -69,12 +87,20 @@ type STARSFacilityAdaptation struct {
 	VideoMapLabels      map[string]string                `json:"map_labels"`
 	ControllerConfigs   map[string]STARSControllerConfig `json:"controller_configs"`
 	InhibitCAVolumes    []av.AirspaceVolume              `json:"inhibit_ca_volumes"`
-	RadarSites          map[string]*av.RadarSite         `json:"radar_sites"`
-	Center              math.Point2LL                    `json:"-"`
-	CenterString        string                           `json:"center"`
-	Range               float32                          `json:"range"`
-	Scratchpads         map[string]string                `json:"scratchpads"`
-	SignificantPoints   map[string]SignificantPoint      `json:"significant_points"`
+	// Minimum IFR altitude sectors; each volume's floor gives its MIA.
+	MIAs              []av.AirspaceVolume         `json:"minimum_ifr_altitudes"`
+	RadarSites        map[string]*av.RadarSite    `json:"radar_sites"`
+	Center            math.Point2LL               `json:"-"`
+	CenterString      string                      `json:"center"`
+	Range             float32                     `json:"range"`
+	Scratchpads       map[string]string           `json:"scratchpads"`
+	SignificantPoints map[string]SignificantPoint `json:"significant_points"`
+
+	// Overrides of the aircraft database's CWT categories, from aircraft
+	// type to category, for when FAA categorizations change.
+	CWTCategories map[string]string `json:"cwt_categories"`
+	// Display legacy weight classes rather than CWT categories.
+	DisplayWeightClass bool `json:"display_weight_class"`
 
 	VideoMapFile      string                        `json:"video_map_file"`
 	CoordinationFixes map[string]av.AdaptationFixes `json:"coordination_fixes"`
@@ -94,6 +120,16 @@ type STARSFacilityAdaptation struct {
 		DisplayAltExitGate bool `json:"display_alternate_exit_gate"`
 	} `json:"scratchpad1"`
 	CoordinationLists []CoordinationList `json:"coordination_lists"`
+	Landlines         []Landline         `json:"landlines"`
+	// Finals specifies the extended centerlines drawn for arrival
+	// runways; see ActiveFinals.
+	Finals []FinalApproachCourse `json:"finals"`
+	// RunwayFlowMaps gives video maps that are shown or hidden according
+	// to the active runways.
+	RunwayFlowMaps []RunwayFlowMaps `json:"runway_flow_maps"`
+	// AutoAcquisitionAreas give where departures from particular
+	// airports are automatically acquired.
+	AutoAcquisitionAreas []AutoAcquisitionArea `json:"auto_acquisition_areas"`
 }
 
 type STARSControllerConfig struct {
@@ -102,12 +138,65 @@ type STARSControllerConfig struct {
 	Center        math.Point2LL `json:"-"`
 	CenterString  string        `json:"center"`
 	Range         float32       `json:"range"`
+	// Template, if given, is the facility's recommended setup for the
+	// position, which users can apply and then personalize.
+	Template *PositionTemplate `json:"template,omitempty"`
+}
+
+// PositionTemplate specifies a layout and settings for a control
+// position.
+type PositionTemplate struct {
+	// Version should be incremented whenever the template is changed;
+	// users who have applied an earlier version are offered the update.
+	Version int `json:"version"`
+	// Layout is the name of one of vice's layout templates, e.g.
+	// "Approach" or "Tower".
+	Layout string `json:"layout"`
+	// Lists gives the STARS lists to show (e.g., "VFR", "TAB", "COAST");
+	// the others are hidden.
+	Lists []string `json:"lists"`
+	// Monitor gives control positions whose frequencies are monitored.
+	Monitor []string `json:"monitor"`
+}
+
+// PositionTemplateLayouts and PositionTemplateLists are the names that
+// may be used for a PositionTemplate's Layout and Lists; they are
+// matched case-insensitively.
+var (
+	PositionTemplateLayouts = []string{"Approach", "Center", "Tower", "Strip bay"}
+	PositionTemplateLists   = []string{"VFR", "TAB", "ALERT", "COAST", "SIGN ON", "NO COMM", "MAPS", "CRDA",
+		"ALTIM", "TOWER 1", "TOWER 2", "TOWER 3"}
+)
+
+func (t *PositionTemplate) check(e *util.ErrorLogger, ctrl string, sg *ScenarioGroup) {
+	if t.Version < 1 {
+		e.ErrorString("\"version\" for %q's \"template\" must be at least 1", ctrl)
+	}
+	if t.Layout != "" && !slices.ContainsFunc(PositionTemplateLayouts,
+		func(l string) bool { return strings.EqualFold(l, t.Layout) }) {
+		e.ErrorString("layout %q in %q's \"template\" is unknown. Options: %s", t.Layout, ctrl,
+			strings.Join(PositionTemplateLayouts, ", "))
+	}
+	for _, list := range t.Lists {
+		if !slices.Contains(PositionTemplateLists, strings.ToUpper(list)) {
+			e.ErrorString("list %q in %q's \"template\" is unknown. Options: %s", list, ctrl,
+				strings.Join(PositionTemplateLists, ", "))
+		}
+	}
+	for _, pos := range t.Monitor {
+		if _, ok := sg.ControlPositions[pos]; !ok {
+			e.ErrorString("control position %q in %q's \"template\" is unknown", pos, ctrl)
+		}
+	}
 }
 
 type CoordinationList struct {
 	Name     string   `json:"name"`
 	Id       string   `json:"id"`
 	Airports []string `json:"airports"`
+	// ReleaseWindow is the number of minutes after a departure is
+	// released that the release remains valid; zero means no limit.
+	ReleaseWindow int `json:"release_window"`
 }
 
 type SignificantPoint struct {
@@ -151,10 +240,16 @@ type Scenario struct {
 	DepartureRunways []ScenarioGroupDepartureRunway `json:"departure_runways,omitempty"`
 	ArrivalRunways   []ScenarioGroupArrivalRunway   `json:"arrival_runways,omitempty"`
 
+	// ADSBOutages are areas where ADS-B reports aren't available.
+	ADSBOutages []av.AirspaceVolume `json:"adsb_outages,omitempty"`
+
 	Center       math.Point2LL `json:"-"`
 	CenterString string        `json:"center"`
 	Range        float32       `json:"range"`
 	DefaultMaps  []string      `json:"default_maps"`
+
+	// Phraseology overrides the scenario group's phraseology, if given.
+	Phraseology string `json:"phraseology"`
 }
 
 type ScenarioGroupDepartureRunway struct {
@@ -172,6 +267,8 @@ type ScenarioGroupArrivalRunway struct {
 }
 
 func (s *Scenario) PostDeserialize(sg *ScenarioGroup, e *util.ErrorLogger) {
+	av.CheckPhraseologySet(s.Phraseology, e)
+
 	// Temporary backwards-compatibility for inbound flows
 	if len(s.ArrivalGroupDefaultRates) > 0 {
 		if len(s.InboundFlowDefaultRates) > 0 {
@@ -192,6 +289,11 @@ func (s *Scenario) PostDeserialize(sg *ScenarioGroup, e *util.ErrorLogger) {
 					ctrl.Arrivals = nil
 				}
 			}
+			for _, as := range ctrl.Airspace {
+				if _, ok := sg.Airspace.Volumes[as]; !ok {
+					e.ErrorString("unknown airspace %q", as)
+				}
+			}
 		}
 		e.Pop()
 	}
@@ -211,6 +313,10 @@ func (s *Scenario) PostDeserialize(sg *ScenarioGroup, e *util.ErrorLogger) {
 		}
 	}
 
+	e.Push("\"adsb_outages\"")
+	checkAirspaceVolumes(e, s.ADSBOutages)
+	e.Pop()
+
 	sort.Slice(s.DepartureRunways, func(i, j int) bool {
 		if s.DepartureRunways[i].Airport != s.DepartureRunways[j].Airport {
 			return s.DepartureRunways[i].Airport < s.DepartureRunways[j].Airport
@@ -697,6 +803,8 @@ func (sg *ScenarioGroup) Locate(s string) (math.Point2LL, bool) {
 	// ScenarioGroup's definitions take precedence...
 	if p, ok := sg.Fixes[s]; ok {
 		return p, true
+	} else if p, ok := sg.sectorFileFix(s); ok {
+		return p, true
 	} else if n, ok := av.DB.Navaids[strings.ToUpper(s)]; ok {
 		return n.Location, ok
 	} else if ap, ok := av.DB.Airports[strings.ToUpper(s)]; ok {
@@ -714,12 +822,64 @@ func (sg *ScenarioGroup) Locate(s string) (math.Point2LL, bool) {
 	return math.Point2LL{}, false
 }
 
+func (sg *ScenarioGroup) sectorFileFix(s string) (math.Point2LL, bool) {
+	if sg.sectorFile == nil {
+		return math.Point2LL{}, false
+	}
+	p, ok := sg.sectorFile.Fixes[s]
+	return p, ok
+}
+
+// addSectorFile adds the controller positions and sectors from the
+// scenario group's sector file, if it has one. Those given in the
+// scenario group take precedence.
+func (sg *ScenarioGroup) addSectorFile() {
+	sf := sg.sectorFile
+	if sf == nil {
+		return
+	}
+
+	if sg.ControlPositions == nil {
+		sg.ControlPositions = make(map[string]*av.Controller)
+	}
+	for _, pos := range sf.Positions {
+		if _, ok := sg.ControlPositions[pos.Callsign]; !ok {
+			sg.ControlPositions[pos.Callsign] = pos.Controller()
+		}
+	}
+
+	if sg.Airspace.Boundaries == nil {
+		sg.Airspace.Boundaries = make(map[string][]math.Point2LL)
+	}
+	if sg.Airspace.Volumes == nil {
+		sg.Airspace.Volumes = make(map[string][]ControllerAirspaceVolume)
+	}
+	for _, sector := range sf.Sectors {
+		_, haveBoundary := sg.Airspace.Boundaries[sector.Name]
+		_, haveVolume := sg.Airspace.Volumes[sector.Name]
+		if haveBoundary || haveVolume || len(sector.Boundary) < 3 {
+			continue
+		}
+		sg.Airspace.Boundaries[sector.Name] = sector.Boundary
+		sg.Airspace.Volumes[sector.Name] = []ControllerAirspaceVolume{{
+			LowerLimit:    sector.Floor,
+			UpperLimit:    sector.Ceiling,
+			BoundaryNames: []string{sector.Name},
+		}}
+	}
+}
+
 var (
 	// "FIX@HDG/DIST"
 	reFixHeadingDistance = regexp.MustCompile(`^([\w-]{3,})@([\d]{3})/(\d+(\.\d+)?)$`)
 )
 
 func (sg *ScenarioGroup) PostDeserialize(multiController bool, e *util.ErrorLogger, simConfigurations map[string]map[string]*Configuration) {
+	av.CheckPhraseologySet(sg.Phraseology, e)
+	if sg.TransitionAltitude < 0 {
+		e.ErrorString("\"transition_altitude\" cannot be negative")
+	}
+
 	// Temporary backwards compatibility for inbound flows
 	if len(sg.ArrivalGroups) > 0 {
 		if len(sg.InboundFlows) > 0 {
@@ -737,9 +897,13 @@ func (sg *ScenarioGroup) PostDeserialize(multiController bool, e *util.ErrorLogg
 		}
 	}
 
+	sg.addSectorFile()
+
 	// stars_config items. This goes first because we need to initialize
 	// Center (and thence NmPerLongitude) ASAP.
-	if ctr := sg.STARSFacilityAdaptation.CenterString; ctr == "" {
+	if ctr := sg.STARSFacilityAdaptation.CenterString; ctr == "" && sg.sectorFile != nil {
+		sg.STARSFacilityAdaptation.Center = sg.sectorFile.Center
+	} else if ctr == "" {
 		e.ErrorString("No \"center\" specified")
 	} else if pos, ok := sg.Locate(ctr); !ok {
 		e.ErrorString("unknown location %q specified for \"center\"", ctr)
@@ -750,6 +914,11 @@ func (sg *ScenarioGroup) PostDeserialize(multiController bool, e *util.ErrorLogg
 	sg.NmPerLatitude = 60
 	sg.NmPerLongitude = 60 * math.Cos(math.Radians(sg.STARSFacilityAdaptation.Center[1]))
 
+	if sg.BulletinURL != "" && !strings.HasPrefix(sg.BulletinURL, "http://") &&
+		!strings.HasPrefix(sg.BulletinURL, "https://") {
+		e.ErrorString("\"bulletin_url\" must be an http or https URL")
+	}
+
 	if sg.TRACON == "" {
 		e.ErrorString("\"tracon\" must be specified")
 	} else if _, ok := av.DB.TRACONs[sg.TRACON]; !ok {
@@ -902,6 +1071,12 @@ func (sg *ScenarioGroup) PostDeserialize(multiController bool, e *util.ErrorLogg
 func (s *STARSFacilityAdaptation) PostDeserialize(e *util.ErrorLogger, sg *ScenarioGroup) {
 	e.Push("stars_config")
 
+	for actype, cwt := range s.CWTCategories {
+		if !slices.Contains(av.CWTCategories, cwt) {
+			e.ErrorString("invalid CWT category %q for %q in \"cwt_categories\"", cwt, actype)
+		}
+	}
+
 	// Video maps
 	for m := range s.VideoMapLabels {
 		if !slices.Contains(s.VideoMapNames, m) {
@@ -936,6 +1111,9 @@ func (s *STARSFacilityAdaptation) PostDeserialize(e *util.ErrorLogger, sg *Scena
 						"\"controller_maps\"", name, ctrl)
 				}
 			}
+			if config.Template != nil {
+				config.Template.check(e, ctrl, sg)
+			}
 			// Make sure all of the control positions are included in at least
 			// one of the scenarios.  As with VideoMapNames, don't try to
 			// validate the map names yet.
@@ -1121,6 +1299,9 @@ func (s *STARSFacilityAdaptation) PostDeserialize(e *util.ErrorLogger, sg *Scena
 		if len(list.Airports) == 0 {
 			e.ErrorString("At least one airport must be specified in \"airports\" for coordination list.")
 		}
+		if list.ReleaseWindow < 0 {
+			e.ErrorString("\"release_window\" must not be negative.")
+		}
 
 		seenIds[list.Id] = append(seenIds[list.Id], list.Name)
 
@@ -1140,6 +1321,11 @@ func (s *STARSFacilityAdaptation) PostDeserialize(e *util.ErrorLogger, sg *Scena
 		}
 	}
 
+	s.checkLandlines(e, sg)
+	s.checkFinals(e)
+	s.checkRunwayFlowMaps(e)
+	s.checkAutoAcquisitionAreas(e, sg)
+
 	if len(s.VideoMapNames) == 0 {
 		if len(s.ControllerConfigs) == 0 {
 			e.ErrorString("must provide one of \"stars_maps\" or \"controller_configs\" in \"stars_config\"")
@@ -1175,6 +1361,17 @@ func (s *STARSFacilityAdaptation) PostDeserialize(e *util.ErrorLogger, sg *Scena
 	e.Pop() // stars_config
 }
 
+// ReleaseWindow returns how long a release for a departure from the given
+// airport remains valid; zero is returned if it doesn't lapse.
+func (fa *STARSFacilityAdaptation) ReleaseWindow(airport string) time.Duration {
+	for _, list := range fa.CoordinationLists {
+		if slices.Contains(list.Airports, airport) {
+			return time.Duration(list.ReleaseWindow) * time.Minute
+		}
+	}
+	return 0
+}
+
 func (fa *STARSFacilityAdaptation) GetCoordinationFix(fp *STARSFlightPlan, acpos math.Point2LL, waypoints []av.Waypoint) (string, bool) {
 	for fix, adaptationFixes := range fa.CoordinationFixes {
 		if adaptationFix, err := adaptationFixes.Fix(fp.Altitude); err == nil {
@@ -1343,6 +1540,11 @@ func loadScenarioGroup(filesystem fs.FS, path string, e *util.ErrorLogger) *Scen
 		e.Error(err)
 		return nil
 	}
+	if s.FormatVersion > ScenarioFormatVersion {
+		e.ErrorString("scenario requires version %d of the scenario format but this version of vice only "+
+			"supports up to version %d; please upgrade vice", s.FormatVersion, ScenarioFormatVersion)
+		return nil
+	}
 	if s.Name == "" {
 		e.ErrorString("scenario group is missing \"name\"")
 		return nil
@@ -1351,6 +1553,16 @@ func loadScenarioGroup(filesystem fs.FS, path string, e *util.ErrorLogger) *Scen
 		e.ErrorString("scenario group is missing \"tracon\"")
 		return nil
 	}
+	if s.SectorFile != "" {
+		if s.sectorFile = av.LoadSectorFile(filesystem, s.SectorFile, e); s.sectorFile == nil {
+			return nil
+		}
+		// Use the sector file's diagrams for video maps unless others
+		// were given.
+		if s.STARSFacilityAdaptation.VideoMapFile == "" {
+			s.STARSFacilityAdaptation.VideoMapFile = s.SectorFile
+		}
+	}
 	return &s
 }
 
@@ -1452,15 +1664,22 @@ func LoadScenarioGroups(isLocal bool, extraScenarioFilename string, extraVideoMa
 	}
 
 	// Load the scenario specified on command line, if any.
+	var extraVideoMapFS fs.FS
 	if extraScenarioFilename != "" {
-		fs := func() fs.FS {
-			if filepath.IsAbs(extraScenarioFilename) {
-				return RootFS{}
-			} else {
-				return os.DirFS(".")
-			}
-		}()
-		s := loadScenarioGroup(fs, extraScenarioFilename, e)
+		src, err := openScenarioSource(extraScenarioFilename)
+		if err != nil {
+			e.Error(err)
+			return nil, nil, nil
+		}
+		if src.stale {
+			lg.Warnf("%s: unable to download scenario; using the most recently downloaded copy", extraScenarioFilename)
+		}
+		if src.videoMapPath != "" && extraVideoMapFilename == "" {
+			// Use the video map from the scenario package.
+			extraVideoMapFilename, extraVideoMapFS = src.videoMapPath, src.fs
+		}
+
+		s := loadScenarioGroup(src.fs, src.scenarioPath, e)
 		if s != nil {
 			// These are allowed to redefine an existing scenario.
 			if scenarioGroups[s.TRACON] == nil {
@@ -1505,12 +1724,26 @@ func LoadScenarioGroups(isLocal bool, extraScenarioFilename string, extraVideoMa
 		os.Exit(1)
 	}
 
+	// Sector files have already been loaded along with the scenario
+	// groups that use them.
+	for _, tracon := range scenarioGroups {
+		for _, sg := range tracon {
+			if sf := sg.sectorFile; sf != nil && sg.STARSFacilityAdaptation.VideoMapFile == sg.SectorFile &&
+				!maplib.HaveFile(sg.SectorFile) {
+				maplib.AddSectorFile(sg.SectorFile, sf, referencedVideoMaps[sg.SectorFile], e)
+			}
+		}
+	}
+
 	lg.Infof("scenario/video map manifest load time: %s\n", time.Since(start))
 
-	// Load the video map specified on the command line, if any.
+	// Load the video map specified on the command line or in the
+	// scenario package, if any.
 	if extraVideoMapFilename != "" {
 		fs := func() fs.FS {
-			if filepath.IsAbs(extraVideoMapFilename) {
+			if extraVideoMapFS != nil {
+				return extraVideoMapFS
+			} else if filepath.IsAbs(extraVideoMapFilename) {
 				return RootFS{}
 			} else {
 				return os.DirFS(".")
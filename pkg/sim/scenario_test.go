@@ -0,0 +1,38 @@
+// pkg/sim/scenario_test.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"testing"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/util"
+)
+
+func TestPositionTemplateCheck(t *testing.T) {
+	sg := &ScenarioGroup{ControlPositions: map[string]*av.Controller{"PHL_TWR": {}}}
+
+	for _, test := range []struct {
+		name     string
+		template PositionTemplate
+		errors   bool
+	}{
+		{name: "valid", template: PositionTemplate{Version: 1, Layout: "Tower", Lists: []string{"VFR", "tower 1"},
+			Monitor: []string{"PHL_TWR"}}},
+		{name: "layout case", template: PositionTemplate{Version: 1, Layout: "strip BAY"}},
+		{name: "no version", errors: true, template: PositionTemplate{Layout: "Tower"}},
+		{name: "unknown layout", errors: true, template: PositionTemplate{Version: 1, Layout: "Ground"}},
+		{name: "unknown list", errors: true, template: PositionTemplate{Version: 1, Lists: []string{"VFR", "SSA"}}},
+		{name: "unknown position", errors: true, template: PositionTemplate{Version: 1, Monitor: []string{"PHL_GND"}}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var e util.ErrorLogger
+			test.template.check(&e, "PHL_APP", sg)
+			if e.HaveErrors() != test.errors {
+				t.Errorf("errors: got %v, expected %v: %s", e.HaveErrors(), test.errors, e.String())
+			}
+		})
+	}
+}
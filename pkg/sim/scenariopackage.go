@@ -0,0 +1,178 @@
+// pkg/sim/scenariopackage.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mmp/vice/pkg/util"
+)
+
+// ScenarioFormatVersion is the most recent version of the scenario file
+// format that this version of vice understands. Scenario files may give
+// the version they require with "format_version"; those that require a
+// newer one than this are rejected with a request to upgrade vice rather
+// than failing in confusing ways.
+const ScenarioFormatVersion = 1
+
+// Scenario packages downloaded from a URL may be no larger than this.
+const maxScenarioPackageSize = 256 * 1024 * 1024
+
+// scenarioSource describes where a scenario specified by the user is to be
+// loaded from. Scenarios may be given as a local JSON file or as a
+// scenario package: a zip file holding a single scenario JSON file along
+// with, optionally, its video map and video map manifest files. Either
+// may also be given as an http or https URL.
+type scenarioSource struct {
+	fs           fs.FS
+	scenarioPath string
+	// videoMapPath is the path of the video map file in a scenario
+	// package, if it includes one.
+	videoMapPath string
+	// stale is set if the scenario couldn't be downloaded and the most
+	// recently downloaded copy is being used.
+	stale bool
+}
+
+func openScenarioSource(name string) (scenarioSource, error) {
+	if strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://") {
+		data, stale, err := util.GetResourceCache().Get(name, 0, func() ([]byte, error) {
+			return fetchScenario(name)
+		})
+		if err != nil {
+			return scenarioSource{}, err
+		}
+
+		if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+			zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				return scenarioSource{}, fmt.Errorf("%s: %w", name, err)
+			}
+			src, err := openScenarioPackage(name, zr)
+			src.stale = stale
+			return src, err
+		}
+
+		fn := path.Base(name)
+		return scenarioSource{
+			fs:           memFS{name: fn, data: data},
+			scenarioPath: fn,
+			stale:        stale,
+		}, nil
+	}
+
+	if strings.ToLower(filepath.Ext(name)) == ".zip" {
+		// The video map is loaded from the package later, when it's
+		// first needed, so the package is read into memory rather than
+		// keeping the file open until then.
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return scenarioSource{}, err
+		}
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return scenarioSource{}, fmt.Errorf("%s: %w", name, err)
+		}
+		return openScenarioPackage(name, zr)
+	}
+
+	if filepath.IsAbs(name) {
+		return scenarioSource{fs: RootFS{}, scenarioPath: name}, nil
+	}
+	return scenarioSource{fs: os.DirFS("."), scenarioPath: name}, nil
+}
+
+// memFS is an fs.FS that holds a single file in memory.
+type memFS struct {
+	name string
+	data []byte
+}
+
+func (m memFS) Open(name string) (fs.File, error) {
+	if name != m.name {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &memFile{Reader: bytes.NewReader(m.data), fs: m}, nil
+}
+
+type memFile struct {
+	*bytes.Reader
+	fs memFS
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f, nil }
+func (f *memFile) Close() error               { return nil }
+
+// fs.FileInfo implementation
+func (f *memFile) Name() string       { return f.fs.name }
+func (f *memFile) Size() int64        { return int64(len(f.fs.data)) }
+func (f *memFile) Mode() fs.FileMode  { return 0o444 }
+func (f *memFile) ModTime() time.Time { return time.Time{} }
+func (f *memFile) IsDir() bool        { return false }
+func (f *memFile) Sys() any           { return nil }
+
+func fetchScenario(url string) ([]byte, error) {
+	client := http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxScenarioPackageSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", url, err)
+	} else if len(data) > maxScenarioPackageSize {
+		return nil, fmt.Errorf("%s: scenario is larger than the %d MB limit", url, maxScenarioPackageSize/(1024*1024))
+	}
+	return data, nil
+}
+
+// openScenarioPackage finds the scenario and video map files in a
+// scenario package.
+func openScenarioPackage(name string, zr *zip.Reader) (scenarioSource, error) {
+	src := scenarioSource{fs: zr}
+	var scenarios, videoMaps []string
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || strings.HasPrefix(f.Name, "__MACOSX/") {
+			continue
+		}
+		switch {
+		case strings.ToLower(path.Ext(f.Name)) == ".json":
+			scenarios = append(scenarios, f.Name)
+		case strings.HasSuffix(f.Name, "-videomaps.gob") || strings.HasSuffix(f.Name, "-videomaps.gob.zst"):
+			videoMaps = append(videoMaps, f.Name)
+		}
+	}
+
+	if len(scenarios) != 1 {
+		return src, fmt.Errorf("%s: scenario package must contain exactly one JSON scenario file; found %d",
+			name, len(scenarios))
+	}
+	src.scenarioPath = scenarios[0]
+
+	if len(videoMaps) > 1 {
+		return src, fmt.Errorf("%s: scenario package may contain at most one video map file; found %s",
+			name, strings.Join(videoMaps, ", "))
+	} else if len(videoMaps) == 1 {
+		src.videoMapPath = videoMaps[0]
+	}
+
+	return src, nil
+}
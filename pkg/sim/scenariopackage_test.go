@@ -0,0 +1,66 @@
+// pkg/sim/scenariopackage_test.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"archive/zip"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMemFS(t *testing.T) {
+	m := memFS{name: "scenario.json", data: []byte(`{"tracon": "PHL"}`)}
+	if fi, err := fs.Stat(m, "scenario.json"); err != nil {
+		t.Errorf("stat: %v", err)
+	} else if fi.Name() != "scenario.json" || fi.Size() != 17 || fi.IsDir() {
+		t.Errorf("unexpected file info: %q %d %v", fi.Name(), fi.Size(), fi.IsDir())
+	}
+	if b, err := fs.ReadFile(m, "scenario.json"); err != nil || string(b) != `{"tracon": "PHL"}` {
+		t.Errorf("got %q, %v", b, err)
+	}
+	if _, err := m.Open("other.json"); err == nil {
+		t.Errorf("expected an error opening a missing file")
+	}
+}
+
+func TestOpenScenarioPackage(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "pkg.zip")
+	f, err := os.Create(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, name := range []string{"phl/scenario.json", "phl/phl-videomaps.gob", "phl/phl-manifest.gob",
+		"__MACOSX/phl/._scenario.json"} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte("{}"))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	src, err := openScenarioSource(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.scenarioPath != "phl/scenario.json" || src.videoMapPath != "phl/phl-videomaps.gob" {
+		t.Errorf("got scenario %q and video map %q", src.scenarioPath, src.videoMapPath)
+	}
+
+	// The package is read into memory, so its files are still available
+	// after the zip file is removed.
+	if err := os.Remove(fn); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.ReadFile(src.fs, src.videoMapPath); err != nil {
+		t.Errorf("unable to read the video map from the package: %v", err)
+	}
+}
@@ -73,9 +73,15 @@ type LaunchConfig struct {
 	Mode int
 
 	GoAroundRate float32
+	// ReadbackErrorRate is the probability that a pilot reads back (and
+	// flies) an incorrect altitude, heading, or speed.
+	ReadbackErrorRate float32
 	// airport -> runway -> category -> rate
-	DepartureRates     map[string]map[string]map[string]float32
-	DepartureRateScale float32
+	DepartureRates                map[string]map[string]map[string]float32
+	DepartureRateScale            float32
+	DeparturePushes               bool
+	DeparturePushFrequencyMinutes int
+	DeparturePushLengthMinutes    int
 	// inbound flow -> airport / "overflights" -> rate
 	InboundFlowRates            map[string]map[string]float32
 	InboundFlowRateScale        float32
@@ -91,6 +97,9 @@ func MakeLaunchConfig(dep []ScenarioGroupDepartureRunway, inbound map[string]map
 		InboundFlowRateScale:        1,
 		ArrivalPushFrequencyMinutes: 20,
 		ArrivalPushLengthMinutes:    10,
+
+		DeparturePushFrequencyMinutes: 30,
+		DeparturePushLengthMinutes:    10,
 	}
 
 	// Walk the departure runways to create the map for departures.
@@ -117,6 +126,43 @@ func MakeLaunchConfig(dep []ScenarioGroupDepartureRunway, inbound map[string]map
 	return lc
 }
 
+// demandProfiles are preset traffic levels that scale both the departure
+// and arrival/overflight rates.
+var demandProfiles = []struct {
+	Name  string
+	Scale float32
+}{
+	{"Light", 0.5},
+	{"Moderate", 1},
+	{"Heavy", 1.5},
+	{"Very heavy", 2},
+	{"Extreme", 3},
+}
+
+// DrawDemandUI draws a selector for the overall traffic demand; selecting
+// a profile sets both the departure and arrival rate scales, which can
+// still be individually adjusted afterward.
+func (lc *LaunchConfig) DrawDemandUI() (changed bool) {
+	current := "Custom"
+	for _, dp := range demandProfiles {
+		if lc.DepartureRateScale == dp.Scale && lc.InboundFlowRateScale == dp.Scale {
+			current = dp.Name
+		}
+	}
+
+	if imgui.BeginComboV("Traffic demand", current, 0) {
+		for _, dp := range demandProfiles {
+			if imgui.SelectableV(dp.Name, dp.Name == current, 0, imgui.Vec2{}) {
+				lc.DepartureRateScale = dp.Scale
+				lc.InboundFlowRateScale = dp.Scale
+				changed = true
+			}
+		}
+		imgui.EndCombo()
+	}
+	return
+}
+
 func (lc *LaunchConfig) DrawDepartureUI(p platform.Platform) (changed bool) {
 	if len(lc.DepartureRates) == 0 {
 		return
@@ -135,6 +181,20 @@ func (lc *LaunchConfig) DrawDepartureUI(p platform.Platform) (changed bool) {
 	// from being here initially.
 	changed = imgui.SliderFloatV("Departure rate scale", &lc.DepartureRateScale, 0, 5, "%.1f", imgui.SliderFlagsNoInput) || changed
 
+	if lc.DeparturePushFrequencyMinutes == 0 {
+		// Launch configs saved before departure pushes were added.
+		lc.DeparturePushFrequencyMinutes, lc.DeparturePushLengthMinutes = 30, 10
+	}
+	changed = imgui.Checkbox("Include random departure pushes", &lc.DeparturePushes) || changed
+	uiStartDisable(!lc.DeparturePushes)
+	dfreq := int32(lc.DeparturePushFrequencyMinutes)
+	changed = imgui.SliderInt("Departure push frequency (minutes)", &dfreq, 3, 60) || changed
+	lc.DeparturePushFrequencyMinutes = int(dfreq)
+	dlen := int32(lc.DeparturePushLengthMinutes)
+	changed = imgui.SliderInt("Length of departure push (minutes)", &dlen, 5, 30) || changed
+	lc.DeparturePushLengthMinutes = int(dlen)
+	uiEndDisable(!lc.DeparturePushes)
+
 	flags := imgui.TableFlagsBordersV | imgui.TableFlagsBordersOuterH | imgui.TableFlagsRowBg | imgui.TableFlagsSizingStretchProp
 
 	tableScale := util.Select(runtime.GOOS == "windows", p.DPIScale(), float32(1))
@@ -209,6 +269,11 @@ func (lc *LaunchConfig) DrawArrivalUI(p platform.Platform) (changed bool) {
 	changed = imgui.SliderFloatV("Arrival/overflight rate scale", &lc.InboundFlowRateScale, 0, 5, "%.1f", imgui.SliderFlagsNoInput) || changed
 
 	changed = imgui.SliderFloatV("Go around probability", &lc.GoAroundRate, 0, 1, "%.02f", 0) || changed
+	changed = imgui.SliderFloatV("Readback error probability", &lc.ReadbackErrorRate, 0, 0.5, "%.02f", 0) || changed
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Probability that a pilot reads back an incorrect altitude, heading, or speed,\n" +
+			"which must be caught and corrected within 30 seconds")
+	}
 
 	changed = imgui.Checkbox("Include random arrival pushes", &lc.ArrivalPushes) || changed
 	uiStartDisable(!lc.ArrivalPushes)
@@ -335,6 +400,10 @@ type RemoteSim struct {
 	RequirePassword    bool
 	AvailablePositions map[string]struct{}
 	CoveredPositions   map[string]struct{}
+	// Positions gives the control position definitions for the
+	// available positions so that they can be presented by facility
+	// along with their frequencies.
+	Positions map[string]*av.Controller
 }
 
 const (
@@ -419,6 +488,84 @@ func (c *NewSimConfiguration) SetScenario(groupName, scenarioName string) {
 	c.ScenarioName = scenarioName
 }
 
+// SelectPosition sets up the configuration to create a single-controller
+// sim with the user signed on to the given position, using the first
+// scenario, checking the default TRACON's first, that has it as its
+// controller. If a split is given, the first scenario that has a split
+// with that name where the position is the primary one is used instead
+// and the split is selected; the position may then be empty, in which
+// case the split's primary position is used.
+func (c *NewSimConfiguration) SelectPosition(position, split string) error {
+	configs := c.selectedServer.configs
+	tracons := util.SortedMapKeys(configs)
+	if i := slices.Index(tracons, *c.defaultTRACON); i > 0 {
+		tracons = append([]string{tracons[i]}, slices.Delete(tracons, i, i+1)...)
+	}
+
+	for _, tracon := range tracons {
+		for _, group := range util.SortedMapKeys(configs[tracon]) {
+			scenarios := configs[tracon][group].ScenarioConfigs
+			for _, name := range util.SortedMapKeys(scenarios) {
+				sc := scenarios[name]
+				if split != "" {
+					if _, ok := sc.SplitConfigurations[split]; !ok {
+						continue
+					}
+					primary, err := sc.SplitConfigurations.GetPrimaryController(split)
+					if err != nil || (position != "" && primary != position) {
+						continue
+					}
+					c.NewSimType = NewSimCreateLocal
+					c.SetTRACON(tracon)
+					c.SetScenario(group, name)
+					c.Scenario.SelectedSplit, c.Scenario.SelectedController = split, primary
+					return nil
+				} else if sc.SelectedController == position {
+					c.NewSimType = NewSimCreateLocal
+					c.SetTRACON(tracon)
+					c.SetScenario(group, name)
+					return nil
+				}
+			}
+		}
+	}
+	if split != "" {
+		if position == "" {
+			return fmt.Errorf("%s: no scenario has this split", split)
+		}
+		return fmt.Errorf("%s: no scenario has this split with %s as its primary position", split, position)
+	}
+	return fmt.Errorf("%s: no scenario has this position", position)
+}
+
+// SelectRemoteSim sets up the configuration to join the running
+// multi-controller sim with the given name at the given position, or as
+// an observer if the position is empty. It returns false if the
+// connection to the multi-controller server hasn't been made yet.
+func (c *NewSimConfiguration) SelectRemoteSim(name, position string) (bool, error) {
+	if c.mgr.remoteServer == nil {
+		return false, nil
+	}
+
+	rs, ok := c.mgr.remoteServer.runningSims[name]
+	if !ok {
+		return true, fmt.Errorf("%s: no multi-controller sim with this name is running", name)
+	}
+	if position == "" {
+		position = "Observer"
+	} else if _, ok := rs.AvailablePositions[position]; !ok {
+		return true, fmt.Errorf("%s: position is not available in %s", position, name)
+	}
+	if rs.RequirePassword && c.RemoteSimPassword == "" {
+		return true, fmt.Errorf("%s: a password is required to join this sim", name)
+	}
+
+	c.NewSimType = NewSimJoinRemote
+	c.selectedServer = c.mgr.remoteServer
+	c.SelectedRemoteSim, c.SelectedRemoteSimPosition = name, position
+	return true, nil
+}
+
 func (c *NewSimConfiguration) UIButtonText() string {
 	return util.Select(c.NewSimType == NewSimJoinRemote, "Join", "Next")
 }
@@ -751,7 +898,9 @@ func (c *NewSimConfiguration) DrawUI(p platform.Platform) bool {
 			c.SelectedRemoteSimPosition = util.SortedMapKeys(rs.AvailablePositions)[0]
 		}
 
-		if imgui.BeginComboV("Position", c.SelectedRemoteSimPosition, 0) {
+		if len(rs.Positions) > 0 {
+			c.drawPositionTree(rs, p)
+		} else if imgui.BeginComboV("Position", c.SelectedRemoteSimPosition, 0) {
 			for _, pos := range util.SortedMapKeys(rs.AvailablePositions) {
 				if pos[0] == '_' {
 					continue
@@ -775,7 +924,72 @@ func (c *NewSimConfiguration) DrawUI(p platform.Platform) bool {
 	return false
 }
 
+// drawPositionTree presents the available positions in a remote sim
+// grouped by facility, along with their frequencies and names.
+func (c *NewSimConfiguration) drawPositionTree(rs *RemoteSim, p platform.Platform) {
+	facilities := make(map[string][]*av.Controller)
+	for pos := range rs.AvailablePositions {
+		if pos[0] == '_' {
+			continue
+		}
+		ctrl, ok := rs.Positions[pos]
+		if !ok {
+			ctrl = &av.Controller{Callsign: pos}
+		}
+		fac := util.Select(ctrl.Facility != "", ctrl.Facility, "Other")
+		facilities[fac] = append(facilities[fac], ctrl)
+	}
+
+	imgui.Text("Position:")
+	tableScale := util.Select(runtime.GOOS == "windows", p.DPIScale(), float32(1))
+	flags := imgui.TableFlagsBordersV | imgui.TableFlagsBordersOuterH | imgui.TableFlagsRowBg |
+		imgui.TableFlagsSizingFixedFit | imgui.TableFlagsScrollY
+	if imgui.BeginTableV("positions", 3, flags, imgui.Vec2{tableScale * 500, tableScale * 200}, 0.) {
+		imgui.TableSetupColumn("Callsign")
+		imgui.TableSetupColumn("Frequency")
+		imgui.TableSetupColumn("Name")
+		imgui.TableHeadersRow()
+
+		selectable := func(callsign, freq, name string) {
+			imgui.TableNextRow()
+			imgui.TableNextColumn()
+			selFlags := imgui.SelectableFlagsSpanAllColumns | imgui.SelectableFlagsDontClosePopups
+			if imgui.SelectableV(callsign, callsign == c.SelectedRemoteSimPosition, selFlags, imgui.Vec2{}) {
+				c.SelectedRemoteSimPosition = callsign
+			}
+			imgui.TableNextColumn()
+			imgui.Text(freq)
+			imgui.TableNextColumn()
+			imgui.Text(name)
+		}
+
+		for _, fac := range util.SortedMapKeys(facilities) {
+			ctrls := facilities[fac]
+			slices.SortFunc(ctrls, func(a, b *av.Controller) int { return strings.Compare(a.Callsign, b.Callsign) })
+
+			imgui.TableNextRow()
+			imgui.TableNextColumn()
+			// Open the facility with the selected position by default.
+			sel := slices.ContainsFunc(ctrls, func(ctrl *av.Controller) bool {
+				return ctrl.Callsign == c.SelectedRemoteSimPosition
+			})
+			imgui.SetNextItemOpen(sel || len(facilities) == 1, imgui.ConditionOnce)
+			if imgui.TreeNodeV(fac, imgui.TreeNodeFlagsSpanFullWidth) {
+				for _, ctrl := range ctrls {
+					selectable(ctrl.Callsign, util.Select(ctrl.Frequency != 0, ctrl.Frequency.String(), ""),
+						ctrl.FullName)
+				}
+				imgui.TreePop()
+			}
+		}
+		selectable("Observer", "", "")
+
+		imgui.EndTable()
+	}
+}
+
 func (c *NewSimConfiguration) DrawRatesUI(p platform.Platform) bool {
+	c.Scenario.LaunchConfig.DrawDemandUI()
 	c.Scenario.LaunchConfig.DrawDepartureUI(p)
 	c.Scenario.LaunchConfig.DrawArrivalUI(p)
 	c.Scenario.LaunchConfig.DrawOverflightUI(p)
@@ -906,6 +1120,11 @@ type Sim struct {
 	TotalArrivals    int
 	TotalOverflights int
 
+	// callsign -> outstanding incorrect readback
+	ReadbackErrors       map[string]ReadbackError
+	ReadbackErrorsCaught int
+	ReadbackErrorsMissed int
+
 	ReportingPoints []av.ReportingPoint
 
 	RequirePassword bool
@@ -923,6 +1142,9 @@ type Sim struct {
 
 	NextPushStart time.Time // both w.r.t. sim time
 	PushEnd       time.Time
+
+	NextDeparturePushStart time.Time // both w.r.t. sim time
+	DeparturePushEnd       time.Time
 }
 
 // DepartureAircraft represents a departing aircraft, either still on the
@@ -1016,6 +1238,10 @@ func NewSim(ssc NewSimConfiguration, scenarioGroups map[string]map[string]*Scena
 		m := 1 + rand.Intn(s.LaunchConfig.ArrivalPushFrequencyMinutes)
 		s.NextPushStart = time.Now().Add(time.Duration(m) * time.Minute)
 	}
+	if s.LaunchConfig.DeparturePushes {
+		m := 1 + rand.Intn(s.LaunchConfig.DeparturePushFrequencyMinutes)
+		s.NextDeparturePushStart = time.Now().Add(time.Duration(m) * time.Minute)
+	}
 
 	s.SignOnPositions = make(map[string]*av.Controller)
 	add := func(callsign string) {
@@ -1061,11 +1287,15 @@ func (s *Sim) LogValue() slog.Value {
 		slog.Int("departures", s.TotalDepartures),
 		slog.Int("arrivals", s.TotalArrivals),
 		slog.Int("overflights", s.TotalOverflights),
+		slog.Int("readback_errors_caught", s.ReadbackErrorsCaught),
+		slog.Int("readback_errors_missed", s.ReadbackErrorsMissed),
 		slog.Time("sim_time", s.SimTime),
 		slog.Float64("sim_rate", float64(s.SimRate)),
 		slog.Bool("paused", s.Paused),
 		slog.Time("next_push_start", s.NextPushStart),
 		slog.Time("push_end", s.PushEnd),
+		slog.Time("next_departure_push_start", s.NextDeparturePushStart),
+		slog.Time("departure_push_end", s.DeparturePushEnd),
 		slog.Any("aircraft", s.State.Aircraft))
 }
 
@@ -1213,6 +1443,14 @@ func (s *Sim) PostEvent(e Event) {
 type GlobalMessage struct {
 	Message        string
 	FromController string
+	// Guard indicates that the message was transmitted on the guard
+	// frequency rather than sent as a text message.
+	Guard bool
+	// ToControllers and Landline are set for messages sent over a
+	// landline, in which case the message only goes to the given
+	// positions.
+	ToControllers []string
+	Landline      LandlineType
 }
 
 type WorldUpdate struct {
@@ -1230,6 +1468,9 @@ type WorldUpdate struct {
 	TotalDepartures  int
 	TotalArrivals    int
 	TotalOverflights int
+
+	ReadbackErrorsCaught int
+	ReadbackErrorsMissed int
 }
 
 func (s *Sim) GetWorldUpdate(token string, update *WorldUpdate) error {
@@ -1262,6 +1503,9 @@ func (s *Sim) GetWorldUpdate(token string, update *WorldUpdate) error {
 			TotalDepartures:  s.TotalDepartures,
 			TotalArrivals:    s.TotalArrivals,
 			TotalOverflights: s.TotalOverflights,
+
+			ReadbackErrorsCaught: s.ReadbackErrorsCaught,
+			ReadbackErrorsMissed: s.ReadbackErrorsMissed,
 		})
 
 		return err
@@ -1429,6 +1673,8 @@ func (s *Sim) updateState() {
 		}
 	}
 
+	s.updateReadbackErrors()
+
 	// Update the simulation state once a second.
 	if now.Sub(s.lastSimUpdate) >= time.Second {
 		s.lastSimUpdate = now
@@ -1775,6 +2021,23 @@ func (s *Sim) spawnDepartures() {
 
 	// Make sure we have a few departing aircraft to work with.
 	s.refreshDeparturePool()
+	s.checkEDCTs()
+
+	if !s.NextDeparturePushStart.IsZero() && now.After(s.NextDeparturePushStart) {
+		s.DeparturePushEnd = now.Add(time.Duration(s.LaunchConfig.DeparturePushLengthMinutes) * time.Minute)
+		s.lg.Info("departure push starting", slog.Time("end_time", s.DeparturePushEnd))
+		s.NextDeparturePushStart = time.Time{}
+	}
+	if !s.DeparturePushEnd.IsZero() && now.After(s.DeparturePushEnd) {
+		m := -2 + rand.Intn(4) + s.LaunchConfig.DeparturePushFrequencyMinutes
+		s.NextDeparturePushStart = now.Add(time.Duration(m) * time.Minute)
+		s.lg.Info("departure push ending", slog.Time("next_start", s.NextDeparturePushStart))
+		s.DeparturePushEnd = time.Time{}
+	}
+
+	pushActive := now.Before(s.DeparturePushEnd)
+
+	s.expireUnusedReleases(now)
 
 	for airport, launchTime := range s.NextDepartureLaunch {
 		if !now.After(launchTime) {
@@ -1783,15 +2046,21 @@ func (s *Sim) spawnDepartures() {
 			continue
 		}
 
-		// Get the departure
+		// Get the first departure that isn't waiting for its EDCT.
 		pool := s.DeparturePool[airport]
-		dep := pool[0]
+		idx := slices.IndexFunc(pool, func(dep DepartureAircraft) bool {
+			return !s.State.Aircraft[dep.Callsign].HeldForEDCT(now)
+		})
+		if idx == -1 {
+			continue
+		}
+		dep := pool[idx]
 		ac := s.State.Aircraft[dep.Callsign]
 
 		// Request a release if necessary.
 		if ac.HoldForRelease && !dep.ReleaseRequested {
 			s.State.STARSComputer().AddHeldDeparture(ac)
-			pool[0].ReleaseRequested = true
+			pool[idx].ReleaseRequested = true
 		}
 
 		if !s.canLaunch(airport, dep) {
@@ -1810,11 +2079,11 @@ func (s *Sim) spawnDepartures() {
 		s.LastDeparture[airport][dep.Runway] = &dep
 
 		// Remove it from the pool of waiting departures.
-		s.DeparturePool[airport] = pool[1:]
+		s.DeparturePool[airport] = slices.Delete(pool, idx, idx+1)
 
 		// And figure out when we want to ask for the next departure.
 		r := sumRateMap2(s.LaunchConfig.DepartureRates[airport], s.LaunchConfig.DepartureRateScale)
-		s.NextDepartureLaunch[airport] = now.Add(randomWait(r, false))
+		s.NextDepartureLaunch[airport] = now.Add(randomWait(r, pushActive))
 	}
 }
 
@@ -1854,7 +2123,7 @@ func (s *Sim) launchInterval(prev, cur DepartureAircraft) time.Duration {
 	}
 
 	// Check for wake turbulence separation.
-	wtDist := av.CWTDirectlyBehindSeparation(pac.CWT(), cac.CWT())
+	wtDist := av.CWTDirectlyBehindSeparation(s.State.CWT(pac), s.State.CWT(cac))
 	if wtDist != 0 {
 		// Assume '1 gives you 3.5'
 		return time.Duration(wtDist / 3.5 * float32(time.Minute))
@@ -1868,12 +2137,16 @@ func (s *Sim) refreshDeparturePool() {
 loop:
 	for airport, rates := range s.LaunchConfig.DepartureRates {
 		pool := s.DeparturePool[airport]
-		// Keep a pool of 2-5 around.
-		if len(pool) >= 2 {
+		// Keep a pool of 2-5 around, not counting ones that are waiting
+		// for their EDCT.
+		n := len(util.FilterSlice(pool, func(dep DepartureAircraft) bool {
+			return !s.State.Aircraft[dep.Callsign].HeldForEDCT(s.SimTime)
+		}))
+		if n >= 2 {
 			continue
 		}
 
-		for len(pool) < 5 {
+		for ; n < 5; n++ {
 			// Figure out which category to generate.
 			runway, category, rateSum := sampleRateMap2(rates, s.LaunchConfig.DepartureRateScale)
 			if rateSum == 0 {
@@ -2009,10 +2282,17 @@ func (s *Sim) SetLaunchConfig(token string, lc LaunchConfig) error {
 			oldSum *= s.LaunchConfig.DepartureRateScale
 
 			if newSum != oldSum {
+				pushActive := s.SimTime.Before(s.DeparturePushEnd)
 				s.lg.Infof("%s: departure rate changed %f -> %f", ap, oldSum, newSum)
-				s.NextDepartureLaunch[ap] = s.SimTime.Add(randomWait(newSum, false))
+				s.NextDepartureLaunch[ap] = s.SimTime.Add(randomWait(newSum, pushActive))
 			}
 		}
+		if lc.DeparturePushes && !s.LaunchConfig.DeparturePushes {
+			m := 1 + rand.Intn(lc.DeparturePushFrequencyMinutes)
+			s.NextDeparturePushStart = s.SimTime.Add(time.Duration(m) * time.Minute)
+		} else if !lc.DeparturePushes {
+			s.NextDeparturePushStart, s.DeparturePushEnd = time.Time{}, time.Time{}
+		}
 		for group, groupRates := range lc.InboundFlowRates {
 			var newSum, oldSum float32
 			for ap, rate := range groupRates {
@@ -2075,6 +2355,8 @@ func (s *Sim) addAircraftNoLock(ac av.Aircraft) {
 		return
 	}
 
+	ac.Nav.Phraseology = s.State.Phraseology
+	ac.Nav.TransitionAltitude = s.State.TransitionAltitude
 	s.State.Aircraft[ac.Callsign] = &ac
 
 	ac.Nav.Check(s.lg)
@@ -2169,11 +2451,24 @@ func (s *Sim) GlobalMessage(global GlobalMessageArgs) error {
 	s.mu.Lock(s.lg)
 	defer s.mu.Unlock(s.lg)
 
-	s.eventStream.Post(Event{
+	e := Event{
 		Type:           GlobalMessageEvent,
 		Message:        global.Message,
 		FromController: global.FromController,
-	})
+	}
+	if global.Guard {
+		e.Type = GuardMessageEvent
+	}
+	if len(global.ToControllers) > 0 {
+		e.Type = LandlineMessageEvent
+		e.Landline = global.Landline
+		for _, ctrl := range global.ToControllers {
+			e.ToController = ctrl
+			s.eventStream.Post(e)
+		}
+		return nil
+	}
+	s.eventStream.Post(e)
 
 	return nil
 }
@@ -2828,25 +3123,133 @@ func (s *Sim) ReleaseDeparture(token, callsign string) error {
 		return ErrInvalidDepartureController
 	}
 
+	var expiration time.Time
+	if w := s.State.STARSFacilityAdaptation.ReleaseWindow(ac.FlightPlan.DepartureAirport); w > 0 {
+		expiration = s.State.SimTime.Add(w)
+	}
+
 	stars := s.State.STARSComputer()
-	if err := stars.ReleaseDeparture(callsign); err == nil {
+	if err := stars.ReleaseDeparture(callsign, expiration); err == nil {
 		ac.Released = true
+		ac.ReleaseExpiration = expiration
+		ac.ReleaseExpired = false
 		return nil
 	} else {
 		return err
 	}
 }
 
+// edctWarningTime is how long before the end of a departure's EDCT window
+// the departure controller is warned that it is at risk of missing it.
+const edctWarningTime = 2 * time.Minute
+
+// checkEDCTs warns departure controllers about departures that are still
+// on the ground as the end of their EDCT window approaches.
+func (s *Sim) checkEDCTs() {
+	for _, pool := range s.DeparturePool {
+		for _, dep := range pool {
+			ac := s.State.Aircraft[dep.Callsign]
+			if ac.EDCTWarned || !ac.EDCTAtRisk(s.SimTime, edctWarningTime) {
+				continue
+			}
+			ac.EDCTWarned = true
+			s.eventStream.Post(Event{
+				Type:         StatusMessageEvent,
+				Callsign:     ac.Callsign,
+				ToController: s.State.DepartureController(ac, s.lg),
+				Message:      ac.Callsign + " at risk of missing EDCT " + ac.EDCT.Format("1504"),
+			})
+		}
+	}
+}
+
+// SetEDCT assigns an EDCT to a departure that hasn't launched yet; a zero
+// time clears it.
+func (s *Sim) SetEDCT(token, callsign string, edct time.Time) error {
+	s.mu.Lock(s.lg)
+	defer s.mu.Unlock(s.lg)
+
+	sc, ok := s.controllers[token]
+	if !ok {
+		return ErrInvalidControllerToken
+	}
+
+	ac, ok := s.State.Aircraft[callsign]
+	if !ok {
+		return av.ErrNoAircraftForCallsign
+	}
+	if s.State.DepartureController(ac, s.lg) != sc.Callsign {
+		return ErrInvalidDepartureController
+	}
+	if !ac.WaitingForLaunch {
+		return ErrAircraftAlreadyDeparted
+	}
+
+	ac.EDCT = edct
+	ac.EDCTWarned = false
+	return nil
+}
+
+// expireUnusedReleases cancels the releases that have gone unused within
+// their window. All of the waiting departures are checked, not just the
+// ones that are next in line to launch, since a departure may be released
+// while others are ahead of it.
+func (s *Sim) expireUnusedReleases(now time.Time) {
+	for _, airport := range util.SortedMapKeys(s.DeparturePool) {
+		for _, dep := range s.DeparturePool[airport] {
+			ac, ok := s.State.Aircraft[dep.Callsign]
+			if ok && ac.HoldForRelease && ac.Released && !ac.ReleaseExpiration.IsZero() &&
+				now.After(ac.ReleaseExpiration) {
+				s.expireRelease(ac)
+			}
+		}
+	}
+}
+
+// expireRelease cancels the release of a departure that has gone unused
+// past its release window and lets the departure controller know.
+func (s *Sim) expireRelease(ac *av.Aircraft) {
+	ac.Released = false
+	ac.ReleaseExpiration = time.Time{}
+	ac.ReleaseExpired = true
+	s.State.STARSComputer().ExpireRelease(ac.Callsign)
+
+	s.eventStream.Post(Event{
+		Type:         StatusMessageEvent,
+		Callsign:     ac.Callsign,
+		ToController: s.State.DepartureController(ac, s.lg),
+		Message:      ac.Callsign + " release expired",
+	})
+}
+
 func (s *Sim) AssignAltitude(token, callsign string, altitude int, afterSpeed bool) error {
 	s.mu.Lock(s.lg)
 	defer s.mu.Unlock(s.lg)
 
 	return s.dispatchControllingCommand(token, callsign,
 		func(ctrl *av.Controller, ac *av.Aircraft) []av.RadioTransmission {
-			return ac.AssignAltitude(altitude, afterSpeed)
+			if ac.FlightPlan != nil {
+				s.postEquipmentWarnings(ctrl, ac.FlightPlan.CheckAssignedAltitude(altitude))
+			}
+			return s.assignWithReadback(ctrl, ac, "altitude", altitude, func(alt int) []av.RadioTransmission {
+				return ac.AssignAltitude(alt, afterSpeed)
+			})
 		})
 }
 
+// postEquipmentWarnings sends warnings about clearances that the
+// aircraft may not be equipped to comply with to the controller who
+// issued them.
+func (s *Sim) postEquipmentWarnings(ctrl *av.Controller, warnings []string) {
+	for _, w := range warnings {
+		s.eventStream.Post(Event{
+			Type:         StatusMessageEvent,
+			ToController: ctrl.Callsign,
+			Message:      w,
+		})
+	}
+}
+
 func (s *Sim) SetTemporaryAltitude(token, callsign string, altitude int) error {
 	s.mu.Lock(s.lg)
 	defer s.mu.Unlock(s.lg)
@@ -2858,6 +3261,28 @@ func (s *Sim) SetTemporaryAltitude(token, callsign string, altitude int) error {
 		})
 }
 
+func (s *Sim) SetTemporaryHeading(token, callsign string, heading int) error {
+	s.mu.Lock(s.lg)
+	defer s.mu.Unlock(s.lg)
+
+	return s.dispatchTrackingCommand(token, callsign,
+		func(ctrl *av.Controller, ac *av.Aircraft) []av.RadioTransmission {
+			ac.TempHeading = heading
+			return nil
+		})
+}
+
+func (s *Sim) SetTemporarySpeed(token, callsign string, speed int) error {
+	s.mu.Lock(s.lg)
+	defer s.mu.Unlock(s.lg)
+
+	return s.dispatchTrackingCommand(token, callsign,
+		func(ctrl *av.Controller, ac *av.Aircraft) []av.RadioTransmission {
+			ac.TempSpeed = speed
+			return nil
+		})
+}
+
 type HeadingArgs struct {
 	ControllerToken string
 	Callsign        string
@@ -2881,7 +3306,9 @@ func (s *Sim) AssignHeading(hdg *HeadingArgs) error {
 			} else if hdg.RightDegrees != 0 {
 				return ac.TurnRight(hdg.RightDegrees)
 			} else {
-				return ac.AssignHeading(hdg.Heading, hdg.Turn)
+				return s.assignWithReadback(ctrl, ac, "heading", hdg.Heading, func(heading int) []av.RadioTransmission {
+					return ac.AssignHeading(heading, hdg.Turn)
+				})
 			}
 		})
 }
@@ -2892,7 +3319,12 @@ func (s *Sim) AssignSpeed(token, callsign string, speed int, afterAltitude bool)
 
 	return s.dispatchControllingCommand(token, callsign,
 		func(ctrl *av.Controller, ac *av.Aircraft) []av.RadioTransmission {
-			return ac.AssignSpeed(speed, afterAltitude)
+			if speed == 0 {
+				return ac.AssignSpeed(speed, afterAltitude)
+			}
+			return s.assignWithReadback(ctrl, ac, "speed", speed, func(spd int) []av.RadioTransmission {
+				return ac.AssignSpeed(spd, afterAltitude)
+			})
 		})
 }
 
@@ -2976,6 +3408,21 @@ func (s *Sim) DirectFix(token, callsign, fix string) error {
 		})
 }
 
+func (s *Sim) DirectFixRejoin(token, callsign, fix, rejoin string) error {
+	s.mu.Lock(s.lg)
+	defer s.mu.Unlock(s.lg)
+
+	p, ok := s.State.Locate(fix)
+	if !ok {
+		return ErrInvalidCommandSyntax
+	}
+
+	return s.dispatchControllingCommand(token, callsign,
+		func(ctrl *av.Controller, ac *av.Aircraft) []av.RadioTransmission {
+			return ac.DirectFixRejoin(fix, p, rejoin)
+		})
+}
+
 func (s *Sim) DepartFixDirect(token, callsign, fixa string, fixb string) error {
 	s.mu.Lock(s.lg)
 	defer s.mu.Unlock(s.lg)
@@ -3040,11 +3487,18 @@ func (s *Sim) ClearedApproach(token, callsign, approach string, straightIn bool)
 
 	return s.dispatchControllingCommand(token, callsign,
 		func(ctrl *av.Controller, ac *av.Aircraft) []av.RadioTransmission {
+			var resp []av.RadioTransmission
 			if straightIn {
-				return ac.ClearedStraightInApproach(approach)
+				resp = ac.ClearedStraightInApproach(approach)
 			} else {
-				return ac.ClearedApproach(approach, s.lg)
+				resp = ac.ClearedApproach(approach, s.lg)
+			}
+
+			if ap := ac.Nav.Approach.Assigned; ac.Nav.Approach.Cleared && ap != nil &&
+				ap.Type == av.RNAVApproach && ac.FlightPlan != nil {
+				s.postEquipmentWarnings(ctrl, ac.FlightPlan.CheckRNAVProcedure(ap.FullName, true))
 			}
+			return resp
 		})
 }
 
@@ -3074,6 +3528,9 @@ func (s *Sim) ClimbViaSID(token, callsign string) error {
 
 	return s.dispatchControllingCommand(token, callsign,
 		func(ctrl *av.Controller, ac *av.Aircraft) []av.RadioTransmission {
+			if ac.SIDIsRNAV && ac.FlightPlan != nil {
+				s.postEquipmentWarnings(ctrl, ac.FlightPlan.CheckRNAVProcedure(ac.SID, false))
+			}
 			return ac.ClimbViaSID()
 		})
 }
@@ -0,0 +1,148 @@
+// pkg/sim/sim_test.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"testing"
+	"time"
+
+	av "github.com/mmp/vice/pkg/aviation"
+)
+
+func TestExpireUnusedReleases(t *testing.T) {
+	db := av.DB
+	defer func() { av.DB = db }()
+	av.DB = &av.StaticDatabase{TRACONs: map[string]av.TRACON{"PHL": {Name: "PHL", ARTCC: "ZNY"}}}
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	departure := func(callsign string, released bool, expiration time.Time) *av.Aircraft {
+		return &av.Aircraft{
+			Callsign:          callsign,
+			FlightPlan:        &av.FlightPlan{DepartureAirport: "KPHL"},
+			HoldForRelease:    true,
+			Released:          released,
+			ReleaseExpiration: expiration,
+		}
+	}
+	// The first departure in line hasn't been released; the ones behind
+	// it have been, but the second one's release has lapsed.
+	waiting := departure("AAL1", false, time.Time{})
+	lapsed := departure("AAL2", true, now.Add(-time.Minute))
+	current := departure("AAL3", true, now.Add(time.Minute))
+	unlimited := departure("AAL4", true, time.Time{})
+
+	stars := &STARSComputer{}
+	es := NewEventStream(nil)
+	sub := es.Subscribe()
+	s := &Sim{
+		State: &State{
+			TRACON:            "PHL",
+			PrimaryController: "PHL_APP",
+			Aircraft:          make(map[string]*av.Aircraft),
+			ERAMComputers: &ERAMComputers{Computers: map[string]*ERAMComputer{
+				"ZNY": {STARSComputers: map[string]*STARSComputer{"PHL": stars}},
+			}},
+		},
+		eventStream: es,
+	}
+	var pool []DepartureAircraft
+	for _, ac := range []*av.Aircraft{waiting, lapsed, current, unlimited} {
+		s.State.Aircraft[ac.Callsign] = ac
+		pool = append(pool, DepartureAircraft{Callsign: ac.Callsign, ReleaseRequested: true})
+		stars.AddHeldDeparture(ac)
+	}
+	s.DeparturePool = map[string][]DepartureAircraft{"KPHL": pool}
+
+	s.expireUnusedReleases(now)
+
+	if lapsed.Released || !lapsed.ReleaseExpired {
+		t.Errorf("release of AAL2 should have lapsed even though it isn't first in line")
+	}
+	for _, ac := range []*av.Aircraft{current, unlimited} {
+		if !ac.Released || ac.ReleaseExpired {
+			t.Errorf("release of %s shouldn't have lapsed", ac.Callsign)
+		}
+	}
+	if waiting.Released || waiting.ReleaseExpired {
+		t.Errorf("AAL1 wasn't released so its release can't lapse")
+	}
+
+	events := sub.Get()
+	if len(events) != 1 || events[0].Callsign != "AAL2" || events[0].ToController != "PHL_APP" {
+		t.Errorf("expected one release expired message for AAL2 to PHL_APP, got %+v", events)
+	}
+}
+
+func TestSelectPositionWithSplit(t *testing.T) {
+	splits := av.SplitConfigurationSet{
+		"combined": {"PHL_APP": {Primary: true}},
+		"north-south": {
+			"PHL_N": {Primary: true},
+			"PHL_S": {},
+		},
+	}
+	group := func(scenarios map[string]*SimScenarioConfiguration) map[string]*Configuration {
+		return map[string]*Configuration{"group": {ScenarioConfigs: scenarios, DefaultScenario: "a"}}
+	}
+	newConfig := func() *NewSimConfiguration {
+		tracon := "PHL"
+		return &NewSimConfiguration{
+			defaultTRACON: &tracon,
+			selectedServer: &Server{configs: map[string]map[string]*Configuration{
+				"ABE": group(map[string]*SimScenarioConfiguration{
+					"a": {SelectedController: "ABE_APP", SplitConfigurations: av.SplitConfigurationSet{
+						"north-south": {"ABE_N": {Primary: true}},
+					}},
+				}),
+				"PHL": group(map[string]*SimScenarioConfiguration{
+					"a": {SelectedController: "PHL_APP", SplitConfigurations: av.SplitConfigurationSet{
+						"combined": splits["combined"],
+					}},
+					"b": {SelectedController: "PHL_APP", SelectedSplit: "combined", SplitConfigurations: splits},
+				}),
+			}},
+		}
+	}
+
+	for _, test := range []struct {
+		position, split  string
+		tracon, scenario string
+		controller       string
+		err              bool
+	}{
+		{position: "PHL_APP", tracon: "PHL", scenario: "a", controller: "PHL_APP"},
+		// The split's primary position is used if none is given.
+		{split: "north-south", tracon: "PHL", scenario: "b", controller: "PHL_N"},
+		{position: "PHL_N", split: "north-south", tracon: "PHL", scenario: "b", controller: "PHL_N"},
+		{position: "ABE_N", split: "north-south", tracon: "ABE", scenario: "a", controller: "ABE_N"},
+		// The position must be the split's primary.
+		{position: "PHL_S", split: "north-south", err: true},
+		{split: "east-west", err: true},
+	} {
+		c := newConfig()
+		err := c.SelectPosition(test.position, test.split)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s/%s: expected an error", test.position, test.split)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("%s/%s: %v", test.position, test.split, err)
+			continue
+		}
+
+		if c.TRACONName != test.tracon || c.ScenarioName != test.scenario || c.NewSimType != NewSimCreateLocal {
+			t.Errorf("%s/%s: got %s/%s, expected %s/%s", test.position, test.split, c.TRACONName, c.ScenarioName,
+				test.tracon, test.scenario)
+		}
+		if c.Scenario.SelectedController != test.controller {
+			t.Errorf("%s/%s: got controller %s, expected %s", test.position, test.split,
+				c.Scenario.SelectedController, test.controller)
+		}
+		if test.split != "" && c.Scenario.SelectedSplit != test.split {
+			t.Errorf("%s/%s: got split %s", test.position, test.split, c.Scenario.SelectedSplit)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// pkg/sim/split.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"slices"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/util"
+)
+
+// The split configuration (the scenario's "multi_controllers") that
+// determines which positions are open and what each one is responsible
+// for can be changed mid-session, e.g. to split off a sector for a
+// controller who has just come online or to combine sectors when one
+// leaves. Handoff targets, the departure list, and the outside-airspace
+// warnings are all based on the current split, so they follow the
+// change.
+
+// ChangeSplit switches the sim to the given split. All of the positions
+// that human controllers are signed in to must be in it.
+func (s *Sim) ChangeSplit(token string, split string) error {
+	s.mu.Lock(s.lg)
+	defer s.mu.Unlock(s.lg)
+
+	ctrl, ok := s.controllers[token]
+	if !ok {
+		return ErrInvalidControllerToken
+	}
+
+	config, ok := s.State.SplitConfigurations[split]
+	if !ok {
+		return ErrUnknownSplit
+	}
+	for _, sc := range s.controllers {
+		if _, ok := config[sc.Callsign]; !ok {
+			return ErrSignedInPositionNotInSplit
+		}
+	}
+
+	prev := s.State.MultiControllers
+	if err := s.State.changeSplit(split); err != nil {
+		return err
+	}
+
+	// Positions that are new in this split become available to sign on
+	// to and ones that aren't in it no longer are.
+	for callsign := range s.SignOnPositions {
+		if _, ok := config[callsign]; !ok {
+			delete(s.SignOnPositions, callsign)
+		}
+	}
+	for callsign := range config {
+		if _, ok := s.SignOnPositions[callsign]; ok {
+			continue
+		}
+		if pos, ok := s.State.ControlPositions[callsign]; ok {
+			posCopy := *pos
+			posCopy.IsHuman = true
+			s.SignOnPositions[callsign] = &posCopy
+		}
+	}
+
+	// Aircraft that are headed to a position that isn't in the new split
+	// go to the one that has taken over its responsibilities instead.
+	for _, ac := range s.State.Aircraft {
+		if ac.DepartureContactController != "" && ac.FlightPlan != nil {
+			if _, ok := config[ac.DepartureContactController]; !ok {
+				pos, err := config.GetDepartureController(ac.FlightPlan.DepartureAirport, "", ac.SID)
+				if err != nil {
+					pos = remapPosition(ac.DepartureContactController, prev, config, s.State.PrimaryController)
+				}
+				ac.DepartureContactController = pos
+			}
+		}
+		ac.WaypointHandoffController = remapPosition(ac.WaypointHandoffController, prev, config,
+			s.State.PrimaryController)
+	}
+
+	s.lg.Infof("%s: changed split to %s", ctrl.Callsign, split)
+
+	s.eventStream.Post(Event{
+		Type:           SplitChangedEvent,
+		FromController: ctrl.Callsign,
+		Message:        split,
+	})
+	s.eventStream.Post(Event{
+		Type:    StatusMessageEvent,
+		Message: ctrl.Callsign + " changed the sector configuration to " + split + ".",
+	})
+
+	return nil
+}
+
+// changeSplit makes the given split the current one.
+func (ss *State) changeSplit(split string) error {
+	config, ok := ss.SplitConfigurations[split]
+	if !ok {
+		return ErrUnknownSplit
+	}
+	primary, err := ss.SplitConfigurations.GetPrimaryController(split)
+	if err != nil {
+		return err
+	}
+
+	ss.MultiControllers, ss.PrimaryController, ss.SelectedSplit = config, primary, split
+	return nil
+}
+
+// remapPosition returns the position in the new split, to, that takes
+// over the given position's responsibilities from the previous split,
+// from: the position itself if it is in both, otherwise one that covers any of its departures or inbound
+// flows, and otherwise the primary position. Positions that aren't in
+// from (e.g., virtual controllers) are returned unchanged.
+func remapPosition(position string, from, to av.SplitConfiguration, primary string) string {
+	old, ok := from[position]
+	if !ok {
+		return position
+	}
+	if _, ok := to[position]; ok {
+		return position
+	}
+
+	for _, callsign := range util.SortedMapKeys(to) {
+		ctrl := to[callsign]
+		if slices.ContainsFunc(old.InboundFlows, ctrl.IsInboundController) ||
+			slices.ContainsFunc(old.Departures, func(d string) bool { return slices.Contains(ctrl.Departures, d) }) {
+			return callsign
+		}
+	}
+	return primary
+}
+
+// ControllerAirspace returns the airspace volumes that the user owns in
+// the current split: those of their position and of the positions
+// consolidated into it. It returns nil if the split doesn't assign
+// airspace to positions.
+func (ss *State) ControllerAirspace() []ControllerAirspaceVolume {
+	var vols []ControllerAirspaceVolume
+	for _, pos := range ss.ConsolidatedPositions() {
+		if ctrl, ok := ss.MultiControllers[pos]; ok {
+			for _, as := range ctrl.Airspace {
+				vols = append(vols, ss.SplitAirspace[as]...)
+			}
+		}
+	}
+	return vols
+}
+
+// ControllerAirspaceNames returns the names of the airspace volumes
+// returned by ControllerAirspace.
+func (ss *State) ControllerAirspaceNames() []string {
+	var names []string
+	for _, pos := range ss.ConsolidatedPositions() {
+		if ctrl, ok := ss.MultiControllers[pos]; ok {
+			names = append(names, ctrl.Airspace...)
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
@@ -0,0 +1,96 @@
+// pkg/sim/split_test.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"testing"
+
+	av "github.com/mmp/vice/pkg/aviation"
+)
+
+// Two splits for testing: in the combined one, PHL_APP works everything;
+// in the split one, PHL_DEP has the departures and PHL_N and PHL_S each
+// have an inbound flow.
+var (
+	testCombinedSplit = av.SplitConfiguration{
+		"PHL_APP": {Primary: true, Departures: []string{"KPHL"}, InboundFlows: []string{"north", "south"}},
+	}
+	testSplit = av.SplitConfiguration{
+		"PHL_N":   {Primary: true, InboundFlows: []string{"north"}},
+		"PHL_S":   {InboundFlows: []string{"south"}},
+		"PHL_DEP": {Departures: []string{"KPHL"}},
+	}
+)
+
+func TestRemapPosition(t *testing.T) {
+	for _, test := range []struct {
+		position string
+		from, to av.SplitConfiguration
+		expected string
+	}{
+		// In both splits
+		{"PHL_N", testSplit, testSplit, "PHL_N"},
+		// Not in the previous split; e.g. a virtual controller
+		{"PHL_TWR", testSplit, testCombinedSplit, "PHL_TWR"},
+		// Combining: everything goes to the position that covers it
+		{"PHL_N", testSplit, testCombinedSplit, "PHL_APP"},
+		{"PHL_S", testSplit, testCombinedSplit, "PHL_APP"},
+		{"PHL_DEP", testSplit, testCombinedSplit, "PHL_APP"},
+		// Splitting: the first position (alphabetically) that takes over
+		// any of its responsibilities
+		{"PHL_APP", testCombinedSplit, testSplit, "PHL_DEP"},
+		// Nothing in the new split covers it, so the primary gets it
+		{"PHL_S", av.SplitConfiguration{"PHL_S": {}}, testSplit, "PHL_N"},
+	} {
+		if pos := remapPosition(test.position, test.from, test.to, "PHL_N"); pos != test.expected {
+			t.Errorf("%s: got %s, expected %s", test.position, pos, test.expected)
+		}
+	}
+}
+
+func TestChangeSplitSignOnPositions(t *testing.T) {
+	positions := make(map[string]*av.Controller)
+	signOn := make(map[string]*av.Controller)
+	for _, callsign := range []string{"PHL_APP", "PHL_N", "PHL_S", "PHL_DEP"} {
+		positions[callsign] = &av.Controller{Callsign: callsign}
+	}
+	signOn["PHL_APP"] = &av.Controller{Callsign: "PHL_APP", IsHuman: true}
+
+	s := &Sim{
+		State: &State{
+			ControlPositions: positions,
+			SplitConfigurations: av.SplitConfigurationSet{
+				"combined": testCombinedSplit,
+				"split":    testSplit,
+			},
+			MultiControllers: testCombinedSplit,
+			Aircraft:         make(map[string]*av.Aircraft),
+		},
+		SignOnPositions: signOn,
+		controllers:     map[string]*ServerController{"token": {Callsign: "PHL_APP"}},
+		eventStream:     NewEventStream(nil),
+	}
+
+	// PHL_APP is signed in but isn't in the split.
+	if err := s.ChangeSplit("token", "split"); err != ErrSignedInPositionNotInSplit {
+		t.Fatalf("expected ErrSignedInPositionNotInSplit, got %v", err)
+	}
+
+	s.controllers = map[string]*ServerController{"token": {Callsign: "PHL_N"}}
+	if err := s.ChangeSplit("token", "split"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.SignOnPositions["PHL_APP"]; ok {
+		t.Errorf("PHL_APP isn't in the split but can still be signed on to")
+	}
+	for callsign := range testSplit {
+		if ctrl, ok := s.SignOnPositions[callsign]; !ok || !ctrl.IsHuman {
+			t.Errorf("%s is in the split but can't be signed on to", callsign)
+		}
+	}
+	if s.State.PrimaryController != "PHL_N" || s.State.SelectedSplit != "split" {
+		t.Errorf("got primary %q and split %q", s.State.PrimaryController, s.State.SelectedSplit)
+	}
+}
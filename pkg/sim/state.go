@@ -28,6 +28,9 @@ type State struct {
 	Aircraft    map[string]*av.Aircraft
 	METAR       map[string]*av.METAR
 	Controllers map[string]*av.Controller
+	// All of the scenario group's control positions, whether or not
+	// they're currently signed in.
+	ControlPositions map[string]*av.Controller
 
 	DepartureAirports map[string]*av.Airport
 	ArrivalAirports   map[string]*av.Airport
@@ -63,10 +66,27 @@ type State struct {
 	TotalDepartures          int
 	TotalArrivals            int
 	TotalOverflights         int
+	ReadbackErrorsCaught     int
+	ReadbackErrorsMissed     int
+	BulletinURL              string
+	Phraseology              string
+	TransitionAltitude       int
 	STARSFacilityAdaptation  STARSFacilityAdaptation
 
 	ControllerVideoMaps        []av.VideoMap
 	ControllerDefaultVideoMaps []string
+
+	// All of the scenario's splits, so that the split can be changed
+	// mid-session, and the name of the one in use.
+	SplitConfigurations av.SplitConfigurationSet
+	SelectedSplit       string
+	// Areas where ADS-B isn't available.
+	ADSBOutages []av.AirspaceVolume
+
+	// Airspace volumes that the splits assign to positions, indexed by
+	// name.
+	SplitAirspace map[string][]ControllerAirspaceVolume
+
 	// Not sent to the client
 	videoMaps map[string]*av.VideoMap
 }
@@ -91,6 +111,16 @@ func newState(selectedSplit string, liveWeather bool, isLocal bool, s *Sim, sg *
 		if err != nil {
 			lg.Errorf("Unable to get multi controllers: %v", err)
 		}
+		ss.SplitConfigurations = sc.SplitConfigurations
+		ss.SelectedSplit = selectedSplit
+		ss.SplitAirspace = make(map[string][]ControllerAirspaceVolume)
+		for _, split := range sc.SplitConfigurations {
+			for _, ctrl := range split {
+				for _, as := range ctrl.Airspace {
+					ss.SplitAirspace[as] = sg.Airspace.Volumes[as]
+				}
+			}
+		}
 	} else {
 		ss.PrimaryController = sc.SoloController
 	}
@@ -101,6 +131,9 @@ func newState(selectedSplit string, liveWeather bool, isLocal bool, s *Sim, sg *
 	ss.Airports = sg.Airports
 	ss.Fixes = sg.Fixes
 	ss.PrimaryAirport = sg.PrimaryAirport
+	ss.BulletinURL = sg.BulletinURL
+	ss.Phraseology = util.Select(sc.Phraseology != "", sc.Phraseology, sg.Phraseology)
+	ss.TransitionAltitude = sg.TransitionAltitude
 	fa := sg.STARSFacilityAdaptation
 	ss.RadarSites = fa.RadarSites
 	ss.Center = util.Select(sc.Center.IsZero(), fa.Center, sc.Center)
@@ -111,6 +144,7 @@ func newState(selectedSplit string, liveWeather bool, isLocal bool, s *Sim, sg *
 	ss.ApproachAirspace = sc.ApproachAirspace
 	ss.DepartureAirspace = sc.DepartureAirspace
 	ss.DepartureRunways = sc.DepartureRunways
+	ss.ADSBOutages = sc.ADSBOutages
 	ss.ArrivalRunways = sc.ArrivalRunways
 	ss.LaunchConfig = s.LaunchConfig
 	ss.SimIsPaused = s.Paused
@@ -119,6 +153,7 @@ func newState(selectedSplit string, liveWeather bool, isLocal bool, s *Sim, sg *
 	ss.SimDescription = s.Scenario
 	ss.SimTime = s.SimTime
 	ss.STARSFacilityAdaptation = sg.STARSFacilityAdaptation
+	ss.ControlPositions = sg.ControlPositions
 	ss.videoMaps = ss.loadVideoMaps(ml, lg)
 
 	for _, callsign := range sc.VirtualControllers {
@@ -157,9 +192,12 @@ func newState(selectedSplit string, liveWeather bool, isLocal bool, s *Sim, sg *
 			wind += "KT"
 		}
 
-		// Just provide the stuff that the STARS display shows
+		// Just provide the stuff that the STARS display shows; the
+		// observation time is the most recent hourly report.
+		obs := ss.SimTime.UTC().Add(9 * time.Minute).Truncate(time.Hour).Add(-9 * time.Minute)
 		ss.METAR[icao] = &av.METAR{
 			AirportICAO: icao,
+			Time:        obs.Format("021504Z"),
 			Wind:        wind,
 			Altimeter:   fmt.Sprintf("A%d", alt-2+rand.Intn(4)),
 		}
@@ -194,6 +232,7 @@ func newState(selectedSplit string, liveWeather bool, isLocal bool, s *Sim, sg *
 		// Just provide the stuff that the STARS display shows
 		ss.METAR[icao] = &av.METAR{
 			AirportICAO: icao,
+			Time:        getObservationTime(weather.RawMETAR),
 			Wind:        wind,
 			Altimeter:   "A" + getAltimiter(weather.RawMETAR),
 		}
@@ -305,6 +344,17 @@ func getAltimiter(metar string) string {
 	return ""
 }
 
+func getObservationTime(metar string) string {
+	// The observation time follows the station identifier (and possibly
+	// a leading "METAR" or "SPECI").
+	for _, f := range strings.Fields(metar) {
+		if len(f) == 7 && f[6] == 'Z' && strings.Trim(f[:6], "0123456789") == "" {
+			return f
+		}
+	}
+	return ""
+}
+
 func (s *State) Activate(ml *av.VideoMapLibrary, lg *log.Logger) {
 	s.videoMaps = s.loadVideoMaps(ml, lg)
 	// Make the ERAMComputers aware of each other.
@@ -354,6 +404,27 @@ func (ss *State) AircraftFromPartialCallsign(c string) *av.Aircraft {
 	}
 }
 
+// AircraftFromSpokenCallsign returns the aircraft that a spoken
+// transmission was addressed to, given the candidate callsigns from
+// av.ParseSpokenTransmission. Unlike AircraftFromPartialCallsign, only
+// exact matches are considered, since acting on an aircraft that was
+// merely similar to a misheard callsign would be worse than not acting.
+func (ss *State) AircraftFromSpokenCallsign(callsigns []string) (*av.Aircraft, error) {
+	var match *av.Aircraft
+	for _, callsign := range callsigns {
+		if ac, ok := ss.Aircraft[callsign]; ok {
+			if match != nil {
+				return nil, ErrAmbiguousCallsign
+			}
+			match = ac
+		}
+	}
+	if match == nil {
+		return nil, av.ErrNoAircraftForCallsign
+	}
+	return match, nil
+}
+
 func (ss *State) DepartureController(ac *av.Aircraft, lg *log.Logger) string {
 	if len(ss.MultiControllers) > 0 {
 		callsign, err := ss.MultiControllers.ResolveController(ac.DepartureContactController,
@@ -371,6 +442,30 @@ func (ss *State) DepartureController(ac *av.Aircraft, lg *log.Logger) string {
 	}
 }
 
+// ResolveController returns the callsign of the controller currently
+// working the given position: the position itself if a virtual or signed
+// in human controller is working it or, otherwise, the signed in human
+// controller it is consolidated into. False is returned if no one is.
+func (ss *State) ResolveController(position string) (string, bool) {
+	if _, ok := ss.Controllers[position]; ok {
+		return position, true
+	}
+	if _, ok := ss.MultiControllers[position]; !ok {
+		return "", false
+	}
+
+	callsign, err := ss.MultiControllers.ResolveController(position,
+		func(callsign string) bool {
+			ctrl, ok := ss.Controllers[callsign]
+			return ok && ctrl.IsHuman
+		})
+	if err != nil || callsign == "" {
+		return "", false
+	}
+	_, ok := ss.Controllers[callsign]
+	return callsign, ok
+}
+
 func (ss *State) GetReleaseDepartures() []*av.Aircraft {
 	return util.FilterSlice(ss.STARSComputer().GetReleaseDepartures(),
 		func(ac *av.Aircraft) bool {
@@ -429,6 +524,41 @@ func (ss *State) InhibitCAVolumes() []av.AirspaceVolume {
 	return ss.STARSFacilityAdaptation.InhibitCAVolumes
 }
 
+func (ss *State) MIAs() []av.AirspaceVolume {
+	return ss.STARSFacilityAdaptation.MIAs
+}
+
+// ControlPosition returns the controller for the given position, whether
+// or not it is currently signed in.
+func (ss *State) ControlPosition(callsign string) (*av.Controller, bool) {
+	if ctrl, ok := ss.Controllers[callsign]; ok {
+		return ctrl, true
+	}
+	ctrl, ok := ss.ControlPositions[callsign]
+	return ctrl, ok
+}
+
+// CWT returns the aircraft's CWT category, taking into account any
+// overrides in the facility adaptation.
+func (ss *State) CWT(ac *av.Aircraft) string {
+	if ac.FlightPlan != nil {
+		if cwt, ok := ss.STARSFacilityAdaptation.CWTCategories[ac.FlightPlan.BaseType()]; ok {
+			return cwt
+		}
+	}
+	return ac.CWT()
+}
+
+// WakeCategory returns the wake category to display for the aircraft:
+// either its CWT category or, if the facility is so adapted, its legacy
+// weight class.
+func (ss *State) WakeCategory(ac *av.Aircraft) string {
+	if ss.STARSFacilityAdaptation.DisplayWeightClass {
+		return ac.WeightClass()
+	}
+	return ss.CWT(ac)
+}
+
 func (ss *State) AverageWindVector() [2]float32 {
 	d := math.OppositeHeading(float32(ss.Wind.Direction))
 	v := [2]float32{math.Sin(math.Radians(d)), math.Cos(math.Radians(d))}
@@ -0,0 +1,40 @@
+// pkg/sim/state_test.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"testing"
+
+	av "github.com/mmp/vice/pkg/aviation"
+)
+
+func TestAircraftFromSpokenCallsign(t *testing.T) {
+	ss := &State{Aircraft: map[string]*av.Aircraft{
+		"AAL123":  {Callsign: "AAL123"},
+		"AAL1234": {Callsign: "AAL1234"},
+		"BAW9A":   {Callsign: "BAW9A"},
+		"SHT9A":   {Callsign: "SHT9A"},
+	}}
+
+	for _, test := range []struct {
+		callsigns []string
+		callsign  string
+		err       error
+	}{
+		{[]string{"AAL123"}, "AAL123", nil},
+		{[]string{"BAW45", "SHT45", "AAL1234"}, "AAL1234", nil},
+		// A partial match must not be taken to be the aircraft.
+		{[]string{"AAL12"}, "", av.ErrNoAircraftForCallsign},
+		{[]string{"BAW9A", "SHT9A"}, "", ErrAmbiguousCallsign},
+	} {
+		ac, err := ss.AircraftFromSpokenCallsign(test.callsigns)
+		if err != test.err {
+			t.Errorf("%v: got error %v, expected %v", test.callsigns, err, test.err)
+		}
+		if (ac == nil && test.callsign != "") || (ac != nil && ac.Callsign != test.callsign) {
+			t.Errorf("%v: got aircraft %v, expected %q", test.callsigns, ac, test.callsign)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+// pkg/sim/trails.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/math"
+)
+
+// TrailRecorder records the complete track history of each aircraft
+// along with a timeline of what happened to it--its check-ins, the
+// instructions it acknowledged, handoffs, and so forth--so that
+// individual flights can be reviewed and exported for incident review or
+// pilot feedback. Like the Radio, it is client-side state.
+type TrailRecorder struct {
+	Trails     map[string]*AircraftTrail
+	lastSample time.Time
+}
+
+// How often (in sim time) aircraft positions are recorded.
+const trailSampleInterval = 5 * time.Second
+
+// Trails are kept for aircraft that have left the sim so that they can
+// still be reviewed, but only for trailRetention (in sim time) after the
+// last thing was recorded for them. Each trail only keeps what happened
+// in the most recent trailHistory.
+const (
+	trailRetention = time.Hour
+	trailHistory   = 4 * time.Hour
+)
+
+type AircraftTrail struct {
+	Callsign     string
+	AircraftType string
+	Points       []TrailPoint
+	// Events is the aircraft's timeline, in time order.
+	Events []TrailEvent
+}
+
+type TrailPoint struct {
+	Time        time.Time
+	Position    math.Point2LL
+	Altitude    int
+	Groundspeed int
+	Heading     int
+	// Controller assignments; zero if there is none.
+	AssignedAltitude int
+	AssignedHeading  int
+	AssignedSpeed    int
+	// Controller tracking the aircraft, if any.
+	Controller string
+}
+
+type TrailEventType string
+
+const (
+	TrailEventContact     TrailEventType = "contact"     // pilot-initiated transmission, e.g. check-in
+	TrailEventInstruction TrailEventType = "instruction" // pilot readback of an instruction
+	TrailEventUrgent      TrailEventType = "urgent"      // unexpected pilot transmission
+	TrailEventAltitude    TrailEventType = "altitude"    // change in the assigned altitude
+	TrailEventTrack       TrailEventType = "track"       // track initiated or dropped
+	TrailEventHandoff     TrailEventType = "handoff"
+	TrailEventPointOut    TrailEventType = "pointout"
+)
+
+// TrailEvent is an entry in an aircraft's timeline.
+type TrailEvent struct {
+	Time        time.Time
+	Type        TrailEventType
+	Controller  string
+	Description string
+}
+
+// trailEventForEvent returns the timeline entry for an event from the
+// event stream, if the event should be in the timeline.
+func trailEventForEvent(e Event) (TrailEvent, bool) {
+	te := TrailEvent{Controller: e.FromController, Description: e.Message}
+	switch e.Type {
+	case RadioTransmissionEvent:
+		te.Controller = e.ToController
+		switch e.RadioTransmissionType {
+		case av.RadioTransmissionContact:
+			te.Type = TrailEventContact
+		case av.RadioTransmissionReadback:
+			te.Type = TrailEventInstruction
+		default:
+			te.Type = TrailEventUrgent
+		}
+	case InitiatedTrackEvent:
+		te.Type, te.Controller, te.Description = TrailEventTrack, e.ToController, "track initiated"
+	case DroppedTrackEvent:
+		te.Type, te.Description = TrailEventTrack, "track dropped"
+	case OfferedHandoffEvent:
+		te.Type, te.Description = TrailEventHandoff, "handoff offered to "+e.ToController
+	case AcceptedHandoffEvent, AcceptedRedirectedHandoffEvent:
+		te.Type, te.Controller, te.Description = TrailEventHandoff, e.ToController, "handoff accepted from "+e.FromController
+	case PointOutEvent:
+		te.Type, te.Description = TrailEventPointOut, "pointed out to "+e.ToController
+	case AcknowledgedPointOutEvent:
+		te.Type, te.Description = TrailEventPointOut, "point out acknowledged"
+	case RejectedPointOutEvent:
+		te.Type, te.Description = TrailEventPointOut, "point out rejected"
+	default:
+		return TrailEvent{}, false
+	}
+	return te, true
+}
+
+// Update records the current state of all aircraft, if enough sim time has
+// passed since the last sample, and adds the given events to the
+// aircraft's timelines.
+func (tr *TrailRecorder) Update(ss *State, events []Event) {
+	if tr.Trails == nil {
+		tr.Trails = make(map[string]*AircraftTrail)
+	}
+
+	trail := func(callsign string) *AircraftTrail {
+		t, ok := tr.Trails[callsign]
+		if !ok {
+			t = &AircraftTrail{Callsign: callsign}
+			if ac, ok := ss.Aircraft[callsign]; ok && ac.FlightPlan != nil {
+				t.AircraftType = ac.FlightPlan.TypeWithoutSuffix()
+			}
+			tr.Trails[callsign] = t
+		}
+		return t
+	}
+
+	for _, e := range events {
+		if e.Callsign == "" {
+			continue
+		}
+		if te, ok := trailEventForEvent(e); ok {
+			te.Time = ss.SimTime
+			t := trail(e.Callsign)
+			t.Events = append(t.Events, te)
+		}
+	}
+
+	if ss.SimTime.Sub(tr.lastSample) < trailSampleInterval {
+		return
+	}
+	tr.lastSample = ss.SimTime
+
+	for callsign, ac := range ss.Aircraft {
+		pt := TrailPoint{
+			Time:        ss.SimTime,
+			Position:    ac.Position(),
+			Altitude:    int(ac.Altitude()),
+			Groundspeed: int(ac.GS()),
+			Heading:     int(ac.Heading() + 0.5),
+			Controller:  ac.TrackingController,
+		}
+		if ac.Nav.Altitude.Assigned != nil {
+			pt.AssignedAltitude = int(*ac.Nav.Altitude.Assigned)
+		}
+		if hdg, ok := ac.Nav.AssignedHeading(); ok {
+			pt.AssignedHeading = int(hdg + 0.5)
+		}
+		if ac.Nav.Speed.Assigned != nil {
+			pt.AssignedSpeed = int(*ac.Nav.Speed.Assigned)
+		}
+		t := trail(callsign)
+		if n := len(t.Points); pt.AssignedAltitude != 0 &&
+			(n == 0 || t.Points[n-1].AssignedAltitude != pt.AssignedAltitude) {
+			t.Events = append(t.Events, TrailEvent{
+				Time:        ss.SimTime,
+				Type:        TrailEventAltitude,
+				Controller:  pt.Controller,
+				Description: fmt.Sprintf("assigned %d", pt.AssignedAltitude),
+			})
+		}
+		t.Points = append(t.Points, pt)
+	}
+
+	for callsign, t := range tr.Trails {
+		if _, ok := ss.Aircraft[callsign]; !ok && ss.SimTime.Sub(t.lastRecorded()) > trailRetention {
+			delete(tr.Trails, callsign)
+		} else {
+			t.trim(ss.SimTime.Add(-trailHistory))
+		}
+	}
+}
+
+// lastRecorded returns the time of the most recent position sample or
+// event in the trail.
+func (t *AircraftTrail) lastRecorded() time.Time {
+	var last time.Time
+	if n := len(t.Points); n > 0 {
+		last = t.Points[n-1].Time
+	}
+	if n := len(t.Events); n > 0 && t.Events[n-1].Time.After(last) {
+		last = t.Events[n-1].Time
+	}
+	return last
+}
+
+// trim discards the position samples and events recorded before start.
+func (t *AircraftTrail) trim(start time.Time) {
+	t.Points = trimBefore(t.Points, start, func(pt TrailPoint) time.Time { return pt.Time })
+	t.Events = trimBefore(t.Events, start, func(e TrailEvent) time.Time { return e.Time })
+}
+
+// trimBefore removes the items from the time-ordered slice s that are
+// from before start.
+func trimBefore[T any](s []T, start time.Time, when func(T) time.Time) []T {
+	i := slices.IndexFunc(s, func(v T) bool { return !when(v).Before(start) })
+	if i == -1 {
+		return s[:0]
+	}
+	return slices.Delete(s, 0, i)
+}
+
+// Callsigns returns the callsigns of all of the aircraft that have a
+// recorded trail, sorted alphabetically.
+func (tr *TrailRecorder) Callsigns() []string {
+	var cs []string
+	for callsign, t := range tr.Trails {
+		if len(t.Points) > 0 {
+			cs = append(cs, callsign)
+		}
+	}
+	slices.Sort(cs)
+	return cs
+}
+
+// WriteCSV writes the trail as CSV with one row for each position
+// sample and one for each timeline event, in time order.
+func (t *AircraftTrail) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"time", "event", "latitude", "longitude", "altitude", "groundspeed", "heading",
+		"assigned_altitude", "assigned_heading", "assigned_speed", "controller", "description"})
+
+	itoa := func(v int) string {
+		if v == 0 {
+			return ""
+		}
+		return strconv.Itoa(v)
+	}
+	ftoa := func(v float32) string { return strconv.FormatFloat(float64(v), 'f', 6, 32) }
+
+	i := 0
+	writeEvents := func(before time.Time) {
+		for ; i < len(t.Events) && !t.Events[i].Time.After(before); i++ {
+			ev := t.Events[i]
+			cw.Write([]string{ev.Time.UTC().Format(time.RFC3339), string(ev.Type), "", "", "", "", "", "", "", "",
+				ev.Controller, ev.Description})
+		}
+	}
+	for _, pt := range t.Points {
+		writeEvents(pt.Time)
+		cw.Write([]string{pt.Time.UTC().Format(time.RFC3339), "position", ftoa(pt.Position.Latitude()),
+			ftoa(pt.Position.Longitude()), strconv.Itoa(pt.Altitude), strconv.Itoa(pt.Groundspeed),
+			strconv.Itoa(pt.Heading), itoa(pt.AssignedAltitude), itoa(pt.AssignedHeading),
+			itoa(pt.AssignedSpeed), pt.Controller, ""})
+	}
+	writeEvents(time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteKML writes the trail as KML with the flight path as a line with
+// absolute altitudes and the timeline events as placemarks at the
+// aircraft's position when each happened.
+func (t *AircraftTrail) WriteKML(w io.Writer) error {
+	const feetToMeters = 0.3048
+
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2">
+<Document>
+<name>%s</name>
+<Placemark>
+<name>%s %s</name>
+<LineString>
+<altitudeMode>absolute</altitudeMode>
+<coordinates>
+`, html.EscapeString(t.Callsign), html.EscapeString(t.Callsign), html.EscapeString(t.AircraftType))
+	for _, pt := range t.Points {
+		fmt.Fprintf(w, "%f,%f,%.0f\n", pt.Position.Longitude(), pt.Position.Latitude(),
+			float32(pt.Altitude)*feetToMeters)
+	}
+	fmt.Fprint(w, "</coordinates>\n</LineString>\n</Placemark>\n")
+
+	for _, ev := range t.Events {
+		// Use the first position sample at or after the event.
+		idx := slices.IndexFunc(t.Points, func(pt TrailPoint) bool { return !pt.Time.Before(ev.Time) })
+		if idx == -1 {
+			if len(t.Points) == 0 {
+				break
+			}
+			idx = len(t.Points) - 1
+		}
+		pt := t.Points[idx]
+		fmt.Fprintf(w, `<Placemark>
+<name>%s</name>
+<description>%s %s %s</description>
+<TimeStamp><when>%s</when></TimeStamp>
+<Point><altitudeMode>absolute</altitudeMode><coordinates>%f,%f,%.0f</coordinates></Point>
+</Placemark>
+`, html.EscapeString(ev.Description), ev.Type, html.EscapeString(ev.Controller), ev.Time.UTC().Format("15:04:05Z"),
+			ev.Time.UTC().Format(time.RFC3339), pt.Position.Longitude(), pt.Position.Latitude(),
+			float32(pt.Altitude)*feetToMeters)
+	}
+
+	_, err := fmt.Fprint(w, "</Document>\n</kml>\n")
+	return err
+}
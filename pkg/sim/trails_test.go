@@ -0,0 +1,67 @@
+// pkg/sim/trails_test.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package sim
+
+import (
+	"testing"
+	"time"
+
+	av "github.com/mmp/vice/pkg/aviation"
+)
+
+func TestTrailRecorderBounds(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	ss := &State{
+		SimTime: start,
+		Aircraft: map[string]*av.Aircraft{
+			"AAL1": {Callsign: "AAL1"},
+			"UAL2": {Callsign: "UAL2"},
+		},
+	}
+	readback := func(callsign string) []Event {
+		return []Event{{Type: RadioTransmissionEvent, Callsign: callsign, ToController: "2K",
+			RadioTransmissionType: av.RadioTransmissionReadback, Message: "climb and maintain 5000"}}
+	}
+
+	var tr TrailRecorder
+	tr.Update(ss, readback("AAL1"))
+	if len(tr.Trails) != 2 {
+		t.Fatalf("expected 2 trails, got %d", len(tr.Trails))
+	}
+
+	// UAL2 lands; its trail is kept for a while so that it can be
+	// reviewed.
+	delete(ss.Aircraft, "UAL2")
+	for ss.SimTime.Sub(start) < trailRetention {
+		ss.SimTime = ss.SimTime.Add(trailSampleInterval)
+		tr.Update(ss, nil)
+	}
+	if tr.Trails["UAL2"] == nil {
+		t.Errorf("UAL2 trail discarded too soon")
+	}
+
+	ss.SimTime = ss.SimTime.Add(2 * trailSampleInterval)
+	tr.Update(ss, nil)
+	if tr.Trails["UAL2"] != nil {
+		t.Errorf("UAL2 trail kept after it left the sim %s ago", ss.SimTime.Sub(start))
+	}
+
+	// AAL1's trail keeps only the most recent samples and events.
+	for ss.SimTime.Sub(start) <= trailHistory+time.Hour {
+		ss.SimTime = ss.SimTime.Add(trailSampleInterval)
+		tr.Update(ss, nil)
+	}
+	tr.Update(ss, readback("AAL1"))
+	trail := tr.Trails["AAL1"]
+	if n := int(trailHistory/trailSampleInterval) + 1; len(trail.Points) != n {
+		t.Errorf("expected %d points, got %d", n, len(trail.Points))
+	}
+	if oldest := trail.Points[0].Time; oldest.Before(ss.SimTime.Add(-trailHistory)) {
+		t.Errorf("oldest sample at %s is more than %s old", oldest, trailHistory)
+	}
+	if len(trail.Events) != 1 || !trail.Events[0].Time.Equal(ss.SimTime) {
+		t.Errorf("expected only the most recent event, got %+v", trail.Events)
+	}
+}
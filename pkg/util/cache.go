@@ -0,0 +1,302 @@
+// pkg/util/cache.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ResourceCache stores resources that have been downloaded--map tiles,
+// weather, TFRs, and scenario packages--on disk, so that they needn't be
+// downloaded again and so that the last good copy can be used when the
+// network is unavailable. Each resource is stored along with its SHA-256
+// checksum, which is verified when it is read back; when the total size of
+// the cache exceeds its limit, the least recently used resources are
+// evicted.
+type ResourceCache struct {
+	mu       sync.Mutex
+	dir      string
+	maxSize  int64
+	size     int64
+	entries  map[string]*cacheEntry
+	dirty    bool
+	lastSave time.Time
+	// lastErr records the most recent error from fetching a resource for
+	// which the cached copy was used instead.
+	lastErr error
+}
+
+type cacheEntry struct {
+	File     string
+	Size     int64
+	SHA256   string
+	Fetched  time.Time
+	LastUsed time.Time
+}
+
+// DefaultResourceCacheSize is the size limit used for the cache if
+// another one isn't set.
+const DefaultResourceCacheSize = 512 * 1024 * 1024
+
+const (
+	cacheIndexFile = "index.json"
+	// cacheSaveInterval limits how often the index is written after
+	// resources are added or used.
+	cacheSaveInterval = 10 * time.Second
+)
+
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+var resourceCache struct {
+	once  sync.Once
+	cache *ResourceCache
+}
+
+// GetResourceCache returns the cache for downloaded resources, which is
+// stored in the directory returned by CacheDir.
+func GetResourceCache() *ResourceCache {
+	resourceCache.once.Do(func() {
+		dir, err := CacheDir()
+		if err == nil {
+			dir = filepath.Join(dir, "resources")
+		}
+		resourceCache.cache = OpenResourceCache(dir, DefaultResourceCacheSize)
+	})
+	return resourceCache.cache
+}
+
+// OpenResourceCache returns a cache that stores resources in the given
+// directory, using the index left there by earlier sessions, if any. If
+// the directory can't be used, resources are never cached.
+func OpenResourceCache(dir string, maxSize int64) *ResourceCache {
+	c := &ResourceCache{maxSize: maxSize, entries: make(map[string]*cacheEntry)}
+	if dir == "" || os.MkdirAll(dir, 0o755) != nil {
+		return c
+	}
+	c.dir = dir
+
+	if b, err := os.ReadFile(filepath.Join(dir, cacheIndexFile)); err == nil {
+		var entries map[string]*cacheEntry
+		if json.Unmarshal(b, &entries) == nil {
+			for key, e := range entries {
+				// Skip entries whose files have gone missing.
+				if fi, err := os.Stat(filepath.Join(dir, e.File)); err == nil && fi.Size() == e.Size {
+					c.entries[key] = e
+					c.size += e.Size
+				}
+			}
+		}
+	}
+	return c
+}
+
+// Get returns the resource with the given key. The cached copy is used
+// if it was fetched less than maxAge ago or, if maxAge is negative, if
+// there is one at all; otherwise fetch is called to get the resource,
+// which is then cached. If fetch fails but there is a cached copy, it is
+// returned with stale set to true; an error is only returned if there is
+// no copy of the resource available.
+func (c *ResourceCache) Get(key string, maxAge time.Duration, fetch func() ([]byte, error)) (data []byte, stale bool, err error) {
+	c.mu.Lock()
+	cached, fetched := c.read(key)
+	if cached != nil && (maxAge < 0 || time.Since(fetched) < maxAge) {
+		c.mu.Unlock()
+		return cached, false, nil
+	}
+	c.mu.Unlock()
+
+	// Don't hold the lock while fetching, which may be slow.
+	data, err = fetch()
+	if err != nil {
+		if cached == nil {
+			return nil, false, err
+		}
+		c.mu.Lock()
+		c.lastErr = err
+		c.mu.Unlock()
+		return cached, true, nil
+	}
+
+	c.Put(key, data)
+	return data, false, nil
+}
+
+// read returns the cached copy of the resource with the given key and
+// when it was fetched, verifying its checksum. c.mu must be held.
+func (c *ResourceCache) read(key string) ([]byte, time.Time) {
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, time.Time{}
+	}
+
+	b, err := os.ReadFile(filepath.Join(c.dir, e.File))
+	if err == nil && checksum(b) != e.SHA256 {
+		err = ErrChecksumMismatch
+	}
+	if err != nil {
+		c.remove(key)
+		return nil, time.Time{}
+	}
+
+	e.LastUsed = time.Now()
+	c.dirty = true
+	c.maybeSave()
+	return b, e.Fetched
+}
+
+// Put adds the resource to the cache, replacing any existing copy.
+func (c *ResourceCache) Put(key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.dir == "" || int64(len(data)) > c.maxSize {
+		return
+	}
+
+	sum := checksum(data)
+	e := &cacheEntry{
+		File:     checksum([]byte(key)),
+		Size:     int64(len(data)),
+		SHA256:   sum,
+		Fetched:  time.Now(),
+		LastUsed: time.Now(),
+	}
+
+	// Write to a temporary file first so that an interrupted write
+	// doesn't leave a truncated file in place of a good one.
+	fn := filepath.Join(c.dir, e.File)
+	if os.WriteFile(fn+".tmp", data, 0o644) != nil || os.Rename(fn+".tmp", fn) != nil {
+		os.Remove(fn + ".tmp")
+		return
+	}
+
+	if old, ok := c.entries[key]; ok {
+		c.size -= old.Size
+	}
+	c.entries[key] = e
+	c.size += e.Size
+	c.dirty = true
+
+	c.evict()
+	c.maybeSave()
+}
+
+// evict removes the least recently used resources until the cache is
+// within its size limit. c.mu must be held.
+func (c *ResourceCache) evict() {
+	if c.size <= c.maxSize {
+		return
+	}
+
+	keys := SortedMapKeys(c.entries)
+	slices.SortStableFunc(keys, func(a, b string) int {
+		return c.entries[a].LastUsed.Compare(c.entries[b].LastUsed)
+	})
+	for _, key := range keys {
+		if c.size <= c.maxSize {
+			break
+		}
+		c.remove(key)
+	}
+}
+
+// remove removes the resource with the given key from the cache. c.mu
+// must be held.
+func (c *ResourceCache) remove(key string) {
+	if e, ok := c.entries[key]; ok {
+		os.Remove(filepath.Join(c.dir, e.File))
+		c.size -= e.Size
+		delete(c.entries, key)
+		c.dirty = true
+	}
+}
+
+func (c *ResourceCache) maybeSave() {
+	if time.Since(c.lastSave) > cacheSaveInterval {
+		c.save()
+	}
+}
+
+// save writes the index if it has changed. c.mu must be held.
+func (c *ResourceCache) save() error {
+	if !c.dirty || c.dir == "" {
+		return nil
+	}
+	b, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	fn := filepath.Join(c.dir, cacheIndexFile)
+	if err := os.WriteFile(fn+".tmp", b, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(fn+".tmp", fn); err != nil {
+		return err
+	}
+	c.dirty, c.lastSave = false, time.Now()
+	return nil
+}
+
+// Flush writes the cache's index; it should be called before exiting.
+func (c *ResourceCache) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.save()
+}
+
+// SetMaxSize sets the cache's size limit in bytes, evicting resources if
+// it is now over it.
+func (c *ResourceCache) SetMaxSize(n int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxSize = n
+	c.evict()
+}
+
+// Clear removes all of the resources from the cache.
+func (c *ResourceCache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		c.remove(key)
+	}
+	return c.save()
+}
+
+// ResourceCacheStats summarizes the contents of a ResourceCache.
+type ResourceCacheStats struct {
+	Dir     string
+	Count   int
+	Size    int64
+	MaxSize int64
+	// LastErr is the most recent error from fetching a resource for which
+	// the cached copy was used instead.
+	LastErr error
+}
+
+func (c *ResourceCache) Stats() ResourceCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ResourceCacheStats{
+		Dir:     c.dir,
+		Count:   len(c.entries),
+		Size:    c.size,
+		MaxSize: c.maxSize,
+		LastErr: c.lastErr,
+	}
+}
+
+func checksum(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
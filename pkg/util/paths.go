@@ -0,0 +1,103 @@
+// pkg/util/paths.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// In portable mode, all of the files that vice writes--its configuration,
+// the log, caches, and exported files--are stored in a directory next to
+// the executable rather than in the user's directories, so that a complete
+// setup can be carried on a USB stick or kept in a synced folder. Portable
+// mode is used if that directory exists.
+
+// PortableDirName is the name of the directory next to the executable
+// that holds vice's files in portable mode.
+const PortableDirName = "vice-data"
+
+// portableDir is the full path to the portable directory; it is empty if
+// portable mode isn't being used.
+var portableDir string
+
+// InitPortableMode checks whether vice should run in portable mode. If
+// create is true, the portable directory is created if it doesn't exist.
+// It must be called before any of the following functions.
+func InitPortableMode(create bool) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return err
+	}
+
+	dir := filepath.Join(filepath.Dir(exe), PortableDirName)
+	if create {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return err
+		}
+	}
+	if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
+		portableDir = dir
+	}
+	return nil
+}
+
+// PortableDir returns the portable directory, or the empty string if
+// portable mode isn't being used.
+func PortableDir() string {
+	return portableDir
+}
+
+// ConfigDir returns the directory that holds vice's configuration file and
+// log.
+func ConfigDir() (string, error) {
+	if portableDir != "" {
+		return portableDir, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "Vice"), nil
+}
+
+// CacheDir returns the directory where vice caches data between sessions.
+func CacheDir() (string, error) {
+	if portableDir != "" {
+		return filepath.Join(portableDir, "cache"), nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "Vice"), nil
+}
+
+// ExportDir returns the directory where files that vice creates for the
+// user, such as screen captures and exported data, are written.
+func ExportDir() string {
+	if portableDir != "" {
+		return portableDir
+	}
+	if dir, err := os.UserHomeDir(); err == nil {
+		return dir
+	}
+	return "."
+}
+
+// ResolvePortablePath returns the path to use for a file that the user
+// has specified. In portable mode, relative paths are taken to be with
+// respect to the portable directory, so that they don't depend on the
+// directory vice is launched from.
+func ResolvePortablePath(path string) string {
+	if portableDir == "" || path == "" || filepath.IsAbs(path) || strings.Contains(path, "://") {
+		return path
+	}
+	return filepath.Join(portableDir, path)
+}
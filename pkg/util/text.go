@@ -138,3 +138,33 @@ func CommaKeyExpand[T any](in map[string]T) map[string]T {
 	}
 	return m
 }
+
+// FuzzyMatch reports whether all of the characters of pattern appear in s
+// in order, ignoring case, as when searching for something by typing a
+// few of the letters of its name. If so, it also returns a score that is
+// higher for better matches: those where the characters are consecutive
+// or at the start of words in s.
+func FuzzyMatch(pattern, s string) (int, bool) {
+	pattern, s = strings.ToLower(pattern), strings.ToLower(s)
+	pr, sr := []rune(pattern), []rune(s)
+	score, prev, j := 0, -2, 0
+	for i := 0; i < len(sr) && j < len(pr); i++ {
+		if sr[i] != pr[j] {
+			continue
+		}
+		score++
+		if i == prev+1 {
+			score += 2
+		}
+		if i == 0 || !unicode.IsLetter(sr[i-1]) && !unicode.IsDigit(sr[i-1]) {
+			score += 3
+		}
+		prev = i
+		j++
+	}
+	if j < len(pr) {
+		return 0, false
+	}
+	// Prefer shorter strings when the matches are otherwise equal.
+	return 4*score - len(sr), true
+}
@@ -5,7 +5,10 @@
 package util
 
 import (
+	"errors"
 	"maps"
+	"os"
+	"path/filepath"
 	"slices"
 	"testing"
 	"time"
@@ -308,3 +311,109 @@ func TestAllPermutations(t *testing.T) {
 		}
 	}
 }
+
+func TestResolvePortablePath(t *testing.T) {
+	defer func(dir string) { portableDir = dir }(portableDir)
+
+	portableDir = ""
+	if p := ResolvePortablePath("scenario.json"); p != "scenario.json" {
+		t.Errorf("without portable mode, got %q", p)
+	}
+
+	portableDir = filepath.Join(t.TempDir(), PortableDirName)
+	abs := filepath.Join(t.TempDir(), "scenario.json")
+	for _, c := range []struct{ path, expected string }{
+		{"", ""},
+		{"scenario.json", filepath.Join(portableDir, "scenario.json")},
+		{filepath.Join("maps", "zny.gob"), filepath.Join(portableDir, "maps", "zny.gob")},
+		{abs, abs},
+		{"https://example.com/scenario.zip", "https://example.com/scenario.zip"},
+	} {
+		if p := ResolvePortablePath(c.path); p != c.expected {
+			t.Errorf("ResolvePortablePath(%q) = %q; expected %q", c.path, p, c.expected)
+		}
+	}
+}
+
+func TestResourceCache(t *testing.T) {
+	dir := t.TempDir()
+	c := OpenResourceCache(dir, 10)
+
+	fetches := 0
+	fetch := func(s string) func() ([]byte, error) {
+		return func() ([]byte, error) {
+			fetches++
+			return []byte(s), nil
+		}
+	}
+	fail := func() ([]byte, error) { return nil, errors.New("offline") }
+
+	if b, stale, err := c.Get("a", -1, fetch("aaaa")); err != nil || stale || string(b) != "aaaa" {
+		t.Fatalf("Get(a) = %q, %v, %v", b, stale, err)
+	}
+	// Cached copies that don't expire shouldn't be fetched again.
+	if b, _, _ := c.Get("a", -1, fetch("xxxx")); string(b) != "aaaa" || fetches != 1 {
+		t.Errorf("Get(a) = %q after %d fetches", b, fetches)
+	}
+	// Expired copies are used when fetching fails.
+	if b, stale, err := c.Get("a", 0, fail); err != nil || !stale || string(b) != "aaaa" {
+		t.Errorf("offline Get(a) = %q, %v, %v", b, stale, err)
+	}
+	if _, _, err := c.Get("b", 0, fail); err == nil {
+		t.Errorf("expected error for uncached resource")
+	}
+
+	// The index should be picked up by a new cache using the directory.
+	c.Flush()
+	c = OpenResourceCache(dir, 10)
+	if b, _, _ := c.Get("a", -1, fetch("xxxx")); string(b) != "aaaa" {
+		t.Errorf("reopened Get(a) = %q", b)
+	}
+
+	// a is now older than b, so it should be evicted first.
+	c.Get("b", -1, fetch("bbbb"))
+	c.Get("c", -1, fetch("cccc"))
+	if s := c.Stats(); s.Count != 2 || s.Size != 8 {
+		t.Errorf("expected 2 resources totaling 8 bytes; got %+v", s)
+	}
+	if b, _, _ := c.Get("a", -1, fetch("AAAA")); string(b) != "AAAA" {
+		t.Errorf("expected a to have been evicted; got %q", b)
+	}
+
+	// Corrupted files shouldn't be returned.
+	os.WriteFile(filepath.Join(dir, checksum([]byte("a"))), []byte("aaab"), 0o644)
+	if _, _, err := c.Get("a", 0, fail); err == nil {
+		t.Errorf("expected error for corrupted resource")
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	for _, c := range []struct {
+		pattern, s string
+		match      bool
+	}{
+		{"", "STARS", true},
+		{"stars", "STARS", true},
+		{"fs", "Flight Strips", true},
+		{"msg", "Messages", true},
+		{"sf", "Flight Strips", false},
+		{"starsx", "STARS", false},
+	} {
+		if _, ok := FuzzyMatch(c.pattern, c.s); ok != c.match {
+			t.Errorf("FuzzyMatch(%q, %q) = %v; expected %v", c.pattern, c.s, ok, c.match)
+		}
+	}
+
+	// Matches at word starts and of consecutive characters should score
+	// higher.
+	for _, c := range []struct{ pattern, better, worse string }{
+		{"fs", "Flight Strips", "Fileserver"},
+		{"mess", "Messages", "Map Edit Scope Settings"},
+	} {
+		b, _ := FuzzyMatch(c.pattern, c.better)
+		w, _ := FuzzyMatch(c.pattern, c.worse)
+		if b <= w {
+			t.Errorf("%q: expected %q (%d) to score higher than %q (%d)", c.pattern, c.better, b, c.worse, w)
+		}
+	}
+}
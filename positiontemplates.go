@@ -0,0 +1,115 @@
+// positiontemplates.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mmp/vice/pkg/log"
+	"github.com/mmp/vice/pkg/panes"
+	"github.com/mmp/vice/pkg/panes/stars"
+	"github.com/mmp/vice/pkg/platform"
+	"github.com/mmp/vice/pkg/renderer"
+	"github.com/mmp/vice/pkg/sim"
+)
+
+// Facilities can provide a template for each position in its
+// "controller_configs" that gives a layout, the STARS lists to show, and
+// frequencies to monitor. Users apply the template from the layouts menu
+// and are then free to personalize the result. The version of the
+// template that was applied is recorded so that when the facility
+// updates the template, the user can be offered the update.
+
+// positionTemplate returns the facility's template for the user's
+// position, if there is one.
+func positionTemplate(c *sim.ControlClient) *sim.PositionTemplate {
+	if c == nil {
+		return nil
+	}
+	if cc, ok := c.State.STARSFacilityAdaptation.ControllerConfigs[c.Callsign]; ok {
+		return cc.Template
+	}
+	return nil
+}
+
+func positionTemplateKey(c *sim.ControlClient) string {
+	return c.State.TRACON + "/" + c.Callsign
+}
+
+// ApplyPositionTemplate sets up the layout, STARS lists, and monitored
+// frequencies as given by the template.
+func (c *Config) ApplyPositionTemplate(t *sim.PositionTemplate, controlClient *sim.ControlClient, r renderer.Renderer,
+	p platform.Platform, eventStream *sim.EventStream, lg *log.Logger) error {
+	if t.Layout != "" {
+		var template func(func(panes.Pane) panes.Pane) *panes.DisplayNode
+		for _, lt := range layoutTemplates {
+			if strings.EqualFold(lt.Name, t.Layout) {
+				template = lt.Make
+			}
+		}
+		if template == nil {
+			return fmt.Errorf("%s: unknown layout in position template", t.Layout)
+		}
+		c.NewLayoutFromTemplate(template, controlClient, r, p, eventStream, lg)
+	}
+
+	var err error
+	c.DisplayRoot.VisitPanes(func(pane panes.Pane) {
+		if sp, ok := pane.(*stars.STARSPane); ok {
+			if unknown := sp.ApplyPositionTemplate(controlClient.State, t); len(unknown) > 0 {
+				err = fmt.Errorf("unknown STARS lists in position template: %s", strings.Join(unknown, ", "))
+			}
+		}
+	})
+
+	for _, pos := range t.Monitor {
+		controlClient.Radio.Monitor(&controlClient.State, pos)
+	}
+
+	if c.AppliedPositionTemplates == nil {
+		c.AppliedPositionTemplates = make(map[string]int)
+	}
+	c.AppliedPositionTemplates[positionTemplateKey(controlClient)] = t.Version
+
+	return err
+}
+
+// uiApplyPositionTemplate applies the template for the user's position,
+// first saving the current layout so that the user can go back to it.
+func uiApplyPositionTemplate(t *sim.PositionTemplate, config *Config, controlClient *sim.ControlClient,
+	r renderer.Renderer, p platform.Platform, eventStream *sim.EventStream, lg *log.Logger) {
+	name := controlClient.Callsign + " before template"
+	if err := config.SaveLayoutSnapshot(name); err != nil {
+		lg.Errorf("%s: unable to save layout: %v", name, err)
+	}
+	if err := config.ApplyPositionTemplate(t, controlClient, r, p, eventStream, lg); err != nil {
+		ShowErrorDialog(p, lg, "%v", err)
+	}
+}
+
+// uiCheckPositionTemplate offers to apply an updated template if the user
+// has applied an earlier version of the one for their position.
+func uiCheckPositionTemplate(config *Config, controlClient *sim.ControlClient, r renderer.Renderer,
+	p platform.Platform, eventStream *sim.EventStream, lg *log.Logger) {
+	t := positionTemplate(controlClient)
+	if t == nil {
+		return
+	}
+	key := positionTemplateKey(controlClient)
+	if applied, ok := config.AppliedPositionTemplates[key]; !ok || applied >= t.Version {
+		return
+	}
+
+	uiShowModalDialog(NewModalDialogBox(&YesOrNoModalClient{
+		title: "Position Template Updated",
+		query: fmt.Sprintf("The facility has updated its template for %s since you applied it.\n"+
+			"Apply the update? Your current layout will be saved as \"%s before template\".",
+			controlClient.Callsign, controlClient.Callsign),
+		ok: func() { uiApplyPositionTemplate(t, config, controlClient, r, p, eventStream, lg) },
+		// Don't ask again about this version.
+		notok: func() { config.AppliedPositionTemplates[key] = t.Version },
+	}, p), false)
+}
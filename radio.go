@@ -0,0 +1,173 @@
+// radio.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mmp/vice/pkg/renderer"
+	"github.com/mmp/vice/pkg/sim"
+	"github.com/mmp/vice/pkg/util"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// How long the indicator for the frequency that traffic most recently
+// came in on is highlighted.
+const radioReceiveHighlight = 5 * time.Second
+
+// uiDrawRadioWindow draws the compact frequency widget, which shows the
+// frequencies the controller is working and monitoring, which one is
+// primed for transmit, and which one traffic last came in on.
+func uiDrawRadioWindow(c *sim.ControlClient, config *Config) {
+	c.Radio.MonitorGuard = config.MonitorGuard
+	if config.HideRadioWindow {
+		return
+	}
+
+	show := true
+	flags := imgui.WindowFlagsAlwaysAutoResize | imgui.WindowFlagsNoFocusOnAppearing |
+		imgui.WindowFlagsNoCollapse
+	imgui.BeginV("Radio", &show, flags)
+	config.HideRadioWindow = !show
+
+	radio := &c.Radio
+	if radio.NumCoupled() >= 2 {
+		imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{1, 0.7, 0.2, 1})
+		imgui.Text(renderer.FontAwesomeIconLink + " Frequencies cross-coupled")
+		imgui.PopStyleColor()
+	}
+
+	owned := 0
+	for _, rf := range radio.Frequencies {
+		if rf.Owned {
+			owned++
+		}
+	}
+
+	tableFlags := imgui.TableFlagsSizingFixedFit | imgui.TableFlagsRowBg
+	if imgui.BeginTableV("radio", 6, tableFlags, imgui.Vec2{}, 0) {
+		for _, rf := range radio.Frequencies {
+			imgui.PushID(rf.Controller)
+			imgui.TableNextRow()
+
+			// Received traffic indicator
+			imgui.TableNextColumn()
+			if rf.Frequency == radio.LastReceived {
+				if time.Since(radio.LastReceivedTime) < radioReceiveHighlight {
+					imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{0.2, 1, 0.2, 1})
+					imgui.Text(renderer.FontAwesomeIconCaretRight)
+					imgui.PopStyleColor()
+				} else {
+					imgui.Text(renderer.FontAwesomeIconCaretRight)
+				}
+				if imgui.IsItemHovered() {
+					imgui.SetTooltip("Last received at " + radio.LastReceivedTime.Format("15:04:05"))
+				}
+			}
+
+			// Clicking the frequency primes it.
+			imgui.TableNextColumn()
+			uiStartDisable(!rf.Owned)
+			primed := rf.Frequency == radio.Primed
+			if imgui.SelectableV(rf.Frequency.String(), primed, imgui.SelectableFlagsDontClosePopups, imgui.Vec2{}) {
+				radio.Prime(rf.Frequency)
+			}
+			uiEndDisable(!rf.Owned)
+
+			imgui.TableNextColumn()
+			imgui.Text(rf.Controller)
+			if rf.Coupled && radio.NumCoupled() >= 2 {
+				imgui.SameLine()
+				imgui.Text(renderer.FontAwesomeIconLink)
+			}
+
+			imgui.TableNextColumn()
+			imgui.Checkbox("RX", &rf.Receive)
+			if primed {
+				// Can't transmit on a frequency without listening to it.
+				rf.Receive = true
+			}
+
+			// Cross-coupling is only possible when working multiple
+			// positions.
+			imgui.TableNextColumn()
+			if rf.Owned && owned > 1 {
+				coupled := rf.Coupled
+				if imgui.Checkbox("XC", &coupled) {
+					radio.Couple(rf.Frequency, coupled)
+				}
+				if imgui.IsItemHovered() {
+					imgui.SetTooltip("Cross-couple: retransmit traffic received on this frequency on the other coupled frequencies")
+				}
+			}
+
+			imgui.TableNextColumn()
+			if primed {
+				imgui.Text("TX")
+			} else if !rf.Owned && imgui.Button(renderer.FontAwesomeIconTrash) {
+				radio.Remove(rf.Frequency)
+			}
+
+			imgui.PopID()
+		}
+		imgui.EndTable()
+	}
+
+	uiDrawGuard(c, config)
+
+	// Offer to monitor the frequencies of other positions.
+	imgui.SetNextItemWidth(150)
+	if imgui.BeginComboV("##monitor", "Monitor...", imgui.ComboFlagsHeightLarge) {
+		for _, callsign := range util.SortedMapKeys(c.Controllers) {
+			if radio.Tuned(callsign) {
+				continue
+			}
+			ctrl := c.Controllers[callsign]
+			if imgui.Selectable(ctrl.Frequency.String() + " " + callsign) {
+				radio.Monitor(&c.State, callsign)
+			}
+		}
+		imgui.EndCombo()
+	}
+
+	imgui.End()
+}
+
+// uiDrawGuard draws the controls for monitoring and transmitting on the
+// guard frequency.
+func uiDrawGuard(c *sim.ControlClient, config *Config) {
+	radio := &c.Radio
+
+	imgui.Separator()
+	if radio.LastReceived == sim.GuardFrequency && time.Since(radio.LastReceivedTime) < radioReceiveHighlight {
+		imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{1, 0.3, 0.3, 1})
+		imgui.Text(renderer.FontAwesomeIconCaretRight)
+		imgui.PopStyleColor()
+		imgui.SameLine()
+	}
+	imgui.Checkbox("Monitor guard ("+sim.GuardFrequency.String()+")", &config.MonitorGuard)
+	radio.MonitorGuard = config.MonitorGuard
+	if !config.MonitorGuard {
+		return
+	}
+
+	imgui.SetNextItemWidth(200)
+	send := imgui.InputTextV("##guard", &ui.guardMessage, imgui.InputTextFlagsEnterReturnsTrue, nil)
+	imgui.SameLine()
+	uiStartDisable(ui.guardMessage == "")
+	send = imgui.Button("Transmit on guard") || send
+	uiEndDisable(ui.guardMessage == "")
+
+	if msg := strings.TrimSpace(ui.guardMessage); send && msg != "" {
+		c.SendGlobalMessage(sim.GlobalMessage{
+			FromController: c.Callsign,
+			Message:        c.Callsign + ": " + msg,
+			Guard:          true,
+		})
+		ui.guardMessage = ""
+	}
+}
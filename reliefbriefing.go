@@ -0,0 +1,67 @@
+// reliefbriefing.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"strings"
+
+	"github.com/mmp/vice/pkg/platform"
+	"github.com/mmp/vice/pkg/sim"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// ReliefBriefingWindow generates a position relief briefing from the
+// current session state and the controller's notes; the briefing can
+// then be copied to the clipboard or sent to the other controllers.
+type ReliefBriefingWindow struct {
+	controlClient *sim.ControlClient
+	notes         string
+	briefing      string
+	sent          bool
+}
+
+func MakeReliefBriefingWindow(controlClient *sim.ControlClient) *ReliefBriefingWindow {
+	rb := &ReliefBriefingWindow{controlClient: controlClient}
+	rb.briefing = controlClient.ReliefBriefing(rb.notes)
+	return rb
+}
+
+func (rb *ReliefBriefingWindow) Draw(p platform.Platform) (show bool) {
+	show = true
+	imgui.SetNextWindowSizeV(imgui.Vec2{500, 600}, imgui.ConditionFirstUseEver)
+	imgui.BeginV("Relief Briefing", &show, 0)
+
+	imgui.Text("Notes (holds, non-standard operations, coordination, ...)")
+	imgui.InputTextMultilineV("##notes", &rb.notes, imgui.Vec2{-1, 100}, 0, nil)
+
+	if imgui.Button("Update briefing") {
+		rb.briefing = rb.controlClient.ReliefBriefing(rb.notes)
+		rb.sent = false
+	}
+	imgui.SameLine()
+	if imgui.Button("Copy to clipboard") {
+		p.GetClipboard().SetText(rb.briefing)
+	}
+	imgui.SameLine()
+	uiStartDisable(rb.sent)
+	if imgui.Button("Send to other controllers") {
+		// Messages are displayed a line at a time.
+		for _, line := range strings.Split(strings.TrimSpace(rb.briefing), "\n") {
+			rb.controlClient.SendGlobalMessage(sim.GlobalMessage{
+				FromController: rb.controlClient.Callsign,
+				Message:        rb.controlClient.Callsign + ": " + line,
+			})
+		}
+		rb.sent = true
+	}
+	uiEndDisable(rb.sent)
+
+	imgui.Separator()
+	imgui.InputTextMultilineV("##briefing", &rb.briefing, imgui.Vec2{-1, -1}, imgui.InputTextFlagsReadOnly, nil)
+
+	imgui.End()
+	return
+}
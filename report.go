@@ -0,0 +1,255 @@
+// report.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/mmp/vice/pkg/log"
+	"github.com/mmp/vice/pkg/platform"
+	"github.com/mmp/vice/pkg/renderer"
+	"github.com/mmp/vice/pkg/sim"
+	"github.com/mmp/vice/pkg/util"
+
+	"github.com/mmp/imgui-go/v4"
+	"github.com/pkg/browser"
+)
+
+const (
+	// reportLogBytes is the amount of the end of the log file that is
+	// included in a problem report.
+	reportLogBytes = 2 * 1024 * 1024
+	// reportTrailDuration is how far back aircraft trails are included.
+	reportTrailDuration = 10 * time.Minute
+)
+
+// Steps in creating a report: the report window is hidden for a frame so
+// that it isn't in the screenshot, which is then taken after that frame
+// has been rendered.
+const (
+	reportIdle = iota
+	reportHide
+	reportCapture
+)
+
+// ReportWindow collects a description of a problem from the user and
+// bundles it into a zip file along with a screenshot, the end of the log
+// file, the user's configuration with identifying information removed,
+// and the recent aircraft trails, so that it can be attached to a bug
+// report.
+type ReportWindow struct {
+	controlClient *sim.ControlClient
+
+	description   string
+	screenshot    bool
+	logs          bool
+	config        bool
+	trails        bool
+	openIssuePage bool
+	state         int
+	status        string
+}
+
+func MakeReportWindow(controlClient *sim.ControlClient) *ReportWindow {
+	return &ReportWindow{
+		controlClient: controlClient,
+		screenshot:    true,
+		logs:          true,
+		config:        true,
+		trails:        controlClient != nil,
+	}
+}
+
+func (rw *ReportWindow) Draw() (show bool) {
+	show = true
+	if rw.state != reportIdle {
+		rw.state = reportCapture
+		return
+	}
+
+	imgui.BeginV("Report a Problem", &show, imgui.WindowFlagsAlwaysAutoResize)
+
+	imgui.Text("Describe what happened and what you expected to happen:")
+	imgui.InputTextMultilineV("##description", &rw.description, imgui.Vec2{X: 500, Y: 150}, 0, nil)
+
+	imgui.Checkbox("Include a screenshot", &rw.screenshot)
+	imgui.Checkbox("Include recent logs", &rw.logs)
+	imgui.Checkbox("Include configuration (identifying information is removed)", &rw.config)
+	uiStartDisable(rw.controlClient == nil)
+	imgui.Checkbox(fmt.Sprintf("Include aircraft tracks from the last %d minutes", int(reportTrailDuration.Minutes())),
+		&rw.trails)
+	uiEndDisable(rw.controlClient == nil)
+
+	imgui.Separator()
+	if imgui.Button("Save report") {
+		rw.state, rw.openIssuePage = reportHide, false
+	}
+	imgui.SameLine()
+	if imgui.Button("Save and file an issue") {
+		rw.state, rw.openIssuePage = reportHide, true
+	}
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Save the report and open a new GitHub issue in your browser;\n" +
+			"attach the saved report to the issue.")
+	}
+
+	if rw.status != "" {
+		imgui.Text(rw.status)
+	}
+
+	imgui.End()
+	return
+}
+
+// Capture creates the report if one has been requested. It must be called
+// after the frame has been rendered so that the screenshot is complete.
+func (rw *ReportWindow) Capture(r renderer.Renderer, p platform.Platform, config *Config, lg *log.Logger) {
+	if rw.state != reportCapture {
+		return
+	}
+	rw.state = reportIdle
+
+	var screenshot *image.RGBA
+	if rw.screenshot {
+		screenshot = readFramebuffer(r, p)
+	}
+
+	fn, err := rw.writeBundle(screenshot, config, lg)
+	if err != nil {
+		rw.status = "Unable to save report: " + err.Error()
+		lg.Errorf("%s: %v", fn, err)
+		return
+	}
+	rw.status = "Saved " + fn
+
+	if rw.openIssuePage {
+		body := rw.description + "\n\n(Please attach " + filepath.Base(fn) + ")"
+		browser.OpenURL("https://github.com/mmp/vice/issues/new?body=" + url.QueryEscape(body))
+	}
+}
+
+// readFramebuffer returns the contents of the framebuffer as an image.
+func readFramebuffer(r renderer.Renderer, p platform.Platform) *image.RGBA {
+	fb := p.FramebufferSize()
+	w, h := int(fb[0]), int(fb[1])
+	px := r.ReadPixelRGBAs(0, 0, w, h)
+
+	// OpenGL's origin is at the bottom of the window, so flip in y, and
+	// make it fully opaque.
+	for i := range h / 2 {
+		for j := range 4 * w {
+			a, b := 4*w*i+j, 4*w*(h-1-i)+j
+			px[a], px[b] = px[b], px[a]
+		}
+	}
+	for i := 3; i < len(px); i += 4 {
+		px[i] = 255
+	}
+
+	return &image.RGBA{Pix: px, Stride: 4 * w, Rect: image.Rect(0, 0, w, h)}
+}
+
+// writeBundle writes the report as a zip file in the user's home
+// directory and returns its path.
+func (rw *ReportWindow) writeBundle(screenshot *image.RGBA, config *Config, lg *log.Logger) (string, error) {
+	fn := filepath.Join(util.ExportDir(), "vice-report-"+time.Now().Format("20060102-150405")+".zip")
+
+	f, err := os.Create(fn)
+	if err != nil {
+		return fn, err
+	}
+	defer f.Close()
+
+	z := zip.NewWriter(f)
+	add := func(name string, write func(w io.Writer) error) {
+		if err != nil {
+			return
+		}
+		var w io.Writer
+		if w, err = z.Create(name); err == nil {
+			err = write(w)
+		}
+	}
+
+	add("report.txt", func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "vice %s (%s/%s)\n%s\n\n%s\n", buildVersion, runtime.GOOS, runtime.GOARCH,
+			time.Now().UTC().Format(time.RFC3339), rw.description)
+		return err
+	})
+	if screenshot != nil {
+		add("screenshot.png", func(w io.Writer) error { return png.Encode(w, screenshot) })
+	}
+	if rw.logs && lg.LogFile != "" {
+		add("vice.slog", func(w io.Writer) error { return copyLogTail(w, lg.LogFile) })
+	}
+	if rw.config {
+		add("config.json", func(w io.Writer) error { return writeAnonymizedConfig(w, config) })
+	}
+	if rw.trails && rw.controlClient != nil {
+		trails := &rw.controlClient.Trails
+		since := rw.controlClient.CurrentTime().Add(-reportTrailDuration)
+		for _, callsign := range trails.Callsigns() {
+			t := trails.Trails[callsign]
+			if t.Points[len(t.Points)-1].Time.After(since) {
+				add("trails/"+callsign+".csv", t.WriteCSV)
+			}
+		}
+	}
+
+	if err != nil {
+		z.Close()
+		return fn, err
+	}
+	return fn, z.Close()
+}
+
+// copyLogTail copies up to the last reportLogBytes of the log file to w.
+func copyLogTail(w io.Writer, fn string) error {
+	f, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if fi, err := f.Stat(); err != nil {
+		return err
+	} else if fi.Size() > reportLogBytes {
+		if _, err := f.Seek(-reportLogBytes, io.SeekEnd); err != nil {
+			return err
+		}
+	}
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// writeAnonymizedConfig writes the user's configuration, without the
+// saved sim and with the fields that may identify the user cleared.
+func writeAnonymizedConfig(w io.Writer, config *Config) error {
+	b, err := json.Marshal(&config.ConfigNoSim)
+	if err != nil {
+		return err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	for _, key := range []string{"Callsign", "LastServer", "RosterURL", "RosterCID", "ReadBulletins", "ImGuiSettings"} {
+		delete(m, key)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	return enc.Encode(m)
+}
@@ -0,0 +1,202 @@
+// reroute.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/log"
+	"github.com/mmp/vice/pkg/math"
+	"github.com/mmp/vice/pkg/sim"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// RerouteWindow lets the user specify closed airspace and then finds the
+// aircraft whose routes pass through it, suggesting amended routes that
+// can be issued to all of them at once.
+type RerouteWindow struct {
+	controlClient *sim.ControlClient
+	lg            *log.Logger
+
+	// User input describing the closed airspace: either a list of fixes
+	// or lat-longs giving a polygon or a single point and a radius.
+	vertices string
+	radius   float32
+	floor    int32
+	ceiling  int32
+	err      string
+
+	closed      []av.AirspaceVolume
+	suggestions []sim.RerouteSuggestion
+	selected    map[string]bool
+	results     map[string]string
+}
+
+func MakeRerouteWindow(controlClient *sim.ControlClient, lg *log.Logger) *RerouteWindow {
+	return &RerouteWindow{
+		controlClient: controlClient,
+		lg:            lg,
+		ceiling:       18000,
+		selected:      make(map[string]bool),
+		results:       make(map[string]string),
+	}
+}
+
+// parseClosedAirspace converts the user's closed airspace specification
+// into an AirspaceVolume.
+func (rw *RerouteWindow) parseClosedAirspace() (av.AirspaceVolume, error) {
+	vol := av.AirspaceVolume{
+		Name:    "CLOSED",
+		Floor:   int(rw.floor),
+		Ceiling: int(rw.ceiling),
+	}
+	if vol.Ceiling <= vol.Floor {
+		return vol, fmt.Errorf("Ceiling must be above floor")
+	}
+
+	for _, f := range strings.Fields(rw.vertices) {
+		if p, ok := rw.controlClient.Locate(f); !ok {
+			return vol, fmt.Errorf("%s: unknown fix or location", f)
+		} else {
+			vol.Vertices = append(vol.Vertices, p)
+		}
+	}
+
+	if rw.radius > 0 {
+		if len(vol.Vertices) != 1 {
+			return vol, fmt.Errorf("A single center point must be given with a radius")
+		}
+		vol.Type = av.AirspaceVolumeCircle
+		vol.Center, vol.Vertices = vol.Vertices[0], nil
+		vol.Radius = rw.radius
+	} else if len(vol.Vertices) < 3 {
+		return vol, fmt.Errorf("At least three vertices are required for a polygon")
+	} else {
+		vol.Type = av.AirspaceVolumePolygon
+	}
+	return vol, nil
+}
+
+func (rw *RerouteWindow) findReroutes() {
+	rw.suggestions = rw.controlClient.SuggestReroutes(rw.closed)
+	clear(rw.selected)
+	clear(rw.results)
+	for _, s := range rw.suggestions {
+		rw.selected[s.Callsign] = !s.Unresolved
+	}
+}
+
+func (rw *RerouteWindow) applyReroutes() {
+	for _, s := range rw.suggestions {
+		if !rw.selected[s.Callsign] || s.Unresolved {
+			continue
+		}
+
+		callsign := s.Callsign
+		rw.results[callsign] = "Pending"
+		rw.controlClient.RunAircraftCommands(callsign, s.Command,
+			func(message string, remainingInput string) {
+				if message != "" {
+					rw.results[callsign] = message
+				} else {
+					rw.results[callsign] = "Issued"
+				}
+			})
+		rw.selected[callsign] = false
+	}
+}
+
+func (rw *RerouteWindow) Draw() (show bool) {
+	show = true
+	imgui.BeginV("Closed Airspace Reroutes", &show, imgui.WindowFlagsAlwaysAutoResize)
+
+	imgui.Text("Closed airspace: polygon vertices, or center point with radius")
+	imgui.InputTextV("Vertices", &rw.vertices, imgui.InputTextFlagsCharsUppercase, nil)
+	imgui.SliderFloatV("Radius (nm)", &rw.radius, 0, 50, "%.1f", 0)
+	imgui.InputIntV("Floor (ft)", &rw.floor, 100, 1000, 0)
+	imgui.InputIntV("Ceiling (ft)", &rw.ceiling, 100, 1000, 0)
+	rw.floor, rw.ceiling = math.Max(0, rw.floor), math.Max(0, rw.ceiling)
+
+	if imgui.Button("Add closed airspace") {
+		if vol, err := rw.parseClosedAirspace(); err != nil {
+			rw.err = err.Error()
+		} else {
+			rw.err = ""
+			rw.closed = append(rw.closed, vol)
+			rw.vertices = ""
+			rw.findReroutes()
+		}
+	}
+	imgui.SameLine()
+	noneClosed := len(rw.closed) == 0
+	uiStartDisable(noneClosed)
+	if imgui.Button("Clear") {
+		rw.closed, rw.suggestions, rw.err = nil, nil, ""
+		clear(rw.selected)
+		clear(rw.results)
+	}
+	uiEndDisable(noneClosed)
+	if rw.err != "" {
+		imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{1, .2, .2, 1})
+		imgui.Text(rw.err)
+		imgui.PopStyleColor()
+	}
+	if len(rw.closed) > 0 {
+		imgui.Text(fmt.Sprintf("%d closed airspace volume(s) defined", len(rw.closed)))
+	}
+
+	imgui.Separator()
+
+	uiStartDisable(len(rw.closed) == 0)
+	if imgui.Button("Find affected aircraft") {
+		rw.findReroutes()
+	}
+	uiEndDisable(len(rw.closed) == 0)
+
+	if len(rw.suggestions) == 0 {
+		imgui.Text("No aircraft under your control are routed through closed airspace.")
+	} else {
+		tableFlags := imgui.TableFlagsBordersV | imgui.TableFlagsBordersOuterH |
+			imgui.TableFlagsRowBg | imgui.TableFlagsSizingStretchProp
+		if imgui.BeginTableV("reroutes", 5, tableFlags, imgui.Vec2{}, 0) {
+			imgui.TableSetupColumn("Apply")
+			imgui.TableSetupColumn("Callsign")
+			imgui.TableSetupColumn("Route")
+			imgui.TableSetupColumn("Command")
+			imgui.TableSetupColumn("Status")
+			imgui.TableHeadersRow()
+
+			for _, s := range rw.suggestions {
+				imgui.TableNextRow()
+				imgui.TableNextColumn()
+				uiStartDisable(s.Unresolved)
+				sel := rw.selected[s.Callsign]
+				imgui.Checkbox("##"+s.Callsign, &sel)
+				rw.selected[s.Callsign] = sel
+				uiEndDisable(s.Unresolved)
+
+				imgui.TableNextColumn()
+				imgui.Text(s.Callsign)
+				imgui.TableNextColumn()
+				imgui.Text(s.Route())
+				imgui.TableNextColumn()
+				imgui.Text(s.Command)
+				imgui.TableNextColumn()
+				imgui.Text(rw.results[s.Callsign])
+			}
+			imgui.EndTable()
+		}
+
+		if imgui.Button("Apply selected reroutes") {
+			rw.applyReroutes()
+		}
+	}
+
+	imgui.End()
+	return
+}
@@ -0,0 +1,206 @@
+// signoff.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/mmp/vice/pkg/platform"
+	"github.com/mmp/vice/pkg/sim"
+	"github.com/mmp/vice/pkg/util"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// signOffDrop is the action for tracks that are to be dropped rather
+// than handed off.
+const signOffDrop = "Drop track"
+
+// SignOffWindow lists the tracks the user owns and lets them be handed
+// off or dropped all at once when closing a position.
+type SignOffWindow struct {
+	controlClient *sim.ControlClient
+
+	selected map[string]bool
+	// actions maps from callsign to the controller the track is to be
+	// handed off to, or signOffDrop.
+	actions map[string]string
+	results map[string]string
+	// allAction is the action selected for "Set all".
+	allAction string
+}
+
+func MakeSignOffWindow(controlClient *sim.ControlClient) *SignOffWindow {
+	return &SignOffWindow{
+		controlClient: controlClient,
+		selected:      make(map[string]bool),
+		actions:       make(map[string]string),
+		results:       make(map[string]string),
+	}
+}
+
+// tracks returns the callsigns of the aircraft the user is tracking.
+func (sw *SignOffWindow) tracks() []string {
+	var tracks []string
+	for _, callsign := range util.SortedMapKeys(sw.controlClient.Aircraft) {
+		if sw.controlClient.Aircraft[callsign].TrackingController == sw.controlClient.Callsign {
+			tracks = append(tracks, callsign)
+		}
+	}
+	return tracks
+}
+
+// targets returns the possible actions for a track: handing it off to
+// any other controller or dropping it.
+func (sw *SignOffWindow) targets() []string {
+	var t []string
+	for _, callsign := range util.SortedMapKeys(sw.controlClient.Controllers) {
+		if callsign != sw.controlClient.Callsign {
+			t = append(t, callsign)
+		}
+	}
+	return append(t, signOffDrop)
+}
+
+func (sw *SignOffWindow) actionLabel(action string) string {
+	if ctrl, ok := sw.controlClient.Controllers[action]; ok {
+		return fmt.Sprintf("%s (%s)", ctrl.Callsign, ctrl.Frequency)
+	}
+	return action
+}
+
+func (sw *SignOffWindow) apply(tracks []string) {
+	for _, callsign := range tracks {
+		action := sw.actions[callsign]
+		onErr := func(err error) { sw.results[callsign] = err.Error() }
+
+		sw.results[callsign] = "Pending"
+		if action == signOffDrop {
+			sw.controlClient.DropTrack(callsign, func(any) { sw.results[callsign] = "Dropped" }, onErr)
+		} else {
+			sw.controlClient.HandoffTrack(callsign, action,
+				func(any) { sw.results[callsign] = "Handoff to " + action }, onErr)
+		}
+		sw.selected[callsign] = false
+	}
+}
+
+func (sw *SignOffWindow) Draw(p platform.Platform) (show bool) {
+	show = true
+	imgui.BeginV("Sign Off", &show, imgui.WindowFlagsAlwaysAutoResize)
+
+	tracks := sw.tracks()
+	targets := sw.targets()
+
+	// Keep results for tracks that were dropped so the user can see
+	// that it worked.
+	for callsign := range sw.results {
+		if !slices.Contains(tracks, callsign) {
+			tracks = append(tracks, callsign)
+		}
+	}
+	slices.Sort(tracks)
+
+	if len(tracks) == 0 {
+		imgui.Text("You are not tracking any aircraft.")
+		imgui.End()
+		return
+	}
+
+	imgui.SetNextItemWidth(200)
+	if imgui.BeginCombo("##all", sw.actionLabel(sw.allAction)) {
+		for _, t := range targets {
+			if imgui.SelectableV(sw.actionLabel(t), t == sw.allAction, 0, imgui.Vec2{}) {
+				sw.allAction = t
+			}
+		}
+		imgui.EndCombo()
+	}
+	imgui.SameLine()
+	uiStartDisable(sw.allAction == "")
+	if imgui.Button("Set all selected") {
+		for callsign, sel := range sw.selected {
+			if sel {
+				sw.actions[callsign] = sw.allAction
+			}
+		}
+	}
+	uiEndDisable(sw.allAction == "")
+
+	tableFlags := imgui.TableFlagsBordersV | imgui.TableFlagsBordersOuterH |
+		imgui.TableFlagsRowBg | imgui.TableFlagsSizingFixedFit
+	if imgui.BeginTableV("signoff", 4, tableFlags, imgui.Vec2{}, 0) {
+		imgui.TableSetupColumn("Select")
+		imgui.TableSetupColumn("Callsign")
+		imgui.TableSetupColumn("Action")
+		imgui.TableSetupColumn("Status")
+		imgui.TableHeadersRow()
+
+		for _, callsign := range tracks {
+			imgui.PushID(callsign)
+			imgui.TableNextRow()
+			imgui.TableNextColumn()
+			sel := sw.selected[callsign]
+			imgui.Checkbox("##sel", &sel)
+			sw.selected[callsign] = sel
+
+			imgui.TableNextColumn()
+			imgui.Text(callsign)
+
+			imgui.TableNextColumn()
+			imgui.SetNextItemWidth(200)
+			if imgui.BeginCombo("##action", sw.actionLabel(sw.actions[callsign])) {
+				for _, t := range targets {
+					if imgui.SelectableV(sw.actionLabel(t), t == sw.actions[callsign], 0, imgui.Vec2{}) {
+						sw.actions[callsign] = t
+						sw.selected[callsign] = true
+					}
+				}
+				imgui.EndCombo()
+			}
+
+			imgui.TableNextColumn()
+			imgui.Text(sw.results[callsign])
+			imgui.PopID()
+		}
+		imgui.EndTable()
+	}
+
+	if imgui.Button("Select all") {
+		for _, callsign := range tracks {
+			sw.selected[callsign] = true
+		}
+	}
+	imgui.SameLine()
+
+	// Only selected tracks with an action are included.
+	var apply []string
+	for _, callsign := range tracks {
+		if sw.selected[callsign] && sw.actions[callsign] != "" {
+			apply = append(apply, callsign)
+		}
+	}
+	uiStartDisable(len(apply) == 0)
+	if imgui.Button("Apply to selected") {
+		var handoffs, drops int
+		for _, callsign := range apply {
+			if sw.actions[callsign] == signOffDrop {
+				drops++
+			} else {
+				handoffs++
+			}
+		}
+		uiShowModalDialog(NewModalDialogBox(&YesOrNoModalClient{
+			title: "Sign Off",
+			query: fmt.Sprintf("Initiate %d handoff(s) and drop %d track(s)?", handoffs, drops),
+			ok:    func() { sw.apply(apply) },
+		}, p), true)
+	}
+	uiEndDisable(len(apply) == 0)
+
+	imgui.End()
+	return
+}
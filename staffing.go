@@ -0,0 +1,181 @@
+// staffing.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mmp/vice/pkg/platform"
+	"github.com/mmp/vice/pkg/sim"
+	"github.com/mmp/vice/pkg/util"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// PositionTimer tracks how long the user has been working the current
+// position (in wall-clock time, since it's about the controller and not
+// the simulation) and periodically reminds them to take a break.
+type PositionTimer struct {
+	start        time.Time
+	nextReminder time.Time
+	reminding    bool
+}
+
+func (pt *PositionTimer) Reset(config *Config) {
+	pt.start = time.Now()
+	pt.nextReminder = pt.start.Add(time.Duration(config.BreakReminderMinutes) * time.Minute)
+	pt.reminding = false
+}
+
+func (pt *PositionTimer) Elapsed() time.Duration {
+	return time.Since(pt.start)
+}
+
+func (pt *PositionTimer) String() string {
+	d := pt.Elapsed()
+	return fmt.Sprintf("%d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// Update shows the break reminder dialog if it's time to.
+func (pt *PositionTimer) Update(config *Config, p platform.Platform) {
+	if pt.start.IsZero() {
+		pt.Reset(config)
+	}
+	if config.DisableBreakReminders || pt.reminding || time.Now().Before(pt.nextReminder) {
+		return
+	}
+
+	pt.reminding = true
+	uiShowModalDialog(NewModalDialogBox(&BreakReminderModalClient{timer: pt, config: config}, p), false)
+}
+
+type BreakReminderModalClient struct {
+	timer  *PositionTimer
+	config *Config
+}
+
+func (b *BreakReminderModalClient) Title() string { return "Break Reminder" }
+func (b *BreakReminderModalClient) Opening()      {}
+
+func (b *BreakReminderModalClient) Buttons() []ModalDialogButton {
+	return []ModalDialogButton{
+		ModalDialogButton{
+			text: "Snooze 15 minutes",
+			action: func() bool {
+				b.timer.nextReminder = time.Now().Add(15 * time.Minute)
+				b.timer.reminding = false
+				return true
+			},
+		},
+		ModalDialogButton{
+			text: "Back from break",
+			action: func() bool {
+				b.timer.Reset(b.config)
+				return true
+			},
+		},
+	}
+}
+
+func (b *BreakReminderModalClient) Draw() int {
+	imgui.Text(fmt.Sprintf("\n\nYou have been on position for %s. Time to take a break?\n\n", b.timer.String()))
+	return -1
+}
+
+// StaffingWindow shows who is working which position and for how long,
+// along with free-form notes about each position (planned reliefs,
+// combined sectors, etc.)
+type StaffingWindow struct {
+	controlClient *sim.ControlClient
+	timer         *PositionTimer
+	notes         map[string]string // controller callsign -> notes
+	splitErr      string
+}
+
+func MakeStaffingWindow(controlClient *sim.ControlClient, timer *PositionTimer) *StaffingWindow {
+	return &StaffingWindow{
+		controlClient: controlClient,
+		timer:         timer,
+		notes:         make(map[string]string),
+	}
+}
+
+func (sw *StaffingWindow) Draw(config *Config) (show bool) {
+	show = true
+	imgui.BeginV("Staffing", &show, imgui.WindowFlagsAlwaysAutoResize)
+
+	imgui.Text("On position " + sw.timer.String())
+	imgui.SameLine()
+	if imgui.Button("Reset timer") {
+		sw.timer.Reset(config)
+	}
+
+	// The split can be changed mid-session, e.g. to open a sector for a
+	// controller who has just signed on.
+	if splits := sw.controlClient.SplitConfigurations; splits.Len() > 1 {
+		imgui.SetNextItemWidth(200)
+		if imgui.BeginComboV("Sector configuration", sw.controlClient.SelectedSplit, imgui.ComboFlagsHeightLarge) {
+			for _, split := range splits.Splits() {
+				if imgui.SelectableV(split, split == sw.controlClient.SelectedSplit, 0, imgui.Vec2{}) &&
+					split != sw.controlClient.SelectedSplit {
+					sw.splitErr = ""
+					sw.controlClient.ChangeSplit(split, func(err error) { sw.splitErr = err.Error() })
+				}
+			}
+			imgui.EndCombo()
+		}
+		if sw.splitErr != "" {
+			imgui.Text(sw.splitErr)
+		}
+	}
+	if names := sw.controlClient.ControllerAirspaceNames(); len(names) > 0 {
+		imgui.Text("Your airspace: " + strings.Join(names, ", "))
+	}
+
+	tableFlags := imgui.TableFlagsBordersV | imgui.TableFlagsBordersOuterH | imgui.TableFlagsRowBg
+	if imgui.BeginTableV("staffing", 5, tableFlags, imgui.Vec2{}, 0) {
+		imgui.TableSetupColumn("Position")
+		imgui.TableSetupColumn("Sector")
+		imgui.TableSetupColumn("Since")
+		imgui.TableSetupColumn("Time on")
+		imgui.TableSetupColumn("Notes")
+		imgui.TableHeadersRow()
+
+		now := time.Now() // sign-on times are wall-clock times
+		for _, callsign := range util.SortedMapKeys(sw.controlClient.Controllers) {
+			ctrl := sw.controlClient.Controllers[callsign]
+			if !ctrl.IsHuman {
+				continue
+			}
+
+			imgui.TableNextRow()
+			imgui.TableNextColumn()
+			imgui.Text(callsign)
+			imgui.TableNextColumn()
+			imgui.Text(ctrl.SectorId)
+			imgui.TableNextColumn()
+			if !ctrl.SignOnTime.IsZero() {
+				imgui.Text(ctrl.SignOnTime.UTC().Format("1504Z"))
+				imgui.TableNextColumn()
+				d := now.Sub(ctrl.SignOnTime)
+				imgui.Text(fmt.Sprintf("%d:%02d", int(d.Hours()), int(d.Minutes())%60))
+			} else {
+				imgui.TableNextColumn()
+			}
+			imgui.TableNextColumn()
+			notes := sw.notes[callsign]
+			imgui.SetNextItemWidth(250)
+			if imgui.InputText("##notes-"+callsign, &notes) {
+				sw.notes[callsign] = notes
+			}
+		}
+		imgui.EndTable()
+	}
+
+	imgui.End()
+	return
+}
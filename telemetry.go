@@ -0,0 +1,183 @@
+// telemetry.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/mmp/vice/pkg/log"
+	"github.com/mmp/vice/pkg/sim"
+	"github.com/mmp/vice/pkg/util"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// Users may opt in to sending anonymous usage metrics--frame times,
+// memory use, which video maps are in use, and how many sessions ended
+// without a crash--to help prioritize optimization work. Nothing is
+// collected unless they have. Each session's report is stored in the
+// config when vice exits and sent to the server when it next starts, so
+// that exiting isn't delayed by the network; the settings window shows
+// exactly what would be sent.
+
+// sessionMarkerFile is created in the config directory at startup and
+// removed when vice exits normally; if it's present at startup, the
+// previous session crashed.
+const sessionMarkerFile = "session-running"
+
+// slowFrameTime is the frame time above which a frame is counted as slow.
+const slowFrameTime = 50 * time.Millisecond
+
+type Telemetry struct {
+	start     time.Time
+	lastFrame time.Time
+	frames    int
+	total     time.Duration
+	max       time.Duration
+	slow      int
+	// histogram of frame times, in milliseconds; the last bucket also
+	// counts all longer frames.
+	histogram [200]int
+}
+
+// MakeTelemetry should be called at startup after the config has been
+// loaded; if the user has opted in, it records whether the previous
+// session crashed and sends the previous session's report.
+func MakeTelemetry(config *Config, serverAddress string, lg *log.Logger) *Telemetry {
+	t := &Telemetry{start: time.Now()}
+	if !config.TelemetryEnabled {
+		return t
+	}
+
+	if fn, err := sessionMarkerPath(); err == nil {
+		if _, err := os.Stat(fn); err == nil {
+			config.CrashedSessions++
+		}
+		if err := os.WriteFile(fn, nil, 0o600); err != nil {
+			lg.Warnf("%s: %v", fn, err)
+		}
+	}
+
+	if r := config.PendingTelemetry; r != nil && serverAddress != "" {
+		config.PendingTelemetry = nil
+		go func() {
+			if err := sim.SendTelemetry(serverAddress, r, lg); err != nil {
+				lg.Infof("Unable to send usage metrics: %v", err)
+			}
+		}()
+	}
+	return t
+}
+
+func sessionMarkerPath() (string, error) {
+	dir, err := util.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, sessionMarkerFile), nil
+}
+
+// Frame should be called once each time through the main loop.
+func (t *Telemetry) Frame() {
+	now := time.Now()
+	if !t.lastFrame.IsZero() {
+		d := now.Sub(t.lastFrame)
+		t.frames++
+		t.total += d
+		t.max = max(t.max, d)
+		if d > slowFrameTime {
+			t.slow++
+		}
+		t.histogram[min(int(d/time.Millisecond), len(t.histogram)-1)]++
+	}
+	t.lastFrame = now
+}
+
+// percentile returns the frame time, in milliseconds, that the given
+// fraction of frames took no longer than.
+func (t *Telemetry) percentile(p float32) float32 {
+	n := int(p * float32(t.frames))
+	for ms, count := range t.histogram {
+		if n -= count; n <= 0 {
+			return float32(ms + 1)
+		}
+	}
+	return float32(len(t.histogram))
+}
+
+// Report returns the report for the session so far.
+func (t *Telemetry) Report(config *Config, controlClient *sim.ControlClient) sim.TelemetryReport {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	r := sim.TelemetryReport{
+		OS:              runtime.GOOS,
+		Arch:            runtime.GOARCH,
+		CPUs:            runtime.NumCPU(),
+		ConfigVersion:   CurrentConfigVersion,
+		SessionMinutes:  float32(time.Since(t.start).Minutes()),
+		Frames:          t.frames,
+		MaxFrameMs:      float32(t.max.Seconds() * 1000),
+		SlowFrames:      t.slow,
+		MemoryMB:        int(mem.Sys >> 20),
+		CleanSessions:   config.CleanSessions,
+		CrashedSessions: config.CrashedSessions,
+	}
+	if t.frames > 0 {
+		r.MeanFrameMs = float32(t.total.Seconds()*1000) / float32(t.frames)
+		r.P95FrameMs = t.percentile(0.95)
+	}
+	if controlClient != nil && controlClient.State.STARSFacilityAdaptation.VideoMapFile != "" {
+		fa := &controlClient.State.STARSFacilityAdaptation
+		r.VideoMapFile = filepath.Base(fa.VideoMapFile)
+		r.VideoMaps = len(fa.VideoMapNames)
+	}
+	return r
+}
+
+// Exit should be called when vice is exiting normally, before the config
+// is saved; it stores the session's report to be sent the next time vice
+// starts.
+func (t *Telemetry) Exit(config *Config, controlClient *sim.ControlClient) {
+	if !config.TelemetryEnabled {
+		return
+	}
+
+	config.CleanSessions++
+	r := t.Report(config, controlClient)
+	config.PendingTelemetry = &r
+
+	if fn, err := sessionMarkerPath(); err == nil {
+		os.Remove(fn)
+	}
+}
+
+// uiDrawTelemetryUI draws the settings for usage metrics, including the
+// report that would be sent for the current session.
+func uiDrawTelemetryUI(t *Telemetry, config *Config, controlClient *sim.ControlClient) {
+	if imgui.Checkbox("Send anonymous usage metrics", &config.TelemetryEnabled) && !config.TelemetryEnabled {
+		// Don't send anything that was collected before opting out.
+		config.PendingTelemetry = nil
+		config.CleanSessions, config.CrashedSessions = 0, 0
+		if fn, err := sessionMarkerPath(); err == nil {
+			os.Remove(fn)
+		}
+	}
+	imgui.Text("Helps prioritize optimization work. Frame times, memory use, the video maps in use, and\n" +
+		"how many sessions ended without a crash are sent to the vice server when vice next starts.\n" +
+		"Nothing that identifies you is sent.")
+
+	if imgui.TreeNode("What would be sent") {
+		r := t.Report(config, controlClient)
+		b, _ := json.MarshalIndent(r, "", "  ")
+		s := string(b)
+		imgui.InputTextMultilineV("##telemetry", &s, imgui.Vec2{X: 400, Y: 300}, imgui.InputTextFlagsReadOnly, nil)
+		imgui.TreePop()
+	}
+}
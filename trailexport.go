@@ -0,0 +1,123 @@
+// trailexport.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mmp/vice/pkg/sim"
+	"github.com/mmp/vice/pkg/util"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// TrailExportWindow shows the timeline of events for a single aircraft
+// and allows exporting its recorded track history and timeline as CSV or
+// KML.
+type TrailExportWindow struct {
+	controlClient *sim.ControlClient
+	callsign      string
+	status        string
+}
+
+func MakeTrailExportWindow(controlClient *sim.ControlClient) *TrailExportWindow {
+	return &TrailExportWindow{controlClient: controlClient}
+}
+
+func (te *TrailExportWindow) Draw() (show bool) {
+	show = true
+	imgui.BeginV("Export Flight Trail", &show, imgui.WindowFlagsAlwaysAutoResize)
+
+	trails := &te.controlClient.Trails
+	callsigns := trails.Callsigns()
+	if len(callsigns) == 0 {
+		imgui.Text("No aircraft tracks have been recorded yet.")
+		imgui.End()
+		return
+	}
+
+	imgui.SetNextItemWidth(150)
+	if imgui.BeginComboV("Aircraft", te.callsign, imgui.ComboFlagsHeightLarge) {
+		for _, cs := range callsigns {
+			if imgui.SelectableV(cs, cs == te.callsign, 0, imgui.Vec2{}) {
+				te.callsign = cs
+				te.status = ""
+			}
+		}
+		imgui.EndCombo()
+	}
+
+	trail, ok := trails.Trails[te.callsign]
+	if !ok || len(trail.Points) == 0 {
+		imgui.End()
+		return
+	}
+
+	start, end := trail.Points[0].Time, trail.Points[len(trail.Points)-1].Time
+	imgui.Text(fmt.Sprintf("%s %s: %s-%s, %d positions, %d events", trail.Callsign, trail.AircraftType,
+		start.UTC().Format("1504:05Z"), end.UTC().Format("1504:05Z"), len(trail.Points), len(trail.Events)))
+
+	if len(trail.Events) > 0 {
+		flags := imgui.TableFlagsBordersV | imgui.TableFlagsBordersOuterH | imgui.TableFlagsRowBg |
+			imgui.TableFlagsSizingFixedFit | imgui.TableFlagsScrollY
+		if imgui.BeginTableV("timeline", 4, flags, imgui.Vec2{X: 700, Y: 300}, 0) {
+			imgui.TableSetupScrollFreeze(0, 1)
+			imgui.TableSetupColumn("Time")
+			imgui.TableSetupColumn("Event")
+			imgui.TableSetupColumn("Controller")
+			imgui.TableSetupColumn("Description")
+			imgui.TableHeadersRow()
+			for _, ev := range trail.Events {
+				imgui.TableNextRow()
+				imgui.TableNextColumn()
+				imgui.Text(ev.Time.UTC().Format("15:04:05Z"))
+				imgui.TableNextColumn()
+				imgui.Text(string(ev.Type))
+				imgui.TableNextColumn()
+				imgui.Text(ev.Controller)
+				imgui.TableNextColumn()
+				imgui.Text(ev.Description)
+			}
+			imgui.EndTable()
+		}
+	}
+
+	if imgui.Button("Export CSV") {
+		te.export(trail, "csv", trail.WriteCSV)
+	}
+	imgui.SameLine()
+	if imgui.Button("Export KML") {
+		te.export(trail, "kml", trail.WriteKML)
+	}
+
+	if te.status != "" {
+		imgui.Text(te.status)
+	}
+
+	imgui.End()
+	return
+}
+
+// export writes the trail to a file in the user's home directory.
+func (te *TrailExportWindow) export(trail *sim.AircraftTrail, ext string, write func(io.Writer) error) {
+	fn := filepath.Join(util.ExportDir(), fmt.Sprintf("vice-%s-%s.%s", trail.Callsign, time.Now().Format("20060102-150405"), ext))
+
+	f, err := os.Create(fn)
+	if err != nil {
+		te.status = err.Error()
+		return
+	}
+	defer f.Close()
+
+	if err := write(f); err != nil {
+		te.status = err.Error()
+	} else {
+		te.status = "Saved " + fn
+	}
+}
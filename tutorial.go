@@ -0,0 +1,251 @@
+// tutorial.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"fmt"
+
+	av "github.com/mmp/vice/pkg/aviation"
+	"github.com/mmp/vice/pkg/panes"
+	"github.com/mmp/vice/pkg/panes/stars"
+	"github.com/mmp/vice/pkg/platform"
+	"github.com/mmp/vice/pkg/sim"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// tutorialStep is a single step of the interactive tutorial.
+type tutorialStep struct {
+	title string
+	text  string
+	// highlight returns true for the pane that should be outlined while
+	// the step is active; it may be nil.
+	highlight     func(panes.Pane) bool
+	highlightMenu bool
+	// done reports whether the user has completed the step's task; steps
+	// where it is nil are advanced with the "Next" button.
+	done func(tw *TutorialWindow, events []sim.Event) bool
+}
+
+func isSTARSPane(p panes.Pane) bool {
+	_, ok := p.(*stars.STARSPane)
+	return ok
+}
+
+func isMessagesPane(p panes.Pane) bool {
+	_, ok := p.(*panes.MessagesPane)
+	return ok
+}
+
+func isFlightStripPane(p panes.Pane) bool {
+	_, ok := p.(*panes.FlightStripPane)
+	return ok
+}
+
+var tutorialSteps = []tutorialStep{
+	{
+		title: "The scope",
+		text: "This is the STARS scope. Each radar track shows a position symbol and, for aircraft " +
+			"that you or other controllers are tracking, a datablock with the callsign, altitude, " +
+			"and groundspeed. Traffic is provided by the simulator; you can pause it at any time " +
+			"with the button in the menu bar.",
+		highlight: isSTARSPane,
+	},
+	{
+		title: "The menu bar",
+		text: "The menu bar has the sim controls, settings, and tools. Hover over a button to see " +
+			"what it does; the keyboard button shows a summary of the available commands.",
+		highlightMenu: true,
+	},
+	{
+		title: "Messages",
+		text: "Pilot readbacks and messages from other controllers are shown here. Commands may also " +
+			"be entered here by typing an aircraft's callsign, a space, and the commands.",
+		highlight: isMessagesPane,
+	},
+	{
+		title: "Flight strips",
+		text: "Flight strips for the aircraft you are working are shown here. Strips can be " +
+			"annotated and reordered by dragging them.",
+		highlight: isFlightStripPane,
+	},
+	{
+		title: "Accept a handoff",
+		text: "When another controller hands off an aircraft to you, its datablock flashes on the " +
+			"scope. Click on the aircraft's radar track with nothing entered to accept the handoff.",
+		highlight: isSTARSPane,
+		done: func(tw *TutorialWindow, events []sim.Event) bool {
+			for _, e := range events {
+				if e.Type == sim.AcceptedHandoffEvent && e.ToController == tw.controlClient.Callsign {
+					return true
+				}
+			}
+			return false
+		},
+	},
+	{
+		title: "Issue an instruction",
+		text: "Now give an aircraft you are tracking an instruction: enter commands and click its " +
+			"track. For example, \"D40\" descends to 4,000', \"H270\" assigns heading 270, and " +
+			"\"S210\" assigns 210 knots. Multiple commands can be given at once, separated by spaces. " +
+			"The pilot's readback appears in the messages pane.",
+		highlight: isSTARSPane,
+		done: func(tw *TutorialWindow, events []sim.Event) bool {
+			for _, e := range events {
+				if e.Type == sim.RadioTransmissionEvent && e.ToController == tw.controlClient.Callsign &&
+					e.RadioTransmissionType == av.RadioTransmissionReadback {
+					return true
+				}
+			}
+			return false
+		},
+	},
+	{
+		title: "Amend the flight plan",
+		text: "Finally, enter a scratchpad for an aircraft you are tracking so that other " +
+			"controllers know your plans for it: enter up to three characters, e.g. the destination " +
+			"airport's identifier, and click its track.",
+		highlight: isSTARSPane,
+		done: func(tw *TutorialWindow, events []sim.Event) bool {
+			for callsign, ac := range tw.controlClient.Aircraft {
+				if ac.TrackingController != tw.controlClient.Callsign {
+					continue
+				}
+				if sp, ok := tw.scratchpads[callsign]; ok && sp != ac.Scratchpad {
+					return true
+				}
+			}
+			return false
+		},
+	},
+	{
+		title: "Done",
+		text: "That's the basics. The vice documentation covers the STARS commands in depth; the " +
+			"tutorial can be run again at any time from the menu bar.",
+	},
+}
+
+// TutorialWindow walks a new controller through the basics of working
+// traffic, highlighting the relevant parts of the UI and waiting for the
+// user to actually perform each task with the simulator's traffic.
+type TutorialWindow struct {
+	controlClient      *sim.ControlClient
+	eventsSubscription *sim.EventsSubscription
+	step               int
+	// scratchpads records the scratchpads of the aircraft when the
+	// current step started.
+	scratchpads map[string]string
+}
+
+func MakeTutorialWindow(controlClient *sim.ControlClient, eventStream *sim.EventStream) *TutorialWindow {
+	tw := &TutorialWindow{
+		controlClient:      controlClient,
+		eventsSubscription: eventStream.Subscribe(),
+	}
+	tw.startStep(0)
+	return tw
+}
+
+func (tw *TutorialWindow) startStep(step int) {
+	tw.step = step
+	tw.scratchpads = make(map[string]string)
+	for callsign, ac := range tw.controlClient.Aircraft {
+		tw.scratchpads[callsign] = ac.Scratchpad
+	}
+}
+
+// Close must be called when the tutorial is no longer being shown.
+func (tw *TutorialWindow) Close() {
+	tw.eventsSubscription.Unsubscribe()
+}
+
+func (tw *TutorialWindow) Draw(config *Config, p platform.Platform, menuBarHeight float32) (show bool) {
+	events := tw.eventsSubscription.Get()
+	step := &tutorialSteps[tw.step]
+
+	completed := step.done != nil && step.done(tw, events)
+	// Aircraft that appear during the step are compared to the
+	// scratchpad they first had.
+	for callsign, ac := range tw.controlClient.Aircraft {
+		if _, ok := tw.scratchpads[callsign]; !ok {
+			tw.scratchpads[callsign] = ac.Scratchpad
+		}
+	}
+	if completed {
+		tw.startStep(tw.step + 1)
+		step = &tutorialSteps[tw.step]
+	}
+
+	tw.drawHighlight(step, config, p, menuBarHeight)
+
+	show = true
+	imgui.BeginV("Tutorial", &show, imgui.WindowFlagsAlwaysAutoResize)
+
+	imgui.Text(fmt.Sprintf("Step %d of %d: %s", tw.step+1, len(tutorialSteps), step.title))
+	imgui.Separator()
+	imgui.PushTextWrapPosV(400)
+	imgui.Text(step.text)
+	imgui.PopTextWrapPos()
+	imgui.Separator()
+
+	uiStartDisable(tw.step == 0)
+	if imgui.Button("Back") {
+		tw.startStep(tw.step - 1)
+	}
+	uiEndDisable(tw.step == 0)
+	imgui.SameLine()
+	if tw.step == len(tutorialSteps)-1 {
+		if imgui.Button("Finish") {
+			show = false
+		}
+	} else if step.done == nil {
+		if imgui.Button("Next") {
+			tw.startStep(tw.step + 1)
+		}
+	} else {
+		imgui.Text("Waiting for you...")
+		imgui.SameLine()
+		if imgui.Button("Skip") {
+			tw.startStep(tw.step + 1)
+		}
+	}
+
+	imgui.End()
+	return
+}
+
+// drawHighlight outlines the part of the UI that the step refers to.
+func (tw *TutorialWindow) drawHighlight(step *tutorialStep, config *Config, p platform.Platform, menuBarHeight float32) {
+	displaySize := p.DisplaySize()
+	var p0, p1 imgui.Vec2
+	if step.highlightMenu {
+		p0, p1 = imgui.Vec2{X: 0, Y: 0}, imgui.Vec2{X: displaySize[0], Y: menuBarHeight}
+	} else if step.highlight != nil {
+		found := false
+		config.DisplayRoot.VisitPanes(func(pane panes.Pane) {
+			if found || !step.highlight(pane) {
+				return
+			}
+			if e, ok := panes.PaneExtent(pane); ok {
+				// Pane extents have y increasing upward, starting at the
+				// bottom of the window.
+				p0 = imgui.Vec2{X: e.P0[0], Y: displaySize[1] - e.P1[1]}
+				p1 = imgui.Vec2{X: e.P1[0], Y: displaySize[1] - e.P0[1]}
+				found = true
+			}
+		})
+		if !found {
+			return
+		}
+	} else {
+		return
+	}
+
+	const inset = 2
+	p0 = imgui.Vec2{X: p0.X + inset, Y: p0.Y + inset}
+	p1 = imgui.Vec2{X: p1.X - inset, Y: p1.Y - inset}
+	color := imgui.PackedColorFromVec4(imgui.Vec4{X: 1, Y: .8, Z: 0, W: 1})
+	imgui.ForegroundDrawList().AddRectV(p0, p1, color, 0, 0, 3)
+}
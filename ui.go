@@ -15,6 +15,7 @@ import (
 	"os"
 	"runtime"
 	"runtime/debug"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -42,6 +43,13 @@ var (
 
 		menuBarHeight float32
 
+		// Name being entered for a new layout snapshot
+		layoutName string
+		// File being entered to import a layout snapshot from
+		layoutImportFile string
+		// Name being entered for a new workspace
+		workspaceName string
+
 		showAboutDialog bool
 
 		iconTextureID     uint32
@@ -54,9 +62,45 @@ var (
 		launchControlWindow  *LaunchControlWindow
 		missingPrimaryDialog *ModalDialogBox
 
+		rerouteWindow   *RerouteWindow
+		interceptWindow *InterceptWindow
+		edctWindow      *EDCTWindow
+		reportWindow    *ReportWindow
+		diagWindow      *DiagnosticsWindow
+		briefingWindow  *ReliefBriefingWindow
+		trailWindow     *TrailExportWindow
+		bookmarkWindow  *BookmarksWindow
+		signOffWindow   *SignOffWindow
+		landlineWindow  *LandlineWindow
+		showBookmarks   bool
+		tutorialWindow  *TutorialWindow
+		helpTopic       *panes.HelpTopic
+		bulletinWindow  *BulletinWindow
+		showBulletin    bool
+		staffingWindow  *StaffingWindow
+		showStaffing    bool
+		positionTimer   PositionTimer
+		guardMessage    string
+
+		// Set once we've checked for an update to the facility's
+		// template for the user's position.
+		checkedPositionTemplate bool
+
+		// DPI scale of the display the window was on when the UI was
+		// last laid out.
+		dpiScale float32
+
 		// Scenario routes to draw on the scope
 		showSettings     bool
 		showScenarioInfo bool
+		// settingsPane is the pane whose settings were requested from
+		// its title bar; its section is opened in the settings window.
+		settingsPane panes.Pane
+
+		panePalette *PanePalette
+
+		telemetry *Telemetry
+		degrader  RenderingDegrader
 	}
 
 	//go:embed icons/tower-256x256.png
@@ -282,9 +326,43 @@ func imguiInit() *imgui.Context {
 	return context
 }
 
+// uiUpdateDPIScale checks whether the window has moved to a display with
+// a different DPI scale and if so, rescales the imgui style and fonts to
+// match.
+func uiUpdateDPIScale(p platform.Platform, lg *log.Logger) {
+	if d := p.DisplaySize(); d[0] == 0 || d[1] == 0 {
+		// Minimized windows report a zero-sized display.
+		return
+	}
+	s := p.DPIScale()
+	if s <= 0 || s == ui.dpiScale {
+		return
+	} else if ui.dpiScale <= 0 {
+		// We didn't have a valid scale at startup; there's nothing to
+		// rescale from.
+		ui.dpiScale = s
+		return
+	}
+
+	lg.Infof("DPI scale changed from %f to %f", ui.dpiScale, s)
+	if runtime.GOOS == "windows" {
+		// Only Windows needs rescaling: there, window coordinates are
+		// physical pixels, so the style sizes and fonts were scaled up
+		// by the DPI scale at startup (see uiInit() and FontsInit()).
+		// On macOS and Linux, window coordinates are in points that
+		// don't change size between displays, DPIScale() is the ratio
+		// of the framebuffer to the window size, and imgui's
+		// framebuffer scale takes care of the rest.
+		imgui.CurrentStyle().ScaleAllSizes(s / ui.dpiScale)
+		imgui.CurrentIO().SetFontGlobalScale(math.Max(1, s) / renderer.FontsDPIScale())
+	}
+	ui.dpiScale = s
+}
+
 func uiInit(r renderer.Renderer, p platform.Platform, config *Config, es *sim.EventStream, lg *log.Logger) {
+	ui.dpiScale = p.DPIScale()
 	if runtime.GOOS == "windows" {
-		imgui.CurrentStyle().ScaleAllSizes(p.DPIScale())
+		imgui.CurrentStyle().ScaleAllSizes(ui.dpiScale)
 	}
 
 	ui.font = renderer.GetFont(renderer.FontIdentifier{Name: "Roboto Regular", Size: config.UIFontSize})
@@ -390,6 +468,8 @@ func uiDraw(mgr *sim.ConnectionManager, config *Config, p platform.Platform, r r
 		}
 	}
 
+	uiUpdateDPIScale(p, lg)
+
 	imgui.PushFont(ui.font.Ifont)
 	if imgui.BeginMainMenuBar() {
 		imgui.PushStyleColor(imgui.StyleColorButton, imgui.CurrentStyle().Color(imgui.StyleColorMenuBarBg))
@@ -435,34 +515,34 @@ func uiDraw(mgr *sim.ConnectionManager, config *Config, p platform.Platform, r r
 			}
 		}
 
-		if imgui.Button(renderer.FontAwesomeIconKeyboard) {
-			uiToggleShowKeyboardWindow()
-		}
-		if imgui.IsItemHovered() {
-			imgui.SetTooltip("Show summary of keyboard commands")
-		}
+		uiDrawLayoutMenu(config, controlClient, r, p, eventStream, lg)
 
-		enableLaunch := controlClient != nil &&
-			(controlClient.LaunchConfig.Controller == "" || controlClient.LaunchConfig.Controller == controlClient.Callsign)
-		uiStartDisable(!enableLaunch)
-		if imgui.Button(renderer.FontAwesomeIconPlaneDeparture) {
-			controlClient.TakeOrReturnLaunchControl(eventStream)
-		}
-		if imgui.IsItemHovered() {
-			verb := util.Select(controlClient.LaunchConfig.Controller == "", "Start", "Stop")
-			tip := verb + " manually control spawning new aircraft"
-			if controlClient.LaunchConfig.Controller != "" {
-				tip += "\nCurrent controller: " + controlClient.LaunchConfig.Controller
+		if config.CompactMode {
+			if imgui.Button(renderer.FontAwesomeIconEllipsisH) {
+				imgui.OpenPopup("tools")
+			}
+			if imgui.IsItemHovered() {
+				imgui.SetTooltip("More")
 			}
-			imgui.SetTooltip(tip)
+			if imgui.BeginPopup("tools") {
+				uiDrawToolButtons(controlClient, config, eventStream, lg)
+				imgui.EndPopup()
+			}
+			uiDrawPaneSwitcher(config)
+		} else {
+			uiDrawToolButtons(controlClient, config, eventStream, lg)
 		}
-		uiEndDisable(!enableLaunch)
 
-		if imgui.Button(renderer.FontAwesomeIconBook) {
-			browser.OpenURL("https://pharr.org/vice/index.html")
-		}
-		if imgui.IsItemHovered() {
-			imgui.SetTooltip("Display online vice documentation")
+		if ui.degrader.Degraded() {
+			imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{1, .8, 0, 1})
+			if imgui.Button(renderer.FontAwesomeIconHourglassHalf) {
+				ui.showSettings = true
+			}
+			imgui.PopStyleColor()
+			if imgui.IsItemHovered() {
+				imgui.SetTooltip(fmt.Sprintf("Rendering is simplified since frames are taking %d ms to draw",
+					ui.degrader.AverageFrameTime().Milliseconds()))
+			}
 		}
 
 		width, _ := ui.font.BoundText(renderer.FontAwesomeIconInfoCircle, 0)
@@ -491,7 +571,7 @@ func uiDraw(mgr *sim.ConnectionManager, config *Config, p platform.Platform, r r
 	ui.menuBarHeight = imgui.CursorPos().Y - 1
 
 	if controlClient != nil {
-		uiDrawSettingsWindow(controlClient, config, p)
+		uiDrawSettingsWindow(controlClient, config, p, r, eventStream, lg)
 
 		if ui.showScenarioInfo {
 			ui.showScenarioInfo = controlClient.DrawScenarioInfoWindow(lg)
@@ -499,6 +579,64 @@ func uiDraw(mgr *sim.ConnectionManager, config *Config, p platform.Platform, r r
 
 		uiDrawMissingPrimaryDialog(mgr, controlClient, p)
 
+		if ui.rerouteWindow != nil && !ui.rerouteWindow.Draw() {
+			ui.rerouteWindow = nil
+		}
+		if ui.interceptWindow != nil && !ui.interceptWindow.Draw() {
+			ui.interceptWindow = nil
+		}
+		if ui.edctWindow != nil && !ui.edctWindow.Draw() {
+			ui.edctWindow = nil
+		}
+		if ui.briefingWindow != nil && !ui.briefingWindow.Draw(p) {
+			ui.briefingWindow = nil
+		}
+		if ui.trailWindow != nil && !ui.trailWindow.Draw() {
+			ui.trailWindow = nil
+		}
+		if ui.signOffWindow != nil && !ui.signOffWindow.Draw(p) {
+			ui.signOffWindow = nil
+		}
+		if ui.landlineWindow != nil && !ui.landlineWindow.Draw() {
+			ui.landlineWindow = nil
+		}
+		if ui.showBookmarks {
+			// As with staffing, the window persists while hidden so that
+			// the bookmarks are kept.
+			if ui.bookmarkWindow == nil {
+				ui.bookmarkWindow = MakeBookmarksWindow(controlClient)
+			}
+			ui.showBookmarks = ui.bookmarkWindow.Draw(config)
+		}
+		if ui.bulletinWindow != nil {
+			if ui.bulletinWindow.Update() && ui.bulletinWindow.Unread(config) {
+				// Show new bulletins as soon as they've loaded.
+				ui.showBulletin = true
+			}
+			if ui.showBulletin {
+				ui.showBulletin = ui.bulletinWindow.Draw(config)
+			}
+		}
+		if ui.tutorialWindow != nil && !ui.tutorialWindow.Draw(config, p, ui.menuBarHeight) {
+			ui.tutorialWindow.Close()
+			ui.tutorialWindow = nil
+		}
+		if ui.showStaffing {
+			// The window persists while hidden so that the notes are kept.
+			if ui.staffingWindow == nil {
+				ui.staffingWindow = MakeStaffingWindow(controlClient, &ui.positionTimer)
+			}
+			ui.showStaffing = ui.staffingWindow.Draw(config)
+		}
+		if controlClient.Connected() {
+			ui.positionTimer.Update(config, p)
+			if !ui.checkedPositionTemplate {
+				ui.checkedPositionTemplate = true
+				uiCheckPositionTemplate(config, controlClient, r, p, eventStream, lg)
+			}
+			uiDrawRadioWindow(controlClient, config)
+		}
+
 		if controlClient.LaunchConfig.Controller == controlClient.Callsign {
 			if ui.launchControlWindow == nil {
 				ui.launchControlWindow = MakeLaunchControlWindow(controlClient, lg)
@@ -510,13 +648,48 @@ func uiDraw(mgr *sim.ConnectionManager, config *Config, p platform.Platform, r r
 	for _, event := range ui.eventsSubscription.Get() {
 		if event.Type == sim.ServerBroadcastMessageEvent {
 			uiShowModalDialog(NewModalDialogBox(&BroadcastModalDialog{Message: event.Message}, p), false)
+		} else if event.Type == sim.RadioTransmissionEvent && controlClient != nil &&
+			controlClient.Radio.Receives(event.ToController) {
+			controlClient.Radio.Received(event.ToController, time.Now())
+		} else if event.Type == sim.GuardMessageEvent && controlClient != nil {
+			controlClient.Radio.ReceivedGuard(time.Now())
+		} else if event.Type == sim.LandlineMessageEvent && controlClient != nil &&
+			slices.Contains(controlClient.ConsolidatedPositions(), event.ToController) {
+			// An incoming call opens the landline panel.
+			if ui.landlineWindow == nil {
+				ui.landlineWindow = MakeLandlineWindow(controlClient)
+			}
+			ui.landlineWindow.Received(event, time.Now())
 		}
 	}
 
 	drawActiveDialogBoxes()
 
+	uiCheckLayoutShortcuts(config, controlClient, r, p, eventStream, lg)
+	uiCheckWorkspaceShortcuts(config, controlClient, r, p, eventStream, lg)
+
+	uiCheckPanePaletteShortcut()
+	if ui.panePalette != nil && !ui.panePalette.Draw(config, p) {
+		ui.panePalette = nil
+	}
+
 	uiDrawKeyboardWindow(controlClient, config)
 
+	if ui.reportWindow != nil && !ui.reportWindow.Draw() {
+		ui.reportWindow = nil
+	}
+	if ui.diagWindow != nil && !ui.diagWindow.Draw(config, lg) {
+		ui.diagWindow = nil
+	}
+
+	if t, ok := panes.PendingHelp(); ok {
+		ui.helpTopic = &t
+	}
+	if pane, ok := panes.PendingSettings(); ok {
+		ui.showSettings, ui.settingsPane = true, pane
+	}
+	uiDrawHelpWindow(config)
+
 	imgui.PopFont()
 
 	// Finalize and submit the imgui draw lists
@@ -524,11 +697,441 @@ func uiDraw(mgr *sim.ConnectionManager, config *Config, p platform.Platform, r r
 	cb := renderer.GetCommandBuffer()
 	defer renderer.ReturnCommandBuffer(cb)
 	renderer.GenerateImguiCommandBuffer(cb, p.DisplaySize(), p.FramebufferSize(), lg)
-	return r.RenderCommandBuffer(cb)
+	stats := r.RenderCommandBuffer(cb)
+
+	if ui.reportWindow != nil {
+		ui.reportWindow.Capture(r, p, config, lg)
+	}
+
+	return stats
+}
+
+// uiDrawToolButtons draws the menu bar buttons for vice's various tool
+// windows; in compact mode, they are drawn in a popup instead.
+func uiDrawToolButtons(controlClient *sim.ControlClient, config *Config, eventStream *sim.EventStream, lg *log.Logger) {
+	if controlClient != nil && controlClient.Connected() {
+		if imgui.Button(renderer.FontAwesomeIconRoute) {
+			if ui.rerouteWindow == nil {
+				ui.rerouteWindow = MakeRerouteWindow(controlClient, lg)
+			} else {
+				ui.rerouteWindow = nil
+			}
+		}
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip("Reroute aircraft around closed airspace")
+		}
+
+		if imgui.Button(renderer.FontAwesomeIconCompass) {
+			if ui.interceptWindow == nil {
+				ui.interceptWindow = MakeInterceptWindow(controlClient)
+			} else {
+				ui.interceptWindow = nil
+			}
+		}
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip("Find the heading to intercept a course")
+		}
+
+		if imgui.Button(renderer.FontAwesomeIconHourglassHalf) {
+			if ui.edctWindow == nil {
+				ui.edctWindow = MakeEDCTWindow(controlClient)
+			} else {
+				ui.edctWindow = nil
+			}
+		}
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip("Assign and track departure EDCTs")
+		}
+
+		if imgui.Button(renderer.FontAwesomeIconClipboardList) {
+			if ui.briefingWindow == nil {
+				ui.briefingWindow = MakeReliefBriefingWindow(controlClient)
+			} else {
+				ui.briefingWindow = nil
+			}
+		}
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip("Generate position relief briefing")
+		}
+
+		if imgui.Button(renderer.FontAwesomeIconSignOutAlt) {
+			if ui.signOffWindow == nil {
+				ui.signOffWindow = MakeSignOffWindow(controlClient)
+			} else {
+				ui.signOffWindow = nil
+			}
+		}
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip("Hand off or drop all of your tracks when signing off")
+		}
+
+		if imgui.Button(renderer.FontAwesomeIconPhone) {
+			if ui.landlineWindow == nil {
+				ui.landlineWindow = MakeLandlineWindow(controlClient)
+			} else {
+				ui.landlineWindow = nil
+			}
+		}
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip("Show landlines to other controllers")
+		}
+
+		if imgui.Button(renderer.FontAwesomeIconFileExport) {
+			if ui.trailWindow == nil {
+				ui.trailWindow = MakeTrailExportWindow(controlClient)
+			} else {
+				ui.trailWindow = nil
+			}
+		}
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip("Show an aircraft's timeline and export its track history")
+		}
+
+		if imgui.Button(renderer.FontAwesomeIconBookmark) {
+			ui.showBookmarks = !ui.showBookmarks
+		}
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip("Bookmark and annotate moments in the session")
+		}
+
+		if ui.bulletinWindow != nil {
+			unread := ui.bulletinWindow.Unread(config)
+			if unread {
+				imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{1, .8, 0, 1})
+			}
+			if imgui.Button(renderer.FontAwesomeIconBullhorn) {
+				ui.showBulletin = !ui.showBulletin
+			}
+			if unread {
+				imgui.PopStyleColor()
+			}
+			if imgui.IsItemHovered() {
+				imgui.SetTooltip(util.Select(unread, "Show the facility bulletin (unread)", "Show the facility bulletin"))
+			}
+		}
+
+		if imgui.Button(renderer.FontAwesomeIconGraduationCap) {
+			if ui.tutorialWindow == nil {
+				ui.tutorialWindow = MakeTutorialWindow(controlClient, eventStream)
+			} else {
+				ui.tutorialWindow.Close()
+				ui.tutorialWindow = nil
+			}
+		}
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip("Run the interactive tutorial")
+		}
+
+		if imgui.Button(renderer.FontAwesomeIconHeadset) {
+			config.HideRadioWindow = !config.HideRadioWindow
+		}
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip(util.Select(config.HideRadioWindow, "Show", "Hide") + " radio frequencies")
+		}
+
+		if imgui.Button(renderer.FontAwesomeIconUsers) {
+			ui.showStaffing = !ui.showStaffing
+		}
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip("Show position staffing (on position " + ui.positionTimer.String() + ")")
+		}
+	}
+
+	if imgui.Button(renderer.FontAwesomeIconKeyboard) {
+		uiToggleShowKeyboardWindow()
+	}
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Show summary of keyboard commands")
+	}
+
+	enableLaunch := controlClient != nil &&
+		(controlClient.LaunchConfig.Controller == "" || controlClient.LaunchConfig.Controller == controlClient.Callsign)
+	uiStartDisable(!enableLaunch)
+	if imgui.Button(renderer.FontAwesomeIconPlaneDeparture) {
+		controlClient.TakeOrReturnLaunchControl(eventStream)
+	}
+	if imgui.IsItemHovered() {
+		verb := util.Select(controlClient.LaunchConfig.Controller == "", "Start", "Stop")
+		tip := verb + " manually control spawning new aircraft"
+		if controlClient.LaunchConfig.Controller != "" {
+			tip += "\nCurrent controller: " + controlClient.LaunchConfig.Controller
+		}
+		imgui.SetTooltip(tip)
+	}
+	uiEndDisable(!enableLaunch)
+
+	if imgui.Button(renderer.FontAwesomeIconBook) {
+		browser.OpenURL("https://pharr.org/vice/index.html")
+	}
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Display online vice documentation")
+	}
+
+	if imgui.Button(renderer.FontAwesomeIconStethoscope) {
+		if ui.diagWindow == nil {
+			ui.diagWindow = MakeDiagnosticsWindow(controlClient, config, lg)
+		} else {
+			ui.diagWindow = nil
+		}
+	}
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Check the configuration and facility adaptation for problems")
+	}
+
+	if imgui.Button(renderer.FontAwesomeIconBug) {
+		if ui.reportWindow == nil {
+			ui.reportWindow = MakeReportWindow(controlClient)
+		} else {
+			ui.reportWindow = nil
+		}
+	}
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Report a problem")
+	}
+}
+
+// uiDrawPaneSwitcher draws a menu for selecting which pane is shown in
+// compact mode.
+func uiDrawPaneSwitcher(config *Config) {
+	current := panes.CompactPane()
+	name := ""
+	if current != nil {
+		name = panes.PaneName(current)
+	}
+
+	imgui.SetNextItemWidth(float32(12 * ui.font.Size))
+	if imgui.BeginComboV("##pane", name, imgui.ComboFlagsHeightLarge) {
+		config.DisplayRoot.VisitPanes(func(pane panes.Pane) {
+			if _, ok := pane.(*panes.SplitLine); ok {
+				return
+			}
+			if imgui.SelectableV(panes.PaneName(pane), pane == current, 0, imgui.Vec2{}) {
+				panes.SetCompactPane(pane)
+			}
+		})
+		imgui.EndCombo()
+	}
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Select the window to show")
+	}
+}
+
+// uiDrawDetachUI lists the panes and lets the user move them to their
+// own windows and back.
+func uiDrawDetachUI(config *Config, p platform.Platform) {
+	root := config.DisplayRoot
+	detached, overlays := root.DetachedPanes(), root.OverlayPanes()
+
+	canUndo, canRedo := panes.CanUndoLayout(root), panes.CanRedoLayout(root)
+	uiStartDisable(!canUndo)
+	if imgui.Button("Undo") {
+		panes.UndoLayout(root)
+	}
+	uiEndDisable(!canUndo)
+	imgui.SameLine()
+	uiStartDisable(!canRedo)
+	if imgui.Button("Redo") {
+		panes.RedoLayout(root)
+	}
+	uiEndDisable(!canRedo)
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Moving, detaching, re-docking, and resizing windows can be undone and redone.")
+	}
+
+	imgui.Text("Detached windows can be moved to other monitors; closing one returns it to the main window.")
+	imgui.Text("Overlays are drawn on top of the other windows; drag one's bar to move it.")
+	flags := imgui.TableFlagsBordersV | imgui.TableFlagsBordersOuterH | imgui.TableFlagsRowBg |
+		imgui.TableFlagsSizingFixedFit
+	// The display hierarchy is updated after it has been traversed.
+	var detach, redock, collapse, restore, overlay, dock panes.Pane
+	if imgui.BeginTableV("detach", 4, flags, imgui.Vec2{}, 0) {
+		root.VisitPanes(func(pane panes.Pane) {
+			if _, ok := pane.(*panes.SplitLine); ok {
+				return
+			}
+			imgui.PushID(fmt.Sprintf("%p", pane))
+			imgui.TableNextRow()
+			imgui.TableNextColumn()
+			imgui.Text(panes.PaneName(pane))
+			imgui.TableNextColumn()
+			isOverlay := slices.Contains(overlays, pane)
+			if slices.Contains(detached, pane) {
+				if imgui.Button("Re-dock") {
+					redock = pane
+				}
+			} else if !isOverlay && imgui.Button("Detach") {
+				detach = pane
+			}
+			imgui.TableNextColumn()
+			if isOverlay {
+				if imgui.Button("Dock") {
+					dock = pane
+				}
+			} else if !slices.Contains(detached, pane) && imgui.Button("Overlay") {
+				overlay = pane
+			}
+			imgui.TableNextColumn()
+			if root.IsCollapsed(pane) {
+				if imgui.Button("Restore") {
+					restore = pane
+				}
+			} else if !slices.Contains(detached, pane) && !isOverlay && imgui.Button("Collapse") {
+				collapse = pane
+			}
+			imgui.PopID()
+		})
+		imgui.EndTable()
+	}
+
+	if redock != nil {
+		root.RedockPane(redock)
+	}
+	if dock != nil {
+		root.DockOverlay(dock)
+	}
+	if restore != nil {
+		root.RestorePane(restore)
+	}
+	if collapse != nil && !root.CollapsePane(collapse) {
+		uiShowModalDialog(NewModalDialogBox(&MessageModalClient{
+			title:   "Error",
+			message: "Only windows that share a split with another expanded window can be collapsed.",
+		}, p), true)
+	}
+	if overlay != nil && !root.OverlayPane(overlay) {
+		uiShowModalDialog(NewModalDialogBox(&MessageModalClient{
+			title:   "Error",
+			message: "At least one window must remain in the main window.",
+		}, p), true)
+	}
+	if detach != nil && !root.DetachPane(detach) {
+		uiShowModalDialog(NewModalDialogBox(&MessageModalClient{
+			title:   "Error",
+			message: "At least one window must remain in the main window.",
+		}, p), true)
+	}
+}
+
+// uiDrawStorageUI draws the settings for the cache of downloaded
+// resources.
+func uiDrawStorageUI(config *Config) {
+	cache := util.GetResourceCache()
+	stats := cache.Stats()
+	if stats.Dir == "" {
+		imgui.Text("The download cache is unavailable.")
+		return
+	}
+
+	imgui.Text("Download cache: " + stats.Dir)
+	imgui.Text(fmt.Sprintf("%d files, %.1f MB of %d MB", stats.Count, float64(stats.Size)/(1<<20),
+		stats.MaxSize>>20))
+
+	mb := int32(config.ResourceCacheMB)
+	imgui.SetNextItemWidth(150)
+	if imgui.InputIntV("Cache size limit (MB)", &mb, 64, 256, imgui.InputTextFlagsEnterReturnsTrue) {
+		config.ResourceCacheMB = int(math.Clamp(mb, 64, 16384))
+		cache.SetMaxSize(int64(config.ResourceCacheMB) << 20)
+	}
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("When the cache is full, the least recently used files are removed.")
+	}
+
+	if imgui.Button("Clear cache") {
+		cache.Clear()
+	}
+
+	if stats.LastErr != nil {
+		imgui.PushStyleColor(imgui.StyleColorText, imgui.Vec4{1, .5, .5, 1})
+		imgui.Text("Offline; using cached data: " + stats.LastErr.Error())
+		imgui.PopStyleColor()
+	}
+}
+
+// uiDrawSplitUI lists the split lines between the panes and lets the user
+// give their positions as percentages or as the sizes of the panes on
+// either side in pixels.
+func uiDrawSplitUI(config *Config) {
+	root := config.DisplayRoot
+	splits := root.Splits()
+	if len(splits) == 0 {
+		return
+	}
+
+	imgui.Text("Split positions: press Enter after typing a value to apply it.")
+	flags := imgui.TableFlagsBordersV | imgui.TableFlagsBordersOuterH | imgui.TableFlagsRowBg |
+		imgui.TableFlagsSizingFixedFit
+	if imgui.BeginTableV("splits", 5, flags, imgui.Vec2{}, 0) {
+		imgui.TableSetupColumn("Left / Bottom")
+		imgui.TableSetupColumn("Right / Top")
+		imgui.TableSetupColumn("Position (%)")
+		imgui.TableSetupColumn("Left / Bottom (px)")
+		imgui.TableSetupColumn("Right / Top (px)")
+		imgui.TableHeadersRow()
+
+		inputFlags := imgui.InputTextFlagsEnterReturnsTrue
+		for i, s := range splits {
+			imgui.PushIDInt(i)
+			imgui.TableNextRow()
+			imgui.TableNextColumn()
+			imgui.Text(s.Before)
+			imgui.TableNextColumn()
+			imgui.Text(s.After)
+
+			imgui.TableNextColumn()
+			pct := strconv.FormatFloat(float64(100*s.Line.Pos), 'f', 1, 32)
+			imgui.SetNextItemWidth(80)
+			if imgui.InputTextV("##pct", &pct, inputFlags|imgui.InputTextFlagsCharsDecimal, nil) {
+				if v, err := strconv.ParseFloat(pct, 32); err == nil {
+					root.SetSplitPos(s.Line, float32(v)/100)
+				}
+			}
+
+			// Pixel sizes are only available if the split was drawn.
+			uiStartDisable(s.Size == 0)
+			imgui.TableNextColumn()
+			before := int32((s.Line.Pos-s.Start)*s.Size + 0.5)
+			imgui.SetNextItemWidth(100)
+			if imgui.InputIntV("##before", &before, 0, 0, inputFlags) && s.Size > 0 {
+				root.SetSplitPos(s.Line, s.Start+float32(before)/s.Size)
+			}
+			imgui.TableNextColumn()
+			after := int32((s.End-s.Line.Pos)*s.Size + 0.5)
+			imgui.SetNextItemWidth(100)
+			if imgui.InputIntV("##after", &after, 0, 0, inputFlags) && s.Size > 0 {
+				root.SetSplitPos(s.Line, s.End-float32(after)/s.Size)
+			}
+			uiEndDisable(s.Size == 0)
+
+			imgui.PopID()
+		}
+		imgui.EndTable()
+	}
 }
 
 func uiResetControlClient(c *sim.ControlClient) {
 	ui.launchControlWindow = nil
+	ui.rerouteWindow = nil
+	ui.interceptWindow = nil
+	ui.edctWindow = nil
+	ui.reportWindow = nil
+	ui.diagWindow = nil
+	ui.briefingWindow = nil
+	ui.trailWindow = nil
+	ui.signOffWindow = nil
+	ui.landlineWindow = nil
+	ui.staffingWindow = nil
+	ui.bookmarkWindow = nil
+	if ui.tutorialWindow != nil {
+		ui.tutorialWindow.Close()
+		ui.tutorialWindow = nil
+	}
+	ui.positionTimer = PositionTimer{} // restarted at the next update
+	ui.checkedPositionTemplate = false
+
+	ui.bulletinWindow, ui.showBulletin = nil, false
+	if c != nil && c.State.BulletinURL != "" {
+		ui.bulletinWindow = MakeBulletinWindow(c.State.BulletinURL)
+	}
 }
 
 func drawActiveDialogBoxes() {
@@ -643,6 +1246,14 @@ type ConnectModalClient struct {
 	allowCancel bool
 	platform    platform.Platform
 	config      *Config
+
+	// The facility roster check runs in a separate goroutine so that the
+	// UI doesn't stall while waiting for the server. rosterCh is non-nil
+	// while it's pending; once it finishes, its warnings are held in
+	// rosterWarnings until the connect button's action picks them up.
+	rosterCh       chan []string
+	rosterWarnings []string
+	rosterChecked  bool
 }
 
 func (c *ConnectModalClient) Title() string { return "New Simulation" }
@@ -651,6 +1262,7 @@ func (c *ConnectModalClient) Opening() {
 	if c.simConfig == nil {
 		c.simConfig = sim.MakeNewSimConfiguration(c.mgr, &c.config.LastTRACON, c.lg)
 	}
+	c.rosterCh, c.rosterWarnings, c.rosterChecked = nil, nil, false
 }
 
 func (c *ConnectModalClient) Buttons() []ModalDialogButton {
@@ -659,30 +1271,116 @@ func (c *ConnectModalClient) Buttons() []ModalDialogButton {
 		b = append(b, ModalDialogButton{text: "Cancel"})
 	}
 
+	if c.rosterCh != nil {
+		return append(b, ModalDialogButton{text: "Checking roster...", disabled: true})
+	}
+
 	next := ModalDialogButton{
 		text:     c.simConfig.UIButtonText(),
 		disabled: c.simConfig.OkDisabled(),
 		action: func() bool {
-			if c.simConfig.ShowRatesWindow() {
-				client := &RatesModalClient{
-					lg:            c.lg,
-					connectClient: c,
-					platform:      c.platform,
+			if !c.rosterChecked {
+				if c.startRosterCheck() {
+					// Draw() runs this action again once the check is done.
+					return false
 				}
-				uiShowModalDialog(NewModalDialogBox(client, c.platform), false)
+				return c.next()
+			}
+
+			warnings := c.rosterWarnings
+			c.rosterWarnings, c.rosterChecked = nil, false
+			if len(warnings) > 0 {
+				uiShowModalDialog(NewModalDialogBox(&YesOrNoModalClient{
+					title: "Position Validation",
+					query: strings.Join(warnings, "\n") + "\n\nConnect anyway?",
+					ok: func() {
+						if !c.next() {
+							// Go back so that the error is shown.
+							uiShowModalDialog(NewModalDialogBox(c, c.platform), false)
+						}
+					},
+					notok: func() {
+						uiShowModalDialog(NewModalDialogBox(c, c.platform), false)
+					},
+				}, c.platform), false)
 				return true
-			} else {
-				c.simConfig.DisplayError = c.simConfig.Start()
-				return c.simConfig.DisplayError == nil
 			}
+			return c.next()
 		},
 	}
 
 	return append(b, next)
 }
 
+// next either moves on to the rates dialog or starts the sim, returning
+// true if the connect dialog should be closed.
+func (c *ConnectModalClient) next() bool {
+	if c.simConfig.ShowRatesWindow() {
+		client := &RatesModalClient{
+			lg:            c.lg,
+			connectClient: c,
+			platform:      c.platform,
+		}
+		uiShowModalDialog(NewModalDialogBox(client, c.platform), false)
+		return true
+	} else {
+		c.simConfig.DisplayError = c.simConfig.Start()
+		return c.simConfig.DisplayError == nil
+	}
+}
+
+// startRosterCheck starts validating the selected position with the
+// facility roster endpoint, if one has been specified, and returns true
+// if it did so. CheckRosterPosition's request times out, so the check
+// always finishes.
+func (c *ConnectModalClient) startRosterCheck() bool {
+	facility, position := c.simConfig.SignOnPosition()
+	if c.config.RosterURL == "" || position == "" {
+		return false
+	}
+
+	ch := make(chan []string, 1) // buffered so the goroutine never blocks if the dialog is closed
+	c.rosterCh = ch
+	rosterURL, cid, lg := c.config.RosterURL, c.config.RosterCID, c.lg
+	go func() {
+		result, err := sim.CheckRosterPosition(rosterURL, cid, facility, position)
+		if err != nil {
+			lg.Warnf("%s: roster check: %v", rosterURL, err)
+			ch <- []string{"Unable to validate " + position + " with the facility roster: " + err.Error()}
+		} else {
+			ch <- result.Warnings()
+		}
+	}()
+	return true
+}
+
 func (c *ConnectModalClient) Draw() int {
-	if enter := c.simConfig.DrawUI(c.platform); enter {
+	if c.rosterCh != nil {
+		select {
+		case c.rosterWarnings = <-c.rosterCh:
+			c.rosterCh, c.rosterChecked = nil, true
+			// Run the connect button's action again to act on the result.
+			if c.allowCancel {
+				return 1
+			}
+			return 0
+		default:
+			imgui.Text("Checking the position with the facility roster...")
+			return -1
+		}
+	}
+
+	enter := c.simConfig.DrawUI(c.platform)
+
+	if _, position := c.simConfig.SignOnPosition(); position != "" {
+		imgui.Separator()
+		if imgui.CollapsingHeader("Facility roster validation") {
+			imgui.InputTextV("Roster URL", &c.config.RosterURL, 0, nil)
+			imgui.InputTextV("Controller ID", &c.config.RosterCID, 0, nil)
+		}
+	}
+
+	if enter {
 		return 1
 	} else {
 		return -1
@@ -1479,7 +2177,8 @@ func (lc *LaunchControlWindow) Draw(eventStream *sim.EventStream, p platform.Pla
 			imgui.EndTable()
 		}
 	} else {
-		changed := lc.controlClient.LaunchConfig.DrawDepartureUI(p)
+		changed := lc.controlClient.LaunchConfig.DrawDemandUI()
+		changed = lc.controlClient.LaunchConfig.DrawDepartureUI(p) || changed
 		changed = lc.controlClient.LaunchConfig.DrawArrivalUI(p) || changed
 		changed = lc.controlClient.LaunchConfig.DrawOverflightUI(p) || changed
 
@@ -1530,6 +2229,7 @@ var secondaryAcCommands = [][3]string{
 	[3]string{"*R_hdg", `"Turn right heading _hdg_".`, "*R210*"},
 	[3]string{"*T_deg*R", `"Turn _deg_ degrees right".`, "*T20R*"},
 	[3]string{"*D_fix*/H_hdg", `"Depart _fix_ heading _hdg_".`, "*DLENDY/H180*"},
+	[3]string{"*D_fix*/R_fix2", `"Proceed direct _fix_, then direct _fix2_", rejoining the route at _fix2_.`, "*DCCC/RDPK*"},
 	[3]string{"*C_fix*/A_alt*/S_kts",
 		`"Cross _fix_ at _alt_ / _kts_ knots."
 Either one or both of *A* and *S* may be specified.`, "*CCAMRN/A110+*"},
@@ -1557,11 +2257,14 @@ If the aircraft has been handed off to another controller who has accepted
 the handoff, transfer control to the other controller.`},
 	[2]string{"*[F3] @", `Initiate track of an untracked aircraft.`},
 	[2]string{"_id_ @", `Handoff aircraft to the controller identified by _id_.`},
+	[2]string{"NEXT @", `Handoff aircraft to the next controller given by the airspace awareness rules.`},
 	[2]string{". @", `Clear aircraft's scratchpad.`},
 	[2]string{"*[F7]Y_scr_ @", `Set aircraft's scratchpad to _scr_ (3 character limit).`},
 	[2]string{"+_alt_ @", `Set the temporary altitude in the aircraft's datablock to _alt_,
 which must be 3 digits (e.g., *040*).`},
 	[2]string{"_id_\\* @", `Point out the aircraft to the controller identified by _id_.`},
+	[2]string{"*\\*H_hdg_ @", `Record the heading assigned to the aircraft in its datablock.`},
+	[2]string{"*\\*S_kts_ @", `Record the speed assigned to the aircraft in its datablock.`},
 }
 
 // draw the windows that shows the available keyboard commands
@@ -1704,6 +2407,51 @@ control positions in the controller list on the upper right side of the scope (u
 	imgui.End()
 }
 
+// uiDrawHelpWindow shows the help topic most recently requested with F1.
+func uiDrawHelpWindow(config *Config) {
+	if ui.helpTopic == nil {
+		return
+	}
+
+	show := true
+	imgui.BeginV("Help: "+ui.helpTopic.Title+"###Help", &show, imgui.WindowFlagsAlwaysAutoResize)
+
+	fixedFont := renderer.GetFont(renderer.FontIdentifier{Name: "Roboto Mono", Size: config.UIFontSize})
+	italicFont := renderer.GetFont(renderer.FontIdentifier{Name: "Roboto Mono Italic", Size: config.UIFontSize})
+
+	t := ui.helpTopic
+	if t.Syntax != "" {
+		uiDrawMarkedupText(ui.font, fixedFont, italicFont, "*"+t.Syntax)
+		imgui.Text("\n")
+	}
+	uiDrawMarkedupText(ui.font, fixedFont, italicFont, t.Description)
+	if t.CLI != "" {
+		imgui.Text("\n\n")
+		uiDrawMarkedupText(ui.font, fixedFont, italicFont, "Messages pane: *"+t.CLI)
+	}
+	if t.Adaptation != "" {
+		imgui.Text("\n\n")
+		uiDrawMarkedupText(ui.font, fixedFont, italicFont, "Adaptation: "+t.Adaptation)
+	}
+	imgui.Text("\n")
+
+	imgui.End()
+
+	if !show {
+		ui.helpTopic = nil
+	}
+}
+
+// uiSettingHelp should be called after the widget for a setting is drawn;
+// pressing F1 while it is hovered shows the setting's help, if any.
+func uiSettingHelp(name string) {
+	if imgui.IsItemHovered() && imgui.IsKeyPressed(platform.ImguiKeyF1) {
+		if t, ok := panes.SettingHelp(name); ok {
+			panes.RequestHelp(t)
+		}
+	}
+}
+
 // uiDrawMarkedupText uses imgui to draw the given string, which may
 // include some rudimentary markup:
 //
@@ -1857,7 +2605,8 @@ func uiDrawMissingPrimaryDialog(mgr *sim.ConnectionManager, c *sim.ControlClient
 	}
 }
 
-func uiDrawSettingsWindow(c *sim.ControlClient, config *Config, p platform.Platform) {
+func uiDrawSettingsWindow(c *sim.ControlClient, config *Config, p platform.Platform, r renderer.Renderer,
+	eventStream *sim.EventStream, lg *log.Logger) {
 	if !ui.showSettings {
 		return
 	}
@@ -1867,9 +2616,25 @@ func uiDrawSettingsWindow(c *sim.ControlClient, config *Config, p platform.Platf
 	if imgui.SliderFloatV("Simulation speed", &c.SimRate, 1, 20, "%.1f", 0) {
 		c.SetSimRate(c.SimRate)
 	}
+	uiSettingHelp("Simulation speed")
+
+	remind := !config.DisableBreakReminders
+	imgui.Checkbox("Remind me to take breaks", &remind)
+	uiSettingHelp("Remind me to take breaks")
+	config.DisableBreakReminders = !remind
+	if remind {
+		minutes := int32(config.BreakReminderMinutes)
+		imgui.SliderInt("Minutes on position between breaks", &minutes, 30, 240)
+		config.BreakReminderMinutes = int(minutes)
+	}
+
+	autosave := int32(config.AutosaveMinutes)
+	imgui.SliderInt("Autosave configuration every (minutes)", &autosave, 1, 30)
+	config.AutosaveMinutes = int(autosave)
 
 	update := !config.InhibitDiscordActivity.Load()
 	imgui.Checkbox("Update Discord activity status", &update)
+	uiSettingHelp("Update Discord activity status")
 	config.InhibitDiscordActivity.Store(!update)
 
 	if imgui.BeginComboV("UI Font Size", strconv.Itoa(config.UIFontSize), imgui.ComboFlagsHeightLarge) {
@@ -1882,18 +2647,107 @@ func uiDrawSettingsWindow(c *sim.ControlClient, config *Config, p platform.Platf
 		}
 		imgui.EndCombo()
 	}
+	uiSettingHelp("UI Font Size")
 
 	if imgui.CollapsingHeader("Display") {
-		if imgui.Checkbox("Enable anti-aliasing", &config.EnableMSAA) {
-			uiShowModalDialog(NewModalDialogBox(
-				&MessageModalClient{
-					title: "Alert",
-					message: "You must restart vice for changes to the anti-aliasing " +
-						"mode to take effect.",
-				}, p), true)
+		msaa := "Off"
+		if config.EnableMSAA {
+			msaa = strconv.Itoa(config.MSAASamples) + "x"
+		}
+		if imgui.BeginCombo("Anti-aliasing (MSAA)", msaa) {
+			for _, samples := range []int{0, 2, 4, 8} {
+				label := util.Select(samples == 0, "Off", strconv.Itoa(samples)+"x")
+				if imgui.SelectableV(label, label == msaa, 0, imgui.Vec2{}) && label != msaa {
+					config.EnableMSAA = samples > 0
+					if samples > 0 {
+						config.MSAASamples = samples
+					}
+					uiShowModalDialog(NewModalDialogBox(
+						&MessageModalClient{
+							title: "Alert",
+							message: "You must restart vice for changes to the anti-aliasing " +
+								"mode to take effect.",
+						}, p), true)
+				}
+			}
+			imgui.EndCombo()
+		}
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip("Higher settings give smoother lines but require more GPU resources.")
+		}
+
+		if imgui.Checkbox("Smooth lines", &config.SmoothLines) {
+			r.SetLineSmoothing(config.SmoothLines && !ui.degrader.Degraded())
+		}
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip("Reduces shimmering of thin map lines when panning and zooming")
+		}
+
+		degrade := !config.DisableDegradedRendering
+		imgui.Checkbox("Simplify rendering when frames are slow", &degrade)
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip("Turns off line smoothing, map imagery, terrain maps, and long history trails " +
+				"while the frame time is high so that the scope stays responsive")
+		}
+		config.DisableDegradedRendering = !degrade
+		if degrade {
+			ms := int32(config.DegradedFrameTimeMS)
+			imgui.SliderInt("Frame time threshold (ms)", &ms, 20, 200)
+			config.DegradedFrameTimeMS = int(ms)
+		}
+
+		imgui.SetNextItemWidth(100)
+		if imgui.BeginCombo("Maximize window shortcut", util.Select(config.maximizePaneKey() == "", "Off",
+			"Ctrl-"+config.maximizePaneKey())) {
+			if imgui.SelectableV("Off", config.maximizePaneKey() == "", 0, imgui.Vec2{}) {
+				config.MaximizePaneKey = "Off"
+			}
+			for k := 'A'; k <= 'Z'; k++ {
+				if imgui.SelectableV("Ctrl-"+string(k), config.maximizePaneKey() == string(k), 0, imgui.Vec2{}) {
+					config.MaximizePaneKey = string(k)
+				}
+			}
+			imgui.EndCombo()
+		}
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip("Shows the window under the mouse using the entire screen; adding shift\n" +
+				"maximizes the window with the keyboard focus. Press it again to restore the layout.")
+		}
+
+		imgui.SetNextItemWidth(200)
+		if imgui.BeginCombo("Maximize at startup", util.Select(config.StartMaximizedPane == "", "None",
+			config.StartMaximizedPane)) {
+			if imgui.SelectableV("None", config.StartMaximizedPane == "", 0, imgui.Vec2{}) {
+				config.StartMaximizedPane = ""
+			}
+			var names []string
+			config.DisplayRoot.VisitPanes(func(pane panes.Pane) {
+				if pane.CanTakeKeyboardFocus() {
+					names = append(names, panes.PaneName(pane))
+				}
+			})
+			slices.Sort(names)
+			for _, name := range slices.Compact(names) {
+				if imgui.SelectableV(name, config.StartMaximizedPane == name, 0, imgui.Vec2{}) {
+					config.StartMaximizedPane = name
+				}
+			}
+			imgui.EndCombo()
+		}
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip("Shows this window using the entire screen when vice starts, e.g. for a\n" +
+				"computer that is only used for the scope")
+		}
+
+		imgui.Checkbox("Compact layout for small screens", &config.CompactMode)
+		uiSettingHelp("Compact layout for small screens")
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip("Show one window at a time, selected from the menu bar, move the tool buttons\n" +
+				"into a menu, and use smaller fonts for the STARS lists")
 		}
 
 		imgui.Checkbox("Start in full-screen", &config.StartInFullScreen)
+		uiSettingHelp("Start in full-screen")
 
 		monitorNames := p.GetAllMonitorNames()
 		if imgui.BeginComboV("Monitor", monitorNames[config.FullScreenMonitor], imgui.ComboFlagsHeightLarge) {
@@ -1907,11 +2761,66 @@ func uiDrawSettingsWindow(c *sim.ControlClient, config *Config, p platform.Platf
 
 			imgui.EndCombo()
 		}
+
+		if len(monitorNames) > 1 {
+			span := config.SpanAllMonitors
+			if imgui.Checkbox("Span all monitors", &span) {
+				p.SetSpanAllMonitors(span)
+			}
+			if imgui.IsItemHovered() {
+				imgui.SetTooltip("When not in full-screen, make the window cover all of the monitors.\n" +
+					"Windows can also be detached and placed on other monitors; their positions\n" +
+					"are remembered separately for each set of monitors.")
+			}
+		}
+	}
+
+	if imgui.CollapsingHeader("Windows") {
+		uiDrawDetachUI(config, p)
+		uiDrawSplitUI(config)
+	}
+
+	if imgui.CollapsingHeader("Storage") {
+		uiDrawStorageUI(config)
+
+		if imgui.Button("Restore from backup...") {
+			uiShowModalDialog(NewModalDialogBox(&RestoreBackupModalClient{
+				config:        config,
+				controlClient: c,
+				renderer:      r,
+				platform:      p,
+				eventStream:   eventStream,
+				lg:            lg,
+			}, p), true)
+		}
+		if imgui.IsItemHovered() {
+			imgui.SetTooltip("A backup of the configuration is made each time vice saves it.")
+		}
+	}
+
+	if imgui.CollapsingHeader("Usage metrics") {
+		uiDrawTelemetryUI(ui.telemetry, config, c)
 	}
 
 	config.DisplayRoot.VisitPanes(func(pane panes.Pane) {
 		if draw, ok := pane.(panes.UIDrawer); ok {
+			if pane == ui.settingsPane {
+				imgui.SetNextItemOpen(true, imgui.ConditionAlways)
+				ui.settingsPane = nil
+			}
 			if imgui.CollapsingHeader(draw.DisplayName()) {
+				imgui.PushID(fmt.Sprintf("%p", pane))
+				if s, ok := pane.(panes.Scaler); ok {
+					pct := 100 * s.UIScale()
+					imgui.SetNextItemWidth(200)
+					if imgui.SliderFloatV("Scale (%)", &pct, 50, 300, "%.0f", 0) {
+						s.SetUIScale(math.Clamp(pct, 50, 300) / 100)
+					}
+					if imgui.IsItemHovered() {
+						imgui.SetTooltip("Scales the text and graphics in this window only")
+					}
+				}
+				imgui.PopID()
 				draw.DrawUI(p, &config.Config)
 			}
 		}
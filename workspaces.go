@@ -0,0 +1,175 @@
+// workspaces.go
+// Copyright(c) 2022-2024 vice contributors, licensed under the GNU Public License, Version 3.
+// SPDX: GPL-3.0-only
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mmp/vice/pkg/log"
+	"github.com/mmp/vice/pkg/panes"
+	"github.com/mmp/vice/pkg/platform"
+	"github.com/mmp/vice/pkg/renderer"
+	"github.com/mmp/vice/pkg/sim"
+
+	"github.com/mmp/imgui-go/v4"
+)
+
+// Workspaces are independent display hierarchies that the user can flip
+// between with Alt and a number key, e.g. one focused on the scope and
+// another on coordination. Unlike layout snapshots, each workspace keeps
+// its panes, so their state is preserved across switches. The active
+// workspace's hierarchy is Config.DisplayRoot; its Root is nil.
+
+// maxWorkspaceShortcuts is the number of workspaces that can be switched
+// to with Alt and a number key.
+const maxWorkspaceShortcuts = 9
+
+type Workspace struct {
+	Name string
+	Root *panes.DisplayNode `json:",omitempty"`
+}
+
+// AddWorkspace adds a new workspace with the given name that starts out
+// with a copy of the current layout.
+func (c *Config) AddWorkspace(name string) error {
+	if slices.ContainsFunc(c.Workspaces, func(ws Workspace) bool { return ws.Name == name }) {
+		return fmt.Errorf("%s: a workspace with that name already exists", name)
+	}
+
+	// Make a copy of the current hierarchy with new panes.
+	b, err := json.Marshal(c.DisplayRoot)
+	if err != nil {
+		return err
+	}
+	root := &panes.DisplayNode{}
+	if err := json.Unmarshal(b, root); err != nil {
+		return err
+	}
+
+	if len(c.Workspaces) == 0 {
+		// The current layout becomes the first workspace.
+		c.Workspaces = []Workspace{{Name: "Main"}}
+		c.ActiveWorkspace = 0
+	}
+	c.Workspaces = append(c.Workspaces, Workspace{Name: name, Root: root})
+	return nil
+}
+
+// SwitchWorkspace makes the i'th workspace the active one.
+func (c *Config) SwitchWorkspace(i int, controlClient *sim.ControlClient, r renderer.Renderer,
+	p platform.Platform, eventStream *sim.EventStream, lg *log.Logger) {
+	if i == c.ActiveWorkspace || i < 0 || i >= len(c.Workspaces) {
+		return
+	}
+
+	root := c.Workspaces[i].Root
+	c.Workspaces[c.ActiveWorkspace].Root = c.DisplayRoot
+	c.Workspaces[i].Root = nil
+	c.ActiveWorkspace = i
+	c.replaceDisplayRoot(root, controlClient, r, p, eventStream, lg)
+	lg.Infof("Switched to workspace %q", c.Workspaces[i].Name)
+}
+
+// RemoveWorkspace removes the i'th workspace, which must not be the
+// active one.
+func (c *Config) RemoveWorkspace(i int) {
+	if i == c.ActiveWorkspace || i < 0 || i >= len(c.Workspaces) {
+		return
+	}
+	c.Workspaces = slices.Delete(c.Workspaces, i, i+1)
+	if i < c.ActiveWorkspace {
+		c.ActiveWorkspace--
+	}
+	if len(c.Workspaces) == 1 {
+		// Only the current one is left.
+		c.Workspaces, c.ActiveWorkspace = nil, 0
+	}
+}
+
+// visitWorkspaceRoots calls the given function for the display hierarchy
+// of each inactive workspace.
+func (c *Config) visitWorkspaceRoots(visit func(*panes.DisplayNode)) {
+	for _, ws := range c.Workspaces {
+		if ws.Root != nil {
+			visit(ws.Root)
+		}
+	}
+}
+
+// uiDrawWorkspacesMenu draws the workspace section of the layouts popup.
+func uiDrawWorkspacesMenu(config *Config, controlClient *sim.ControlClient, r renderer.Renderer, p platform.Platform,
+	eventStream *sim.EventStream, lg *log.Logger) {
+	if !imgui.BeginMenu("Workspaces") {
+		return
+	}
+
+	switchTo, remove := -1, -1
+	for i, ws := range config.Workspaces {
+		shortcut := ""
+		if i < maxWorkspaceShortcuts {
+			shortcut = fmt.Sprintf("Alt-%d", i+1)
+		}
+		if imgui.MenuItemV(ws.Name, shortcut, i == config.ActiveWorkspace, true) {
+			switchTo = i
+		}
+	}
+	if len(config.Workspaces) > 1 && imgui.BeginMenu("Delete") {
+		for i, ws := range config.Workspaces {
+			if imgui.MenuItemV(ws.Name, "", false, i != config.ActiveWorkspace) {
+				remove = i
+			}
+		}
+		imgui.EndMenu()
+	}
+
+	imgui.SetNextItemWidth(200)
+	add := imgui.InputTextWithHintV("##workspacename", "Workspace name", &ui.workspaceName,
+		imgui.InputTextFlagsEnterReturnsTrue, nil)
+	imgui.SameLine()
+	name := strings.TrimSpace(ui.workspaceName)
+	uiStartDisable(name == "")
+	add = imgui.Button("Add workspace") || add
+	uiEndDisable(name == "")
+	if imgui.IsItemHovered() {
+		imgui.SetTooltip("Adds a workspace that starts out with a copy of the current layout")
+	}
+	if add && name != "" {
+		if err := config.AddWorkspace(name); err != nil {
+			lg.Errorf("%v", err)
+		} else {
+			switchTo = len(config.Workspaces) - 1
+		}
+		ui.workspaceName = ""
+	}
+
+	imgui.EndMenu()
+
+	if remove != -1 {
+		config.RemoveWorkspace(remove)
+	}
+	if switchTo != -1 {
+		config.SwitchWorkspace(switchTo, controlClient, r, p, eventStream, lg)
+	}
+}
+
+// uiCheckWorkspaceShortcuts switches to the nth workspace when Alt-n is
+// pressed.
+func uiCheckWorkspaceShortcuts(config *Config, controlClient *sim.ControlClient, r renderer.Renderer,
+	p platform.Platform, eventStream *sim.EventStream, lg *log.Logger) {
+	io := imgui.CurrentIO()
+	if io.WantCaptureKeyboard() || !io.KeyAltPressed() || io.KeyCtrlPressed() || io.KeyShiftPressed() {
+		return
+	}
+
+	for i := 0; i < len(config.Workspaces) && i < maxWorkspaceShortcuts; i++ {
+		if imgui.IsKeyPressed(platform.ImguiKey1 + i) {
+			config.SwitchWorkspace(i, controlClient, r, p, eventStream, lg)
+			return
+		}
+	}
+}